@@ -1,6 +1,8 @@
 package app
 
 import (
+	"context"
+
 	"eduhub/server/api/handler"
 	"eduhub/server/internal/config"
 	"eduhub/server/internal/middleware"
@@ -54,5 +56,11 @@ func (a *App) Start() error {
 	// Setup routes
 	handler.SetupRoutes(a.e, a.handlers, a.middleware.Auth)
 
+	// Background jobs stop when the server does
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go a.services.AbsenteeScheduler.Run(ctx)
+	go a.services.SuspensionScheduler.Run(ctx)
+
 	return a.e.Start(":" + a.config.AppPort)
 }
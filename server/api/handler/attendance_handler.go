@@ -1,10 +1,15 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"eduhub/server/internal/helpers"
 	"eduhub/server/internal/models" // Import models package
+	"eduhub/server/internal/repository"
 
 	// "eduhub/server/internal/middleware" // Assuming validator is setup via middleware or directly
 	"eduhub/server/internal/services/attendance"
@@ -18,11 +23,19 @@ type AttendanceHandler struct {
 
 // BulkAttendanceRequest defines the structure for the bulk attendance marking endpoint.
 type BulkAttendanceRequest struct {
-	Attendances []models.StudentAttendanceStatus `json:"attendances" validate:"required,dive"` // Use dive for validating nested structs
+	Attendances []models.StudentAttendanceStatus `json:"attendances" validate:"required,dive"`               // Use dive for validating nested structs
+	Mode        string                           `json:"mode" validate:"omitempty,oneof=atomic best_effort"` // Defaults to atomic
+	Reason      string                           `json:"reason,omitempty"`                                   // Recorded in the attendance audit log
+}
+
+type UpdateAttendanceRequest struct {
+	Status string `json:"status" validate:"required"`
+	Reason string `json:"reason,omitempty"` // Recorded in the attendance audit log
 }
 
 type QRCodeRequest struct {
 	QRCodeData string `json:"qrcode_data"`
+	models.ScanMetadata
 }
 
 func NewAttendanceHandler(attendance attendance.AttendanceService) *AttendanceHandler {
@@ -68,42 +81,129 @@ func (a *AttendanceHandler) ProcessAttendance(c echo.Context) error {
 	if err := c.Bind(&qrcodeData); err != nil {
 		return helpers.Error(c, "invalid request body", 400)
 	}
-	err = a.attendanceService.ProcessQRCode(ctx, collegeID, studentId, qrcodeData.QRCodeData)
-	return err
+	actorID, err := helpers.ExtractIdentityID(c)
+	if err != nil {
+		return err
+	}
+	scan, err := a.attendanceService.ProcessQRCode(ctx, collegeID, studentId, qrcodeData.QRCodeData, qrcodeData.ScanMetadata, models.AuditInfo{ActorID: actorID})
+	if err != nil {
+		switch {
+		case errors.Is(err, attendance.ErrScanRejected), errors.Is(err, attendance.ErrAttendanceWindowClosed):
+			return helpers.Error(c, err.Error(), http.StatusForbidden)
+		case errors.Is(err, attendance.ErrInvalidQRCode), errors.Is(err, attendance.ErrQRCodeExpired),
+			errors.Is(err, repository.ErrQRCodeNotIssued):
+			return helpers.Error(c, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, repository.ErrQRCodeAlreadyRedeemed):
+			return helpers.Error(c, err.Error(), http.StatusConflict)
+		case errors.Is(err, attendance.ErrQRCodeCollegeDenied):
+			return helpers.Error(c, err.Error(), http.StatusForbidden)
+		default:
+			return helpers.Error(c, err.Error(), http.StatusInternalServerError)
+		}
+	}
+	return helpers.Success(c, scan, http.StatusOK)
 }
 
-func (a *AttendanceHandler) MarkAttendance(c echo.Context) error {
+func (a *AttendanceHandler) RegisterDevice(c echo.Context) error {
 	ctx := c.Request().Context()
-
 	collegeID, err := helpers.ExtractCollegeID(c)
 	if err != nil {
 		return err
 	}
+	studentID, err := helpers.ExtractStudentID(c)
+	if err != nil {
+		return err
+	}
+	var device models.StudentDevice
+	if err := c.Bind(&device); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+	if err := a.attendanceService.RegisterDevice(ctx, collegeID, studentID, &device); err != nil {
+		if errors.Is(err, repository.ErrDeviceTaken) || errors.Is(err, repository.ErrDeviceLimitReached) {
+			return helpers.Error(c, err.Error(), http.StatusConflict)
+		}
+		return helpers.Error(c, err.Error(), http.StatusBadRequest)
+	}
+	return helpers.Success(c, device, http.StatusCreated)
+}
 
+func (a *AttendanceHandler) GetMyDevices(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
 	studentID, err := helpers.ExtractStudentID(c)
 	if err != nil {
 		return err
 	}
+	devices, err := a.attendanceService.GetStudentDevices(ctx, collegeID, studentID)
+	if err != nil {
+		return helpers.Error(c, "unable to get devices", http.StatusInternalServerError)
+	}
+	return helpers.Success(c, devices, http.StatusOK)
+}
 
-	courseID, err := helpers.GetIDFromParam(c, "courseID")
+// RevokeMyDevice deactivates one of the signed-in student's devices
+func (a *AttendanceHandler) RevokeMyDevice(c echo.Context) error {
+	studentID, err := helpers.ExtractStudentID(c)
 	if err != nil {
 		return err
 	}
-	lectureID, err := helpers.GetIDFromParam(c, "lectureID")
+	return a.revokeDevice(c, studentID)
+}
+
+// RevokeStudentDevice deactivates a student's device, e.g. a lost phone
+func (a *AttendanceHandler) RevokeStudentDevice(c echo.Context) error {
+	studentID, err := helpers.GetIDFromParam(c, "studentID")
 	if err != nil {
 		return err
 	}
+	return a.revokeDevice(c, studentID)
+}
 
-	ok, err := a.attendanceService.MarkAttendance(ctx, collegeID, studentID, courseID, lectureID)
+func (a *AttendanceHandler) revokeDevice(c echo.Context, studentID int) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	deviceID, err := helpers.GetIDFromParam(c, "deviceID")
 	if err != nil {
-		return helpers.Error(c, err.Error(), http.StatusInternalServerError)
+		return err
+	}
+	if err := a.attendanceService.RevokeDevice(ctx, collegeID, studentID, deviceID); err != nil {
+		if errors.Is(err, repository.ErrDeviceNotFound) {
+			return helpers.Error(c, err.Error(), http.StatusNotFound)
+		}
+		return helpers.Error(c, "unable to revoke device", http.StatusInternalServerError)
 	}
+	return helpers.Success(c, "device revoked", http.StatusOK)
+}
 
-	if !ok {
-		return helpers.Error(c, "Failed to mark attendance", http.StatusInternalServerError)
+// GetLectureScans lists recorded QR scans for a lecture; ?flagged=true returns only rejected ones
+func (a *AttendanceHandler) GetLectureScans(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
 	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	lectureID, err := helpers.GetIDFromParam(c, "lectureID")
+	if err != nil {
+		return err
+	}
+	flaggedOnly := c.QueryParam("flagged") == "true"
+	limit, offset := helpers.GetPagination(c)
 
-	return helpers.Success(c, "attendance marked", http.StatusOK)
+	scans, err := a.attendanceService.GetLectureScans(ctx, collegeID, courseID, lectureID, flaggedOnly, limit, offset)
+	if err != nil {
+		return helpers.Error(c, "unable to get scans", http.StatusInternalServerError)
+	}
+	return helpers.Success(c, scans, http.StatusOK)
 }
 
 func (a *AttendanceHandler) GetAttendanceByCourse(c echo.Context) error {
@@ -119,7 +219,8 @@ func (a *AttendanceHandler) GetAttendanceByCourse(c echo.Context) error {
 		return helpers.Error(c, "Invalid course ID", http.StatusBadRequest)
 	}
 
-	attendance, err := a.attendanceService.GetAttendanceByCourse(ctx, collegeID, courseID)
+	limit, offset := helpers.GetPagination(c)
+	attendance, err := a.attendanceService.GetAttendanceByCourse(ctx, collegeID, courseID, limit, offset)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, err)
 	}
@@ -139,7 +240,8 @@ func (a *AttendanceHandler) GetAttendanceForStudent(c echo.Context) error {
 		return helpers.Error(c, "invalid studentID", 400)
 	}
 
-	attendance, err := a.attendanceService.GetAttendanceByStudent(ctx, collegeID, studentID)
+	limit, offset := helpers.GetPagination(c)
+	attendance, err := a.attendanceService.GetAttendanceByStudent(ctx, collegeID, studentID, limit, offset)
 	if err != nil {
 		return helpers.Error(c, "unable to get attendance by student", http.StatusInternalServerError)
 	}
@@ -163,7 +265,8 @@ func (a *AttendanceHandler) GetAttendanceByStudentAndCourse(c echo.Context) erro
 		return err
 	}
 
-	attendance, err := a.attendanceService.GetAttendanceByStudentAndCourse(ctx, collegeID, studentID, courseID)
+	limit, offset := helpers.GetPagination(c)
+	attendance, err := a.attendanceService.GetAttendanceByStudentAndCourse(ctx, collegeID, studentID, courseID, limit, offset)
 	if err != nil {
 		return helpers.Error(c, "unable to get attendance", http.StatusInternalServerError)
 	}
@@ -180,21 +283,31 @@ func (a *AttendanceHandler) UpdateAttendance(c echo.Context) error {
 	if err != nil {
 		return helpers.Error(c, "invalid lectureID", 400)
 	}
-	studentID, err := helpers.ExtractStudentID(c)
+	studentID, err := helpers.GetIDFromParam(c, "studentID")
 	if err != nil {
-		helpers.Error(c, "invalid studentID", 400)
+		return helpers.Error(c, "invalid studentID", 400)
 	}
 	courseID, err := helpers.GetIDFromParam(c, "courseID")
 	if err != nil {
 		return helpers.Error(c, "invalid courseID ", 400)
 	}
+	var req UpdateAttendanceRequest
+	if err := c.Bind(&req); err != nil {
+		return helpers.Error(c, "invalid request body", 400)
+	}
 
-	ok, err := a.attendanceService.UpdateAttendance(ctx, collegeID, studentID, courseID, lectureID)
-	if !ok {
-		return helpers.Error(c, "Unable update attendance", 500)
+	actorID, err := helpers.ExtractIdentityID(c)
+	if err != nil {
+		return err
 	}
+
+	audit := models.AuditInfo{ActorID: actorID, Reason: req.Reason}
+	ok, err := a.attendanceService.UpdateAttendanceStatus(ctx, collegeID, studentID, courseID, lectureID, req.Status, audit)
 	if err != nil {
-		return helpers.Error(c, "error in update attendance", 502)
+		return helpers.Error(c, "error in update attendance: "+err.Error(), 400)
+	}
+	if !ok {
+		return helpers.Error(c, "Unable update attendance", 500)
 	}
 
 	return helpers.Success(c, "Success", 200)
@@ -210,7 +323,11 @@ func (a *AttendanceHandler) FreezeAttendance(c echo.Context) error {
 	if err != nil {
 		return helpers.Error(c, "Invalid student ID", 400)
 	}
-	ok, _ := a.attendanceService.FreezeAttendance(ctx, collegeID, studentID)
+	actorID, err := helpers.ExtractIdentityID(c)
+	if err != nil {
+		return err
+	}
+	ok, _ := a.attendanceService.FreezeAttendance(ctx, collegeID, studentID, models.AuditInfo{ActorID: actorID})
 	if !ok {
 		return helpers.Error(c, "unable to freeze attendance", 500)
 	}
@@ -244,11 +361,229 @@ func (a *AttendanceHandler) MarkBulkAttendance(c echo.Context) error {
 	// 	 return helpers.Error(c, "Validation failed: "+err.Error(), http.StatusBadRequest)
 	// }
 
-	err = a.attendanceService.MarkBulkAttendance(ctx, collegeID, courseID, lectureID, req.Attendances)
+	if req.Mode != "" && req.Mode != models.BulkModeAtomic && req.Mode != models.BulkModeBestEffort {
+		return helpers.Error(c, "mode must be atomic or best_effort", http.StatusBadRequest)
+	}
+
+	actorID, err := helpers.ExtractIdentityID(c)
 	if err != nil {
-		// The service layer now returns a more descriptive error if needed
+		return err
+	}
+
+	audit := models.AuditInfo{ActorID: actorID, Reason: req.Reason}
+	results, err := a.attendanceService.MarkBulkAttendance(ctx, collegeID, courseID, lectureID, req.Attendances, req.Mode, audit)
+	switch {
+	case err == nil:
+		return helpers.Success(c, results, http.StatusOK)
+	case errors.Is(err, attendance.ErrLectureNotInCourse):
+		return helpers.Error(c, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, attendance.ErrBulkAttendanceIncomplete):
+		// best effort stored the valid rows; atomic stored nothing
+		if req.Mode == models.BulkModeBestEffort {
+			return helpers.Success(c, results, http.StatusMultiStatus)
+		}
+		return helpers.Success(c, results, http.StatusUnprocessableEntity)
+	case results != nil:
+		return helpers.Success(c, results, http.StatusInternalServerError)
+	default:
 		return helpers.Error(c, "Failed to mark bulk attendance: "+err.Error(), http.StatusInternalServerError)
 	}
+}
+
+// GetCourseAttendanceReport lists every enrolled student's attendance in a course
+// in ?term_id=, defaulting to the current term
+func (a *AttendanceHandler) GetCourseAttendanceReport(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetTermID(c)
+	if err != nil {
+		return err
+	}
+
+	report, err := a.attendanceService.GetCourseAttendanceReport(ctx, collegeID, courseID, termID)
+	if err != nil {
+		return helpers.Error(c, "unable to get attendance report", http.StatusInternalServerError)
+	}
+	return helpers.Success(c, report, http.StatusOK)
+}
+
+// GetAttendanceShortage lists students below ?threshold= percent (default 75) in a course
+// in ?term_id=, defaulting to the current term
+func (a *AttendanceHandler) GetAttendanceShortage(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetTermID(c)
+	if err != nil {
+		return err
+	}
+	threshold := attendance.DefaultShortageThreshold
+	if raw := c.QueryParam("threshold"); raw != "" {
+		threshold, err = strconv.ParseFloat(raw, 64)
+		if err != nil || threshold <= 0 || threshold > 100 {
+			return helpers.Error(c, "threshold must be a number between 0 and 100", http.StatusBadRequest)
+		}
+	}
+
+	report, err := a.attendanceService.GetAttendanceShortage(ctx, collegeID, courseID, termID, threshold)
+	if err != nil {
+		return helpers.Error(c, "unable to get attendance shortage", http.StatusInternalServerError)
+	}
+	return helpers.Success(c, report, http.StatusOK)
+}
+
+const exportDateLayout = "2006-01-02"
+
+// ExportAttendanceRegister streams the course register as CSV or XLSX.
+// Query params: format=csv|xlsx (default csv), from and to as YYYY-MM-DD, both inclusive,
+// and term_id for the students listed, defaulting to the current term.
+func (a *AttendanceHandler) ExportAttendanceRegister(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetTermID(c)
+	if err != nil {
+		return err
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = attendance.ExportFormatCSV
+	}
+	var contentType string
+	switch format {
+	case attendance.ExportFormatCSV:
+		contentType = "text/csv"
+	case attendance.ExportFormatXLSX:
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return helpers.Error(c, "format must be csv or xlsx", http.StatusBadRequest)
+	}
 
-	return helpers.Success(c, "Bulk attendance marked successfully", http.StatusOK)
+	var from, to time.Time
+	if raw := c.QueryParam("from"); raw != "" {
+		from, err = time.Parse(exportDateLayout, raw)
+		if err != nil {
+			return helpers.Error(c, "from must be a date in YYYY-MM-DD format", http.StatusBadRequest)
+		}
+	}
+	if raw := c.QueryParam("to"); raw != "" {
+		to, err = time.Parse(exportDateLayout, raw)
+		if err != nil {
+			return helpers.Error(c, "to must be a date in YYYY-MM-DD format", http.StatusBadRequest)
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return helpers.Error(c, "from must not be after to", http.StatusBadRequest)
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, contentType)
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"attendance-course-%d.%s\"", courseID, format))
+
+	err = a.attendanceService.ExportAttendanceRegister(ctx, collegeID, courseID, termID, from, to, format, res)
+	if err != nil {
+		if res.Committed {
+			// headers and part of the body are already sent; all we can do is stop
+			c.Logger().Errorf("attendance export for course %d aborted: %v", courseID, err)
+			return nil
+		}
+		res.Header().Del(echo.HeaderContentDisposition)
+		return helpers.Error(c, "unable to export attendance", http.StatusInternalServerError)
+	}
+	return nil
+}
+
+// GetAttendanceHistory returns every recorded change to one attendance record
+func (a *AttendanceHandler) GetAttendanceHistory(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	attendanceID, err := helpers.GetIDFromParam(c, "attendanceID")
+	if err != nil {
+		return err
+	}
+
+	history, err := a.attendanceService.GetAttendanceHistory(ctx, collegeID, attendanceID)
+	if err != nil {
+		return helpers.Error(c, "unable to get attendance history", http.StatusInternalServerError)
+	}
+	return helpers.Success(c, history, http.StatusOK)
+}
+
+// GetLectureAttendanceHistory returns every recorded attendance change for a lecture
+func (a *AttendanceHandler) GetLectureAttendanceHistory(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	lectureID, err := helpers.GetIDFromParam(c, "lectureID")
+	if err != nil {
+		return err
+	}
+	limit, offset := helpers.GetPagination(c)
+
+	history, err := a.attendanceService.GetLectureAttendanceHistory(ctx, collegeID, courseID, lectureID, limit, offset)
+	if err != nil {
+		return helpers.Error(c, "unable to get attendance history", http.StatusInternalServerError)
+	}
+	return helpers.Success(c, history, http.StatusOK)
+}
+
+// GetAttendancePolicy returns the college's late-arrival policy, or the defaults
+func (a *AttendanceHandler) GetAttendancePolicy(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	policy, err := a.attendanceService.GetAttendancePolicy(ctx, collegeID)
+	if err != nil {
+		return helpers.Error(c, "unable to get attendance policy", http.StatusInternalServerError)
+	}
+	return helpers.Success(c, policy, http.StatusOK)
+}
+
+func (a *AttendanceHandler) UpdateAttendancePolicy(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	var policy models.AttendancePolicy
+	if err := c.Bind(&policy); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+	policy.CollegeID = collegeID
+	if err := a.attendanceService.UpdateAttendancePolicy(ctx, &policy); err != nil {
+		return helpers.Error(c, err.Error(), http.StatusBadRequest)
+	}
+	return helpers.Success(c, policy, http.StatusOK)
 }
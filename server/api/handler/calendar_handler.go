@@ -0,0 +1,340 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"eduhub/server/internal/helpers"
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+	"eduhub/server/internal/services/calendar"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// maxCalendarImportBytes caps the size of an uploaded iCalendar file.
+const maxCalendarImportBytes = 2 << 20
+
+type CalendarHandler struct {
+	calendarService calendar.CalendarService
+	feedService     calendar.CalendarFeedService
+}
+
+// CalendarEventRequest creates or updates a calendar event. All-day events
+// give Date and, for several days, EndDate as YYYY-MM-DD; timed events give
+// StartTime and EndTime in RFC 3339 instead. CourseID limits the event to
+// one course.
+type CalendarEventRequest struct {
+	Title       string                   `json:"title"`
+	Description string                   `json:"description"`
+	EventType   models.CalendarEventType `json:"event_type"`
+	CourseID    *int                     `json:"course_id,omitempty"`
+	Date        string                   `json:"date,omitempty"`
+	EndDate     string                   `json:"end_date,omitempty"`
+	StartTime   *time.Time               `json:"start_time,omitempty"`
+	EndTime     *time.Time               `json:"end_time,omitempty"`
+}
+
+// CalendarEventList is one page of calendar events along with their total.
+type CalendarEventList struct {
+	Events []*models.CalendarBlock `json:"events"`
+	Total  int                     `json:"total"`
+	Limit  uint64                  `json:"limit"`
+	Offset uint64                  `json:"offset"`
+}
+
+func NewCalendarHandler(calendarService calendar.CalendarService, feedService calendar.CalendarFeedService) *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: calendarService,
+		feedService:     feedService,
+	}
+}
+
+// GetEvents lists events overlapping ?from= to ?to= (YYYY-MM-DD), optionally
+// of one ?type= and for one ?course_id= along with college-wide events.
+func (h *CalendarHandler) GetEvents(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	limit, offset := helpers.GetPagination(c)
+
+	filter := models.CalendarBlockFilter{CollegeID: &collegeID, Limit: limit, Offset: offset}
+	if from := c.QueryParam("from"); from != "" {
+		d, err := time.Parse(time.DateOnly, from)
+		if err != nil {
+			return helpers.Error(c, "from must be YYYY-MM-DD", http.StatusBadRequest)
+		}
+		filter.StartDate = &d
+	}
+	if to := c.QueryParam("to"); to != "" {
+		d, err := time.Parse(time.DateOnly, to)
+		if err != nil {
+			return helpers.Error(c, "to must be YYYY-MM-DD", http.StatusBadRequest)
+		}
+		filter.EndDate = &d
+	}
+	if eventType := c.QueryParam("type"); eventType != "" {
+		t := models.CalendarEventType(eventType)
+		filter.EventType = &t
+	}
+	if courseID := c.QueryParam("course_id"); courseID != "" {
+		id, err := strconv.Atoi(courseID)
+		if err != nil {
+			return helpers.Error(c, "invalid course_id", http.StatusBadRequest)
+		}
+		filter.CourseID = &id
+	}
+
+	events, err := h.calendarService.GetEvents(ctx, filter)
+	if err != nil {
+		return helpers.Error(c, "unable to get calendar events", http.StatusInternalServerError)
+	}
+	total, err := h.calendarService.CountEvents(ctx, filter)
+	if err != nil {
+		return helpers.Error(c, "unable to get calendar events", http.StatusInternalServerError)
+	}
+	return helpers.Success(c, CalendarEventList{Events: events, Total: total, Limit: limit, Offset: offset}, http.StatusOK)
+}
+
+func (h *CalendarHandler) GetEvent(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	eventID, err := helpers.GetIDFromParam(c, "eventID")
+	if err != nil {
+		return err
+	}
+
+	event, err := h.calendarService.GetEvent(ctx, collegeID, eventID)
+	if err != nil {
+		return calendarError(c, err)
+	}
+	return helpers.Success(c, event, http.StatusOK)
+}
+
+func (h *CalendarHandler) CreateEvent(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+
+	event, err := bindCalendarEvent(c)
+	if err != nil {
+		return err
+	}
+	event.CollegeID = collegeID
+
+	if err := h.calendarService.CreateEvent(ctx, event); err != nil {
+		return calendarError(c, err)
+	}
+	return helpers.Success(c, event, http.StatusCreated)
+}
+
+func (h *CalendarHandler) UpdateEvent(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	eventID, err := helpers.GetIDFromParam(c, "eventID")
+	if err != nil {
+		return err
+	}
+
+	event, err := bindCalendarEvent(c)
+	if err != nil {
+		return err
+	}
+	event.ID, event.CollegeID = eventID, collegeID
+
+	if err := h.calendarService.UpdateEvent(ctx, event); err != nil {
+		return calendarError(c, err)
+	}
+	updated, err := h.calendarService.GetEvent(ctx, collegeID, eventID)
+	if err != nil {
+		return calendarError(c, err)
+	}
+	return helpers.Success(c, updated, http.StatusOK)
+}
+
+func (h *CalendarHandler) DeleteEvent(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	eventID, err := helpers.GetIDFromParam(c, "eventID")
+	if err != nil {
+		return err
+	}
+
+	if err := h.calendarService.DeleteEvent(ctx, collegeID, eventID); err != nil {
+		return calendarError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CreateFeed issues the user a new iCalendar subscription URL, revoking the
+// previous one. The URL is only shown in this response.
+func (h *CalendarHandler) CreateFeed(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	identityID, err := helpers.ExtractIdentityID(c)
+	if err != nil {
+		return err
+	}
+	role, err := helpers.GetUserRole(c)
+	if err != nil {
+		return err
+	}
+
+	feed, err := h.feedService.CreateFeed(ctx, collegeID, identityID, role)
+	if err != nil {
+		return calendarError(c, err)
+	}
+	feed.URL = c.Scheme() + "://" + c.Request().Host + "/ical/" + feed.Token + ".ics"
+	return helpers.Success(c, feed, http.StatusCreated)
+}
+
+func (h *CalendarHandler) GetFeed(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	identityID, err := helpers.ExtractIdentityID(c)
+	if err != nil {
+		return err
+	}
+
+	feed, err := h.feedService.GetFeed(ctx, collegeID, identityID)
+	if err != nil {
+		return calendarError(c, err)
+	}
+	return helpers.Success(c, feed, http.StatusOK)
+}
+
+func (h *CalendarHandler) RevokeFeed(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	identityID, err := helpers.ExtractIdentityID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.feedService.RevokeFeed(ctx, collegeID, identityID); err != nil {
+		return calendarError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ServeFeed serves /ical/<token>.ics to calendar clients, which cannot log
+// in; the token is the credential.
+func (h *CalendarHandler) ServeFeed(c echo.Context) error {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	cal, err := h.feedService.FeedCalendar(c.Request().Context(), token)
+	if err != nil {
+		if errors.Is(err, repository.ErrCalendarFeedNotFound) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/calendar; charset=utf-8")
+	res.Header().Set(echo.HeaderContentDisposition, `inline; filename="eduhub.ics"`)
+	res.Header().Set("Cache-Control", "private, max-age=900")
+	res.WriteHeader(http.StatusOK)
+	return cal.Encode(res)
+}
+
+// ImportHolidays reads an iCalendar file, sent as the request body or as the
+// "file" field of a multipart form, and adds its events as holidays.
+func (h *CalendarHandler) ImportHolidays(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader = c.Request().Body
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			return helpers.Error(c, "unable to read uploaded file", http.StatusBadRequest)
+		}
+		defer f.Close()
+		body = f
+	}
+
+	result, err := h.calendarService.ImportHolidays(ctx, collegeID, io.LimitReader(body, maxCalendarImportBytes))
+	if err != nil {
+		return calendarError(c, err)
+	}
+	return helpers.Success(c, result, http.StatusOK)
+}
+
+func bindCalendarEvent(c echo.Context) (*models.CalendarBlock, error) {
+	var body CalendarEventRequest
+	if err := c.Bind(&body); err != nil {
+		return nil, helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+	event := &models.CalendarBlock{
+		Title:       body.Title,
+		Description: body.Description,
+		EventType:   body.EventType,
+		CourseID:    body.CourseID,
+		StartTime:   body.StartTime,
+		EndTime:     body.EndTime,
+	}
+
+	var err error
+	if body.Date != "" {
+		if event.Date, err = time.Parse(time.DateOnly, body.Date); err != nil {
+			return nil, helpers.Error(c, "date must be YYYY-MM-DD", http.StatusBadRequest)
+		}
+	}
+	if body.EndDate != "" {
+		if event.EndDate, err = time.Parse(time.DateOnly, body.EndDate); err != nil {
+			return nil, helpers.Error(c, "end_date must be YYYY-MM-DD", http.StatusBadRequest)
+		}
+	}
+	return event, nil
+}
+
+func calendarError(c echo.Context, err error) error {
+	var (
+		conflict *calendar.CalendarConflictError
+		invalid  validator.ValidationErrors
+	)
+	switch {
+	case errors.As(err, &conflict):
+		return helpers.Error(c, conflict, http.StatusConflict)
+	case errors.As(err, &invalid):
+		return helpers.Error(c, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrCalendarBlockNotFound), errors.Is(err, repository.ErrCourseNotFound),
+		errors.Is(err, repository.ErrCalendarFeedNotFound), errors.Is(err, calendar.ErrNoStudentProfile):
+		return helpers.Error(c, err.Error(), http.StatusNotFound)
+	case errors.Is(err, calendar.ErrInvalidEventDates), errors.Is(err, calendar.ErrInvalidEventTimes),
+		errors.Is(err, calendar.ErrInvalidCalendarFile):
+		return helpers.Error(c, err.Error(), http.StatusBadRequest)
+	default:
+		return helpers.Error(c, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,298 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"eduhub/server/internal/helpers"
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+	"eduhub/server/internal/services/course"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CourseHandler struct {
+	courseService course.CourseService
+}
+
+// CourseRequest is the body for creating or updating a course.
+type CourseRequest struct {
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Credits      int    `json:"credits"`
+	InstructorID int    `json:"instructor_id"`
+	Capacity     *int   `json:"capacity,omitempty"`      // Omit for unlimited seats
+	DepartmentID *int   `json:"department_id,omitempty"` // Its head approves the course's results
+}
+
+type PrerequisiteRequest struct {
+	PrerequisiteID int      `json:"prerequisite_id"`
+	MinPercentage  *float64 `json:"min_percentage,omitempty"` // Omit for the college's pass mark
+}
+
+type AssignInstructorRequest struct {
+	InstructorID int `json:"instructor_id"`
+}
+
+// CourseList is one page of courses along with the total number that match.
+type CourseList struct {
+	Courses []*models.Course `json:"courses"`
+	Total   int              `json:"total"`
+	Limit   uint64           `json:"limit"`
+	Offset  uint64           `json:"offset"`
+}
+
+func NewCourseHandler(courseService course.CourseService) *CourseHandler {
+	return &CourseHandler{
+		courseService: courseService,
+	}
+}
+
+// ListCourses pages through the college's courses. ?search= matches names and
+// descriptions; ?instructor_id= lists one instructor's courses.
+func (h *CourseHandler) ListCourses(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	limit, offset := helpers.GetPagination(c)
+	search := c.QueryParam("search")
+
+	var (
+		courses []*models.Course
+		total   int
+	)
+	switch {
+	case c.QueryParam("instructor_id") != "":
+		instructorID, err := strconv.Atoi(c.QueryParam("instructor_id"))
+		if err != nil {
+			return helpers.Error(c, "invalid instructor_id", http.StatusBadRequest)
+		}
+		if courses, err = h.courseService.FindCoursesByInstructor(ctx, collegeID, instructorID, limit, offset); err == nil {
+			total, err = h.courseService.CountCoursesByInstructor(ctx, collegeID, instructorID)
+		}
+		if err != nil {
+			return helpers.Error(c, "unable to get courses", http.StatusInternalServerError)
+		}
+	case search != "":
+		if courses, err = h.courseService.SearchCourses(ctx, collegeID, search, limit, offset); err == nil {
+			total, err = h.courseService.CountSearchCourses(ctx, collegeID, search)
+		}
+		if err != nil {
+			return helpers.Error(c, "unable to get courses", http.StatusInternalServerError)
+		}
+	default:
+		if courses, err = h.courseService.FindAllCourses(ctx, collegeID, limit, offset); err == nil {
+			total, err = h.courseService.CountCoursesByCollege(ctx, collegeID)
+		}
+		if err != nil {
+			return helpers.Error(c, "unable to get courses", http.StatusInternalServerError)
+		}
+	}
+
+	return helpers.Success(c, CourseList{Courses: courses, Total: total, Limit: limit, Offset: offset}, http.StatusOK)
+}
+
+func (h *CourseHandler) CreateCourse(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	var body CourseRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+
+	crs := &models.Course{
+		CollegeID:    collegeID,
+		Name:         body.Name,
+		Description:  body.Description,
+		Credits:      body.Credits,
+		InstructorID: body.InstructorID,
+		Capacity:     body.Capacity,
+		DepartmentID: body.DepartmentID,
+	}
+	if err := h.courseService.CreateCourse(ctx, crs); err != nil {
+		return courseError(c, err)
+	}
+	return helpers.Success(c, crs, http.StatusCreated)
+}
+
+func (h *CourseHandler) GetCourse(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+
+	crs, err := h.courseService.FindCourseByID(ctx, collegeID, courseID)
+	if err != nil {
+		return courseError(c, err)
+	}
+	return helpers.Success(c, crs, http.StatusOK)
+}
+
+func (h *CourseHandler) UpdateCourse(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	var body CourseRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+
+	crs := &models.Course{
+		ID:           courseID,
+		CollegeID:    collegeID,
+		Name:         body.Name,
+		Description:  body.Description,
+		Credits:      body.Credits,
+		InstructorID: body.InstructorID,
+		Capacity:     body.Capacity,
+		DepartmentID: body.DepartmentID,
+	}
+	if err := h.courseService.UpdateCourse(ctx, courseID, crs); err != nil {
+		return courseError(c, err)
+	}
+	updated, err := h.courseService.FindCourseByID(ctx, collegeID, courseID)
+	if err != nil {
+		return courseError(c, err)
+	}
+	return helpers.Success(c, updated, http.StatusOK)
+}
+
+func (h *CourseHandler) DeleteCourse(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+
+	if err := h.courseService.DeleteCourse(ctx, collegeID, courseID); err != nil {
+		return courseError(c, err)
+	}
+	return helpers.Success(c, "course deleted", http.StatusOK)
+}
+
+func (h *CourseHandler) AssignInstructor(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	var body AssignInstructorRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+
+	crs, err := h.courseService.AssignInstructor(ctx, collegeID, courseID, body.InstructorID)
+	if err != nil {
+		return courseError(c, err)
+	}
+	return helpers.Success(c, crs, http.StatusOK)
+}
+
+func (h *CourseHandler) GetPrerequisites(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+
+	prerequisites, err := h.courseService.GetPrerequisites(ctx, collegeID, courseID)
+	if err != nil {
+		return courseError(c, err)
+	}
+	return helpers.Success(c, prerequisites, http.StatusOK)
+}
+
+// AddPrerequisite makes the body's course a prerequisite of :courseID, or
+// updates its minimum if it already is one.
+func (h *CourseHandler) AddPrerequisite(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	var body PrerequisiteRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+
+	prerequisite := &models.CoursePrerequisite{
+		CourseID:       courseID,
+		PrerequisiteID: body.PrerequisiteID,
+		CollegeID:      collegeID,
+		MinPercentage:  body.MinPercentage,
+	}
+	if err := h.courseService.AddPrerequisite(ctx, prerequisite); err != nil {
+		return courseError(c, err)
+	}
+	return helpers.Success(c, prerequisite, http.StatusCreated)
+}
+
+func (h *CourseHandler) RemovePrerequisite(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	prerequisiteID, err := helpers.GetIDFromParam(c, "prerequisiteID")
+	if err != nil {
+		return err
+	}
+
+	if err := h.courseService.RemovePrerequisite(ctx, collegeID, courseID, prerequisiteID); err != nil {
+		return courseError(c, err)
+	}
+	return helpers.Success(c, "prerequisite removed", http.StatusOK)
+}
+
+func courseError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrCourseNotFound), errors.Is(err, repository.ErrPrerequisiteNotFound):
+		return helpers.Error(c, err.Error(), http.StatusNotFound)
+	case errors.Is(err, repository.ErrPrerequisiteCycle):
+		return helpers.Error(c, err.Error(), http.StatusConflict)
+	case errors.Is(err, course.ErrNotFaculty):
+		return helpers.Error(c, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, course.ErrInstructorChange):
+		return helpers.Error(c, err.Error(), http.StatusForbidden)
+	default:
+		return helpers.Error(c, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"eduhub/server/internal/helpers"
+	"eduhub/server/internal/middleware"
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+	"eduhub/server/internal/services/enrollment"
+
+	"github.com/labstack/echo/v4"
+)
+
+type EnrollmentHandler struct {
+	enrollmentService enrollment.EnrollmentService
+}
+
+// EnrollStudentsRequest names the students to enroll, by ID, roll number or
+// both. Admins may set Override to enroll students with unmet prerequisites.
+type EnrollStudentsRequest struct {
+	StudentIDs []int            `json:"student_ids,omitempty"`
+	RollNos    []string         `json:"roll_nos,omitempty"`
+	Override   *OverrideRequest `json:"override,omitempty"`
+}
+
+type OverrideRequest struct {
+	Reason string `json:"reason"`
+}
+
+// DropResult reports which waitlisted students took the freed seat.
+type DropResult struct {
+	PromotedStudentIDs []int `json:"promoted_student_ids"`
+}
+
+func NewEnrollmentHandler(enrollmentService enrollment.EnrollmentService) *EnrollmentHandler {
+	return &EnrollmentHandler{
+		enrollmentService: enrollmentService,
+	}
+}
+
+func (h *EnrollmentHandler) EnrollStudents(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	var body EnrollStudentsRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+	var override *models.EnrollmentOverride
+	if body.Override != nil {
+		role, err := helpers.GetUserRole(c)
+		if err != nil {
+			return err
+		}
+		if role != middleware.RoleAdmin {
+			return helpers.Error(c, "only admins can override prerequisites", http.StatusForbidden)
+		}
+		adminID, err := helpers.ExtractIdentityID(c)
+		if err != nil {
+			return err
+		}
+		override = &models.EnrollmentOverride{OverriddenBy: adminID, Reason: body.Override.Reason}
+	}
+
+	results, err := h.enrollmentService.EnrollStudents(ctx, collegeID, courseID, body.StudentIDs, body.RollNos, override)
+	if err != nil {
+		return enrollmentError(c, err)
+	}
+	return helpers.Success(c, results, http.StatusOK)
+}
+
+// ListEnrolledStudents lists the course's students with their records in
+// ?term_id=, defaulting to the current term; ?status= filters by enrollment
+// status, and ?status=waitlisted returns the waitlist in promotion order.
+func (h *EnrollmentHandler) ListEnrolledStudents(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetTermID(c)
+	if err != nil {
+		return err
+	}
+	limit, offset := helpers.GetPagination(c)
+
+	roster, err := h.enrollmentService.GetCourseRoster(ctx, collegeID, courseID, termID, c.QueryParam("status"), limit, offset)
+	if err != nil {
+		return enrollmentError(c, err)
+	}
+	return helpers.Success(c, roster, http.StatusOK)
+}
+
+// CheckEligibility lists the signed-in student's unmet prerequisites for the course.
+func (h *EnrollmentHandler) CheckEligibility(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	studentID, err := helpers.ExtractStudentID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+
+	unmet, err := h.enrollmentService.CheckEligibility(ctx, collegeID, courseID, studentID)
+	if err != nil {
+		return enrollmentError(c, err)
+	}
+	return helpers.Success(c, map[string]any{"eligible": len(unmet) == 0, "unmet": unmet}, http.StatusOK)
+}
+
+func (h *EnrollmentHandler) GetEnrollmentOverrides(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	limit, offset := helpers.GetPagination(c)
+
+	overrides, err := h.enrollmentService.GetEnrollmentOverrides(ctx, collegeID, courseID, limit, offset)
+	if err != nil {
+		return enrollmentError(c, err)
+	}
+	return helpers.Success(c, overrides, http.StatusOK)
+}
+
+// RemoveStudent drops a student from the course on behalf of staff.
+func (h *EnrollmentHandler) RemoveStudent(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	studentID, err := helpers.GetIDFromParam(c, "studentID")
+	if err != nil {
+		return err
+	}
+
+	promoted, err := h.enrollmentService.DropStudent(ctx, collegeID, courseID, studentID)
+	if err != nil {
+		return enrollmentError(c, err)
+	}
+	return helpers.Success(c, DropResult{PromotedStudentIDs: promoted}, http.StatusOK)
+}
+
+// DropCourse drops the signed-in student from the course.
+func (h *EnrollmentHandler) DropCourse(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	studentID, err := helpers.ExtractStudentID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.enrollmentService.DropStudent(ctx, collegeID, courseID, studentID); err != nil {
+		return enrollmentError(c, err)
+	}
+	return helpers.Success(c, "course dropped", http.StatusOK)
+}
+
+func enrollmentError(c echo.Context, err error) error {
+	var ineligible *enrollment.IneligibleError
+	switch {
+	case errors.As(err, &ineligible):
+		return helpers.Error(c, ineligible, http.StatusUnprocessableEntity)
+	case errors.Is(err, repository.ErrCourseNotFound), errors.Is(err, repository.ErrEnrollmentNotFound):
+		return helpers.Error(c, err.Error(), http.StatusNotFound)
+	case errors.Is(err, enrollment.ErrNoStudents), errors.Is(err, enrollment.ErrTooManyStudents), errors.Is(err, enrollment.ErrInvalidStatus):
+		return helpers.Error(c, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, enrollment.ErrRegistrationClosed):
+		return helpers.Error(c, err.Error(), http.StatusConflict)
+	default:
+		return helpers.Error(c, err.Error(), http.StatusInternalServerError)
+	}
+}
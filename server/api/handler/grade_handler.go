@@ -0,0 +1,857 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"eduhub/server/internal/helpers"
+	"eduhub/server/internal/middleware"
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+	"eduhub/server/internal/services/grades"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// PublicationRequest moves a course's results in a term, defaulting to the
+// current term, a step towards publication. Returning them needs a reason.
+type PublicationRequest struct {
+	TermID *int   `json:"term_id,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ReevaluationRequest asks for a published grade to be marked again.
+type ReevaluationRequest struct {
+	GradeID int    `json:"grade_id"`
+	Reason  string `json:"reason"`
+}
+
+type GradeHandler struct {
+	gradeService grades.GradeServices
+}
+
+// AssessmentRequest creates or updates an assessment. ExamType and TermID
+// are fixed once the assessment exists; TermID defaults to the current term.
+type AssessmentRequest struct {
+	Name     string          `json:"name"`
+	ExamType models.ExamType `json:"exam_type"`
+	Weight   float64         `json:"weight"`
+	MaxMarks float64         `json:"max_marks"`
+	TermID   *int            `json:"term_id,omitempty"`
+}
+
+// SubmitScoresRequest gives students' marks in an assessment.
+type SubmitScoresRequest struct {
+	Scores []models.AssessmentScore `json:"scores"`
+}
+
+// GradingScaleRequest creates or updates a grading scale.
+type GradingScaleRequest struct {
+	Name           string               `json:"name"`
+	Method         models.GradingMethod `json:"method"`
+	PassPercentage float64              `json:"pass_percentage"`
+	Bands          []models.GradeBand   `json:"bands"`
+	IsDefault      bool                 `json:"is_default"`
+}
+
+// UnlockCurveRequest unlocks a course's grades in a term, defaulting to the
+// current term.
+type UnlockCurveRequest struct {
+	TermID *int   `json:"term_id,omitempty"`
+	Reason string `json:"reason"`
+}
+
+func NewGradeHandler(gradeService grades.GradeServices) *GradeHandler {
+	return &GradeHandler{
+		gradeService: gradeService,
+	}
+}
+
+// GetAssessments lists the course's assessments in ?term_id=, defaulting to
+// the current term.
+func (h *GradeHandler) GetAssessments(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetTermID(c)
+	if err != nil {
+		return err
+	}
+
+	assessments, err := h.gradeService.GetAssessments(ctx, collegeID, courseID, termID)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, assessments, http.StatusOK)
+}
+
+func (h *GradeHandler) CreateAssessment(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	var body AssessmentRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+
+	assessment := &models.Assessment{
+		CollegeID: collegeID,
+		CourseID:  courseID,
+		TermID:    body.TermID,
+		Name:      body.Name,
+		ExamType:  body.ExamType,
+		Weight:    body.Weight,
+		MaxMarks:  body.MaxMarks,
+	}
+	if err := h.gradeService.CreateAssessment(ctx, assessment); err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, assessment, http.StatusCreated)
+}
+
+func (h *GradeHandler) UpdateAssessment(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	assessmentID, err := helpers.GetIDFromParam(c, "assessmentID")
+	if err != nil {
+		return err
+	}
+	var body AssessmentRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+
+	assessment := &models.Assessment{
+		ID:        assessmentID,
+		CollegeID: collegeID,
+		CourseID:  courseID,
+		Name:      body.Name,
+		Weight:    body.Weight,
+		MaxMarks:  body.MaxMarks,
+	}
+	if err := h.gradeService.UpdateAssessment(ctx, assessment); err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, assessment, http.StatusOK)
+}
+
+func (h *GradeHandler) DeleteAssessment(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	assessmentID, err := helpers.GetIDFromParam(c, "assessmentID")
+	if err != nil {
+		return err
+	}
+
+	if err := h.gradeService.DeleteAssessment(ctx, collegeID, courseID, assessmentID); err != nil {
+		return gradeError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SubmitScores stores the marks of every listed student, or of none if any
+// is refused.
+func (h *GradeHandler) SubmitScores(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	assessmentID, err := helpers.GetIDFromParam(c, "assessmentID")
+	if err != nil {
+		return err
+	}
+	var body SubmitScoresRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+	if len(body.Scores) == 0 {
+		return helpers.Error(c, "scores must not be empty", http.StatusBadRequest)
+	}
+
+	saved, err := h.gradeService.SubmitScores(ctx, collegeID, courseID, assessmentID, body.Scores)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, saved, http.StatusOK)
+}
+
+// GetGradesByCourse pages through the weighted totals of the course's
+// students in ?term_id=, defaulting to the current term.
+func (h *GradeHandler) GetGradesByCourse(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetTermID(c)
+	if err != nil {
+		return err
+	}
+	limit, offset := helpers.GetPagination(c)
+
+	book, err := h.gradeService.GetCourseGrades(ctx, collegeID, courseID, termID, limit, offset)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, book, http.StatusOK)
+}
+
+func (h *GradeHandler) GetStudentGrades(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	studentID, err := helpers.GetIDFromParam(c, "studentID")
+	if err != nil {
+		return err
+	}
+	publishedOnly, err := studentView(c)
+	if err != nil {
+		return err
+	}
+	limit, offset := helpers.GetPagination(c)
+
+	courseGrades, err := h.gradeService.GetStudentGrades(ctx, collegeID, studentID, publishedOnly, limit, offset)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, courseGrades, http.StatusOK)
+}
+
+// GetGrades lists the college's grades, each with the student's roll number
+// and name and the course's name, for result sheets. ?course_id=,
+// ?student_id=, ?assessment_id=, ?exam_type= and ?term_id= filter them; the
+// term defaults to the current one unless an assessment is given.
+func (h *GradeHandler) GetGrades(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetTermID(c)
+	if err != nil {
+		return err
+	}
+	filter, err := gradeFilter(c, collegeID, termID)
+	if err != nil {
+		return helpers.Error(c, err.Error(), http.StatusBadRequest)
+	}
+	if raw := c.QueryParam("student_id"); raw != "" {
+		studentID, err := strconv.Atoi(raw)
+		if err != nil || studentID <= 0 {
+			return helpers.Error(c, "invalid student_id", http.StatusBadRequest)
+		}
+		filter.StudentID = &studentID
+	}
+
+	rows, err := h.gradeService.GetGradeRows(ctx, filter)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, rows, http.StatusOK)
+}
+
+// GetStudentScores lists the student's marks with the course names, filtered
+// like GetGrades.
+func (h *GradeHandler) GetStudentScores(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	studentID, err := helpers.GetIDFromParam(c, "studentID")
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetTermID(c)
+	if err != nil {
+		return err
+	}
+	filter, err := gradeFilter(c, collegeID, termID)
+	if err != nil {
+		return helpers.Error(c, err.Error(), http.StatusBadRequest)
+	}
+	filter.StudentID = &studentID
+	if filter.PublishedOnly, err = studentView(c); err != nil {
+		return err
+	}
+
+	rows, err := h.gradeService.GetGradeRows(ctx, filter)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, rows, http.StatusOK)
+}
+
+func (h *GradeHandler) GetGradingScales(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+
+	scales, err := h.gradeService.GetGradingScales(ctx, collegeID)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, scales, http.StatusOK)
+}
+
+func (h *GradeHandler) CreateGradingScale(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	var body GradingScaleRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+
+	scale := body.scale(collegeID)
+	if err := h.gradeService.CreateGradingScale(ctx, scale); err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, scale, http.StatusCreated)
+}
+
+func (h *GradeHandler) UpdateGradingScale(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	scaleID, err := helpers.GetIDFromParam(c, "scaleID")
+	if err != nil {
+		return err
+	}
+	var body GradingScaleRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+
+	scale := body.scale(collegeID)
+	scale.ID = scaleID
+	if err := h.gradeService.UpdateGradingScale(ctx, scale); err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, scale, http.StatusOK)
+}
+
+func (h *GradeHandler) DeleteGradingScale(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	scaleID, err := helpers.GetIDFromParam(c, "scaleID")
+	if err != nil {
+		return err
+	}
+
+	if err := h.gradeService.DeleteGradingScale(ctx, collegeID, scaleID); err != nil {
+		return gradeError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ComputeTermResults grades the courses of ?term_id=, defaulting to the
+// current term, and updates the GPA of their students.
+func (h *GradeHandler) ComputeTermResults(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetTermID(c)
+	if err != nil {
+		return err
+	}
+
+	report, err := h.gradeService.ComputeTermResults(ctx, collegeID, termID)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, report, http.StatusOK)
+}
+
+func (h *GradeHandler) GetStudentGPA(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	studentID, err := helpers.GetIDFromParam(c, "studentID")
+	if err != nil {
+		return err
+	}
+	publishedOnly, err := studentView(c)
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := h.gradeService.GetStudentGPA(ctx, collegeID, studentID, publishedOnly)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, snapshots, http.StatusOK)
+}
+
+// GetTranscript returns the student's transcript as JSON, or as a PDF
+// download with ?format=pdf.
+func (h *GradeHandler) GetTranscript(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	studentID, err := helpers.GetIDFromParam(c, "studentID")
+	if err != nil {
+		return err
+	}
+	format := c.QueryParam("format")
+	if format != "" && format != "json" && format != "pdf" {
+		return helpers.Error(c, "format must be json or pdf", http.StatusBadRequest)
+	}
+	publishedOnly, err := studentView(c)
+	if err != nil {
+		return err
+	}
+
+	transcript, err := h.gradeService.GetTranscript(ctx, collegeID, studentID, publishedOnly)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	if format != "pdf" {
+		return helpers.Success(c, transcript, http.StatusOK)
+	}
+
+	var buf bytes.Buffer
+	if err := grades.WriteTranscriptPDF(&buf, transcript); err != nil {
+		return helpers.Error(c, "unable to render transcript", http.StatusInternalServerError)
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"transcript-%s.pdf\"", transcript.RollNo))
+	return c.Blob(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// PreviewCurve shows the letters a curve would give the course's students
+// without saving anything.
+func (h *GradeHandler) PreviewCurve(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	var curve models.Curve
+	if err := c.Bind(&curve); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+
+	preview, err := h.gradeService.PreviewCurve(ctx, collegeID, courseID, &curve)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, preview, http.StatusOK)
+}
+
+// CommitCurve grades the course on a curve and locks its grades.
+func (h *GradeHandler) CommitCurve(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	identityID, err := helpers.ExtractIdentityID(c)
+	if err != nil {
+		return err
+	}
+	var curve models.Curve
+	if err := c.Bind(&curve); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+
+	committed, err := h.gradeService.CommitCurve(ctx, collegeID, courseID, &curve, identityID)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, committed, http.StatusCreated)
+}
+
+// GetCurves lists the course's committed curve versions in ?term_id=,
+// defaulting to the current term.
+func (h *GradeHandler) GetCurves(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetTermID(c)
+	if err != nil {
+		return err
+	}
+
+	curves, err := h.gradeService.GetCurves(ctx, collegeID, courseID, termID)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, curves, http.StatusOK)
+}
+
+func (h *GradeHandler) UnlockCurve(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	identityID, err := helpers.ExtractIdentityID(c)
+	if err != nil {
+		return err
+	}
+	var body UnlockCurveRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+
+	curve, err := h.gradeService.UnlockCurve(ctx, collegeID, courseID, body.TermID, identityID, body.Reason)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, curve, http.StatusOK)
+}
+
+// GetPublication returns where the course's results in ?term_id= stand.
+func (h *GradeHandler) GetPublication(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetTermID(c)
+	if err != nil {
+		return err
+	}
+
+	pub, err := h.gradeService.GetPublication(ctx, collegeID, courseID, termID)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, pub, http.StatusOK)
+}
+
+// GetPublications lists the publications in ?term_id=, with ?status= if
+// given, e.g. the results waiting for approval.
+func (h *GradeHandler) GetPublications(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetTermID(c)
+	if err != nil {
+		return err
+	}
+	status := models.PublicationStatus(c.QueryParam("status"))
+	switch status {
+	case "", models.ResultsDraft, models.ResultsSubmitted, models.ResultsApproved, models.ResultsPublished:
+	default:
+		return helpers.Error(c, "invalid status", http.StatusBadRequest)
+	}
+
+	pubs, err := h.gradeService.GetPublications(ctx, collegeID, termID, status)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, pubs, http.StatusOK)
+}
+
+func (h *GradeHandler) SubmitResults(c echo.Context) error {
+	return h.publicationStep(c, func(ctx context.Context, collegeID, courseID int, body PublicationRequest, by string) (*models.ResultPublication, error) {
+		return h.gradeService.SubmitResults(ctx, collegeID, courseID, body.TermID, by)
+	})
+}
+
+func (h *GradeHandler) ApproveResults(c echo.Context) error {
+	return h.publicationStep(c, func(ctx context.Context, collegeID, courseID int, body PublicationRequest, by string) (*models.ResultPublication, error) {
+		return h.gradeService.ApproveResults(ctx, collegeID, courseID, body.TermID, by)
+	})
+}
+
+func (h *GradeHandler) ReturnResults(c echo.Context) error {
+	return h.publicationStep(c, func(ctx context.Context, collegeID, courseID int, body PublicationRequest, by string) (*models.ResultPublication, error) {
+		return h.gradeService.ReturnResults(ctx, collegeID, courseID, body.TermID, by, body.Reason)
+	})
+}
+
+func (h *GradeHandler) PublishResults(c echo.Context) error {
+	return h.publicationStep(c, func(ctx context.Context, collegeID, courseID int, body PublicationRequest, by string) (*models.ResultPublication, error) {
+		return h.gradeService.PublishResults(ctx, collegeID, courseID, body.TermID, by)
+	})
+}
+
+// publicationStep reads a PublicationRequest for the course and takes the
+// step as the caller.
+func (h *GradeHandler) publicationStep(c echo.Context, step func(ctx context.Context, collegeID, courseID int, body PublicationRequest, by string) (*models.ResultPublication, error)) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	identityID, err := helpers.ExtractIdentityID(c)
+	if err != nil {
+		return err
+	}
+	var body PublicationRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+
+	pub, err := step(ctx, collegeID, courseID, body, identityID)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, pub, http.StatusOK)
+}
+
+func (h *GradeHandler) RequestReevaluation(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	studentID, err := helpers.GetIDFromParam(c, "studentID")
+	if err != nil {
+		return err
+	}
+	identityID, err := helpers.ExtractIdentityID(c)
+	if err != nil {
+		return err
+	}
+	var body ReevaluationRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+
+	reevaluation, err := h.gradeService.RequestReevaluation(ctx, collegeID, studentID, body.GradeID, body.Reason, identityID)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, reevaluation, http.StatusCreated)
+}
+
+// GetStudentReevaluations lists the student's re-evaluation requests.
+func (h *GradeHandler) GetStudentReevaluations(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	studentID, err := helpers.GetIDFromParam(c, "studentID")
+	if err != nil {
+		return err
+	}
+	limit, offset := helpers.GetPagination(c)
+
+	reevaluations, err := h.gradeService.GetReevaluations(ctx, collegeID, studentID, "", limit, offset)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, reevaluations, http.StatusOK)
+}
+
+// GetReevaluations lists the college's re-evaluation requests, with
+// ?status= if given.
+func (h *GradeHandler) GetReevaluations(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	status := models.ReevaluationStatus(c.QueryParam("status"))
+	switch status {
+	case "", models.ReevaluationPending, models.ReevaluationAccepted, models.ReevaluationRejected:
+	default:
+		return helpers.Error(c, "invalid status", http.StatusBadRequest)
+	}
+	limit, offset := helpers.GetPagination(c)
+
+	reevaluations, err := h.gradeService.GetReevaluations(ctx, collegeID, 0, status, limit, offset)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, reevaluations, http.StatusOK)
+}
+
+// ResolveReevaluation accepts the request with new marks or rejects it.
+func (h *GradeHandler) ResolveReevaluation(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	reevaluationID, err := helpers.GetIDFromParam(c, "reevaluationID")
+	if err != nil {
+		return err
+	}
+	identityID, err := helpers.ExtractIdentityID(c)
+	if err != nil {
+		return err
+	}
+	role, err := helpers.GetUserRole(c)
+	if err != nil {
+		return err
+	}
+	var decision models.ReevaluationDecision
+	if err := c.Bind(&decision); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+
+	reevaluation, err := h.gradeService.ResolveReevaluation(ctx, collegeID, reevaluationID, &decision, identityID,
+		role == middleware.RoleAdmin)
+	if err != nil {
+		return gradeError(c, err)
+	}
+	return helpers.Success(c, reevaluation, http.StatusOK)
+}
+
+func (r *GradingScaleRequest) scale(collegeID int) *models.GradingScale {
+	return &models.GradingScale{
+		CollegeID:      collegeID,
+		Name:           r.Name,
+		Method:         r.Method,
+		PassPercentage: r.PassPercentage,
+		Bands:          r.Bands,
+		IsDefault:      r.IsDefault,
+	}
+}
+
+// gradeFilter reads the course, assessment, exam type and pagination shared
+// by the grade listings.
+func gradeFilter(c echo.Context, collegeID int, termID *int) (models.GradeFilter, error) {
+	limit, offset := helpers.GetPagination(c)
+	filter := models.GradeFilter{CollegeID: &collegeID, TermID: termID, Limit: limit, Offset: offset}
+
+	for param, id := range map[string]**int{"course_id": &filter.CourseID, "assessment_id": &filter.AssessmentID} {
+		if raw := c.QueryParam(param); raw != "" {
+			v, err := strconv.Atoi(raw)
+			if err != nil || v <= 0 {
+				return filter, fmt.Errorf("invalid %s", param)
+			}
+			*id = &v
+		}
+	}
+	switch examType := c.QueryParam("exam_type"); examType {
+	case "", models.Midterm1, models.Midterm2, models.Final, models.Assignments:
+		filter.ExamType = examType
+	default:
+		return filter, errors.New("invalid exam_type")
+	}
+	return filter, nil
+}
+
+// studentView reports whether the caller is a student, who only sees
+// published results.
+func studentView(c echo.Context) (bool, error) {
+	role, err := helpers.GetUserRole(c)
+	if err != nil {
+		return false, err
+	}
+	return role == middleware.RoleStudent, nil
+}
+
+func gradeError(c echo.Context, err error) error {
+	var (
+		rejected *grades.ScoreSubmissionError
+		invalid  validator.ValidationErrors
+	)
+	switch {
+	case errors.As(err, &rejected):
+		return helpers.Error(c, rejected, http.StatusUnprocessableEntity)
+	case errors.Is(err, grades.ErrNoScoresToCurve):
+		return helpers.Error(c, err.Error(), http.StatusUnprocessableEntity)
+	case errors.As(err, &invalid), errors.Is(err, grades.ErrInvalidGradingScale), errors.Is(err, grades.ErrInvalidCurve),
+		errors.Is(err, grades.ErrInvalidReevaluation):
+		return helpers.Error(c, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, grades.ErrSelfApproval), errors.Is(err, grades.ErrNotResolver),
+		errors.Is(err, grades.ErrNotSubmitter), errors.Is(err, grades.ErrNotApprover):
+		return helpers.Error(c, err.Error(), http.StatusForbidden)
+	case errors.Is(err, repository.ErrAssessmentNotFound), errors.Is(err, repository.ErrCourseNotFound),
+		errors.Is(err, repository.ErrGradingScaleNotFound), errors.Is(err, repository.ErrStudentNotFound),
+		errors.Is(err, repository.ErrGradeCurveNotFound), errors.Is(err, repository.ErrGradeNotFound),
+		errors.Is(err, repository.ErrReevaluationNotFound):
+		return helpers.Error(c, err.Error(), http.StatusNotFound)
+	case errors.Is(err, repository.ErrAssessmentExists), errors.Is(err, repository.ErrAssessmentWeightExceeded),
+		errors.Is(err, grades.ErrMaxMarksBelowScores), errors.Is(err, repository.ErrGradingScaleExists),
+		errors.Is(err, repository.ErrGradesLocked), errors.Is(err, repository.ErrResultsSubmitted),
+		errors.Is(err, repository.ErrPublicationChanged), errors.Is(err, grades.ErrInvalidTransition),
+		errors.Is(err, grades.ErrResultsNotPublished), errors.Is(err, repository.ErrReevaluationPending),
+		errors.Is(err, repository.ErrReevaluationResolved), errors.Is(err, repository.ErrScoresChanged):
+		return helpers.Error(c, err.Error(), http.StatusConflict)
+	default:
+		return helpers.Error(c, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -12,14 +12,32 @@ type Handlers struct {
 	// Quiz *QuizHandler
 	// fee handler
 	// attendance handler
+	Course     *CourseHandler
+	Enrollment *EnrollmentHandler
+	Lecture    *LectureHandler
 	Attendance *AttendanceHandler
+	Leave      *LeaveHandler
+	Suspension *SuspensionHandler
+	Term       *TermHandler
+	Timetable  *TimetableHandler
+	Calendar   *CalendarHandler
+	Grade      *GradeHandler
 	// System     *SystemHandler
 }
 
 func NewHandlers(services *services.Services) *Handlers {
 	return &Handlers{
 		Auth:       NewAuthHandler(services.Auth),
+		Course:     NewCourseHandler(services.CourseService),
+		Enrollment: NewEnrollmentHandler(services.Enrollment),
+		Lecture:    NewLectureHandler(services.LectureService),
 		Attendance: NewAttendanceHandler(services.Attendance),
+		Leave:      NewLeaveHandler(services.LeaveService),
+		Suspension: NewSuspensionHandler(services.Suspension),
+		Term:       NewTermHandler(services.Term),
+		Timetable:  NewTimetableHandler(services.Timetable),
+		Calendar:   NewCalendarHandler(services.Calendar, services.CalendarFeed),
+		Grade:      NewGradeHandler(services.GradeService),
 		// other handlers
 		// System: NewSystemHandler(services.System),
 	}
@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"eduhub/server/internal/helpers"
+	"eduhub/server/internal/middleware"
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+	"eduhub/server/internal/services/leave"
+
+	"github.com/labstack/echo/v4"
+)
+
+type LeaveHandler struct {
+	leaveService leave.LeaveService
+}
+
+// SubmitLeaveRequest is the body a student sends to request leave. Dates are
+// YYYY-MM-DD and both ends are inclusive.
+type SubmitLeaveRequest struct {
+	LeaveType   string                    `json:"leave_type"`
+	StartDate   string                    `json:"start_date"`
+	EndDate     string                    `json:"end_date"`
+	Reason      string                    `json:"reason"`
+	Attachments []*models.LeaveAttachment `json:"attachments,omitempty"`
+}
+
+type ReviewLeaveRequest struct {
+	Note string `json:"note,omitempty"`
+}
+
+func NewLeaveHandler(leaveService leave.LeaveService) *LeaveHandler {
+	return &LeaveHandler{
+		leaveService: leaveService,
+	}
+}
+
+func (h *LeaveHandler) SubmitLeaveRequest(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	studentID, err := helpers.ExtractStudentID(c)
+	if err != nil {
+		return err
+	}
+
+	var body SubmitLeaveRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+	startDate, err := time.Parse("2006-01-02", body.StartDate)
+	if err != nil {
+		return helpers.Error(c, "start_date must be YYYY-MM-DD", http.StatusBadRequest)
+	}
+	endDate, err := time.Parse("2006-01-02", body.EndDate)
+	if err != nil {
+		return helpers.Error(c, "end_date must be YYYY-MM-DD", http.StatusBadRequest)
+	}
+
+	req := &models.LeaveRequest{
+		CollegeID:   collegeID,
+		StudentID:   studentID,
+		LeaveType:   body.LeaveType,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Reason:      body.Reason,
+		Attachments: body.Attachments,
+	}
+	if err := h.leaveService.SubmitLeaveRequest(ctx, req); err != nil {
+		return helpers.Error(c, err.Error(), http.StatusBadRequest)
+	}
+	return helpers.Success(c, req, http.StatusCreated)
+}
+
+func (h *LeaveHandler) GetMyLeaveRequests(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	studentID, err := helpers.ExtractStudentID(c)
+	if err != nil {
+		return err
+	}
+	limit, offset := helpers.GetPagination(c)
+
+	requests, err := h.leaveService.GetStudentLeaveRequests(ctx, collegeID, studentID, limit, offset)
+	if err != nil {
+		return helpers.Error(c, "unable to get leave requests", http.StatusInternalServerError)
+	}
+	return helpers.Success(c, requests, http.StatusOK)
+}
+
+func (h *LeaveHandler) CancelLeaveRequest(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	studentID, err := helpers.ExtractStudentID(c)
+	if err != nil {
+		return err
+	}
+	requestID, err := helpers.GetIDFromParam(c, "leaveID")
+	if err != nil {
+		return err
+	}
+
+	if err := h.leaveService.CancelLeaveRequest(ctx, collegeID, studentID, requestID); err != nil {
+		return leaveError(c, err)
+	}
+	return helpers.Success(c, "leave request cancelled", http.StatusOK)
+}
+
+// GetLeaveRequests lists the college's leave requests; ?status= filters them.
+func (h *LeaveHandler) GetLeaveRequests(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	status := c.QueryParam("status")
+	switch status {
+	case "", models.LeaveStatusPending, models.LeaveStatusApproved, models.LeaveStatusRejected, models.LeaveStatusCancelled:
+	default:
+		return helpers.Error(c, "invalid status", http.StatusBadRequest)
+	}
+	limit, offset := helpers.GetPagination(c)
+
+	requests, err := h.leaveService.GetLeaveRequests(ctx, collegeID, status, limit, offset)
+	if err != nil {
+		return helpers.Error(c, "unable to get leave requests", http.StatusInternalServerError)
+	}
+	return helpers.Success(c, requests, http.StatusOK)
+}
+
+func (h *LeaveHandler) GetLeaveRequest(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	requestID, err := helpers.GetIDFromParam(c, "leaveID")
+	if err != nil {
+		return err
+	}
+
+	req, err := h.leaveService.GetLeaveRequest(ctx, collegeID, requestID)
+	if err != nil {
+		return leaveError(c, err)
+	}
+	return helpers.Success(c, req, http.StatusOK)
+}
+
+func (h *LeaveHandler) ApproveLeaveRequest(c echo.Context) error {
+	return h.reviewLeaveRequest(c, h.leaveService.ApproveLeaveRequest)
+}
+
+func (h *LeaveHandler) RejectLeaveRequest(c echo.Context) error {
+	return h.reviewLeaveRequest(c, h.leaveService.RejectLeaveRequest)
+}
+
+func (h *LeaveHandler) reviewLeaveRequest(c echo.Context, review func(ctx context.Context, collegeID, requestID int, reviewerID string, admin bool, note string) (*models.LeaveRequest, error)) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	requestID, err := helpers.GetIDFromParam(c, "leaveID")
+	if err != nil {
+		return err
+	}
+	reviewerID, err := helpers.ExtractIdentityID(c)
+	if err != nil {
+		return err
+	}
+	role, err := helpers.GetUserRole(c)
+	if err != nil {
+		return err
+	}
+	var body ReviewLeaveRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+
+	req, err := review(ctx, collegeID, requestID, reviewerID, role == middleware.RoleAdmin, body.Note)
+	if err != nil {
+		return leaveError(c, err)
+	}
+	return helpers.Success(c, req, http.StatusOK)
+}
+
+func leaveError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrLeaveRequestNotFound):
+		return helpers.Error(c, err.Error(), http.StatusNotFound)
+	case errors.Is(err, leave.ErrLeaveNotPending):
+		return helpers.Error(c, err.Error(), http.StatusConflict)
+	case errors.Is(err, leave.ErrNotRequestOwner), errors.Is(err, leave.ErrNotReviewer), errors.Is(err, leave.ErrCrossCourseLeave):
+		return helpers.Error(c, err.Error(), http.StatusForbidden)
+	default:
+		return helpers.Error(c, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"eduhub/server/internal/helpers"
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+	"eduhub/server/internal/services/lecture"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+type LectureHandler struct {
+	lectureService lecture.LectureService
+}
+
+// LectureRequest is the body for creating or updating a lecture. Times are
+// RFC 3339.
+type LectureRequest struct {
+	Title                   string    `json:"title"`
+	Description             string    `json:"description"`
+	StartTime               time.Time `json:"start_time"`
+	EndTime                 time.Time `json:"end_time"`
+	MeetingLink             string    `json:"meeting_link"`
+	Latitude                *float64  `json:"latitude,omitempty"`
+	Longitude               *float64  `json:"longitude,omitempty"`
+	GeofenceRadiusMeters    *int      `json:"geofence_radius_meters,omitempty"`
+	RequireRegisteredDevice bool      `json:"require_registered_device"`
+}
+
+// GenerateLecturesRequest generates lectures from the course timetable. Dates
+// are YYYY-MM-DD in Timezone (an IANA name, UTC if empty) and default to the
+// term's start and end; TermID defaults to the current term.
+type GenerateLecturesRequest struct {
+	TermID   *int   `json:"term_id,omitempty"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// LectureList is one page of a course's lectures along with their total.
+type LectureList struct {
+	Lectures []*models.Lecture `json:"lectures"`
+	Total    int               `json:"total"`
+	Limit    uint64            `json:"limit"`
+	Offset   uint64            `json:"offset"`
+}
+
+func NewLectureHandler(lectureService lecture.LectureService) *LectureHandler {
+	return &LectureHandler{
+		lectureService: lectureService,
+	}
+}
+
+// ListLectures pages through the course's lectures in ?term_id=, defaulting
+// to the current term.
+func (h *LectureHandler) ListLectures(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetTermID(c)
+	if err != nil {
+		return err
+	}
+	limit, offset := helpers.GetPagination(c)
+
+	lectures, err := h.lectureService.FindLecturesByCourse(ctx, collegeID, courseID, termID, limit, offset)
+	if err != nil {
+		return helpers.Error(c, "unable to get lectures", http.StatusInternalServerError)
+	}
+	total, err := h.lectureService.CountLecturesByCourse(ctx, collegeID, courseID, termID)
+	if err != nil {
+		return helpers.Error(c, "unable to get lectures", http.StatusInternalServerError)
+	}
+	return helpers.Success(c, LectureList{Lectures: lectures, Total: total, Limit: limit, Offset: offset}, http.StatusOK)
+}
+
+func (h *LectureHandler) CreateLecture(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	var body LectureRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+
+	l := &models.Lecture{CourseID: courseID, CollegeID: collegeID}
+	body.applyTo(l)
+	if err := h.lectureService.CreateLecture(ctx, l); err != nil {
+		return lectureError(c, err)
+	}
+	return helpers.Success(c, l, http.StatusCreated)
+}
+
+func (h *LectureHandler) GetLecture(c echo.Context) error {
+	l, err := h.findLecture(c)
+	if err != nil {
+		return lectureError(c, err)
+	}
+	return helpers.Success(c, l, http.StatusOK)
+}
+
+func (h *LectureHandler) UpdateLecture(c echo.Context) error {
+	ctx := c.Request().Context()
+	l, err := h.findLecture(c)
+	if err != nil {
+		return lectureError(c, err)
+	}
+	var body LectureRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+
+	body.applyTo(l)
+	l.TermID = nil // Follows the new start time
+	if err := h.lectureService.UpdateLecture(ctx, l); err != nil {
+		return lectureError(c, err)
+	}
+	updated, err := h.lectureService.GetLectureByID(ctx, l.CollegeID, l.ID)
+	if err != nil {
+		return lectureError(c, err)
+	}
+	return helpers.Success(c, updated, http.StatusOK)
+}
+
+func (h *LectureHandler) DeleteLecture(c echo.Context) error {
+	ctx := c.Request().Context()
+	l, err := h.findLecture(c)
+	if err != nil {
+		return lectureError(c, err)
+	}
+
+	if err := h.lectureService.DeleteLecture(ctx, l.CollegeID, l.ID); err != nil {
+		return lectureError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GenerateLectures creates the course's lectures from its timetable, skipping
+// holidays. Slots generated before are counted as existing, not duplicated.
+func (h *LectureHandler) GenerateLectures(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return err
+	}
+	var body GenerateLecturesRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+
+	loc := time.UTC
+	if body.Timezone != "" {
+		if loc, err = time.LoadLocation(body.Timezone); err != nil {
+			return helpers.Error(c, "invalid timezone", http.StatusBadRequest)
+		}
+	}
+	var from, to time.Time
+	if body.From != "" {
+		if from, err = time.Parse(time.DateOnly, body.From); err != nil {
+			return helpers.Error(c, "from must be YYYY-MM-DD", http.StatusBadRequest)
+		}
+	}
+	if body.To != "" {
+		if to, err = time.Parse(time.DateOnly, body.To); err != nil {
+			return helpers.Error(c, "to must be YYYY-MM-DD", http.StatusBadRequest)
+		}
+	}
+
+	result, err := h.lectureService.GenerateLectures(ctx, collegeID, courseID, body.TermID, from, to, loc)
+	if err != nil {
+		return lectureError(c, err)
+	}
+	return helpers.Success(c, result, http.StatusCreated)
+}
+
+// findLecture loads the :lectureID lecture, failing with
+// repository.ErrLectureNotFound unless it belongs to the :courseID course.
+func (h *LectureHandler) findLecture(c echo.Context) (*models.Lecture, error) {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return nil, err
+	}
+	courseID, err := helpers.GetIDFromParam(c, "courseID")
+	if err != nil {
+		return nil, err
+	}
+	lectureID, err := helpers.GetIDFromParam(c, "lectureID")
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := h.lectureService.GetLectureByID(ctx, collegeID, lectureID)
+	if err != nil {
+		return nil, err
+	}
+	if l.CourseID != courseID {
+		return nil, fmt.Errorf("lecture %d of course %d: %w", lectureID, courseID, repository.ErrLectureNotFound)
+	}
+	return l, nil
+}
+
+func (r LectureRequest) applyTo(l *models.Lecture) {
+	l.Title = r.Title
+	l.Description = r.Description
+	l.StartTime = r.StartTime
+	l.EndTime = r.EndTime
+	l.MeetingLink = r.MeetingLink
+	l.Latitude = r.Latitude
+	l.Longitude = r.Longitude
+	l.GeofenceRadiusMeters = r.GeofenceRadiusMeters
+	l.RequireRegisteredDevice = r.RequireRegisteredDevice
+}
+
+func lectureError(c echo.Context, err error) error {
+	var invalid validator.ValidationErrors
+	var httpErr *echo.HTTPError
+	switch {
+	case errors.As(err, &httpErr):
+		return httpErr
+	case errors.As(err, &invalid):
+		return helpers.Error(c, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrLectureNotFound), errors.Is(err, repository.ErrCourseNotFound), errors.Is(err, repository.ErrTermNotFound):
+		return helpers.Error(c, err.Error(), http.StatusNotFound)
+	case errors.Is(err, lecture.ErrNoGenerationRange), errors.Is(err, lecture.ErrInvalidGenerationRange):
+		return helpers.Error(c, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, lecture.ErrNoTimetable):
+		return helpers.Error(c, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		return helpers.Error(c, err.Error(), http.StatusInternalServerError)
+	}
+}
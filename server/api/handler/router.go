@@ -18,6 +18,8 @@ func SetupRoutes(e *echo.Echo, a *Handlers, m *middleware.AuthMiddleware) {
 		return c.Redirect(302, "/docs/index.html")
 	})
 	e.GET("/docs/*", echoSwagger.WrapHandler)
+	// iCalendar subscriptions, authenticated by the token in the URL
+	e.GET("/ical/:token", a.Calendar.ServeFeed)
 	// Auth routes
 	auth := e.Group("/auth")
 	auth.GET("/register", a.Auth.InitiateRegistration)
@@ -63,33 +65,49 @@ func SetupRoutes(e *echo.Echo, a *Handlers, m *middleware.AuthMiddleware) {
 	// students.DELETE("/:studentID", a.Student.DeleteStudent, m.RequireRole(middleware.RoleAdmin))
 	// students.PUT("/:studentID/freeze", a.Student.FreezeStudent, m.RequireRole(middleware.RoleAdmin))
 
-	// // Course management
-	// courses := apiGroup.Group("/courses")
-	// courses.GET("", a.Course.ListCourses)
-	// courses.POST("", a.Course.CreateCourse, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
-	// courses.GET("/:courseID", a.Course.GetCourse)
-	// courses.PUT("/:courseID", a.Course.UpdateCourse, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
-	// courses.DELETE("/:courseID", a.Course.DeleteCourse, m.RequireRole(middleware.RoleAdmin))
-
-	// // Course enrollment
-	// courses.POST("/:courseID/enroll", a.Course.EnrollStudents, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
-	// courses.DELETE("/:courseID/students/:studentID", a.Course.RemoveStudent, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
-	// courses.GET("/:courseID/students", a.Course.ListEnrolledStudents)
-
-	// // Lecture management
-	// lectures := apiGroup.Group("/courses/:courseID/lectures")
-	// lectures.GET("", a.Lecture.ListLectures)
-	// lectures.POST("", a.Lecture.CreateLecture, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
-	// lectures.GET("/:lectureID", a.Lecture.GetLecture)
-	// lectures.PUT("/:lectureID", a.Lecture.UpdateLecture, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
-	// lectures.DELETE("/:lectureID", a.Lecture.DeleteLecture, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	// Academic terms; list endpoints default to the current term
+	terms := apiGroup.Group("/terms")
+	terms.GET("", a.Term.GetTerms)
+	terms.GET("/current", a.Term.GetCurrentTerm)
+	terms.GET("/:termID", a.Term.GetTerm)
+	terms.POST("", a.Term.CreateTerm, m.RequireRole(middleware.RoleAdmin))
+	terms.PUT("/:termID", a.Term.UpdateTerm, m.RequireRole(middleware.RoleAdmin))
+	terms.DELETE("/:termID", a.Term.DeleteTerm, m.RequireRole(middleware.RoleAdmin))
+	terms.POST("/:termID/current", a.Term.SetCurrentTerm, m.RequireRole(middleware.RoleAdmin))
+
+	// Course management
+	courses := apiGroup.Group("/courses")
+	courses.GET("", a.Course.ListCourses)
+	courses.POST("", a.Course.CreateCourse, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	courses.GET("/:courseID", a.Course.GetCourse)
+	courses.PUT("/:courseID", a.Course.UpdateCourse, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty), m.VerifyCourseInstructor)
+	courses.DELETE("/:courseID", a.Course.DeleteCourse, m.RequireRole(middleware.RoleAdmin))
+	courses.PUT("/:courseID/instructor", a.Course.AssignInstructor, m.RequireRole(middleware.RoleAdmin))
+
+	// Course enrollment
+	courses.POST("/:courseID/enroll", a.Enrollment.EnrollStudents, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty), m.VerifyCourseInstructor)
+	courses.DELETE("/:courseID/students/:studentID", a.Enrollment.RemoveStudent, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty), m.VerifyCourseInstructor)
+	courses.GET("/:courseID/students", a.Enrollment.ListEnrolledStudents, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty), m.VerifyCourseInstructor)
+	courses.POST("/:courseID/drop", a.Enrollment.DropCourse, m.RequireRole(middleware.RoleStudent), m.LoadStudentProfile)
+	courses.GET("/:courseID/eligibility", a.Enrollment.CheckEligibility, m.RequireRole(middleware.RoleStudent), m.LoadStudentProfile)
+	courses.GET("/:courseID/overrides", a.Enrollment.GetEnrollmentOverrides, m.RequireRole(middleware.RoleAdmin))
+
+	// Course prerequisites
+	courses.GET("/:courseID/prerequisites", a.Course.GetPrerequisites)
+	courses.POST("/:courseID/prerequisites", a.Course.AddPrerequisite, m.RequireRole(middleware.RoleAdmin))
+	courses.DELETE("/:courseID/prerequisites/:prerequisiteID", a.Course.RemovePrerequisite, m.RequireRole(middleware.RoleAdmin))
+
+	// Lecture management
+	lectures := apiGroup.Group("/courses/:courseID/lectures")
+	lectures.GET("", a.Lecture.ListLectures)
+	lectures.POST("", a.Lecture.CreateLecture, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty), m.VerifyCourseInstructor)
+	lectures.POST("/generate", a.Lecture.GenerateLectures, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty), m.VerifyCourseInstructor)
+	lectures.GET("/:lectureID", a.Lecture.GetLecture)
+	lectures.PUT("/:lectureID", a.Lecture.UpdateLecture, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty), m.VerifyCourseInstructor)
+	lectures.DELETE("/:lectureID", a.Lecture.DeleteLecture, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty), m.VerifyCourseInstructor)
 
 	// Attendance management
 	attendance := apiGroup.Group("/attendance")
-	attendance.POST("/mark/course/:courseID/lecture/:lectureID", a.Attendance.MarkAttendance,
-		m.RequireRole(middleware.RoleStudent),
-		m.LoadStudentProfile,
-		m.VerifyStudentOwnership)
 	attendance.POST("/mark/bulk/course/:courseID/lecture/:lectureID", a.Attendance.MarkBulkAttendance,
 		m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
 	attendance.GET("/course/:courseID/lecture/:lectureID/qrcode", a.Attendance.GenerateQRCode,
@@ -106,24 +124,129 @@ func SetupRoutes(e *echo.Echo, a *Handlers, m *middleware.AuthMiddleware) {
 		m.VerifyStudentOwnership)
 	attendance.PUT("/course/:courseID/lecture/:lectureID/student/:studentID", a.Attendance.UpdateAttendance,
 		m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	attendance.GET("/records/:attendanceID/history", a.Attendance.GetAttendanceHistory,
+		m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	attendance.GET("/course/:courseID/lecture/:lectureID/history", a.Attendance.GetLectureAttendanceHistory,
+		m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	attendance.GET("/report/course/:courseID", a.Attendance.GetCourseAttendanceReport,
+		m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	attendance.GET("/report/course/:courseID/shortage", a.Attendance.GetAttendanceShortage,
+		m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	attendance.GET("/export/course/:courseID", a.Attendance.ExportAttendanceRegister,
+		m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
 	attendance.GET("/report/:studentID", a.Attendance.GetAttendanceForStudent, m.RequireRole(middleware.RoleAdmin, middleware.RoleStudent), m.VerifyStudentOwnership)
 	attendance.POST("/process-qr", a.Attendance.ProcessAttendance, m.RequireRole(middleware.RoleStudent), m.LoadStudentProfile)
-	// 	// Grades/Assessment management
-	// 	grades := apiGroup.Group("/grades")
-	// 	grades.GET("/course/:courseID", a.Grade.GetGradesByCourse, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
-	// 	grades.POST("/course/:courseID", a.Grade.CreateAssessment, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
-	// 	grades.PUT("/course/:courseID/assessment/:assessmentID", a.Grade.UpdateAssessment, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
-	// 	grades.DELETE("/course/:courseID/assessment/:assessmentID", a.Grade.DeleteAssessment, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
-	// 	grades.POST("/course/:courseID/assessment/:assessmentID/scores", a.Grade.SubmitScores, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
-	// 	grades.GET("/student/:studentID", a.Grade.GetStudentGrades,
-	// 		m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty, middleware.RoleStudent),
-	// 		m.LoadStudentProfile,
-	// 		m.VerifyStudentOwnership)
-
-	// 	// Calendar/Schedule management
-	// 	calendar := apiGroup.Group("/calendar")
-	// 	calendar.GET("", a.Calendar.GetEvents)
-	// 	calendar.POST("", a.Calendar.CreateEvent, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
-	// 	calendar.PUT("/:eventID", a.Calendar.UpdateEvent, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
-	// 	calendar.DELETE("/:eventID", a.Calendar.DeleteEvent, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	attendance.POST("/devices", a.Attendance.RegisterDevice, m.RequireRole(middleware.RoleStudent), m.LoadStudentProfile)
+	attendance.GET("/devices", a.Attendance.GetMyDevices, m.RequireRole(middleware.RoleStudent), m.LoadStudentProfile)
+	attendance.DELETE("/devices/:deviceID", a.Attendance.RevokeMyDevice, m.RequireRole(middleware.RoleStudent), m.LoadStudentProfile)
+	attendance.DELETE("/student/:studentID/devices/:deviceID", a.Attendance.RevokeStudentDevice,
+		m.RequireRole(middleware.RoleAdmin))
+	attendance.GET("/course/:courseID/lecture/:lectureID/scans", a.Attendance.GetLectureScans,
+		m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	attendance.GET("/policy", a.Attendance.GetAttendancePolicy,
+		m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	attendance.PUT("/policy", a.Attendance.UpdateAttendancePolicy,
+		m.RequireRole(middleware.RoleAdmin))
+
+	// Weekly timetable; blocks that share a room, faculty member or class
+	// at overlapping times are refused with the clashing blocks
+	timetable := apiGroup.Group("/timetable")
+	timetable.POST("", a.Timetable.CreateBlock, m.RequireRole(middleware.RoleAdmin))
+	timetable.GET("/:blockID", a.Timetable.GetBlock)
+	timetable.PUT("/:blockID", a.Timetable.UpdateBlock, m.RequireRole(middleware.RoleAdmin))
+	timetable.DELETE("/:blockID", a.Timetable.DeleteBlock, m.RequireRole(middleware.RoleAdmin))
+	timetable.GET("/classes/:classID", a.Timetable.GetClassTimetable)
+	timetable.GET("/faculty/:facultyID", a.Timetable.GetFacultyTimetable)
+	timetable.GET("/rooms/:room", a.Timetable.GetRoomTimetable)
+
+	// Solver drafts, reviewed and published by admins
+	drafts := timetable.Group("/drafts", m.RequireRole(middleware.RoleAdmin))
+	drafts.POST("", a.Timetable.GenerateDraft)
+	drafts.GET("", a.Timetable.GetDrafts)
+	drafts.GET("/:draftID", a.Timetable.GetDraft)
+	drafts.POST("/:draftID/publish", a.Timetable.PublishDraft)
+	drafts.DELETE("/:draftID", a.Timetable.DeleteDraft)
+
+	// Leave requests: students submit, faculty and admins review
+	leave := apiGroup.Group("/leave")
+	leave.POST("", a.Leave.SubmitLeaveRequest, m.RequireRole(middleware.RoleStudent), m.LoadStudentProfile)
+	leave.GET("/mine", a.Leave.GetMyLeaveRequests, m.RequireRole(middleware.RoleStudent), m.LoadStudentProfile)
+	leave.DELETE("/:leaveID", a.Leave.CancelLeaveRequest, m.RequireRole(middleware.RoleStudent), m.LoadStudentProfile)
+	leave.GET("", a.Leave.GetLeaveRequests, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	leave.GET("/:leaveID", a.Leave.GetLeaveRequest, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	leave.POST("/:leaveID/approve", a.Leave.ApproveLeaveRequest, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	leave.POST("/:leaveID/reject", a.Leave.RejectLeaveRequest, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+
+	// Student suspensions freeze the student, their enrollments and attendance
+	suspensions := apiGroup.Group("/suspensions", m.RequireRole(middleware.RoleAdmin))
+	suspensions.POST("", a.Suspension.CreateSuspension)
+	suspensions.GET("", a.Suspension.GetSuspensions)
+	suspensions.GET("/:suspensionID", a.Suspension.GetSuspension)
+	suspensions.POST("/:suspensionID/lift", a.Suspension.LiftSuspension)
+
+	// Grades/Assessment management; course totals weigh each assessment and
+	// results are banded on the college's grading scale into SGPA and CGPA
+	grades := apiGroup.Group("/grades")
+	grades.GET("", a.Grade.GetGrades, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	grades.GET("/course/:courseID", a.Grade.GetGradesByCourse, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty), m.VerifyCourseInstructor)
+	grades.GET("/course/:courseID/assessments", a.Grade.GetAssessments)
+	grades.POST("/course/:courseID", a.Grade.CreateAssessment, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty), m.VerifyCourseInstructor)
+	grades.PUT("/course/:courseID/assessment/:assessmentID", a.Grade.UpdateAssessment, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty), m.VerifyCourseInstructor)
+	grades.DELETE("/course/:courseID/assessment/:assessmentID", a.Grade.DeleteAssessment, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty), m.VerifyCourseInstructor)
+	grades.POST("/course/:courseID/assessment/:assessmentID/scores", a.Grade.SubmitScores, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty), m.VerifyCourseInstructor)
+	grades.GET("/course/:courseID/curves", a.Grade.GetCurves, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty), m.VerifyCourseInstructor)
+	grades.POST("/course/:courseID/curve/preview", a.Grade.PreviewCurve, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty), m.VerifyCourseInstructor)
+	grades.POST("/course/:courseID/curve", a.Grade.CommitCurve, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty), m.VerifyCourseInstructor)
+	grades.POST("/course/:courseID/curve/unlock", a.Grade.UnlockCurve, m.RequireRole(middleware.RoleAdmin))
+	grades.GET("/student/:studentID", a.Grade.GetStudentGrades,
+		m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty, middleware.RoleStudent),
+		m.LoadStudentProfile,
+		m.VerifyStudentOwnership)
+	grades.GET("/student/:studentID/scores", a.Grade.GetStudentScores,
+		m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty, middleware.RoleStudent),
+		m.LoadStudentProfile,
+		m.VerifyStudentOwnership)
+	grades.GET("/student/:studentID/gpa", a.Grade.GetStudentGPA,
+		m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty, middleware.RoleStudent),
+		m.LoadStudentProfile,
+		m.VerifyStudentOwnership)
+	grades.GET("/student/:studentID/transcript", a.Grade.GetTranscript,
+		m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty, middleware.RoleStudent),
+		m.LoadStudentProfile,
+		m.VerifyStudentOwnership)
+	grades.POST("/results", a.Grade.ComputeTermResults, m.RequireRole(middleware.RoleAdmin))
+	// Results go draft -> submitted -> approved by the head of department ->
+	// published; published grades only change through re-evaluation
+	grades.GET("/publications", a.Grade.GetPublications, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	grades.GET("/course/:courseID/publication", a.Grade.GetPublication, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	grades.POST("/course/:courseID/publication/submit", a.Grade.SubmitResults, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	grades.POST("/course/:courseID/publication/approve", a.Grade.ApproveResults, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	grades.POST("/course/:courseID/publication/return", a.Grade.ReturnResults, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	grades.POST("/course/:courseID/publication/publish", a.Grade.PublishResults, m.RequireRole(middleware.RoleAdmin))
+	grades.GET("/reevaluations", a.Grade.GetReevaluations, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	grades.POST("/reevaluations/:reevaluationID/resolve", a.Grade.ResolveReevaluation, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	grades.GET("/student/:studentID/reevaluations", a.Grade.GetStudentReevaluations,
+		m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty, middleware.RoleStudent),
+		m.LoadStudentProfile,
+		m.VerifyStudentOwnership)
+	grades.POST("/student/:studentID/reevaluations", a.Grade.RequestReevaluation,
+		m.RequireRole(middleware.RoleAdmin, middleware.RoleStudent),
+		m.LoadStudentProfile,
+		m.VerifyStudentOwnership)
+	grades.GET("/scales", a.Grade.GetGradingScales)
+	grades.POST("/scales", a.Grade.CreateGradingScale, m.RequireRole(middleware.RoleAdmin))
+	grades.PUT("/scales/:scaleID", a.Grade.UpdateGradingScale, m.RequireRole(middleware.RoleAdmin))
+	grades.DELETE("/scales/:scaleID", a.Grade.DeleteGradingScale, m.RequireRole(middleware.RoleAdmin))
+
+	// Calendar/Schedule management; holidays may not fall on exams
+	calendar := apiGroup.Group("/calendar")
+	calendar.GET("", a.Calendar.GetEvents)
+	calendar.POST("/feed", a.Calendar.CreateFeed)
+	calendar.GET("/feed", a.Calendar.GetFeed)
+	calendar.DELETE("/feed", a.Calendar.RevokeFeed)
+	calendar.POST("/import", a.Calendar.ImportHolidays, m.RequireRole(middleware.RoleAdmin))
+	calendar.GET("/:eventID", a.Calendar.GetEvent)
+	calendar.POST("", a.Calendar.CreateEvent, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	calendar.PUT("/:eventID", a.Calendar.UpdateEvent, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
+	calendar.DELETE("/:eventID", a.Calendar.DeleteEvent, m.RequireRole(middleware.RoleAdmin, middleware.RoleFaculty))
 }
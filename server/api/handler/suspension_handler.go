@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"eduhub/server/internal/helpers"
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+	"eduhub/server/internal/services/suspension"
+
+	"github.com/labstack/echo/v4"
+)
+
+type SuspensionHandler struct {
+	suspensionService suspension.SuspensionService
+}
+
+// CreateSuspensionRequest suspends a student. Dates are YYYY-MM-DD; starts_on
+// defaults to today and an empty ends_on keeps the suspension open until lifted.
+type CreateSuspensionRequest struct {
+	StudentID int    `json:"student_id"`
+	Reason    string `json:"reason"`
+	StartsOn  string `json:"starts_on,omitempty"`
+	EndsOn    string `json:"ends_on,omitempty"`
+}
+
+type LiftSuspensionRequest struct {
+	Reason string `json:"reason"`
+}
+
+func NewSuspensionHandler(suspensionService suspension.SuspensionService) *SuspensionHandler {
+	return &SuspensionHandler{
+		suspensionService: suspensionService,
+	}
+}
+
+func (h *SuspensionHandler) CreateSuspension(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	adminID, err := helpers.ExtractIdentityID(c)
+	if err != nil {
+		return err
+	}
+
+	var body CreateSuspensionRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+	s := &models.StudentSuspension{
+		CollegeID:   collegeID,
+		StudentID:   body.StudentID,
+		Reason:      body.Reason,
+		SuspendedBy: adminID,
+	}
+	if body.StartsOn != "" {
+		if s.StartsOn, err = time.Parse(time.DateOnly, body.StartsOn); err != nil {
+			return helpers.Error(c, "starts_on must be YYYY-MM-DD", http.StatusBadRequest)
+		}
+	}
+	if body.EndsOn != "" {
+		endsOn, err := time.Parse(time.DateOnly, body.EndsOn)
+		if err != nil {
+			return helpers.Error(c, "ends_on must be YYYY-MM-DD", http.StatusBadRequest)
+		}
+		s.EndsOn = &endsOn
+	}
+
+	if err := h.suspensionService.SuspendStudent(ctx, s); err != nil {
+		return suspensionError(c, err)
+	}
+	return helpers.Success(c, s, http.StatusCreated)
+}
+
+// GetSuspensions lists the college's suspensions; ?student_id= and ?status=
+// filter them.
+func (h *SuspensionHandler) GetSuspensions(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	studentID := 0
+	if raw := c.QueryParam("student_id"); raw != "" {
+		if studentID, err = strconv.Atoi(raw); err != nil {
+			return helpers.Error(c, "invalid student_id", http.StatusBadRequest)
+		}
+	}
+	status := c.QueryParam("status")
+	switch status {
+	case "", models.SuspensionScheduled, models.SuspensionActive, models.SuspensionLifted:
+	default:
+		return helpers.Error(c, "invalid status", http.StatusBadRequest)
+	}
+	limit, offset := helpers.GetPagination(c)
+
+	suspensions, err := h.suspensionService.GetSuspensions(ctx, collegeID, studentID, status, limit, offset)
+	if err != nil {
+		return helpers.Error(c, "unable to get suspensions", http.StatusInternalServerError)
+	}
+	return helpers.Success(c, suspensions, http.StatusOK)
+}
+
+func (h *SuspensionHandler) GetSuspension(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	suspensionID, err := helpers.GetIDFromParam(c, "suspensionID")
+	if err != nil {
+		return err
+	}
+
+	s, err := h.suspensionService.GetSuspension(ctx, collegeID, suspensionID)
+	if err != nil {
+		return suspensionError(c, err)
+	}
+	return helpers.Success(c, s, http.StatusOK)
+}
+
+func (h *SuspensionHandler) LiftSuspension(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	suspensionID, err := helpers.GetIDFromParam(c, "suspensionID")
+	if err != nil {
+		return err
+	}
+	adminID, err := helpers.ExtractIdentityID(c)
+	if err != nil {
+		return err
+	}
+	var body LiftSuspensionRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+
+	s, err := h.suspensionService.LiftSuspension(ctx, collegeID, suspensionID, adminID, body.Reason)
+	if err != nil {
+		return suspensionError(c, err)
+	}
+	return helpers.Success(c, s, http.StatusOK)
+}
+
+func suspensionError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrSuspensionNotFound):
+		return helpers.Error(c, err.Error(), http.StatusNotFound)
+	case errors.Is(err, repository.ErrStudentAlreadySuspended), errors.Is(err, suspension.ErrSuspensionAlreadyLifted):
+		return helpers.Error(c, err.Error(), http.StatusConflict)
+	case errors.Is(err, suspension.ErrInvalidSuspensionPeriod):
+		return helpers.Error(c, err.Error(), http.StatusBadRequest)
+	default:
+		return helpers.Error(c, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"eduhub/server/internal/helpers"
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+	"eduhub/server/internal/services/term"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+type TermHandler struct {
+	termService term.TermService
+}
+
+// TermRequest creates or updates an academic term. Dates are YYYY-MM-DD and
+// the registration window is optional. IsCurrent is only honoured on create;
+// use POST /terms/:termID/current to switch terms later.
+type TermRequest struct {
+	Name                 string `json:"name"`
+	StartDate            string `json:"start_date"`
+	EndDate              string `json:"end_date"`
+	RegistrationOpensOn  string `json:"registration_opens_on,omitempty"`
+	RegistrationClosesOn string `json:"registration_closes_on,omitempty"`
+	IsCurrent            bool   `json:"is_current,omitempty"`
+}
+
+func NewTermHandler(termService term.TermService) *TermHandler {
+	return &TermHandler{
+		termService: termService,
+	}
+}
+
+func (h *TermHandler) GetTerms(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	limit, offset := helpers.GetPagination(c)
+
+	terms, err := h.termService.GetTerms(ctx, collegeID, limit, offset)
+	if err != nil {
+		return helpers.Error(c, "unable to get terms", http.StatusInternalServerError)
+	}
+	return helpers.Success(c, terms, http.StatusOK)
+}
+
+func (h *TermHandler) GetCurrentTerm(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+
+	t, err := h.termService.GetCurrentTerm(ctx, collegeID)
+	if err != nil {
+		return termError(c, err)
+	}
+	return helpers.Success(c, t, http.StatusOK)
+}
+
+func (h *TermHandler) GetTerm(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetIDFromParam(c, "termID")
+	if err != nil {
+		return err
+	}
+
+	t, err := h.termService.GetTerm(ctx, collegeID, termID)
+	if err != nil {
+		return termError(c, err)
+	}
+	return helpers.Success(c, t, http.StatusOK)
+}
+
+func (h *TermHandler) CreateTerm(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+
+	t, err := bindTerm(c)
+	if err != nil {
+		return err
+	}
+	t.CollegeID = collegeID
+
+	if err := h.termService.CreateTerm(ctx, t); err != nil {
+		return termError(c, err)
+	}
+	return helpers.Success(c, t, http.StatusCreated)
+}
+
+func (h *TermHandler) UpdateTerm(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetIDFromParam(c, "termID")
+	if err != nil {
+		return err
+	}
+
+	t, err := bindTerm(c)
+	if err != nil {
+		return err
+	}
+	t.ID, t.CollegeID = termID, collegeID
+
+	if err := h.termService.UpdateTerm(ctx, t); err != nil {
+		return termError(c, err)
+	}
+	return helpers.Success(c, t, http.StatusOK)
+}
+
+func (h *TermHandler) DeleteTerm(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetIDFromParam(c, "termID")
+	if err != nil {
+		return err
+	}
+
+	if err := h.termService.DeleteTerm(ctx, collegeID, termID); err != nil {
+		return termError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SetCurrentTerm makes the term the college's current one, which list
+// endpoints default to.
+func (h *TermHandler) SetCurrentTerm(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetIDFromParam(c, "termID")
+	if err != nil {
+		return err
+	}
+
+	t, err := h.termService.SetCurrentTerm(ctx, collegeID, termID)
+	if err != nil {
+		return termError(c, err)
+	}
+	return helpers.Success(c, t, http.StatusOK)
+}
+
+func bindTerm(c echo.Context) (*models.AcademicTerm, error) {
+	var body TermRequest
+	if err := c.Bind(&body); err != nil {
+		return nil, helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+	t := &models.AcademicTerm{Name: body.Name, IsCurrent: body.IsCurrent}
+
+	var err error
+	if t.StartDate, err = time.Parse(time.DateOnly, body.StartDate); err != nil {
+		return nil, helpers.Error(c, "start_date must be YYYY-MM-DD", http.StatusBadRequest)
+	}
+	if t.EndDate, err = time.Parse(time.DateOnly, body.EndDate); err != nil {
+		return nil, helpers.Error(c, "end_date must be YYYY-MM-DD", http.StatusBadRequest)
+	}
+	if body.RegistrationOpensOn != "" {
+		opens, err := time.Parse(time.DateOnly, body.RegistrationOpensOn)
+		if err != nil {
+			return nil, helpers.Error(c, "registration_opens_on must be YYYY-MM-DD", http.StatusBadRequest)
+		}
+		t.RegistrationOpensOn = &opens
+	}
+	if body.RegistrationClosesOn != "" {
+		closes, err := time.Parse(time.DateOnly, body.RegistrationClosesOn)
+		if err != nil {
+			return nil, helpers.Error(c, "registration_closes_on must be YYYY-MM-DD", http.StatusBadRequest)
+		}
+		t.RegistrationClosesOn = &closes
+	}
+	return t, nil
+}
+
+func termError(c echo.Context, err error) error {
+	var invalid validator.ValidationErrors
+	switch {
+	case errors.As(err, &invalid):
+		return helpers.Error(c, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrTermNotFound), errors.Is(err, repository.ErrNoCurrentTerm):
+		return helpers.Error(c, err.Error(), http.StatusNotFound)
+	case errors.Is(err, repository.ErrTermOverlap), errors.Is(err, repository.ErrTermNameTaken), errors.Is(err, repository.ErrTermInUse):
+		return helpers.Error(c, err.Error(), http.StatusConflict)
+	case errors.Is(err, term.ErrInvalidTermDates), errors.Is(err, term.ErrInvalidRegistrationWindow):
+		return helpers.Error(c, err.Error(), http.StatusBadRequest)
+	default:
+		return helpers.Error(c, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,402 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"eduhub/server/internal/helpers"
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+	"eduhub/server/internal/services/timetable"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+type TimetableHandler struct {
+	timetableService timetable.TimetableService
+}
+
+// TimetableBlockRequest creates or updates a weekly timetable block. DayOfWeek
+// is 0 (Sunday) to 6 and times are HH:MM or HH:MM:SS.
+type TimetableBlockRequest struct {
+	CourseID     int          `json:"course_id"`
+	DepartmentID *int         `json:"department_id,omitempty"`
+	TermID       *int         `json:"term_id,omitempty"`
+	ClassID      *int         `json:"class_id,omitempty"`
+	DayOfWeek    time.Weekday `json:"day_of_week"`
+	StartTime    string       `json:"start_time"`
+	EndTime      string       `json:"end_time"`
+	RoomNumber   *string      `json:"room_number,omitempty"`
+	FacultyID    *string      `json:"faculty_id,omitempty"`
+}
+
+// TimetableBlockResponse is a timetable block with its times as HH:MM:SS.
+type TimetableBlockResponse struct {
+	*models.TimeTableBlock
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+// GenerateTimetableRequest asks the solver for a draft timetable of the
+// term (the current one if TermID is unset). The problem's fields sit at the
+// top level of the body.
+type GenerateTimetableRequest struct {
+	Name   string `json:"name"`
+	TermID *int   `json:"term_id,omitempty"`
+	models.TimetableProblem
+}
+
+// TimetableDraftResponse is a draft with its blocks' times as HH:MM:SS.
+type TimetableDraftResponse struct {
+	*models.TimetableDraft
+	Blocks []*TimetableBlockResponse `json:"blocks,omitempty"`
+}
+
+// TimetableClashResponse is the body of a 409 for a clashing block.
+type TimetableClashResponse struct {
+	Message string                    `json:"message"`
+	Clashes []*TimetableBlockResponse `json:"clashes"`
+}
+
+func NewTimetableHandler(timetableService timetable.TimetableService) *TimetableHandler {
+	return &TimetableHandler{
+		timetableService: timetableService,
+	}
+}
+
+func (h *TimetableHandler) CreateBlock(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+
+	block, err := bindTimetableBlock(c)
+	if err != nil {
+		return err
+	}
+	block.CollegeID = collegeID
+
+	if err := h.timetableService.CreateBlock(ctx, block); err != nil {
+		return timetableError(c, err)
+	}
+	return helpers.Success(c, timetableBlockResponse(block), http.StatusCreated)
+}
+
+func (h *TimetableHandler) GetBlock(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	blockID, err := helpers.GetIDFromParam(c, "blockID")
+	if err != nil {
+		return err
+	}
+
+	block, err := h.timetableService.GetBlock(ctx, collegeID, blockID)
+	if err != nil {
+		return timetableError(c, err)
+	}
+	return helpers.Success(c, timetableBlockResponse(block), http.StatusOK)
+}
+
+func (h *TimetableHandler) UpdateBlock(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	blockID, err := helpers.GetIDFromParam(c, "blockID")
+	if err != nil {
+		return err
+	}
+
+	block, err := bindTimetableBlock(c)
+	if err != nil {
+		return err
+	}
+	block.ID, block.CollegeID = blockID, collegeID
+
+	if err := h.timetableService.UpdateBlock(ctx, block); err != nil {
+		return timetableError(c, err)
+	}
+	updated, err := h.timetableService.GetBlock(ctx, collegeID, blockID)
+	if err != nil {
+		return timetableError(c, err)
+	}
+	return helpers.Success(c, timetableBlockResponse(updated), http.StatusOK)
+}
+
+func (h *TimetableHandler) DeleteBlock(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	blockID, err := helpers.GetIDFromParam(c, "blockID")
+	if err != nil {
+		return err
+	}
+
+	if err := h.timetableService.DeleteBlock(ctx, collegeID, blockID); err != nil {
+		return timetableError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetClassTimetable returns the class's week in ?term_id=, defaulting to the
+// current term.
+func (h *TimetableHandler) GetClassTimetable(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	classID, err := helpers.GetIDFromParam(c, "classID")
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetTermID(c)
+	if err != nil {
+		return err
+	}
+
+	blocks, err := h.timetableService.GetClassTimetable(ctx, collegeID, classID, termID)
+	if err != nil {
+		return helpers.Error(c, "unable to get timetable", http.StatusInternalServerError)
+	}
+	return helpers.Success(c, timetableBlockResponses(blocks), http.StatusOK)
+}
+
+// GetFacultyTimetable returns the faculty member's week in ?term_id=,
+// defaulting to the current term.
+func (h *TimetableHandler) GetFacultyTimetable(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetTermID(c)
+	if err != nil {
+		return err
+	}
+
+	blocks, err := h.timetableService.GetFacultyTimetable(ctx, collegeID, c.Param("facultyID"), termID)
+	if err != nil {
+		return helpers.Error(c, "unable to get timetable", http.StatusInternalServerError)
+	}
+	return helpers.Success(c, timetableBlockResponses(blocks), http.StatusOK)
+}
+
+// GetRoomTimetable returns the room's week in ?term_id=, defaulting to the
+// current term.
+func (h *TimetableHandler) GetRoomTimetable(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetTermID(c)
+	if err != nil {
+		return err
+	}
+
+	blocks, err := h.timetableService.GetRoomTimetable(ctx, collegeID, c.Param("room"), termID)
+	if err != nil {
+		return helpers.Error(c, "unable to get timetable", http.StatusInternalServerError)
+	}
+	return helpers.Success(c, timetableBlockResponses(blocks), http.StatusOK)
+}
+
+// GenerateDraft solves the posted requirements into a draft timetable that
+// can be reviewed and then published.
+func (h *TimetableHandler) GenerateDraft(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	userID, err := helpers.ExtractIdentityID(c)
+	if err != nil {
+		return err
+	}
+	var body GenerateTimetableRequest
+	if err := c.Bind(&body); err != nil {
+		return helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+
+	draft, err := h.timetableService.GenerateDraft(ctx, collegeID, body.TermID, body.Name, userID, body.TimetableProblem)
+	if err != nil {
+		return timetableError(c, err)
+	}
+	return helpers.Success(c, timetableDraftResponse(draft), http.StatusCreated)
+}
+
+// GetDrafts lists drafts in ?term_id=, or in every term.
+func (h *TimetableHandler) GetDrafts(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	termID, err := helpers.GetTermID(c)
+	if err != nil {
+		return err
+	}
+	limit, offset := helpers.GetPagination(c)
+
+	drafts, err := h.timetableService.GetDrafts(ctx, collegeID, termID, limit, offset)
+	if err != nil {
+		return helpers.Error(c, "unable to get timetable drafts", http.StatusInternalServerError)
+	}
+	return helpers.Success(c, drafts, http.StatusOK)
+}
+
+func (h *TimetableHandler) GetDraft(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	draftID, err := helpers.GetIDFromParam(c, "draftID")
+	if err != nil {
+		return err
+	}
+
+	draft, err := h.timetableService.GetDraft(ctx, collegeID, draftID)
+	if err != nil {
+		return timetableError(c, err)
+	}
+	return helpers.Success(c, timetableDraftResponse(draft), http.StatusOK)
+}
+
+// PublishDraft replaces the timetable of the draft's courses with the draft.
+func (h *TimetableHandler) PublishDraft(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	userID, err := helpers.ExtractIdentityID(c)
+	if err != nil {
+		return err
+	}
+	draftID, err := helpers.GetIDFromParam(c, "draftID")
+	if err != nil {
+		return err
+	}
+
+	draft, err := h.timetableService.PublishDraft(ctx, collegeID, draftID, userID)
+	if err != nil {
+		return timetableError(c, err)
+	}
+	return helpers.Success(c, timetableDraftResponse(draft), http.StatusOK)
+}
+
+func (h *TimetableHandler) DeleteDraft(c echo.Context) error {
+	ctx := c.Request().Context()
+	collegeID, err := helpers.ExtractCollegeID(c)
+	if err != nil {
+		return err
+	}
+	draftID, err := helpers.GetIDFromParam(c, "draftID")
+	if err != nil {
+		return err
+	}
+
+	if err := h.timetableService.DeleteDraft(ctx, collegeID, draftID); err != nil {
+		return timetableError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func bindTimetableBlock(c echo.Context) (*models.TimeTableBlock, error) {
+	var body TimetableBlockRequest
+	if err := c.Bind(&body); err != nil {
+		return nil, helpers.Error(c, "invalid request body", http.StatusBadRequest)
+	}
+	block := &models.TimeTableBlock{
+		CourseID:     body.CourseID,
+		DepartmentID: body.DepartmentID,
+		TermID:       body.TermID,
+		ClassID:      body.ClassID,
+		DayOfWeek:    body.DayOfWeek,
+		RoomNumber:   body.RoomNumber,
+		FacultyID:    body.FacultyID,
+	}
+
+	var ok bool
+	if block.StartTime, ok = parseClock(body.StartTime); !ok {
+		return nil, helpers.Error(c, "start_time must be HH:MM or HH:MM:SS", http.StatusBadRequest)
+	}
+	if block.EndTime, ok = parseClock(body.EndTime); !ok {
+		return nil, helpers.Error(c, "end_time must be HH:MM or HH:MM:SS", http.StatusBadRequest)
+	}
+	return block, nil
+}
+
+// parseClock parses an HH:MM or HH:MM:SS time of day.
+func parseClock(s string) (pgtype.Time, bool) {
+	for _, layout := range []string{"15:04", time.TimeOnly} {
+		if t, err := time.Parse(layout, s); err == nil {
+			seconds := t.Hour()*3600 + t.Minute()*60 + t.Second()
+			return pgtype.Time{Microseconds: int64(seconds) * 1e6, Status: pgtype.Present}, true
+		}
+	}
+	return pgtype.Time{}, false
+}
+
+func formatClock(t pgtype.Time) string {
+	return time.Time{}.Add(time.Duration(t.Microseconds) * time.Microsecond).Format(time.TimeOnly)
+}
+
+func timetableBlockResponse(block *models.TimeTableBlock) *TimetableBlockResponse {
+	return &TimetableBlockResponse{
+		TimeTableBlock: block,
+		StartTime:      formatClock(block.StartTime),
+		EndTime:        formatClock(block.EndTime),
+	}
+}
+
+func timetableBlockResponses(blocks []*models.TimeTableBlock) []*TimetableBlockResponse {
+	responses := make([]*TimetableBlockResponse, len(blocks))
+	for i, block := range blocks {
+		responses[i] = timetableBlockResponse(block)
+	}
+	return responses
+}
+
+func timetableDraftResponse(draft *models.TimetableDraft) *TimetableDraftResponse {
+	return &TimetableDraftResponse{
+		TimetableDraft: draft,
+		Blocks:         timetableBlockResponses(draft.Blocks),
+	}
+}
+
+func timetableError(c echo.Context, err error) error {
+	var (
+		clash         *repository.TimeTableClashError
+		unschedulable *timetable.UnschedulableError
+		invalid       validator.ValidationErrors
+	)
+	switch {
+	case errors.As(err, &clash):
+		return helpers.Error(c, TimetableClashResponse{Message: clash.Error(), Clashes: timetableBlockResponses(clash.Clashes)}, http.StatusConflict)
+	case errors.As(err, &unschedulable):
+		return helpers.Error(c, unschedulable, http.StatusUnprocessableEntity)
+	case errors.As(err, &invalid):
+		return helpers.Error(c, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrTimeTableBlockNotFound), errors.Is(err, repository.ErrTimetableDraftNotFound), errors.Is(err, repository.ErrCourseNotFound), errors.Is(err, repository.ErrTermNotFound):
+		return helpers.Error(c, err.Error(), http.StatusNotFound)
+	case errors.Is(err, repository.ErrTimetableDraftPublished):
+		return helpers.Error(c, err.Error(), http.StatusConflict)
+	case errors.Is(err, timetable.ErrInvalidBlockTimes), errors.Is(err, timetable.ErrInvalidDayOfWeek), errors.Is(err, timetable.ErrInvalidDayHours):
+		return helpers.Error(c, err.Error(), http.StatusBadRequest)
+	default:
+		return helpers.Error(c, err.Error(), http.StatusInternalServerError)
+	}
+}
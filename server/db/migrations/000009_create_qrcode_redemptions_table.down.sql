@@ -0,0 +1,14 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_qrcode_redemptions_student_id;
+DROP INDEX IF EXISTS idx_qrcodes_lecture_id;
+DROP TABLE IF EXISTS qrcode_redemptions;
+
+ALTER TABLE qrcodes DROP CONSTRAINT IF EXISTS fk_qrcodes_lecture;
+ALTER TABLE qrcodes DROP COLUMN IF EXISTS lecture_id;
+ALTER TABLE qrcodes DROP COLUMN IF EXISTS course_id;
+ALTER TABLE qrcodes DROP COLUMN IF EXISTS college_id;
+DELETE FROM qrcodes WHERE student_id IS NULL;
+ALTER TABLE qrcodes ALTER COLUMN student_id SET NOT NULL;
+
+COMMIT;
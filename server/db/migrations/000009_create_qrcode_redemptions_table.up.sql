@@ -0,0 +1,40 @@
+BEGIN;
+
+-- QR codes are now issued per lecture rather than per student, so the
+-- student link becomes optional and the lecture context is stored instead.
+ALTER TABLE qrcodes ALTER COLUMN student_id DROP NOT NULL;
+ALTER TABLE qrcodes ADD COLUMN IF NOT EXISTS college_id INT;
+ALTER TABLE qrcodes ADD COLUMN IF NOT EXISTS course_id INT;
+ALTER TABLE qrcodes ADD COLUMN IF NOT EXISTS lecture_id INT;
+
+ALTER TABLE qrcodes
+    ADD CONSTRAINT fk_qrcodes_lecture
+        FOREIGN KEY (lecture_id)
+        REFERENCES lectures(id)
+        ON DELETE CASCADE;
+
+CREATE TABLE IF NOT EXISTS qrcode_redemptions (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    qrcode_id INT NOT NULL,
+    student_id INT NOT NULL,
+    college_id INT NOT NULL,
+    redeemed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_qrcode_redemptions_qrcode
+        FOREIGN KEY (qrcode_id)
+        REFERENCES qrcodes(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_qrcode_redemptions_student
+        FOREIGN KEY (student_id)
+        REFERENCES students(student_id)
+        ON DELETE CASCADE,
+
+    -- A student may redeem a given QR code only once
+    UNIQUE (qrcode_id, student_id)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_qrcodes_lecture_id ON qrcodes (lecture_id);
+CREATE INDEX IF NOT EXISTS idx_qrcode_redemptions_student_id ON qrcode_redemptions (student_id);
+
+COMMIT;
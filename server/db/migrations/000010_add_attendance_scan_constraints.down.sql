@@ -0,0 +1,14 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_attendance_scans_student_id;
+DROP INDEX IF EXISTS idx_attendance_scans_lecture_id;
+DROP INDEX IF EXISTS idx_student_devices_student_id;
+DROP TABLE IF EXISTS attendance_scans;
+DROP TABLE IF EXISTS student_devices;
+
+ALTER TABLE lectures DROP COLUMN IF EXISTS require_registered_device;
+ALTER TABLE lectures DROP COLUMN IF EXISTS geofence_radius_meters;
+ALTER TABLE lectures DROP COLUMN IF EXISTS longitude;
+ALTER TABLE lectures DROP COLUMN IF EXISTS latitude;
+
+COMMIT;
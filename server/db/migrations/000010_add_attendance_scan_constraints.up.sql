@@ -0,0 +1,61 @@
+BEGIN;
+
+-- Optional per-lecture scan constraints
+ALTER TABLE lectures ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+ALTER TABLE lectures ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+ALTER TABLE lectures ADD COLUMN IF NOT EXISTS geofence_radius_meters INT;
+ALTER TABLE lectures ADD COLUMN IF NOT EXISTS require_registered_device BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS student_devices (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    college_id INT NOT NULL,
+    student_id INT NOT NULL,
+    device_fingerprint VARCHAR(255) NOT NULL,
+    label VARCHAR(100),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_student_devices_student
+        FOREIGN KEY (student_id)
+        REFERENCES students(student_id)
+        ON DELETE CASCADE,
+
+    -- A device belongs to one student in a college, so one phone cannot
+    -- scan for a whole class
+    CONSTRAINT uq_student_devices_college_fingerprint
+        UNIQUE (college_id, device_fingerprint)
+);
+
+-- Every QR scan, accepted or not, so faculty can review flagged ones
+CREATE TABLE IF NOT EXISTS attendance_scans (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    college_id INT NOT NULL,
+    student_id INT NOT NULL,
+    course_id INT NOT NULL,
+    lecture_id INT NOT NULL,
+    qr_nonce VARCHAR(255) NOT NULL,
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    distance_meters DOUBLE PRECISION,
+    device_fingerprint VARCHAR(255),
+    accepted BOOLEAN NOT NULL,
+    reasons TEXT[] NOT NULL DEFAULT '{}',
+    scanned_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_attendance_scans_student
+        FOREIGN KEY (student_id)
+        REFERENCES students(student_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_attendance_scans_lecture
+        FOREIGN KEY (lecture_id)
+        REFERENCES lectures(id)
+        ON DELETE CASCADE
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_student_devices_student_id ON student_devices (student_id);
+CREATE INDEX IF NOT EXISTS idx_attendance_scans_lecture_id ON attendance_scans (lecture_id);
+CREATE INDEX IF NOT EXISTS idx_attendance_scans_student_id ON attendance_scans (student_id);
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_attendance_audit_log_lecture_id;
+DROP INDEX IF EXISTS idx_attendance_audit_log_attendance_id;
+DROP TRIGGER IF EXISTS trg_attendance_audit_log_append_only ON attendance_audit_log;
+DROP FUNCTION IF EXISTS attendance_audit_log_append_only();
+DROP TABLE IF EXISTS attendance_audit_log;
+
+COMMIT;
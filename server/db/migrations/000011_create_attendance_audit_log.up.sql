@@ -0,0 +1,34 @@
+BEGIN;
+
+-- Append-only history of every attendance status change
+CREATE TABLE IF NOT EXISTS attendance_audit_log (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    attendance_id INT NOT NULL,
+    college_id INT NOT NULL,
+    student_id INT NOT NULL,
+    course_id INT NOT NULL,
+    lecture_id INT NOT NULL,
+    old_status VARCHAR(50), -- NULL when the record was created
+    new_status VARCHAR(50) NOT NULL,
+    actor_identity_id VARCHAR(255) NOT NULL, -- Kratos identity ID, or "system"
+    source VARCHAR(50) NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    -- No FK to attendance: history must outlive the rows it describes
+);
+
+CREATE OR REPLACE FUNCTION attendance_audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'attendance_audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_attendance_audit_log_append_only
+    BEFORE UPDATE OR DELETE ON attendance_audit_log
+    FOR EACH ROW EXECUTE FUNCTION attendance_audit_log_append_only();
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_attendance_audit_log_attendance_id ON attendance_audit_log (attendance_id);
+CREATE INDEX IF NOT EXISTS idx_attendance_audit_log_lecture_id ON attendance_audit_log (college_id, lecture_id);
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_leave_request_attachments_request_id;
+DROP INDEX IF EXISTS idx_leave_requests_college_status;
+DROP INDEX IF EXISTS idx_leave_requests_student_id;
+DROP TABLE IF EXISTS leave_request_attachments;
+DROP TABLE IF EXISTS leave_requests;
+
+COMMIT;
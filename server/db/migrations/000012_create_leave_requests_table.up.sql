@@ -0,0 +1,52 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS leave_requests (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    college_id INT NOT NULL,
+    student_id INT NOT NULL,
+    leave_type VARCHAR(50) NOT NULL CHECK (leave_type IN ('medical', 'sports', 'official_duty')),
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    reason TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled')),
+    reviewed_by VARCHAR(255), -- Kratos identity ID of the approving or rejecting user
+    review_note TEXT,
+    reviewed_at TIMESTAMPTZ,
+    excused_lectures INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_leave_requests_student
+        FOREIGN KEY (student_id)
+        REFERENCES students(student_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_leave_requests_college
+        FOREIGN KEY (college_id)
+        REFERENCES colleges(id)
+        ON DELETE CASCADE,
+
+    CHECK (end_date >= start_date)
+);
+
+-- Supporting documents (medical certificates, duty letters). Files live in
+-- external storage; only their location is kept here.
+CREATE TABLE IF NOT EXISTS leave_request_attachments (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    leave_request_id INT NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    file_url TEXT NOT NULL,
+    content_type VARCHAR(100),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_leave_request_attachments_request
+        FOREIGN KEY (leave_request_id)
+        REFERENCES leave_requests(id)
+        ON DELETE CASCADE
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_leave_requests_student_id ON leave_requests (student_id);
+CREATE INDEX IF NOT EXISTS idx_leave_requests_college_status ON leave_requests (college_id, status);
+CREATE INDEX IF NOT EXISTS idx_leave_request_attachments_request_id ON leave_request_attachments (leave_request_id);
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+
+-- One attendance policy per college. Colleges without a row use the defaults
+-- in models.DefaultAttendancePolicy.
+CREATE TABLE IF NOT EXISTS attendance_policies (
+    college_id INT PRIMARY KEY,
+    grace_minutes INT NOT NULL DEFAULT 10 CHECK (grace_minutes >= 0),
+    late_cutoff_minutes INT NOT NULL DEFAULT 30,
+    absent_cutoff_minutes INT, -- NULL keeps marking open for the whole lecture
+    lates_per_absence INT NOT NULL DEFAULT 0 CHECK (lates_per_absence >= 0), -- 0 disables the late penalty
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_attendance_policies_college
+        FOREIGN KEY (college_id)
+        REFERENCES colleges(id)
+        ON DELETE CASCADE,
+
+    CHECK (late_cutoff_minutes >= grace_minutes),
+    CHECK (absent_cutoff_minutes IS NULL OR absent_cutoff_minutes >= late_cutoff_minutes)
+);
+
+COMMIT;
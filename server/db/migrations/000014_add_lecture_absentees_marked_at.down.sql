@@ -0,0 +1,7 @@
+BEGIN;
+
+-- start_time and end_time are kept; the repository depends on them.
+DROP INDEX IF EXISTS idx_lectures_pending_absentees;
+ALTER TABLE lectures DROP COLUMN IF EXISTS absentees_marked_at;
+
+COMMIT;
@@ -0,0 +1,20 @@
+BEGIN;
+
+-- The lecture's time span; 000020 adds the rest of the columns the lecture
+-- repository uses
+ALTER TABLE lectures ADD COLUMN IF NOT EXISTS start_time TIMESTAMPTZ;
+ALTER TABLE lectures ADD COLUMN IF NOT EXISTS end_time TIMESTAMPTZ;
+UPDATE lectures SET start_time = lecture_datetime WHERE start_time IS NULL;
+UPDATE lectures SET end_time = start_time + INTERVAL '1 hour' WHERE end_time IS NULL;
+
+-- Set by the absentee job once a finished lecture's missing attendance has
+-- been filled in; NULL means the lecture still has to be processed.
+ALTER TABLE lectures ADD COLUMN IF NOT EXISTS absentees_marked_at TIMESTAMPTZ;
+
+-- Lectures that ended before the job existed were never scanned against it;
+-- marking their missing students Absent now would rewrite past registers.
+UPDATE lectures SET absentees_marked_at = NOW() WHERE absentees_marked_at IS NULL AND end_time <= NOW();
+
+CREATE INDEX IF NOT EXISTS idx_lectures_pending_absentees ON lectures (end_time) WHERE absentees_marked_at IS NULL;
+
+COMMIT;
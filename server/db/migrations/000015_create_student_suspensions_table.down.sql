@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_student_suspensions_college_status;
+DROP INDEX IF EXISTS idx_student_suspensions_open;
+DROP TABLE IF EXISTS student_suspensions;
+
+COMMIT;
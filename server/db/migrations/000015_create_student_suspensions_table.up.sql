@@ -0,0 +1,34 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS student_suspensions (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    college_id INT NOT NULL,
+    student_id INT NOT NULL,
+    reason TEXT NOT NULL,
+    starts_on DATE NOT NULL,
+    ends_on DATE, -- NULL until lifted by an admin; otherwise lifted automatically after this date
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'active', 'lifted')),
+    suspended_by VARCHAR(255) NOT NULL, -- Kratos identity ID
+    lifted_by VARCHAR(255),
+    lift_reason TEXT,
+    lifted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_student_suspensions_student
+        FOREIGN KEY (student_id)
+        REFERENCES students(student_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_student_suspensions_college
+        FOREIGN KEY (college_id)
+        REFERENCES colleges(id)
+        ON DELETE CASCADE,
+
+    CHECK (ends_on IS NULL OR ends_on >= starts_on)
+);
+
+-- A student has at most one suspension that is not yet lifted
+CREATE UNIQUE INDEX IF NOT EXISTS idx_student_suspensions_open ON student_suspensions (student_id) WHERE status <> 'lifted';
+CREATE INDEX IF NOT EXISTS idx_student_suspensions_college_status ON student_suspensions (college_id, status);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_courses_college_name;
+ALTER TABLE courses DROP COLUMN IF EXISTS college_id;
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+
+-- Courses are scoped to a college like every other academic record.
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS college_id INT REFERENCES colleges(id) ON DELETE CASCADE;
+
+-- Existing courses take the college of their enrollments, then of their
+-- lectures, both of which always carry one.
+UPDATE courses c
+SET college_id = COALESCE(
+    (SELECT e.college_id FROM enrollments e WHERE e.course_id = c.id GROUP BY e.college_id ORDER BY COUNT(*) DESC, e.college_id LIMIT 1),
+    (SELECT l.college_id FROM lectures l WHERE l.course_id = c.id GROUP BY l.college_id ORDER BY COUNT(*) DESC, l.college_id LIMIT 1)
+)
+WHERE c.college_id IS NULL;
+
+-- Courses with neither belong to the only college, if there is just one.
+UPDATE courses
+SET college_id = (SELECT MIN(id) FROM colleges)
+WHERE college_id IS NULL
+  AND (SELECT COUNT(*) FROM colleges) = 1;
+
+CREATE INDEX IF NOT EXISTS idx_courses_college_name ON courses (college_id, name);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_enrollments_waitlist;
+ALTER TABLE courses DROP COLUMN IF EXISTS capacity;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+-- NULL means the course takes any number of students.
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS capacity INT CHECK (capacity > 0);
+
+-- Waitlisted enrollments are promoted oldest first.
+CREATE INDEX IF NOT EXISTS idx_enrollments_waitlist ON enrollments (course_id, updated_at, id) WHERE status = 'waitlisted';
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS enrollment_overrides;
+DROP TABLE IF EXISTS course_prerequisites;
+
+COMMIT;
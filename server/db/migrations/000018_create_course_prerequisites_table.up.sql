@@ -0,0 +1,34 @@
+BEGIN;
+
+-- course_id requires prerequisite_id. The service rejects edges that would
+-- close a cycle.
+CREATE TABLE IF NOT EXISTS course_prerequisites (
+    course_id INT NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    prerequisite_id INT NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    college_id INT NOT NULL REFERENCES colleges(id) ON DELETE CASCADE,
+    -- Best final-exam percentage the student needs in the prerequisite; NULL
+    -- means the pass mark of the college's grading scale.
+    min_percentage NUMERIC(5,2) CHECK (min_percentage BETWEEN 0 AND 100),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (course_id, prerequisite_id),
+    CHECK (course_id <> prerequisite_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_course_prerequisites_prerequisite ON course_prerequisites (prerequisite_id);
+
+-- One row per student enrolled despite unmet prerequisites.
+CREATE TABLE IF NOT EXISTS enrollment_overrides (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    college_id INT NOT NULL REFERENCES colleges(id) ON DELETE CASCADE,
+    course_id INT NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    student_id INT NOT NULL REFERENCES students(student_id) ON DELETE CASCADE,
+    overridden_by VARCHAR(255) NOT NULL, -- Kratos identity of the admin
+    reason TEXT NOT NULL,
+    unmet JSONB NOT NULL, -- The requirements that were waived
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_enrollment_overrides_course ON enrollment_overrides (college_id, course_id, created_at);
+
+COMMIT;
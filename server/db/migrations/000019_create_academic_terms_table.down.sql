@@ -0,0 +1,12 @@
+BEGIN;
+
+ALTER TABLE IF EXISTS grades DROP COLUMN IF EXISTS term_id;
+ALTER TABLE IF EXISTS timetable_blocks DROP COLUMN IF EXISTS term_id;
+ALTER TABLE lectures DROP COLUMN IF EXISTS term_id;
+DROP INDEX IF EXISTS idx_enrollments_student_course_term;
+ALTER TABLE enrollments DROP COLUMN IF EXISTS term_id;
+ALTER TABLE enrollments ADD CONSTRAINT enrollments_student_id_course_id_key UNIQUE (student_id, course_id);
+
+DROP TABLE IF EXISTS academic_terms;
+
+COMMIT;
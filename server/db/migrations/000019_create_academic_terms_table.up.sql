@@ -0,0 +1,46 @@
+BEGIN;
+
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+CREATE TABLE IF NOT EXISTS academic_terms (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    college_id INT NOT NULL,
+    name VARCHAR(100) NOT NULL, -- e.g., "Fall 2026", "Semester 1 2026-2027"
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    registration_opens_on DATE, -- Optional enrollment window
+    registration_closes_on DATE,
+    is_current BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_academic_terms_college
+        FOREIGN KEY (college_id)
+        REFERENCES colleges(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT academic_terms_college_name_key UNIQUE (college_id, name),
+    CHECK (end_date >= start_date),
+    CHECK (registration_opens_on IS NULL OR registration_closes_on IS NULL OR registration_closes_on >= registration_opens_on),
+    -- A date belongs to at most one term of a college
+    CONSTRAINT academic_terms_no_overlap
+        EXCLUDE USING gist (college_id WITH =, daterange(start_date, end_date, '[]') WITH &&)
+);
+
+-- A college has at most one current term
+CREATE UNIQUE INDEX IF NOT EXISTS idx_academic_terms_current ON academic_terms (college_id) WHERE is_current;
+
+-- Existing rows keep a NULL term
+ALTER TABLE enrollments ADD COLUMN IF NOT EXISTS term_id INT REFERENCES academic_terms(id) ON DELETE RESTRICT;
+ALTER TABLE lectures ADD COLUMN IF NOT EXISTS term_id INT REFERENCES academic_terms(id) ON DELETE RESTRICT;
+ALTER TABLE IF EXISTS timetable_blocks ADD COLUMN IF NOT EXISTS term_id INT REFERENCES academic_terms(id) ON DELETE RESTRICT;
+ALTER TABLE IF EXISTS grades ADD COLUMN IF NOT EXISTS term_id INT REFERENCES academic_terms(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_enrollments_term_id ON enrollments (term_id);
+
+-- A student may take a course again in a later term, once per term
+ALTER TABLE enrollments DROP CONSTRAINT IF EXISTS enrollments_student_id_course_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_enrollments_student_course_term ON enrollments (student_id, course_id, COALESCE(term_id, 0));
+CREATE INDEX IF NOT EXISTS idx_lectures_term_id ON lectures (term_id);
+
+COMMIT;
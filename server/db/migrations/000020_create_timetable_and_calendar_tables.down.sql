@@ -0,0 +1,10 @@
+BEGIN;
+
+-- The lecture columns added alongside are kept; the repository depends on them.
+DROP INDEX IF EXISTS idx_lectures_timetable_slot;
+ALTER TABLE lectures DROP COLUMN IF EXISTS timetable_block_id;
+
+DROP TABLE IF EXISTS calendar_blocks;
+DROP TABLE IF EXISTS timetable_blocks;
+
+COMMIT;
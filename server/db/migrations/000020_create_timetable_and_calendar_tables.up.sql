@@ -0,0 +1,69 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS timetable_blocks (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    college_id INT NOT NULL,
+    department_id INT,
+    course_id INT NOT NULL,
+    term_id INT,
+    class_id INT, -- Section or batch
+    day_of_week SMALLINT NOT NULL CHECK (day_of_week BETWEEN 0 AND 6), -- 0 = Sunday, as time.Weekday
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    room_number VARCHAR(50),
+    faculty_id VARCHAR(255), -- Kratos identity ID
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_timetable_blocks_college
+        FOREIGN KEY (college_id)
+        REFERENCES colleges(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_timetable_blocks_course
+        FOREIGN KEY (course_id)
+        REFERENCES courses(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_timetable_blocks_term
+        FOREIGN KEY (term_id)
+        REFERENCES academic_terms(id)
+        ON DELETE RESTRICT,
+
+    CHECK (end_time > start_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_timetable_blocks_college_term ON timetable_blocks (college_id, term_id);
+CREATE INDEX IF NOT EXISTS idx_timetable_blocks_course_id ON timetable_blocks (course_id);
+
+CREATE TABLE IF NOT EXISTS calendar_blocks (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    college_id INT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    event_type VARCHAR(20) NOT NULL CHECK (event_type IN ('exam', 'holiday', 'event', 'deadline', 'other')),
+    date DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_calendar_blocks_college
+        FOREIGN KEY (college_id)
+        REFERENCES colleges(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_calendar_blocks_college_date ON calendar_blocks (college_id, date);
+
+-- Columns the lecture repository reads and writes
+ALTER TABLE lectures ADD COLUMN IF NOT EXISTS title VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE lectures ADD COLUMN IF NOT EXISTS description VARCHAR(200) NOT NULL DEFAULT '';
+ALTER TABLE lectures ADD COLUMN IF NOT EXISTS start_time TIMESTAMPTZ;
+ALTER TABLE lectures ADD COLUMN IF NOT EXISTS end_time TIMESTAMPTZ;
+ALTER TABLE lectures ADD COLUMN IF NOT EXISTS meeting_link VARCHAR(255) NOT NULL DEFAULT '';
+UPDATE lectures SET start_time = lecture_datetime WHERE start_time IS NULL;
+UPDATE lectures SET end_time = start_time + INTERVAL '1 hour' WHERE end_time IS NULL;
+
+-- Lectures generated from the timetable remember their block, so generating
+-- the same range twice does not duplicate them
+ALTER TABLE lectures ADD COLUMN IF NOT EXISTS timetable_block_id INT REFERENCES timetable_blocks(id) ON DELETE SET NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_lectures_timetable_slot ON lectures (timetable_block_id, start_time) WHERE timetable_block_id IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS timetable_draft_blocks;
+DROP TABLE IF EXISTS timetable_drafts;
+
+COMMIT;
@@ -0,0 +1,56 @@
+BEGIN;
+
+-- Timetables produced by the solver, reviewed before they replace the live
+-- timetable_blocks of their courses
+CREATE TABLE IF NOT EXISTS timetable_drafts (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    college_id INT NOT NULL,
+    term_id INT,
+    name VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'published')),
+    problem JSONB NOT NULL, -- The solver input, kept for review and re-runs
+    penalty INT NOT NULL DEFAULT 0, -- Soft constraint cost; lower is better
+    warnings JSONB NOT NULL DEFAULT '[]', -- Soft constraints the draft breaks
+    created_by VARCHAR(255) NOT NULL, -- Kratos identity ID
+    published_by VARCHAR(255),
+    published_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_timetable_drafts_college
+        FOREIGN KEY (college_id)
+        REFERENCES colleges(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_timetable_drafts_term
+        FOREIGN KEY (term_id)
+        REFERENCES academic_terms(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_timetable_drafts_college_term ON timetable_drafts (college_id, term_id);
+
+CREATE TABLE IF NOT EXISTS timetable_draft_blocks (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    draft_id INT NOT NULL,
+    course_id INT NOT NULL,
+    class_id INT,
+    day_of_week SMALLINT NOT NULL CHECK (day_of_week BETWEEN 0 AND 6),
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    room_number VARCHAR(50),
+    faculty_id VARCHAR(255),
+
+    CONSTRAINT fk_timetable_draft_blocks_draft
+        FOREIGN KEY (draft_id)
+        REFERENCES timetable_drafts(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_timetable_draft_blocks_course
+        FOREIGN KEY (course_id)
+        REFERENCES courses(id)
+        ON DELETE CASCADE,
+
+    CHECK (end_time > start_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_timetable_draft_blocks_draft_id ON timetable_draft_blocks (draft_id);
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_calendar_blocks_course_id;
+ALTER TABLE calendar_blocks DROP CONSTRAINT IF EXISTS calendar_blocks_time_range;
+ALTER TABLE calendar_blocks DROP CONSTRAINT IF EXISTS calendar_blocks_date_range;
+ALTER TABLE calendar_blocks DROP COLUMN IF EXISTS course_id;
+ALTER TABLE calendar_blocks DROP COLUMN IF EXISTS end_time;
+ALTER TABLE calendar_blocks DROP COLUMN IF EXISTS start_time;
+ALTER TABLE calendar_blocks DROP COLUMN IF EXISTS end_date;
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+-- Multi-day and timed events, and events that only concern one course
+ALTER TABLE calendar_blocks ADD COLUMN IF NOT EXISTS end_date DATE;
+UPDATE calendar_blocks SET end_date = date WHERE end_date IS NULL;
+ALTER TABLE calendar_blocks ALTER COLUMN end_date SET NOT NULL;
+
+ALTER TABLE calendar_blocks ADD COLUMN IF NOT EXISTS start_time TIMESTAMPTZ;
+ALTER TABLE calendar_blocks ADD COLUMN IF NOT EXISTS end_time TIMESTAMPTZ;
+ALTER TABLE calendar_blocks ADD COLUMN IF NOT EXISTS course_id INT REFERENCES courses(id) ON DELETE CASCADE;
+
+ALTER TABLE calendar_blocks ADD CONSTRAINT calendar_blocks_date_range CHECK (end_date >= date);
+ALTER TABLE calendar_blocks ADD CONSTRAINT calendar_blocks_time_range
+    CHECK ((start_time IS NULL AND end_time IS NULL) OR end_time > start_time);
+
+CREATE INDEX IF NOT EXISTS idx_calendar_blocks_course_id ON calendar_blocks (course_id);
+
+COMMIT;
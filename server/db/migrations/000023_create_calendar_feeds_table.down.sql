@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_calendar_blocks_external_uid;
+ALTER TABLE calendar_blocks DROP COLUMN IF EXISTS external_uid;
+DROP TABLE IF EXISTS calendar_feeds;
+
+COMMIT;
@@ -0,0 +1,32 @@
+BEGIN;
+
+-- Token-protected iCalendar subscriptions; only a hash of the token is kept
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    college_id INT NOT NULL,
+    identity_id VARCHAR(255) NOT NULL, -- Kratos identity ID of the subscriber
+    role VARCHAR(20) NOT NULL,
+    student_id INT, -- Set for students
+    token_hash CHAR(64) NOT NULL UNIQUE, -- SHA-256, hex
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+
+    CONSTRAINT fk_calendar_feeds_college
+        FOREIGN KEY (college_id)
+        REFERENCES colleges(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_calendar_feeds_student
+        FOREIGN KEY (student_id)
+        REFERENCES students(student_id)
+        ON DELETE CASCADE
+);
+
+-- One live feed per user; rotating revokes the old one
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_feeds_active ON calendar_feeds (college_id, identity_id) WHERE revoked_at IS NULL;
+
+-- Events imported from ICS files keep their UID so re-imports update them
+ALTER TABLE calendar_blocks ADD COLUMN IF NOT EXISTS external_uid VARCHAR(255);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_blocks_external_uid ON calendar_blocks (college_id, external_uid) WHERE external_uid IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_grades_assessment_student;
+ALTER TABLE IF EXISTS grades DROP COLUMN IF EXISTS assessment_id;
+DROP TABLE IF EXISTS course_assessments;
+
+COMMIT;
@@ -0,0 +1,39 @@
+BEGIN;
+
+-- Weighted components of a course's grade in a term, e.g. midterm-1 worth
+-- 20% out of 50 marks
+CREATE TABLE IF NOT EXISTS course_assessments (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    college_id INT NOT NULL,
+    course_id INT NOT NULL,
+    term_id INT,
+    name VARCHAR(100) NOT NULL,
+    exam_type VARCHAR(20) NOT NULL CHECK (exam_type IN ('midterm-1', 'midterm-2', 'final', 'assignment')),
+    weight NUMERIC(5,2) NOT NULL CHECK (weight > 0 AND weight <= 100), -- Percent of the course total
+    max_marks NUMERIC(7,2) NOT NULL CHECK (max_marks > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_course_assessments_college
+        FOREIGN KEY (college_id)
+        REFERENCES colleges(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_course_assessments_course
+        FOREIGN KEY (course_id)
+        REFERENCES courses(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_course_assessments_term
+        FOREIGN KEY (term_id)
+        REFERENCES academic_terms(id)
+        ON DELETE RESTRICT
+);
+
+-- One component of each type per course and term
+CREATE UNIQUE INDEX IF NOT EXISTS idx_course_assessments_component
+    ON course_assessments (course_id, COALESCE(term_id, 0), exam_type);
+
+-- Scores for an assessment are grades rows, one per student
+ALTER TABLE IF EXISTS grades ADD COLUMN IF NOT EXISTS assessment_id INT REFERENCES course_assessments(id) ON DELETE CASCADE;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_grades_assessment_student ON grades (assessment_id, student_id) WHERE assessment_id IS NOT NULL;
+
+COMMIT;
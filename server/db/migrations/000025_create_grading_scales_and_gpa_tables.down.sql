@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS gpa_snapshots;
+DROP TABLE IF EXISTS course_results;
+DROP TABLE IF EXISTS grading_scales;
+
+COMMIT;
@@ -0,0 +1,89 @@
+BEGIN;
+
+-- Letter grade bands of a college. Absolute scales band the course
+-- percentage; relative scales band the student's percentile in the course,
+-- with those below pass_percentage given the lowest band.
+CREATE TABLE IF NOT EXISTS grading_scales (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    college_id INT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    method VARCHAR(20) NOT NULL CHECK (method IN ('absolute', 'relative')),
+    pass_percentage NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (pass_percentage >= 0 AND pass_percentage <= 100),
+    bands JSONB NOT NULL, -- [{letter, min_score, grade_point}], highest first
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_grading_scales_college
+        FOREIGN KEY (college_id)
+        REFERENCES colleges(id)
+        ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_grading_scales_name ON grading_scales (college_id, name);
+-- Results are computed with the college's default scale
+CREATE UNIQUE INDEX IF NOT EXISTS idx_grading_scales_default ON grading_scales (college_id) WHERE is_default;
+
+-- A student's final result in a course and term
+CREATE TABLE IF NOT EXISTS course_results (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    college_id INT NOT NULL,
+    student_id INT NOT NULL,
+    course_id INT NOT NULL,
+    term_id INT,
+    percentage NUMERIC(5,2) NOT NULL,
+    grade_letter VARCHAR(5) NOT NULL,
+    grade_point NUMERIC(4,2) NOT NULL,
+    credits INT NOT NULL, -- The course's credits when the result was computed
+    grading_scale_id INT REFERENCES grading_scales(id) ON DELETE SET NULL, -- NULL for the built-in scale
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_course_results_college
+        FOREIGN KEY (college_id)
+        REFERENCES colleges(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_course_results_student
+        FOREIGN KEY (student_id)
+        REFERENCES students(student_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_course_results_course
+        FOREIGN KEY (course_id)
+        REFERENCES courses(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_course_results_term
+        FOREIGN KEY (term_id)
+        REFERENCES academic_terms(id)
+        ON DELETE RESTRICT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_course_results_student_course ON course_results (student_id, course_id, COALESCE(term_id, 0));
+
+-- SGPA of each term a student has results in, and CGPA through that term
+CREATE TABLE IF NOT EXISTS gpa_snapshots (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    college_id INT NOT NULL,
+    student_id INT NOT NULL,
+    term_id INT,
+    sgpa NUMERIC(4,2) NOT NULL,
+    cgpa NUMERIC(4,2) NOT NULL,
+    term_credits INT NOT NULL,
+    credits_earned INT NOT NULL, -- Through the term, passed courses only
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_gpa_snapshots_college
+        FOREIGN KEY (college_id)
+        REFERENCES colleges(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_gpa_snapshots_student
+        FOREIGN KEY (student_id)
+        REFERENCES students(student_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_gpa_snapshots_term
+        FOREIGN KEY (term_id)
+        REFERENCES academic_terms(id)
+        ON DELETE RESTRICT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_gpa_snapshots_student_term ON gpa_snapshots (student_id, COALESCE(term_id, 0));
+
+COMMIT;
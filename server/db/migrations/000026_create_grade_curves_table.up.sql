@@ -0,0 +1,43 @@
+BEGIN;
+
+-- Committed grading curves of a course and term. Each commit is a new
+-- version; while the latest is locked the course's scores and assessments
+-- cannot change.
+CREATE TABLE IF NOT EXISTS grade_curves (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    college_id INT NOT NULL,
+    course_id INT NOT NULL,
+    term_id INT,
+    version INT NOT NULL,
+    method VARCHAR(20) NOT NULL CHECK (method IN ('mean_sd', 'percentile', 'fixed')),
+    bands JSONB NOT NULL, -- The bands as given: [{letter, cutoff, grade_point}]
+    cutoffs JSONB NOT NULL, -- Resolved to percentages: [{letter, min_score, grade_point}]
+    mean NUMERIC(5,2) NOT NULL,
+    std_dev NUMERIC(5,2) NOT NULL,
+    distribution JSONB NOT NULL, -- [{letter, count}]
+    is_locked BOOLEAN NOT NULL DEFAULT TRUE,
+    committed_by VARCHAR(255) NOT NULL, -- Kratos identity ID
+    committed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    unlocked_by VARCHAR(255),
+    unlocked_at TIMESTAMPTZ,
+    unlock_reason TEXT,
+
+    CONSTRAINT fk_grade_curves_college
+        FOREIGN KEY (college_id)
+        REFERENCES colleges(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_grade_curves_course
+        FOREIGN KEY (course_id)
+        REFERENCES courses(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_grade_curves_term
+        FOREIGN KEY (term_id)
+        REFERENCES academic_terms(id)
+        ON DELETE RESTRICT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_grade_curves_version ON grade_curves (course_id, COALESCE(term_id, 0), version);
+-- At most one locked version per course and term
+CREATE UNIQUE INDEX IF NOT EXISTS idx_grade_curves_locked ON grade_curves (course_id, COALESCE(term_id, 0)) WHERE is_locked;
+
+COMMIT;
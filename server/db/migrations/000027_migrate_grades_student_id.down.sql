@@ -0,0 +1,10 @@
+BEGIN;
+
+-- The table itself is kept: it may have existed before the up migration.
+-- Grades dropped for unknown students are not restored.
+DROP INDEX IF EXISTS idx_grades_course;
+DROP INDEX IF EXISTS idx_grades_student;
+ALTER TABLE IF EXISTS grades DROP CONSTRAINT IF EXISTS fk_grades_student;
+ALTER TABLE IF EXISTS grades ALTER COLUMN student_id TYPE VARCHAR(255) USING student_id::text;
+
+COMMIT;
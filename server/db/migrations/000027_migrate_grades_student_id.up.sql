@@ -0,0 +1,62 @@
+BEGIN;
+
+-- grades predates the migrations and kept student_id as text, holding either
+-- the student's ID or their Kratos identity. Create it where it is missing
+-- and otherwise convert student_id to reference students.
+CREATE TABLE IF NOT EXISTS grades (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    student_id INT NOT NULL,
+    course_id INT NOT NULL,
+    college_id INT NOT NULL,
+    term_id INT REFERENCES academic_terms(id) ON DELETE RESTRICT,
+    assessment_id INT REFERENCES course_assessments(id) ON DELETE CASCADE,
+    marks_obtained DOUBLE PRECISION NOT NULL,
+    total_marks DOUBLE PRECISION NOT NULL,
+    grade_letter VARCHAR(5),
+    semester INT NOT NULL DEFAULT 0,
+    academic_year VARCHAR(20) NOT NULL DEFAULT '',
+    exam_type VARCHAR(20) NOT NULL,
+    graded_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    comments TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_grades_course
+        FOREIGN KEY (course_id)
+        REFERENCES courses(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_grades_college
+        FOREIGN KEY (college_id)
+        REFERENCES colleges(id)
+        ON DELETE RESTRICT
+);
+
+DO $$
+BEGIN
+    IF (SELECT data_type FROM information_schema.columns
+        WHERE table_name = 'grades' AND column_name = 'student_id') <> 'integer' THEN
+        -- Kratos identities are resolved to the student in the same college;
+        -- grades of no known student cannot be kept
+        UPDATE grades g SET student_id = s.student_id::text
+        FROM students s
+        WHERE s.kratos_identity_id = g.student_id AND s.college_id = g.college_id;
+
+        DELETE FROM grades g
+        WHERE NOT EXISTS (SELECT 1 FROM students s WHERE s.student_id::text = g.student_id);
+
+        ALTER TABLE grades ALTER COLUMN student_id TYPE INT USING student_id::int;
+    END IF;
+END $$;
+
+ALTER TABLE grades
+    ADD CONSTRAINT fk_grades_student
+        FOREIGN KEY (student_id)
+        REFERENCES students(student_id)
+        ON DELETE CASCADE;
+
+-- Missed by 000024 where grades did not exist yet
+CREATE UNIQUE INDEX IF NOT EXISTS idx_grades_assessment_student ON grades (assessment_id, student_id) WHERE assessment_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_grades_student ON grades (college_id, student_id);
+CREATE INDEX IF NOT EXISTS idx_grades_course ON grades (college_id, course_id);
+
+COMMIT;
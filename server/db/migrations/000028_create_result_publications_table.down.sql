@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS grade_reevaluations;
+DROP TABLE IF EXISTS result_publications;
+
+COMMIT;
@@ -0,0 +1,80 @@
+BEGIN;
+
+-- Where a course's results in a term are on the way to publication. A course
+-- without a row is a draft; its scores and assessments can only change while
+-- it is one, and students only see them once published.
+CREATE TABLE IF NOT EXISTS result_publications (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    college_id INT NOT NULL,
+    course_id INT NOT NULL,
+    term_id INT,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('draft', 'submitted', 'approved', 'published')),
+    submitted_by VARCHAR(255), -- Kratos identity IDs
+    submitted_at TIMESTAMPTZ,
+    approved_by VARCHAR(255),
+    approved_at TIMESTAMPTZ,
+    published_by VARCHAR(255),
+    published_at TIMESTAMPTZ,
+    bands JSONB, -- The bands published results were graded on, as percentages: [{letter, min_score, grade_point}]
+    returned_by VARCHAR(255),
+    returned_at TIMESTAMPTZ,
+    return_reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_result_publications_college
+        FOREIGN KEY (college_id)
+        REFERENCES colleges(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_result_publications_course
+        FOREIGN KEY (course_id)
+        REFERENCES courses(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_result_publications_term
+        FOREIGN KEY (term_id)
+        REFERENCES academic_terms(id)
+        ON DELETE RESTRICT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_result_publications_course_term ON result_publications (course_id, COALESCE(term_id, 0));
+CREATE INDEX IF NOT EXISTS idx_result_publications_status ON result_publications (college_id, status);
+
+-- Requests to re-evaluate a published grade; the only way its marks change.
+-- Resolved requests keep the marks before and after.
+CREATE TABLE IF NOT EXISTS grade_reevaluations (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    college_id INT NOT NULL,
+    grade_id INT NOT NULL,
+    student_id INT NOT NULL,
+    course_id INT NOT NULL,
+    term_id INT,
+    reason TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'rejected')),
+    requested_by VARCHAR(255) NOT NULL,
+    previous_marks DOUBLE PRECISION NOT NULL,
+    revised_marks DOUBLE PRECISION,
+    resolved_by VARCHAR(255),
+    resolved_at TIMESTAMPTZ,
+    resolution_note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_grade_reevaluations_college
+        FOREIGN KEY (college_id)
+        REFERENCES colleges(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_grade_reevaluations_grade
+        FOREIGN KEY (grade_id)
+        REFERENCES grades(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_grade_reevaluations_student
+        FOREIGN KEY (student_id)
+        REFERENCES students(student_id)
+        ON DELETE CASCADE
+);
+
+-- A grade has at most one open request
+CREATE UNIQUE INDEX IF NOT EXISTS idx_grade_reevaluations_pending ON grade_reevaluations (grade_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_grade_reevaluations_course ON grade_reevaluations (course_id, status);
+CREATE INDEX IF NOT EXISTS idx_grade_reevaluations_student ON grade_reevaluations (student_id);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE courses DROP COLUMN IF EXISTS department_id;
+DROP TABLE IF EXISTS departments;
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+
+-- Departments group courses; the head (hod) approves their results before
+-- publication.
+CREATE TABLE IF NOT EXISTS departments (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    college_id INT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    hod VARCHAR(255) NOT NULL DEFAULT '', -- Kratos identity of the head of department
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_departments_college
+        FOREIGN KEY (college_id)
+        REFERENCES colleges(id)
+        ON DELETE CASCADE,
+    CONSTRAINT uq_departments_college_name UNIQUE (college_id, name)
+);
+
+-- NULL means the course has no department, and so no one to approve its results.
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS department_id INT REFERENCES departments(id) ON DELETE SET NULL;
+
+COMMIT;
@@ -10,6 +10,8 @@ type Config struct {
 	DB         *repository.DB
 	DBConfig   *DBConfig
 	AuthConfig *AuthConfig
+	QRConfig   *QRConfig
+	Scheduler  *SchedulerConfig
 	AppPort    string
 }
 
@@ -23,12 +25,22 @@ func NewConfig() (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	qrConfig, err := LoadQRConfig()
+	if err != nil {
+		return nil, err
+	}
+	schedulerConfig, err := LoadSchedulerConfig()
+	if err != nil {
+		return nil, err
+	}
 
 	AppPort := os.Getenv("APP_PORT")
 	cfg := &Config{
 		DB:         db,
 		DBConfig:   dbConfig,
 		AuthConfig: authConfig,
+		QRConfig:   qrConfig,
+		Scheduler:  schedulerConfig,
 		AppPort:    AppPort,
 	}
 
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+const defaultQRRotationSeconds = 15
+
+type QRConfig struct {
+	SigningKey     []byte        // HMAC key used to sign attendance QR tokens
+	RotationPeriod time.Duration // How long a single QR token stays valid
+}
+
+// LoadQRConfig loads the attendance QR signing configuration from environment variables.
+// QR_SIGNING_KEY should be set (and shared) in every deployment that runs more than one
+// replica; when it is missing an ephemeral key is generated so local setups keep working.
+func LoadQRConfig() (*QRConfig, error) {
+	rotation := defaultQRRotationSeconds
+	if raw := os.Getenv("QR_ROTATION_SECONDS"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds <= 0 {
+			return nil, fmt.Errorf("invalid QR_ROTATION_SECONDS value: %q", raw)
+		}
+		rotation = seconds
+	}
+
+	key := []byte(os.Getenv("QR_SIGNING_KEY"))
+	if len(key) == 0 {
+		log.Println("Warning: QR_SIGNING_KEY not set, generating an ephemeral key (QR codes will not survive restarts)")
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate QR signing key: %w", err)
+		}
+	}
+
+	return &QRConfig{
+		SigningKey:     key,
+		RotationPeriod: time.Duration(rotation) * time.Second,
+	}, nil
+}
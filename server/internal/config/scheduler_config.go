@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultAbsenteeIntervalSeconds   = 60
+	defaultAbsenteeBatchSize         = 50
+	defaultSuspensionIntervalSeconds = 300
+	defaultSuspensionBatchSize       = 20
+)
+
+type SchedulerConfig struct {
+	AbsenteeInterval    time.Duration // How often to look for lectures that have ended
+	AbsenteeBatchSize   int           // Lectures claimed per transaction
+	SuspensionInterval  time.Duration // How often to start and lift student suspensions
+	SuspensionBatchSize int           // Suspensions claimed per transaction
+}
+
+// LoadSchedulerConfig loads background job settings from environment variables.
+// Every replica can run the jobs; they coordinate through row locks.
+func LoadSchedulerConfig() (*SchedulerConfig, error) {
+	interval, err := positiveIntEnv("ABSENTEE_JOB_INTERVAL_SECONDS", defaultAbsenteeIntervalSeconds)
+	if err != nil {
+		return nil, err
+	}
+	batchSize, err := positiveIntEnv("ABSENTEE_JOB_BATCH_SIZE", defaultAbsenteeBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	suspensionInterval, err := positiveIntEnv("SUSPENSION_JOB_INTERVAL_SECONDS", defaultSuspensionIntervalSeconds)
+	if err != nil {
+		return nil, err
+	}
+	suspensionBatchSize, err := positiveIntEnv("SUSPENSION_JOB_BATCH_SIZE", defaultSuspensionBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SchedulerConfig{
+		AbsenteeInterval:    time.Duration(interval) * time.Second,
+		AbsenteeBatchSize:   batchSize,
+		SuspensionInterval:  time.Duration(suspensionInterval) * time.Second,
+		SuspensionBatchSize: suspensionBatchSize,
+	}, nil
+}
+
+func positiveIntEnv(name string, fallback int) (int, error) {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("invalid %s value: %q", name, raw)
+	}
+	return value, nil
+}
@@ -0,0 +1,16 @@
+package helpers
+
+import (
+	"eduhub/server/internal/services/auth"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ExtractIdentityID returns the Kratos identity ID of the authenticated user.
+func ExtractIdentityID(c echo.Context) (string, error) {
+	identity, ok := c.Get("identity").(*auth.Identity)
+	if !ok || identity == nil || identity.ID == "" {
+		return "", echo.NewHTTPError(401, "unauthorized")
+	}
+	return identity.ID, nil
+}
@@ -0,0 +1,26 @@
+package helpers
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+)
+
+// GetPagination reads the limit and offset query parameters, falling back to
+// DefaultPageLimit and 0 for missing or invalid values.
+func GetPagination(c echo.Context) (uint64, uint64) {
+	limit := uint64(DefaultPageLimit)
+	if l, err := strconv.ParseUint(c.QueryParam("limit"), 10, 64); err == nil && l > 0 {
+		limit = min(l, MaxPageLimit)
+	}
+	var offset uint64
+	if o, err := strconv.ParseUint(c.QueryParam("offset"), 10, 64); err == nil {
+		offset = o
+	}
+	return limit, offset
+}
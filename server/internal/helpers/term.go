@@ -0,0 +1,21 @@
+package helpers
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetTermID reads the optional term_id query parameter. It returns nil if the
+// parameter is missing, leaving services to default to the current term.
+func GetTermID(c echo.Context) (*int, error) {
+	raw := c.QueryParam("term_id")
+	if raw == "" {
+		return nil, nil
+	}
+	termID, err := strconv.Atoi(raw)
+	if err != nil || termID <= 0 {
+		return nil, Error(c, "invalid term_id", 400)
+	}
+	return &termID, nil
+}
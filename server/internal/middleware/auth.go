@@ -6,6 +6,7 @@ import (
 
 	"eduhub/server/internal/helpers"
 	"eduhub/server/internal/services/auth"
+	"eduhub/server/internal/services/course"
 	"eduhub/server/internal/services/student"
 
 	"github.com/labstack/echo/v4"
@@ -31,15 +32,17 @@ type AuthMiddleware struct {
 	AuthService auth.AuthService
 	// StudentRepo repository.StudentRepository
 	StudentService student.StudentService
+	CourseService  course.CourseService
 }
 
 // NewAuthMiddleware now accepts an auth.AuthService instance,
 // ensuring that the middleware has access to both authentication
 // (session validation) and authorization (permission checking) logic.
-func NewAuthMiddleware(authSvc auth.AuthService, studentService student.StudentService) *AuthMiddleware {
+func NewAuthMiddleware(authSvc auth.AuthService, studentService student.StudentService, courseService course.CourseService) *AuthMiddleware {
 	return &AuthMiddleware{
 		AuthService:    authSvc,
 		StudentService: studentService,
+		CourseService:  courseService,
 	}
 }
 
@@ -193,3 +196,34 @@ func (m *AuthMiddleware) VerifyStudentOwnership(next echo.HandlerFunc) echo.Hand
 		return next(c)
 	}
 }
+
+// VerifyCourseInstructor ensures faculty only act on courses they instruct,
+// the one in the courseID path parameter. Admins act on any course.
+func (m *AuthMiddleware) VerifyCourseInstructor(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		identity, ok := c.Get(identityContextKey).(*auth.Identity)
+		if !ok || identity == nil {
+			return helpers.Error(c, "Unauthorized", http.StatusUnauthorized)
+		}
+		if m.AuthService.HasRole(identity, RoleAdmin) {
+			return next(c)
+		}
+
+		collegeID, err := helpers.ExtractCollegeID(c)
+		if err != nil {
+			return err
+		}
+		courseID, err := strconv.Atoi(c.Param("courseID"))
+		if err != nil || courseID <= 0 {
+			return helpers.Error(c, "Invalid course ID", http.StatusBadRequest)
+		}
+		instructs, err := m.CourseService.IsCourseInstructor(c.Request().Context(), collegeID, courseID, identity.ID)
+		if err != nil {
+			return helpers.Error(c, "Error checking course instructor", http.StatusInternalServerError)
+		}
+		if !instructs {
+			return helpers.Error(c, "Access denied", http.StatusForbidden)
+		}
+		return next(c)
+	}
+}
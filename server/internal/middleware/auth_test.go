@@ -56,7 +56,7 @@ func TestNewAuthMiddleware(t *testing.T) {
 	mockAuthSvc := new(MockAuthService)
 	mockStudentSvc := new(MockStudentService)
 
-	middleware := NewAuthMiddleware(mockAuthSvc, mockStudentSvc)
+	middleware := NewAuthMiddleware(mockAuthSvc, mockStudentSvc, nil)
 
 	assert.NotNil(t, middleware)
 	assert.Equal(t, mockAuthSvc, middleware.AuthService)
@@ -66,7 +66,7 @@ func TestNewAuthMiddleware(t *testing.T) {
 func TestAuthMiddleware_ValidateSession(t *testing.T) {
 	mockAuthSvc := new(MockAuthService)
 	mockStudentSvc := new(MockStudentService)
-	middleware := NewAuthMiddleware(mockAuthSvc, mockStudentSvc)
+	middleware := NewAuthMiddleware(mockAuthSvc, mockStudentSvc, nil)
 	validToken := "valid-token"
 	invalidToken := "invalid-token"
 	identity := &auth.Identity{ID: "test-id"}
@@ -110,7 +110,7 @@ func TestAuthMiddleware_ValidateSession(t *testing.T) {
 func TestAuthMiddleware_RequireCollege(t *testing.T) {
 	mockAuthSvc := new(MockAuthService)
 	mockStudentSvc := new(MockStudentService)
-	middleware := NewAuthMiddleware(mockAuthSvc, mockStudentSvc)
+	middleware := NewAuthMiddleware(mockAuthSvc, mockStudentSvc, nil)
 	collegeID := 123
 	identity := &auth.Identity{Traits: auth.Traits{College: auth.College{ID: collegeID}}}
 
@@ -139,7 +139,7 @@ func TestAuthMiddleware_RequireCollege(t *testing.T) {
 func TestAuthMiddleware_LoadStudentProfile(t *testing.T) {
 	mockAuthSvc := new(MockAuthService)
 	mockStudentSvc := new(MockStudentService)
-	middleware := NewAuthMiddleware(mockAuthSvc, mockStudentSvc)
+	middleware := NewAuthMiddleware(mockAuthSvc, mockStudentSvc, nil)
 	kratosID := "student-kratos-id"
 	studentID := 1
 	student := &models.Student{StudentID: studentID, KratosID: kratosID, IsActive: true}
@@ -206,7 +206,7 @@ func TestAuthMiddleware_LoadStudentProfile(t *testing.T) {
 func TestAuthMiddleware_RequireRole(t *testing.T) {
 	mockAuthSvc := new(MockAuthService)
 	mockStudentSvc := new(MockStudentService)
-	middleware := NewAuthMiddleware(mockAuthSvc, mockStudentSvc)
+	middleware := NewAuthMiddleware(mockAuthSvc, mockStudentSvc, nil)
 	identity := &auth.Identity{ID: "test-id"}
 	roleAdmin := "admin"
 	roleStudent := "student"
@@ -248,7 +248,7 @@ func TestAuthMiddleware_RequireRole(t *testing.T) {
 func TestAuthMiddleware_RequirePermission(t *testing.T) {
 	mockAuthSvc := new(MockAuthService)
 	mockStudentSvc := new(MockStudentService)
-	middleware := NewAuthMiddleware(mockAuthSvc, mockStudentSvc)
+	middleware := NewAuthMiddleware(mockAuthSvc, mockStudentSvc, nil)
 	identity := &auth.Identity{ID: "test-id"}
 	subject := "test-subject"
 	resource := "test-resource"
@@ -302,7 +302,7 @@ func TestAuthMiddleware_RequirePermission(t *testing.T) {
 func TestAuthMiddleware_VerifyStudentOwnership(t *testing.T) {
 	mockAuthSvc := new(MockAuthService)
 	mockStudentSvc := new(MockStudentService)
-	middleware := NewAuthMiddleware(mockAuthSvc, mockStudentSvc)
+	middleware := NewAuthMiddleware(mockAuthSvc, mockStudentSvc, nil)
 	studentID := 123
 	otherStudentID := 456
 	identity := &auth.Identity{ID: "test-id"}
@@ -13,7 +13,8 @@ func NewMiddleware(services *services.Services) *Middleware {
 	authSvc := services.Auth
 
 	studentService := services.StudentService
+	courseService := services.CourseService
 	return &Middleware{
-		Auth: NewAuthMiddleware(authSvc, studentService),
+		Auth: NewAuthMiddleware(authSvc, studentService, courseService),
 	}
 }
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// AcademicTerm is a semester or other teaching period of a college. Terms of
+// a college never overlap, and at most one is current; list endpoints default
+// to the current term.
+type AcademicTerm struct {
+	ID                   int        `db:"id" json:"id"`
+	CollegeID            int        `db:"college_id" json:"college_id"`
+	Name                 string     `db:"name" json:"name" validate:"required,max=100"`
+	StartDate            time.Time  `db:"start_date" json:"start_date"`
+	EndDate              time.Time  `db:"end_date" json:"end_date"`
+	RegistrationOpensOn  *time.Time `db:"registration_opens_on" json:"registration_opens_on,omitempty"`
+	RegistrationClosesOn *time.Time `db:"registration_closes_on" json:"registration_closes_on,omitempty"`
+	IsCurrent            bool       `db:"is_current" json:"is_current"`
+	CreatedAt            time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt            time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// RegistrationOpenOn reports whether day, a local date, falls in the term's
+// registration window. A window without a start or end is open on that side.
+func (t *AcademicTerm) RegistrationOpenOn(day time.Time) bool {
+	date := day.Format(time.DateOnly)
+	if t.RegistrationOpensOn != nil && date < t.RegistrationOpensOn.UTC().Format(time.DateOnly) {
+		return false
+	}
+	if t.RegistrationClosesOn != nil && date > t.RegistrationClosesOn.UTC().Format(time.DateOnly) {
+		return false
+	}
+	return true
+}
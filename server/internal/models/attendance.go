@@ -20,3 +20,168 @@ type StudentAttendanceStatus struct {
 	StudentID int    `json:"student_id" validate:"required,gt=0"`
 	Status    string `json:"status" validate:"required,oneof=Present Absent"` // Ensure status is either Present or Absent
 }
+
+// Bulk attendance modes. Atomic writes all rows or none; best effort writes
+// every row it can and reports the rest.
+const (
+	BulkModeAtomic     = "atomic"
+	BulkModeBestEffort = "best_effort"
+)
+
+// Per-student outcomes of a bulk attendance request.
+const (
+	BulkResultMarked     = "marked"
+	BulkResultRejected   = "rejected"    // Failed validation or enrollment checks
+	BulkResultFailed     = "failed"      // The database write failed
+	BulkResultNotApplied = "not_applied" // Valid, but an atomic request was abandoned
+)
+
+type BulkAttendanceResult struct {
+	StudentID int    `json:"student_id"`
+	Status    string `json:"status,omitempty"`
+	Result    string `json:"result"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Reasons recorded against an attendance scan. Rejections block the scan;
+// the others explain why it was accepted.
+const (
+	ScanReasonNotEnrolled         = "not_enrolled"
+	ScanReasonLocationMissing     = "location_missing"
+	ScanReasonOutsideGeofence     = "outside_geofence"
+	ScanReasonDeviceMissing       = "device_missing"
+	ScanReasonDeviceNotRegistered = "device_not_registered"
+	ScanReasonWindowClosed        = "window_closed"
+	ScanReasonWithinGeofence      = "within_geofence"
+	ScanReasonDeviceVerified      = "device_verified"
+	ScanReasonNoConstraints       = "no_constraints"
+)
+
+// ScanMetadata is what the client reports alongside a QR scan.
+type ScanMetadata struct {
+	Latitude          *float64 `json:"latitude,omitempty" validate:"omitempty,latitude"`
+	Longitude         *float64 `json:"longitude,omitempty" validate:"omitempty,longitude"`
+	DeviceFingerprint string   `json:"device_fingerprint,omitempty" validate:"omitempty,max=255"`
+}
+
+// AttendanceScan records the outcome of a single QR scan so faculty can review
+// rejected (flagged) attempts.
+type AttendanceScan struct {
+	ID                int       `db:"id" json:"id"`
+	CollegeID         int       `db:"college_id" json:"college_id"`
+	StudentID         int       `db:"student_id" json:"student_id"`
+	CourseID          int       `db:"course_id" json:"course_id"`
+	LectureID         int       `db:"lecture_id" json:"lecture_id"`
+	QRNonce           string    `db:"qr_nonce" json:"qr_nonce"`
+	Latitude          *float64  `db:"latitude" json:"latitude,omitempty"`
+	Longitude         *float64  `db:"longitude" json:"longitude,omitempty"`
+	DistanceMeters    *float64  `db:"distance_meters" json:"distance_meters,omitempty"`
+	DeviceFingerprint *string   `db:"device_fingerprint" json:"device_fingerprint,omitempty"`
+	Accepted          bool      `db:"accepted" json:"accepted"`
+	Reasons           []string  `db:"reasons" json:"reasons"`
+	ScannedAt         time.Time `db:"scanned_at" json:"scanned_at"`
+}
+
+// StudentDevice is a device a student has registered for attendance scans.
+type StudentDevice struct {
+	ID                int       `db:"id" json:"id"`
+	CollegeID         int       `db:"college_id" json:"college_id"`
+	StudentID         int       `db:"student_id" json:"student_id"`
+	DeviceFingerprint string    `db:"device_fingerprint" json:"device_fingerprint" validate:"required,max=255"`
+	Label             string    `db:"label" json:"label,omitempty" validate:"omitempty,max=100"`
+	IsActive          bool      `db:"is_active" json:"is_active"`
+	CreatedAt         time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// StudentAttendanceReport is a student's attendance summary for one course.
+// Percentage counts Present and Late against the lectures held so far, not
+// counting lectures the student was Excused from. LatePenalty is the number of
+// lates turned into absences by the college's attendance policy.
+type StudentAttendanceReport struct {
+	StudentID     int     `db:"student_id" json:"student_id"`
+	RollNo        string  `db:"roll_no" json:"roll_no"`
+	TotalLectures int     `db:"total_lectures" json:"total_lectures"`
+	Present       int     `db:"present" json:"present"`
+	Absent        int     `db:"absent" json:"absent"`
+	Late          int     `db:"late" json:"late"`
+	Excused       int     `db:"excused" json:"excused"`
+	LatePenalty   int     `db:"late_penalty" json:"late_penalty"`
+	Percentage    float64 `db:"percentage" json:"percentage"`
+}
+
+// AttendancePolicy decides how a scan is classified by how long after the
+// lecture start it arrives: up to GraceMinutes it is Present, up to
+// LateCutoffMinutes Late, and Absent after that. Scans after
+// AbsentCutoffMinutes are refused; nil keeps marking open.
+// A positive LatesPerAbsence makes reports count every N lates as one absence.
+type AttendancePolicy struct {
+	CollegeID           int       `db:"college_id" json:"college_id"`
+	GraceMinutes        int       `db:"grace_minutes" json:"grace_minutes"`
+	LateCutoffMinutes   int       `db:"late_cutoff_minutes" json:"late_cutoff_minutes"`
+	AbsentCutoffMinutes *int      `db:"absent_cutoff_minutes" json:"absent_cutoff_minutes,omitempty"`
+	LatesPerAbsence     int       `db:"lates_per_absence" json:"lates_per_absence"`
+	UpdatedAt           time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// DefaultAttendancePolicy applies to colleges that have not configured one.
+func DefaultAttendancePolicy(collegeID int) *AttendancePolicy {
+	return &AttendancePolicy{
+		CollegeID:         collegeID,
+		GraceMinutes:      10,
+		LateCutoffMinutes: 30,
+	}
+}
+
+// CourseAttendanceReport aggregates attendance for every student enrolled in a course.
+type CourseAttendanceReport struct {
+	CourseID      int                        `json:"course_id"`
+	TotalLectures int                        `json:"total_lectures"`
+	Threshold     *float64                   `json:"threshold,omitempty"` // Only set for shortage reports
+	Students      []*StudentAttendanceReport `json:"students"`
+}
+
+// AttendanceRegisterRow is one student's line in an attendance register export.
+// Statuses is keyed by lecture ID; lectures without a record are left out.
+type AttendanceRegisterRow struct {
+	StudentID int
+	RollNo    string
+	Statuses  map[int]string
+}
+
+// Sources recorded against attendance audit entries.
+const (
+	AuditSourceQR           = "qr"
+	AuditSourceBulk         = "bulk"
+	AuditSourceManualUpdate = "manual_update"
+	AuditSourceFreeze       = "freeze"
+	AuditSourceUnfreeze     = "unfreeze"
+	AuditSourceLeave        = "leave"
+	AuditSourceAbsenteeJob  = "absentee_job"
+)
+
+// AuditActorSystem is the actor recorded for changes made by background jobs.
+const AuditActorSystem = "system"
+
+// AuditInfo says who changed attendance, through which path and why.
+type AuditInfo struct {
+	ActorID string // Kratos identity ID
+	Source  string
+	Reason  string
+}
+
+// AttendanceAuditEntry is one row of the append-only attendance history.
+type AttendanceAuditEntry struct {
+	ID           int       `db:"id" json:"id"`
+	AttendanceID int       `db:"attendance_id" json:"attendance_id"`
+	CollegeID    int       `db:"college_id" json:"college_id"`
+	StudentID    int       `db:"student_id" json:"student_id"`
+	CourseID     int       `db:"course_id" json:"course_id"`
+	LectureID    int       `db:"lecture_id" json:"lecture_id"`
+	OldStatus    *string   `db:"old_status" json:"old_status"`
+	NewStatus    string    `db:"new_status" json:"new_status"`
+	ActorID      string    `db:"actor_identity_id" json:"actor_identity_id"`
+	Source       string    `db:"source" json:"source"`
+	Reason       *string   `db:"reason" json:"reason,omitempty"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
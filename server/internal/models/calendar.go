@@ -12,23 +12,32 @@ const (
 	EventTypeOther    CalendarEventType = "other"
 )
 
+// CalendarBlock is an event on the college calendar. It covers the days from
+// Date through EndDate, all day unless StartTime and EndTime are set. Events
+// with a CourseID only concern that course.
 type CalendarBlock struct {
 	ID          int               `db:"id" json:"id"`
 	CollegeID   int               `db:"college_id" json:"college_id"`
-	Title       string            `db:"title" json:"title"`
+	CourseID    *int              `db:"course_id" json:"course_id,omitempty"`
+	Title       string            `db:"title" json:"title" validate:"required,max=255"`
 	Description string            `db:"description" json:"description"`
-	EventType   CalendarEventType `db:"event_type" json:"event_type"`
+	EventType   CalendarEventType `db:"event_type" json:"event_type" validate:"required,oneof=exam holiday event deadline other"`
 	Date        time.Time         `db:"date" json:"date"`
-	// Optional: For multi-day events or events with specific times
-	// StartTime   *time.Time        `db:"start_time" json:"start_time,omitempty"`
-	// EndTime     *time.Time        `db:"end_time" json:"end_time,omitempty"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	EndDate     time.Time         `db:"end_date" json:"end_date"`
+	// Optional: For events with specific times
+	StartTime *time.Time `db:"start_time" json:"start_time,omitempty"`
+	EndTime   *time.Time `db:"end_time" json:"end_time,omitempty"`
+	// UID of the iCalendar event this was imported from
+	ExternalUID *string   `db:"external_uid" json:"external_uid,omitempty"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
 }
 
-// CalendarBlockFilter can be used for querying lists of calendar blocks
+// CalendarBlockFilter can be used for querying lists of calendar blocks. The
+// date range matches events that overlap it.
 type CalendarBlockFilter struct {
-	CollegeID *int               `json:"college_id"` // Mandatory for most queries
+	CollegeID *int               `json:"college_id"`          // Mandatory for most queries
+	CourseID  *int               `json:"course_id,omitempty"` // The course's events and college-wide ones
 	EventType *CalendarEventType `json:"event_type,omitempty"`
 	StartDate *time.Time         `json:"start_date,omitempty"` // Inclusive
 	EndDate   *time.Time         `json:"end_date,omitempty"`   // Inclusive
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// CalendarFeed is a user's iCalendar subscription. Only a hash of its token
+// is stored; Token is set once, when the feed is created.
+type CalendarFeed struct {
+	ID         int        `db:"id" json:"id"`
+	CollegeID  int        `db:"college_id" json:"college_id"`
+	IdentityID string     `db:"identity_id" json:"-"`
+	Role       string     `db:"role" json:"role"`
+	StudentID  *int       `db:"student_id" json:"student_id,omitempty"`
+	TokenHash  string     `db:"token_hash" json:"-"`
+	Token      string     `db:"-" json:"token,omitempty"`
+	URL        string     `db:"-" json:"url,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+}
+
+// HolidayImport reports what importing an iCalendar holiday list did.
+// Events are matched to earlier imports by their UID.
+type HolidayImport struct {
+	Created int                  `json:"created"`
+	Updated int                  `json:"updated"`
+	Skipped []*HolidayImportSkip `json:"skipped"`
+}
+
+// HolidayImportSkip is an event that was not imported, and why.
+type HolidayImportSkip struct {
+	UID     string `json:"uid"`
+	Summary string `json:"summary"`
+	Reason  string `json:"reason"`
+}
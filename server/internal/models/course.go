@@ -12,6 +12,8 @@ type Course struct {
 	Description  string    `db:"description" json:"description" validate:"omitempty,max=200"`
 	Credits      int       `db:"credits" json:"credits" validate:"required,gte=1,lte=5"`
 	InstructorID int       `db:"instructor_id" json:"instructor_id" validate:"required,gte=1"`
+	Capacity     *int      `db:"capacity" json:"capacity,omitempty" validate:"omitempty,gte=1"`           // Seats; nil means unlimited
+	DepartmentID *int      `db:"department_id" json:"department_id,omitempty" validate:"omitempty,gte=1"` // Its head approves the course's results
 	CreatedAt    time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
 
@@ -25,13 +27,37 @@ type Lecture struct {
 	ID          int       `db:"id" json:"id" validate:"omitempty,gte=0"`                               // Primary Key
 	CourseID    int       `db:"course_id" json:"course_id" validate:"required,gte=1"`                  // Foreign key to courses table
 	CollegeID   int       `db:"college_id" json:"college_id" validate:"required,gte=1"`                // Denormalized, Foreign key to colleges table
+	TermID      *int      `db:"term_id" json:"term_id,omitempty"`                                      // Defaults to the term the lecture falls in
 	Title       string    `db:"title" json:"title" validate:"required,min=3,max=100"`                  // Title of the lecture
 	Description string    `db:"description" json:"description,omitempty" validate:"omitempty,max=200"` // Optional description
-	StartTime   time.Time `db:"start_time" json:"start_time" validate:"required,before_end_time"`      // Start time of the lecture
-	EndTime     time.Time `db:"end_time" json:"end_time" validate:"required,after_start_time"`         // End time of the lecture
+	StartTime   time.Time `db:"start_time" json:"start_time" validate:"required,ltfield=EndTime"`      // Start time of the lecture
+	EndTime     time.Time `db:"end_time" json:"end_time" validate:"required,gtfield=StartTime"`        // End time of the lecture
 	MeetingLink string    `db:"meeting_link" json:"meeting_link,omitempty" validate:"omitempty,url"`   // For online lectures
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`                                          // Timestamp of creation
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`                                          // Timestamp of last update
+
+	// Set on lectures generated from the timetable
+	TimetableBlockID *int `db:"timetable_block_id" json:"timetable_block_id,omitempty"`
+
+	// Optional attendance constraints, evaluated when a student scans the lecture QR code
+	Latitude                *float64 `db:"latitude" json:"latitude,omitempty" validate:"omitempty,latitude"`
+	Longitude               *float64 `db:"longitude" json:"longitude,omitempty" validate:"omitempty,longitude"`
+	GeofenceRadiusMeters    *int     `db:"geofence_radius_meters" json:"geofence_radius_meters,omitempty" validate:"omitempty,gt=0"`
+	RequireRegisteredDevice bool     `db:"require_registered_device" json:"require_registered_device"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"` // Timestamp of creation
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"` // Timestamp of last update
+}
+
+// LectureGeneration reports the outcome of generating a course's lectures
+// from its timetable.
+type LectureGeneration struct {
+	Created  []*Lecture `json:"created"`
+	Existing int        `json:"existing"`           // Slots that already had a generated lecture
+	Holidays []string   `json:"holidays,omitempty"` // YYYY-MM-DD dates skipped as holidays
+}
+
+// HasGeofence reports whether the lecture restricts scans to a location.
+func (l *Lecture) HasGeofence() bool {
+	return l.Latitude != nil && l.Longitude != nil && l.GeofenceRadiusMeters != nil
 }
 
 // QRCode represents a unique QR code for each lecture
@@ -5,16 +5,40 @@ import "time"
 type EnrollmentStatus = string
 
 const (
-	Active    EnrollmentStatus = "active"
-	Inactive  EnrollmentStatus = "inactive"
-	Completed EnrollmentStatus = "completed"
+	Active     EnrollmentStatus = "active"
+	Inactive   EnrollmentStatus = "inactive"
+	Completed  EnrollmentStatus = "completed"
+	Frozen     EnrollmentStatus = "frozen"     // Held while the student is suspended
+	Waitlisted EnrollmentStatus = "waitlisted" // Course was full; promoted oldest first as seats free up
+	Dropped    EnrollmentStatus = "dropped"
+
+	FrozenWaitlisted EnrollmentStatus = "frozen_waitlisted" // Waitlisted while the student is suspended; keeps its place
+)
+
+// Per-student outcomes of a bulk enrollment request.
+const (
+	EnrollResultEnrolled   = "enrolled"
+	EnrollResultWaitlisted = "waitlisted"
+	EnrollResultUnchanged  = "unchanged"  // Already on the course or its waitlist
+	EnrollResultNotFound   = "not_found"  // No active student with that ID or roll number
+	EnrollResultIneligible = "ineligible" // Prerequisites unmet and not overridden
 )
 
+type BulkEnrollmentResult struct {
+	StudentID  int                 `json:"student_id,omitempty"`
+	RollNo     string              `json:"roll_no,omitempty"`
+	Status     string              `json:"status,omitempty"` // Enrollment status after the request
+	Result     string              `json:"result"`
+	Unmet      []*UnmetRequirement `json:"unmet,omitempty"`
+	Overridden bool                `json:"overridden,omitempty"` // Enrolled despite Unmet
+}
+
 type Enrollment struct {
 	ID             int              `db:"id" json:"id"`
 	StudentID      int              `db:"student_id" json:"student_id"`
 	CourseID       int              `db:"course_id" json:"course_id"`
 	CollegeID      int              `db:"college_id" json:"college_id"`
+	TermID         *int             `db:"term_id" json:"term_id,omitempty"` // Defaults to the college's current term
 	EnrollmentDate time.Time        `db:"enrollment_date" json:"enrollment_date"`
 	Status         EnrollmentStatus `db:"status" json:"status"` // Active, Completed, Dropped
 	Grade          string           `db:"grade" json:"grade,omitempty"`
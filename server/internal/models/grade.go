@@ -14,9 +14,11 @@ const (
 
 type Grade struct {
 	ID            int       `db:"id" json:"id"`
-	StudentID     string    `db:"student_id" json:"student_id"` // Kratos ID or internal student identifier
+	StudentID     int       `db:"student_id" json:"student_id"`
 	CourseID      int       `db:"course_id" json:"course_id"`
 	CollegeID     int       `db:"college_id" json:"college_id"`
+	TermID        *int      `db:"term_id" json:"term_id,omitempty"`             // Defaults to the college's current term
+	AssessmentID  *int      `db:"assessment_id" json:"assessment_id,omitempty"` // Set on scores submitted for an assessment
 	MarksObtained float64   `db:"marks_obtained" json:"marks_obtained"`
 	TotalMarks    float64   `db:"total_marks" json:"total_marks"`
 	GradeLetter   *string   `db:"grade_letter" json:"grade_letter,omitempty"`
@@ -29,14 +31,90 @@ type Grade struct {
 	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
 }
 
+// Assessment is a weighted component of a course's grade in a term, such as
+// its first midterm. Weight is the percentage of the course total it carries;
+// a course's weights add up to at most 100.
+type Assessment struct {
+	ID        int       `db:"id" json:"id"`
+	CollegeID int       `db:"college_id" json:"college_id"`
+	CourseID  int       `db:"course_id" json:"course_id"`
+	TermID    *int      `db:"term_id" json:"term_id,omitempty"` // Defaults to the college's current term
+	Name      string    `db:"name" json:"name" validate:"required,max=100"`
+	ExamType  ExamType  `db:"exam_type" json:"exam_type" validate:"required,oneof=midterm-1 midterm-2 final assignment"`
+	Weight    float64   `db:"weight" json:"weight" validate:"gt=0,lte=100"`
+	MaxMarks  float64   `db:"max_marks" json:"max_marks" validate:"gt=0"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// AssessmentScore is a student's marks in an assessment as submitted by
+// faculty.
+type AssessmentScore struct {
+	StudentID int     `json:"student_id" validate:"gt=0"`
+	Marks     float64 `json:"marks" validate:"gte=0"`
+	Comments  *string `json:"comments,omitempty"`
+}
+
+// RejectedScore is a submitted score that was refused, and why.
+type RejectedScore struct {
+	StudentID int    `json:"student_id"`
+	Reason    string `json:"reason"`
+}
+
+// ComponentScore is a student's result in one assessment. Marks is nil until
+// they are graded in it; Weighted is their share of the assessment's weight.
+type ComponentScore struct {
+	AssessmentID int      `json:"assessment_id"`
+	Name         string   `json:"name"`
+	ExamType     ExamType `json:"exam_type"`
+	Weight       float64  `json:"weight"`
+	MaxMarks     float64  `json:"max_marks"`
+	Marks        *float64 `json:"marks"`
+	Weighted     float64  `json:"weighted"`
+}
+
+// CourseGrade is a student's weighted total in a course, out of TotalWeight.
+// Ungraded components count as zero; GradedWeight is how much of TotalWeight
+// has been graded so far.
+type CourseGrade struct {
+	StudentID     int               `json:"student_id"`
+	CourseID      int               `json:"course_id"`
+	TermID        *int              `json:"term_id,omitempty"`
+	Components    []*ComponentScore `json:"components"`
+	WeightedTotal float64           `json:"weighted_total"`
+	GradedWeight  float64           `json:"graded_weight"`
+	TotalWeight   float64           `json:"total_weight"`
+}
+
+// CourseGradeBook is a course's assessments and its students' grades.
+type CourseGradeBook struct {
+	CourseID    int            `json:"course_id"`
+	TermID      *int           `json:"term_id,omitempty"`
+	Assessments []*Assessment  `json:"assessments"`
+	Students    []*CourseGrade `json:"students"`
+}
+
+// GradeRow is a grade with the student's roll number and name and the
+// course's name, for result sheets.
+type GradeRow struct {
+	Grade
+	RollNo      string `db:"roll_no" json:"roll_no"`
+	StudentName string `db:"student_name" json:"student_name"`
+	CourseName  string `db:"course_name" json:"course_name"`
+}
+
 // GradeFilter can be used for querying lists of grades with specific criteria
 type GradeFilter struct {
-	StudentID    *string  `json:"student_id,omitempty"`
+	StudentID    *int     `json:"student_id,omitempty"`
 	CourseID     *int     `json:"course_id,omitempty"`
+	AssessmentID *int     `json:"assessment_id,omitempty"`
 	CollegeID    *int     `json:"college_id,omitempty"` // Essential for multi-tenancy
+	TermID       *int     `json:"term_id,omitempty"`
 	Semester     *int     `json:"semester,omitempty"`
 	AcademicYear *string  `json:"academic_year,omitempty"`
 	ExamType     ExamType `json:"exam_type,omitempty"`
+	// PublishedOnly keeps grades whose course results are published
+	PublishedOnly bool `json:"published_only,omitempty"`
 	// Add pagination fields if needed
 	Limit  uint64 `json:"limit,omitempty"`
 	Offset uint64 `json:"offset,omitempty"`
@@ -0,0 +1,221 @@
+package models
+
+import "time"
+
+type GradingMethod string
+
+const (
+	// AbsoluteGrading bands the course percentage.
+	AbsoluteGrading GradingMethod = "absolute"
+	// RelativeGrading bands the student's percentile among the course's
+	// students in the term.
+	RelativeGrading GradingMethod = "relative"
+)
+
+// GradeBand gives Letter and GradePoint to scores of at least MinScore, a
+// percentage or, on relative scales, a percentile.
+type GradeBand struct {
+	Letter     string  `json:"letter" validate:"required,max=5"`
+	MinScore   float64 `json:"min_score" validate:"gte=0,lte=100"`
+	GradePoint float64 `json:"grade_point" validate:"gte=0,lte=10"`
+}
+
+// GradingScale maps course results to letter grades. Its bands run from the
+// highest MinScore down and the last starts at 0, so every score has a band.
+// On relative scales, students below PassPercentage get the last band
+// whatever their percentile.
+type GradingScale struct {
+	ID             int           `db:"id" json:"id"`
+	CollegeID      int           `db:"college_id" json:"college_id"`
+	Name           string        `db:"name" json:"name" validate:"required,max=100"`
+	Method         GradingMethod `db:"method" json:"method" validate:"required,oneof=absolute relative"`
+	PassPercentage float64       `db:"pass_percentage" json:"pass_percentage" validate:"gte=0,lte=100"`
+	Bands          []GradeBand   `db:"bands" json:"bands" validate:"required,min=1,dive"`
+	IsDefault      bool          `db:"is_default" json:"is_default"`
+	CreatedAt      time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time     `db:"updated_at" json:"updated_at"`
+}
+
+// PassMark is the lowest percentage that passes on the scale: its pass
+// percentage and, on absolute scales, at least the start of the lowest band
+// that earns grade points.
+func (s *GradingScale) PassMark() float64 {
+	mark := s.PassPercentage
+	if s.Method != AbsoluteGrading {
+		return mark
+	}
+	lowest := -1.0
+	for _, b := range s.Bands {
+		if b.GradePoint > 0 && (lowest < 0 || b.MinScore < lowest) {
+			lowest = b.MinScore
+		}
+	}
+	if lowest > mark {
+		mark = lowest
+	}
+	return mark
+}
+
+// DefaultGradingScale is the ten point scale used by colleges that have not
+// set a default of their own.
+var DefaultGradingScale = GradingScale{
+	Name:   "Ten point",
+	Method: AbsoluteGrading,
+	Bands: []GradeBand{
+		{Letter: "O", MinScore: 90, GradePoint: 10},
+		{Letter: "A+", MinScore: 80, GradePoint: 9},
+		{Letter: "A", MinScore: 70, GradePoint: 8},
+		{Letter: "B+", MinScore: 60, GradePoint: 7},
+		{Letter: "B", MinScore: 50, GradePoint: 6},
+		{Letter: "C", MinScore: 45, GradePoint: 5},
+		{Letter: "P", MinScore: 40, GradePoint: 4},
+		{Letter: "F", MinScore: 0, GradePoint: 0},
+	},
+}
+
+// CourseResult is a student's final grade in a course and term. Credits are
+// earned when GradePoint is above zero.
+type CourseResult struct {
+	ID             int       `db:"id" json:"id"`
+	CollegeID      int       `db:"college_id" json:"college_id"`
+	StudentID      int       `db:"student_id" json:"student_id"`
+	CourseID       int       `db:"course_id" json:"course_id"`
+	TermID         *int      `db:"term_id" json:"term_id,omitempty"`
+	Percentage     float64   `db:"percentage" json:"percentage"`
+	GradeLetter    string    `db:"grade_letter" json:"grade_letter"`
+	GradePoint     float64   `db:"grade_point" json:"grade_point"`
+	Credits        int       `db:"credits" json:"credits"`
+	GradingScaleID *int      `db:"grading_scale_id" json:"grading_scale_id,omitempty"`
+	ComputedAt     time.Time `db:"computed_at" json:"computed_at"`
+
+	// Joined for transcripts - not stored with the result
+	CourseName string     `db:"course_name" json:"course_name,omitempty"`
+	TermName   *string    `db:"term_name" json:"term_name,omitempty"`
+	TermStart  *time.Time `db:"term_start" json:"-"`
+}
+
+// GPASnapshot is a student's SGPA in a term and CGPA through it, as last
+// computed.
+type GPASnapshot struct {
+	ID            int       `db:"id" json:"id"`
+	CollegeID     int       `db:"college_id" json:"college_id"`
+	StudentID     int       `db:"student_id" json:"student_id"`
+	TermID        *int      `db:"term_id" json:"term_id,omitempty"`
+	SGPA          float64   `db:"sgpa" json:"sgpa"`
+	CGPA          float64   `db:"cgpa" json:"cgpa"`
+	TermCredits   int       `db:"term_credits" json:"term_credits"`
+	CreditsEarned int       `db:"credits_earned" json:"credits_earned"`
+	ComputedAt    time.Time `db:"computed_at" json:"computed_at"`
+}
+
+// TermResults reports a computation of a term's course results.
+type TermResults struct {
+	TermID   *int  `json:"term_id,omitempty"`
+	Courses  int   `json:"courses"`
+	Results  int   `json:"results"`
+	Students []int `json:"students"`
+	// Published courses keep the results they were published with
+	Published int `json:"published"`
+}
+
+// Transcript is a student's official record of results, term by term.
+type Transcript struct {
+	CollegeName   string            `db:"college_name" json:"college_name"`
+	StudentID     int               `db:"student_id" json:"student_id"`
+	StudentName   string            `db:"student_name" json:"student_name"`
+	RollNo        string            `db:"roll_no" json:"roll_no"`
+	Terms         []*TranscriptTerm `db:"-" json:"terms"`
+	CGPA          float64           `db:"-" json:"cgpa"`
+	CreditsEarned int               `db:"-" json:"credits_earned"`
+	GeneratedAt   time.Time         `db:"-" json:"generated_at"`
+}
+
+// TranscriptTerm is a term of a transcript.
+type TranscriptTerm struct {
+	TermID  *int            `json:"term_id,omitempty"`
+	Name    string          `json:"name"`
+	Courses []*CourseResult `json:"courses"`
+	Credits int             `json:"credits"`
+	SGPA    float64         `json:"sgpa"`
+	CGPA    float64         `json:"cgpa"`
+}
+
+type CurveMethod string
+
+const (
+	// CurveMeanSD places each cutoff Cutoff standard deviations from the
+	// class mean.
+	CurveMeanSD CurveMethod = "mean_sd"
+	// CurvePercentile places each cutoff at the Cutoff-th percentile of the
+	// class's percentages.
+	CurvePercentile CurveMethod = "percentile"
+	// CurveFixed takes each Cutoff as a percentage, e.g. a previewed cutoff
+	// adjusted by hand.
+	CurveFixed CurveMethod = "fixed"
+)
+
+// CurveBand is a letter of a curve and where it starts, in the units of the
+// curve's method.
+type CurveBand struct {
+	Letter     string  `json:"letter" validate:"required,max=5"`
+	Cutoff     float64 `json:"cutoff"`
+	GradePoint float64 `json:"grade_point" validate:"gte=0,lte=10"`
+}
+
+// Curve grades a course relative to its class. Bands run from the highest
+// cutoff down; the last takes everyone below the others, so its cutoff is
+// ignored.
+type Curve struct {
+	TermID *int        `json:"term_id,omitempty"` // Defaults to the college's current term
+	Method CurveMethod `json:"method" validate:"required,oneof=mean_sd percentile fixed"`
+	Bands  []CurveBand `json:"bands" validate:"required,min=2,dive"`
+}
+
+// LetterCount is how many students a curve gives a letter.
+type LetterCount struct {
+	Letter string `json:"letter"`
+	Count  int    `json:"count"`
+}
+
+// CurvedGrade is a student's letter under a curve.
+type CurvedGrade struct {
+	StudentID   int     `json:"student_id"`
+	Percentage  float64 `json:"percentage"`
+	GradeLetter string  `json:"grade_letter"`
+	GradePoint  float64 `json:"grade_point"`
+}
+
+// CurvePreview is what a curve would give a course's students. Cutoffs are
+// the curve's bands resolved to percentages.
+type CurvePreview struct {
+	CourseID     int            `json:"course_id"`
+	TermID       *int           `json:"term_id,omitempty"`
+	Method       CurveMethod    `json:"method"`
+	Mean         float64        `json:"mean"`
+	StdDev       float64        `json:"std_dev"`
+	Cutoffs      []GradeBand    `json:"cutoffs"`
+	Distribution []LetterCount  `json:"distribution"`
+	Grades       []*CurvedGrade `json:"grades"`
+}
+
+// GradeCurve is a committed version of a course's curve. While it is locked
+// the course's scores and assessments in the term cannot change.
+type GradeCurve struct {
+	ID           int           `db:"id" json:"id"`
+	CollegeID    int           `db:"college_id" json:"college_id"`
+	CourseID     int           `db:"course_id" json:"course_id"`
+	TermID       *int          `db:"term_id" json:"term_id,omitempty"`
+	Version      int           `db:"version" json:"version"`
+	Method       CurveMethod   `db:"method" json:"method"`
+	Bands        []CurveBand   `db:"bands" json:"bands"`
+	Cutoffs      []GradeBand   `db:"cutoffs" json:"cutoffs"`
+	Mean         float64       `db:"mean" json:"mean"`
+	StdDev       float64       `db:"std_dev" json:"std_dev"`
+	Distribution []LetterCount `db:"distribution" json:"distribution"`
+	IsLocked     bool          `db:"is_locked" json:"is_locked"`
+	CommittedBy  string        `db:"committed_by" json:"committed_by"`
+	CommittedAt  time.Time     `db:"committed_at" json:"committed_at"`
+	UnlockedBy   *string       `db:"unlocked_by" json:"unlocked_by,omitempty"`
+	UnlockedAt   *time.Time    `db:"unlocked_at" json:"unlocked_at,omitempty"`
+	UnlockReason *string       `db:"unlock_reason" json:"unlock_reason,omitempty"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+)
+
+// Leave types a student can request.
+const (
+	LeaveTypeMedical      = "medical"
+	LeaveTypeSports       = "sports"
+	LeaveTypeOfficialDuty = "official_duty"
+)
+
+// Leave request statuses. Only pending requests can be reviewed or cancelled.
+const (
+	LeaveStatusPending   = "pending"
+	LeaveStatusApproved  = "approved"
+	LeaveStatusRejected  = "rejected"
+	LeaveStatusCancelled = "cancelled"
+)
+
+// LeaveRequest is a student's request to be excused from lectures between
+// StartDate and EndDate (inclusive).
+type LeaveRequest struct {
+	ID              int                `db:"id" json:"id"`
+	CollegeID       int                `db:"college_id" json:"college_id"`
+	StudentID       int                `db:"student_id" json:"student_id"`
+	LeaveType       string             `db:"leave_type" json:"leave_type" validate:"required,oneof=medical sports official_duty"`
+	StartDate       time.Time          `db:"start_date" json:"start_date" validate:"required"`
+	EndDate         time.Time          `db:"end_date" json:"end_date" validate:"required"`
+	Reason          string             `db:"reason" json:"reason" validate:"required,max=2000"`
+	Status          string             `db:"status" json:"status"`
+	ReviewedBy      *string            `db:"reviewed_by" json:"reviewed_by,omitempty"` // Kratos identity ID
+	ReviewNote      *string            `db:"review_note" json:"review_note,omitempty"`
+	ReviewedAt      *time.Time         `db:"reviewed_at" json:"reviewed_at,omitempty"`
+	ExcusedLectures int                `db:"excused_lectures" json:"excused_lectures"`
+	CreatedAt       time.Time          `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time          `db:"updated_at" json:"updated_at"`
+	Attachments     []*LeaveAttachment `db:"-" json:"attachments" validate:"omitempty,max=10,dive"`
+}
+
+// LeaveAttachment points at a supporting document held in external storage.
+type LeaveAttachment struct {
+	ID             int       `db:"id" json:"id"`
+	LeaveRequestID int       `db:"leave_request_id" json:"leave_request_id"`
+	FileName       string    `db:"file_name" json:"file_name" validate:"required,max=255"`
+	FileURL        string    `db:"file_url" json:"file_url" validate:"required,url"`
+	ContentType    *string   `db:"content_type" json:"content_type,omitempty" validate:"omitempty,max=100"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// CoursePrerequisite says CourseID requires PrerequisiteID.
+type CoursePrerequisite struct {
+	CourseID         int       `db:"course_id" json:"course_id"`
+	PrerequisiteID   int       `db:"prerequisite_id" json:"prerequisite_id" validate:"required,gt=0"`
+	PrerequisiteName string    `db:"prerequisite_name" json:"prerequisite_name,omitempty"`
+	CollegeID        int       `db:"college_id" json:"college_id"`
+	MinPercentage    *float64  `db:"min_percentage" json:"min_percentage,omitempty" validate:"omitempty,gte=0,lte=100"` // Of the best final-exam grade; nil requires the pass mark
+	CreatedAt        time.Time `db:"created_at" json:"created_at"`
+}
+
+// Why a prerequisite is unmet.
+const (
+	UnmetNotTaken     = "not_taken"     // No final grade in the prerequisite
+	UnmetBelowMinimum = "below_minimum" // Best final grade is under the minimum
+)
+
+type UnmetRequirement struct {
+	PrerequisiteID   int      `json:"prerequisite_id"`
+	PrerequisiteName string   `json:"prerequisite_name,omitempty"`
+	Reason           string   `json:"reason"`
+	MinPercentage    float64  `json:"min_percentage"`
+	BestPercentage   *float64 `json:"best_percentage,omitempty"`
+}
+
+// EnrollmentOverride records an admin enrolling a student despite unmet
+// prerequisites.
+type EnrollmentOverride struct {
+	ID           int                 `db:"id" json:"id"`
+	CollegeID    int                 `db:"college_id" json:"college_id"`
+	CourseID     int                 `db:"course_id" json:"course_id"`
+	StudentID    int                 `db:"student_id" json:"student_id"`
+	OverriddenBy string              `db:"overridden_by" json:"overridden_by"`
+	Reason       string              `db:"reason" json:"reason" validate:"required,max=1000"`
+	Unmet        []*UnmetRequirement `db:"unmet" json:"unmet"`
+	CreatedAt    time.Time           `db:"created_at" json:"created_at"`
+}
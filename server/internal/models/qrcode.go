@@ -2,8 +2,14 @@ package models
 
 import "time"
 
+// QRCode records a signed attendance QR token issued for a lecture.
+// QRCodeID holds the token nonce, which is what scans are checked against.
 type QRCode struct {
-	StudentID int       `db:"student_id" json:"student_id"`
+	ID        int       `db:"id" json:"id"`
+	StudentID *int      `db:"student_id" json:"student_id,omitempty"` // Only set for student-specific codes
+	CollegeID int       `db:"college_id" json:"college_id"`
+	CourseID  int       `db:"course_id" json:"course_id"`
+	LectureID int       `db:"lecture_id" json:"lecture_id"`
 	QRCodeID  string    `db:"qr_code_id" json:"qr_code_id"`
 	IssuedAt  time.Time `db:"issued_at" json:"issued_at"`
 	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
@@ -14,3 +20,12 @@ type QRCode struct {
 	// Relations - not stored in DB
 	Student *Student `db:"-" json:"student,omitempty"`
 }
+
+// QRCodeRedemption records a single student scanning an issued QR code.
+type QRCodeRedemption struct {
+	ID         int       `db:"id" json:"id"`
+	QRCodeID   int       `db:"qrcode_id" json:"qrcode_id"`
+	StudentID  int       `db:"student_id" json:"student_id"`
+	CollegeID  int       `db:"college_id" json:"college_id"`
+	RedeemedAt time.Time `db:"redeemed_at" json:"redeemed_at"`
+}
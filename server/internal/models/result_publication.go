@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// PublicationStatus is how far a course's results in a term are on their
+// way to students: faculty submit the draft, the head of department approves
+// it and it is published.
+type PublicationStatus string
+
+const (
+	ResultsDraft     PublicationStatus = "draft"
+	ResultsSubmitted PublicationStatus = "submitted"
+	ResultsApproved  PublicationStatus = "approved"
+	ResultsPublished PublicationStatus = "published"
+)
+
+// ResultPublication is where a course's results in a term stand. Scores and
+// assessments only change in drafts, and students only see published
+// results. A submitted or approved draft can be returned with a reason.
+// Bands are set on publication to the bands the results were graded on,
+// resolved to percentages, so a re-evaluation regrades just one student.
+type ResultPublication struct {
+	ID           int               `db:"id" json:"id"`
+	CollegeID    int               `db:"college_id" json:"college_id"`
+	CourseID     int               `db:"course_id" json:"course_id"`
+	TermID       *int              `db:"term_id" json:"term_id,omitempty"`
+	Status       PublicationStatus `db:"status" json:"status"`
+	SubmittedBy  *string           `db:"submitted_by" json:"submitted_by,omitempty"`
+	SubmittedAt  *time.Time        `db:"submitted_at" json:"submitted_at,omitempty"`
+	ApprovedBy   *string           `db:"approved_by" json:"approved_by,omitempty"`
+	ApprovedAt   *time.Time        `db:"approved_at" json:"approved_at,omitempty"`
+	PublishedBy  *string           `db:"published_by" json:"published_by,omitempty"`
+	PublishedAt  *time.Time        `db:"published_at" json:"published_at,omitempty"`
+	Bands        []GradeBand       `db:"bands" json:"bands,omitempty"`
+	ReturnedBy   *string           `db:"returned_by" json:"returned_by,omitempty"`
+	ReturnedAt   *time.Time        `db:"returned_at" json:"returned_at,omitempty"`
+	ReturnReason *string           `db:"return_reason" json:"return_reason,omitempty"`
+	CreatedAt    time.Time         `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time         `db:"updated_at" json:"updated_at"`
+}
+
+type ReevaluationStatus string
+
+const (
+	ReevaluationPending  ReevaluationStatus = "pending"
+	ReevaluationAccepted ReevaluationStatus = "accepted"
+	ReevaluationRejected ReevaluationStatus = "rejected"
+)
+
+// Reevaluation is a request to mark a published grade again, the only way
+// its marks change. It keeps the marks before and, if accepted, after.
+type Reevaluation struct {
+	ID             int                `db:"id" json:"id"`
+	CollegeID      int                `db:"college_id" json:"college_id"`
+	GradeID        int                `db:"grade_id" json:"grade_id"`
+	StudentID      int                `db:"student_id" json:"student_id"`
+	CourseID       int                `db:"course_id" json:"course_id"`
+	TermID         *int               `db:"term_id" json:"term_id,omitempty"`
+	Reason         string             `db:"reason" json:"reason" validate:"required,max=1000"`
+	Status         ReevaluationStatus `db:"status" json:"status"`
+	RequestedBy    string             `db:"requested_by" json:"requested_by"`
+	PreviousMarks  float64            `db:"previous_marks" json:"previous_marks"`
+	RevisedMarks   *float64           `db:"revised_marks" json:"revised_marks,omitempty"`
+	ResolvedBy     *string            `db:"resolved_by" json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time         `db:"resolved_at" json:"resolved_at,omitempty"`
+	ResolutionNote *string            `db:"resolution_note" json:"resolution_note,omitempty"`
+	CreatedAt      time.Time          `db:"created_at" json:"created_at"`
+}
+
+// ReevaluationDecision resolves a re-evaluation: with Marks it is accepted
+// and they replace the grade's, without them it is rejected.
+type ReevaluationDecision struct {
+	Marks *float64 `json:"marks,omitempty" validate:"omitempty,gte=0"`
+	Note  string   `json:"note" validate:"max=1000"`
+}
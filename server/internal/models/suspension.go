@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Suspension statuses. A scheduled suspension takes effect on StartsOn; an
+// active one is lifted by an admin or automatically after EndsOn.
+const (
+	SuspensionScheduled = "scheduled"
+	SuspensionActive    = "active"
+	SuspensionLifted    = "lifted"
+)
+
+// StudentSuspension freezes a student together with their attendance and
+// enrollments for a period.
+type StudentSuspension struct {
+	ID          int        `db:"id" json:"id"`
+	CollegeID   int        `db:"college_id" json:"college_id"`
+	StudentID   int        `db:"student_id" json:"student_id" validate:"required,gt=0"`
+	Reason      string     `db:"reason" json:"reason" validate:"required,max=2000"`
+	StartsOn    time.Time  `db:"starts_on" json:"starts_on"`
+	EndsOn      *time.Time `db:"ends_on" json:"ends_on,omitempty"`
+	Status      string     `db:"status" json:"status"`
+	SuspendedBy string     `db:"suspended_by" json:"suspended_by"` // Kratos identity ID
+	LiftedBy    *string    `db:"lifted_by" json:"lifted_by,omitempty"`
+	LiftReason  *string    `db:"lift_reason" json:"lift_reason,omitempty"`
+	LiftedAt    *time.Time `db:"lifted_at" json:"lifted_at,omitempty"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+}
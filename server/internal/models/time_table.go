@@ -11,6 +11,7 @@ type TimeTableBlock struct {
 	CollegeID    int          `db:"college_id" json:"college_id"` // For multi-tenancy
 	DepartmentID *int         `db:"department_id" json:"department_id,omitempty"`
 	CourseID     int          `db:"course_id" json:"course_id"`
+	TermID       *int         `db:"term_id" json:"term_id,omitempty"`   // Defaults to the college's current term
 	ClassID      *int         `db:"class_id" json:"class_id,omitempty"` // e.g., "Section A", "Batch 1" - could be FK to a 'classes' table
 	DayOfWeek    time.Weekday `db:"day_of_week" json:"day_of_week"`     // e.g., time.Monday, time.Tuesday
 	StartTime    pgtype.Time  `db:"start_time" json:"start_time"`       // Represents HH:MM:SS
@@ -29,9 +30,11 @@ type TimeTableBlockFilter struct {
 	CollegeID    int           `json:"college_id"` // Mandatory
 	DepartmentID *int          `json:"department_id,omitempty"`
 	CourseID     *int          `json:"course_id,omitempty"`
+	TermID       *int          `json:"term_id,omitempty"`
 	ClassID      *int          `json:"class_id,omitempty"`
 	DayOfWeek    *time.Weekday `json:"day_of_week,omitempty"`
 	FacultyID    *string       `json:"faculty_id,omitempty"`
+	RoomNumber   *string       `json:"room_number,omitempty"`
 	Limit        uint64        `json:"limit,omitempty"`
 	Offset       uint64        `json:"offset,omitempty"`
 }
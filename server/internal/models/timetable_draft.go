@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// Timetable draft statuses.
+const (
+	TimetableDraftStatusDraft     = "draft"
+	TimetableDraftStatusPublished = "published"
+)
+
+// TimetableProblem is the input to the timetable solver. The week is split
+// into one-hour periods from DayStartHour to DayEndHour on each of Days.
+type TimetableProblem struct {
+	Requirements []TimetableRequirement `json:"requirements" validate:"required,min=1,dive"`
+	Rooms        []TimetableRoom        `json:"rooms" validate:"required,min=1,dive"`
+	Availability []FacultyAvailability  `json:"availability,omitempty" validate:"dive"`           // Faculty without entries are always available
+	Days         []time.Weekday         `json:"days,omitempty" validate:"dive,gte=0,lte=6"`       // Defaults to Monday to Friday
+	DayStartHour int                    `json:"day_start_hour,omitempty" validate:"gte=0,lte=23"` // Defaults to 9
+	DayEndHour   int                    `json:"day_end_hour,omitempty" validate:"gte=0,lte=24"`   // Defaults to 17
+
+	// Soft constraint: teaching more consecutive hours than this is allowed
+	// but penalised. Defaults to 3.
+	MaxConsecutiveHours int `json:"max_consecutive_hours,omitempty" validate:"gte=0"`
+}
+
+// TimetableRequirement asks for HoursPerWeek one-hour periods of a course for
+// a class section, taught by FacultyID in a room seating Students.
+type TimetableRequirement struct {
+	CourseID     int    `json:"course_id" validate:"required,gte=1"`
+	ClassID      *int   `json:"class_id,omitempty"`
+	FacultyID    string `json:"faculty_id" validate:"required"`
+	HoursPerWeek int    `json:"hours_per_week" validate:"required,gte=1,lte=40"`
+	Students     int    `json:"students" validate:"gte=0"`
+}
+
+type TimetableRoom struct {
+	Number   string `json:"number" validate:"required,max=50"`
+	Capacity int    `json:"capacity" validate:"gte=1"`
+}
+
+// FacultyAvailability is a window, from FromHour up to ToHour, in which a
+// faculty member can teach on DayOfWeek.
+type FacultyAvailability struct {
+	FacultyID string       `json:"faculty_id" validate:"required"`
+	DayOfWeek time.Weekday `json:"day_of_week" validate:"gte=0,lte=6"`
+	FromHour  int          `json:"from_hour" validate:"gte=0,lte=23"`
+	ToHour    int          `json:"to_hour" validate:"gtfield=FromHour,lte=24"`
+}
+
+// TimetableDraft is a solved timetable awaiting review. Publishing it
+// replaces the live timetable blocks of its courses in its term.
+type TimetableDraft struct {
+	ID          int              `db:"id" json:"id"`
+	CollegeID   int              `db:"college_id" json:"college_id"`
+	TermID      *int             `db:"term_id" json:"term_id,omitempty"`
+	Name        string           `db:"name" json:"name" validate:"required,max=100"`
+	Status      string           `db:"status" json:"status"`
+	Problem     TimetableProblem `db:"problem" json:"problem"`
+	Penalty     int              `db:"penalty" json:"penalty"`
+	Warnings    []string         `db:"warnings" json:"warnings"`
+	CreatedBy   string           `db:"created_by" json:"created_by"`
+	PublishedBy *string          `db:"published_by" json:"published_by,omitempty"`
+	PublishedAt *time.Time       `db:"published_at" json:"published_at,omitempty"`
+	CreatedAt   time.Time        `db:"created_at" json:"created_at"`
+
+	// Relations - not stored in the drafts table
+	Blocks []*TimeTableBlock `db:"-" json:"blocks,omitempty"`
+}
@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+var (
+	ErrTermNotFound  = errors.New("academic term not found")
+	ErrNoCurrentTerm = errors.New("college has no current academic term")
+	ErrTermOverlap   = errors.New("academic term overlaps another term of the college")
+	ErrTermNameTaken = errors.New("college already has an academic term with that name")
+	ErrTermInUse     = errors.New("academic term is referenced by enrollments, lectures, timetable blocks or grades")
+)
+
+type AcademicTermRepository interface {
+	// CreateTerm stores the term; if it is current, the college's previous
+	// current term stops being current.
+	CreateTerm(ctx context.Context, term *models.AcademicTerm) error
+	GetTermByID(ctx context.Context, collegeID int, termID int) (*models.AcademicTerm, error)
+	// UpdateTerm updates everything but IsCurrent; see SetCurrentTerm.
+	UpdateTerm(ctx context.Context, term *models.AcademicTerm) error
+	DeleteTerm(ctx context.Context, collegeID int, termID int) error
+	FindTerms(ctx context.Context, collegeID int, limit, offset uint64) ([]*models.AcademicTerm, error)
+
+	GetCurrentTerm(ctx context.Context, collegeID int) (*models.AcademicTerm, error)
+	// CurrentTermID returns the ID of the college's current term, or nil if it has none.
+	CurrentTermID(ctx context.Context, collegeID int) (*int, error)
+	SetCurrentTerm(ctx context.Context, collegeID int, termID int) error
+}
+
+const academicTermTable = "academic_terms"
+
+var academicTermQueryFields = []string{
+	"id", "college_id", "name", "start_date", "end_date", "registration_opens_on",
+	"registration_closes_on", "is_current", "created_at", "updated_at",
+}
+
+type academicTermRepository struct {
+	DB *DB
+}
+
+func NewAcademicTermRepository(db *DB) AcademicTermRepository {
+	return &academicTermRepository{DB: db}
+}
+
+// currentTerm is the ID of the college's current term, for use as a column
+// value; it is NULL if the college has none.
+func currentTerm(collegeID int) squirrel.Sqlizer {
+	return squirrel.Expr("(SELECT id FROM "+academicTermTable+" WHERE college_id = ? AND is_current)", collegeID)
+}
+
+// termOn is the ID of the college's term containing t, or NULL.
+func termOn(collegeID int, t time.Time) squirrel.Sqlizer {
+	return squirrel.Expr("(SELECT id FROM "+academicTermTable+" WHERE college_id = ? AND ?::date BETWEEN start_date AND end_date)", collegeID, t)
+}
+
+// termOrDefault is termID as a column value, or fallback if it is unset.
+func termOrDefault(termID *int, fallback squirrel.Sqlizer) any {
+	if termID != nil {
+		return *termID
+	}
+	return fallback
+}
+
+// termError maps constraint violations on academic_terms to our errors.
+func termError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == "23P01": // exclusion_violation
+			return ErrTermOverlap
+		case pgErr.Code == "23505" && pgErr.ConstraintName == "academic_terms_college_name_key":
+			return ErrTermNameTaken
+		case pgErr.Code == "23503": // foreign_key_violation
+			return ErrTermInUse
+		}
+	}
+	return err
+}
+
+func (r *academicTermRepository) CreateTerm(ctx context.Context, term *models.AcademicTerm) error {
+	now := time.Now()
+	term.CreatedAt = now
+	term.UpdatedAt = now
+
+	sql, args, err := r.DB.SQ.Insert(academicTermTable).
+		Columns("college_id", "name", "start_date", "end_date", "registration_opens_on", "registration_closes_on", "is_current", "created_at", "updated_at").
+		Values(term.CollegeID, term.Name, term.StartDate, term.EndDate, term.RegistrationOpensOn, term.RegistrationClosesOn, term.IsCurrent, term.CreatedAt, term.UpdatedAt).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CreateTerm: failed to build query: %w", err)
+	}
+
+	err = r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if term.IsCurrent {
+			if err := r.clearCurrent(ctx, tx, term.CollegeID); err != nil {
+				return err
+			}
+		}
+		if err := tx.QueryRow(ctx, sql, args...).Scan(&term.ID); err != nil {
+			if mapped := termError(err); mapped != err {
+				return mapped
+			}
+			return fmt.Errorf("failed to execute query or scan ID: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("CreateTerm: %w", err)
+	}
+	return nil
+}
+
+func (r *academicTermRepository) GetTermByID(ctx context.Context, collegeID int, termID int) (*models.AcademicTerm, error) {
+	sql, args, err := r.DB.SQ.Select(academicTermQueryFields...).
+		From(academicTermTable).
+		Where(squirrel.Eq{"id": termID, "college_id": collegeID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("GetTermByID: failed to build query: %w", err)
+	}
+
+	term := &models.AcademicTerm{}
+	if err := pgxscan.Get(ctx, r.DB.Pool, term, sql, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("GetTermByID: term %d for college ID %d: %w", termID, collegeID, ErrTermNotFound)
+		}
+		return nil, fmt.Errorf("GetTermByID: failed to execute query or scan: %w", err)
+	}
+	return term, nil
+}
+
+func (r *academicTermRepository) UpdateTerm(ctx context.Context, term *models.AcademicTerm) error {
+	term.UpdatedAt = time.Now()
+
+	sql, args, err := r.DB.SQ.Update(academicTermTable).
+		Set("name", term.Name).
+		Set("start_date", term.StartDate).
+		Set("end_date", term.EndDate).
+		Set("registration_opens_on", term.RegistrationOpensOn).
+		Set("registration_closes_on", term.RegistrationClosesOn).
+		Set("updated_at", term.UpdatedAt).
+		Where(squirrel.Eq{"id": term.ID, "college_id": term.CollegeID}).
+		Suffix("RETURNING is_current, created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("UpdateTerm: failed to build query: %w", err)
+	}
+
+	if err := r.DB.Pool.QueryRow(ctx, sql, args...).Scan(&term.IsCurrent, &term.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("UpdateTerm: term %d for college ID %d: %w", term.ID, term.CollegeID, ErrTermNotFound)
+		}
+		return fmt.Errorf("UpdateTerm: %w", termError(err))
+	}
+	return nil
+}
+
+func (r *academicTermRepository) DeleteTerm(ctx context.Context, collegeID int, termID int) error {
+	sql, args, err := r.DB.SQ.Delete(academicTermTable).
+		Where(squirrel.Eq{"id": termID, "college_id": collegeID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("DeleteTerm: failed to build query: %w", err)
+	}
+
+	tag, err := r.DB.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("DeleteTerm: %w", termError(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("DeleteTerm: term %d for college ID %d: %w", termID, collegeID, ErrTermNotFound)
+	}
+	return nil
+}
+
+func (r *academicTermRepository) FindTerms(ctx context.Context, collegeID int, limit, offset uint64) ([]*models.AcademicTerm, error) {
+	sql, args, err := r.DB.SQ.Select(academicTermQueryFields...).
+		From(academicTermTable).
+		Where(squirrel.Eq{"college_id": collegeID}).
+		OrderBy("start_date DESC").
+		Limit(limit).
+		Offset(offset).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindTerms: failed to build query: %w", err)
+	}
+
+	terms := []*models.AcademicTerm{}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &terms, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindTerms: failed to execute query or scan: %w", err)
+	}
+	return terms, nil
+}
+
+func (r *academicTermRepository) GetCurrentTerm(ctx context.Context, collegeID int) (*models.AcademicTerm, error) {
+	sql, args, err := r.DB.SQ.Select(academicTermQueryFields...).
+		From(academicTermTable).
+		Where(squirrel.Eq{"college_id": collegeID, "is_current": true}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("GetCurrentTerm: failed to build query: %w", err)
+	}
+
+	term := &models.AcademicTerm{}
+	if err := pgxscan.Get(ctx, r.DB.Pool, term, sql, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("GetCurrentTerm: college ID %d: %w", collegeID, ErrNoCurrentTerm)
+		}
+		return nil, fmt.Errorf("GetCurrentTerm: failed to execute query or scan: %w", err)
+	}
+	return term, nil
+}
+
+func (r *academicTermRepository) CurrentTermID(ctx context.Context, collegeID int) (*int, error) {
+	sql, args, err := r.DB.SQ.Select("id").
+		From(academicTermTable).
+		Where(squirrel.Eq{"college_id": collegeID, "is_current": true}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("CurrentTermID: failed to build query: %w", err)
+	}
+
+	var termID int
+	if err := r.DB.Pool.QueryRow(ctx, sql, args...).Scan(&termID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("CurrentTermID: failed to execute query or scan: %w", err)
+	}
+	return &termID, nil
+}
+
+func (r *academicTermRepository) SetCurrentTerm(ctx context.Context, collegeID int, termID int) error {
+	sql, args, err := r.DB.SQ.Update(academicTermTable).
+		Set("is_current", true).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": termID, "college_id": collegeID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("SetCurrentTerm: failed to build query: %w", err)
+	}
+
+	err = r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if err := r.clearCurrent(ctx, tx, collegeID); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, sql, args...)
+		if err != nil {
+			return fmt.Errorf("failed to execute query: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("term %d for college ID %d: %w", termID, collegeID, ErrTermNotFound)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("SetCurrentTerm: %w", err)
+	}
+	return nil
+}
+
+// clearCurrent unsets the college's current term, if any.
+func (r *academicTermRepository) clearCurrent(ctx context.Context, tx pgx.Tx, collegeID int) error {
+	sql, args, err := r.DB.SQ.Update(academicTermTable).
+		Set("is_current", false).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"college_id": collegeID, "is_current": true}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query clearing current term: %w", err)
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to clear current term: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+const assessmentTable = "course_assessments"
+
+var assessmentQueryFields = []string{
+	"id", "college_id", "course_id", "term_id", "name", "exam_type", "weight::float8 AS weight",
+	"max_marks::float8 AS max_marks", "created_at", "updated_at",
+}
+
+var (
+	ErrAssessmentNotFound       = errors.New("assessment not found")
+	ErrAssessmentExists         = errors.New("course already has an assessment of that type in the term")
+	ErrAssessmentWeightExceeded = errors.New("assessment weights of the course would exceed 100")
+)
+
+// AssessmentRepository stores course assessments and their scores. Changes
+// to either fail with ErrGradesLocked while the course has a locked curve in
+// the term.
+type AssessmentRepository interface {
+	// CreateAssessment stores the assessment in its term, the college's
+	// current term if TermID is nil. It fails with ErrAssessmentExists if
+	// the course has one of that type in the term already and with
+	// ErrAssessmentWeightExceeded if the course's weights would pass 100.
+	CreateAssessment(ctx context.Context, assessment *models.Assessment) error
+	GetAssessment(ctx context.Context, collegeID int, assessmentID int) (*models.Assessment, error)
+	// UpdateAssessment changes the name, weight and max marks; the course,
+	// term and type stay. Weights are checked like in CreateAssessment.
+	UpdateAssessment(ctx context.Context, assessment *models.Assessment) error
+	// DeleteAssessment removes the assessment and the scores given in it.
+	DeleteAssessment(ctx context.Context, collegeID int, assessmentID int) error
+	// FindAssessments lists the course's assessments in termID, or those
+	// without a term if it is nil.
+	FindAssessments(ctx context.Context, collegeID int, courseID int, termID *int) ([]*models.Assessment, error)
+
+	// SaveScores stores the grades of one assessment, replacing any a
+	// student already has in it.
+	SaveScores(ctx context.Context, grades []*models.Grade) error
+	// FindScores returns the grades given in the assessments, to studentIDs
+	// only if it is not empty.
+	FindScores(ctx context.Context, collegeID int, assessmentIDs []int, studentIDs []int) ([]*models.Grade, error)
+}
+
+type assessmentRepository struct {
+	DB *DB
+}
+
+func NewAssessmentRepository(db *DB) AssessmentRepository {
+	return &assessmentRepository{DB: db}
+}
+
+func (r *assessmentRepository) CreateAssessment(ctx context.Context, assessment *models.Assessment) error {
+	now := time.Now()
+	assessment.CreatedAt = now
+	assessment.UpdatedAt = now
+
+	sql, args, err := r.DB.SQ.Insert(assessmentTable).
+		Columns("college_id", "course_id", "term_id", "name", "exam_type", "weight", "max_marks", "created_at", "updated_at").
+		Values(assessment.CollegeID, assessment.CourseID, termOrDefault(assessment.TermID, currentTerm(assessment.CollegeID)),
+			assessment.Name, assessment.ExamType, assessment.Weight, assessment.MaxMarks, assessment.CreatedAt, assessment.UpdatedAt).
+		Suffix("RETURNING id, term_id").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CreateAssessment: failed to build query: %w", err)
+	}
+
+	return r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if err := lockCourseAssessments(ctx, tx, assessment.CourseID); err != nil {
+			return fmt.Errorf("CreateAssessment: %w", err)
+		}
+		if err := tx.QueryRow(ctx, sql, args...).Scan(&assessment.ID, &assessment.TermID); err != nil {
+			return fmt.Errorf("CreateAssessment: %w", assessmentError(err))
+		}
+		if err := checkUnlocked(ctx, tx, assessment.CourseID, assessment.TermID); err != nil {
+			return fmt.Errorf("CreateAssessment: %w", err)
+		}
+		if err := r.checkWeights(ctx, tx, assessment); err != nil {
+			return fmt.Errorf("CreateAssessment: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *assessmentRepository) GetAssessment(ctx context.Context, collegeID int, assessmentID int) (*models.Assessment, error) {
+	sql, args, err := r.DB.SQ.Select(assessmentQueryFields...).
+		From(assessmentTable).
+		Where(squirrel.Eq{"id": assessmentID, "college_id": collegeID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("GetAssessment: failed to build query: %w", err)
+	}
+
+	assessment := &models.Assessment{}
+	if err := pgxscan.Get(ctx, r.DB.Pool, assessment, sql, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("GetAssessment: assessment %d for college ID %d: %w", assessmentID, collegeID, ErrAssessmentNotFound)
+		}
+		return nil, fmt.Errorf("GetAssessment: failed to execute query or scan: %w", err)
+	}
+	return assessment, nil
+}
+
+func (r *assessmentRepository) UpdateAssessment(ctx context.Context, assessment *models.Assessment) error {
+	assessment.UpdatedAt = time.Now()
+
+	sql, args, err := r.DB.SQ.Update(assessmentTable).
+		Set("name", assessment.Name).
+		Set("weight", assessment.Weight).
+		Set("max_marks", assessment.MaxMarks).
+		Set("updated_at", assessment.UpdatedAt).
+		Where(squirrel.Eq{"id": assessment.ID, "college_id": assessment.CollegeID}).
+		Suffix("RETURNING course_id, term_id").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("UpdateAssessment: failed to build query: %w", err)
+	}
+
+	return r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if err := lockCourseAssessments(ctx, tx, assessment.CourseID); err != nil {
+			return fmt.Errorf("UpdateAssessment: %w", err)
+		}
+		if err := tx.QueryRow(ctx, sql, args...).Scan(&assessment.CourseID, &assessment.TermID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("UpdateAssessment: assessment %d for college ID %d: %w", assessment.ID, assessment.CollegeID, ErrAssessmentNotFound)
+			}
+			return fmt.Errorf("UpdateAssessment: failed to execute query: %w", err)
+		}
+		if err := checkUnlocked(ctx, tx, assessment.CourseID, assessment.TermID); err != nil {
+			return fmt.Errorf("UpdateAssessment: %w", err)
+		}
+		if err := r.checkWeights(ctx, tx, assessment); err != nil {
+			return fmt.Errorf("UpdateAssessment: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *assessmentRepository) DeleteAssessment(ctx context.Context, collegeID int, assessmentID int) error {
+	assessment, err := r.GetAssessment(ctx, collegeID, assessmentID)
+	if err != nil {
+		return fmt.Errorf("DeleteAssessment: %w", err)
+	}
+	sql, args, err := r.DB.SQ.Delete(assessmentTable).
+		Where(squirrel.Eq{"id": assessmentID, "college_id": collegeID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("DeleteAssessment: failed to build query: %w", err)
+	}
+
+	return r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if err := lockCourseAssessments(ctx, tx, assessment.CourseID); err != nil {
+			return fmt.Errorf("DeleteAssessment: %w", err)
+		}
+		if err := checkUnlocked(ctx, tx, assessment.CourseID, assessment.TermID); err != nil {
+			return fmt.Errorf("DeleteAssessment: %w", err)
+		}
+		commandTag, err := tx.Exec(ctx, sql, args...)
+		if err != nil {
+			return fmt.Errorf("DeleteAssessment: failed to execute query: %w", err)
+		}
+		if commandTag.RowsAffected() == 0 {
+			return fmt.Errorf("DeleteAssessment: assessment %d for college ID %d: %w", assessmentID, collegeID, ErrAssessmentNotFound)
+		}
+		return nil
+	})
+}
+
+func (r *assessmentRepository) FindAssessments(ctx context.Context, collegeID int, courseID int, termID *int) ([]*models.Assessment, error) {
+	query := r.DB.SQ.Select(assessmentQueryFields...).
+		From(assessmentTable).
+		Where(squirrel.Eq{"college_id": collegeID, "course_id": courseID}).
+		OrderBy("id ASC")
+	if termID != nil {
+		query = query.Where(squirrel.Eq{"term_id": *termID})
+	} else {
+		query = query.Where(squirrel.Eq{"term_id": nil})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindAssessments: failed to build query: %w", err)
+	}
+	assessments := []*models.Assessment{}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &assessments, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindAssessments: failed to execute query or scan: %w", err)
+	}
+	return assessments, nil
+}
+
+func (r *assessmentRepository) SaveScores(ctx context.Context, grades []*models.Grade) error {
+	if len(grades) == 0 {
+		return nil
+	}
+	now := time.Now()
+	insert := r.DB.SQ.Insert(gradeTable).
+		Columns(
+			"student_id", "course_id", "college_id", "term_id", "assessment_id", "marks_obtained", "total_marks",
+			"semester", "academic_year", "exam_type", "graded_at", "comments", "created_at", "updated_at",
+		).
+		Suffix(`ON CONFLICT (assessment_id, student_id) WHERE assessment_id IS NOT NULL DO UPDATE SET
+			marks_obtained = EXCLUDED.marks_obtained,
+			total_marks = EXCLUDED.total_marks,
+			comments = EXCLUDED.comments,
+			graded_at = EXCLUDED.graded_at,
+			updated_at = EXCLUDED.updated_at
+			RETURNING id`)
+	for _, g := range grades {
+		g.GradedAt, g.CreatedAt, g.UpdatedAt = now, now, now
+		insert = insert.Values(
+			g.StudentID, g.CourseID, g.CollegeID, g.TermID, g.AssessmentID, g.MarksObtained, g.TotalMarks,
+			g.Semester, g.AcademicYear, g.ExamType, g.GradedAt, g.Comments, g.CreatedAt, g.UpdatedAt,
+		)
+	}
+	sql, args, err := insert.ToSql()
+	if err != nil {
+		return fmt.Errorf("SaveScores: failed to build query: %w", err)
+	}
+
+	return r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		// Scores are saved one assessment at a time
+		if err := lockCourseAssessments(ctx, tx, grades[0].CourseID); err != nil {
+			return fmt.Errorf("SaveScores: %w", err)
+		}
+		if err := checkUnlocked(ctx, tx, grades[0].CourseID, grades[0].TermID); err != nil {
+			return fmt.Errorf("SaveScores: %w", err)
+		}
+		rows, err := tx.Query(ctx, sql, args...)
+		if err != nil {
+			return fmt.Errorf("SaveScores: failed to execute query: %w", err)
+		}
+		defer rows.Close()
+		for i := 0; rows.Next(); i++ {
+			if err := rows.Scan(&grades[i].ID); err != nil {
+				return fmt.Errorf("SaveScores: failed to scan ID: %w", err)
+			}
+		}
+		return rows.Err()
+	})
+}
+
+func (r *assessmentRepository) FindScores(ctx context.Context, collegeID int, assessmentIDs []int, studentIDs []int) ([]*models.Grade, error) {
+	grades := []*models.Grade{}
+	if len(assessmentIDs) == 0 {
+		return grades, nil
+	}
+	query := r.DB.SQ.Select(gradeQueryFields...).
+		From(gradeTable).
+		Where(squirrel.Eq{"college_id": collegeID, "assessment_id": assessmentIDs}).
+		OrderBy("student_id ASC", "assessment_id ASC")
+	if len(studentIDs) > 0 {
+		query = query.Where(squirrel.Eq{"student_id": studentIDs})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindScores: failed to build query: %w", err)
+	}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &grades, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindScores: failed to execute query or scan: %w", err)
+	}
+	return grades, nil
+}
+
+// lockCourseAssessments serialises changes to a course's assessments and
+// scores so the weight and curve lock checks see every committed change.
+func lockCourseAssessments(ctx context.Context, tx pgx.Tx, courseID int) error {
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext('"+assessmentTable+"'), $1)", courseID); err != nil {
+		return fmt.Errorf("failed to lock course assessments: %w", err)
+	}
+	return nil
+}
+
+// checkWeights fails if the assessments of the course in the assessment's
+// term, itself included, weigh more than 100.
+func (r *assessmentRepository) checkWeights(ctx context.Context, tx pgx.Tx, assessment *models.Assessment) error {
+	sql, args, err := r.DB.SQ.Select("COALESCE(SUM(weight), 0)::float8").
+		From(assessmentTable).
+		Where(squirrel.Eq{"course_id": assessment.CourseID}).
+		Where(squirrel.Expr("term_id IS NOT DISTINCT FROM ?", assessment.TermID)).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build weight query: %w", err)
+	}
+	var total float64
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&total); err != nil {
+		return fmt.Errorf("failed to sum weights: %w", err)
+	}
+	if total > 100 {
+		return fmt.Errorf("%.2f%% of the course is left: %w", 100-(total-assessment.Weight), ErrAssessmentWeightExceeded)
+	}
+	return nil
+}
+
+// assessmentError maps constraint violations on course_assessments to our
+// errors.
+func assessmentError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_course_assessments_component" {
+		return ErrAssessmentExists
+	}
+	return fmt.Errorf("failed to execute query or scan ID: %w", err)
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+)
+
+// MarkAbsentees fills in attendance for up to limit lectures that ended by now
+// and have not been processed yet. Every active student enrolled in the course
+// without a record gets Absent, or Excused if an approved leave covers the
+// lecture date. Lectures are claimed with FOR UPDATE SKIP LOCKED and stamped
+// with absentees_marked_at in the same transaction, so concurrent replicas never
+// process the same lecture and a lecture is never processed twice.
+func (a *attendanceRepository) MarkAbsentees(ctx context.Context, now time.Time, limit uint64) (int, int, error) {
+	claimSQL, claimArgs, err := a.DB.SQ.Select("id", "college_id", "course_id", "start_time::date AS lecture_date").
+		From(lectureTable).
+		Where(squirrel.LtOrEq{"end_time": now}).
+		Where("absentees_marked_at IS NULL").
+		OrderBy("end_time ASC").
+		Limit(limit).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return 0, 0, fmt.Errorf("MarkAbsentees: failed to build claim query: %w", err)
+	}
+
+	var lectures []struct {
+		ID          int       `db:"id"`
+		CollegeID   int       `db:"college_id"`
+		CourseID    int       `db:"course_id"`
+		LectureDate time.Time `db:"lecture_date"`
+	}
+	marked := 0
+	err = a.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if err := pgxscan.Select(ctx, tx, &lectures, claimSQL, claimArgs...); err != nil {
+			return fmt.Errorf("failed to claim lectures: %w", err)
+		}
+		for _, l := range lectures {
+			n, err := a.insertAbsentees(ctx, tx, l.CollegeID, l.CourseID, l.ID, l.LectureDate, now)
+			if err != nil {
+				return fmt.Errorf("lecture %d: %w", l.ID, err)
+			}
+			marked += n
+		}
+		if len(lectures) == 0 {
+			return nil
+		}
+
+		ids := make([]int, len(lectures))
+		for i, l := range lectures {
+			ids[i] = l.ID
+		}
+		sql, args, err := a.DB.SQ.Update(lectureTable).
+			Set("absentees_marked_at", now).
+			Where(squirrel.Eq{"id": ids}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("failed to build update query: %w", err)
+		}
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return fmt.Errorf("failed to stamp lectures: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("MarkAbsentees: %w", err)
+	}
+	return len(lectures), marked, nil
+}
+
+// insertAbsentees writes the missing rows for one lecture and their audit entries.
+func (a *attendanceRepository) insertAbsentees(ctx context.Context, tx pgx.Tx, collegeID, courseID, lectureID int, lectureDate, now time.Time) (int, error) {
+	onLeave := squirrel.Select("1").
+		From(leaveRequestTable+" lr").
+		Where("lr.student_id = e.student_id AND lr.college_id = e.college_id").
+		Where(squirrel.Eq{"lr.status": models.LeaveStatusApproved}).
+		Where("? BETWEEN lr.start_date AND lr.end_date", lectureDate)
+	onLeaveSQL, onLeaveArgs, err := onLeave.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build leave subquery: %w", err)
+	}
+	recorded := squirrel.Select("1").
+		From(attendanceTable + " att").
+		Where("att.student_id = e.student_id AND att.college_id = e.college_id").
+		Where(squirrel.Eq{"att.lecture_id": lectureID})
+	recordedSQL, recordedArgs, err := recorded.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build attendance subquery: %w", err)
+	}
+
+	// Placeholders in a select list are untyped, so they are cast explicitly.
+	missing := squirrel.Select("e.student_id", "e.course_id", "e.college_id").
+		Column("?::int", lectureID).
+		Column("?::date", lectureDate).
+		Column("CASE WHEN EXISTS ("+onLeaveSQL+") THEN 'Excused' ELSE 'Absent' END", onLeaveArgs...).
+		Column("?::timestamptz", now).
+		From(enrollmentTable+" e").
+		Join(studentTable+" s ON s.id = e.student_id AND s.college_id = e.college_id").
+		Where(squirrel.Eq{"e.college_id": collegeID, "e.course_id": courseID, "s.is_active": true}).
+		Where(activeEnrollment).
+		Where("NOT EXISTS ("+recordedSQL+")", recordedArgs...)
+
+	sql, args, err := a.DB.SQ.Insert(attendanceTable).
+		Columns("student_id", "course_id", "college_id", "lecture_id", "date", "status", "scanned_at").
+		Select(missing).
+		Suffix("ON CONFLICT DO NOTHING RETURNING id, student_id, status").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build insert query: %w", err)
+	}
+
+	var inserted []struct {
+		ID        int    `db:"id"`
+		StudentID int    `db:"student_id"`
+		Status    string `db:"status"`
+	}
+	if err := pgxscan.Select(ctx, tx, &inserted, sql, args...); err != nil {
+		return 0, fmt.Errorf("failed to insert absentees: %w", err)
+	}
+
+	changes := map[string][]attendanceChange{}
+	for _, row := range inserted {
+		changes[row.Status] = append(changes[row.Status], attendanceChange{
+			AttendanceID: row.ID,
+			StudentID:    row.StudentID,
+			CourseID:     courseID,
+			LectureID:    lectureID,
+			NewStatus:    row.Status,
+		})
+	}
+	reasons := map[string]string{
+		"Absent":  "no attendance recorded before the lecture ended",
+		"Excused": "approved leave covers the lecture date",
+	}
+	for status, rows := range changes {
+		audit := models.AuditInfo{ActorID: models.AuditActorSystem, Source: models.AuditSourceAbsenteeJob, Reason: reasons[status]}
+		if err := a.insertAttendanceAudit(ctx, tx, collegeID, rows, audit); err != nil {
+			return 0, err
+		}
+	}
+	return len(inserted), nil
+}
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+)
+
+const attendanceAuditTable = "attendance_audit_log"
+
+// attendanceChange is a single status transition waiting to be written to the audit log.
+type attendanceChange struct {
+	AttendanceID int
+	StudentID    int
+	CourseID     int
+	LectureID    int
+	OldStatus    *string
+	NewStatus    string
+}
+
+// insertAttendanceAudit appends one audit row per change. It must run on the
+// same Querier (transaction) as the mutation it describes.
+func (a *attendanceRepository) insertAttendanceAudit(ctx context.Context, q Querier, collegeID int, changes []attendanceChange, audit models.AuditInfo) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	if audit.ActorID == "" || audit.Source == "" {
+		return fmt.Errorf("insertAttendanceAudit: actor and source are required")
+	}
+	var reason *string
+	if audit.Reason != "" {
+		reason = &audit.Reason
+	}
+
+	now := time.Now()
+	query := a.DB.SQ.Insert(attendanceAuditTable).
+		Columns("attendance_id", "college_id", "student_id", "course_id", "lecture_id", "old_status", "new_status", "actor_identity_id", "source", "reason", "created_at")
+	for _, c := range changes {
+		query = query.Values(c.AttendanceID, collegeID, c.StudentID, c.CourseID, c.LectureID, c.OldStatus, c.NewStatus, audit.ActorID, audit.Source, reason, now)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("insertAttendanceAudit: failed to build query: %w", err)
+	}
+	if _, err := q.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("insertAttendanceAudit: failed to execute query: %w", err)
+	}
+	return nil
+}
+
+// upsertAttendanceStatus creates or overwrites one student's attendance for a
+// lecture and records the transition. A lecture has at most one row per
+// student; new rows are dated by the lecture's start time. It returns false if
+// nothing was written.
+func (a *attendanceRepository) upsertAttendanceStatus(ctx context.Context, q Querier, collegeID int, studentID int, courseID int, lectureID int, status string, audit models.AuditInfo) (bool, error) {
+	now := time.Now()
+
+	oldSQL, oldArgs, err := a.DB.SQ.Select("id", "status").
+		From(attendanceTable).
+		Where(squirrel.Eq{
+			"student_id": studentID,
+			"course_id":  courseID,
+			"lecture_id": lectureID,
+			"college_id": collegeID,
+		}).
+		OrderBy("id ASC").
+		Limit(1).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("upsertAttendanceStatus: failed to build lookup query: %w", err)
+	}
+	var existing []struct {
+		ID     int    `db:"id"`
+		Status string `db:"status"`
+	}
+	if err := pgxscan.Select(ctx, q, &existing, oldSQL, oldArgs...); err != nil {
+		return false, fmt.Errorf("upsertAttendanceStatus: failed to execute lookup query: %w", err)
+	}
+
+	change := attendanceChange{StudentID: studentID, CourseID: courseID, LectureID: lectureID, NewStatus: status}
+	if len(existing) > 0 {
+		change.AttendanceID = existing[0].ID
+		change.OldStatus = &existing[0].Status
+
+		sql, args, err := a.DB.SQ.Update(attendanceTable).
+			Set("status", status).
+			Set("scanned_at", now).
+			Set("updated_at", now).
+			Where(squirrel.Eq{"id": change.AttendanceID}).
+			ToSql()
+		if err != nil {
+			return false, fmt.Errorf("upsertAttendanceStatus: failed to build update query: %w", err)
+		}
+		commandTag, err := q.Exec(ctx, sql, args...)
+		if err != nil {
+			return false, fmt.Errorf("upsertAttendanceStatus: failed to execute update query: %w", err)
+		}
+		if commandTag.RowsAffected() == 0 {
+			return false, nil
+		}
+	} else {
+		lectureDate := squirrel.Expr("COALESCE((SELECT start_time::date FROM "+lectureTable+" WHERE id = ?), CURRENT_DATE)", lectureID)
+		sql, args, err := a.DB.SQ.Insert(attendanceTable).
+			Columns("student_id", "course_id", "college_id", "lecture_id", "date", "status", "scanned_at").
+			Values(studentID, courseID, collegeID, lectureID, lectureDate, status, now).
+			Suffix(`ON CONFLICT (student_id, course_id, lecture_id, date, college_id)
+              DO UPDATE SET scanned_at = EXCLUDED.scanned_at, status = EXCLUDED.status
+              RETURNING id`).
+			ToSql()
+		if err != nil {
+			return false, fmt.Errorf("upsertAttendanceStatus: failed to build insert query: %w", err)
+		}
+		if err := q.QueryRow(ctx, sql, args...).Scan(&change.AttendanceID); err != nil {
+			return false, fmt.Errorf("upsertAttendanceStatus: failed to execute insert query: %w", err)
+		}
+	}
+
+	// a repeat scan only refreshes scanned_at; it is not a status change
+	if change.OldStatus != nil && *change.OldStatus == status {
+		return true, nil
+	}
+	if err := a.insertAttendanceAudit(ctx, q, collegeID, []attendanceChange{change}, audit); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// updateAttendanceStatusWhere sets status on every attendance row matching
+// where and records each transition. It returns the number of rows changed.
+func (a *attendanceRepository) updateAttendanceStatusWhere(ctx context.Context, q Querier, collegeID int, where squirrel.Sqlizer, status string, audit models.AuditInfo) (int, error) {
+	lockSQL, lockArgs, err := a.DB.SQ.Select("id", "student_id", "course_id", "lecture_id", "status").
+		From(attendanceTable).
+		Where(squirrel.Eq{"college_id": collegeID}).
+		Where(where).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("updateAttendanceStatusWhere: failed to build lookup query: %w", err)
+	}
+	var rows []struct {
+		ID        int    `db:"id"`
+		StudentID int    `db:"student_id"`
+		CourseID  int    `db:"course_id"`
+		LectureID int    `db:"lecture_id"`
+		Status    string `db:"status"`
+	}
+	if err := pgxscan.Select(ctx, q, &rows, lockSQL, lockArgs...); err != nil {
+		return 0, fmt.Errorf("updateAttendanceStatusWhere: failed to execute lookup query: %w", err)
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int, len(rows))
+	changes := make([]attendanceChange, len(rows))
+	for i, r := range rows {
+		ids[i] = r.ID
+		oldStatus := r.Status
+		changes[i] = attendanceChange{AttendanceID: r.ID, StudentID: r.StudentID, CourseID: r.CourseID, LectureID: r.LectureID, OldStatus: &oldStatus, NewStatus: status}
+	}
+
+	sql, args, err := a.DB.SQ.Update(attendanceTable).
+		Set("status", status).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": ids}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("updateAttendanceStatusWhere: failed to build query: %w", err)
+	}
+	if _, err := q.Exec(ctx, sql, args...); err != nil {
+		return 0, fmt.Errorf("updateAttendanceStatusWhere: failed to execute query: %w", err)
+	}
+
+	if err := a.insertAttendanceAudit(ctx, q, collegeID, changes, audit); err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+// restoreFrozenAttendance puts each of the student's Frozen rows back to the
+// status it had before it was frozen, read from the audit log. Rows without
+// that history fall back to Absent. It returns the number of rows restored.
+func (a *attendanceRepository) restoreFrozenAttendance(ctx context.Context, q Querier, collegeID int, studentID int, audit models.AuditInfo) (int, error) {
+	previous := squirrel.Select("al.old_status").
+		From(attendanceAuditTable + " al").
+		Where("al.attendance_id = att.id AND al.new_status = 'Frozen' AND al.old_status IS NOT NULL").
+		OrderBy("al.id DESC").
+		Limit(1)
+
+	sql, args, err := a.DB.SQ.Select("att.id").
+		Column(squirrel.Alias(squirrel.Expr("COALESCE((?), 'Absent')", previous), "restore_status")).
+		From(attendanceTable + " att").
+		Where(squirrel.Eq{"att.college_id": collegeID, "att.student_id": studentID, "att.status": "Frozen"}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("restoreFrozenAttendance: failed to build query: %w", err)
+	}
+	var rows []struct {
+		ID            int    `db:"id"`
+		RestoreStatus string `db:"restore_status"`
+	}
+	if err := pgxscan.Select(ctx, q, &rows, sql, args...); err != nil {
+		return 0, fmt.Errorf("restoreFrozenAttendance: failed to execute query: %w", err)
+	}
+
+	byStatus := map[string][]int{}
+	for _, r := range rows {
+		byStatus[r.RestoreStatus] = append(byStatus[r.RestoreStatus], r.ID)
+	}
+	restored := 0
+	for status, ids := range byStatus {
+		n, err := a.updateAttendanceStatusWhere(ctx, q, collegeID, squirrel.Eq{"id": ids, "status": "Frozen"}, status, audit)
+		if err != nil {
+			return restored, err
+		}
+		restored += n
+	}
+	return restored, nil
+}
+
+func (a *attendanceRepository) GetAttendanceHistory(ctx context.Context, collegeID int, attendanceID int) ([]*models.AttendanceAuditEntry, error) {
+	return a.findAttendanceAudit(ctx, squirrel.Eq{"college_id": collegeID, "attendance_id": attendanceID}, 0, 0)
+}
+
+func (a *attendanceRepository) GetLectureAttendanceHistory(ctx context.Context, collegeID int, courseID int, lectureID int, limit, offset uint64) ([]*models.AttendanceAuditEntry, error) {
+	return a.findAttendanceAudit(ctx, squirrel.Eq{"college_id": collegeID, "course_id": courseID, "lecture_id": lectureID}, limit, offset)
+}
+
+// findAttendanceAudit lists audit entries oldest first; a zero limit returns all of them.
+func (a *attendanceRepository) findAttendanceAudit(ctx context.Context, where squirrel.Eq, limit, offset uint64) ([]*models.AttendanceAuditEntry, error) {
+	query := a.DB.SQ.Select("id", "attendance_id", "college_id", "student_id", "course_id", "lecture_id", "old_status", "new_status", "actor_identity_id", "source", "reason", "created_at").
+		From(attendanceAuditTable).
+		Where(where).
+		OrderBy("created_at ASC", "id ASC")
+	if limit > 0 {
+		query = query.Limit(limit).Offset(offset)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("findAttendanceAudit: failed to build query: %w", err)
+	}
+
+	entries := []*models.AttendanceAuditEntry{}
+	err = pgxscan.Select(ctx, a.DB.Pool, &entries, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("findAttendanceAudit: failed to execute query or scan: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAttendanceAuditTest(t *testing.T) (pgxmock.PgxPoolIface, *attendanceRepository, context.Context) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+
+	db := &DB{
+		Pool: mock,
+		SQ:   squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+	return mock, &attendanceRepository{DB: db}, context.Background()
+}
+
+var freezeAudit = models.AuditInfo{ActorID: "admin-identity", Source: models.AuditSourceFreeze, Reason: "suspension 4"}
+
+func TestUpdateAttendanceStatusWhere(t *testing.T) {
+	mock, repo, ctx := setupAttendanceAuditTest(t)
+	defer mock.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, student_id, course_id, lecture_id, status FROM attendance WHERE college_id = $1 AND student_id = $2 FOR UPDATE`)).
+		WithArgs(1, 101).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "student_id", "course_id", "lecture_id", "status"}).
+			AddRow(5, 101, 2, 201, "Present").
+			AddRow(6, 101, 3, 301, "Late"))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE attendance SET status = $1, updated_at = $2 WHERE id IN ($3,$4)`)).
+		WithArgs("Frozen", pgxmock.AnyArg(), 5, 6).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+	// One audit row per change, with the status each row had
+	present, late, reason := "Present", "Late", freezeAudit.Reason
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO attendance_audit_log`)).
+		WithArgs(
+			5, 1, 101, 2, 201, &present, "Frozen", freezeAudit.ActorID, freezeAudit.Source, &reason, pgxmock.AnyArg(),
+			6, 1, 101, 3, 301, &late, "Frozen", freezeAudit.ActorID, freezeAudit.Source, &reason, pgxmock.AnyArg(),
+		).
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+
+	n, err := repo.updateAttendanceStatusWhere(ctx, mock, 1, squirrel.Eq{"student_id": 101}, "Frozen", freezeAudit)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateAttendanceStatusWhere_NoRows(t *testing.T) {
+	mock, repo, ctx := setupAttendanceAuditTest(t)
+	defer mock.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, student_id, course_id, lecture_id, status FROM attendance WHERE college_id = $1 AND student_id = $2 FOR UPDATE`)).
+		WithArgs(1, 101).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "student_id", "course_id", "lecture_id", "status"}))
+
+	// Nothing to change means no update and no audit rows
+	n, err := repo.updateAttendanceStatusWhere(ctx, mock, 1, squirrel.Eq{"student_id": 101}, "Frozen", freezeAudit)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreFrozenAttendance(t *testing.T) {
+	mock, repo, ctx := setupAttendanceAuditTest(t)
+	defer mock.Close()
+	audit := models.AuditInfo{ActorID: models.AuditActorSystem, Source: models.AuditSourceUnfreeze}
+
+	// The status before freezing comes from the audit log, Absent without one
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT att.id, (COALESCE((SELECT al.old_status FROM attendance_audit_log al WHERE al.attendance_id = att.id AND al.new_status = 'Frozen' AND al.old_status IS NOT NULL ORDER BY al.id DESC LIMIT 1), 'Absent')) AS restore_status FROM attendance att WHERE att.college_id = $1 AND att.status = $2 AND att.student_id = $3`)).
+		WithArgs(1, "Frozen", 101).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "restore_status"}).
+			AddRow(5, "Present").
+			AddRow(6, "Present"))
+	// Rows are only restored if they are still Frozen
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, student_id, course_id, lecture_id, status FROM attendance WHERE college_id = $1 AND id IN ($2,$3) AND status = $4 FOR UPDATE`)).
+		WithArgs(1, 5, 6, "Frozen").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "student_id", "course_id", "lecture_id", "status"}).
+			AddRow(5, 101, 2, 201, "Frozen"))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE attendance SET status = $1, updated_at = $2 WHERE id IN ($3)`)).
+		WithArgs("Present", pgxmock.AnyArg(), 5).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	frozen := "Frozen"
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO attendance_audit_log`)).
+		WithArgs(5, 1, 101, 2, 201, &frozen, "Present", models.AuditActorSystem, models.AuditSourceUnfreeze, (*string)(nil), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	n, err := repo.restoreFrozenAttendance(ctx, mock, 1, 101, audit)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
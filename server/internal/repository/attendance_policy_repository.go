@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+)
+
+var ErrAttendancePolicyNotFound = errors.New("attendance policy not configured")
+
+type AttendancePolicyRepository interface {
+	GetAttendancePolicy(ctx context.Context, collegeID int) (*models.AttendancePolicy, error)
+	UpsertAttendancePolicy(ctx context.Context, policy *models.AttendancePolicy) error
+}
+
+const attendancePolicyTable = "attendance_policies"
+
+type attendancePolicyRepository struct {
+	DB *DB
+}
+
+func NewAttendancePolicyRepository(db *DB) AttendancePolicyRepository {
+	return &attendancePolicyRepository{DB: db}
+}
+
+func (r *attendancePolicyRepository) GetAttendancePolicy(ctx context.Context, collegeID int) (*models.AttendancePolicy, error) {
+	sql, args, err := r.DB.SQ.Select("college_id", "grace_minutes", "late_cutoff_minutes", "absent_cutoff_minutes", "lates_per_absence", "updated_at").
+		From(attendancePolicyTable).
+		Where(squirrel.Eq{"college_id": collegeID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("GetAttendancePolicy: failed to build query: %w", err)
+	}
+
+	policy := &models.AttendancePolicy{}
+	if err := pgxscan.Get(ctx, r.DB.Pool, policy, sql, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAttendancePolicyNotFound
+		}
+		return nil, fmt.Errorf("GetAttendancePolicy: failed to execute query or scan: %w", err)
+	}
+	return policy, nil
+}
+
+func (r *attendancePolicyRepository) UpsertAttendancePolicy(ctx context.Context, policy *models.AttendancePolicy) error {
+	policy.UpdatedAt = time.Now()
+
+	sql, args, err := r.DB.SQ.Insert(attendancePolicyTable).
+		Columns("college_id", "grace_minutes", "late_cutoff_minutes", "absent_cutoff_minutes", "lates_per_absence", "updated_at").
+		Values(policy.CollegeID, policy.GraceMinutes, policy.LateCutoffMinutes, policy.AbsentCutoffMinutes, policy.LatesPerAbsence, policy.UpdatedAt).
+		Suffix(`ON CONFLICT (college_id) DO UPDATE SET
+              grace_minutes = EXCLUDED.grace_minutes,
+              late_cutoff_minutes = EXCLUDED.late_cutoff_minutes,
+              absent_cutoff_minutes = EXCLUDED.absent_cutoff_minutes,
+              lates_per_absence = EXCLUDED.lates_per_absence,
+              updated_at = EXCLUDED.updated_at`).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("UpsertAttendancePolicy: failed to build query: %w", err)
+	}
+	if _, err := r.DB.Pool.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("UpsertAttendancePolicy: failed to execute query: %w", err)
+	}
+	return nil
+}
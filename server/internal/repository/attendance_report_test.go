@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAttendanceShortageScopedToTerm(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+	repo := &attendanceRepository{DB: &DB{Pool: mock, SQ: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}}
+
+	// Held lectures and enrollments are both the term's, and attended
+	// lectures are those of the enrollment's term
+	mock.ExpectQuery(regexp.QuoteMeta(`(SELECT COUNT(*) FROM lectures l WHERE l.college_id = $1 AND l.course_id = $2 AND l.term_id IS NOT DISTINCT FROM $3 AND l.start_time <= NOW()) AS total_lectures`)+
+		`.*`+regexp.QuoteMeta(`AND term_id IS NOT DISTINCT FROM e.term_id) WHERE e.college_id = $5 AND e.course_id = $6 AND e.term_id IS NOT DISTINCT FROM $7 AND LOWER(e.status) = $8`)).
+		WithArgs(1, 10, 3, 1, 1, 10, 3, "active", 75.0).
+		WillReturnRows(pgxmock.NewRows([]string{"student_id", "roll_no", "total_lectures", "present", "absent", "late", "excused", "late_penalty", "percentage"}).
+			AddRow(7, "R7", 10, 5, 5, 0, 0, 0, 50.0))
+
+	reports, err := repo.GetAttendanceShortage(context.Background(), 1, 10, intPtr(3), 75)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, 50.0, reports[0].Percentage)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func intPtr(i int) *int { return &i }
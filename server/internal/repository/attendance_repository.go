@@ -11,14 +11,33 @@ import (
 
 	"github.com/Masterminds/squirrel"
 	"github.com/georgysavva/scany/pgxscan" // Import pgxscan
+	"github.com/jackc/pgx/v4"
 )
 
 type AttendanceRepository interface {
-	MarkAttendance(ctx context.Context, collegeID int, studentID int, courseID int, lectureID int) (bool, error)
-	UpdateAttendance(ctx context.Context, collegeID int, studentID int, courseID int, lectureID int, status string) error
-	SetAttendanceStatus(ctx context.Context, collegeID int, studentID, courseID int, lectureID int, status string) error
-	FreezeAttendance(ctx context.Context, collegeID int, studentID int) error
-	UnFreezeAttendance(ctx context.Context, collegeID int, studentID int) error
+	// Every mutation appends to the attendance audit log in the same transaction.
+	// MarkAttendance records a scan with the status the attendance policy gave
+	// it. A student already Present or Late for the lecture keeps that status.
+	MarkAttendance(ctx context.Context, collegeID int, studentID int, courseID int, lectureID int, status string, audit models.AuditInfo) (bool, error)
+	// MarkScannedAttendance is MarkAttendance for a QR scan: the student's
+	// redemption of nonce is recorded in the same transaction, failing with
+	// ErrQRCodeNotIssued or ErrQRCodeAlreadyRedeemed.
+	MarkScannedAttendance(ctx context.Context, collegeID int, studentID int, courseID int, lectureID int, status string, nonce string, audit models.AuditInfo) (bool, error)
+	UpdateAttendance(ctx context.Context, collegeID int, studentID int, courseID int, lectureID int, status string, audit models.AuditInfo) error
+	SetAttendanceStatus(ctx context.Context, collegeID int, studentID, courseID int, lectureID int, status string, audit models.AuditInfo) error
+	SetBulkAttendanceStatus(ctx context.Context, collegeID int, courseID int, lectureID int, statuses []models.StudentAttendanceStatus, audit models.AuditInfo) error
+	// ExcuseAttendanceRange marks the student Excused for every lecture of their
+	// active enrollments held between from and to (inclusive dates).
+	ExcuseAttendanceRange(ctx context.Context, collegeID int, studentID int, from, to time.Time, audit models.AuditInfo) (int, error)
+	// MarkAbsentees records missing attendance for up to limit lectures that
+	// ended by now. It returns how many lectures and rows it processed.
+	MarkAbsentees(ctx context.Context, now time.Time, limit uint64) (int, int, error)
+	FreezeAttendance(ctx context.Context, collegeID int, studentID int, audit models.AuditInfo) error
+	UnFreezeAttendance(ctx context.Context, collegeID int, studentID int, audit models.AuditInfo) error
+
+	// Audit history, oldest first
+	GetAttendanceHistory(ctx context.Context, collegeID int, attendanceID int) ([]*models.AttendanceAuditEntry, error)
+	GetLectureAttendanceHistory(ctx context.Context, collegeID int, courseID int, lectureID int, limit, offset uint64) ([]*models.AttendanceAuditEntry, error)
 
 	// Get methods with pagination
 	GetAttendanceByCourse(ctx context.Context, collegeID int, courseID int, limit, offset uint64) ([]*models.Attendance, error)
@@ -26,6 +45,16 @@ type AttendanceRepository interface {
 	GetAttendanceStudent(ctx context.Context, collegeID int, studentID int, limit, offset uint64) ([]*models.Attendance, error)
 	GetAttendanceByLecture(ctx context.Context, collegeID int, lectureID int, courseID int, limit, offset uint64) ([]*models.Attendance, error)
 
+	// Report methods aggregate in SQL, one row per enrolled student, over
+	// termID or the current term if it is unset
+	GetCourseAttendanceReport(ctx context.Context, collegeID int, courseID int, termID *int) ([]*models.StudentAttendanceReport, error)
+	GetAttendanceShortage(ctx context.Context, collegeID int, courseID int, termID *int, threshold float64) ([]*models.StudentAttendanceReport, error)
+
+	// StreamAttendanceRegister calls fn once per student actively enrolled in termID, or the
+	// current term if it is unset, ordered by roll number, with their statuses for the given
+	// lectures. Rows are read from the cursor as they arrive.
+	StreamAttendanceRegister(ctx context.Context, collegeID int, courseID int, termID *int, lectureIDs []int, fn func(row *models.AttendanceRegisterRow) error) error
+
 	// Count methods (add corresponding count methods if needed)
 	// ProcessQRCode(ctx context.Context, collegeID int, studentID int, courseID int, lectureID int) (bool, error)
 	// SetAttendanceStatus(ctx context.Context, collegeID int, studentID, courseID int, lectureID int, status string) error
@@ -105,72 +134,78 @@ func (a *attendanceRepository) GetAttendanceByCourse(
 	return attendances, nil
 }
 
-func (a *attendanceRepository) MarkAttendance(ctx context.Context, collegeID int, studentID, courseID int, lectureID int) (bool, error) {
-	now := time.Now()
-	// Truncate date for the 'date' column if you only store the date part
-	attendanceDate := now.Truncate(24 * time.Hour)
-
-	// This query attempts to insert a record.
-	// If a record for the same student, course, lecture, and date already exists,
-	// it updates the scanned_at timestamp. This is a common "upsert" pattern.
-	query := a.DB.SQ.Insert(attendanceTable).
-		Columns(
-
-			"student_id",
-			"course_id",
-			"college_id",
-			"lecture_id",
-			"date",
-			"status", // Initial status, e.g., "Present"
-			"scanned_at",
-		).
-		Values(
-
-			studentID,
-			courseID,
-			collegeID,
-			lectureID,
-			attendanceDate,
-			"Present", // Default status when marked
-			now,
-		).
-		Suffix(`ON CONFLICT (student_id, course_id, lecture_id, date, college_id)
-              DO UPDATE SET scanned_at = EXCLUDED.scanned_at, status = EXCLUDED.status`)
-
-	sql, args, err := query.ToSql()
+func (a *attendanceRepository) MarkAttendance(ctx context.Context, collegeID int, studentID, courseID int, lectureID int, status string, audit models.AuditInfo) (bool, error) {
+	var marked bool
+	err := a.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		var err error
+		marked, err = a.markAttendance(ctx, tx, collegeID, studentID, courseID, lectureID, status, audit)
+		return err
+	})
 	if err != nil {
-		return false, fmt.Errorf("MarkAttendance: failed to build query: %w", err)
+		return false, fmt.Errorf("MarkAttendance: %w", err)
 	}
+	return marked, nil
+}
 
-	// Execute the query (Exec is used for INSERT/UPDATE/DELETE)
-	commandTag, err := a.DB.Pool.Exec(ctx, sql, args...)
+func (a *attendanceRepository) MarkScannedAttendance(ctx context.Context, collegeID int, studentID int, courseID int, lectureID int, status string, nonce string, audit models.AuditInfo) (bool, error) {
+	var marked bool
+	err := a.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		// The nonce is only used up if the attendance is written
+		if err := redeemQRCode(ctx, a.DB, tx, collegeID, nonce, studentID); err != nil {
+			return err
+		}
+		var err error
+		marked, err = a.markAttendance(ctx, tx, collegeID, studentID, courseID, lectureID, status, audit)
+		return err
+	})
 	if err != nil {
-		return false, fmt.Errorf("MarkAttendance: failed to execute query: %w", err)
+		return false, fmt.Errorf("MarkScannedAttendance: %w", err)
 	}
-
-	// commandTag.RowsAffected() will be 1 if a row was inserted or updated.
-	// It's a good check, but often just checking for nil error is sufficient for "success".
-	// Given the bool return, let's return true if at least one row was affected.
-	return commandTag.RowsAffected() > 0, nil
+	return marked, nil
 }
 
-func (a *attendanceRepository) UpdateAttendance(ctx context.Context, collegeID int, studentID int, courseID int, lectureID int, status string) error {
-	query := a.DB.SQ.Update(attendanceTable).
-		Set("status", status).Where(squirrel.Eq{
-		"college_id": collegeID,
-		"student_id": studentID,
-		"course_id":  courseID,
-		"lecture_id": lectureID,
-	})
-	sql, args, err := query.ToSql()
+// markAttendance marks the student unless they are already Present or Late:
+// a repeat scan must not turn an earlier Present into Late; it only counts
+// once. The old and new status go to the audit log through q.
+func (a *attendanceRepository) markAttendance(ctx context.Context, q Querier, collegeID int, studentID, courseID int, lectureID int, status string, audit models.AuditInfo) (bool, error) {
+	sql, args, err := a.DB.SQ.Select("COUNT(*)").
+		From(attendanceTable).
+		Where(squirrel.Eq{
+			"student_id": studentID,
+			"course_id":  courseID,
+			"lecture_id": lectureID,
+			"college_id": collegeID,
+			"status":     []string{"Present", "Late"},
+		}).
+		ToSql()
 	if err != nil {
-		return fmt.Errorf("UpdateAttendance: failed to build query: %w", err) // Wrap error
+		return false, fmt.Errorf("failed to build lookup query: %w", err)
+	}
+	var attended int
+	if err := q.QueryRow(ctx, sql, args...).Scan(&attended); err != nil {
+		return false, fmt.Errorf("failed to execute lookup query: %w", err)
 	}
-	commandTag, err := a.DB.Pool.Exec(ctx, sql, args...)
+	if attended > 0 {
+		return true, nil
+	}
+	return a.upsertAttendanceStatus(ctx, q, collegeID, studentID, courseID, lectureID, status, audit)
+}
+
+func (a *attendanceRepository) UpdateAttendance(ctx context.Context, collegeID int, studentID int, courseID int, lectureID int, status string, audit models.AuditInfo) error {
+	var updated int
+	err := a.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		var err error
+		updated, err = a.updateAttendanceStatusWhere(ctx, tx, collegeID, squirrel.Eq{
+			"student_id": studentID,
+			"course_id":  courseID,
+			"lecture_id": lectureID,
+		}, status, audit)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("UpdateAttendance: failed to execute query: %w", err) // Wrap error
+		return fmt.Errorf("UpdateAttendance: %w", err)
 	}
-	if commandTag.RowsAffected() == 0 {
+	if updated == 0 {
 		return fmt.Errorf("did not update attendance") // Keep specific error for no rows affected
 	}
 	return nil
@@ -283,61 +318,91 @@ func (a *attendanceRepository) GetAttendanceByLecture(
 	return attendances, nil // Returns slice (empty if no rows) and nil error on success
 }
 
-// FreezeAttendance updates the status of all attendance records for a specific student to "Frozen".
-// This is a simple example; actual freezing logic might be more complex (e.g., only for past dates).
-func (a *attendanceRepository) FreezeAttendance(ctx context.Context, collegeID int, studentID int) error {
-	// Build the UPDATE query
-	query := a.DB.SQ.Update(attendanceTable).
-		Set("status", "Frozen"). // Set the status to "Frozen"
-		// Add other potential fields like freeze_date = now() if needed
-		Where(squirrel.Eq{ // Identify the records to freeze
-			"college_id": collegeID,
-			"student_id": studentID,
-		})
-		// Optional: Add a condition to only freeze records that aren't already frozen or finalized
-		// .Where(squirrel.NotEq{"status": "Frozen"})
-
-	sql, args, err := query.ToSql()
+// ExcuseAttendanceRange upserts Excused for each lecture of the student's active
+// enrollments that starts on a date between from and to. Lectures already
+// Excused or Frozen are skipped. It returns the number of lectures changed.
+func (a *attendanceRepository) ExcuseAttendanceRange(ctx context.Context, collegeID int, studentID int, from, to time.Time, audit models.AuditInfo) (int, error) {
+	settled := squirrel.Select("1").
+		From(attendanceTable + " att").
+		Where("att.student_id = e.student_id AND att.lecture_id = l.id AND att.college_id = l.college_id").
+		Where(squirrel.Eq{"att.status": []string{"Excused", "Frozen"}})
+	settledSQL, settledArgs, err := settled.ToSql()
 	if err != nil {
-		return fmt.Errorf("FreezeAttendance: failed to build query: %w", err)
+		return 0, fmt.Errorf("ExcuseAttendanceRange: failed to build subquery: %w", err)
 	}
 
-	// Execute the query
-	commandTag, err := a.DB.Pool.Exec(ctx, sql, args...)
+	sql, args, err := a.DB.SQ.Select("l.id", "l.course_id").
+		From(lectureTable+" l").
+		Join(enrollmentTable+" e ON e.course_id = l.course_id AND e.college_id = l.college_id").
+		Where(squirrel.Eq{"e.student_id": studentID, "e.college_id": collegeID}).
+		Where(activeEnrollment).
+		Where(squirrel.GtOrEq{"l.start_time": from}).
+		Where(squirrel.Lt{"l.start_time": to.AddDate(0, 0, 1)}).
+		Where("NOT EXISTS ("+settledSQL+")", settledArgs...).
+		OrderBy("l.start_time ASC").
+		ToSql()
 	if err != nil {
-		return fmt.Errorf("FreezeAttendance: failed to execute query: %w", err)
+		return 0, fmt.Errorf("ExcuseAttendanceRange: failed to build query: %w", err)
 	}
 
-	// Optional: Check if any rows were affected. Freezing might affect 0 rows
-	// if the student has no attendance records or they are already frozen.
-	if commandTag.RowsAffected() == 0 {
-		return fmt.Errorf("unabel to freeze attendance")
+	excused := 0
+	err = a.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		var lectures []struct {
+			ID       int `db:"id"`
+			CourseID int `db:"course_id"`
+		}
+		if err := pgxscan.Select(ctx, tx, &lectures, sql, args...); err != nil {
+			return fmt.Errorf("failed to find lectures: %w", err)
+		}
+		for _, l := range lectures {
+			written, err := a.upsertAttendanceStatus(ctx, tx, collegeID, studentID, l.CourseID, l.ID, "Excused", audit)
+			if err != nil {
+				return err
+			}
+			if written {
+				excused++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("ExcuseAttendanceRange: %w", err)
 	}
-	return nil // Success
+	return excused, nil
 }
 
-// UnFreezeAttendance updates the status of "Frozen" attendance records for a student back to a default (e.g., "Absent").
-func (a *attendanceRepository) UnFreezeAttendance(ctx context.Context, collegeID int, studentID int) error {
-	// Determine the status to revert to. "Absent" might be a safe default if the original status isn't stored.
-	revertStatus := "Absent" // Or fetch original status if stored elsewhere
-
-	query := a.DB.SQ.Update(attendanceTable).
-		Set("status", revertStatus).
-		// Add other potential fields like unfreeze_date = now() if needed
-		Where(squirrel.Eq{
-			"college_id": collegeID,
-			"student_id": studentID,
-			"status":     "Frozen", // Only unfreeze records that are currently frozen
-		})
-
-	sql, args, err := query.ToSql()
+// FreezeAttendance updates the status of all attendance records for a specific student to "Frozen".
+// This is a simple example; actual freezing logic might be more complex (e.g., only for past dates).
+func (a *attendanceRepository) FreezeAttendance(ctx context.Context, collegeID int, studentID int, audit models.AuditInfo) error {
+	var frozen int
+	err := a.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		var err error
+		frozen, err = a.updateAttendanceStatusWhere(ctx, tx, collegeID, squirrel.And{
+			squirrel.Eq{"student_id": studentID},
+			squirrel.NotEq{"status": "Frozen"},
+		}, "Frozen", audit)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("UnFreezeAttendance: failed to build query: %w", err)
+		return fmt.Errorf("FreezeAttendance: %w", err)
 	}
 
-	_, err = a.DB.Pool.Exec(ctx, sql, args...) // CommandTag not strictly needed here unless checking RowsAffected
+	// Freezing might affect 0 rows if the student has no attendance records or they are already frozen.
+	if frozen == 0 {
+		return fmt.Errorf("unabel to freeze attendance")
+	}
+	return nil // Success
+}
+
+// UnFreezeAttendance puts a student's "Frozen" attendance records back to the
+// status they had before they were frozen, or "Absent" if that is unknown.
+func (a *attendanceRepository) UnFreezeAttendance(ctx context.Context, collegeID int, studentID int, audit models.AuditInfo) error {
+	err := a.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		_, err := a.restoreFrozenAttendance(ctx, tx, collegeID, studentID, audit)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("UnFreezeAttendance: failed to execute query: %w", err)
+		return fmt.Errorf("UnFreezeAttendance: %w", err)
 	}
 
 	// Note: 0 rows affected is not necessarily an error here (student might have no frozen records).
@@ -355,19 +420,188 @@ func (a *attendanceRepository) UnFreezeAttendance(ctx context.Context, collegeID
 // 	LectureID int       `json:"lectureID"`
 // }
 
-func (a *attendanceRepository) SetAttendanceStatus(ctx context.Context, collegeID int, studentID int, courseID int, lectureID int, status string) error {
-	// Build the UPDATE query
-	query := a.DB.SQ.Insert(attendanceTable).Columns("student_id", "course_id", "college_id", "status", "scanned_at", "lecture_id").Values(studentID, courseID, collegeID, status, time.Now(), lectureID).Suffix(`ON CONFLICT (student_id, course_id, college_id, lecture_id) DO UPDATE SET status = EXCLUDED.status, scanned_at = EXCLUDED.scanned_at`)
+func (a *attendanceRepository) SetAttendanceStatus(ctx context.Context, collegeID int, studentID int, courseID int, lectureID int, status string, audit models.AuditInfo) error {
+	err := a.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		written, err := a.upsertAttendanceStatus(ctx, tx, collegeID, studentID, courseID, lectureID, status, audit)
+		if err != nil {
+			return err
+		}
+		if !written {
+			return errors.New("failed to update attendance")
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("SetAttendanceStatus: %w", err)
+	}
+	return nil
+}
+
+// SetBulkAttendanceStatus upserts every status in a single transaction. If any
+// write fails nothing is stored and the error is an *AttendanceWriteError
+// naming the student whose row failed.
+func (a *attendanceRepository) SetBulkAttendanceStatus(ctx context.Context, collegeID int, courseID int, lectureID int, statuses []models.StudentAttendanceStatus, audit models.AuditInfo) error {
+	return a.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		for _, st := range statuses {
+			if _, err := a.upsertAttendanceStatus(ctx, tx, collegeID, st.StudentID, courseID, lectureID, st.Status, audit); err != nil {
+				return &AttendanceWriteError{StudentID: st.StudentID, Err: err}
+			}
+		}
+		return nil
+	})
+}
+
+// AttendanceWriteError identifies the student whose attendance row could not be written.
+type AttendanceWriteError struct {
+	StudentID int
+	Err       error
+}
+
+func (e *AttendanceWriteError) Error() string {
+	return fmt.Sprintf("failed to write attendance for student %d: %v", e.StudentID, e.Err)
+}
+
+func (e *AttendanceWriteError) Unwrap() error {
+	return e.Err
+}
+
+// courseReportQuery builds the per-student attendance aggregate for a course's
+// active enrollments in the term. Only the term's lectures that have started
+// count, towards the total and every status, and Excused ones are left out of
+// the percentage.
+// When the college's policy sets lates_per_absence, every that many lates
+// cost one attended lecture.
+// The nested builders use the default '?' placeholders; the outer a.DB.SQ
+// query numbers them when it is rendered.
+func (a *attendanceRepository) courseReportQuery(collegeID int, courseID int, termID *int) squirrel.SelectBuilder {
+	term := termOrDefault(termID, currentTerm(collegeID))
+	heldLectures := squirrel.Select("COUNT(*)").
+		From(lectureTable + " l").
+		Where(squirrel.Eq{"l.college_id": collegeID, "l.course_id": courseID}).
+		Where(squirrel.Expr("l.term_id IS NOT DISTINCT FROM ?", term)).
+		Where("l.start_time <= NOW()")
+
+	latesPerAbsence := squirrel.Select("COALESCE(MAX(lates_per_absence), 0)").
+		From(attendancePolicyTable).
+		Where(squirrel.Eq{"college_id": collegeID})
+
+	counts := squirrel.Select(
+		"s.id AS student_id",
+		"s.roll_no",
+		"COUNT(att.id) FILTER (WHERE att.status = 'Present') AS present",
+		"COUNT(att.id) FILTER (WHERE att.status = 'Absent') AS absent",
+		"COUNT(att.id) FILTER (WHERE att.status = 'Late') AS late",
+		"COUNT(att.id) FILTER (WHERE att.status = 'Excused') AS excused",
+	).
+		Column(squirrel.Alias(heldLectures, "total_lectures")).
+		Column(squirrel.Alias(latesPerAbsence, "lates_per_absence")).
+		From(enrollmentTable+" e").
+		Join(studentTable+" s ON s.id = e.student_id").
+		LeftJoin(attendanceTable+" att ON att.student_id = e.student_id AND att.course_id = e.course_id AND att.college_id = e.college_id"+
+			" AND att.lecture_id IN (SELECT id FROM "+lectureTable+" WHERE start_time <= NOW() AND term_id IS NOT DISTINCT FROM e.term_id)").
+		Where(squirrel.Eq{"e.college_id": collegeID, "e.course_id": courseID}).
+		Where(squirrel.Expr("e.term_id IS NOT DISTINCT FROM ?", term)).
+		Where(activeEnrollment).
+		GroupBy("s.id", "s.roll_no")
+
+	penalised := squirrel.Select(
+		"*",
+		"CASE WHEN lates_per_absence > 0 THEN late / lates_per_absence ELSE 0 END AS late_penalty",
+	).FromSelect(counts, "c")
+
+	return squirrel.Select(
+		"student_id", "roll_no", "total_lectures", "present", "absent", "late", "excused", "late_penalty",
+		"COALESCE(ROUND(100.0 * (present + late - late_penalty) / NULLIF(total_lectures - excused, 0), 2), 0)::float8 AS percentage",
+	).FromSelect(penalised, "r")
+}
+
+func (a *attendanceRepository) GetCourseAttendanceReport(ctx context.Context, collegeID int, courseID int, termID *int) ([]*models.StudentAttendanceReport, error) {
+	query := a.DB.SQ.Select("*").
+		FromSelect(a.courseReportQuery(collegeID, courseID, termID), "report").
+		OrderBy("roll_no ASC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("GetCourseAttendanceReport: failed to build query: %w", err)
+	}
+
+	reports := []*models.StudentAttendanceReport{}
+	err = pgxscan.Select(ctx, a.DB.Pool, &reports, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("GetCourseAttendanceReport: failed to execute query or scan: %w", err)
+	}
+	return reports, nil
+}
+
+// GetAttendanceShortage returns students whose attendance percentage is below threshold.
+func (a *attendanceRepository) GetAttendanceShortage(ctx context.Context, collegeID int, courseID int, termID *int, threshold float64) ([]*models.StudentAttendanceReport, error) {
+	query := a.DB.SQ.Select("*").
+		FromSelect(a.courseReportQuery(collegeID, courseID, termID), "report").
+		Where(squirrel.Lt{"percentage": threshold}).
+		OrderBy("percentage ASC", "roll_no ASC")
+
 	sql, args, err := query.ToSql()
 	if err != nil {
-		return errors.New("failed to build query")
+		return nil, fmt.Errorf("GetAttendanceShortage: failed to build query: %w", err)
 	}
-	commandTag, err := a.DB.Pool.Exec(ctx, sql, args...)
+
+	reports := []*models.StudentAttendanceReport{}
+	err = pgxscan.Select(ctx, a.DB.Pool, &reports, sql, args...)
 	if err != nil {
-		return errors.New("failed to execute query")
+		return nil, fmt.Errorf("GetAttendanceShortage: failed to execute query or scan: %w", err)
+	}
+	return reports, nil
+}
+
+func (a *attendanceRepository) StreamAttendanceRegister(ctx context.Context, collegeID int, courseID int, termID *int, lectureIDs []int, fn func(row *models.AttendanceRegisterRow) error) error {
+	query := a.DB.SQ.Select("s.id", "s.roll_no", "att.lecture_id", "att.status").
+		From(enrollmentTable+" e").
+		Join(studentTable+" s ON s.id = e.student_id").
+		LeftJoin(attendanceTable+" att ON att.student_id = e.student_id AND att.course_id = e.course_id AND att.college_id = e.college_id AND att.lecture_id = ANY(?)", lectureIDs).
+		Where(squirrel.Eq{"e.college_id": collegeID, "e.course_id": courseID}).
+		Where(squirrel.Expr("e.term_id IS NOT DISTINCT FROM ?", termOrDefault(termID, currentTerm(collegeID)))).
+		Where(activeEnrollment).
+		OrderBy("s.roll_no ASC", "s.id ASC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("StreamAttendanceRegister: failed to build query: %w", err)
+	}
+
+	rows, err := a.DB.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("StreamAttendanceRegister: failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	var current *models.AttendanceRegisterRow
+	for rows.Next() {
+		var (
+			studentID int
+			rollNo    string
+			lectureID *int
+			status    *string
+		)
+		if err := rows.Scan(&studentID, &rollNo, &lectureID, &status); err != nil {
+			return fmt.Errorf("StreamAttendanceRegister: failed to scan row: %w", err)
+		}
+		if current == nil || current.StudentID != studentID {
+			if current != nil {
+				if err := fn(current); err != nil {
+					return err
+				}
+			}
+			current = &models.AttendanceRegisterRow{StudentID: studentID, RollNo: rollNo, Statuses: map[int]string{}}
+		}
+		if lectureID != nil && status != nil {
+			current.Statuses[*lectureID] = *status
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("StreamAttendanceRegister: failed to iterate rows: %w", err)
 	}
-	if commandTag.RowsAffected() == 0 {
-		return errors.New("failed to update attendance")
+	if current != nil {
+		return fn(current)
 	}
 	return nil
 }
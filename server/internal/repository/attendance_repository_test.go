@@ -3,9 +3,12 @@ package repository
 import (
 	"context"
 	"errors"
+	"regexp"
 	"testing"
 	"time"
 
+	"eduhub/server/internal/models"
+
 	"github.com/Masterminds/squirrel"
 	"github.com/pashagolub/pgxmock"
 	"github.com/stretchr/testify/assert"
@@ -48,12 +51,12 @@ func TestGetAttendanceByCourse(t *testing.T) {
 		AddRow(2, 102, 2, 1, time.Now(), "Absent", time.Now(), 201)
 
 	// Expect the query with specific arguments
-	mock.ExpectQuery(`SELECT  id, student_id, course_id, college_id, date, status, scanned_at, lecture_id FROM attendance WHERE`).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, student_id, course_id, college_id, date, status, scanned_at, lecture_id FROM attendance WHERE college_id = $1 AND course_id = $2 ORDER BY date DESC, student_id ASC LIMIT 10 OFFSET 0`)).
 		WithArgs(collegeID, courseID).
 		WillReturnRows(rows)
 
 	// Call the method
-	attendances, err := repo.GetAttendanceByCourse(ctx, collegeID, courseID)
+	attendances, err := repo.GetAttendanceByCourse(ctx, collegeID, courseID, 10, 0)
 
 	// Assert no error occurred
 	assert.NoError(t, err)
@@ -75,12 +78,12 @@ func TestGetAttendanceByCourse_Error(t *testing.T) {
 	courseID := 2
 
 	// Simulate a database error
-	mock.ExpectQuery(`SELECT  id, student_id, course_id, college_id, date, status, scanned_at, lecture_id FROM attendance WHERE`).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, student_id, course_id, college_id, date, status, scanned_at, lecture_id FROM attendance WHERE college_id = $1 AND course_id = $2 ORDER BY date DESC, student_id ASC LIMIT 10 OFFSET 0`)).
 		WithArgs(collegeID, courseID).
 		WillReturnError(errors.New("database error"))
 
 	// Call the method
-	attendances, err := repo.GetAttendanceByCourse(ctx, collegeID, courseID)
+	attendances, err := repo.GetAttendanceByCourse(ctx, collegeID, courseID, 10, 0)
 
 	// Assert error occurred
 	assert.Error(t, err)
@@ -91,6 +94,8 @@ func TestGetAttendanceByCourse_Error(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+var manualAudit = models.AuditInfo{ActorID: "faculty-identity", Source: models.AuditSourceManualUpdate}
+
 func TestMarkAttendance(t *testing.T) {
 	mock, _, repo, ctx := setupAttendanceTest(t)
 	defer mock.Close()
@@ -100,12 +105,24 @@ func TestMarkAttendance(t *testing.T) {
 	courseID := 2
 	lectureID := 201
 
-	mock.ExpectExec(`INSERT INTO attendance \(student_id,course_id,college_id,lecture_id,date,status,scanned_at\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7\) ON CONFLICT \(student_id, course_id, lecture_id, date, college_id\) DO UPDATE SET scanned_at = EXCLUDED.scanned_at, status = EXCLUDED.status`).
-		WithArgs(studentID, courseID, collegeID, lectureID, pgxmock.AnyArg(), "Present", pgxmock.AnyArg()). // 7 Args, matching the VALUES
+	mock.ExpectBegin()
+	// Not yet Present or Late, and no row to overwrite
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM attendance WHERE college_id = $1 AND course_id = $2 AND lecture_id = $3 AND status IN ($4,$5) AND student_id = $6`)).
+		WithArgs(collegeID, courseID, lectureID, "Present", "Late", studentID).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, status FROM attendance WHERE college_id = $1 AND course_id = $2 AND lecture_id = $3 AND student_id = $4 ORDER BY id ASC LIMIT 1 FOR UPDATE`)).
+		WithArgs(collegeID, courseID, lectureID, studentID).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "status"}))
+	mock.ExpectQuery(`INSERT INTO attendance \(student_id,course_id,college_id,lecture_id,date,status,scanned_at\) VALUES .* ON CONFLICT \(student_id, course_id, lecture_id, date, college_id\)`).
+		WithArgs(studentID, courseID, collegeID, lectureID, lectureID, "Present", pgxmock.AnyArg()). // The lecture ID is repeated to date the row
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(9))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO attendance_audit_log`)).
+		WithArgs(9, collegeID, studentID, courseID, lectureID, (*string)(nil), "Present", manualAudit.ActorID, manualAudit.Source, (*string)(nil), pgxmock.AnyArg()).
 		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
 
 	// Call the method
-	success, err := repo.MarkAttendance(ctx, collegeID, studentID, courseID, lectureID)
+	success, err := repo.MarkAttendance(ctx, collegeID, studentID, courseID, lectureID, "Present", manualAudit)
 
 	// Assert no error occurred and operation was successful
 	assert.NoError(t, err)
@@ -124,19 +141,21 @@ func TestMarkAttendance_Error(t *testing.T) {
 	courseID := 2
 	lectureID := 201
 
-	// Simulate a database error using the same refined regex pattern
-	mock.ExpectExec(`INSERT INTO attendance \(student_id,course_id,college_id,lecture_id,date,status,scanned_at\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7\) ON CONFLICT \(student_id, course_id, lecture_id, date, college_id\) DO UPDATE SET scanned_at = EXCLUDED.scanned_at, status = EXCLUDED.status`).
-		WithArgs(studentID, courseID, collegeID, lectureID, pgxmock.AnyArg(), "Present", pgxmock.AnyArg()). // 7 Args
+	// Simulate a database error
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM attendance WHERE`)).
+		WithArgs(collegeID, courseID, lectureID, "Present", "Late", studentID).
 		WillReturnError(errors.New("database error"))
+	mock.ExpectRollback()
 
 	// Call the method
-	success, err := repo.MarkAttendance(ctx, collegeID, studentID, courseID, lectureID)
+	success, err := repo.MarkAttendance(ctx, collegeID, studentID, courseID, lectureID, "Present", manualAudit)
 
 	// Assert error occurred and operation failed
 	assert.Error(t, err)
 	assert.False(t, success)
-	assert.Contains(t, err.Error(), "failed to execute query")
-	//
+	assert.Contains(t, err.Error(), "failed to execute lookup query")
+
 	// Ensure all expectations were met
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -151,14 +170,22 @@ func TestUpdateAttendance(t *testing.T) {
 	lectureID := 201
 	status := "Absent"
 
-	// Expect the query with specific arguments in the correct order
-	// Actual order seems to be: status, collegeID, courseID, lectureID, studentID
-	mock.ExpectExec(`UPDATE attendance SET status = \$1 WHERE college_id = \$2 AND course_id = \$3 AND lecture_id = \$4 AND student_id = \$5`). // Match the actual WHERE clause order if known
-																			WithArgs(status, collegeID, courseID, lectureID, studentID). // Correct argument order
-																			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, student_id, course_id, lecture_id, status FROM attendance WHERE college_id = $1 AND course_id = $2 AND lecture_id = $3 AND student_id = $4 FOR UPDATE`)).
+		WithArgs(collegeID, courseID, lectureID, studentID).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "student_id", "course_id", "lecture_id", "status"}).
+			AddRow(5, studentID, courseID, lectureID, "Present"))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE attendance SET status = $1, updated_at = $2 WHERE id IN ($3)`)).
+		WithArgs(status, pgxmock.AnyArg(), 5).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	present := "Present"
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO attendance_audit_log`)).
+		WithArgs(5, collegeID, studentID, courseID, lectureID, &present, status, manualAudit.ActorID, manualAudit.Source, (*string)(nil), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
 
 	// Call the method
-	err := repo.UpdateAttendance(ctx, collegeID, studentID, courseID, lectureID, status)
+	err := repo.UpdateAttendance(ctx, collegeID, studentID, courseID, lectureID, status, manualAudit)
 
 	// Assert no error occurred
 	assert.NoError(t, err)
@@ -177,19 +204,19 @@ func TestUpdateAttendance_NoRowsAffected(t *testing.T) {
 	lectureID := 201
 	status := "Absent"
 
-	// Simulate no rows affected with correct argument order
-	// Actual order seems to be: status, collegeID, courseID, lectureID, studentID
-	mock.ExpectExec(`UPDATE attendance SET status = \$1 WHERE college_id = \$2 AND course_id = \$3 AND lecture_id = \$4 AND student_id = \$5`). // Match the actual WHERE clause order if known
-																			WithArgs(status, collegeID, courseID, lectureID, studentID). // Correct argument order
-																			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	// Simulate no attendance to update
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, student_id, course_id, lecture_id, status FROM attendance WHERE college_id = $1 AND course_id = $2 AND lecture_id = $3 AND student_id = $4 FOR UPDATE`)).
+		WithArgs(collegeID, courseID, lectureID, studentID).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "student_id", "course_id", "lecture_id", "status"}))
+	mock.ExpectCommit()
 
 	// Call the method
-	err := repo.UpdateAttendance(ctx, collegeID, studentID, courseID, lectureID, status)
+	err := repo.UpdateAttendance(ctx, collegeID, studentID, courseID, lectureID, status, manualAudit)
 
 	// Assert error occurred
 	assert.Error(t, err)
-	// Update the expected error message if the underlying function changed it
-	assert.Contains(t, err.Error(), "did not update attendance") // Or the actual error message from repo
+	assert.Contains(t, err.Error(), "did not update attendance")
 
 	// Ensure all expectations were met
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -211,12 +238,12 @@ func TestGetAttendanceStudentInCourse(t *testing.T) {
 		AddRow(2, studentID, courseID, collegeID, time.Now().Add(24*time.Hour), "Absent", time.Now().Add(24*time.Hour), 202)
 
 	// Expect the query matching the actual WHERE clause order and argument order
-	mock.ExpectQuery(`SELECT id, student_id, course_id, college_id, date, status, scanned_at, lecture_id FROM attendance WHERE college_id = \$1 AND course_id = \$2 AND student_id = \$3 ORDER BY scanned_at ASC`). // Correct WHERE clause order
-																											WithArgs(collegeID, courseID, studentID). // Correct argument order
-																											WillReturnRows(rows)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, student_id, course_id, college_id, date, status, scanned_at, lecture_id FROM attendance WHERE college_id = $1 AND course_id = $2 AND student_id = $3 ORDER BY date DESC, scanned_at DESC LIMIT 10 OFFSET 0`)).
+		WithArgs(collegeID, courseID, studentID).
+		WillReturnRows(rows)
 
 	// Call the method
-	attendances, err := repo.GetAttendanceStudentInCourse(ctx, collegeID, studentID, courseID)
+	attendances, err := repo.GetAttendanceStudentInCourse(ctx, collegeID, studentID, courseID, 10, 0)
 
 	// Assert no error occurred
 	assert.NoError(t, err)
@@ -244,13 +271,13 @@ func TestGetAttendanceStudent(t *testing.T) {
 		AddRow(1, studentID, 2, collegeID, time.Now(), "Present", time.Now(), 201).
 		AddRow(2, studentID, 3, collegeID, time.Now(), "Absent", time.Now(), 301)
 
-	// Expect the query without 'id' and with ordering
-	mock.ExpectQuery(`SELECT id, student_id, course_id, college_id, date, status, scanned_at, lecture_id FROM attendance WHERE college_id = \$1 AND student_id = \$2 ORDER BY date ASC, course_id ASC, scanned_at ASC`).
+	// Expect the query with ordering
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, student_id, course_id, college_id, date, status, scanned_at, lecture_id FROM attendance WHERE college_id = $1 AND student_id = $2 ORDER BY date DESC, course_id ASC, scanned_at DESC LIMIT 10 OFFSET 0`)).
 		WithArgs(collegeID, studentID).
 		WillReturnRows(rows)
 
 	// Call the method
-	attendances, err := repo.GetAttendanceStudent(ctx, collegeID, studentID)
+	attendances, err := repo.GetAttendanceStudent(ctx, collegeID, studentID, 10, 0)
 
 	// Assert no error occurred
 	assert.NoError(t, err)
@@ -278,12 +305,12 @@ func TestGetAttendanceByLecture(t *testing.T) {
 		AddRow(2, 102, courseID, collegeID, time.Now(), "Absent", time.Now(), lectureID)
 
 	// Expect the query matching the actual WHERE clause order and argument order
-	mock.ExpectQuery(`SELECT id,  student_id, course_id, college_id, date, status, scanned_at, lecture_id FROM attendance WHERE college_id = \$1 AND course_id = \$2 AND lecture_id = \$3 ORDER BY student_id ASC, scanned_at ASC`). // Correct WHERE clause order
-																														WithArgs(collegeID, courseID, lectureID). // Correct argument order
-																														WillReturnRows(rows)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, student_id, course_id, college_id, date, status, scanned_at, lecture_id FROM attendance WHERE college_id = $1 AND course_id = $2 AND lecture_id = $3 ORDER BY student_id ASC, scanned_at ASC LIMIT 10 OFFSET 0`)).
+		WithArgs(collegeID, courseID, lectureID).
+		WillReturnRows(rows)
 
 	// Call the method
-	attendances, err := repo.GetAttendanceByLecture(ctx, collegeID, lectureID, courseID)
+	attendances, err := repo.GetAttendanceByLecture(ctx, collegeID, lectureID, courseID, 10, 0)
 
 	// Assert no error occurred
 	assert.NoError(t, err)
@@ -297,6 +324,13 @@ func TestGetAttendanceByLecture(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+// expectFreezeLookup expects the Frozen update to lock the student's rows
+// that are not already Frozen.
+func expectFreezeLookup(mock pgxmock.PgxPoolIface, collegeID, studentID int) *pgxmock.ExpectedQuery {
+	return mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, student_id, course_id, lecture_id, status FROM attendance WHERE college_id = $1 AND (student_id = $2 AND status <> $3) FOR UPDATE`)).
+		WithArgs(collegeID, studentID, "Frozen")
+}
+
 func TestFreezeAttendance(t *testing.T) {
 	mock, _, repo, ctx := setupAttendanceTest(t)
 	defer mock.Close()
@@ -304,13 +338,20 @@ func TestFreezeAttendance(t *testing.T) {
 	collegeID := 1
 	studentID := 101
 
-	// Expect the query with specific arguments
-	mock.ExpectExec(`UPDATE attendance SET status = \$1 WHERE`).
-		WithArgs("Frozen", collegeID, studentID).
+	mock.ExpectBegin()
+	expectFreezeLookup(mock, collegeID, studentID).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "student_id", "course_id", "lecture_id", "status"}).
+			AddRow(5, studentID, 2, 201, "Present").
+			AddRow(6, studentID, 3, 301, "Absent"))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE attendance SET status = $1, updated_at = $2 WHERE id IN ($3,$4)`)).
+		WithArgs("Frozen", pgxmock.AnyArg(), 5, 6).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO attendance_audit_log`)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	mock.ExpectCommit()
 
 	// Call the method
-	err := repo.FreezeAttendance(ctx, collegeID, studentID)
+	err := repo.FreezeAttendance(ctx, collegeID, studentID, freezeAudit)
 
 	// Assert no error occurred
 	assert.NoError(t, err)
@@ -326,13 +367,14 @@ func TestFreezeAttendance_NoRowsAffected(t *testing.T) {
 	collegeID := 1
 	studentID := 101
 
-	// Simulate no rows affected
-	mock.ExpectExec(`UPDATE attendance SET status = \$1 WHERE`).
-		WithArgs("Frozen", collegeID, studentID).
-		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	// Simulate nothing left to freeze
+	mock.ExpectBegin()
+	expectFreezeLookup(mock, collegeID, studentID).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "student_id", "course_id", "lecture_id", "status"}))
+	mock.ExpectCommit()
 
 	// Call the method
-	err := repo.FreezeAttendance(ctx, collegeID, studentID)
+	err := repo.FreezeAttendance(ctx, collegeID, studentID, freezeAudit)
 
 	// Assert error occurred
 	assert.Error(t, err)
@@ -350,16 +392,17 @@ func TestFreezeAttendance_Error(t *testing.T) {
 	studentID := 101
 
 	// Simulate a database error
-	mock.ExpectExec(`UPDATE attendance SET status = \$1 WHERE`).
-		WithArgs("Frozen", collegeID, studentID).
+	mock.ExpectBegin()
+	expectFreezeLookup(mock, collegeID, studentID).
 		WillReturnError(errors.New("database error"))
+	mock.ExpectRollback()
 
 	// Call the method
-	err := repo.FreezeAttendance(ctx, collegeID, studentID)
+	err := repo.FreezeAttendance(ctx, collegeID, studentID, freezeAudit)
 
 	// Assert error occurred
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "failed to execute query")
+	assert.Contains(t, err.Error(), "failed to execute lookup query")
 
 	// Ensure all expectations were met
 	assert.NoError(t, mock.ExpectationsWereMet())
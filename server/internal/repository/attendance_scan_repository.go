@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+)
+
+// AttendanceScanRepository stores the outcome of every QR scan.
+type AttendanceScanRepository interface {
+	CreateScan(ctx context.Context, scan *models.AttendanceScan) error
+	// FindScansByLecture lists scans for a lecture, newest first. When flaggedOnly
+	// is set only rejected scans are returned.
+	FindScansByLecture(ctx context.Context, collegeID, courseID, lectureID int, flaggedOnly bool, limit, offset uint64) ([]*models.AttendanceScan, error)
+}
+
+const attendanceScanTable = "attendance_scans"
+
+type attendanceScanRepository struct {
+	DB *DB
+}
+
+func NewAttendanceScanRepository(db *DB) AttendanceScanRepository {
+	return &attendanceScanRepository{DB: db}
+}
+
+func (r *attendanceScanRepository) CreateScan(ctx context.Context, scan *models.AttendanceScan) error {
+	if scan.ScannedAt.IsZero() {
+		scan.ScannedAt = time.Now()
+	}
+	if scan.Reasons == nil {
+		scan.Reasons = []string{}
+	}
+
+	query := r.DB.SQ.Insert(attendanceScanTable).
+		Columns("college_id", "student_id", "course_id", "lecture_id", "qr_nonce", "latitude", "longitude", "distance_meters", "device_fingerprint", "accepted", "reasons", "scanned_at").
+		Values(scan.CollegeID, scan.StudentID, scan.CourseID, scan.LectureID, scan.QRNonce, scan.Latitude, scan.Longitude, scan.DistanceMeters, scan.DeviceFingerprint, scan.Accepted, scan.Reasons, scan.ScannedAt).
+		Suffix("RETURNING id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("CreateScan: failed to build query: %w", err)
+	}
+
+	err = r.DB.Pool.QueryRow(ctx, sql, args...).Scan(&scan.ID)
+	if err != nil {
+		return fmt.Errorf("CreateScan: failed to execute query or scan ID: %w", err)
+	}
+	return nil
+}
+
+func (r *attendanceScanRepository) FindScansByLecture(ctx context.Context, collegeID, courseID, lectureID int, flaggedOnly bool, limit, offset uint64) ([]*models.AttendanceScan, error) {
+	where := squirrel.Eq{
+		"college_id": collegeID,
+		"course_id":  courseID,
+		"lecture_id": lectureID,
+	}
+	if flaggedOnly {
+		where["accepted"] = false
+	}
+
+	query := r.DB.SQ.Select("id", "college_id", "student_id", "course_id", "lecture_id", "qr_nonce", "latitude", "longitude", "distance_meters", "device_fingerprint", "accepted", "reasons", "scanned_at").
+		From(attendanceScanTable).
+		Where(where).
+		OrderBy("scanned_at DESC").
+		Limit(limit).
+		Offset(offset)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindScansByLecture: failed to build query: %w", err)
+	}
+
+	scans := []*models.AttendanceScan{}
+	err = pgxscan.Select(ctx, r.DB.Pool, &scans, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("FindScansByLecture: failed to execute query or scan: %w", err)
+	}
+	return scans, nil
+}
@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+)
+
+const calendarFeedTable = "calendar_feeds"
+
+var calendarFeedQueryFields = []string{
+	"id", "college_id", "identity_id", "role", "student_id", "token_hash", "created_at", "last_used_at", "revoked_at",
+}
+
+var ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+
+// CalendarFeedRepository stores iCalendar subscriptions and reads what goes
+// into them.
+type CalendarFeedRepository interface {
+	// CreateFeed stores the feed, revoking the user's previous one.
+	CreateFeed(ctx context.Context, feed *models.CalendarFeed) error
+	// GetActiveFeed returns the user's feed unless it has been revoked.
+	GetActiveFeed(ctx context.Context, collegeID int, identityID string) (*models.CalendarFeed, error)
+	RevokeFeed(ctx context.Context, collegeID int, identityID string) error
+	// UseFeed returns the live feed with this token hash and records that it
+	// was read.
+	UseFeed(ctx context.Context, tokenHash string) (*models.CalendarFeed, error)
+
+	// FindFeedCourses returns the courses a feed covers: a student's active
+	// enrollments, or the courses a faculty member teaches or is timetabled
+	// for. Admins' feeds cover no course.
+	FindFeedCourses(ctx context.Context, feed *models.CalendarFeed) ([]*models.Course, error)
+	// The following return the courses' items falling in [from, to).
+	FindFeedLectures(ctx context.Context, collegeID int, courseIDs []int, from, to time.Time) ([]*models.Lecture, error)
+	FindFeedAssignments(ctx context.Context, collegeID int, courseIDs []int, from, to time.Time) ([]*models.Assignment, error)
+	FindFeedQuizzes(ctx context.Context, collegeID int, courseIDs []int, from, to time.Time) ([]*models.Quiz, error)
+	// FindFeedCalendarBlocks returns college-wide events and the courses'
+	// events overlapping [from, to].
+	FindFeedCalendarBlocks(ctx context.Context, collegeID int, courseIDs []int, from, to time.Time) ([]*models.CalendarBlock, error)
+	// FindFeedTimetableBlocks returns the courses' blocks in the term that
+	// have no lectures generated from them yet.
+	FindFeedTimetableBlocks(ctx context.Context, collegeID int, termID int, courseIDs []int) ([]*models.TimeTableBlock, error)
+}
+
+type calendarFeedRepository struct {
+	DB *DB
+}
+
+func NewCalendarFeedRepository(db *DB) CalendarFeedRepository {
+	return &calendarFeedRepository{DB: db}
+}
+
+func (r *calendarFeedRepository) CreateFeed(ctx context.Context, feed *models.CalendarFeed) error {
+	feed.CreatedAt = time.Now()
+
+	revoke, revokeArgs, err := r.DB.SQ.Update(calendarFeedTable).
+		Set("revoked_at", feed.CreatedAt).
+		Where(squirrel.Eq{"college_id": feed.CollegeID, "identity_id": feed.IdentityID, "revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CreateFeed: failed to build revoke query: %w", err)
+	}
+	insert, insertArgs, err := r.DB.SQ.Insert(calendarFeedTable).
+		Columns("college_id", "identity_id", "role", "student_id", "token_hash", "created_at").
+		Values(feed.CollegeID, feed.IdentityID, feed.Role, feed.StudentID, feed.TokenHash, feed.CreatedAt).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CreateFeed: failed to build query: %w", err)
+	}
+
+	return r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, revoke, revokeArgs...); err != nil {
+			return fmt.Errorf("CreateFeed: failed to revoke previous feed: %w", err)
+		}
+		if err := tx.QueryRow(ctx, insert, insertArgs...).Scan(&feed.ID); err != nil {
+			return fmt.Errorf("CreateFeed: failed to execute query or scan ID: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *calendarFeedRepository) GetActiveFeed(ctx context.Context, collegeID int, identityID string) (*models.CalendarFeed, error) {
+	sql, args, err := r.DB.SQ.Select(calendarFeedQueryFields...).
+		From(calendarFeedTable).
+		Where(squirrel.Eq{"college_id": collegeID, "identity_id": identityID, "revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("GetActiveFeed: failed to build query: %w", err)
+	}
+
+	feed := &models.CalendarFeed{}
+	if err := pgxscan.Get(ctx, r.DB.Pool, feed, sql, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("GetActiveFeed: %w", ErrCalendarFeedNotFound)
+		}
+		return nil, fmt.Errorf("GetActiveFeed: failed to execute query or scan: %w", err)
+	}
+	return feed, nil
+}
+
+func (r *calendarFeedRepository) RevokeFeed(ctx context.Context, collegeID int, identityID string) error {
+	sql, args, err := r.DB.SQ.Update(calendarFeedTable).
+		Set("revoked_at", time.Now()).
+		Where(squirrel.Eq{"college_id": collegeID, "identity_id": identityID, "revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("RevokeFeed: failed to build query: %w", err)
+	}
+
+	commandTag, err := r.DB.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("RevokeFeed: failed to execute query: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("RevokeFeed: %w", ErrCalendarFeedNotFound)
+	}
+	return nil
+}
+
+func (r *calendarFeedRepository) UseFeed(ctx context.Context, tokenHash string) (*models.CalendarFeed, error) {
+	sql, args, err := r.DB.SQ.Update(calendarFeedTable).
+		Set("last_used_at", time.Now()).
+		Where(squirrel.Eq{"token_hash": tokenHash, "revoked_at": nil}).
+		Suffix("RETURNING " + strings.Join(calendarFeedQueryFields, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("UseFeed: failed to build query: %w", err)
+	}
+
+	feed := &models.CalendarFeed{}
+	if err := pgxscan.Get(ctx, r.DB.Pool, feed, sql, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("UseFeed: %w", ErrCalendarFeedNotFound)
+		}
+		return nil, fmt.Errorf("UseFeed: failed to execute query or scan: %w", err)
+	}
+	return feed, nil
+}
+
+func (r *calendarFeedRepository) FindFeedCourses(ctx context.Context, feed *models.CalendarFeed) ([]*models.Course, error) {
+	query := r.DB.SQ.Select("c.id", "c.name", "c.description", "c.credits", "c.instructor_id", "c.capacity", "c.college_id", "c.created_at", "c.updated_at").
+		From(courseTable + " c").
+		Where(squirrel.Eq{"c.college_id": feed.CollegeID}).
+		OrderBy("c.name ASC")
+
+	switch {
+	case feed.StudentID != nil:
+		query = query.Where(squirrel.Expr(
+			"EXISTS (SELECT 1 FROM "+enrollmentTable+" e WHERE e.course_id = c.id AND e.college_id = c.college_id AND e.student_id = ? AND LOWER(e.status) = ?)",
+			*feed.StudentID, models.Active,
+		))
+	case feed.Role == "faculty":
+		query = query.Where(squirrel.Or{
+			squirrel.Expr("c.instructor_id IN (SELECT u.id FROM users u WHERE u.kratos_identity_id = ?)", feed.IdentityID),
+			squirrel.Expr("EXISTS (SELECT 1 FROM "+timeTableBlockTable+" b WHERE b.course_id = c.id AND b.college_id = c.college_id AND b.faculty_id = ?)", feed.IdentityID),
+		})
+	default:
+		return []*models.Course{}, nil
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindFeedCourses: failed to build query: %w", err)
+	}
+	var courses []*models.Course
+	if err := pgxscan.Select(ctx, r.DB.Pool, &courses, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindFeedCourses: failed to execute query or scan: %w", err)
+	}
+	return courses, nil
+}
+
+func (r *calendarFeedRepository) FindFeedLectures(ctx context.Context, collegeID int, courseIDs []int, from, to time.Time) ([]*models.Lecture, error) {
+	var lectures []*models.Lecture
+	if len(courseIDs) == 0 {
+		return lectures, nil
+	}
+	sql, args, err := r.DB.SQ.Select(lectureQueryFields...).
+		From(lectureTable).
+		Where(squirrel.Eq{"college_id": collegeID, "course_id": courseIDs}).
+		Where(squirrel.GtOrEq{"start_time": from}).
+		Where(squirrel.Lt{"start_time": to}).
+		OrderBy("start_time ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindFeedLectures: failed to build query: %w", err)
+	}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &lectures, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindFeedLectures: failed to execute query or scan: %w", err)
+	}
+	return lectures, nil
+}
+
+func (r *calendarFeedRepository) FindFeedAssignments(ctx context.Context, collegeID int, courseIDs []int, from, to time.Time) ([]*models.Assignment, error) {
+	var assignments []*models.Assignment
+	if len(courseIDs) == 0 {
+		return assignments, nil
+	}
+	sql, args, err := r.DB.SQ.Select("id", "course_id", "college_id", "title", "description", "due_date", "max_points", "created_at", "updated_at").
+		From("assignments").
+		Where(squirrel.Eq{"college_id": collegeID, "course_id": courseIDs}).
+		Where(squirrel.GtOrEq{"due_date": from}).
+		Where(squirrel.Lt{"due_date": to}).
+		OrderBy("due_date ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindFeedAssignments: failed to build query: %w", err)
+	}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &assignments, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindFeedAssignments: failed to execute query or scan: %w", err)
+	}
+	return assignments, nil
+}
+
+func (r *calendarFeedRepository) FindFeedQuizzes(ctx context.Context, collegeID int, courseIDs []int, from, to time.Time) ([]*models.Quiz, error) {
+	var quizzes []*models.Quiz
+	if len(courseIDs) == 0 {
+		return quizzes, nil
+	}
+	sql, args, err := r.DB.SQ.Select("id", "college_id", "course_id", "title", "description", "time_limit_minutes", "due_date", "created_at", "updated_at").
+		From(quizTable).
+		Where(squirrel.Eq{"college_id": collegeID, "course_id": courseIDs}).
+		Where(squirrel.GtOrEq{"due_date": from}).
+		Where(squirrel.Lt{"due_date": to}).
+		OrderBy("due_date ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindFeedQuizzes: failed to build query: %w", err)
+	}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &quizzes, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindFeedQuizzes: failed to execute query or scan: %w", err)
+	}
+	return quizzes, nil
+}
+
+func (r *calendarFeedRepository) FindFeedCalendarBlocks(ctx context.Context, collegeID int, courseIDs []int, from, to time.Time) ([]*models.CalendarBlock, error) {
+	scope := squirrel.Or{squirrel.Eq{"course_id": nil}}
+	if len(courseIDs) > 0 {
+		scope = append(scope, squirrel.Eq{"course_id": courseIDs})
+	}
+	sql, args, err := r.DB.SQ.Select(calendarBlockQueryFields...).
+		From(calendarBlockTable).
+		Where(squirrel.Eq{"college_id": collegeID}).
+		Where(scope).
+		Where(squirrel.GtOrEq{"end_date": from}).
+		Where(squirrel.LtOrEq{"date": to}).
+		OrderBy("date ASC", "id ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindFeedCalendarBlocks: failed to build query: %w", err)
+	}
+	var blocks []*models.CalendarBlock
+	if err := pgxscan.Select(ctx, r.DB.Pool, &blocks, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindFeedCalendarBlocks: failed to execute query or scan: %w", err)
+	}
+	return blocks, nil
+}
+
+func (r *calendarFeedRepository) FindFeedTimetableBlocks(ctx context.Context, collegeID int, termID int, courseIDs []int) ([]*models.TimeTableBlock, error) {
+	var blocks []*models.TimeTableBlock
+	if len(courseIDs) == 0 {
+		return blocks, nil
+	}
+	fields := make([]string, len(timeTableBlockQueryFields))
+	for i, f := range timeTableBlockQueryFields {
+		fields[i] = "b." + f
+	}
+	sql, args, err := r.DB.SQ.Select(fields...).
+		From(timeTableBlockTable+" b").
+		Where(squirrel.Eq{"b.college_id": collegeID, "b.term_id": termID, "b.course_id": courseIDs}).
+		Where("NOT EXISTS (SELECT 1 FROM "+lectureTable+" l WHERE l.timetable_block_id = b.id)").
+		OrderBy("b.day_of_week ASC", "b.start_time ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindFeedTimetableBlocks: failed to build query: %w", err)
+	}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &blocks, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindFeedTimetableBlocks: failed to execute query or scan: %w", err)
+	}
+	return blocks, nil
+}
@@ -16,10 +16,12 @@ import (
 const calendarBlockTable = "calendar_blocks"
 
 var calendarBlockQueryFields = []string{
-	"id", "college_id", "title", "description", "event_type", "date",
-	"created_at", "updated_at",
+	"id", "college_id", "course_id", "title", "description", "event_type", "date", "end_date",
+	"start_time", "end_time", "external_uid", "created_at", "updated_at",
 }
 
+var ErrCalendarBlockNotFound = errors.New("calendar event not found")
+
 type CalendarRepository interface {
 	CreateCalendarBlock(ctx context.Context, block *models.CalendarBlock) error
 	GetCalendarBlockByID(ctx context.Context, blockID int, collegeID int) (*models.CalendarBlock, error)
@@ -27,6 +29,9 @@ type CalendarRepository interface {
 	DeleteCalendarBlock(ctx context.Context, blockID int, collegeID int) error
 	GetCalendarBlocks(ctx context.Context, filter models.CalendarBlockFilter) ([]*models.CalendarBlock, error)
 	CountCalendarBlocks(ctx context.Context, filter models.CalendarBlockFilter) (int, error)
+	// GetCalendarBlockByExternalUID finds the event imported from the
+	// iCalendar event with this UID.
+	GetCalendarBlockByExternalUID(ctx context.Context, collegeID int, uid string) (*models.CalendarBlock, error)
 }
 
 type calendarRepository struct {
@@ -43,8 +48,8 @@ func (r *calendarRepository) CreateCalendarBlock(ctx context.Context, block *mod
 	block.UpdatedAt = now
 
 	query := r.DB.SQ.Insert(calendarBlockTable).
-		Columns("college_id", "title", "description", "event_type", "date", "created_at", "updated_at").
-		Values(block.CollegeID, block.Title, block.Description, block.EventType, block.Date, block.CreatedAt, block.UpdatedAt).
+		Columns("college_id", "course_id", "title", "description", "event_type", "date", "end_date", "start_time", "end_time", "external_uid", "created_at", "updated_at").
+		Values(block.CollegeID, block.CourseID, block.Title, block.Description, block.EventType, block.Date, block.EndDate, block.StartTime, block.EndTime, block.ExternalUID, block.CreatedAt, block.UpdatedAt).
 		Suffix("RETURNING id")
 
 	sql, args, err := query.ToSql()
@@ -73,21 +78,47 @@ func (r *calendarRepository) GetCalendarBlockByID(ctx context.Context, blockID i
 	err = pgxscan.Get(ctx, r.DB.Pool, block, sql, args...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("GetCalendarBlockByID: block with ID %d for college ID %d not found: %w", blockID, collegeID, err)
+			return nil, fmt.Errorf("GetCalendarBlockByID: block with ID %d for college ID %d: %w", blockID, collegeID, ErrCalendarBlockNotFound)
 		}
 		return nil, fmt.Errorf("GetCalendarBlockByID: failed to execute query or scan: %w", err)
 	}
 	return block, nil
 }
 
+func (r *calendarRepository) GetCalendarBlockByExternalUID(ctx context.Context, collegeID int, uid string) (*models.CalendarBlock, error) {
+	block := &models.CalendarBlock{}
+	query := r.DB.SQ.Select(calendarBlockQueryFields...).
+		From(calendarBlockTable).
+		Where(squirrel.Eq{"college_id": collegeID, "external_uid": uid})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("GetCalendarBlockByExternalUID: failed to build query: %w", err)
+	}
+
+	err = pgxscan.Get(ctx, r.DB.Pool, block, sql, args...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("GetCalendarBlockByExternalUID: block with UID %q for college ID %d: %w", uid, collegeID, ErrCalendarBlockNotFound)
+		}
+		return nil, fmt.Errorf("GetCalendarBlockByExternalUID: failed to execute query or scan: %w", err)
+	}
+	return block, nil
+}
+
 func (r *calendarRepository) UpdateCalendarBlock(ctx context.Context, block *models.CalendarBlock) error {
 	block.UpdatedAt = time.Now()
 
 	query := r.DB.SQ.Update(calendarBlockTable).
+		Set("course_id", block.CourseID).
 		Set("title", block.Title).
 		Set("description", block.Description).
 		Set("event_type", block.EventType).
 		Set("date", block.Date).
+		Set("end_date", block.EndDate).
+		Set("start_time", block.StartTime).
+		Set("end_time", block.EndTime).
+		Set("external_uid", block.ExternalUID).
 		Set("updated_at", block.UpdatedAt).
 		Where(squirrel.Eq{"id": block.ID, "college_id": block.CollegeID})
 
@@ -102,7 +133,7 @@ func (r *calendarRepository) UpdateCalendarBlock(ctx context.Context, block *mod
 	}
 
 	if commandTag.RowsAffected() == 0 {
-		return fmt.Errorf("UpdateCalendarBlock: no block found with ID %d for college ID %d, or no changes made", block.ID, block.CollegeID)
+		return fmt.Errorf("UpdateCalendarBlock: block with ID %d for college ID %d: %w", block.ID, block.CollegeID, ErrCalendarBlockNotFound)
 	}
 	return nil
 }
@@ -122,7 +153,7 @@ func (r *calendarRepository) DeleteCalendarBlock(ctx context.Context, blockID in
 	}
 
 	if commandTag.RowsAffected() == 0 {
-		return fmt.Errorf("DeleteCalendarBlock: no block found with ID %d for college ID %d", blockID, collegeID)
+		return fmt.Errorf("DeleteCalendarBlock: block with ID %d for college ID %d: %w", blockID, collegeID, ErrCalendarBlockNotFound)
 	}
 	return nil
 }
@@ -136,11 +167,15 @@ func (r *calendarRepository) applyCalendarBlockFilter(query squirrel.SelectBuild
 		query = query.Where(squirrel.Eq{"college_id": *filter.CollegeID})
 	}
 
+	if filter.CourseID != nil {
+		query = query.Where(squirrel.Or{squirrel.Eq{"course_id": *filter.CourseID}, squirrel.Eq{"course_id": nil}})
+	}
 	if filter.EventType != nil {
 		query = query.Where(squirrel.Eq{"event_type": *filter.EventType})
 	}
+	// Events overlapping the range, not only those starting in it
 	if filter.StartDate != nil {
-		query = query.Where(squirrel.GtOrEq{"date": *filter.StartDate})
+		query = query.Where(squirrel.GtOrEq{"end_date": *filter.StartDate})
 	}
 	if filter.EndDate != nil {
 		query = query.Where(squirrel.LtOrEq{"date": *filter.EndDate})
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+)
+
+var (
+	ErrPrerequisiteNotFound = errors.New("prerequisite not found")
+	ErrPrerequisiteCycle    = errors.New("prerequisite would create a cycle")
+)
+
+type CoursePrerequisiteRepository interface {
+	// AddPrerequisite adds or updates an edge, rejecting one that would make a
+	// course (indirectly) its own prerequisite.
+	AddPrerequisite(ctx context.Context, prerequisite *models.CoursePrerequisite) error
+	RemovePrerequisite(ctx context.Context, collegeID int, courseID int, prerequisiteID int) error
+	FindPrerequisites(ctx context.Context, collegeID int, courseID int) ([]*models.CoursePrerequisite, error)
+
+	FindEnrollmentOverrides(ctx context.Context, collegeID int, courseID int, limit, offset uint64) ([]*models.EnrollmentOverride, error)
+}
+
+const (
+	coursePrerequisiteTable = "course_prerequisites"
+	enrollmentOverrideTable = "enrollment_overrides"
+
+	// prerequisiteLockClass namespaces the per-college advisory lock taken
+	// while the prerequisite graph changes.
+	prerequisiteLockClass = 0x70726571
+)
+
+type coursePrerequisiteRepository struct {
+	DB *DB
+}
+
+func NewCoursePrerequisiteRepository(db *DB) CoursePrerequisiteRepository {
+	return &coursePrerequisiteRepository{DB: db}
+}
+
+func (r *coursePrerequisiteRepository) AddPrerequisite(ctx context.Context, p *models.CoursePrerequisite) error {
+	if p.CourseID == p.PrerequisiteID {
+		return ErrPrerequisiteCycle
+	}
+	p.CreatedAt = time.Now()
+
+	err := r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		// Two concurrent additions could each pass the cycle check and
+		// together close a cycle, so changes to a college's graph are serialised.
+		if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1, $2)", prerequisiteLockClass, p.CollegeID); err != nil {
+			return fmt.Errorf("failed to lock prerequisite graph: %w", err)
+		}
+
+		sql, args, err := r.DB.SQ.Select("COUNT(*)").
+			From(courseTable).
+			Where(squirrel.Eq{"college_id": p.CollegeID, "id": []int{p.CourseID, p.PrerequisiteID}}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("failed to build course check: %w", err)
+		}
+		var found int
+		if err := tx.QueryRow(ctx, sql, args...).Scan(&found); err != nil {
+			return fmt.Errorf("failed to check courses: %w", err)
+		}
+		if found != 2 {
+			return ErrCourseNotFound
+		}
+
+		// The new edge closes a cycle if the prerequisite already requires
+		// the course, directly or through other courses.
+		var cycle bool
+		err = tx.QueryRow(ctx, `
+			WITH RECURSIVE required(id) AS (
+			    SELECT prerequisite_id FROM `+coursePrerequisiteTable+` WHERE course_id = $1
+			    UNION
+			    SELECT cp.prerequisite_id FROM `+coursePrerequisiteTable+` cp JOIN required r ON cp.course_id = r.id
+			)
+			SELECT EXISTS (SELECT 1 FROM required WHERE id = $2)`,
+			p.PrerequisiteID, p.CourseID).Scan(&cycle)
+		if err != nil {
+			return fmt.Errorf("failed to check for cycles: %w", err)
+		}
+		if cycle {
+			return ErrPrerequisiteCycle
+		}
+
+		sql, args, err = r.DB.SQ.Insert(coursePrerequisiteTable).
+			Columns("course_id", "prerequisite_id", "college_id", "min_percentage", "created_at").
+			Values(p.CourseID, p.PrerequisiteID, p.CollegeID, p.MinPercentage, p.CreatedAt).
+			Suffix("ON CONFLICT (course_id, prerequisite_id) DO UPDATE SET min_percentage = EXCLUDED.min_percentage").
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("failed to build insert: %w", err)
+		}
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return fmt.Errorf("failed to save prerequisite: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("AddPrerequisite: %w", err)
+	}
+	return nil
+}
+
+func (r *coursePrerequisiteRepository) RemovePrerequisite(ctx context.Context, collegeID int, courseID int, prerequisiteID int) error {
+	sql, args, err := r.DB.SQ.Delete(coursePrerequisiteTable).
+		Where(squirrel.Eq{"college_id": collegeID, "course_id": courseID, "prerequisite_id": prerequisiteID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("RemovePrerequisite: failed to build query: %w", err)
+	}
+	tag, err := r.DB.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("RemovePrerequisite: failed to execute query: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrPrerequisiteNotFound
+	}
+	return nil
+}
+
+func (r *coursePrerequisiteRepository) FindPrerequisites(ctx context.Context, collegeID int, courseID int) ([]*models.CoursePrerequisite, error) {
+	sql, args, err := r.DB.SQ.Select(
+		"cp.course_id", "cp.prerequisite_id", "c.name AS prerequisite_name", "cp.college_id",
+		"cp.min_percentage::float8 AS min_percentage", "cp.created_at",
+	).
+		From(coursePrerequisiteTable + " cp").
+		Join(courseTable + " c ON c.id = cp.prerequisite_id").
+		Where(squirrel.Eq{"cp.college_id": collegeID, "cp.course_id": courseID}).
+		OrderBy("c.name ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindPrerequisites: failed to build query: %w", err)
+	}
+
+	prerequisites := []*models.CoursePrerequisite{}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &prerequisites, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindPrerequisites: failed to execute query or scan: %w", err)
+	}
+	return prerequisites, nil
+}
+
+func (r *coursePrerequisiteRepository) FindEnrollmentOverrides(ctx context.Context, collegeID int, courseID int, limit, offset uint64) ([]*models.EnrollmentOverride, error) {
+	sql, args, err := r.DB.SQ.Select("id", "college_id", "course_id", "student_id", "overridden_by", "reason", "unmet", "created_at").
+		From(enrollmentOverrideTable).
+		Where(squirrel.Eq{"college_id": collegeID, "course_id": courseID}).
+		OrderBy("created_at DESC", "id DESC").
+		Limit(limit).
+		Offset(offset).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindEnrollmentOverrides: failed to build query: %w", err)
+	}
+
+	overrides := []*models.EnrollmentOverride{}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &overrides, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindEnrollmentOverrides: failed to execute query or scan: %w", err)
+	}
+	return overrides, nil
+}
+
+// insertEnrollmentOverride records, inside the enrolling transaction, that a
+// student was enrolled despite unmet prerequisites.
+func insertEnrollmentOverride(ctx context.Context, db *DB, q Querier, o *models.EnrollmentOverride) error {
+	unmet, err := json.Marshal(o.Unmet)
+	if err != nil {
+		return fmt.Errorf("failed to encode unmet requirements: %w", err)
+	}
+	o.CreatedAt = time.Now()
+
+	sql, args, err := db.SQ.Insert(enrollmentOverrideTable).
+		Columns("college_id", "course_id", "student_id", "overridden_by", "reason", "unmet", "created_at").
+		Values(o.CollegeID, o.CourseID, o.StudentID, o.OverriddenBy, o.Reason, squirrel.Expr("?::jsonb", string(unmet)), o.CreatedAt).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build override insert: %w", err)
+	}
+	if err := q.QueryRow(ctx, sql, args...).Scan(&o.ID); err != nil {
+		return fmt.Errorf("failed to record enrollment override: %w", err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt" // Keep fmt for error wrapping
+	"strings"
 	"time"
 
 	"eduhub/server/internal/models"
@@ -13,6 +14,8 @@ import (
 	"github.com/jackc/pgx/v4"              // Use v4 for pgx.ErrNoRows
 )
 
+var ErrCourseNotFound = errors.New("course not found")
+
 type CourseRepository interface {
 	CreateCourse(ctx context.Context, course *models.Course) error
 	FindCourseByID(ctx context.Context, collegeID int, courseID int) (*models.Course, error) // Added collegeID
@@ -22,10 +25,24 @@ type CourseRepository interface {
 	// Find methods with pagination
 	FindAllCourses(ctx context.Context, collegeID int, limit, offset uint64) ([]*models.Course, error)
 	FindCoursesByInstructor(ctx context.Context, collegeID int, instructorID int, limit, offset uint64) ([]*models.Course, error)
+	// SearchCourses matches search against course names and descriptions, case-insensitively.
+	SearchCourses(ctx context.Context, collegeID int, search string, limit, offset uint64) ([]*models.Course, error)
 
 	// Count methods
 	CountCoursesByCollege(ctx context.Context, collegeID int) (int, error)
 	CountCoursesByInstructor(ctx context.Context, collegeID int, instructorID int) (int, error)
+	CountSearchCourses(ctx context.Context, collegeID int, search string) (int, error)
+
+	// IsCourseInstructor reports whether the Kratos identity instructs the course.
+	IsCourseInstructor(ctx context.Context, collegeID int, courseID int, identityID string) (bool, error)
+	// TeachesStudent reports whether the Kratos identity instructs a course
+	// the student is actively enrolled in.
+	TeachesStudent(ctx context.Context, collegeID int, identityID string, studentID int) (bool, error)
+	// TeachesAllCoursesOf reports whether the Kratos identity instructs every
+	// course the student is actively enrolled in.
+	TeachesAllCoursesOf(ctx context.Context, collegeID int, identityID string, studentID int) (bool, error)
+	// IsDepartmentHead reports whether the Kratos identity heads the course's department.
+	IsDepartmentHead(ctx context.Context, collegeID int, courseID int, identityID string) (bool, error)
 }
 
 type courseRepository struct {
@@ -38,7 +55,7 @@ func NewCourseRepository(db *DB) CourseRepository {
 	}
 }
 
-const courseTable = "course" // Define table name constant
+const courseTable = "courses" // Define table name constant
 
 func (c *courseRepository) CreateCourse(ctx context.Context, course *models.Course) error {
 	// Set timestamps
@@ -51,12 +68,14 @@ func (c *courseRepository) CreateCourse(ctx context.Context, course *models.Cour
 	}
 
 	query := c.DB.SQ.Insert(courseTable).
-		Columns("name", "description", "credits", "instructor_id", "college_id", "created_at", "updated_at"). // Added college_id, created_at, updated_at
+		Columns("name", "description", "credits", "instructor_id", "capacity", "department_id", "college_id", "created_at", "updated_at"). // Added college_id, created_at, updated_at
 		Values(
 			course.Name,
 			course.Description,
 			course.Credits,
 			course.InstructorID,
+			course.Capacity,
+			course.DepartmentID,
 			course.CollegeID, // Assuming CollegeID exists in models.Course
 			course.CreatedAt,
 			course.UpdatedAt,
@@ -77,7 +96,7 @@ func (c *courseRepository) CreateCourse(ctx context.Context, course *models.Cour
 
 func (c *courseRepository) FindCourseByID(ctx context.Context, collegeID int, courseID int) (*models.Course, error) {
 	query := c.DB.SQ.Select(
-		"id", "name", "description", "credits", "instructor_id", "college_id", "created_at", "updated_at", // Added college_id, timestamps
+		"id", "name", "description", "credits", "instructor_id", "capacity", "department_id", "college_id", "created_at", "updated_at", // Added college_id, timestamps
 	).
 		From(courseTable).
 		Where(squirrel.Eq{
@@ -98,7 +117,7 @@ func (c *courseRepository) FindCourseByID(ctx context.Context, collegeID int, co
 	if err != nil {
 		// It's better to check for specific errors like "no rows"
 		if errors.Is(err, pgx.ErrNoRows) { // Use errors.Is for checking pgx.ErrNoRows
-			return nil, fmt.Errorf("FindCourseByID: course with ID %d not found for college ID %d", courseID, collegeID) // Or a custom ErrNotFound
+			return nil, fmt.Errorf("FindCourseByID: course with ID %d for college ID %d: %w", courseID, collegeID, ErrCourseNotFound)
 		}
 		return nil, fmt.Errorf("unable to find course: %w", err) // Wrap the original error
 	}
@@ -114,6 +133,8 @@ func (c *courseRepository) UpdateCourse(ctx context.Context, course *models.Cour
 		Set("description", course.Description).
 		Set("credits", course.Credits).
 		Set("instructor_id", course.InstructorID).
+		Set("capacity", course.Capacity).
+		Set("department_id", course.DepartmentID).
 		Set("updated_at", course.UpdatedAt).
 		Where(squirrel.Eq{
 			"id":         course.ID,
@@ -131,7 +152,7 @@ func (c *courseRepository) UpdateCourse(ctx context.Context, course *models.Cour
 	}
 
 	if commandTag.RowsAffected() == 0 {
-		return fmt.Errorf("UpdateCourse: no course found with ID %d for college ID %d, or no changes made", course.ID, course.CollegeID)
+		return fmt.Errorf("UpdateCourse: course with ID %d for college ID %d: %w", course.ID, course.CollegeID, ErrCourseNotFound)
 	}
 
 	return nil
@@ -156,7 +177,7 @@ func (c *courseRepository) DeleteCourse(ctx context.Context, collegeID int, cour
 	}
 
 	if commandTag.RowsAffected() == 0 {
-		return fmt.Errorf("DeleteCourse: no course found with ID %d for college ID %d, or already deleted", courseID, collegeID)
+		return fmt.Errorf("DeleteCourse: course with ID %d for college ID %d: %w", courseID, collegeID, ErrCourseNotFound)
 	}
 
 	return nil
@@ -164,7 +185,7 @@ func (c *courseRepository) DeleteCourse(ctx context.Context, collegeID int, cour
 
 func (c *courseRepository) FindAllCourses(ctx context.Context, collegeID int, limit, offset uint64) ([]*models.Course, error) {
 	query := c.DB.SQ.Select(
-		"id", "name", "description", "credits", "instructor_id", "college_id", "created_at", "updated_at",
+		"id", "name", "description", "credits", "instructor_id", "capacity", "department_id", "college_id", "created_at", "updated_at",
 	).
 		From(courseTable).
 		Where(squirrel.Eq{"college_id": collegeID}).
@@ -188,7 +209,7 @@ func (c *courseRepository) FindAllCourses(ctx context.Context, collegeID int, li
 
 func (c *courseRepository) FindCoursesByInstructor(ctx context.Context, collegeID int, instructorID int, limit, offset uint64) ([]*models.Course, error) {
 	query := c.DB.SQ.Select(
-		"id", "name", "description", "credits", "instructor_id", "college_id", "created_at", "updated_at",
+		"id", "name", "description", "credits", "instructor_id", "capacity", "department_id", "college_id", "created_at", "updated_at",
 	).
 		From(courseTable).
 		Where(squirrel.Eq{
@@ -213,6 +234,31 @@ func (c *courseRepository) FindCoursesByInstructor(ctx context.Context, collegeI
 	return courses, nil
 }
 
+func (c *courseRepository) SearchCourses(ctx context.Context, collegeID int, search string, limit, offset uint64) ([]*models.Course, error) {
+	query := c.DB.SQ.Select(
+		"id", "name", "description", "credits", "instructor_id", "capacity", "department_id", "college_id", "created_at", "updated_at",
+	).
+		From(courseTable).
+		Where(squirrel.Eq{"college_id": collegeID}).
+		Where(courseSearch(search)).
+		OrderBy("name ASC").
+		Limit(limit).
+		Offset(offset)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("SearchCourses: failed to build query: %w", err)
+	}
+
+	courses := []*models.Course{}
+	err = pgxscan.Select(ctx, c.DB.Pool, &courses, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("SearchCourses: failed to execute query or scan: %w", err)
+	}
+
+	return courses, nil
+}
+
 func (c *courseRepository) CountCoursesByCollege(ctx context.Context, collegeID int) (int, error) {
 	return c.countCourses(ctx, squirrel.Eq{"college_id": collegeID})
 }
@@ -221,6 +267,80 @@ func (c *courseRepository) CountCoursesByInstructor(ctx context.Context, college
 	return c.countCourses(ctx, squirrel.Eq{"college_id": collegeID, "instructor_id": instructorID})
 }
 
+func (c *courseRepository) CountSearchCourses(ctx context.Context, collegeID int, search string) (int, error) {
+	return c.countCourses(ctx, squirrel.And{squirrel.Eq{"college_id": collegeID}, courseSearch(search)})
+}
+
+func (c *courseRepository) IsCourseInstructor(ctx context.Context, collegeID int, courseID int, identityID string) (bool, error) {
+	count, err := c.countCourses(ctx, squirrel.And{
+		squirrel.Eq{"college_id": collegeID, "id": courseID},
+		instructedBy(identityID),
+	})
+	return count > 0, err
+}
+
+func (c *courseRepository) TeachesStudent(ctx context.Context, collegeID int, identityID string, studentID int) (bool, error) {
+	count, err := c.countCourses(ctx, squirrel.And{
+		squirrel.Eq{"college_id": collegeID},
+		instructedBy(identityID),
+		attendedBy(studentID),
+	})
+	return count > 0, err
+}
+
+func (c *courseRepository) TeachesAllCoursesOf(ctx context.Context, collegeID int, identityID string, studentID int) (bool, error) {
+	teaches, err := c.TeachesStudent(ctx, collegeID, identityID, studentID)
+	if err != nil || !teaches {
+		return false, err
+	}
+	// Courses without an instructor count as someone else's
+	others, err := c.countCourses(ctx, squirrel.And{
+		squirrel.Eq{"college_id": collegeID},
+		squirrel.Expr("NOT COALESCE(?, FALSE)", instructedBy(identityID)),
+		attendedBy(studentID),
+	})
+	return others == 0, err
+}
+
+func (c *courseRepository) IsDepartmentHead(ctx context.Context, collegeID int, courseID int, identityID string) (bool, error) {
+	headed := squirrel.Select("1").
+		From(departmentTable + " d").
+		Where("d.id = " + courseTable + ".department_id").
+		Where(squirrel.Eq{"d.college_id": collegeID, "d.hod": identityID})
+	count, err := c.countCourses(ctx, squirrel.And{
+		squirrel.Eq{"college_id": collegeID, "id": courseID},
+		squirrel.Expr("EXISTS (?)", headed),
+	})
+	return count > 0, err
+}
+
+// attendedBy matches courses the student is actively enrolled in.
+func attendedBy(studentID int) squirrel.Sqlizer {
+	enrolled := squirrel.Select("1").
+		From(enrollmentTable + " e").
+		Where("e.course_id = " + courseTable + ".id").
+		Where(squirrel.Eq{"e.student_id": studentID}).
+		Where(activeEnrollment)
+	return squirrel.Expr("EXISTS (?)", enrolled)
+}
+
+// instructedBy matches courses whose instructor has the Kratos identity.
+func instructedBy(identityID string) squirrel.Sqlizer {
+	return squirrel.Expr("instructor_id IN (SELECT id FROM "+userTable+" WHERE kratos_identity_id = ?)", identityID)
+}
+
+// courseSearch matches search as a substring of the name or description.
+// LIKE wildcards in search are matched literally.
+func courseSearch(search string) squirrel.Sqlizer {
+	pattern := "%" + likeEscaper.Replace(search) + "%"
+	return squirrel.Or{
+		squirrel.ILike{"name": pattern},
+		squirrel.ILike{"description": pattern},
+	}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 // countCourses is a helper function for counting based on conditions.
 func (c *courseRepository) countCourses(ctx context.Context, whereClause squirrel.Sqlizer) (int, error) {
 	query := c.DB.SQ.Select("COUNT(*)").From(courseTable).Where(whereClause)
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"regexp" // Import regexp for ExpectQuery with regex
 	"testing"
+	"time"
 
 	"eduhub/server/internal/models"
 
@@ -39,15 +40,15 @@ func TestCreateCourse(t *testing.T) {
 		Description:  "Learn how to test Go code",
 		Credits:      3,
 		InstructorID: 1,
+		CollegeID:    1,
 	}
 	expectedID := 10
 
-	// Use regex to match the SQL query, escaping special characters
-	// This allows flexibility if the exact spacing or casing changes slightly
-	sqlRegex := `INSERT INTO course \(Name,Description,Credits,InstructorID\) VALUES \(\$1,\$2,\$3,\$4\) RETURNING id`
+	// Match the exact SQL query, escaping special characters
+	sqlRegex := `INSERT INTO courses (name,description,credits,instructor_id,capacity,department_id,college_id,created_at,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING id`
 
 	mock.ExpectQuery(regexp.QuoteMeta(sqlRegex)). // Use regexp.QuoteMeta if matching exact string, or provide regex pattern
-							WithArgs(course.Name, course.Description, course.Credits, course.InstructorID).
+							WithArgs(course.Name, course.Description, course.Credits, course.InstructorID, course.Capacity, course.DepartmentID, course.CollegeID, pgxmock.AnyArg(), pgxmock.AnyArg()).
 							WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(expectedID))
 
 	err := repo.CreateCourse(ctx, course)
@@ -66,19 +67,20 @@ func TestCreateCourse_Error(t *testing.T) {
 		Description:  "This will fail",
 		Credits:      1,
 		InstructorID: 1,
+		CollegeID:    1,
 	}
 	dbError := errors.New("database error")
 
-	sqlRegex := `INSERT INTO course \(Name,Description,Credits,InstructorID\) VALUES \(\$1,\$2,\$3,\$4\) RETURNING id`
+	sqlRegex := `INSERT INTO courses (name,description,credits,instructor_id,capacity,department_id,college_id,created_at,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING id`
 
 	mock.ExpectQuery(regexp.QuoteMeta(sqlRegex)).
-		WithArgs(course.Name, course.Description, course.Credits, course.InstructorID).
+		WithArgs(course.Name, course.Description, course.Credits, course.InstructorID, course.Capacity, course.DepartmentID, course.CollegeID, pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnError(dbError)
 
 	err := repo.CreateCourse(ctx, course)
 
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "unable to create a course") // Check against the specific error returned by the repo
+	assert.Contains(t, err.Error(), "failed to execute query or scan ID") // Check against the specific error returned by the repo
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -86,25 +88,31 @@ func TestFindCourseByID(t *testing.T) {
 	mock, _, repo, ctx := setupCourseTest(t)
 	defer mock.Close()
 
+	collegeID := 1
 	courseID := 10
+	now := time.Now()
 	expectedCourse := &models.Course{
 		ID:           courseID,
 		Name:         "Found Course",
 		Description:  "Successfully retrieved",
 		Credits:      4,
 		InstructorID: 2,
+		CollegeID:    collegeID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
 	}
 
 	// Use regex for flexibility or exact string match
-	sqlRegex := `SELECT ID, Name, Description, Credits, InstructorID FROM course WHERE ID = \$1`
-	rows := pgxmock.NewRows([]string{"ID", "Name", "Description", "Credits", "InstructorID"}).
-		AddRow(expectedCourse.ID, expectedCourse.Name, expectedCourse.Description, expectedCourse.Credits, expectedCourse.InstructorID)
+	sqlRegex := `SELECT id, name, description, credits, instructor_id, capacity, department_id, college_id, created_at, updated_at FROM courses WHERE college_id = $1 AND id = $2`
+	rows := pgxmock.NewRows([]string{"id", "name", "description", "credits", "instructor_id", "capacity", "department_id", "college_id", "created_at", "updated_at"}).
+		AddRow(expectedCourse.ID, expectedCourse.Name, expectedCourse.Description, expectedCourse.Credits, expectedCourse.InstructorID,
+			expectedCourse.Capacity, expectedCourse.DepartmentID, expectedCourse.CollegeID, expectedCourse.CreatedAt, expectedCourse.UpdatedAt)
 
 	mock.ExpectQuery(regexp.QuoteMeta(sqlRegex)).
-		WithArgs(courseID).
+		WithArgs(collegeID, courseID).
 		WillReturnRows(rows)
 
-	course, err := repo.FindCourseByID(ctx, courseID)
+	course, err := repo.FindCourseByID(ctx, collegeID, courseID)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedCourse, course)
@@ -115,18 +123,19 @@ func TestFindCourseByID_NotFound(t *testing.T) {
 	mock, _, repo, ctx := setupCourseTest(t)
 	defer mock.Close()
 
+	collegeID := 1
 	courseID := 99
-	sqlRegex := `SELECT ID, Name, Description, Credits, InstructorID FROM course WHERE ID = \$1`
+	sqlRegex := `SELECT id, name, description, credits, instructor_id, capacity, department_id, college_id, created_at, updated_at FROM courses WHERE college_id = $1 AND id = $2`
 
 	mock.ExpectQuery(regexp.QuoteMeta(sqlRegex)).
-		WithArgs(courseID).
+		WithArgs(collegeID, courseID).
 		WillReturnError(pgx.ErrNoRows)
 
-	course, err := repo.FindCourseByID(ctx, courseID)
+	course, err := repo.FindCourseByID(ctx, collegeID, courseID)
 
 	assert.Error(t, err)
 	assert.Nil(t, course)
-	assert.Contains(t, err.Error(), "not found") // Check the specific error message
+	assert.ErrorIs(t, err, ErrCourseNotFound)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -134,15 +143,16 @@ func TestFindCourseByID_Error(t *testing.T) {
 	mock, _, repo, ctx := setupCourseTest(t)
 	defer mock.Close()
 
+	collegeID := 1
 	courseID := 10
 	dbError := errors.New("database connection lost")
-	sqlRegex := `SELECT ID, Name, Description, Credits, InstructorID FROM course WHERE ID = \$1`
+	sqlRegex := `SELECT id, name, description, credits, instructor_id, capacity, department_id, college_id, created_at, updated_at FROM courses WHERE college_id = $1 AND id = $2`
 
 	mock.ExpectQuery(regexp.QuoteMeta(sqlRegex)).
-		WithArgs(courseID).
+		WithArgs(collegeID, courseID).
 		WillReturnError(dbError)
 
-	course, err := repo.FindCourseByID(ctx, courseID)
+	course, err := repo.FindCourseByID(ctx, collegeID, courseID)
 
 	assert.Error(t, err)
 	assert.Nil(t, course)
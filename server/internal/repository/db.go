@@ -31,6 +31,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgconn" // Import pgconn for CommandTag
@@ -47,12 +48,21 @@ type PoolIface interface {
 	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
 	Exec(ctx context.Context, sql string, args ...any) (commandTag pgconn.CommandTag, err error) // Use pgconn.CommandTag
+	Begin(ctx context.Context) (pgx.Tx, error)                                                   // Used by DB.WithTx
 	Close()                                                                                      // Include Close if used by DB.Close()
 	// Add other methods from pgxpool.Pool if *any* repository method calls them
 	// e.g., Acquire(ctx context.Context) (*pgxpool.Conn, error)
 	//      Stat() *pgxpool.Stat
 }
 
+// Querier is the subset of PoolIface that is also implemented by pgx.Tx, so
+// query helpers can run either directly on the pool or inside a transaction.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Exec(ctx context.Context, sql string, args ...any) (commandTag pgconn.CommandTag, err error)
+}
+
 // DB represents the database connection structure used by repositories
 type DB struct {
 	Pool PoolIface
@@ -81,3 +91,29 @@ func (db *DB) Close() {
 		db.Pool.Close()
 	}
 }
+
+// WithTx runs fn inside a transaction. The transaction is committed if fn
+// returns nil and rolled back otherwise, including when fn panics.
+func (db *DB) WithTx(ctx context.Context, fn func(tx pgx.Tx) error) (err error) {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("WithTx: failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("WithTx: failed to commit transaction: %w", err)
+	}
+	return nil
+}
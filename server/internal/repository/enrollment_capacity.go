@@ -0,0 +1,378 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+)
+
+var ErrEnrollmentNotFound = errors.New("enrollment not found")
+
+// seatTaken matches enrollments aliased as e that hold one of the course's
+// seats. A suspended student keeps their seat.
+var seatTaken = squirrel.Expr("LOWER(e.status) IN (?, ?)", models.Active, models.Frozen)
+
+// EnrollStudents enrolls students in the order given, waitlisting them once
+// the course is full. Students listed in ineligible are skipped unless an
+// override is given, in which case each is enrolled and a copy of override is
+// recorded for them. The course row is locked for the duration so concurrent
+// requests cannot oversell seats.
+func (e *enrollmentRepository) EnrollStudents(ctx context.Context, collegeID int, courseID int, studentIDs []int, ineligible map[int][]*models.UnmetRequirement, override *models.EnrollmentOverride) ([]*models.BulkEnrollmentResult, error) {
+	var results []*models.BulkEnrollmentResult
+	err := e.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		results = make([]*models.BulkEnrollmentResult, 0, len(studentIDs))
+
+		capacity, err := e.lockCourse(ctx, tx, collegeID, courseID)
+		if err != nil {
+			return err
+		}
+		rollNos, err := e.resolveStudents(ctx, tx, collegeID, studentIDs)
+		if err != nil {
+			return err
+		}
+		current, err := e.enrollmentStatuses(ctx, tx, collegeID, courseID, rollNos)
+		if err != nil {
+			return err
+		}
+		taken, err := e.countTakenSeats(ctx, tx, collegeID, courseID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, studentID := range studentIDs {
+			result := &models.BulkEnrollmentResult{StudentID: studentID, Result: models.EnrollResultNotFound}
+			results = append(results, result)
+			rollNo, ok := rollNos[studentID]
+			if !ok {
+				continue
+			}
+			result.RollNo = rollNo
+
+			switch status := current[studentID]; status {
+			case models.Active, models.Frozen, models.Waitlisted, models.FrozenWaitlisted, models.Completed:
+				result.Status = status
+				result.Result = models.EnrollResultUnchanged
+				continue
+			}
+			if unmet := ineligible[studentID]; len(unmet) > 0 {
+				result.Unmet = unmet
+				if override == nil {
+					result.Result = models.EnrollResultIneligible
+					continue
+				}
+				record := *override
+				record.CollegeID, record.CourseID, record.StudentID, record.Unmet = collegeID, courseID, studentID, unmet
+				if err := insertEnrollmentOverride(ctx, e.DB, tx, &record); err != nil {
+					return err
+				}
+				result.Overridden = true
+			}
+
+			result.Status, result.Result = models.Active, models.EnrollResultEnrolled
+			if capacity != nil && taken >= *capacity {
+				result.Status, result.Result = models.Waitlisted, models.EnrollResultWaitlisted
+			} else {
+				taken++
+			}
+			if err := e.upsertEnrollment(ctx, tx, collegeID, courseID, studentID, result.Status, now); err != nil {
+				return err
+			}
+			current[studentID] = result.Status
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("EnrollStudents: %w", err)
+	}
+	return results, nil
+}
+
+// CreateEnrollmentWithOverride creates the enrollment and records the
+// override that allowed it in one transaction.
+func (e *enrollmentRepository) CreateEnrollmentWithOverride(ctx context.Context, enrollment *models.Enrollment, override *models.EnrollmentOverride) error {
+	err := e.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if err := e.createEnrollment(ctx, tx, enrollment); err != nil {
+			return err
+		}
+		return insertEnrollmentOverride(ctx, e.DB, tx, override)
+	})
+	if err != nil {
+		return fmt.Errorf("CreateEnrollmentWithOverride: %w", err)
+	}
+	return nil
+}
+
+// DropEnrollment drops the student from the course or its waitlist and
+// promotes waitlisted students into any seat that frees up. It returns the
+// promoted students' IDs.
+func (e *enrollmentRepository) DropEnrollment(ctx context.Context, collegeID int, courseID int, studentID int) ([]int, error) {
+	var promoted []int
+	err := e.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		capacity, err := e.lockCourse(ctx, tx, collegeID, courseID)
+		if err != nil {
+			return err
+		}
+
+		sql, args, err := e.DB.SQ.Update(enrollmentTable+" e").
+			Set("status", models.Dropped).
+			Set("updated_at", time.Now()).
+			Where(squirrel.Eq{"e.college_id": collegeID, "e.course_id": courseID, "e.student_id": studentID}).
+			Where(squirrel.Or{seatTaken, squirrel.Eq{"e.status": []string{models.Waitlisted, models.FrozenWaitlisted}}}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("failed to build drop query: %w", err)
+		}
+		tag, err := tx.Exec(ctx, sql, args...)
+		if err != nil {
+			return fmt.Errorf("failed to drop enrollment: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrEnrollmentNotFound
+		}
+
+		promoted, err = e.promoteWaitlist(ctx, tx, collegeID, courseID, capacity)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("DropEnrollment: %w", err)
+	}
+	return promoted, nil
+}
+
+// PromoteWaitlist fills any free seats from the course's waitlist, for
+// example after its capacity was raised. It returns the promoted students' IDs.
+func (e *enrollmentRepository) PromoteWaitlist(ctx context.Context, collegeID int, courseID int) ([]int, error) {
+	var promoted []int
+	err := e.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		capacity, err := e.lockCourse(ctx, tx, collegeID, courseID)
+		if err != nil {
+			return err
+		}
+		promoted, err = e.promoteWaitlist(ctx, tx, collegeID, courseID, capacity)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("PromoteWaitlist: %w", err)
+	}
+	return promoted, nil
+}
+
+// FindCourseRoster lists the course's enrollments with their students. An
+// empty status lists everyone except dropped students; the waitlist is
+// returned in promotion order.
+func (e *enrollmentRepository) FindCourseRoster(ctx context.Context, collegeID int, courseID int, termID *int, status string, limit, offset uint64) ([]*models.Enrollment, error) {
+	query := e.DB.SQ.Select(
+		"e.id", "e.student_id", "e.course_id", "e.college_id", "e.term_id", "e.enrollment_date",
+		"LOWER(e.status) AS status", "COALESCE(e.grade, '') AS grade", "e.created_at", "e.updated_at",
+		`s.id AS "student.id"`, `s.user_id AS "student.user_id"`, `s.college_id AS "student.college_id"`,
+		`s.kratos_identity_id AS "student.kratos_identity_id"`, `COALESCE(s.enrollment_year, 0) AS "student.enrollment_year"`,
+		`s.roll_no AS "student.roll_no"`, `s.is_active AS "student.is_active"`,
+		`s.created_at AS "student.created_at"`, `s.updated_at AS "student.updated_at"`,
+	).
+		From(enrollmentTable + " e").
+		Join(studentTable + " s ON s.id = e.student_id").
+		Where(squirrel.Eq{"e.college_id": collegeID, "e.course_id": courseID}).
+		Limit(limit).
+		Offset(offset)
+	if termID != nil {
+		query = query.Where(squirrel.Eq{"e.term_id": *termID})
+	}
+
+	switch status {
+	case "":
+		query = query.Where("LOWER(e.status) <> ?", models.Dropped).OrderBy("s.roll_no ASC")
+	case models.Waitlisted:
+		query = query.Where(squirrel.Eq{"e.status": models.Waitlisted}).OrderBy("e.updated_at ASC", "e.id ASC")
+	default:
+		query = query.Where("LOWER(e.status) = ?", status).OrderBy("s.roll_no ASC")
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindCourseRoster: failed to build query: %w", err)
+	}
+
+	rows := []*struct {
+		models.Enrollment
+		Student models.Student `db:"student"`
+	}{}
+	if err := pgxscan.Select(ctx, e.DB.Pool, &rows, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindCourseRoster: failed to execute query or scan: %w", err)
+	}
+
+	enrollments := make([]*models.Enrollment, len(rows))
+	for i, row := range rows {
+		enrollment := row.Enrollment
+		student := row.Student
+		enrollment.Student = &student
+		enrollments[i] = &enrollment
+	}
+	return enrollments, nil
+}
+
+// lockCourse locks the course row, serialising seat changes, and returns its
+// capacity.
+func (e *enrollmentRepository) lockCourse(ctx context.Context, q Querier, collegeID int, courseID int) (*int, error) {
+	sql, args, err := e.DB.SQ.Select("capacity").
+		From(courseTable).
+		Where(squirrel.Eq{"id": courseID, "college_id": collegeID}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build course lock query: %w", err)
+	}
+
+	var capacity *int
+	if err := q.QueryRow(ctx, sql, args...).Scan(&capacity); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("course %d for college ID %d: %w", courseID, collegeID, ErrCourseNotFound)
+		}
+		return nil, fmt.Errorf("failed to lock course: %w", err)
+	}
+	return capacity, nil
+}
+
+// resolveStudents returns the roll numbers of those of studentIDs that are
+// active students of the college.
+func (e *enrollmentRepository) resolveStudents(ctx context.Context, tx pgx.Tx, collegeID int, studentIDs []int) (map[int]string, error) {
+	rollNos := make(map[int]string, len(studentIDs))
+	if len(studentIDs) == 0 {
+		return rollNos, nil
+	}
+
+	sql, args, err := e.DB.SQ.Select("id", "roll_no").
+		From(studentTable).
+		Where(squirrel.Eq{"college_id": collegeID, "is_active": true, "id": studentIDs}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build student lookup query: %w", err)
+	}
+
+	students := []struct {
+		ID     int    `db:"id"`
+		RollNo string `db:"roll_no"`
+	}{}
+	if err := pgxscan.Select(ctx, tx, &students, sql, args...); err != nil {
+		return nil, fmt.Errorf("failed to look up students: %w", err)
+	}
+	for _, s := range students {
+		rollNos[s.ID] = s.RollNo
+	}
+	return rollNos, nil
+}
+
+// enrollmentStatuses returns the enrollment status in the course, in the
+// college's current term, of each student in students.
+func (e *enrollmentRepository) enrollmentStatuses(ctx context.Context, tx pgx.Tx, collegeID int, courseID int, students map[int]string) (map[int]string, error) {
+	current := make(map[int]string, len(students))
+	if len(students) == 0 {
+		return current, nil
+	}
+	studentIDs := make([]int, 0, len(students))
+	for id := range students {
+		studentIDs = append(studentIDs, id)
+	}
+
+	sql, args, err := e.DB.SQ.Select("student_id", "LOWER(status) AS status").
+		From(enrollmentTable).
+		Where(squirrel.Eq{"college_id": collegeID, "course_id": courseID, "student_id": studentIDs}).
+		Where(squirrel.Expr("term_id IS NOT DISTINCT FROM ?", currentTerm(collegeID))).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build enrollment status query: %w", err)
+	}
+
+	rows := []struct {
+		StudentID int    `db:"student_id"`
+		Status    string `db:"status"`
+	}{}
+	if err := pgxscan.Select(ctx, tx, &rows, sql, args...); err != nil {
+		return nil, fmt.Errorf("failed to load enrollment statuses: %w", err)
+	}
+	for _, row := range rows {
+		current[row.StudentID] = row.Status
+	}
+	return current, nil
+}
+
+func (e *enrollmentRepository) countTakenSeats(ctx context.Context, q Querier, collegeID int, courseID int) (int, error) {
+	sql, args, err := e.DB.SQ.Select("COUNT(*)").
+		From(enrollmentTable + " e").
+		Where(squirrel.Eq{"e.college_id": collegeID, "e.course_id": courseID}).
+		Where(seatTaken).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build seat count query: %w", err)
+	}
+	var taken int
+	if err := q.QueryRow(ctx, sql, args...).Scan(&taken); err != nil {
+		return 0, fmt.Errorf("failed to count taken seats: %w", err)
+	}
+	return taken, nil
+}
+
+// upsertEnrollment enrolls the student in the college's current term, reusing
+// the row of an earlier dropped or inactive enrollment in the same course and
+// term. Enrollments in earlier terms are kept, so students can retake courses.
+func (e *enrollmentRepository) upsertEnrollment(ctx context.Context, q Querier, collegeID int, courseID int, studentID int, status string, now time.Time) error {
+	sql, args, err := e.DB.SQ.Insert(enrollmentTable).
+		Columns("student_id", "course_id", "college_id", "term_id", "enrollment_date", "status", "created_at", "updated_at").
+		Values(studentID, courseID, collegeID, currentTerm(collegeID), now, status, now, now).
+		Suffix(`ON CONFLICT (student_id, course_id, COALESCE(term_id, 0)) DO UPDATE SET
+              status = EXCLUDED.status,
+              enrollment_date = EXCLUDED.enrollment_date,
+              updated_at = EXCLUDED.updated_at`).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build enrollment upsert: %w", err)
+	}
+	if _, err := q.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to enroll student %d: %w", studentID, err)
+	}
+	return nil
+}
+
+// promoteWaitlist moves waitlisted students, oldest first, into the seats the
+// course has free. A course without a capacity takes everyone waiting.
+func (e *enrollmentRepository) promoteWaitlist(ctx context.Context, tx pgx.Tx, collegeID int, courseID int, capacity *int) ([]int, error) {
+	waiting := squirrel.Select("id").
+		From(enrollmentTable).
+		Where(squirrel.Eq{"college_id": collegeID, "course_id": courseID, "status": models.Waitlisted}).
+		OrderBy("updated_at ASC", "id ASC")
+	if capacity != nil {
+		taken, err := e.countTakenSeats(ctx, tx, collegeID, courseID)
+		if err != nil {
+			return nil, err
+		}
+		if taken >= *capacity {
+			return nil, nil
+		}
+		waiting = waiting.Limit(uint64(*capacity - taken))
+	}
+
+	now := time.Now()
+	sql, args, err := e.DB.SQ.Update(enrollmentTable).
+		Set("status", models.Active).
+		Set("enrollment_date", now).
+		Set("updated_at", now).
+		Where(squirrel.Expr("id IN (?)", waiting)).
+		Suffix("RETURNING student_id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build waitlist promotion: %w", err)
+	}
+
+	promoted := []int{}
+	if err := pgxscan.Select(ctx, tx, &promoted, sql, args...); err != nil {
+		return nil, fmt.Errorf("failed to promote waitlist: %w", err)
+	}
+	return promoted, nil
+}
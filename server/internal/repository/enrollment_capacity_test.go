@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupEnrollmentCapacityTest(t *testing.T) (pgxmock.PgxPoolIface, *enrollmentRepository, context.Context) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+
+	db := &DB{
+		Pool: mock,
+		SQ:   squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+	return mock, &enrollmentRepository{DB: db}, context.Background()
+}
+
+const (
+	lockCourseSQL = `SELECT capacity FROM courses WHERE college_id = $1 AND id = $2 FOR UPDATE`
+	takenSeatsSQL = `SELECT COUNT(*) FROM enrollments e WHERE e.college_id = $1 AND e.course_id = $2 AND LOWER(e.status) IN ($3, $4)`
+	promoteSQL    = `UPDATE enrollments SET status = $1, enrollment_date = $2, updated_at = $3 WHERE id IN (SELECT id FROM enrollments WHERE college_id = $4 AND course_id = $5 AND status = $6 ORDER BY updated_at ASC, id ASC`
+)
+
+func expectLockCourse(mock pgxmock.PgxPoolIface, capacity *int) {
+	mock.ExpectQuery(regexp.QuoteMeta(lockCourseSQL)).
+		WithArgs(1, 10).
+		WillReturnRows(pgxmock.NewRows([]string{"capacity"}).AddRow(capacity))
+}
+
+func expectTakenSeats(mock pgxmock.PgxPoolIface, taken int) {
+	mock.ExpectQuery(regexp.QuoteMeta(takenSeatsSQL)).
+		WithArgs(1, 10, models.Active, models.Frozen).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(taken))
+}
+
+func TestEnrollStudentsWaitlistsOnceFull(t *testing.T) {
+	mock, repo, ctx := setupEnrollmentCapacityTest(t)
+	defer mock.Close()
+	seats := 2
+
+	mock.ExpectBegin()
+	expectLockCourse(mock, &seats)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, roll_no FROM students`)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "roll_no"}).
+			AddRow(7, "R7").
+			AddRow(8, "R8").
+			AddRow(9, "R9"))
+	// Student 9 already holds a seat and is left alone
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT student_id, LOWER(status) AS status FROM enrollments WHERE college_id = $1 AND course_id = $2 AND student_id IN ($3,$4,$5) AND term_id IS NOT DISTINCT FROM (SELECT id FROM academic_terms WHERE college_id = $6 AND is_current)`)).
+		WillReturnRows(pgxmock.NewRows([]string{"student_id", "status"}).AddRow(9, models.Active))
+	expectTakenSeats(mock, 1)
+	// Rows are per term, so earlier terms' enrollments are never overwritten
+	mock.ExpectExec(`INSERT INTO enrollments .* ON CONFLICT \(student_id, course_id, COALESCE\(term_id, 0\)\) DO UPDATE SET\s+status = EXCLUDED.status,\s+enrollment_date`).
+		WithArgs(7, 10, 1, 1, pgxmock.AnyArg(), models.Active, pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO enrollments`)).
+		WithArgs(8, 10, 1, 1, pgxmock.AnyArg(), models.Waitlisted, pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	results, err := repo.EnrollStudents(ctx, 1, 10, []int{7, 8, 9, 99}, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.Equal(t, models.EnrollResultEnrolled, results[0].Result)
+	assert.Equal(t, models.EnrollResultWaitlisted, results[1].Result)
+	assert.Equal(t, models.Waitlisted, results[1].Status)
+	assert.Equal(t, models.EnrollResultUnchanged, results[2].Result)
+	assert.Equal(t, models.EnrollResultNotFound, results[3].Result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromoteWaitlistFillsFreeSeatsOldestFirst(t *testing.T) {
+	mock, repo, ctx := setupEnrollmentCapacityTest(t)
+	defer mock.Close()
+	seats := 3
+
+	mock.ExpectBegin()
+	expectLockCourse(mock, &seats)
+	expectTakenSeats(mock, 1)
+	// Two seats are free, so the two longest waiting are promoted
+	mock.ExpectQuery(regexp.QuoteMeta(promoteSQL+` LIMIT 2) RETURNING student_id`)).
+		WithArgs(models.Active, pgxmock.AnyArg(), pgxmock.AnyArg(), 1, 10, models.Waitlisted).
+		WillReturnRows(pgxmock.NewRows([]string{"student_id"}).AddRow(7).AddRow(8))
+	mock.ExpectCommit()
+
+	promoted, err := repo.PromoteWaitlist(ctx, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int{7, 8}, promoted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromoteWaitlistFullCourse(t *testing.T) {
+	mock, repo, ctx := setupEnrollmentCapacityTest(t)
+	defer mock.Close()
+	seats := 3
+
+	mock.ExpectBegin()
+	expectLockCourse(mock, &seats)
+	expectTakenSeats(mock, 3)
+	mock.ExpectCommit()
+
+	promoted, err := repo.PromoteWaitlist(ctx, 1, 10)
+	require.NoError(t, err)
+	assert.Empty(t, promoted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromoteWaitlistUnlimitedCourse(t *testing.T) {
+	mock, repo, ctx := setupEnrollmentCapacityTest(t)
+	defer mock.Close()
+
+	mock.ExpectBegin()
+	expectLockCourse(mock, nil)
+	// Without a capacity everyone waiting is promoted, with no seat count
+	mock.ExpectQuery(regexp.QuoteMeta(promoteSQL+`) RETURNING student_id`)).
+		WithArgs(models.Active, pgxmock.AnyArg(), pgxmock.AnyArg(), 1, 10, models.Waitlisted).
+		WillReturnRows(pgxmock.NewRows([]string{"student_id"}).AddRow(7))
+	mock.ExpectCommit()
+
+	promoted, err := repo.PromoteWaitlist(ctx, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int{7}, promoted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDropEnrollmentPromotesWaitlist(t *testing.T) {
+	mock, repo, ctx := setupEnrollmentCapacityTest(t)
+	defer mock.Close()
+	seats := 2
+
+	mock.ExpectBegin()
+	expectLockCourse(mock, &seats)
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE enrollments e SET status = $1, updated_at = $2`)).
+		WithArgs(models.Dropped, pgxmock.AnyArg(), 1, 10, 5, models.Active, models.Frozen, models.Waitlisted, models.FrozenWaitlisted).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectTakenSeats(mock, 1)
+	mock.ExpectQuery(regexp.QuoteMeta(promoteSQL+` LIMIT 1) RETURNING student_id`)).
+		WithArgs(models.Active, pgxmock.AnyArg(), pgxmock.AnyArg(), 1, 10, models.Waitlisted).
+		WillReturnRows(pgxmock.NewRows([]string{"student_id"}).AddRow(7))
+	mock.ExpectCommit()
+
+	promoted, err := repo.DropEnrollment(ctx, 1, 10, 5)
+	require.NoError(t, err)
+	assert.Equal(t, []int{7}, promoted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDropEnrollmentNotEnrolled(t *testing.T) {
+	mock, repo, ctx := setupEnrollmentCapacityTest(t)
+	defer mock.Close()
+	seats := 2
+
+	mock.ExpectBegin()
+	expectLockCourse(mock, &seats)
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE enrollments e SET status = $1, updated_at = $2`)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectRollback()
+
+	_, err := repo.DropEnrollment(ctx, 1, 10, 5)
+	assert.ErrorIs(t, err, ErrEnrollmentNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteEnrollmentPromotesWaitlist(t *testing.T) {
+	mock, repo, ctx := setupEnrollmentCapacityTest(t)
+	defer mock.Close()
+	seats := 2
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT course_id FROM enrollments WHERE college_id = $1 AND id = $2`)).
+		WithArgs(1, 40).
+		WillReturnRows(pgxmock.NewRows([]string{"course_id"}).AddRow(10))
+	expectLockCourse(mock, &seats)
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM enrollments WHERE college_id = $1 AND id = $2`)).
+		WithArgs(1, 40).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	expectTakenSeats(mock, 1)
+	mock.ExpectQuery(regexp.QuoteMeta(promoteSQL+` LIMIT 1) RETURNING student_id`)).
+		WithArgs(models.Active, pgxmock.AnyArg(), pgxmock.AnyArg(), 1, 10, models.Waitlisted).
+		WillReturnRows(pgxmock.NewRows([]string{"student_id"}).AddRow(7))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.DeleteEnrollment(ctx, 1, 40))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -15,6 +16,9 @@ import (
 type EnrollmentRepository interface {
 	CreateEnrollment(ctx context.Context, enrollment *models.Enrollment) error
 	IsStudentEnrolled(ctx context.Context, collegeID int, studentID int, courseID int) (bool, error)
+	// FindEnrolledActiveStudentIDs returns which of studentIDs are active students enrolled in the course, in one query.
+	// Only active or completed enrollments count; waitlisted, frozen, inactive and dropped ones do not.
+	FindEnrolledActiveStudentIDs(ctx context.Context, collegeID int, courseID int, studentIDs []int) (map[int]bool, error)
 	GetEnrollmentByID(ctx context.Context, collegeID int, enrollmentID int) (*models.Enrollment, error) // Added collegeID for scoping
 	UpdateEnrollment(ctx context.Context, enrollment *models.Enrollment) error
 	UpdateEnrollmentStatus(ctx context.Context, collegeID int, enrollmentID int, status string) error // Added collegeID for scoping
@@ -29,6 +33,14 @@ type EnrollmentRepository interface {
 	CountEnrollmentsByStudent(ctx context.Context, collegeID int, studentID int) (int, error)
 	CountEnrollmentsByCourse(ctx context.Context, collegeID int, courseID int) (int, error)
 	CountEnrollmentsByCollege(ctx context.Context, collegeID int) (int, error)
+
+	// Seat-limited enrollment; see enrollment_capacity.go
+	EnrollStudents(ctx context.Context, collegeID int, courseID int, studentIDs []int, ineligible map[int][]*models.UnmetRequirement, override *models.EnrollmentOverride) ([]*models.BulkEnrollmentResult, error)
+	CreateEnrollmentWithOverride(ctx context.Context, enrollment *models.Enrollment, override *models.EnrollmentOverride) error
+	DropEnrollment(ctx context.Context, collegeID int, courseID int, studentID int) ([]int, error)
+	PromoteWaitlist(ctx context.Context, collegeID int, courseID int) ([]int, error)
+	// FindCourseRoster lists the course's enrollments, in termID only if it is set.
+	FindCourseRoster(ctx context.Context, collegeID int, courseID int, termID *int, status string, limit, offset uint64) ([]*models.Enrollment, error)
 }
 
 // enrollmentRepository now holds a direct reference to *DB
@@ -45,8 +57,16 @@ func NewEnrollmentRepository(db *DB) EnrollmentRepository {
 
 const enrollmentTable = "enrollments" // Define your table name
 
+// activeEnrollment matches active rows of enrollments aliased as e. Older rows
+// carry the table default 'Active', newer ones models.Active.
+var activeEnrollment = squirrel.Expr("LOWER(e.status) = ?", models.Active)
+
 // CreateEnrollment inserts a new enrollment record into the database.
 func (e *enrollmentRepository) CreateEnrollment(ctx context.Context, enrollment *models.Enrollment) error {
+	return e.createEnrollment(ctx, e.DB.Pool, enrollment)
+}
+
+func (e *enrollmentRepository) createEnrollment(ctx context.Context, q Querier, enrollment *models.Enrollment) error {
 	// Set timestamps if they are zero-valued
 	now := time.Now()
 	if enrollment.CreatedAt.IsZero() {
@@ -62,6 +82,7 @@ func (e *enrollmentRepository) CreateEnrollment(ctx context.Context, enrollment
 			"student_id",
 			"course_id",
 			"college_id", // Include college_id based on your struct and queries
+			"term_id",
 			"enrollment_date",
 			"status",
 			"grade",
@@ -72,13 +93,14 @@ func (e *enrollmentRepository) CreateEnrollment(ctx context.Context, enrollment
 			enrollment.StudentID,
 			enrollment.CourseID,
 			enrollment.CollegeID, // Use the field from the struct
+			termOrDefault(enrollment.TermID, currentTerm(enrollment.CollegeID)),
 			enrollment.EnrollmentDate,
 			enrollment.Status,
 			enrollment.Grade,
 			enrollment.CreatedAt,
 			enrollment.UpdatedAt,
 		).
-		Suffix("RETURNING id") // Assuming 'id' is auto-generated and you want it back
+		Suffix("RETURNING id, term_id") // Assuming 'id' is auto-generated and you want it back
 
 	sql, args, err := query.ToSql()
 	if err != nil {
@@ -86,7 +108,7 @@ func (e *enrollmentRepository) CreateEnrollment(ctx context.Context, enrollment
 	}
 
 	// Execute the query and scan the returned ID back into the struct
-	err = e.DB.Pool.QueryRow(ctx, sql, args...).Scan(&enrollment.ID)
+	err = q.QueryRow(ctx, sql, args...).Scan(&enrollment.ID, &enrollment.TermID)
 	if err != nil {
 		return fmt.Errorf("CreateEnrollment: failed to execute query or scan ID: %w", err)
 	}
@@ -127,11 +149,44 @@ func (e *enrollmentRepository) IsStudentEnrolled(ctx context.Context, collegeID
 	return true, nil // Record exists
 }
 
+func (e *enrollmentRepository) FindEnrolledActiveStudentIDs(ctx context.Context, collegeID int, courseID int, studentIDs []int) (map[int]bool, error) {
+	enrolled := make(map[int]bool, len(studentIDs))
+	if len(studentIDs) == 0 {
+		return enrolled, nil
+	}
+
+	query := e.DB.SQ.Select("e.student_id").
+		From(enrollmentTable + " e").
+		Join(studentTable + " s ON s.id = e.student_id AND s.college_id = e.college_id").
+		Where(squirrel.Eq{
+			"e.college_id": collegeID,
+			"e.course_id":  courseID,
+			"e.student_id": studentIDs,
+			"s.is_active":  true,
+		}).
+		Where(squirrel.Expr("LOWER(e.status) IN (?, ?)", models.Active, models.Completed))
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindEnrolledActiveStudentIDs: failed to build query: %w", err)
+	}
+
+	ids := []int{}
+	err = pgxscan.Select(ctx, e.DB.Pool, &ids, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("FindEnrolledActiveStudentIDs: failed to execute query or scan: %w", err)
+	}
+	for _, id := range ids {
+		enrolled[id] = true
+	}
+	return enrolled, nil
+}
+
 // GetEnrollmentByID retrieves a specific enrollment by its ID, scoped by collegeID.
 func (e *enrollmentRepository) GetEnrollmentByID(ctx context.Context, collegeID int, enrollmentID int) (*models.Enrollment, error) {
 	// Build the SELECT query for a single row
 	query := e.DB.SQ.Select(
-		"id", "student_id", "course_id", "college_id",
+		"id", "student_id", "course_id", "college_id", "term_id",
 		"enrollment_date", "status", "grade", "created_at", "updated_at",
 	).
 		From(enrollmentTable).
@@ -165,7 +220,7 @@ func (e *enrollmentRepository) GetEnrollmentByID(ctx context.Context, collegeID
 func (e *enrollmentRepository) FindEnrollmentsByStudent(ctx context.Context, collegeID int, studentID int, limit, offset uint64) ([]*models.Enrollment, error) {
 	// Build the SELECT query for multiple rows
 	query := e.DB.SQ.Select(
-		"id", "student_id", "course_id", "college_id",
+		"id", "student_id", "course_id", "college_id", "term_id",
 		"enrollment_date", "status", "grade", "created_at", "updated_at",
 	).
 		From(enrollmentTable).
@@ -198,7 +253,7 @@ func (e *enrollmentRepository) FindEnrollmentsByStudent(ctx context.Context, col
 // FindEnrollmentsByCourse retrieves all enrollment records for a specific course in a college with pagination.
 func (e *enrollmentRepository) FindEnrollmentsByCourse(ctx context.Context, collegeID int, courseID int, limit, offset uint64) ([]*models.Enrollment, error) {
 	query := e.DB.SQ.Select(
-		"id", "student_id", "course_id", "college_id",
+		"id", "student_id", "course_id", "college_id", "term_id",
 		"enrollment_date", "status", "grade", "created_at", "updated_at",
 	).
 		From(enrollmentTable).
@@ -227,7 +282,7 @@ func (e *enrollmentRepository) FindEnrollmentsByCourse(ctx context.Context, coll
 // FindEnrollmentsByCollege retrieves all enrollment records for a specific college with pagination.
 func (e *enrollmentRepository) FindEnrollmentsByCollege(ctx context.Context, collegeID int, limit, offset uint64) ([]*models.Enrollment, error) {
 	query := e.DB.SQ.Select(
-		"id", "student_id", "course_id", "college_id",
+		"id", "student_id", "course_id", "college_id", "term_id",
 		"enrollment_date", "status", "grade", "created_at", "updated_at",
 	).
 		From(enrollmentTable).
@@ -319,27 +374,54 @@ func (e *enrollmentRepository) UpdateEnrollmentStatus(ctx context.Context, colle
 	return nil // Success
 }
 
-// DeleteEnrollment removes an enrollment record by its ID, scoped by collegeID.
+// DeleteEnrollment removes an enrollment record by its ID, scoped by
+// collegeID, and promotes waitlisted students into a seat it frees.
 func (e *enrollmentRepository) DeleteEnrollment(ctx context.Context, collegeID, enrollmentID int) error {
-	query := e.DB.SQ.Delete(enrollmentTable).Where(squirrel.Eq{
-		"id":         enrollmentID,
-		"college_id": collegeID, // Ensure deletion is scoped
-	})
-
-	sql, args, err := query.ToSql()
+	lookupSQL, lookupArgs, err := e.DB.SQ.Select("course_id").
+		From(enrollmentTable).
+		Where(squirrel.Eq{"id": enrollmentID, "college_id": collegeID}).
+		ToSql()
 	if err != nil {
-		return fmt.Errorf("DeleteEnrollment: failed to build query: %w", err)
+		return fmt.Errorf("DeleteEnrollment: failed to build lookup query: %w", err)
 	}
-
-	commandTag, err := e.DB.Pool.Exec(ctx, sql, args...)
+	sql, args, err := e.DB.SQ.Delete(enrollmentTable).
+		Where(squirrel.Eq{
+			"id":         enrollmentID,
+			"college_id": collegeID, // Ensure deletion is scoped
+		}).
+		ToSql()
 	if err != nil {
-		return fmt.Errorf("DeleteEnrollment: failed to execute query: %w", err)
+		return fmt.Errorf("DeleteEnrollment: failed to build query: %w", err)
 	}
 
-	if commandTag.RowsAffected() == 0 {
-		return fmt.Errorf("DeleteEnrollment: no enrollment found with ID %d for college ID %d, or already deleted", enrollmentID, collegeID)
-	}
+	notFound := fmt.Errorf("no enrollment found with ID %d for college ID %d, or already deleted", enrollmentID, collegeID)
+	err = e.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		var courseID int
+		if err := tx.QueryRow(ctx, lookupSQL, lookupArgs...).Scan(&courseID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return notFound
+			}
+			return fmt.Errorf("failed to look up enrollment: %w", err)
+		}
+		capacity, err := e.lockCourse(ctx, tx, collegeID, courseID)
+		if err != nil {
+			return err
+		}
+
+		commandTag, err := tx.Exec(ctx, sql, args...)
+		if err != nil {
+			return fmt.Errorf("failed to execute query: %w", err)
+		}
+		if commandTag.RowsAffected() == 0 {
+			return notFound
+		}
 
+		_, err = e.promoteWaitlist(ctx, tx, collegeID, courseID, capacity)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("DeleteEnrollment: %w", err)
+	}
 	return nil
 }
 
@@ -352,6 +434,9 @@ func (e *enrollmentRepository) UpdateEnrollment(ctx context.Context, enrollment
 		Set("grade", enrollment.Grade).
 		Set("updated_at", enrollment.UpdatedAt).                                    // Corrected typo: updated_at
 		Where(squirrel.Eq{"id": enrollment.ID, "college_id": enrollment.CollegeID}) // Ensure update is scoped
+	if enrollment.TermID != nil {
+		query = query.Set("term_id", *enrollment.TermID)
+	}
 
 	sql, args, err := query.ToSql()
 	if err != nil {
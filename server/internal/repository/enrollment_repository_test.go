@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+
+	"eduhub/server/internal/models"
+
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindEnrolledActiveStudentIDsCountsOnlyHeldEnrollments(t *testing.T) {
+	mock, repo, ctx := setupEnrollmentCapacityTest(t)
+	defer mock.Close()
+
+	// Waitlisted, frozen, inactive and dropped enrollments are left out
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT e.student_id FROM enrollments e JOIN students s ON s.id = e.student_id AND s.college_id = e.college_id WHERE e.college_id = $1 AND e.course_id = $2 AND e.student_id IN ($3,$4) AND s.is_active = $5 AND LOWER(e.status) IN ($6, $7)`)).
+		WithArgs(1, 10, 7, 8, true, models.Active, models.Completed).
+		WillReturnRows(pgxmock.NewRows([]string{"student_id"}).AddRow(7))
+
+	enrolled, err := repo.FindEnrolledActiveStudentIDs(ctx, 1, 10, []int{7, 8})
+	require.NoError(t, err)
+	assert.Equal(t, map[int]bool{7: true}, enrolled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
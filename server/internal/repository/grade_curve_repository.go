@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+)
+
+const gradeCurveTable = "grade_curves"
+
+var gradeCurveQueryFields = []string{
+	"id", "college_id", "course_id", "term_id", "version", "method", "bands", "cutoffs", "mean::float8 AS mean",
+	"std_dev::float8 AS std_dev", "distribution", "is_locked", "committed_by", "committed_at", "unlocked_by",
+	"unlocked_at", "unlock_reason",
+}
+
+var (
+	ErrGradeCurveNotFound = errors.New("grade curve not found")
+	ErrGradesLocked       = errors.New("course grades are locked by a committed curve")
+	ErrScoresChanged      = errors.New("course scores changed while the curve was computed")
+)
+
+type GradeCurveRepository interface {
+	// ScoresVersion fingerprints what the course's percentages in termID are
+	// computed from: its assessments, their scores and its enrollments.
+	ScoresVersion(ctx context.Context, courseID int, termID *int) (string, error)
+	// CommitCurve stores the curve as the next version for its course and
+	// term, locked, and writes each student's letter to their grades in the
+	// course's assessments. It fails with ErrGradesLocked if a locked
+	// version exists already, and with ErrScoresChanged unless the course's
+	// ScoresVersion is still scoresVersion, the one grades were computed on.
+	CommitCurve(ctx context.Context, curve *models.GradeCurve, grades []*models.CurvedGrade, scoresVersion string) error
+	// GetLockedCurve returns the course's locked curve in termID, or fails
+	// with ErrGradeCurveNotFound.
+	GetLockedCurve(ctx context.Context, collegeID int, courseID int, termID *int) (*models.GradeCurve, error)
+	// FindCurves lists the course's curve versions in termID, newest first.
+	FindCurves(ctx context.Context, collegeID int, courseID int, termID *int) ([]*models.GradeCurve, error)
+	// UnlockCurve unlocks the course's locked curve in termID so its grades
+	// can change and a new version be committed. It fails with
+	// ErrResultsSubmitted unless the course's results there are a draft.
+	UnlockCurve(ctx context.Context, collegeID int, courseID int, termID *int, unlockedBy string, reason string) (*models.GradeCurve, error)
+}
+
+type gradeCurveRepository struct {
+	DB *DB
+}
+
+func NewGradeCurveRepository(db *DB) GradeCurveRepository {
+	return &gradeCurveRepository{DB: db}
+}
+
+// scoresVersionQuery hashes, for course $1 in term $2, everything its
+// percentages depend on, in a stable order.
+const scoresVersionQuery = `SELECT md5(concat_ws('|',
+	(SELECT string_agg(concat_ws(',', a.id, a.weight, a.max_marks), ';' ORDER BY a.id)
+		FROM ` + assessmentTable + ` a WHERE a.course_id = $1 AND a.term_id IS NOT DISTINCT FROM $2),
+	(SELECT string_agg(concat_ws(',', g.id, g.student_id, g.marks_obtained, g.total_marks), ';' ORDER BY g.id)
+		FROM ` + gradeTable + ` g JOIN ` + assessmentTable + ` a ON a.id = g.assessment_id
+		WHERE a.course_id = $1 AND a.term_id IS NOT DISTINCT FROM $2),
+	(SELECT string_agg(concat_ws(',', e.id, e.student_id, e.status), ';' ORDER BY e.id)
+		FROM ` + enrollmentTable + ` e WHERE e.course_id = $1)))`
+
+func (r *gradeCurveRepository) ScoresVersion(ctx context.Context, courseID int, termID *int) (string, error) {
+	version, err := fingerprintScores(ctx, r.DB.Pool, courseID, termID)
+	if err != nil {
+		return "", fmt.Errorf("ScoresVersion: %w", err)
+	}
+	return version, nil
+}
+
+func fingerprintScores(ctx context.Context, q Querier, courseID int, termID *int) (string, error) {
+	var version string
+	if err := q.QueryRow(ctx, scoresVersionQuery, courseID, termID).Scan(&version); err != nil {
+		return "", fmt.Errorf("failed to fingerprint scores: %w", err)
+	}
+	return version, nil
+}
+
+func (r *gradeCurveRepository) CommitCurve(ctx context.Context, curve *models.GradeCurve, grades []*models.CurvedGrade, scoresVersion string) error {
+	curve.CommittedAt = time.Now()
+	curve.IsLocked = true
+
+	bands, err := json.Marshal(curve.Bands)
+	if err != nil {
+		return fmt.Errorf("CommitCurve: failed to encode bands: %w", err)
+	}
+	cutoffs, err := json.Marshal(curve.Cutoffs)
+	if err != nil {
+		return fmt.Errorf("CommitCurve: failed to encode cutoffs: %w", err)
+	}
+	distribution, err := json.Marshal(curve.Distribution)
+	if err != nil {
+		return fmt.Errorf("CommitCurve: failed to encode distribution: %w", err)
+	}
+	nextVersion := squirrel.Expr("(SELECT COALESCE(MAX(version), 0) + 1 FROM "+gradeCurveTable+
+		" WHERE course_id = ? AND term_id IS NOT DISTINCT FROM ?)", curve.CourseID, curve.TermID)
+	sql, args, err := r.DB.SQ.Insert(gradeCurveTable).
+		Columns("college_id", "course_id", "term_id", "version", "method", "bands", "cutoffs", "mean", "std_dev",
+			"distribution", "is_locked", "committed_by", "committed_at").
+		Values(curve.CollegeID, curve.CourseID, curve.TermID, nextVersion, curve.Method,
+			squirrel.Expr("?::jsonb", string(bands)), squirrel.Expr("?::jsonb", string(cutoffs)), curve.Mean, curve.StdDev,
+			squirrel.Expr("?::jsonb", string(distribution)), curve.IsLocked, curve.CommittedBy, curve.CommittedAt).
+		Suffix("RETURNING id, version").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CommitCurve: failed to build query: %w", err)
+	}
+
+	students := make(map[string][]int)
+	for _, g := range grades {
+		students[g.GradeLetter] = append(students[g.GradeLetter], g.StudentID)
+	}
+
+	return r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if err := lockCourseAssessments(ctx, tx, curve.CourseID); err != nil {
+			return fmt.Errorf("CommitCurve: %w", err)
+		}
+		if err := checkUnlocked(ctx, tx, curve.CourseID, curve.TermID); err != nil {
+			return fmt.Errorf("CommitCurve: %w", err)
+		}
+		// The letters are only right for the scores they were computed on
+		current, err := fingerprintScores(ctx, tx, curve.CourseID, curve.TermID)
+		if err != nil {
+			return fmt.Errorf("CommitCurve: %w", err)
+		}
+		if current != scoresVersion {
+			return fmt.Errorf("CommitCurve: course %d: %w", curve.CourseID, ErrScoresChanged)
+		}
+		if err := tx.QueryRow(ctx, sql, args...).Scan(&curve.ID, &curve.Version); err != nil {
+			return fmt.Errorf("CommitCurve: failed to insert curve: %w", err)
+		}
+
+		for letter, ids := range students {
+			if err := writeGradeLetters(ctx, r.DB, tx, curve.CollegeID, curve.CourseID, curve.TermID, letter, ids, curve.CommittedAt); err != nil {
+				return fmt.Errorf("CommitCurve: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// writeGradeLetters sets the letter on the students' grades in the course's
+// assessments in the term. Callers hold the course's assessment lock.
+func writeGradeLetters(ctx context.Context, db *DB, q Querier, collegeID int, courseID int, termID *int, letter string, studentIDs []int, at time.Time) error {
+	sql, args, err := db.SQ.Update(gradeTable).
+		Set("grade_letter", letter).
+		Set("updated_at", at).
+		Where(squirrel.Eq{"college_id": collegeID, "course_id": courseID, "student_id": studentIDs}).
+		Where(squirrel.Expr("assessment_id IN (SELECT id FROM "+assessmentTable+
+			" WHERE course_id = ? AND term_id IS NOT DISTINCT FROM ?)", courseID, termID)).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build grade letter query: %w", err)
+	}
+	if _, err := q.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to write grade letters: %w", err)
+	}
+	return nil
+}
+
+func (r *gradeCurveRepository) GetLockedCurve(ctx context.Context, collegeID int, courseID int, termID *int) (*models.GradeCurve, error) {
+	sql, args, err := r.DB.SQ.Select(gradeCurveQueryFields...).
+		From(gradeCurveTable).
+		Where(squirrel.Eq{"college_id": collegeID, "course_id": courseID, "is_locked": true}).
+		Where(squirrel.Expr("term_id IS NOT DISTINCT FROM ?", termID)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("GetLockedCurve: failed to build query: %w", err)
+	}
+
+	curve := &models.GradeCurve{}
+	if err := pgxscan.Get(ctx, r.DB.Pool, curve, sql, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("GetLockedCurve: course %d: %w", courseID, ErrGradeCurveNotFound)
+		}
+		return nil, fmt.Errorf("GetLockedCurve: failed to execute query or scan: %w", err)
+	}
+	return curve, nil
+}
+
+func (r *gradeCurveRepository) FindCurves(ctx context.Context, collegeID int, courseID int, termID *int) ([]*models.GradeCurve, error) {
+	sql, args, err := r.DB.SQ.Select(gradeCurveQueryFields...).
+		From(gradeCurveTable).
+		Where(squirrel.Eq{"college_id": collegeID, "course_id": courseID}).
+		Where(squirrel.Expr("term_id IS NOT DISTINCT FROM ?", termID)).
+		OrderBy("version DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindCurves: failed to build query: %w", err)
+	}
+
+	curves := []*models.GradeCurve{}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &curves, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindCurves: failed to execute query or scan: %w", err)
+	}
+	return curves, nil
+}
+
+func (r *gradeCurveRepository) UnlockCurve(ctx context.Context, collegeID int, courseID int, termID *int, unlockedBy string, reason string) (*models.GradeCurve, error) {
+	sql, args, err := r.DB.SQ.Update(gradeCurveTable).
+		Set("is_locked", false).
+		Set("unlocked_by", unlockedBy).
+		Set("unlocked_at", time.Now()).
+		Set("unlock_reason", reason).
+		Where(squirrel.Eq{"college_id": collegeID, "course_id": courseID, "is_locked": true}).
+		Where(squirrel.Expr("term_id IS NOT DISTINCT FROM ?", termID)).
+		Suffix("RETURNING " + strings.Join(gradeCurveQueryFields, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("UnlockCurve: failed to build query: %w", err)
+	}
+
+	curve := &models.GradeCurve{}
+	err = r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if err := lockCourseAssessments(ctx, tx, courseID); err != nil {
+			return err
+		}
+		// Submitted results were graded on the curve, so it stays until they are returned
+		if err := checkDraft(ctx, tx, courseID, termID); err != nil {
+			return err
+		}
+		return pgxscan.Get(ctx, tx, curve, sql, args...)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("UnlockCurve: no locked curve for course %d: %w", courseID, ErrGradeCurveNotFound)
+		}
+		return nil, fmt.Errorf("UnlockCurve: %w", err)
+	}
+	return curve, nil
+}
+
+// checkUnlocked fails with ErrGradesLocked if the course has a locked curve
+// in the term, or with ErrResultsSubmitted if its results there are past the
+// draft. Callers hold the course's assessment lock.
+func checkUnlocked(ctx context.Context, q Querier, courseID int, termID *int) error {
+	var locked bool
+	err := q.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM "+gradeCurveTable+
+		" WHERE course_id = $1 AND term_id IS NOT DISTINCT FROM $2 AND is_locked)", courseID, termID).Scan(&locked)
+	if err != nil {
+		return fmt.Errorf("failed to check grade lock: %w", err)
+	}
+	if locked {
+		return ErrGradesLocked
+	}
+	return checkDraft(ctx, q, courseID, termID)
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupGradeCurveTest(t *testing.T) (pgxmock.PgxPoolIface, *gradeCurveRepository, context.Context) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	repo := &gradeCurveRepository{DB: &DB{Pool: mock, SQ: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}}
+	return mock, repo, context.Background()
+}
+
+func expectLockCourseAssessments(mock pgxmock.PgxPoolIface, courseID int) {
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock(hashtext('course_assessments'), $1)`)).
+		WithArgs(courseID).
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+}
+
+func TestCommitCurveScoresChanged(t *testing.T) {
+	mock, repo, ctx := setupGradeCurveTest(t)
+	defer mock.Close()
+	curve := &models.GradeCurve{CollegeID: 1, CourseID: 10, TermID: intPtr(3), Method: models.CurveFixed}
+
+	mock.ExpectBegin()
+	expectLockCourseAssessments(mock, 10)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM grade_curves`)).
+		WithArgs(10, intPtr(3)).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM result_publications`)).
+		WithArgs(10, intPtr(3), models.ResultsDraft).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+	// A score saved since the preview changes the fingerprint
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT md5(`)).
+		WithArgs(10, intPtr(3)).
+		WillReturnRows(pgxmock.NewRows([]string{"md5"}).AddRow("v2"))
+	mock.ExpectRollback()
+
+	err := repo.CommitCurve(ctx, curve, []*models.CurvedGrade{{StudentID: 1, GradeLetter: "A"}}, "v1")
+	assert.ErrorIs(t, err, ErrScoresChanged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnlockCurveResultsSubmitted(t *testing.T) {
+	mock, repo, ctx := setupGradeCurveTest(t)
+	defer mock.Close()
+
+	mock.ExpectBegin()
+	expectLockCourseAssessments(mock, 10)
+	// Submitted results were graded on the curve, so it stays locked
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM result_publications`)).
+		WithArgs(10, intPtr(3), models.ResultsDraft).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	_, err := repo.UnlockCurve(ctx, 1, 10, intPtr(3), "admin-1", "Wrong cutoffs")
+	assert.ErrorIs(t, err, ErrResultsSubmitted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
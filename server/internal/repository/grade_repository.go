@@ -16,11 +16,13 @@ import (
 const gradeTable = "grades"
 
 var gradeQueryFields = []string{
-	"id", "student_id", "course_id", "college_id", "marks_obtained", "total_marks",
+	"id", "student_id", "course_id", "college_id", "term_id", "assessment_id", "marks_obtained", "total_marks",
 	"grade_letter", "semester", "academic_year", "exam_type", "graded_at",
 	"comments", "created_at", "updated_at",
 }
 
+var ErrGradeNotFound = errors.New("grade not found")
+
 type GradeRepository interface {
 	CreateGrade(ctx context.Context, grade *models.Grade) error
 	GetGradeByID(ctx context.Context, gradeID int, collegeID int) (*models.Grade, error)
@@ -31,6 +33,13 @@ type GradeRepository interface {
 	// or more complex queries. For now, GetGrades with filters can serve many needs.
 	GetGradesByCourse(ctx context.Context, collegeID int, courseID int) ([]*models.Grade, error)
 	GetGradesByStudent(ctx context.Context, collegeID int, studentID int) ([]*models.Grade, error)
+	// FindGradeRows returns the grades matching filter, like GetGrades, with
+	// each student's roll number and name and the course's name, ordered by
+	// course, roll number and assessment.
+	FindGradeRows(ctx context.Context, filter models.GradeFilter) ([]*models.GradeRow, error)
+	// GetBestFinalPercentages returns, per student and course, the best
+	// final-exam percentage each student has in each of courseIDs.
+	GetBestFinalPercentages(ctx context.Context, collegeID int, studentIDs []int, courseIDs []int) (map[int]map[int]float64, error)
 }
 
 type gradeRepository struct {
@@ -51,23 +60,23 @@ func (r *gradeRepository) CreateGrade(ctx context.Context, grade *models.Grade)
 
 	query := r.DB.SQ.Insert(gradeTable).
 		Columns(
-			"student_id", "course_id", "college_id", "marks_obtained", "total_marks",
+			"student_id", "course_id", "college_id", "term_id", "assessment_id", "marks_obtained", "total_marks",
 			"grade_letter", "semester", "academic_year", "exam_type", "graded_at",
 			"comments", "created_at", "updated_at",
 		).
 		Values(
-			grade.StudentID, grade.CourseID, grade.CollegeID, grade.MarksObtained, grade.TotalMarks,
+			grade.StudentID, grade.CourseID, grade.CollegeID, termOrDefault(grade.TermID, currentTerm(grade.CollegeID)), grade.AssessmentID, grade.MarksObtained, grade.TotalMarks,
 			grade.GradeLetter, grade.Semester, grade.AcademicYear, grade.ExamType, grade.GradedAt,
 			grade.Comments, grade.CreatedAt, grade.UpdatedAt,
 		).
-		Suffix("RETURNING id")
+		Suffix("RETURNING id, term_id")
 
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return fmt.Errorf("CreateGrade: failed to build query: %w", err)
 	}
 
-	err = r.DB.Pool.QueryRow(ctx, sql, args...).Scan(&grade.ID)
+	err = r.DB.Pool.QueryRow(ctx, sql, args...).Scan(&grade.ID, &grade.TermID)
 	if err != nil {
 		// Consider specific error handling for duplicate entries or foreign key violations
 		return fmt.Errorf("CreateGrade: failed to execute query or scan ID: %w", err)
@@ -89,7 +98,7 @@ func (r *gradeRepository) GetGradeByID(ctx context.Context, gradeID int, college
 	err = pgxscan.Get(ctx, r.DB.Pool, grade, sql, args...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("GetGradeByID: grade with ID %d for college ID %d not found: %w", gradeID, collegeID, err)
+			return nil, fmt.Errorf("GetGradeByID: grade with ID %d for college ID %d: %w", gradeID, collegeID, ErrGradeNotFound)
 		}
 		return nil, fmt.Errorf("GetGradeByID: failed to execute query or scan: %w", err)
 	}
@@ -112,6 +121,9 @@ func (r *gradeRepository) UpdateGrade(ctx context.Context, grade *models.Grade)
 		Set("comments", grade.Comments).
 		Set("updated_at", grade.UpdatedAt).
 		Where(squirrel.Eq{"id": grade.ID, "college_id": grade.CollegeID}) // Ensure update is for the correct college
+	if grade.TermID != nil {
+		query = query.Set("term_id", *grade.TermID)
+	}
 
 	sql, args, err := query.ToSql()
 	if err != nil {
@@ -155,23 +167,7 @@ func (r *gradeRepository) GetGrades(ctx context.Context, filter models.GradeFilt
 	if filter.CollegeID == nil {
 		return nil, errors.New("GetGrades: CollegeID filter is required")
 	}
-	query = query.Where(squirrel.Eq{"college_id": *filter.CollegeID})
-
-	if filter.StudentID != nil {
-		query = query.Where(squirrel.Eq{"student_id": *filter.StudentID})
-	}
-	if filter.CourseID != nil {
-		query = query.Where(squirrel.Eq{"course_id": *filter.CourseID})
-	}
-	if filter.Semester != nil {
-		query = query.Where(squirrel.Eq{"semester": *filter.Semester})
-	}
-	if filter.AcademicYear != nil {
-		query = query.Where(squirrel.Eq{"academic_year": *filter.AcademicYear})
-	}
-	if filter.ExamType != "" {
-		query = query.Where(squirrel.Eq{"exam_type": filter.ExamType})
-	}
+	query = filterGrades(query, filter, gradeTable+".")
 
 	// For progress tracking, you might want to order by academic_year, semester, graded_at
 	query = query.OrderBy("academic_year ASC", "semester ASC", "graded_at ASC") // Default ordering
@@ -200,9 +196,8 @@ func (r *gradeRepository) GetGrades(ctx context.Context, filter models.GradeFilt
 }
 
 func (r *gradeRepository) GetGradesByStudent(ctx context.Context, collegeID int, studentID int) ([]*models.Grade, error) {
-	studentIDStr := string(studentID)
 	filter := models.GradeFilter{
-		StudentID: &studentIDStr,
+		StudentID: &studentID,
 		CollegeID: &collegeID,
 	}
 	return r.GetGrades(ctx, filter)
@@ -216,3 +211,108 @@ func (r *gradeRepository) GetGradesByCourse(ctx context.Context, collegeID int,
 	}
 	return r.GetGrades(ctx, filter)
 }
+
+func (r *gradeRepository) GetBestFinalPercentages(ctx context.Context, collegeID int, studentIDs []int, courseIDs []int) (map[int]map[int]float64, error) {
+	best := make(map[int]map[int]float64, len(studentIDs))
+	if len(studentIDs) == 0 || len(courseIDs) == 0 {
+		return best, nil
+	}
+	sql, args, err := r.DB.SQ.Select("student_id", "course_id", "MAX(marks_obtained * 100.0 / total_marks)::float8 AS percentage").
+		From(gradeTable).
+		Where(squirrel.Eq{
+			"college_id": collegeID,
+			"exam_type":  models.Final,
+			"student_id": studentIDs,
+			"course_id":  courseIDs,
+		}).
+		Where("total_marks > 0").
+		GroupBy("student_id", "course_id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("GetBestFinalPercentages: failed to build query: %w", err)
+	}
+
+	rows := []struct {
+		StudentID  int     `db:"student_id"`
+		CourseID   int     `db:"course_id"`
+		Percentage float64 `db:"percentage"`
+	}{}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &rows, sql, args...); err != nil {
+		return nil, fmt.Errorf("GetBestFinalPercentages: failed to execute query or scan: %w", err)
+	}
+	for _, row := range rows {
+		if best[row.StudentID] == nil {
+			best[row.StudentID] = make(map[int]float64)
+		}
+		best[row.StudentID][row.CourseID] = row.Percentage
+	}
+	return best, nil
+}
+
+func (r *gradeRepository) FindGradeRows(ctx context.Context, filter models.GradeFilter) ([]*models.GradeRow, error) {
+	if filter.CollegeID == nil {
+		return nil, errors.New("FindGradeRows: CollegeID filter is required")
+	}
+	fields := make([]string, 0, len(gradeQueryFields)+3)
+	for _, f := range gradeQueryFields {
+		fields = append(fields, "g."+f)
+	}
+	fields = append(fields, "s.roll_no", "COALESCE(u.name, '') AS student_name", "c.name AS course_name")
+
+	query := r.DB.SQ.Select(fields...).
+		From(gradeTable + " g").
+		Join(studentTable + " s ON s.id = g.student_id").
+		LeftJoin(userTable + " u ON u.id = s.user_id").
+		Join(courseTable + " c ON c.id = g.course_id")
+	query = filterGrades(query, filter, "g.").
+		OrderBy("c.name ASC", "s.roll_no ASC", "g.assessment_id ASC", "g.graded_at ASC")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindGradeRows: failed to build query: %w", err)
+	}
+
+	rows := []*models.GradeRow{}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &rows, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindGradeRows: failed to execute query or scan: %w", err)
+	}
+	return rows, nil
+}
+
+// filterGrades applies filter to a query on grades, whose columns are
+// qualified with prefix.
+func filterGrades(query squirrel.SelectBuilder, filter models.GradeFilter, prefix string) squirrel.SelectBuilder {
+	query = query.Where(squirrel.Eq{prefix + "college_id": *filter.CollegeID})
+	if filter.StudentID != nil {
+		query = query.Where(squirrel.Eq{prefix + "student_id": *filter.StudentID})
+	}
+	if filter.CourseID != nil {
+		query = query.Where(squirrel.Eq{prefix + "course_id": *filter.CourseID})
+	}
+	if filter.AssessmentID != nil {
+		query = query.Where(squirrel.Eq{prefix + "assessment_id": *filter.AssessmentID})
+	}
+	if filter.TermID != nil {
+		query = query.Where(squirrel.Eq{prefix + "term_id": *filter.TermID})
+	}
+	if filter.Semester != nil {
+		query = query.Where(squirrel.Eq{prefix + "semester": *filter.Semester})
+	}
+	if filter.AcademicYear != nil {
+		query = query.Where(squirrel.Eq{prefix + "academic_year": *filter.AcademicYear})
+	}
+	if filter.ExamType != "" {
+		query = query.Where(squirrel.Eq{prefix + "exam_type": filter.ExamType})
+	}
+	if filter.PublishedOnly {
+		query = query.Where(squirrel.Expr("EXISTS (SELECT 1 FROM "+resultPublicationTable+" p WHERE p.course_id = "+prefix+
+			"course_id AND p.term_id IS NOT DISTINCT FROM "+prefix+"term_id AND p.status = ?)", models.ResultsPublished))
+	}
+	return query
+}
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+const gradingScaleTable = "grading_scales"
+
+var gradingScaleQueryFields = []string{
+	"id", "college_id", "name", "method", "pass_percentage::float8 AS pass_percentage", "bands", "is_default",
+	"created_at", "updated_at",
+}
+
+var (
+	ErrGradingScaleNotFound = errors.New("grading scale not found")
+	ErrGradingScaleExists   = errors.New("college already has a grading scale with that name")
+)
+
+type GradingScaleRepository interface {
+	// CreateGradingScale stores the scale. If it is the default, the
+	// college's previous default stops being one.
+	CreateGradingScale(ctx context.Context, scale *models.GradingScale) error
+	GetGradingScale(ctx context.Context, collegeID int, scaleID int) (*models.GradingScale, error)
+	// GetDefaultGradingScale fails with ErrGradingScaleNotFound if the
+	// college has no default.
+	GetDefaultGradingScale(ctx context.Context, collegeID int) (*models.GradingScale, error)
+	UpdateGradingScale(ctx context.Context, scale *models.GradingScale) error
+	DeleteGradingScale(ctx context.Context, collegeID int, scaleID int) error
+	FindGradingScales(ctx context.Context, collegeID int) ([]*models.GradingScale, error)
+}
+
+type gradingScaleRepository struct {
+	DB *DB
+}
+
+func NewGradingScaleRepository(db *DB) GradingScaleRepository {
+	return &gradingScaleRepository{DB: db}
+}
+
+func (r *gradingScaleRepository) CreateGradingScale(ctx context.Context, scale *models.GradingScale) error {
+	now := time.Now()
+	scale.CreatedAt = now
+	scale.UpdatedAt = now
+
+	bands, err := json.Marshal(scale.Bands)
+	if err != nil {
+		return fmt.Errorf("CreateGradingScale: failed to encode bands: %w", err)
+	}
+	sql, args, err := r.DB.SQ.Insert(gradingScaleTable).
+		Columns("college_id", "name", "method", "pass_percentage", "bands", "is_default", "created_at", "updated_at").
+		Values(scale.CollegeID, scale.Name, scale.Method, scale.PassPercentage, squirrel.Expr("?::jsonb", string(bands)),
+			scale.IsDefault, scale.CreatedAt, scale.UpdatedAt).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CreateGradingScale: failed to build query: %w", err)
+	}
+
+	return r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if scale.IsDefault {
+			if err := r.clearDefault(ctx, tx, scale.CollegeID, 0); err != nil {
+				return fmt.Errorf("CreateGradingScale: %w", err)
+			}
+		}
+		if err := tx.QueryRow(ctx, sql, args...).Scan(&scale.ID); err != nil {
+			return fmt.Errorf("CreateGradingScale: %w", gradingScaleError(err))
+		}
+		return nil
+	})
+}
+
+func (r *gradingScaleRepository) GetGradingScale(ctx context.Context, collegeID int, scaleID int) (*models.GradingScale, error) {
+	sql, args, err := r.DB.SQ.Select(gradingScaleQueryFields...).
+		From(gradingScaleTable).
+		Where(squirrel.Eq{"id": scaleID, "college_id": collegeID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("GetGradingScale: failed to build query: %w", err)
+	}
+
+	scale := &models.GradingScale{}
+	if err := pgxscan.Get(ctx, r.DB.Pool, scale, sql, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("GetGradingScale: scale %d for college ID %d: %w", scaleID, collegeID, ErrGradingScaleNotFound)
+		}
+		return nil, fmt.Errorf("GetGradingScale: failed to execute query or scan: %w", err)
+	}
+	return scale, nil
+}
+
+func (r *gradingScaleRepository) GetDefaultGradingScale(ctx context.Context, collegeID int) (*models.GradingScale, error) {
+	sql, args, err := r.DB.SQ.Select(gradingScaleQueryFields...).
+		From(gradingScaleTable).
+		Where(squirrel.Eq{"college_id": collegeID, "is_default": true}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("GetDefaultGradingScale: failed to build query: %w", err)
+	}
+
+	scale := &models.GradingScale{}
+	if err := pgxscan.Get(ctx, r.DB.Pool, scale, sql, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("GetDefaultGradingScale: college ID %d: %w", collegeID, ErrGradingScaleNotFound)
+		}
+		return nil, fmt.Errorf("GetDefaultGradingScale: failed to execute query or scan: %w", err)
+	}
+	return scale, nil
+}
+
+func (r *gradingScaleRepository) UpdateGradingScale(ctx context.Context, scale *models.GradingScale) error {
+	scale.UpdatedAt = time.Now()
+
+	bands, err := json.Marshal(scale.Bands)
+	if err != nil {
+		return fmt.Errorf("UpdateGradingScale: failed to encode bands: %w", err)
+	}
+	sql, args, err := r.DB.SQ.Update(gradingScaleTable).
+		Set("name", scale.Name).
+		Set("method", scale.Method).
+		Set("pass_percentage", scale.PassPercentage).
+		Set("bands", squirrel.Expr("?::jsonb", string(bands))).
+		Set("is_default", scale.IsDefault).
+		Set("updated_at", scale.UpdatedAt).
+		Where(squirrel.Eq{"id": scale.ID, "college_id": scale.CollegeID}).
+		Suffix("RETURNING created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("UpdateGradingScale: failed to build query: %w", err)
+	}
+
+	return r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if scale.IsDefault {
+			if err := r.clearDefault(ctx, tx, scale.CollegeID, scale.ID); err != nil {
+				return fmt.Errorf("UpdateGradingScale: %w", err)
+			}
+		}
+		if err := tx.QueryRow(ctx, sql, args...).Scan(&scale.CreatedAt); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("UpdateGradingScale: scale %d for college ID %d: %w", scale.ID, scale.CollegeID, ErrGradingScaleNotFound)
+			}
+			return fmt.Errorf("UpdateGradingScale: %w", gradingScaleError(err))
+		}
+		return nil
+	})
+}
+
+func (r *gradingScaleRepository) DeleteGradingScale(ctx context.Context, collegeID int, scaleID int) error {
+	sql, args, err := r.DB.SQ.Delete(gradingScaleTable).
+		Where(squirrel.Eq{"id": scaleID, "college_id": collegeID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("DeleteGradingScale: failed to build query: %w", err)
+	}
+
+	commandTag, err := r.DB.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("DeleteGradingScale: failed to execute query: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("DeleteGradingScale: scale %d for college ID %d: %w", scaleID, collegeID, ErrGradingScaleNotFound)
+	}
+	return nil
+}
+
+func (r *gradingScaleRepository) FindGradingScales(ctx context.Context, collegeID int) ([]*models.GradingScale, error) {
+	sql, args, err := r.DB.SQ.Select(gradingScaleQueryFields...).
+		From(gradingScaleTable).
+		Where(squirrel.Eq{"college_id": collegeID}).
+		OrderBy("is_default DESC", "name ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindGradingScales: failed to build query: %w", err)
+	}
+
+	scales := []*models.GradingScale{}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &scales, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindGradingScales: failed to execute query or scan: %w", err)
+	}
+	return scales, nil
+}
+
+// clearDefault unsets the college's default scale, unless it is exceptID.
+func (r *gradingScaleRepository) clearDefault(ctx context.Context, tx pgx.Tx, collegeID int, exceptID int) error {
+	sql, args, err := r.DB.SQ.Update(gradingScaleTable).
+		Set("is_default", false).
+		Where(squirrel.Eq{"college_id": collegeID, "is_default": true}).
+		Where(squirrel.NotEq{"id": exceptID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build clear default query: %w", err)
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to clear default scale: %w", err)
+	}
+	return nil
+}
+
+// gradingScaleError maps constraint violations on grading_scales to our
+// errors.
+func gradingScaleError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_grading_scales_name" {
+		return ErrGradingScaleExists
+	}
+	return fmt.Errorf("failed to execute query or scan: %w", err)
+}
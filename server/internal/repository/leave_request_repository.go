@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+)
+
+var ErrLeaveRequestNotFound = errors.New("leave request not found")
+
+type LeaveRequestRepository interface {
+	// CreateLeaveRequest stores the request and its attachments together.
+	CreateLeaveRequest(ctx context.Context, req *models.LeaveRequest) error
+	GetLeaveRequestByID(ctx context.Context, collegeID int, requestID int) (*models.LeaveRequest, error)
+	FindLeaveRequestsByStudent(ctx context.Context, collegeID int, studentID int, limit, offset uint64) ([]*models.LeaveRequest, error)
+	// FindLeaveRequests lists a college's requests, optionally filtered by status.
+	FindLeaveRequests(ctx context.Context, collegeID int, status string, limit, offset uint64) ([]*models.LeaveRequest, error)
+
+	// UpdateLeaveRequestStatus moves a request from one status to another and
+	// records who did it. It returns false if the request was not in fromStatus.
+	UpdateLeaveRequestStatus(ctx context.Context, collegeID int, requestID int, fromStatus, toStatus string, reviewedBy *string, note *string) (bool, error)
+	SetExcusedLectures(ctx context.Context, collegeID int, requestID int, count int) error
+}
+
+const (
+	leaveRequestTable    = "leave_requests"
+	leaveAttachmentTable = "leave_request_attachments"
+)
+
+var leaveRequestQueryFields = []string{
+	"id", "college_id", "student_id", "leave_type", "start_date", "end_date", "reason", "status",
+	"reviewed_by", "review_note", "reviewed_at", "excused_lectures", "created_at", "updated_at",
+}
+
+type leaveRequestRepository struct {
+	DB *DB
+}
+
+func NewLeaveRequestRepository(db *DB) LeaveRequestRepository {
+	return &leaveRequestRepository{DB: db}
+}
+
+func (r *leaveRequestRepository) CreateLeaveRequest(ctx context.Context, req *models.LeaveRequest) error {
+	now := time.Now()
+	req.Status = models.LeaveStatusPending
+	req.CreatedAt = now
+	req.UpdatedAt = now
+
+	sql, args, err := r.DB.SQ.Insert(leaveRequestTable).
+		Columns("college_id", "student_id", "leave_type", "start_date", "end_date", "reason", "status", "created_at", "updated_at").
+		Values(req.CollegeID, req.StudentID, req.LeaveType, req.StartDate, req.EndDate, req.Reason, req.Status, req.CreatedAt, req.UpdatedAt).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CreateLeaveRequest: failed to build query: %w", err)
+	}
+
+	return r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, sql, args...).Scan(&req.ID); err != nil {
+			return fmt.Errorf("CreateLeaveRequest: failed to execute query or scan ID: %w", err)
+		}
+		if len(req.Attachments) == 0 {
+			return nil
+		}
+
+		query := r.DB.SQ.Insert(leaveAttachmentTable).
+			Columns("leave_request_id", "file_name", "file_url", "content_type", "created_at").
+			Suffix("RETURNING id")
+		for _, a := range req.Attachments {
+			a.LeaveRequestID = req.ID
+			a.CreatedAt = now
+			query = query.Values(a.LeaveRequestID, a.FileName, a.FileURL, a.ContentType, a.CreatedAt)
+		}
+		attSQL, attArgs, err := query.ToSql()
+		if err != nil {
+			return fmt.Errorf("CreateLeaveRequest: failed to build attachment query: %w", err)
+		}
+		rows, err := tx.Query(ctx, attSQL, attArgs...)
+		if err != nil {
+			return fmt.Errorf("CreateLeaveRequest: failed to insert attachments: %w", err)
+		}
+		defer rows.Close()
+		for i := 0; rows.Next(); i++ {
+			if err := rows.Scan(&req.Attachments[i].ID); err != nil {
+				return fmt.Errorf("CreateLeaveRequest: failed to scan attachment ID: %w", err)
+			}
+		}
+		return rows.Err()
+	})
+}
+
+func (r *leaveRequestRepository) GetLeaveRequestByID(ctx context.Context, collegeID int, requestID int) (*models.LeaveRequest, error) {
+	sql, args, err := r.DB.SQ.Select(leaveRequestQueryFields...).
+		From(leaveRequestTable).
+		Where(squirrel.Eq{"id": requestID, "college_id": collegeID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("GetLeaveRequestByID: failed to build query: %w", err)
+	}
+
+	req := &models.LeaveRequest{}
+	if err := pgxscan.Get(ctx, r.DB.Pool, req, sql, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("GetLeaveRequestByID: request %d for college ID %d: %w", requestID, collegeID, ErrLeaveRequestNotFound)
+		}
+		return nil, fmt.Errorf("GetLeaveRequestByID: failed to execute query or scan: %w", err)
+	}
+
+	if err := r.loadAttachments(ctx, []*models.LeaveRequest{req}); err != nil {
+		return nil, fmt.Errorf("GetLeaveRequestByID: %w", err)
+	}
+	return req, nil
+}
+
+func (r *leaveRequestRepository) FindLeaveRequestsByStudent(ctx context.Context, collegeID int, studentID int, limit, offset uint64) ([]*models.LeaveRequest, error) {
+	query := r.DB.SQ.Select(leaveRequestQueryFields...).
+		From(leaveRequestTable).
+		Where(squirrel.Eq{"college_id": collegeID, "student_id": studentID}).
+		OrderBy("start_date DESC", "id DESC").
+		Limit(limit).
+		Offset(offset)
+
+	requests, err := r.findLeaveRequests(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("FindLeaveRequestsByStudent: %w", err)
+	}
+	return requests, nil
+}
+
+func (r *leaveRequestRepository) FindLeaveRequests(ctx context.Context, collegeID int, status string, limit, offset uint64) ([]*models.LeaveRequest, error) {
+	query := r.DB.SQ.Select(leaveRequestQueryFields...).
+		From(leaveRequestTable).
+		Where(squirrel.Eq{"college_id": collegeID}).
+		OrderBy("created_at ASC", "id ASC").
+		Limit(limit).
+		Offset(offset)
+	if status != "" {
+		query = query.Where(squirrel.Eq{"status": status})
+	}
+
+	requests, err := r.findLeaveRequests(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("FindLeaveRequests: %w", err)
+	}
+	return requests, nil
+}
+
+func (r *leaveRequestRepository) findLeaveRequests(ctx context.Context, query squirrel.SelectBuilder) ([]*models.LeaveRequest, error) {
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	requests := []*models.LeaveRequest{}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &requests, sql, args...); err != nil {
+		return nil, fmt.Errorf("failed to execute query or scan: %w", err)
+	}
+	if err := r.loadAttachments(ctx, requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// loadAttachments fills in the attachments of every request with one query.
+func (r *leaveRequestRepository) loadAttachments(ctx context.Context, requests []*models.LeaveRequest) error {
+	if len(requests) == 0 {
+		return nil
+	}
+	byID := make(map[int]*models.LeaveRequest, len(requests))
+	ids := make([]int, 0, len(requests))
+	for _, req := range requests {
+		req.Attachments = []*models.LeaveAttachment{}
+		byID[req.ID] = req
+		ids = append(ids, req.ID)
+	}
+
+	sql, args, err := r.DB.SQ.Select("id", "leave_request_id", "file_name", "file_url", "content_type", "created_at").
+		From(leaveAttachmentTable).
+		Where(squirrel.Eq{"leave_request_id": ids}).
+		OrderBy("id ASC").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("loadAttachments: failed to build query: %w", err)
+	}
+
+	attachments := []*models.LeaveAttachment{}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &attachments, sql, args...); err != nil {
+		return fmt.Errorf("loadAttachments: failed to execute query or scan: %w", err)
+	}
+	for _, a := range attachments {
+		if req, ok := byID[a.LeaveRequestID]; ok {
+			req.Attachments = append(req.Attachments, a)
+		}
+	}
+	return nil
+}
+
+func (r *leaveRequestRepository) UpdateLeaveRequestStatus(ctx context.Context, collegeID int, requestID int, fromStatus, toStatus string, reviewedBy *string, note *string) (bool, error) {
+	now := time.Now()
+	query := r.DB.SQ.Update(leaveRequestTable).
+		Set("status", toStatus).
+		Set("updated_at", now).
+		Where(squirrel.Eq{"id": requestID, "college_id": collegeID, "status": fromStatus})
+	if reviewedBy != nil {
+		query = query.
+			Set("reviewed_by", *reviewedBy).
+			Set("review_note", note).
+			Set("reviewed_at", now)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("UpdateLeaveRequestStatus: failed to build query: %w", err)
+	}
+	commandTag, err := r.DB.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("UpdateLeaveRequestStatus: failed to execute query: %w", err)
+	}
+	return commandTag.RowsAffected() > 0, nil
+}
+
+func (r *leaveRequestRepository) SetExcusedLectures(ctx context.Context, collegeID int, requestID int, count int) error {
+	sql, args, err := r.DB.SQ.Update(leaveRequestTable).
+		Set("excused_lectures", count).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": requestID, "college_id": collegeID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("SetExcusedLectures: failed to build query: %w", err)
+	}
+	if _, err := r.DB.Pool.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("SetExcusedLectures: failed to execute query: %w", err)
+	}
+	return nil
+}
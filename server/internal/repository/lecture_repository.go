@@ -20,8 +20,15 @@ type LectureRepository interface {
 	DeleteLecture(ctx context.Context, collegeID int, lectureID int) error
 
 	// Finder methods
-	FindLecturesByCourse(ctx context.Context, collegeID int, courseID int, limit, offset uint64) ([]*models.Lecture, error)
-	CountLecturesByCourse(ctx context.Context, collegeID int, courseID int) (int, error)
+	// FindLecturesByCourse and CountLecturesByCourse only consider lectures in termID if it is set.
+	FindLecturesByCourse(ctx context.Context, collegeID int, courseID int, termID *int, limit, offset uint64) ([]*models.Lecture, error)
+	CountLecturesByCourse(ctx context.Context, collegeID int, courseID int, termID *int) (int, error)
+	// FindLecturesByCourseInRange returns lectures starting in [from, to); zero times leave that side open.
+	FindLecturesByCourseInRange(ctx context.Context, collegeID int, courseID int, from, to time.Time) ([]*models.Lecture, error)
+	// CreateGeneratedLectures inserts lectures generated from timetable
+	// blocks, skipping any whose block already has a lecture at that start
+	// time. It returns the lectures it created.
+	CreateGeneratedLectures(ctx context.Context, lectures []*models.Lecture) ([]*models.Lecture, error)
 	// Add more finders as needed, e.g., FindLecturesByDateRange, FindLecturesByInstructor (if lectures are directly linked to instructors)
 }
 
@@ -35,22 +42,29 @@ func NewLectureRepository(db *DB) LectureRepository {
 
 const lectureTable = "lectures"
 
+var ErrLectureNotFound = errors.New("lecture not found")
+
+var lectureQueryFields = []string{
+	"id", "course_id", "college_id", "term_id", "title", "description", "start_time", "end_time", "meeting_link",
+	"timetable_block_id", "latitude", "longitude", "geofence_radius_meters", "require_registered_device", "created_at", "updated_at",
+}
+
 func (r *lectureRepository) CreateLecture(ctx context.Context, lecture *models.Lecture) error {
 	now := time.Now()
 	lecture.CreatedAt = now
 	lecture.UpdatedAt = now
 
 	query := r.DB.SQ.Insert(lectureTable).
-		Columns("course_id", "college_id", "title", "description", "start_time", "end_time", "meeting_link", "created_at", "updated_at").
-		Values(lecture.CourseID, lecture.CollegeID, lecture.Title, lecture.Description, lecture.StartTime, lecture.EndTime, lecture.MeetingLink, lecture.CreatedAt, lecture.UpdatedAt).
-		Suffix("RETURNING id")
+		Columns("course_id", "college_id", "term_id", "title", "description", "start_time", "end_time", "meeting_link", "timetable_block_id", "latitude", "longitude", "geofence_radius_meters", "require_registered_device", "created_at", "updated_at").
+		Values(lecture.CourseID, lecture.CollegeID, termOrDefault(lecture.TermID, termOn(lecture.CollegeID, lecture.StartTime)), lecture.Title, lecture.Description, lecture.StartTime, lecture.EndTime, lecture.MeetingLink, lecture.TimetableBlockID, lecture.Latitude, lecture.Longitude, lecture.GeofenceRadiusMeters, lecture.RequireRegisteredDevice, lecture.CreatedAt, lecture.UpdatedAt).
+		Suffix("RETURNING id, term_id")
 
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return fmt.Errorf("CreateLecture: failed to build query: %w", err)
 	}
 
-	err = r.DB.Pool.QueryRow(ctx, sql, args...).Scan(&lecture.ID)
+	err = r.DB.Pool.QueryRow(ctx, sql, args...).Scan(&lecture.ID, &lecture.TermID)
 	if err != nil {
 		// Consider checking for specific DB errors like foreign key violations
 		return fmt.Errorf("CreateLecture: failed to execute query or scan ID: %w", err)
@@ -59,7 +73,7 @@ func (r *lectureRepository) CreateLecture(ctx context.Context, lecture *models.L
 }
 
 func (r *lectureRepository) GetLectureByID(ctx context.Context, collegeID int, lectureID int) (*models.Lecture, error) {
-	query := r.DB.SQ.Select("id", "course_id", "college_id", "title", "description", "start_time", "end_time", "meeting_link", "created_at", "updated_at").
+	query := r.DB.SQ.Select(lectureQueryFields...).
 		From(lectureTable).
 		Where(squirrel.Eq{"id": lectureID, "college_id": collegeID}) // Ensure lecture belongs to the specified college
 
@@ -72,7 +86,7 @@ func (r *lectureRepository) GetLectureByID(ctx context.Context, collegeID int, l
 	err = pgxscan.Get(ctx, r.DB.Pool, lecture, sql, args...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("GetLectureByID: lecture with ID %d not found for college ID %d", lectureID, collegeID)
+			return nil, fmt.Errorf("GetLectureByID: lecture with ID %d for college ID %d: %w", lectureID, collegeID, ErrLectureNotFound)
 		}
 		return nil, fmt.Errorf("GetLectureByID: failed to execute query or scan: %w", err)
 	}
@@ -88,7 +102,12 @@ func (r *lectureRepository) UpdateLecture(ctx context.Context, lecture *models.L
 		Set("start_time", lecture.StartTime).
 		Set("end_time", lecture.EndTime).
 		Set("meeting_link", lecture.MeetingLink).
+		Set("latitude", lecture.Latitude).
+		Set("longitude", lecture.Longitude).
+		Set("geofence_radius_meters", lecture.GeofenceRadiusMeters).
+		Set("require_registered_device", lecture.RequireRegisteredDevice).
 		Set("course_id", lecture.CourseID). // Allow course_id to be updated if necessary
+		Set("term_id", termOrDefault(lecture.TermID, termOn(lecture.CollegeID, lecture.StartTime))).
 		Set("updated_at", lecture.UpdatedAt).
 		Where(squirrel.Eq{"id": lecture.ID, "college_id": lecture.CollegeID}) // Ensure update is scoped
 
@@ -103,7 +122,7 @@ func (r *lectureRepository) UpdateLecture(ctx context.Context, lecture *models.L
 	}
 
 	if cmdTag.RowsAffected() == 0 {
-		return fmt.Errorf("UpdateLecture: no lecture found with ID %d for college ID %d, or no changes made", lecture.ID, lecture.CollegeID)
+		return fmt.Errorf("UpdateLecture: lecture with ID %d for college ID %d: %w", lecture.ID, lecture.CollegeID, ErrLectureNotFound)
 	}
 	return nil
 }
@@ -125,13 +144,13 @@ func (r *lectureRepository) DeleteLecture(ctx context.Context, collegeID int, le
 	}
 
 	if cmdTag.RowsAffected() == 0 {
-		return fmt.Errorf("DeleteLecture: no lecture found with ID %d for college ID %d, or already deleted", lectureID, collegeID)
+		return fmt.Errorf("DeleteLecture: lecture with ID %d for college ID %d: %w", lectureID, collegeID, ErrLectureNotFound)
 	}
 	return nil
 }
 
-func (r *lectureRepository) FindLecturesByCourse(ctx context.Context, collegeID int, courseID int, limit, offset uint64) ([]*models.Lecture, error) {
-	query := r.DB.SQ.Select("id", "course_id", "college_id", "title", "description", "start_time", "end_time", "meeting_link", "created_at", "updated_at").
+func (r *lectureRepository) FindLecturesByCourse(ctx context.Context, collegeID int, courseID int, termID *int, limit, offset uint64) ([]*models.Lecture, error) {
+	query := r.DB.SQ.Select(lectureQueryFields...).
 		From(lectureTable).
 		Where(squirrel.Eq{
 			"college_id": collegeID,
@@ -140,6 +159,9 @@ func (r *lectureRepository) FindLecturesByCourse(ctx context.Context, collegeID
 		OrderBy("start_time ASC"). // Order lectures chronologically
 		Limit(limit).
 		Offset(offset)
+	if termID != nil {
+		query = query.Where(squirrel.Eq{"term_id": *termID})
+	}
 
 	sql, args, err := query.ToSql()
 	if err != nil {
@@ -154,13 +176,16 @@ func (r *lectureRepository) FindLecturesByCourse(ctx context.Context, collegeID
 	return lectures, nil
 }
 
-func (r *lectureRepository) CountLecturesByCourse(ctx context.Context, collegeID int, courseID int) (int, error) {
+func (r *lectureRepository) CountLecturesByCourse(ctx context.Context, collegeID int, courseID int, termID *int) (int, error) {
 	query := r.DB.SQ.Select("COUNT(*)").
 		From(lectureTable).
 		Where(squirrel.Eq{
 			"college_id": collegeID,
 			"course_id":  courseID,
 		})
+	if termID != nil {
+		query = query.Where(squirrel.Eq{"term_id": *termID})
+	}
 
 	sql, args, err := query.ToSql()
 	if err != nil {
@@ -174,3 +199,62 @@ func (r *lectureRepository) CountLecturesByCourse(ctx context.Context, collegeID
 	}
 	return count, nil
 }
+
+func (r *lectureRepository) FindLecturesByCourseInRange(ctx context.Context, collegeID int, courseID int, from, to time.Time) ([]*models.Lecture, error) {
+	query := r.DB.SQ.Select(lectureQueryFields...).
+		From(lectureTable).
+		Where(squirrel.Eq{
+			"college_id": collegeID,
+			"course_id":  courseID,
+		}).
+		OrderBy("start_time ASC")
+	if !from.IsZero() {
+		query = query.Where(squirrel.GtOrEq{"start_time": from})
+	}
+	if !to.IsZero() {
+		query = query.Where(squirrel.Lt{"start_time": to})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindLecturesByCourseInRange: failed to build query: %w", err)
+	}
+
+	lectures := []*models.Lecture{}
+	err = pgxscan.Select(ctx, r.DB.Pool, &lectures, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("FindLecturesByCourseInRange: failed to execute query or scan: %w", err)
+	}
+	return lectures, nil
+}
+
+func (r *lectureRepository) CreateGeneratedLectures(ctx context.Context, lectures []*models.Lecture) ([]*models.Lecture, error) {
+	created := []*models.Lecture{}
+	err := r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		now := time.Now()
+		for _, lecture := range lectures {
+			sql, args, err := r.DB.SQ.Insert(lectureTable).
+				Columns("course_id", "college_id", "term_id", "title", "description", "start_time", "end_time", "timetable_block_id", "created_at", "updated_at").
+				Values(lecture.CourseID, lecture.CollegeID, termOrDefault(lecture.TermID, termOn(lecture.CollegeID, lecture.StartTime)), lecture.Title, lecture.Description, lecture.StartTime, lecture.EndTime, lecture.TimetableBlockID, now, now).
+				Suffix("ON CONFLICT (timetable_block_id, start_time) WHERE timetable_block_id IS NOT NULL DO NOTHING RETURNING id, term_id").
+				ToSql()
+			if err != nil {
+				return fmt.Errorf("failed to build query: %w", err)
+			}
+			err = tx.QueryRow(ctx, sql, args...).Scan(&lecture.ID, &lecture.TermID)
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue // Generated before
+			}
+			if err != nil {
+				return fmt.Errorf("failed to insert lecture: %w", err)
+			}
+			lecture.CreatedAt, lecture.UpdatedAt = now, now
+			created = append(created, lecture)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("CreateGeneratedLectures: %w", err)
+	}
+	return created, nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+)
+
+var (
+	ErrQRCodeNotIssued       = errors.New("qr code was not issued or is no longer active")
+	ErrQRCodeAlreadyRedeemed = errors.New("qr code already redeemed by this student")
+)
+
+// QRCodeRepository is the nonce ledger for attendance QR codes: it records every
+// issued token and every (student, token) redemption so replays can be rejected.
+type QRCodeRepository interface {
+	CreateQRCode(ctx context.Context, qrCode *models.QRCode) error
+	// CheckQRCode returns ErrQRCodeNotIssued for unknown nonces and
+	// ErrQRCodeAlreadyRedeemed when the student has already used the nonce.
+	// It does not redeem the nonce; AttendanceRepository.MarkScannedAttendance
+	// does, with the attendance it marks.
+	CheckQRCode(ctx context.Context, collegeID int, nonce string, studentID int) error
+}
+
+const (
+	qrCodeTable           = "qrcodes"
+	qrCodeRedemptionTable = "qrcode_redemptions"
+)
+
+type qrCodeRepository struct {
+	DB *DB
+}
+
+func NewQRCodeRepository(db *DB) QRCodeRepository {
+	return &qrCodeRepository{DB: db}
+}
+
+func (r *qrCodeRepository) CreateQRCode(ctx context.Context, qrCode *models.QRCode) error {
+	now := time.Now()
+	qrCode.CreatedAt = now
+	qrCode.UpdatedAt = now
+	qrCode.IsActive = true
+
+	query := r.DB.SQ.Insert(qrCodeTable).
+		Columns("student_id", "college_id", "course_id", "lecture_id", "qr_code_id", "issued_at", "expires_at", "is_active", "created_at", "updated_at").
+		Values(qrCode.StudentID, qrCode.CollegeID, qrCode.CourseID, qrCode.LectureID, qrCode.QRCodeID, qrCode.IssuedAt, qrCode.ExpiresAt, qrCode.IsActive, qrCode.CreatedAt, qrCode.UpdatedAt).
+		Suffix("RETURNING id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("CreateQRCode: failed to build query: %w", err)
+	}
+
+	err = r.DB.Pool.QueryRow(ctx, sql, args...).Scan(&qrCode.ID)
+	if err != nil {
+		return fmt.Errorf("CreateQRCode: failed to execute query or scan ID: %w", err)
+	}
+	return nil
+}
+
+func (r *qrCodeRepository) CheckQRCode(ctx context.Context, collegeID int, nonce string, studentID int) error {
+	if err := checkQRCode(ctx, r.DB, r.DB.Pool, collegeID, nonce, studentID); err != nil {
+		return fmt.Errorf("CheckQRCode: %w", err)
+	}
+	return nil
+}
+
+// checkQRCode fails with ErrQRCodeNotIssued or ErrQRCodeAlreadyRedeemed
+// unless the student can still redeem the nonce.
+func checkQRCode(ctx context.Context, db *DB, q Querier, collegeID int, nonce string, studentID int) error {
+	sql, args, err := db.SQ.Select().
+		Column(squirrel.Expr("EXISTS (SELECT 1 FROM "+qrCodeRedemptionTable+" r WHERE r.qrcode_id = q.id AND r.student_id = ?)", studentID)).
+		From(qrCodeTable + " q").
+		Where(squirrel.Eq{"q.qr_code_id": nonce, "q.college_id": collegeID, "q.is_active": true}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build lookup query: %w", err)
+	}
+	var redeemed bool
+	if err := q.QueryRow(ctx, sql, args...).Scan(&redeemed); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrQRCodeNotIssued
+		}
+		return fmt.Errorf("failed to execute lookup query: %w", err)
+	}
+	if redeemed {
+		return ErrQRCodeAlreadyRedeemed
+	}
+	return nil
+}
+
+// redeemQRCode records the student's one redemption of the nonce through q,
+// failing like checkQRCode if they cannot.
+func redeemQRCode(ctx context.Context, db *DB, q Querier, collegeID int, nonce string, studentID int) error {
+	issued := db.SQ.Select("id").
+		Column("?", studentID).
+		Column("college_id").
+		Column("?", time.Now()).
+		From(qrCodeTable).
+		Where(squirrel.Eq{
+			"qr_code_id": nonce,
+			"college_id": collegeID,
+			"is_active":  true,
+		})
+
+	query := db.SQ.Insert(qrCodeRedemptionTable).
+		Columns("qrcode_id", "student_id", "college_id", "redeemed_at").
+		Select(issued).
+		Suffix("ON CONFLICT (qrcode_id, student_id) DO NOTHING")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("redeemQRCode: failed to build query: %w", err)
+	}
+
+	commandTag, err := q.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("redeemQRCode: failed to execute query: %w", err)
+	}
+	if commandTag.RowsAffected() > 0 {
+		return nil
+	}
+
+	// Nothing was inserted: either the nonce is unknown or this student already used it.
+	if err := checkQRCode(ctx, db, q, collegeID, nonce, studentID); err != nil {
+		return err
+	}
+	return ErrQRCodeAlreadyRedeemed
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"eduhub/server/internal/models"
+)
+
+// MemoryQRCodeRepository is an in-process QRCodeRepository used by tests.
+// It is not shared across replicas and must not be used in production.
+type MemoryQRCodeRepository struct {
+	mu       sync.Mutex
+	nextID   int
+	issued   map[string]*models.QRCode
+	redeemed map[string]map[int]bool
+}
+
+func NewMemoryQRCodeRepository() *MemoryQRCodeRepository {
+	return &MemoryQRCodeRepository{
+		issued:   make(map[string]*models.QRCode),
+		redeemed: make(map[string]map[int]bool),
+	}
+}
+
+func (m *MemoryQRCodeRepository) CreateQRCode(ctx context.Context, qrCode *models.QRCode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.nextID++
+	qrCode.ID = m.nextID
+	qrCode.CreatedAt = now
+	qrCode.UpdatedAt = now
+	qrCode.IsActive = true
+
+	stored := *qrCode
+	m.issued[qrCode.QRCodeID] = &stored
+	return nil
+}
+
+func (m *MemoryQRCodeRepository) CheckQRCode(ctx context.Context, collegeID int, nonce string, studentID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	qrCode, ok := m.issued[nonce]
+	if !ok || !qrCode.IsActive || qrCode.CollegeID != collegeID {
+		return ErrQRCodeNotIssued
+	}
+	if m.redeemed[nonce][studentID] {
+		return ErrQRCodeAlreadyRedeemed
+	}
+	return nil
+}
+
+// RedeemQRCode stands in for the redemption MarkScannedAttendance records.
+func (m *MemoryQRCodeRepository) RedeemQRCode(ctx context.Context, collegeID int, nonce string, studentID int) error {
+	if err := m.CheckQRCode(ctx, collegeID, nonce, studentID); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.redeemed[nonce] == nil {
+		m.redeemed[nonce] = make(map[int]bool)
+	}
+	m.redeemed[nonce][studentID] = true
+	return nil
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+const reevaluationTable = "grade_reevaluations"
+
+var reevaluationQueryFields = []string{
+	"id", "college_id", "grade_id", "student_id", "course_id", "term_id", "reason", "status", "requested_by",
+	"previous_marks", "revised_marks", "resolved_by", "resolved_at", "resolution_note", "created_at",
+}
+
+var (
+	ErrReevaluationNotFound = errors.New("re-evaluation not found")
+	ErrReevaluationPending  = errors.New("grade already has a pending re-evaluation")
+	ErrReevaluationResolved = errors.New("re-evaluation is already resolved")
+)
+
+type ReevaluationRepository interface {
+	// CreateReevaluation fails with ErrReevaluationPending if the grade has
+	// an unresolved request.
+	CreateReevaluation(ctx context.Context, reevaluation *models.Reevaluation) error
+	GetReevaluation(ctx context.Context, collegeID int, reevaluationID int) (*models.Reevaluation, error)
+	// FindReevaluations pages through the college's requests, newest first,
+	// for the student unless studentID is 0 and with the status unless it
+	// is empty.
+	FindReevaluations(ctx context.Context, collegeID int, studentID int, status models.ReevaluationStatus, limit, offset uint64) ([]*models.Reevaluation, error)
+	// ResolveReevaluation records the pending request's outcome under the
+	// course's assessment lock. An accepted request also writes its revised
+	// marks to the grade, and result, the student's regraded course result,
+	// to their result row and the letters on their grades in the course; it
+	// fails with ErrScoresChanged unless the course's ScoresVersion is still
+	// scoresVersion, the one result was computed on. It fails with
+	// ErrReevaluationResolved if the request is not pending.
+	ResolveReevaluation(ctx context.Context, reevaluation *models.Reevaluation, result *models.CourseResult, scoresVersion string) error
+}
+
+type reevaluationRepository struct {
+	DB *DB
+}
+
+func NewReevaluationRepository(db *DB) ReevaluationRepository {
+	return &reevaluationRepository{DB: db}
+}
+
+func (r *reevaluationRepository) CreateReevaluation(ctx context.Context, reevaluation *models.Reevaluation) error {
+	reevaluation.CreatedAt = time.Now()
+	reevaluation.Status = models.ReevaluationPending
+
+	sql, args, err := r.DB.SQ.Insert(reevaluationTable).
+		Columns("college_id", "grade_id", "student_id", "course_id", "term_id", "reason", "status", "requested_by",
+			"previous_marks", "created_at").
+		Values(reevaluation.CollegeID, reevaluation.GradeID, reevaluation.StudentID, reevaluation.CourseID,
+			reevaluation.TermID, reevaluation.Reason, reevaluation.Status, reevaluation.RequestedBy,
+			reevaluation.PreviousMarks, reevaluation.CreatedAt).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CreateReevaluation: failed to build query: %w", err)
+	}
+
+	if err := r.DB.Pool.QueryRow(ctx, sql, args...).Scan(&reevaluation.ID); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_grade_reevaluations_pending" {
+			return fmt.Errorf("CreateReevaluation: grade %d: %w", reevaluation.GradeID, ErrReevaluationPending)
+		}
+		return fmt.Errorf("CreateReevaluation: failed to execute query or scan ID: %w", err)
+	}
+	return nil
+}
+
+func (r *reevaluationRepository) GetReevaluation(ctx context.Context, collegeID int, reevaluationID int) (*models.Reevaluation, error) {
+	sql, args, err := r.DB.SQ.Select(reevaluationQueryFields...).
+		From(reevaluationTable).
+		Where(squirrel.Eq{"id": reevaluationID, "college_id": collegeID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("GetReevaluation: failed to build query: %w", err)
+	}
+
+	reevaluation := &models.Reevaluation{}
+	if err := pgxscan.Get(ctx, r.DB.Pool, reevaluation, sql, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("GetReevaluation: re-evaluation %d: %w", reevaluationID, ErrReevaluationNotFound)
+		}
+		return nil, fmt.Errorf("GetReevaluation: failed to execute query or scan: %w", err)
+	}
+	return reevaluation, nil
+}
+
+func (r *reevaluationRepository) FindReevaluations(ctx context.Context, collegeID int, studentID int, status models.ReevaluationStatus, limit, offset uint64) ([]*models.Reevaluation, error) {
+	query := r.DB.SQ.Select(reevaluationQueryFields...).
+		From(reevaluationTable).
+		Where(squirrel.Eq{"college_id": collegeID}).
+		OrderBy("created_at DESC", "id DESC")
+	if studentID != 0 {
+		query = query.Where(squirrel.Eq{"student_id": studentID})
+	}
+	if status != "" {
+		query = query.Where(squirrel.Eq{"status": status})
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindReevaluations: failed to build query: %w", err)
+	}
+	reevaluations := []*models.Reevaluation{}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &reevaluations, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindReevaluations: failed to execute query or scan: %w", err)
+	}
+	return reevaluations, nil
+}
+
+func (r *reevaluationRepository) ResolveReevaluation(ctx context.Context, reevaluation *models.Reevaluation, result *models.CourseResult, scoresVersion string) error {
+	now := time.Now()
+	reevaluation.ResolvedAt = &now
+
+	sql, args, err := r.DB.SQ.Update(reevaluationTable).
+		Set("status", reevaluation.Status).
+		Set("revised_marks", reevaluation.RevisedMarks).
+		Set("resolved_by", reevaluation.ResolvedBy).
+		Set("resolved_at", reevaluation.ResolvedAt).
+		Set("resolution_note", reevaluation.ResolutionNote).
+		Where(squirrel.Eq{"id": reevaluation.ID, "college_id": reevaluation.CollegeID, "status": models.ReevaluationPending}).
+		Suffix("RETURNING " + strings.Join(reevaluationQueryFields, ", ")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ResolveReevaluation: failed to build query: %w", err)
+	}
+	accepted := reevaluation.Status == models.ReevaluationAccepted
+	var gradeSQL, resultSQL string
+	var gradeArgs, resultArgs []interface{}
+	if accepted {
+		gradeSQL, gradeArgs, err = r.DB.SQ.Update(gradeTable).
+			Set("marks_obtained", reevaluation.RevisedMarks).
+			Set("updated_at", now).
+			Where(squirrel.Eq{"id": reevaluation.GradeID, "college_id": reevaluation.CollegeID}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("ResolveReevaluation: failed to build grade query: %w", err)
+		}
+		result.ComputedAt = now
+		resultSQL, resultArgs, err = r.DB.SQ.Update(courseResultTable).
+			Set("percentage", result.Percentage).
+			Set("grade_letter", result.GradeLetter).
+			Set("grade_point", result.GradePoint).
+			Set("computed_at", result.ComputedAt).
+			Where(squirrel.Eq{
+				"college_id": reevaluation.CollegeID,
+				"student_id": reevaluation.StudentID,
+				"course_id":  reevaluation.CourseID,
+			}).
+			Where(squirrel.Expr("term_id IS NOT DISTINCT FROM ?", reevaluation.TermID)).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("ResolveReevaluation: failed to build result query: %w", err)
+		}
+	}
+
+	err = r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		// Published grades are otherwise frozen, so this is the only writer
+		if err := lockCourseAssessments(ctx, tx, reevaluation.CourseID); err != nil {
+			return err
+		}
+		if accepted {
+			version, err := fingerprintScores(ctx, tx, reevaluation.CourseID, reevaluation.TermID)
+			if err != nil {
+				return err
+			}
+			if version != scoresVersion {
+				return ErrScoresChanged
+			}
+		}
+		if err := pgxscan.Get(ctx, tx, reevaluation, sql, args...); err != nil {
+			return err
+		}
+		if !accepted {
+			return nil
+		}
+		if _, err := tx.Exec(ctx, gradeSQL, gradeArgs...); err != nil {
+			return fmt.Errorf("failed to revise grade: %w", err)
+		}
+		if _, err := tx.Exec(ctx, resultSQL, resultArgs...); err != nil {
+			return fmt.Errorf("failed to revise course result: %w", err)
+		}
+		return writeGradeLetters(ctx, r.DB, tx, reevaluation.CollegeID, reevaluation.CourseID, reevaluation.TermID,
+			result.GradeLetter, []int{reevaluation.StudentID}, now)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("ResolveReevaluation: re-evaluation %d: %w", reevaluation.ID, ErrReevaluationResolved)
+		}
+		return fmt.Errorf("ResolveReevaluation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveReevaluationScoresChanged(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+	repo := &reevaluationRepository{DB: &DB{Pool: mock, SQ: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}}
+	marks := 85.0
+	reevaluation := &models.Reevaluation{ID: 4, CollegeID: 1, GradeID: 100, StudentID: 7, CourseID: 10, TermID: intPtr(3),
+		Status: models.ReevaluationAccepted, RevisedMarks: &marks}
+
+	mock.ExpectBegin()
+	expectLockCourseAssessments(mock, 10)
+	// Another re-evaluation of the student was resolved since the result
+	// was computed, so it would be overwritten
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT md5(`)).
+		WithArgs(10, intPtr(3)).
+		WillReturnRows(pgxmock.NewRows([]string{"md5"}).AddRow("v2"))
+	mock.ExpectRollback()
+
+	err = repo.ResolveReevaluation(context.Background(), reevaluation,
+		&models.CourseResult{StudentID: 7, Percentage: 85, GradeLetter: "A+", GradePoint: 9}, "v1")
+	assert.ErrorIs(t, err, ErrScoresChanged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
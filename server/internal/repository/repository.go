@@ -1,18 +1,36 @@
 package repository
 
 type Repository struct {
-	AttendanceRepository AttendanceRepository
-	StudentRepository    StudentRepository
-	UserRepository       UserRepository
-	EnrollmentRepository EnrollmentRepository
-	PlacementRepository  PlacementRepository  // Added Placement
-	QuizRepository       QuizRepository       // Added Quiz
-	DepartmentRepository DepartmentRepository // Added Department
-	ProfileRepository    ProfileRepository    // Added Profile
-	CourseRepository     CourseRepository
-	LectureRepository    LectureRepository
-	CollegeRepository    CollegeRepository
-	GradeRepository      GradeRepository
+	AttendanceRepository         AttendanceRepository
+	StudentRepository            StudentRepository
+	UserRepository               UserRepository
+	EnrollmentRepository         EnrollmentRepository
+	PlacementRepository          PlacementRepository  // Added Placement
+	QuizRepository               QuizRepository       // Added Quiz
+	DepartmentRepository         DepartmentRepository // Added Department
+	ProfileRepository            ProfileRepository    // Added Profile
+	CourseRepository             CourseRepository
+	LectureRepository            LectureRepository
+	CollegeRepository            CollegeRepository
+	GradeRepository              GradeRepository
+	QRCodeRepository             QRCodeRepository
+	AttendanceScanRepository     AttendanceScanRepository
+	StudentDeviceRepository      StudentDeviceRepository
+	LeaveRequestRepository       LeaveRequestRepository
+	AttendancePolicyRepository   AttendancePolicyRepository
+	StudentSuspensionRepository  StudentSuspensionRepository
+	CoursePrerequisiteRepository CoursePrerequisiteRepository
+	AcademicTermRepository       AcademicTermRepository
+	TimeTableRepository          TimeTableRepository
+	TimetableDraftRepository     TimetableDraftRepository
+	CalendarRepository           CalendarRepository
+	CalendarFeedRepository       CalendarFeedRepository
+	AssessmentRepository         AssessmentRepository
+	GradingScaleRepository       GradingScaleRepository
+	ResultRepository             ResultRepository
+	GradeCurveRepository         GradeCurveRepository
+	ResultPublicationRepository  ResultPublicationRepository
+	ReevaluationRepository       ReevaluationRepository
 }
 
 // NewRepository creates a new repository with all required sub-repositories
@@ -31,18 +49,54 @@ func NewRepository(DB *DB) *Repository {
 	lectureRepo := NewLectureRepository(DB)
 	collegeRepo := NewCollegeRepository(DB)
 	gradeRepo := NewGradeRepository(DB)
+	qrCodeRepo := NewQRCodeRepository(DB)
+	attendanceScanRepo := NewAttendanceScanRepository(DB)
+	studentDeviceRepo := NewStudentDeviceRepository(DB)
+	leaveRequestRepo := NewLeaveRequestRepository(DB)
+	attendancePolicyRepo := NewAttendancePolicyRepository(DB)
+	studentSuspensionRepo := NewStudentSuspensionRepository(DB)
+	coursePrerequisiteRepo := NewCoursePrerequisiteRepository(DB)
+	academicTermRepo := NewAcademicTermRepository(DB)
+	timeTableRepo := NewTimeTableRepository(DB)
+	timetableDraftRepo := NewTimetableDraftRepository(DB)
+	calendarRepo := NewCalendarRepository(DB)
+	calendarFeedRepo := NewCalendarFeedRepository(DB)
+	assessmentRepo := NewAssessmentRepository(DB)
+	gradingScaleRepo := NewGradingScaleRepository(DB)
+	resultRepo := NewResultRepository(DB)
+	gradeCurveRepo := NewGradeCurveRepository(DB)
+	resultPublicationRepo := NewResultPublicationRepository(DB)
+	reevaluationRepo := NewReevaluationRepository(DB)
 	return &Repository{
-		AttendanceRepository: attendanceRepo,
-		StudentRepository:    studentRepo,
-		UserRepository:       userRepo,
-		EnrollmentRepository: enrollmentRepo,
-		PlacementRepository:  placementRepo,
-		QuizRepository:       quizRepo,
-		DepartmentRepository: departmentRepo,
-		ProfileRepository:    profileRepo,
-		CourseRepository:     courseRepo,
-		LectureRepository:    lectureRepo,
-		CollegeRepository:    collegeRepo,
-		GradeRepository:      gradeRepo,
+		AttendanceRepository:         attendanceRepo,
+		StudentRepository:            studentRepo,
+		UserRepository:               userRepo,
+		EnrollmentRepository:         enrollmentRepo,
+		PlacementRepository:          placementRepo,
+		QuizRepository:               quizRepo,
+		DepartmentRepository:         departmentRepo,
+		ProfileRepository:            profileRepo,
+		CourseRepository:             courseRepo,
+		LectureRepository:            lectureRepo,
+		CollegeRepository:            collegeRepo,
+		GradeRepository:              gradeRepo,
+		QRCodeRepository:             qrCodeRepo,
+		AttendanceScanRepository:     attendanceScanRepo,
+		StudentDeviceRepository:      studentDeviceRepo,
+		LeaveRequestRepository:       leaveRequestRepo,
+		AttendancePolicyRepository:   attendancePolicyRepo,
+		StudentSuspensionRepository:  studentSuspensionRepo,
+		CoursePrerequisiteRepository: coursePrerequisiteRepo,
+		AcademicTermRepository:       academicTermRepo,
+		TimeTableRepository:          timeTableRepo,
+		TimetableDraftRepository:     timetableDraftRepo,
+		CalendarRepository:           calendarRepo,
+		CalendarFeedRepository:       calendarFeedRepo,
+		AssessmentRepository:         assessmentRepo,
+		GradingScaleRepository:       gradingScaleRepo,
+		ResultRepository:             resultRepo,
+		GradeCurveRepository:         gradeCurveRepo,
+		ResultPublicationRepository:  resultPublicationRepo,
+		ReevaluationRepository:       reevaluationRepo,
 	}
 }
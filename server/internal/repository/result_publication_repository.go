@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+)
+
+const resultPublicationTable = "result_publications"
+
+var resultPublicationQueryFields = []string{
+	"id", "college_id", "course_id", "term_id", "status", "submitted_by", "submitted_at", "approved_by",
+	"approved_at", "published_by", "published_at", "bands", "returned_by", "returned_at", "return_reason",
+	"created_at", "updated_at",
+}
+
+var (
+	ErrPublicationChanged = errors.New("course results changed status meanwhile")
+	ErrResultsSubmitted   = errors.New("course results are submitted for publication")
+)
+
+type ResultPublicationRepository interface {
+	// GetPublication returns where the course's results in termID stand; a
+	// course without a publication gets a draft with no ID.
+	GetPublication(ctx context.Context, collegeID int, courseID int, termID *int) (*models.ResultPublication, error)
+	// FindPublications lists the college's publications in termID with the
+	// status, or any status if it is empty.
+	FindPublications(ctx context.Context, collegeID int, termID *int, status models.PublicationStatus) ([]*models.ResultPublication, error)
+	// FindPublished lists the college's published results in every term.
+	FindPublished(ctx context.Context, collegeID int) ([]*models.ResultPublication, error)
+	// SavePublication stores pub if its course's results are still in the
+	// from status, under the course's assessment lock, or fails with
+	// ErrPublicationChanged.
+	SavePublication(ctx context.Context, pub *models.ResultPublication, from models.PublicationStatus) error
+}
+
+type resultPublicationRepository struct {
+	DB *DB
+}
+
+func NewResultPublicationRepository(db *DB) ResultPublicationRepository {
+	return &resultPublicationRepository{DB: db}
+}
+
+func (r *resultPublicationRepository) GetPublication(ctx context.Context, collegeID int, courseID int, termID *int) (*models.ResultPublication, error) {
+	sql, args, err := r.DB.SQ.Select(resultPublicationQueryFields...).
+		From(resultPublicationTable).
+		Where(squirrel.Eq{"college_id": collegeID, "course_id": courseID}).
+		Where(squirrel.Expr("term_id IS NOT DISTINCT FROM ?", termID)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("GetPublication: failed to build query: %w", err)
+	}
+
+	pub := &models.ResultPublication{}
+	if err := pgxscan.Get(ctx, r.DB.Pool, pub, sql, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &models.ResultPublication{CollegeID: collegeID, CourseID: courseID, TermID: termID, Status: models.ResultsDraft}, nil
+		}
+		return nil, fmt.Errorf("GetPublication: failed to execute query or scan: %w", err)
+	}
+	return pub, nil
+}
+
+func (r *resultPublicationRepository) FindPublications(ctx context.Context, collegeID int, termID *int, status models.PublicationStatus) ([]*models.ResultPublication, error) {
+	query := r.DB.SQ.Select(resultPublicationQueryFields...).
+		From(resultPublicationTable).
+		Where(squirrel.Eq{"college_id": collegeID}).
+		Where(squirrel.Expr("term_id IS NOT DISTINCT FROM ?", termID)).
+		OrderBy("course_id ASC")
+	if status != "" {
+		query = query.Where(squirrel.Eq{"status": status})
+	}
+	return r.find(ctx, "FindPublications", query)
+}
+
+func (r *resultPublicationRepository) FindPublished(ctx context.Context, collegeID int) ([]*models.ResultPublication, error) {
+	query := r.DB.SQ.Select(resultPublicationQueryFields...).
+		From(resultPublicationTable).
+		Where(squirrel.Eq{"college_id": collegeID, "status": models.ResultsPublished}).
+		OrderBy("course_id ASC")
+	return r.find(ctx, "FindPublished", query)
+}
+
+func (r *resultPublicationRepository) find(ctx context.Context, method string, query squirrel.SelectBuilder) ([]*models.ResultPublication, error) {
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to build query: %w", method, err)
+	}
+	pubs := []*models.ResultPublication{}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &pubs, sql, args...); err != nil {
+		return nil, fmt.Errorf("%s: failed to execute query or scan: %w", method, err)
+	}
+	return pubs, nil
+}
+
+func (r *resultPublicationRepository) SavePublication(ctx context.Context, pub *models.ResultPublication, from models.PublicationStatus) error {
+	pub.UpdatedAt = time.Now()
+	if pub.ID == 0 {
+		pub.CreatedAt = pub.UpdatedAt
+	}
+
+	var bands *string
+	if pub.Bands != nil {
+		encoded, err := json.Marshal(pub.Bands)
+		if err != nil {
+			return fmt.Errorf("SavePublication: failed to encode bands: %w", err)
+		}
+		published := string(encoded)
+		bands = &published
+	}
+
+	// A draft may have no row yet; any other status must still be current
+	sql, args, err := r.DB.SQ.Insert(resultPublicationTable).
+		Columns("college_id", "course_id", "term_id", "status", "submitted_by", "submitted_at", "approved_by",
+			"approved_at", "published_by", "published_at", "bands", "returned_by", "returned_at", "return_reason",
+			"created_at", "updated_at").
+		Values(pub.CollegeID, pub.CourseID, pub.TermID, pub.Status, pub.SubmittedBy, pub.SubmittedAt, pub.ApprovedBy,
+			pub.ApprovedAt, pub.PublishedBy, pub.PublishedAt, squirrel.Expr("?::jsonb", bands), pub.ReturnedBy,
+			pub.ReturnedAt, pub.ReturnReason, pub.CreatedAt, pub.UpdatedAt).
+		Suffix(`ON CONFLICT (course_id, COALESCE(term_id, 0)) DO UPDATE SET
+			status = EXCLUDED.status,
+			submitted_by = EXCLUDED.submitted_by,
+			submitted_at = EXCLUDED.submitted_at,
+			approved_by = EXCLUDED.approved_by,
+			approved_at = EXCLUDED.approved_at,
+			published_by = EXCLUDED.published_by,
+			published_at = EXCLUDED.published_at,
+			bands = EXCLUDED.bands,
+			returned_by = EXCLUDED.returned_by,
+			returned_at = EXCLUDED.returned_at,
+			return_reason = EXCLUDED.return_reason,
+			updated_at = EXCLUDED.updated_at
+			WHERE `+resultPublicationTable+`.status = ?
+			RETURNING id, created_at`, from).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("SavePublication: failed to build query: %w", err)
+	}
+
+	err = r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if err := lockCourseAssessments(ctx, tx, pub.CourseID); err != nil {
+			return err
+		}
+		return tx.QueryRow(ctx, sql, args...).Scan(&pub.ID, &pub.CreatedAt)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("SavePublication: course %d is no longer %s: %w", pub.CourseID, from, ErrPublicationChanged)
+		}
+		return fmt.Errorf("SavePublication: %w", err)
+	}
+	return nil
+}
+
+// checkDraft fails with ErrResultsSubmitted unless the course's results in
+// the term are a draft. Callers hold the course's assessment lock.
+func checkDraft(ctx context.Context, q Querier, courseID int, termID *int) error {
+	var submitted bool
+	err := q.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM "+resultPublicationTable+
+		" WHERE course_id = $1 AND term_id IS NOT DISTINCT FROM $2 AND status <> $3)",
+		courseID, termID, models.ResultsDraft).Scan(&submitted)
+	if err != nil {
+		return fmt.Errorf("failed to check result publication: %w", err)
+	}
+	if submitted {
+		return ErrResultsSubmitted
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	courseResultTable = "course_results"
+	gpaSnapshotTable  = "gpa_snapshots"
+)
+
+var gpaSnapshotQueryFields = []string{
+	"id", "college_id", "student_id", "term_id", "sgpa::float8 AS sgpa", "cgpa::float8 AS cgpa",
+	"term_credits", "credits_earned", "computed_at",
+}
+
+var ErrStudentNotFound = errors.New("student not found")
+
+type ResultRepository interface {
+	// FindAssessedCourses lists the courses with assessments in termID, or
+	// without a term if it is nil.
+	FindAssessedCourses(ctx context.Context, collegeID int, termID *int) ([]*models.Course, error)
+	// SaveCourseResults replaces the course's results in termID with these.
+	SaveCourseResults(ctx context.Context, collegeID int, courseID int, termID *int, results []*models.CourseResult) error
+	// FindStudentResults lists the student's course results with their
+	// course and term names, oldest term first; results of one term are
+	// always listed together.
+	FindStudentResults(ctx context.Context, collegeID int, studentID int) ([]*models.CourseResult, error)
+
+	// SaveGPASnapshots replaces the student's snapshots with these.
+	SaveGPASnapshots(ctx context.Context, collegeID int, studentID int, snapshots []*models.GPASnapshot) error
+	// FindGPASnapshots lists the student's snapshots, oldest term first.
+	FindGPASnapshots(ctx context.Context, collegeID int, studentID int) ([]*models.GPASnapshot, error)
+
+	// GetTranscriptStudent returns a transcript with just the student and
+	// college filled in.
+	GetTranscriptStudent(ctx context.Context, collegeID int, studentID int) (*models.Transcript, error)
+}
+
+type resultRepository struct {
+	DB *DB
+}
+
+func NewResultRepository(db *DB) ResultRepository {
+	return &resultRepository{DB: db}
+}
+
+func (r *resultRepository) FindAssessedCourses(ctx context.Context, collegeID int, termID *int) ([]*models.Course, error) {
+	sql, args, err := r.DB.SQ.Select(
+		"c.id", "c.name", "c.college_id", "c.description", "c.credits", "c.instructor_id", "c.capacity",
+		"c.created_at", "c.updated_at",
+	).
+		From(courseTable + " c").
+		Where(squirrel.Eq{"c.college_id": collegeID}).
+		Where(squirrel.Expr("EXISTS (SELECT 1 FROM "+assessmentTable+" a WHERE a.course_id = c.id AND a.term_id IS NOT DISTINCT FROM ?)", termID)).
+		OrderBy("c.id ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindAssessedCourses: failed to build query: %w", err)
+	}
+
+	courses := []*models.Course{}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &courses, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindAssessedCourses: failed to execute query or scan: %w", err)
+	}
+	return courses, nil
+}
+
+func (r *resultRepository) SaveCourseResults(ctx context.Context, collegeID int, courseID int, termID *int, results []*models.CourseResult) error {
+	deleteSQL, deleteArgs, err := r.DB.SQ.Delete(courseResultTable).
+		Where(squirrel.Eq{"college_id": collegeID, "course_id": courseID}).
+		Where(squirrel.Expr("term_id IS NOT DISTINCT FROM ?", termID)).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("SaveCourseResults: failed to build delete query: %w", err)
+	}
+
+	now := time.Now()
+	insert := r.DB.SQ.Insert(courseResultTable).
+		Columns("college_id", "student_id", "course_id", "term_id", "percentage", "grade_letter", "grade_point",
+			"credits", "grading_scale_id", "computed_at").
+		Suffix("RETURNING id")
+	for _, res := range results {
+		res.CollegeID, res.CourseID, res.TermID, res.ComputedAt = collegeID, courseID, termID, now
+		insert = insert.Values(res.CollegeID, res.StudentID, res.CourseID, res.TermID, res.Percentage, res.GradeLetter,
+			res.GradePoint, res.Credits, res.GradingScaleID, res.ComputedAt)
+	}
+
+	return r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, deleteSQL, deleteArgs...); err != nil {
+			return fmt.Errorf("SaveCourseResults: failed to clear results: %w", err)
+		}
+		if len(results) == 0 {
+			return nil
+		}
+		sql, args, err := insert.ToSql()
+		if err != nil {
+			return fmt.Errorf("SaveCourseResults: failed to build query: %w", err)
+		}
+		rows, err := tx.Query(ctx, sql, args...)
+		if err != nil {
+			return fmt.Errorf("SaveCourseResults: failed to execute query: %w", err)
+		}
+		defer rows.Close()
+		for i := 0; rows.Next(); i++ {
+			if err := rows.Scan(&results[i].ID); err != nil {
+				return fmt.Errorf("SaveCourseResults: failed to scan ID: %w", err)
+			}
+		}
+		return rows.Err()
+	})
+}
+
+func (r *resultRepository) FindStudentResults(ctx context.Context, collegeID int, studentID int) ([]*models.CourseResult, error) {
+	sql, args, err := r.DB.SQ.Select(
+		"r.id", "r.college_id", "r.student_id", "r.course_id", "r.term_id", "r.percentage::float8 AS percentage",
+		"r.grade_letter", "r.grade_point::float8 AS grade_point", "r.credits", "r.grading_scale_id", "r.computed_at",
+		"c.name AS course_name", "t.name AS term_name", "t.start_date AS term_start",
+	).
+		From(courseResultTable+" r").
+		Join(courseTable+" c ON c.id = r.course_id").
+		LeftJoin(academicTermTable+" t ON t.id = r.term_id").
+		Where(squirrel.Eq{"r.college_id": collegeID, "r.student_id": studentID}).
+		OrderBy("t.start_date ASC NULLS FIRST", "r.term_id ASC NULLS FIRST", "c.name ASC", "r.course_id ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindStudentResults: failed to build query: %w", err)
+	}
+
+	results := []*models.CourseResult{}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &results, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindStudentResults: failed to execute query or scan: %w", err)
+	}
+	return results, nil
+}
+
+func (r *resultRepository) SaveGPASnapshots(ctx context.Context, collegeID int, studentID int, snapshots []*models.GPASnapshot) error {
+	deleteSQL, deleteArgs, err := r.DB.SQ.Delete(gpaSnapshotTable).
+		Where(squirrel.Eq{"college_id": collegeID, "student_id": studentID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("SaveGPASnapshots: failed to build delete query: %w", err)
+	}
+
+	now := time.Now()
+	insert := r.DB.SQ.Insert(gpaSnapshotTable).
+		Columns("college_id", "student_id", "term_id", "sgpa", "cgpa", "term_credits", "credits_earned", "computed_at").
+		Suffix("RETURNING id")
+	for _, s := range snapshots {
+		s.CollegeID, s.StudentID, s.ComputedAt = collegeID, studentID, now
+		insert = insert.Values(s.CollegeID, s.StudentID, s.TermID, s.SGPA, s.CGPA, s.TermCredits, s.CreditsEarned, s.ComputedAt)
+	}
+
+	return r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, deleteSQL, deleteArgs...); err != nil {
+			return fmt.Errorf("SaveGPASnapshots: failed to clear snapshots: %w", err)
+		}
+		if len(snapshots) == 0 {
+			return nil
+		}
+		sql, args, err := insert.ToSql()
+		if err != nil {
+			return fmt.Errorf("SaveGPASnapshots: failed to build query: %w", err)
+		}
+		rows, err := tx.Query(ctx, sql, args...)
+		if err != nil {
+			return fmt.Errorf("SaveGPASnapshots: failed to execute query: %w", err)
+		}
+		defer rows.Close()
+		for i := 0; rows.Next(); i++ {
+			if err := rows.Scan(&snapshots[i].ID); err != nil {
+				return fmt.Errorf("SaveGPASnapshots: failed to scan ID: %w", err)
+			}
+		}
+		return rows.Err()
+	})
+}
+
+func (r *resultRepository) FindGPASnapshots(ctx context.Context, collegeID int, studentID int) ([]*models.GPASnapshot, error) {
+	fields := make([]string, len(gpaSnapshotQueryFields))
+	for i, f := range gpaSnapshotQueryFields {
+		fields[i] = "g." + f
+	}
+	sql, args, err := r.DB.SQ.Select(fields...).
+		From(gpaSnapshotTable+" g").
+		LeftJoin(academicTermTable+" t ON t.id = g.term_id").
+		Where(squirrel.Eq{"g.college_id": collegeID, "g.student_id": studentID}).
+		OrderBy("t.start_date ASC NULLS FIRST", "g.term_id ASC NULLS FIRST").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindGPASnapshots: failed to build query: %w", err)
+	}
+
+	snapshots := []*models.GPASnapshot{}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &snapshots, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindGPASnapshots: failed to execute query or scan: %w", err)
+	}
+	return snapshots, nil
+}
+
+func (r *resultRepository) GetTranscriptStudent(ctx context.Context, collegeID int, studentID int) (*models.Transcript, error) {
+	sql, args, err := r.DB.SQ.Select("col.name AS college_name", "s.id AS student_id", "COALESCE(u.name, '') AS student_name", "s.roll_no").
+		From(studentTable + " s").
+		Join(collegeTable + " col ON col.id = s.college_id").
+		LeftJoin(userTable + " u ON u.id = s.user_id").
+		Where(squirrel.Eq{"s.id": studentID, "s.college_id": collegeID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("GetTranscriptStudent: failed to build query: %w", err)
+	}
+
+	transcript := &models.Transcript{}
+	if err := pgxscan.Get(ctx, r.DB.Pool, transcript, sql, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("GetTranscriptStudent: student %d for college ID %d: %w", studentID, collegeID, ErrStudentNotFound)
+		}
+		return nil, fmt.Errorf("GetTranscriptStudent: failed to execute query or scan: %w", err)
+	}
+	return transcript, nil
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupResultTest(t *testing.T) (pgxmock.PgxPoolIface, *resultRepository, context.Context) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+
+	db := &DB{
+		Pool: mock,
+		SQ:   squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+	return mock, &resultRepository{DB: db}, context.Background()
+}
+
+// Terms starting on the same date must not interleave, or GPAs are
+// accumulated in the wrong order.
+func TestStudentResultsGroupTermsStartingTogether(t *testing.T) {
+	mock, repo, ctx := setupResultTest(t)
+	defer mock.Close()
+
+	mock.ExpectQuery(`FROM course_results r .* ORDER BY t.start_date ASC NULLS FIRST, r.term_id ASC NULLS FIRST, c.name ASC, r.course_id ASC$`).
+		WithArgs(1, 7).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}))
+	_, err := repo.FindStudentResults(ctx, 1, 7)
+	require.NoError(t, err)
+
+	mock.ExpectQuery(`FROM gpa_snapshots g .* ORDER BY t.start_date ASC NULLS FIRST, g.term_id ASC NULLS FIRST$`).
+		WithArgs(1, 7).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}))
+	_, err = repo.FindGPASnapshots(ctx, 1, 7)
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
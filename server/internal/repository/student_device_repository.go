@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+)
+
+var (
+	ErrDeviceNotFound     = errors.New("device not found")
+	ErrDeviceTaken        = errors.New("device is registered to another student")
+	ErrDeviceLimitReached = errors.New("student has registered the maximum number of devices")
+)
+
+type StudentDeviceRepository interface {
+	// RegisterDevice adds a device for the student, reactivating it if it was
+	// registered before. A device belongs to one student in a college: it
+	// fails with ErrDeviceTaken if another student registered it, and with
+	// ErrDeviceLimitReached if the student already has limit active devices.
+	RegisterDevice(ctx context.Context, device *models.StudentDevice, limit int) error
+	IsDeviceRegistered(ctx context.Context, collegeID, studentID int, fingerprint string) (bool, error)
+	FindDevicesByStudent(ctx context.Context, collegeID, studentID int) ([]*models.StudentDevice, error)
+	// RevokeDevice deactivates one of the student's devices. It stays bound
+	// to the student, who can register it again.
+	RevokeDevice(ctx context.Context, collegeID, studentID, deviceID int) error
+}
+
+const studentDeviceTable = "student_devices"
+
+type studentDeviceRepository struct {
+	DB *DB
+}
+
+func NewStudentDeviceRepository(db *DB) StudentDeviceRepository {
+	return &studentDeviceRepository{DB: db}
+}
+
+func (r *studentDeviceRepository) RegisterDevice(ctx context.Context, device *models.StudentDevice, limit int) error {
+	now := time.Now()
+	device.CreatedAt = now
+	device.UpdatedAt = now
+	device.IsActive = true
+
+	countSQL, countArgs, err := r.DB.SQ.Select("COUNT(*)").
+		From(studentDeviceTable).
+		Where(squirrel.Eq{
+			"college_id": device.CollegeID,
+			"student_id": device.StudentID,
+			"is_active":  true,
+		}).
+		Where(squirrel.NotEq{"device_fingerprint": device.DeviceFingerprint}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("RegisterDevice: failed to build count query: %w", err)
+	}
+	// Another student's device is left alone: the update's WHERE fails and
+	// nothing is returned
+	sql, args, err := r.DB.SQ.Insert(studentDeviceTable).
+		Columns("college_id", "student_id", "device_fingerprint", "label", "is_active", "created_at", "updated_at").
+		Values(device.CollegeID, device.StudentID, device.DeviceFingerprint, device.Label, device.IsActive, device.CreatedAt, device.UpdatedAt).
+		Suffix("ON CONFLICT (college_id, device_fingerprint) DO UPDATE SET label = EXCLUDED.label, is_active = TRUE, updated_at = EXCLUDED.updated_at " +
+			"WHERE " + studentDeviceTable + ".student_id = EXCLUDED.student_id RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("RegisterDevice: failed to build query: %w", err)
+	}
+
+	err = r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		// Serialises the student's registrations so the limit holds
+		if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext($1), $2)", studentDeviceTable, device.StudentID); err != nil {
+			return fmt.Errorf("failed to lock devices: %w", err)
+		}
+		var active int
+		if err := tx.QueryRow(ctx, countSQL, countArgs...).Scan(&active); err != nil {
+			return fmt.Errorf("failed to count devices: %w", err)
+		}
+		if active >= limit {
+			return ErrDeviceLimitReached
+		}
+		return tx.QueryRow(ctx, sql, args...).Scan(&device.ID, &device.CreatedAt)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("RegisterDevice: %w", ErrDeviceTaken)
+		}
+		return fmt.Errorf("RegisterDevice: %w", err)
+	}
+	return nil
+}
+
+func (r *studentDeviceRepository) IsDeviceRegistered(ctx context.Context, collegeID, studentID int, fingerprint string) (bool, error) {
+	query := r.DB.SQ.Select("COUNT(*)").
+		From(studentDeviceTable).
+		Where(squirrel.Eq{
+			"college_id":         collegeID,
+			"student_id":         studentID,
+			"device_fingerprint": fingerprint,
+			"is_active":          true,
+		})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("IsDeviceRegistered: failed to build query: %w", err)
+	}
+
+	var count int
+	err = r.DB.Pool.QueryRow(ctx, sql, args...).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("IsDeviceRegistered: failed to execute query or scan: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (r *studentDeviceRepository) FindDevicesByStudent(ctx context.Context, collegeID, studentID int) ([]*models.StudentDevice, error) {
+	query := r.DB.SQ.Select("id", "college_id", "student_id", "device_fingerprint", "COALESCE(label, '') AS label", "is_active", "created_at", "updated_at").
+		From(studentDeviceTable).
+		Where(squirrel.Eq{
+			"college_id": collegeID,
+			"student_id": studentID,
+		}).
+		OrderBy("created_at DESC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindDevicesByStudent: failed to build query: %w", err)
+	}
+
+	devices := []*models.StudentDevice{}
+	err = pgxscan.Select(ctx, r.DB.Pool, &devices, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("FindDevicesByStudent: failed to execute query or scan: %w", err)
+	}
+	return devices, nil
+}
+
+func (r *studentDeviceRepository) RevokeDevice(ctx context.Context, collegeID, studentID, deviceID int) error {
+	sql, args, err := r.DB.SQ.Update(studentDeviceTable).
+		Set("is_active", false).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{
+			"id":         deviceID,
+			"college_id": collegeID,
+			"student_id": studentID,
+		}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("RevokeDevice: failed to build query: %w", err)
+	}
+
+	commandTag, err := r.DB.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("RevokeDevice: failed to execute query: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("RevokeDevice: device %d: %w", deviceID, ErrDeviceNotFound)
+	}
+	return nil
+}
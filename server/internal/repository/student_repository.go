@@ -24,6 +24,8 @@ import (
 type StudentRepository interface {
 	CreateStudent(ctx context.Context, student *models.Student) error
 	GetStudentByRollNo(ctx context.Context, collegeID int, rollNo string) (*models.Student, error)
+	// FindStudentIDsByRollNos maps each roll number found in the college to its student ID.
+	FindStudentIDsByRollNos(ctx context.Context, collegeID int, rollNos []string) (map[string]int, error)
 	GetStudentByID(ctx context.Context, collegeID int, studentID int) (*models.Student, error) // Note: studentID is the primary key 'id' here
 	UpdateStudent(ctx context.Context, model *models.Student) error
 	FreezeStudent(ctx context.Context, rollNo string) error   // Renamed param to match casing
@@ -133,6 +135,35 @@ func (s *studentRepository) GetStudentByRollNo(ctx context.Context, collegeID in
 	return student, nil // Success
 }
 
+// FindStudentIDsByRollNos resolves many roll numbers in one query.
+func (s *studentRepository) FindStudentIDsByRollNos(ctx context.Context, collegeID int, rollNos []string) (map[string]int, error) {
+	ids := make(map[string]int, len(rollNos))
+	if len(rollNos) == 0 {
+		return ids, nil
+	}
+
+	query := s.DB.SQ.Select("id", "roll_no").
+		From(studentTable).
+		Where(squirrel.Eq{"college_id": collegeID, "roll_no": rollNos})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindStudentIDsByRollNos: failed to build query: %w", err)
+	}
+
+	rows := []struct {
+		ID     int    `db:"id"`
+		RollNo string `db:"roll_no"`
+	}{}
+	if err := pgxscan.Select(ctx, s.DB.Pool, &rows, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindStudentIDsByRollNos: failed to execute query or scan: %w", err)
+	}
+	for _, row := range rows {
+		ids[row.RollNo] = row.ID
+	}
+	return ids, nil
+}
+
 // DeleteStudent removes a student record by its ID, scoped by collegeID.
 func (s *studentRepository) DeleteStudent(ctx context.Context, collegeID int, studentID int) error {
 	query := s.DB.SQ.Delete(studentTable).
@@ -45,7 +45,7 @@ func TestCreateStudent(t *testing.T) {
 	}
 	expectedID := 5
 
-	sqlRegex := `INSERT INTO students \(user_id,college_id,kratos_identity_id,enrollment_year,roll_no,is_active,created_at,updated_at\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8\) RETURNING id`
+	sqlRegex := `INSERT INTO students (user_id,college_id,kratos_identity_id,enrollment_year,roll_no,is_active,created_at,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING id`
 
 	mock.ExpectQuery(regexp.QuoteMeta(sqlRegex)).
 		WithArgs(
@@ -75,7 +75,7 @@ func TestCreateStudent_Error(t *testing.T) {
 
 	student := &models.Student{RollNo: "FAIL001"} // Minimal data for error case
 	dbError := errors.New("insert failed")
-	sqlRegex := `INSERT INTO students \(user_id,college_id,kratos_identity_id,enrollment_year,roll_no,is_active,created_at,updated_at\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8\) RETURNING id`
+	sqlRegex := `INSERT INTO students (user_id,college_id,kratos_identity_id,enrollment_year,roll_no,is_active,created_at,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING id`
 
 	mock.ExpectQuery(regexp.QuoteMeta(sqlRegex)).
 		WithArgs(student.UserID, student.CollegeID, student.KratosIdentityID, student.EnrollmentYear, student.RollNo, student.IsActive, pgxmock.AnyArg(), pgxmock.AnyArg()).
@@ -107,16 +107,16 @@ func TestGetStudentByRollNo(t *testing.T) {
 		UpdatedAt:        time.Now(),
 	}
 
-	sqlRegex := `SELECT id, user_id, college_id, kratos_identity_id, enrollment_year, roll_no, is_active, created_at, updated_at FROM students WHERE roll_no = \$1`
+	sqlRegex := `SELECT id, user_id, college_id, kratos_identity_id, enrollment_year, roll_no, is_active, created_at, updated_at FROM students WHERE college_id = $1 AND roll_no = $2`
 	rows := pgxmock.NewRows([]string{"id", "user_id", "college_id", "kratos_identity_id", "enrollment_year", "roll_no", "is_active", "created_at", "updated_at"}).
 		AddRow(expectedStudent.StudentID, expectedStudent.UserID, expectedStudent.CollegeID, expectedStudent.KratosIdentityID, expectedStudent.EnrollmentYear, expectedStudent.RollNo, expectedStudent.IsActive, expectedStudent.CreatedAt, expectedStudent.UpdatedAt)
 
 	// pgxscan.Get uses QueryRow internally
 	mock.ExpectQuery(regexp.QuoteMeta(sqlRegex)).
-		WithArgs(rollNo).
+		WithArgs(1, rollNo).
 		WillReturnRows(rows)
 
-	student, err := repo.GetStudentByRollNo(ctx, rollNo)
+	student, err := repo.GetStudentByRollNo(ctx, 1, rollNo)
 
 	assert.NoError(t, err)
 	assert.EqualValues(t, expectedStudent, student) // Use EqualValues for struct comparison
@@ -128,15 +128,15 @@ func TestGetStudentByRollNo_NotFound(t *testing.T) {
 	defer mock.Close()
 
 	rollNo := "NOTFOUND"
-	sqlRegex := `SELECT id, user_id, college_id, kratos_identity_id, enrollment_year, roll_no, is_active, created_at, updated_at FROM students WHERE roll_no = \$1`
+	sqlRegex := `SELECT id, user_id, college_id, kratos_identity_id, enrollment_year, roll_no, is_active, created_at, updated_at FROM students WHERE college_id = $1 AND roll_no = $2`
 
 	mock.ExpectQuery(regexp.QuoteMeta(sqlRegex)).
-		WithArgs(rollNo).
+		WithArgs(1, rollNo).
 		WillReturnError(pgx.ErrNoRows)
 
-	student, err := repo.GetStudentByRollNo(ctx, rollNo)
+	student, err := repo.GetStudentByRollNo(ctx, 1, rollNo)
 
-	assert.NoError(t, err) // Implementation returns nil, nil for not found
+	assert.ErrorIs(t, err, pgx.ErrNoRows) // scany wraps the error, so it is not reported as nil, nil
 	assert.Nil(t, student)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -161,7 +161,7 @@ func TestUpdateStudent(t *testing.T) {
 		// UpdatedAt will be set by the method
 	}
 
-	sqlRegex := `UPDATE students SET user_id = \$1, college_id = \$2, kratos_identity_id = \$3, enrollment_year = \$4, roll_no = \$5, is_active = \$6, updated_at = \$7 WHERE id = \$8`
+	sqlRegex := `UPDATE students SET user_id = $1, college_id = $2, kratos_identity_id = $3, enrollment_year = $4, roll_no = $5, is_active = $6, updated_at = $7 WHERE id = $8`
 
 	mock.ExpectExec(regexp.QuoteMeta(sqlRegex)).
 		WithArgs(
@@ -188,7 +188,7 @@ func TestUpdateStudent_NotFound(t *testing.T) {
 	defer mock.Close()
 
 	studentToUpdate := &models.Student{StudentID: 999, RollNo: "NOTFOUND"} // Non-existent ID
-	sqlRegex := `UPDATE students SET user_id = \$1, college_id = \$2, kratos_identity_id = \$3, enrollment_year = \$4, roll_no = \$5, is_active = \$6, updated_at = \$7 WHERE id = \$8`
+	sqlRegex := `UPDATE students SET user_id = $1, college_id = $2, kratos_identity_id = $3, enrollment_year = $4, roll_no = $5, is_active = $6, updated_at = $7 WHERE id = $8`
 
 	mock.ExpectExec(regexp.QuoteMeta(sqlRegex)).
 		WithArgs(studentToUpdate.UserID, studentToUpdate.CollegeID, studentToUpdate.KratosIdentityID, studentToUpdate.EnrollmentYear, studentToUpdate.RollNo, studentToUpdate.IsActive, pgxmock.AnyArg(), studentToUpdate.StudentID).
@@ -208,7 +208,7 @@ func TestFreezeStudent(t *testing.T) {
 	defer mock.Close()
 
 	rollNo := "TEST001"
-	sqlRegex := `UPDATE students SET is_active = \$1, updated_at = \$2 WHERE roll_no = \$3`
+	sqlRegex := `UPDATE students SET is_active = $1, updated_at = $2 WHERE roll_no = $3`
 
 	mock.ExpectExec(regexp.QuoteMeta(sqlRegex)).
 		WithArgs(false, pgxmock.AnyArg(), rollNo).
@@ -225,7 +225,7 @@ func TestFreezeStudent_NotFound(t *testing.T) {
 	defer mock.Close()
 
 	rollNo := "NOTFOUND"
-	sqlRegex := `UPDATE students SET is_active = \$1, updated_at = \$2 WHERE roll_no = \$3`
+	sqlRegex := `UPDATE students SET is_active = $1, updated_at = $2 WHERE roll_no = $3`
 
 	mock.ExpectExec(regexp.QuoteMeta(sqlRegex)).
 		WithArgs(false, pgxmock.AnyArg(), rollNo).
@@ -245,7 +245,7 @@ func TestUnFreezeStudent(t *testing.T) {
 	defer mock.Close()
 
 	rollNo := "TEST001"
-	sqlRegex := `UPDATE students SET is_active = \$1, updated_at = \$2 WHERE roll_no = \$3`
+	sqlRegex := `UPDATE students SET is_active = $1, updated_at = $2 WHERE roll_no = $3`
 
 	mock.ExpectExec(regexp.QuoteMeta(sqlRegex)).
 		WithArgs(true, pgxmock.AnyArg(), rollNo).
@@ -262,7 +262,7 @@ func TestUnFreezeStudent_NotFound(t *testing.T) {
 	defer mock.Close()
 
 	rollNo := "NOTFOUND"
-	sqlRegex := `UPDATE students SET is_active = \$1, updated_at = \$2 WHERE roll_no = \$3`
+	sqlRegex := `UPDATE students SET is_active = $1, updated_at = $2 WHERE roll_no = $3`
 
 	mock.ExpectExec(regexp.QuoteMeta(sqlRegex)).
 		WithArgs(true, pgxmock.AnyArg(), rollNo).
@@ -284,7 +284,7 @@ func TestFindByKratosID(t *testing.T) {
 	kratosID := "kratos-test-id"
 	expectedStudent := &models.Student{ /* ... fill expected data ... */ RollNo: "TEST001", KratosIdentityID: kratosID}
 
-	sqlRegex := `SELECT id, user_id, college_id, kratos_identity_id, enrollment_year, roll_no, is_active, created_at, updated_at FROM students WHERE kratos_identity_id = \$1`
+	sqlRegex := `SELECT id, user_id, college_id, kratos_identity_id, enrollment_year, roll_no, is_active, created_at, updated_at FROM students WHERE kratos_identity_id = $1`
 	rows := pgxmock.NewRows([]string{"id", "user_id", "college_id", "kratos_identity_id", "enrollment_year", "roll_no", "is_active", "created_at", "updated_at"}).
 		AddRow(expectedStudent.StudentID, expectedStudent.UserID, expectedStudent.CollegeID, expectedStudent.KratosIdentityID, expectedStudent.EnrollmentYear, expectedStudent.RollNo, expectedStudent.IsActive, expectedStudent.CreatedAt, expectedStudent.UpdatedAt)
 
@@ -304,7 +304,7 @@ func TestFindByKratosID_NotFound(t *testing.T) {
 	defer mock.Close()
 
 	kratosID := "kratos-not-found"
-	sqlRegex := `SELECT id, user_id, college_id, kratos_identity_id, enrollment_year, roll_no, is_active, created_at, updated_at FROM students WHERE kratos_identity_id = \$1`
+	sqlRegex := `SELECT id, user_id, college_id, kratos_identity_id, enrollment_year, roll_no, is_active, created_at, updated_at FROM students WHERE kratos_identity_id = $1`
 
 	mock.ExpectQuery(regexp.QuoteMeta(sqlRegex)).
 		WithArgs(kratosID).
@@ -312,7 +312,7 @@ func TestFindByKratosID_NotFound(t *testing.T) {
 
 	student, err := repo.FindByKratosID(ctx, kratosID)
 
-	assert.NoError(t, err) // Implementation returns nil, nil
+	assert.ErrorIs(t, err, pgx.ErrNoRows) // scany wraps the error, so it is not reported as nil, nil
 	assert.Nil(t, student)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -0,0 +1,371 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+var (
+	ErrSuspensionNotFound      = errors.New("suspension not found")
+	ErrStudentAlreadySuspended = errors.New("student already has an open suspension")
+)
+
+// StudentSuspensionRepository freezes and unfreezes a student, their
+// attendance and their enrollments in one transaction per suspension.
+type StudentSuspensionRepository interface {
+	// CreateSuspension stores the suspension; if it starts on or before today
+	// it is applied straight away.
+	CreateSuspension(ctx context.Context, suspension *models.StudentSuspension, today time.Time) error
+	GetSuspensionByID(ctx context.Context, collegeID int, suspensionID int) (*models.StudentSuspension, error)
+	// FindSuspensions lists suspensions, optionally for one student (studentID > 0) and status.
+	FindSuspensions(ctx context.Context, collegeID int, studentID int, status string, limit, offset uint64) ([]*models.StudentSuspension, error)
+	// LiftSuspension ends a scheduled or active suspension. It returns false if
+	// the suspension was already lifted.
+	LiftSuspension(ctx context.Context, collegeID int, suspensionID int, liftedBy string, reason string) (bool, error)
+
+	// ActivateDueSuspensions applies up to limit scheduled suspensions starting
+	// on or before today, and LiftExpiredSuspensions lifts up to limit active
+	// ones that ended before today. Both skip rows another replica holds.
+	ActivateDueSuspensions(ctx context.Context, today time.Time, limit uint64) (int, error)
+	LiftExpiredSuspensions(ctx context.Context, today time.Time, limit uint64) (int, error)
+}
+
+const studentSuspensionTable = "student_suspensions"
+
+var studentSuspensionQueryFields = []string{
+	"id", "college_id", "student_id", "reason", "starts_on", "ends_on", "status", "suspended_by",
+	"lifted_by", "lift_reason", "lifted_at", "created_at", "updated_at",
+}
+
+type studentSuspensionRepository struct {
+	DB *DB
+	// attendance gives access to the audited attendance helpers inside our transactions
+	attendance *attendanceRepository
+}
+
+func NewStudentSuspensionRepository(db *DB) StudentSuspensionRepository {
+	return &studentSuspensionRepository{DB: db, attendance: &attendanceRepository{DB: db}}
+}
+
+func (r *studentSuspensionRepository) CreateSuspension(ctx context.Context, suspension *models.StudentSuspension, today time.Time) error {
+	now := time.Now()
+	suspension.CreatedAt = now
+	suspension.UpdatedAt = now
+	suspension.Status = models.SuspensionScheduled
+	startsNow := !suspension.StartsOn.After(today)
+	if startsNow {
+		suspension.Status = models.SuspensionActive
+	}
+
+	sql, args, err := r.DB.SQ.Insert(studentSuspensionTable).
+		Columns("college_id", "student_id", "reason", "starts_on", "ends_on", "status", "suspended_by", "created_at", "updated_at").
+		Values(suspension.CollegeID, suspension.StudentID, suspension.Reason, suspension.StartsOn, suspension.EndsOn, suspension.Status, suspension.SuspendedBy, suspension.CreatedAt, suspension.UpdatedAt).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CreateSuspension: failed to build query: %w", err)
+	}
+
+	err = r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, sql, args...).Scan(&suspension.ID); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return ErrStudentAlreadySuspended
+			}
+			return fmt.Errorf("failed to execute query or scan ID: %w", err)
+		}
+		if !startsNow {
+			return nil
+		}
+		return r.freeze(ctx, tx, suspension)
+	})
+	if err != nil {
+		return fmt.Errorf("CreateSuspension: %w", err)
+	}
+	return nil
+}
+
+func (r *studentSuspensionRepository) GetSuspensionByID(ctx context.Context, collegeID int, suspensionID int) (*models.StudentSuspension, error) {
+	sql, args, err := r.DB.SQ.Select(studentSuspensionQueryFields...).
+		From(studentSuspensionTable).
+		Where(squirrel.Eq{"id": suspensionID, "college_id": collegeID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("GetSuspensionByID: failed to build query: %w", err)
+	}
+
+	suspension := &models.StudentSuspension{}
+	if err := pgxscan.Get(ctx, r.DB.Pool, suspension, sql, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("GetSuspensionByID: suspension %d for college ID %d: %w", suspensionID, collegeID, ErrSuspensionNotFound)
+		}
+		return nil, fmt.Errorf("GetSuspensionByID: failed to execute query or scan: %w", err)
+	}
+	return suspension, nil
+}
+
+func (r *studentSuspensionRepository) FindSuspensions(ctx context.Context, collegeID int, studentID int, status string, limit, offset uint64) ([]*models.StudentSuspension, error) {
+	query := r.DB.SQ.Select(studentSuspensionQueryFields...).
+		From(studentSuspensionTable).
+		Where(squirrel.Eq{"college_id": collegeID}).
+		OrderBy("starts_on DESC", "id DESC").
+		Limit(limit).
+		Offset(offset)
+	if studentID > 0 {
+		query = query.Where(squirrel.Eq{"student_id": studentID})
+	}
+	if status != "" {
+		query = query.Where(squirrel.Eq{"status": status})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindSuspensions: failed to build query: %w", err)
+	}
+	suspensions := []*models.StudentSuspension{}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &suspensions, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindSuspensions: failed to execute query or scan: %w", err)
+	}
+	return suspensions, nil
+}
+
+func (r *studentSuspensionRepository) LiftSuspension(ctx context.Context, collegeID int, suspensionID int, liftedBy string, reason string) (bool, error) {
+	sql, args, err := r.DB.SQ.Select(studentSuspensionQueryFields...).
+		From(studentSuspensionTable).
+		Where(squirrel.Eq{"id": suspensionID, "college_id": collegeID}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("LiftSuspension: failed to build query: %w", err)
+	}
+
+	lifted := false
+	err = r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		suspension := &models.StudentSuspension{}
+		if err := pgxscan.Get(ctx, tx, suspension, sql, args...); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrSuspensionNotFound
+			}
+			return fmt.Errorf("failed to lock suspension: %w", err)
+		}
+		if suspension.Status == models.SuspensionLifted {
+			return nil
+		}
+		lifted = true
+		return r.lift(ctx, tx, suspension, liftedBy, reason)
+	})
+	if err != nil {
+		return false, fmt.Errorf("LiftSuspension: %w", err)
+	}
+	return lifted, nil
+}
+
+func (r *studentSuspensionRepository) ActivateDueSuspensions(ctx context.Context, today time.Time, limit uint64) (int, error) {
+	query := r.DB.SQ.Select(studentSuspensionQueryFields...).
+		From(studentSuspensionTable).
+		Where(squirrel.Eq{"status": models.SuspensionScheduled}).
+		Where(squirrel.LtOrEq{"starts_on": today.Format(time.DateOnly)})
+
+	n, err := r.processClaimed(ctx, query, limit, func(tx pgx.Tx, s *models.StudentSuspension) error {
+		if err := r.setStatus(ctx, tx, s.ID, models.SuspensionActive, nil, ""); err != nil {
+			return err
+		}
+		return r.freeze(ctx, tx, s)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("ActivateDueSuspensions: %w", err)
+	}
+	return n, nil
+}
+
+func (r *studentSuspensionRepository) LiftExpiredSuspensions(ctx context.Context, today time.Time, limit uint64) (int, error) {
+	query := r.DB.SQ.Select(studentSuspensionQueryFields...).
+		From(studentSuspensionTable).
+		Where(squirrel.Eq{"status": models.SuspensionActive}).
+		Where(squirrel.Lt{"ends_on": today.Format(time.DateOnly)})
+
+	n, err := r.processClaimed(ctx, query, limit, func(tx pgx.Tx, s *models.StudentSuspension) error {
+		return r.lift(ctx, tx, s, models.AuditActorSystem, "suspension period ended")
+	})
+	if err != nil {
+		return 0, fmt.Errorf("LiftExpiredSuspensions: %w", err)
+	}
+	return n, nil
+}
+
+// processClaimed locks up to limit rows of query, skipping rows held by other
+// transactions, and runs fn on each inside the same transaction.
+func (r *studentSuspensionRepository) processClaimed(ctx context.Context, query squirrel.SelectBuilder, limit uint64, fn func(tx pgx.Tx, s *models.StudentSuspension) error) (int, error) {
+	sql, args, err := query.OrderBy("id ASC").Limit(limit).Suffix("FOR UPDATE SKIP LOCKED").ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build claim query: %w", err)
+	}
+
+	processed := 0
+	err = r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		var suspensions []*models.StudentSuspension
+		if err := pgxscan.Select(ctx, tx, &suspensions, sql, args...); err != nil {
+			return fmt.Errorf("failed to claim suspensions: %w", err)
+		}
+		for _, s := range suspensions {
+			if err := fn(tx, s); err != nil {
+				return fmt.Errorf("suspension %d: %w", s.ID, err)
+			}
+		}
+		processed = len(suspensions)
+		return nil
+	})
+	return processed, err
+}
+
+// freeze deactivates the student, holds their active and waitlisted
+// enrollments and freezes their attendance. A held waitlist entry keeps its
+// updated_at, and with it its place in the queue, but is not promoted.
+func (r *studentSuspensionRepository) freeze(ctx context.Context, tx pgx.Tx, s *models.StudentSuspension) error {
+	if err := r.setStudentActive(ctx, tx, s.CollegeID, s.StudentID, false); err != nil {
+		return err
+	}
+
+	sql, args, err := r.DB.SQ.Update(enrollmentTable+" e").
+		Set("status", models.Frozen).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"e.student_id": s.StudentID, "e.college_id": s.CollegeID}).
+		Where(activeEnrollment).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build enrollment query: %w", err)
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to freeze enrollments: %w", err)
+	}
+	if _, err := r.setEnrollmentStatus(ctx, tx, s, models.Waitlisted, models.FrozenWaitlisted); err != nil {
+		return fmt.Errorf("failed to freeze waitlist: %w", err)
+	}
+
+	audit := models.AuditInfo{ActorID: s.SuspendedBy, Source: models.AuditSourceFreeze, Reason: fmt.Sprintf("suspension %d: %s", s.ID, s.Reason)}
+	_, err = r.attendance.updateAttendanceStatusWhere(ctx, tx, s.CollegeID, squirrel.And{
+		squirrel.Eq{"student_id": s.StudentID},
+		squirrel.NotEq{"status": "Frozen"},
+	}, "Frozen", audit)
+	return err
+}
+
+// lift undoes freeze and marks the suspension lifted.
+func (r *studentSuspensionRepository) lift(ctx context.Context, tx pgx.Tx, s *models.StudentSuspension, liftedBy string, reason string) error {
+	if err := r.setStatus(ctx, tx, s.ID, models.SuspensionLifted, &liftedBy, reason); err != nil {
+		return err
+	}
+	// A scheduled suspension never froze anything.
+	if s.Status != models.SuspensionActive {
+		return nil
+	}
+
+	if err := r.setStudentActive(ctx, tx, s.CollegeID, s.StudentID, true); err != nil {
+		return err
+	}
+
+	sql, args, err := r.DB.SQ.Update(enrollmentTable).
+		Set("status", models.Active).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"student_id": s.StudentID, "college_id": s.CollegeID, "status": models.Frozen}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build enrollment query: %w", err)
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to unfreeze enrollments: %w", err)
+	}
+	courseIDs, err := r.setEnrollmentStatus(ctx, tx, s, models.FrozenWaitlisted, models.Waitlisted)
+	if err != nil {
+		return fmt.Errorf("failed to unfreeze waitlist: %w", err)
+	}
+	// Seats may have freed up while the student could not be promoted
+	enrollments := &enrollmentRepository{DB: r.DB}
+	for _, courseID := range courseIDs {
+		capacity, err := enrollments.lockCourse(ctx, tx, s.CollegeID, courseID)
+		if err != nil {
+			return err
+		}
+		if _, err := enrollments.promoteWaitlist(ctx, tx, s.CollegeID, courseID, capacity); err != nil {
+			return err
+		}
+	}
+
+	auditReason := fmt.Sprintf("suspension %d lifted", s.ID)
+	if reason != "" {
+		auditReason += ": " + reason
+	}
+	audit := models.AuditInfo{ActorID: liftedBy, Source: models.AuditSourceUnfreeze, Reason: auditReason}
+	_, err = r.attendance.restoreFrozenAttendance(ctx, tx, s.CollegeID, s.StudentID, audit)
+	return err
+}
+
+func (r *studentSuspensionRepository) setStatus(ctx context.Context, tx pgx.Tx, suspensionID int, status string, liftedBy *string, reason string) error {
+	now := time.Now()
+	query := r.DB.SQ.Update(studentSuspensionTable).
+		Set("status", status).
+		Set("updated_at", now).
+		Where(squirrel.Eq{"id": suspensionID})
+	if liftedBy != nil {
+		var liftReason *string
+		if reason != "" {
+			liftReason = &reason
+		}
+		query = query.Set("lifted_by", *liftedBy).Set("lift_reason", liftReason).Set("lifted_at", now)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build status query: %w", err)
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to update suspension status: %w", err)
+	}
+	return nil
+}
+
+// setEnrollmentStatus moves the student's enrollments from one status to the
+// other without touching updated_at, and returns their courses.
+func (r *studentSuspensionRepository) setEnrollmentStatus(ctx context.Context, tx pgx.Tx, s *models.StudentSuspension, from, to models.EnrollmentStatus) ([]int, error) {
+	sql, args, err := r.DB.SQ.Update(enrollmentTable).
+		Set("status", to).
+		Where(squirrel.Eq{"student_id": s.StudentID, "college_id": s.CollegeID, "status": from}).
+		Suffix("RETURNING course_id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build enrollment query: %w", err)
+	}
+	courseIDs := []int{}
+	if err := pgxscan.Select(ctx, tx, &courseIDs, sql, args...); err != nil {
+		return nil, err
+	}
+	return courseIDs, nil
+}
+
+func (r *studentSuspensionRepository) setStudentActive(ctx context.Context, tx pgx.Tx, collegeID, studentID int, active bool) error {
+	sql, args, err := r.DB.SQ.Update(studentTable).
+		Set("is_active", active).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": studentID, "college_id": collegeID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build student query: %w", err)
+	}
+	commandTag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update student: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("student %d not found in college %d", studentID, collegeID)
+	}
+	return nil
+}
@@ -0,0 +1,410 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	timetableDraftTable      = "timetable_drafts"
+	timetableDraftBlockTable = "timetable_draft_blocks"
+)
+
+var timetableDraftQueryFields = []string{
+	"id", "college_id", "term_id", "name", "status", "problem", "penalty", "warnings",
+	"created_by", "published_by", "published_at", "created_at",
+}
+
+var (
+	ErrTimetableDraftNotFound  = errors.New("timetable draft not found")
+	ErrTimetableDraftPublished = errors.New("timetable draft has already been published")
+)
+
+type TimetableDraftRepository interface {
+	// CreateDraft stores the draft and its blocks.
+	CreateDraft(ctx context.Context, draft *models.TimetableDraft) error
+	// GetDraft returns the draft with its blocks.
+	GetDraft(ctx context.Context, collegeID int, draftID int) (*models.TimetableDraft, error)
+	// FindDrafts lists drafts, newest first and without their blocks, in
+	// termID or in every term if it is nil.
+	FindDrafts(ctx context.Context, collegeID int, termID *int, limit, offset uint64) ([]*models.TimetableDraft, error)
+	// DeleteDraft removes a draft that has not been published.
+	DeleteDraft(ctx context.Context, collegeID int, draftID int) error
+	// PublishDraft replaces the draft's courses' timetable blocks in its term,
+	// or the current term if it has none, with the draft's blocks. Blocks in
+	// an unchanged slot keep their ID and lectures; the upcoming lectures of
+	// removed blocks go with them. If a block would clash with another it
+	// returns a *TimeTableClashError and publishes nothing.
+	PublishDraft(ctx context.Context, collegeID int, draftID int, publishedBy string) error
+}
+
+type timetableDraftRepository struct {
+	DB *DB
+}
+
+func NewTimetableDraftRepository(db *DB) TimetableDraftRepository {
+	return &timetableDraftRepository{DB: db}
+}
+
+func (r *timetableDraftRepository) CreateDraft(ctx context.Context, draft *models.TimetableDraft) error {
+	problem, err := json.Marshal(draft.Problem)
+	if err != nil {
+		return fmt.Errorf("CreateDraft: failed to encode problem: %w", err)
+	}
+	if draft.Warnings == nil {
+		draft.Warnings = []string{}
+	}
+	warnings, err := json.Marshal(draft.Warnings)
+	if err != nil {
+		return fmt.Errorf("CreateDraft: failed to encode warnings: %w", err)
+	}
+	draft.Status = models.TimetableDraftStatusDraft
+	draft.CreatedAt = time.Now()
+
+	sql, args, err := r.DB.SQ.Insert(timetableDraftTable).
+		Columns("college_id", "term_id", "name", "status", "problem", "penalty", "warnings", "created_by", "created_at").
+		Values(draft.CollegeID, draft.TermID, draft.Name, draft.Status, squirrel.Expr("?::jsonb", string(problem)),
+			draft.Penalty, squirrel.Expr("?::jsonb", string(warnings)), draft.CreatedBy, draft.CreatedAt).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CreateDraft: failed to build query: %w", err)
+	}
+
+	return r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, sql, args...).Scan(&draft.ID); err != nil {
+			return fmt.Errorf("CreateDraft: failed to insert draft: %w", err)
+		}
+		if len(draft.Blocks) == 0 {
+			return nil
+		}
+
+		insert := r.DB.SQ.Insert(timetableDraftBlockTable).
+			Columns("draft_id", "course_id", "class_id", "day_of_week", "start_time", "end_time", "room_number", "faculty_id").
+			Suffix("RETURNING id")
+		for _, b := range draft.Blocks {
+			insert = insert.Values(draft.ID, b.CourseID, b.ClassID, b.DayOfWeek, b.StartTime, b.EndTime, b.RoomNumber, b.FacultyID)
+		}
+		sql, args, err := insert.ToSql()
+		if err != nil {
+			return fmt.Errorf("CreateDraft: failed to build blocks query: %w", err)
+		}
+		rows, err := tx.Query(ctx, sql, args...)
+		if err != nil {
+			return fmt.Errorf("CreateDraft: failed to insert blocks: %w", err)
+		}
+		defer rows.Close()
+		for i := 0; rows.Next(); i++ {
+			b := draft.Blocks[i]
+			if err := rows.Scan(&b.ID); err != nil {
+				return fmt.Errorf("CreateDraft: failed to scan block ID: %w", err)
+			}
+			b.CollegeID, b.TermID = draft.CollegeID, draft.TermID
+		}
+		return rows.Err()
+	})
+}
+
+func (r *timetableDraftRepository) GetDraft(ctx context.Context, collegeID int, draftID int) (*models.TimetableDraft, error) {
+	sql, args, err := r.DB.SQ.Select(timetableDraftQueryFields...).
+		From(timetableDraftTable).
+		Where(squirrel.Eq{"id": draftID, "college_id": collegeID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("GetDraft: failed to build query: %w", err)
+	}
+
+	draft := &models.TimetableDraft{}
+	if err := pgxscan.Get(ctx, r.DB.Pool, draft, sql, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("GetDraft: draft %d: %w", draftID, ErrTimetableDraftNotFound)
+		}
+		return nil, fmt.Errorf("GetDraft: failed to execute query or scan: %w", err)
+	}
+
+	draft.Blocks, err = r.draftBlocks(ctx, r.DB.Pool, draft)
+	if err != nil {
+		return nil, fmt.Errorf("GetDraft: %w", err)
+	}
+	return draft, nil
+}
+
+func (r *timetableDraftRepository) FindDrafts(ctx context.Context, collegeID int, termID *int, limit, offset uint64) ([]*models.TimetableDraft, error) {
+	query := r.DB.SQ.Select(timetableDraftQueryFields...).
+		From(timetableDraftTable).
+		Where(squirrel.Eq{"college_id": collegeID}).
+		OrderBy("created_at DESC", "id DESC").
+		Limit(limit).
+		Offset(offset)
+	if termID != nil {
+		query = query.Where(squirrel.Eq{"term_id": *termID})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("FindDrafts: failed to build query: %w", err)
+	}
+
+	drafts := []*models.TimetableDraft{}
+	if err := pgxscan.Select(ctx, r.DB.Pool, &drafts, sql, args...); err != nil {
+		return nil, fmt.Errorf("FindDrafts: failed to execute query or scan: %w", err)
+	}
+	return drafts, nil
+}
+
+func (r *timetableDraftRepository) DeleteDraft(ctx context.Context, collegeID int, draftID int) error {
+	return r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if _, err := r.lockDraft(ctx, tx, collegeID, draftID); err != nil {
+			return fmt.Errorf("DeleteDraft: %w", err)
+		}
+		sql, args, err := r.DB.SQ.Delete(timetableDraftTable).
+			Where(squirrel.Eq{"id": draftID, "college_id": collegeID}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("DeleteDraft: failed to build query: %w", err)
+		}
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return fmt.Errorf("DeleteDraft: failed to execute query: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *timetableDraftRepository) PublishDraft(ctx context.Context, collegeID int, draftID int, publishedBy string) error {
+	return r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		draft, err := r.lockDraft(ctx, tx, collegeID, draftID)
+		if err != nil {
+			return fmt.Errorf("PublishDraft: %w", err)
+		}
+		termID, err := r.publishTerm(ctx, tx, draft)
+		if err != nil {
+			return fmt.Errorf("PublishDraft: %w", err)
+		}
+		blocks, err := r.draftBlocks(ctx, tx, draft)
+		if err != nil {
+			return fmt.Errorf("PublishDraft: %w", err)
+		}
+
+		courseIDs := []int{}
+		seen := map[int]bool{}
+		for _, b := range blocks {
+			b.TermID = termID
+			if !seen[b.CourseID] {
+				seen[b.CourseID] = true
+				courseIDs = append(courseIDs, b.CourseID)
+			}
+		}
+		current, err := r.publishedBlocks(ctx, tx, collegeID, courseIDs, termID)
+		if err != nil {
+			return fmt.Errorf("PublishDraft: %w", err)
+		}
+
+		// Blocks keeping their slot are updated in place, so the lectures
+		// generated from them stay linked and are not generated again.
+		unused := map[string][]int{}
+		for _, b := range current {
+			unused[blockSlot(b)] = append(unused[blockSlot(b)], b.ID)
+		}
+		for _, b := range blocks {
+			b.ID = 0 // A draft block ID, not a timetable block's
+			if ids := unused[blockSlot(b)]; len(ids) > 0 {
+				b.ID, unused[blockSlot(b)] = ids[0], ids[1:]
+			}
+		}
+		removed := []int{}
+		for _, ids := range unused {
+			removed = append(removed, ids...)
+		}
+		if err := r.removeBlocks(ctx, tx, collegeID, removed); err != nil {
+			return fmt.Errorf("PublishDraft: %w", err)
+		}
+
+		now := time.Now()
+		for _, b := range blocks {
+			b.UpdatedAt = now
+			if b.ID != 0 {
+				sql, args, err := r.DB.SQ.Update(timeTableBlockTable).
+					Set("room_number", b.RoomNumber).
+					Set("faculty_id", b.FacultyID).
+					Set("updated_at", b.UpdatedAt).
+					Where(squirrel.Eq{"id": b.ID}).
+					ToSql()
+				if err != nil {
+					return fmt.Errorf("PublishDraft: failed to build update query: %w", err)
+				}
+				if _, err := tx.Exec(ctx, sql, args...); err != nil {
+					return fmt.Errorf("PublishDraft: failed to update timetable block: %w", err)
+				}
+				continue
+			}
+			b.CreatedAt = now
+			sql, args, err := r.DB.SQ.Insert(timeTableBlockTable).
+				Columns("college_id", "course_id", "term_id", "class_id", "day_of_week", "start_time", "end_time", "room_number", "faculty_id", "created_at", "updated_at").
+				Values(collegeID, b.CourseID, b.TermID, b.ClassID, b.DayOfWeek, b.StartTime, b.EndTime, b.RoomNumber, b.FacultyID, b.CreatedAt, b.UpdatedAt).
+				Suffix("RETURNING id").
+				ToSql()
+			if err != nil {
+				return fmt.Errorf("PublishDraft: failed to build insert query: %w", err)
+			}
+			if err := tx.QueryRow(ctx, sql, args...).Scan(&b.ID); err != nil {
+				return fmt.Errorf("PublishDraft: failed to insert timetable block: %w", err)
+			}
+		}
+
+		// Checked once every block is written, against the timetable as published
+		for _, b := range blocks {
+			if err := checkTimeTableClashes(ctx, r.DB, tx, b); err != nil {
+				return err
+			}
+		}
+
+		sql, args, err := r.DB.SQ.Update(timetableDraftTable).
+			Set("status", models.TimetableDraftStatusPublished).
+			Set("published_by", publishedBy).
+			Set("published_at", now).
+			Where(squirrel.Eq{"id": draftID}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("PublishDraft: failed to build update query: %w", err)
+		}
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return fmt.Errorf("PublishDraft: failed to mark draft published: %w", err)
+		}
+		return nil
+	})
+}
+
+// publishTerm is the draft's term, or the college's current term if it has
+// none, or nil if the college has no current term either.
+func (r *timetableDraftRepository) publishTerm(ctx context.Context, tx pgx.Tx, draft *models.TimetableDraft) (*int, error) {
+	if draft.TermID != nil {
+		return draft.TermID, nil
+	}
+	sql, args, err := r.DB.SQ.Select("id").
+		From(academicTermTable).
+		Where(squirrel.Eq{"college_id": draft.CollegeID, "is_current": true}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build current term query: %w", err)
+	}
+	var termID int
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&termID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to look up current term: %w", err)
+	}
+	return &termID, nil
+}
+
+// publishedBlocks returns the courses' timetable blocks in termID.
+func (r *timetableDraftRepository) publishedBlocks(ctx context.Context, tx pgx.Tx, collegeID int, courseIDs []int, termID *int) ([]*models.TimeTableBlock, error) {
+	sql, args, err := r.DB.SQ.Select(timeTableBlockQueryFields...).
+		From(timeTableBlockTable).
+		Where(squirrel.Eq{"college_id": collegeID, "course_id": courseIDs}).
+		Where("term_id IS NOT DISTINCT FROM ?", termID).
+		OrderBy("id ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build timetable blocks query: %w", err)
+	}
+	blocks := []*models.TimeTableBlock{}
+	if err := pgxscan.Select(ctx, tx, &blocks, sql, args...); err != nil {
+		return nil, fmt.Errorf("failed to load timetable blocks: %w", err)
+	}
+	return blocks, nil
+}
+
+// removeBlocks deletes the timetable blocks with the upcoming lectures
+// generated from them that nobody has attended yet. Past lectures are kept.
+func (r *timetableDraftRepository) removeBlocks(ctx context.Context, tx pgx.Tx, collegeID int, blockIDs []int) error {
+	if len(blockIDs) == 0 {
+		return nil
+	}
+	sql, args, err := r.DB.SQ.Delete(lectureTable + " l").
+		Where(squirrel.Eq{"l.college_id": collegeID, "l.timetable_block_id": blockIDs}).
+		Where(squirrel.Gt{"l.start_time": time.Now()}).
+		Where("NOT EXISTS (SELECT 1 FROM " + attendanceTable + " a WHERE a.lecture_id = l.id)").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build lecture delete query: %w", err)
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to delete upcoming lectures: %w", err)
+	}
+
+	sql, args, err = r.DB.SQ.Delete(timeTableBlockTable).
+		Where(squirrel.Eq{"college_id": collegeID, "id": blockIDs}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build delete query: %w", err)
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to delete timetable blocks: %w", err)
+	}
+	return nil
+}
+
+// blockSlot identifies when and for whom a block is taught; blocks with the
+// same slot generate the same lectures.
+func blockSlot(b *models.TimeTableBlock) string {
+	classID := 0
+	if b.ClassID != nil {
+		classID = *b.ClassID
+	}
+	return fmt.Sprintf("%d/%d/%d/%d/%d", b.CourseID, classID, b.DayOfWeek, b.StartTime.Microseconds, b.EndTime.Microseconds)
+}
+
+// lockDraft locks an unpublished draft for the rest of tx.
+func (r *timetableDraftRepository) lockDraft(ctx context.Context, tx pgx.Tx, collegeID int, draftID int) (*models.TimetableDraft, error) {
+	sql, args, err := r.DB.SQ.Select(timetableDraftQueryFields...).
+		From(timetableDraftTable).
+		Where(squirrel.Eq{"id": draftID, "college_id": collegeID}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build draft query: %w", err)
+	}
+
+	draft := &models.TimetableDraft{}
+	if err := pgxscan.Get(ctx, tx, draft, sql, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("draft %d: %w", draftID, ErrTimetableDraftNotFound)
+		}
+		return nil, fmt.Errorf("failed to lock draft: %w", err)
+	}
+	if draft.Status == models.TimetableDraftStatusPublished {
+		return nil, ErrTimetableDraftPublished
+	}
+	return draft, nil
+}
+
+// draftBlocks loads the draft's blocks, carrying its college and term.
+func (r *timetableDraftRepository) draftBlocks(ctx context.Context, q pgxscan.Querier, draft *models.TimetableDraft) ([]*models.TimeTableBlock, error) {
+	sql, args, err := r.DB.SQ.Select("id", "course_id", "class_id", "day_of_week", "start_time", "end_time", "room_number", "faculty_id").
+		From(timetableDraftBlockTable).
+		Where(squirrel.Eq{"draft_id": draft.ID}).
+		OrderBy("day_of_week ASC", "start_time ASC", "id ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build blocks query: %w", err)
+	}
+
+	blocks := []*models.TimeTableBlock{}
+	if err := pgxscan.Select(ctx, q, &blocks, sql, args...); err != nil {
+		return nil, fmt.Errorf("failed to load draft blocks: %w", err)
+	}
+	for _, b := range blocks {
+		b.CollegeID, b.TermID = draft.CollegeID, draft.TermID
+	}
+	return blocks, nil
+}
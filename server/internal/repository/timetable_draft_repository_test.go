@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgtype"
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func clockAt(hour int) pgtype.Time {
+	return pgtype.Time{Microseconds: int64(hour) * int64(time.Hour/time.Microsecond), Status: pgtype.Present}
+}
+
+func TestPublishDraftKeepsUnchangedSlots(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+	repo := &timetableDraftRepository{DB: &DB{Pool: mock, SQ: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}}
+	ctx := context.Background()
+	newRoom, oldRoom := "B-204", "A-101"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT .* FROM timetable_drafts WHERE college_id = \$1 AND id = \$2 FOR UPDATE`).
+		WithArgs(1, 3).
+		WillReturnRows(pgxmock.NewRows(timetableDraftQueryFields).
+			AddRow(3, 1, (*int)(nil), "Fall", models.TimetableDraftStatusDraft, models.TimetableProblem{}, 0, []string{}, "admin-1", (*string)(nil), (*time.Time)(nil), time.Now()))
+	// A draft without a term publishes into the current one
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM academic_terms WHERE college_id = $1 AND is_current = $2`)).
+		WithArgs(1, true).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM timetable_draft_blocks WHERE draft_id = $1`)).
+		WithArgs(3).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "course_id", "class_id", "day_of_week", "start_time", "end_time", "room_number", "faculty_id"}).
+			AddRow(100, 7, (*int)(nil), time.Monday, clockAt(9), clockAt(10), &newRoom, (*string)(nil)).
+			AddRow(101, 7, (*int)(nil), time.Thursday, clockAt(11), clockAt(12), (*string)(nil), (*string)(nil)))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM timetable_blocks WHERE college_id = $1 AND course_id IN ($2) AND term_id IS NOT DISTINCT FROM $3 ORDER BY id ASC`)).
+		WithArgs(1, 7, pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows(timeTableBlockQueryFields).
+			AddRow(30, 1, (*int)(nil), 7, intPtr(5), (*int)(nil), time.Monday, clockAt(9), clockAt(10), &oldRoom, (*string)(nil), time.Now(), time.Now()).
+			AddRow(31, 1, (*int)(nil), 7, intPtr(5), (*int)(nil), time.Wednesday, clockAt(9), clockAt(10), (*string)(nil), (*string)(nil), time.Now(), time.Now()))
+	// Only the dropped slot loses its block and its unattended upcoming lectures
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM lectures l WHERE l.college_id = $1 AND l.timetable_block_id IN ($2) AND l.start_time > $3 AND NOT EXISTS (SELECT 1 FROM attendance a WHERE a.lecture_id = l.id)`)).
+		WithArgs(1, 31, pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("DELETE", 4))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM timetable_blocks WHERE college_id = $1 AND id IN ($2)`)).
+		WithArgs(1, 31).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE timetable_blocks SET room_number = $1, faculty_id = $2, updated_at = $3 WHERE id = $4`)).
+		WithArgs(&newRoom, (*string)(nil), pgxmock.AnyArg(), 30).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO timetable_blocks`)).
+		WithArgs(1, 7, intPtr(5), (*int)(nil), time.Thursday, clockAt(11), clockAt(12), (*string)(nil), (*string)(nil), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(32))
+	// Clashes are checked against the timetable as published, in its term
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock(hashtext($1), $2)`)).
+		WithArgs("timetable_blocks", 1).
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectQuery(`FROM timetable_blocks WHERE .* id <> \$\d+ .* term_id = \$\d+`).
+		WillReturnRows(pgxmock.NewRows(timeTableBlockQueryFields))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE timetable_drafts SET status = $1, published_by = $2, published_at = $3 WHERE id = $4`)).
+		WithArgs(models.TimetableDraftStatusPublished, "admin-2", pgxmock.AnyArg(), 3).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.PublishDraft(ctx, 1, 3, "admin-2"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -16,14 +16,36 @@ import (
 const timeTableBlockTable = "timetable_blocks"
 
 var timeTableBlockQueryFields = []string{
-	"id", "college_id", "department_id", "course_id", "class_id",
+	"id", "college_id", "department_id", "course_id", "term_id", "class_id",
 	"day_of_week", "start_time", "end_time", "room_number", "faculty_id",
 	"created_at", "updated_at",
 }
 
+var (
+	ErrTimeTableBlockNotFound = errors.New("timetable block not found")
+	ErrTimeTableClash         = errors.New("timetable block clashes with existing blocks")
+)
+
+// TimeTableClashError lists the blocks that share a room, faculty member or
+// class with a block at overlapping times on the same day of the same term.
+type TimeTableClashError struct {
+	Clashes []*models.TimeTableBlock `json:"clashes"`
+}
+
+func (e *TimeTableClashError) Error() string {
+	return fmt.Sprintf("timetable block clashes with %d existing block(s)", len(e.Clashes))
+}
+
+func (e *TimeTableClashError) Unwrap() error {
+	return ErrTimeTableClash
+}
+
 type TimeTableRepository interface {
+	// CreateTimeTableBlock stores the block unless it clashes with another
+	// block in its term, in which case it returns a *TimeTableClashError.
 	CreateTimeTableBlock(ctx context.Context, block *models.TimeTableBlock) error
 	GetTimeTableBlockByID(ctx context.Context, blockID int, collegeID int) (*models.TimeTableBlock, error)
+	// UpdateTimeTableBlock refuses clashing changes like CreateTimeTableBlock.
 	UpdateTimeTableBlock(ctx context.Context, block *models.TimeTableBlock) error
 	DeleteTimeTableBlock(ctx context.Context, blockID int, collegeID int) error
 	GetTimeTableBlocks(ctx context.Context, filter models.TimeTableBlockFilter) ([]*models.TimeTableBlock, error)
@@ -45,27 +67,32 @@ func (r *timetableRepository) CreateTimeTableBlock(ctx context.Context, block *m
 
 	query := r.DB.SQ.Insert(timeTableBlockTable).
 		Columns(
-			"college_id", "department_id", "course_id", "class_id",
+			"college_id", "department_id", "course_id", "term_id", "class_id",
 			"day_of_week", "start_time", "end_time", "room_number", "faculty_id",
 			"created_at", "updated_at",
 		).
 		Values(
-			block.CollegeID, block.DepartmentID, block.CourseID, block.ClassID,
+			block.CollegeID, block.DepartmentID, block.CourseID, termOrDefault(block.TermID, currentTerm(block.CollegeID)), block.ClassID,
 			block.DayOfWeek, block.StartTime, block.EndTime, block.RoomNumber, block.FacultyID,
 			block.CreatedAt, block.UpdatedAt,
 		).
-		Suffix("RETURNING id")
+		Suffix("RETURNING id, term_id")
 
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return fmt.Errorf("CreateTimeTableBlock: failed to build query: %w", err)
 	}
 
-	err = r.DB.Pool.QueryRow(ctx, sql, args...).Scan(&block.ID)
-	if err != nil {
-		return fmt.Errorf("CreateTimeTableBlock: failed to execute query or scan ID: %w", err)
-	}
-	return nil
+	return r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if err := checkTimeTableClashes(ctx, r.DB, tx, block); err != nil {
+			return err
+		}
+		err := tx.QueryRow(ctx, sql, args...).Scan(&block.ID, &block.TermID)
+		if err != nil {
+			return fmt.Errorf("CreateTimeTableBlock: failed to execute query or scan ID: %w", err)
+		}
+		return nil
+	})
 }
 
 func (r *timetableRepository) GetTimeTableBlockByID(ctx context.Context, blockID int, collegeID int) (*models.TimeTableBlock, error) {
@@ -82,7 +109,7 @@ func (r *timetableRepository) GetTimeTableBlockByID(ctx context.Context, blockID
 	err = pgxscan.Get(ctx, r.DB.Pool, block, sql, args...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("GetTimeTableBlockByID: block with ID %d for college ID %d not found: %w", blockID, collegeID, err)
+			return nil, fmt.Errorf("GetTimeTableBlockByID: block with ID %d for college ID %d: %w", blockID, collegeID, ErrTimeTableBlockNotFound)
 		}
 		return nil, fmt.Errorf("GetTimeTableBlockByID: failed to execute query or scan: %w", err)
 	}
@@ -103,19 +130,78 @@ func (r *timetableRepository) UpdateTimeTableBlock(ctx context.Context, block *m
 		Set("faculty_id", block.FacultyID).
 		Set("updated_at", block.UpdatedAt).
 		Where(squirrel.Eq{"id": block.ID, "college_id": block.CollegeID})
+	if block.TermID != nil {
+		query = query.Set("term_id", *block.TermID)
+	}
 
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return fmt.Errorf("UpdateTimeTableBlock: failed to build query: %w", err)
 	}
 
-	commandTag, err := r.DB.Pool.Exec(ctx, sql, args...)
+	return r.DB.WithTx(ctx, func(tx pgx.Tx) error {
+		if err := checkTimeTableClashes(ctx, r.DB, tx, block); err != nil {
+			return err
+		}
+		commandTag, err := tx.Exec(ctx, sql, args...)
+		if err != nil {
+			return fmt.Errorf("UpdateTimeTableBlock: failed to execute query: %w", err)
+		}
+		if commandTag.RowsAffected() == 0 {
+			return fmt.Errorf("UpdateTimeTableBlock: block with ID %d for college ID %d: %w", block.ID, block.CollegeID, ErrTimeTableBlockNotFound)
+		}
+		return nil
+	})
+}
+
+// checkTimeTableClashes returns a *TimeTableClashError if another block in the
+// block's term meets on the same day at an overlapping time in the same room,
+// with the same faculty member or for the same class. Blocks that only touch
+// end to start do not clash. A per-college advisory lock held until tx ends
+// keeps two concurrent writes from both passing the check.
+func checkTimeTableClashes(ctx context.Context, db *DB, tx pgx.Tx, block *models.TimeTableBlock) error {
+	shared := squirrel.Or{}
+	if block.RoomNumber != nil {
+		shared = append(shared, squirrel.Expr("LOWER(room_number) = LOWER(?)", *block.RoomNumber))
+	}
+	if block.FacultyID != nil {
+		shared = append(shared, squirrel.Eq{"faculty_id": *block.FacultyID})
+	}
+	if block.ClassID != nil {
+		shared = append(shared, squirrel.Eq{"class_id": *block.ClassID})
+	}
+	if len(shared) == 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext($1), $2)", timeTableBlockTable, block.CollegeID); err != nil {
+		return fmt.Errorf("checkTimeTableClashes: failed to lock timetable: %w", err)
+	}
+
+	query := db.SQ.Select(timeTableBlockQueryFields...).
+		From(timeTableBlockTable).
+		Where(squirrel.Eq{"college_id": block.CollegeID, "day_of_week": block.DayOfWeek}).
+		Where(squirrel.NotEq{"id": block.ID}).
+		Where("start_time < ? AND end_time > ?", block.EndTime, block.StartTime).
+		Where(shared).
+		OrderBy("start_time ASC", "id ASC")
+	if block.TermID != nil {
+		query = query.Where(squirrel.Eq{"term_id": *block.TermID})
+	} else {
+		query = query.Where(squirrel.Expr("term_id IS NOT DISTINCT FROM (?)", currentTerm(block.CollegeID)))
+	}
+
+	sql, args, err := query.ToSql()
 	if err != nil {
-		return fmt.Errorf("UpdateTimeTableBlock: failed to execute query: %w", err)
+		return fmt.Errorf("checkTimeTableClashes: failed to build query: %w", err)
 	}
 
-	if commandTag.RowsAffected() == 0 {
-		return fmt.Errorf("UpdateTimeTableBlock: no block found with ID %d for college ID %d, or no changes made", block.ID, block.CollegeID)
+	var clashes []*models.TimeTableBlock
+	if err := pgxscan.Select(ctx, tx, &clashes, sql, args...); err != nil {
+		return fmt.Errorf("checkTimeTableClashes: failed to execute query or scan: %w", err)
+	}
+	if len(clashes) > 0 {
+		return &TimeTableClashError{Clashes: clashes}
 	}
 	return nil
 }
@@ -135,7 +221,7 @@ func (r *timetableRepository) DeleteTimeTableBlock(ctx context.Context, blockID
 	}
 
 	if commandTag.RowsAffected() == 0 {
-		return fmt.Errorf("DeleteTimeTableBlock: no block found with ID %d for college ID %d", blockID, collegeID)
+		return fmt.Errorf("DeleteTimeTableBlock: block with ID %d for college ID %d: %w", blockID, collegeID, ErrTimeTableBlockNotFound)
 	}
 	return nil
 }
@@ -149,6 +235,9 @@ func (r *timetableRepository) applyTimeTableBlockFilter(query squirrel.SelectBui
 	if filter.CourseID != nil {
 		query = query.Where(squirrel.Eq{"course_id": *filter.CourseID})
 	}
+	if filter.TermID != nil {
+		query = query.Where(squirrel.Eq{"term_id": *filter.TermID})
+	}
 	if filter.ClassID != nil {
 		query = query.Where(squirrel.Eq{"class_id": *filter.ClassID})
 	}
@@ -158,6 +247,9 @@ func (r *timetableRepository) applyTimeTableBlockFilter(query squirrel.SelectBui
 	if filter.FacultyID != nil {
 		query = query.Where(squirrel.Eq{"faculty_id": *filter.FacultyID})
 	}
+	if filter.RoomNumber != nil {
+		query = query.Where("LOWER(room_number) = LOWER(?)", *filter.RoomNumber)
+	}
 	return query
 }
 
@@ -13,6 +13,8 @@ import (
 	"github.com/jackc/pgx/v4"
 )
 
+var ErrUserNotFound = errors.New("user not found")
+
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *models.User) error
 
@@ -106,7 +108,7 @@ func (u *userRepository) GetUserByID(ctx context.Context, userID int) (*models.U
 	err = pgxscan.Get(ctx, u.DB.Pool, user, sql, args...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("GetUserByID: user with ID %d not found", userID)
+			return nil, fmt.Errorf("GetUserByID: user with ID %d: %w", userID, ErrUserNotFound)
 		}
 		return nil, fmt.Errorf("GetUserByID: failed to execute query or scan: %w", err)
 	}
@@ -0,0 +1,66 @@
+package attendance
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"eduhub/server/internal/repository"
+)
+
+// AbsenteeScheduler periodically records Absent for students who never marked
+// attendance in a lecture that has ended. It is safe to run on every replica:
+// the repository claims each lecture exactly once.
+type AbsenteeScheduler struct {
+	repo      repository.AttendanceRepository
+	interval  time.Duration
+	batchSize int
+	now       func() time.Time
+}
+
+func NewAbsenteeScheduler(repo repository.AttendanceRepository, interval time.Duration, batchSize int) *AbsenteeScheduler {
+	return &AbsenteeScheduler{
+		repo:      repo,
+		interval:  interval,
+		batchSize: batchSize,
+		now:       time.Now,
+	}
+}
+
+// Run processes ended lectures every interval until ctx is cancelled.
+func (s *AbsenteeScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		lectures, marked, err := s.RunOnce(ctx)
+		if err != nil {
+			log.Printf("absentee job: %v", err)
+		} else if lectures > 0 {
+			log.Printf("absentee job: processed %d lectures, recorded %d missing attendances", lectures, marked)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce drains every lecture that has ended, one batch per transaction.
+func (s *AbsenteeScheduler) RunOnce(ctx context.Context) (int, int, error) {
+	now := s.now()
+	totalLectures, totalMarked := 0, 0
+	for ctx.Err() == nil {
+		lectures, marked, err := s.repo.MarkAbsentees(ctx, now, uint64(s.batchSize))
+		if err != nil {
+			return totalLectures, totalMarked, err
+		}
+		totalLectures += lectures
+		totalMarked += marked
+		if lectures < s.batchSize {
+			break
+		}
+	}
+	return totalLectures, totalMarked, nil
+}
@@ -0,0 +1,58 @@
+package attendance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"eduhub/server/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type absenteeAttendanceRepo struct {
+	repository.AttendanceRepository
+	pending int
+	failAt  int
+	calls   int
+}
+
+func (f *absenteeAttendanceRepo) MarkAbsentees(ctx context.Context, now time.Time, limit uint64) (int, int, error) {
+	f.calls++
+	if f.calls == f.failAt {
+		return 0, 0, errors.New("connection lost")
+	}
+	n := min(f.pending, int(limit))
+	f.pending -= n
+	return n, n * 30, nil
+}
+
+func TestAbsenteeSchedulerRunOnceDrainsAllBatches(t *testing.T) {
+	repo := &absenteeAttendanceRepo{pending: 5}
+	scheduler := NewAbsenteeScheduler(repo, time.Minute, 2)
+
+	lectures, marked, err := scheduler.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5, lectures)
+	assert.Equal(t, 150, marked)
+	assert.Equal(t, 3, repo.calls)
+	assert.Zero(t, repo.pending)
+
+	// nothing left: one empty claim and no further work
+	lectures, _, err = scheduler.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, lectures)
+	assert.Equal(t, 4, repo.calls)
+}
+
+func TestAbsenteeSchedulerRunOnceStopsOnError(t *testing.T) {
+	repo := &absenteeAttendanceRepo{pending: 5, failAt: 2}
+	scheduler := NewAbsenteeScheduler(repo, time.Minute, 2)
+
+	lectures, _, err := scheduler.RunOnce(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, 2, lectures)
+	assert.Equal(t, 3, repo.pending)
+}
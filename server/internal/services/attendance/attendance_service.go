@@ -2,7 +2,10 @@ package attendance
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"time"
 
 	"eduhub/server/internal/models"
 	"eduhub/server/internal/repository"
@@ -11,33 +14,67 @@ import (
 const (
 	Present = "Present"
 	Absent  = "Absent"
+	Late    = "Late"
 	Freezed = "Freezed"
 )
 
+// MaxStudentDevices is how many active devices a student may register for
+// attendance scans.
+const MaxStudentDevices = 2
+
+// DefaultShortageThreshold is the minimum attendance percentage a student needs in a course.
+const DefaultShortageThreshold = 75.0
+
 type AttendanceService interface {
 	GenerateQRCode(ctx context.Context, collegeID, courseID int, lectureID int) (string, error)
 	GetAttendanceByLecture(ctx context.Context, collegeID, courseID int, lectureID int, limit, offset uint64) ([]*models.Attendance, error)
 	GetAttendanceByCourse(ctx context.Context, collegeID, courseID int, limit, offset uint64) ([]*models.Attendance, error)
 	GetAttendanceByStudent(ctx context.Context, collegeID, studentID int, limit, offset uint64) ([]*models.Attendance, error)
 	GetAttendanceByStudentAndCourse(ctx context.Context, collegeID, studentID int, courseID int, limit, offset uint64) ([]*models.Attendance, error)
-	MarkAttendance(ctx context.Context, collegeID int, studentID int, courseID int, lectureID int) (bool, error)
-	UpdateAttendanceStatus(ctx context.Context, collegeID, studentID int, courseID int, lectureID int, newStatus string) (bool, error)
-	FreezeAttendance(ctx context.Context, collegeID, studentID int) (bool, error)
+	// Mutations take the acting identity for the attendance audit log; an empty
+	// Source is filled in with the default for that path.
+	UpdateAttendanceStatus(ctx context.Context, collegeID, studentID int, courseID int, lectureID int, newStatus string, audit models.AuditInfo) (bool, error)
+	FreezeAttendance(ctx context.Context, collegeID, studentID int, audit models.AuditInfo) (bool, error)
+	UnFreezeAttendance(ctx context.Context, collegeID, studentID int, audit models.AuditInfo) (bool, error)
 	VerifyStudentStateAndEnrollment(ctx context.Context, collegeID, studentID, courseID int) (bool, error)
-	ProcessQRCode(ctx context.Context, collegeID int, studentID int, qrCodeContent string) error
-	MarkBulkAttendance(ctx context.Context, collegeID, courseID, lectureID int, studentStatuses []models.StudentAttendanceStatus) error
+	ProcessQRCode(ctx context.Context, collegeID int, studentID int, qrCodeContent string, meta models.ScanMetadata, audit models.AuditInfo) (*models.AttendanceScan, error)
+	RegisterDevice(ctx context.Context, collegeID, studentID int, device *models.StudentDevice) error
+	GetStudentDevices(ctx context.Context, collegeID, studentID int) ([]*models.StudentDevice, error)
+	RevokeDevice(ctx context.Context, collegeID, studentID, deviceID int) error
+	GetLectureScans(ctx context.Context, collegeID, courseID, lectureID int, flaggedOnly bool, limit, offset uint64) ([]*models.AttendanceScan, error)
+	MarkBulkAttendance(ctx context.Context, collegeID, courseID, lectureID int, studentStatuses []models.StudentAttendanceStatus, mode string, audit models.AuditInfo) ([]*models.BulkAttendanceResult, error)
+	// Reports and the register cover termID, or the current term if it is unset.
+	GetCourseAttendanceReport(ctx context.Context, collegeID, courseID int, termID *int) (*models.CourseAttendanceReport, error)
+	GetAttendanceShortage(ctx context.Context, collegeID, courseID int, termID *int, threshold float64) (*models.CourseAttendanceReport, error)
+	GetAttendanceHistory(ctx context.Context, collegeID, attendanceID int) ([]*models.AttendanceAuditEntry, error)
+	GetLectureAttendanceHistory(ctx context.Context, collegeID, courseID, lectureID int, limit, offset uint64) ([]*models.AttendanceAuditEntry, error)
+	ExportAttendanceRegister(ctx context.Context, collegeID, courseID int, termID *int, from, to time.Time, format string, w io.Writer) error
+	GetAttendancePolicy(ctx context.Context, collegeID int) (*models.AttendancePolicy, error)
+	UpdateAttendancePolicy(ctx context.Context, policy *models.AttendancePolicy) error
 }
 type attendanceService struct {
 	repo           repository.AttendanceRepository
 	studentRepo    repository.StudentRepository
 	enrollmentRepo repository.EnrollmentRepository
+	lectureRepo    repository.LectureRepository
+	qrCodeRepo     repository.QRCodeRepository
+	scanRepo       repository.AttendanceScanRepository
+	deviceRepo     repository.StudentDeviceRepository
+	policyRepo     repository.AttendancePolicyRepository
+	qrSigner       *QRTokenSigner
 }
 
-func NewAttendanceService(repo repository.AttendanceRepository, studentRepo repository.StudentRepository, enrollmentRepo repository.EnrollmentRepository) AttendanceService {
+func NewAttendanceService(repo repository.AttendanceRepository, studentRepo repository.StudentRepository, enrollmentRepo repository.EnrollmentRepository, lectureRepo repository.LectureRepository, qrCodeRepo repository.QRCodeRepository, scanRepo repository.AttendanceScanRepository, deviceRepo repository.StudentDeviceRepository, policyRepo repository.AttendancePolicyRepository, qrSigner *QRTokenSigner) AttendanceService {
 	return &attendanceService{
 		repo:           repo,
 		studentRepo:    studentRepo,
 		enrollmentRepo: enrollmentRepo,
+		lectureRepo:    lectureRepo,
+		qrCodeRepo:     qrCodeRepo,
+		scanRepo:       scanRepo,
+		deviceRepo:     deviceRepo,
+		policyRepo:     policyRepo,
+		qrSigner:       qrSigner,
 	}
 }
 
@@ -58,26 +95,6 @@ func (a *attendanceService) GetAttendanceByStudentAndCourse(ctx context.Context,
 	return a.repo.GetAttendanceStudentInCourse(ctx, collegeID, studentID, courseID, limit, offset)
 }
 
-// manually mark attendance for a student
-func (a *attendanceService) MarkAttendance(ctx context.Context, collegeID int, studentID, courseID, lectureID int) (bool, error) {
-	ok, err := a.VerifyStudentStateAndEnrollment(ctx, collegeID, studentID, courseID)
-
-	if !ok {
-		return false, err
-	}
-	if err != nil {
-		return false, err
-	}
-
-	// Mark attendance only if all verifications pass
-	ok, err = a.repo.MarkAttendance(ctx, collegeID, studentID, courseID, lectureID)
-	if err != nil {
-		return false, fmt.Errorf("failed to mark attendance: %w", err)
-	}
-
-	return ok, nil
-}
-
 func (a *attendanceService) VerifyStudentStateAndEnrollment(ctx context.Context, collegeID int, studentID int, courseID int) (bool, error) {
 	student, err := a.studentRepo.GetStudentByID(ctx, collegeID, studentID)
 	if err != nil {
@@ -94,67 +111,220 @@ func (a *attendanceService) VerifyStudentStateAndEnrollment(ctx context.Context,
 	return exists, err
 }
 
-func (a *attendanceService) GenerateAndProcessQRCode(ctx context.Context, collegeID, studentID int, courseID int, lectureID int) error {
+func (a *attendanceService) GenerateAndProcessQRCode(ctx context.Context, collegeID, studentID int, courseID int, lectureID int, audit models.AuditInfo) error {
 	qrCode, err := a.GenerateQRCode(ctx, collegeID, courseID, lectureID)
 	if err != nil {
 		return nil
 	}
-	err = a.ProcessQRCode(ctx, collegeID, studentID, qrCode)
+	_, err = a.ProcessQRCode(ctx, collegeID, studentID, qrCode, models.ScanMetadata{}, audit)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (a *attendanceService) UpdateAttendanceStatus(ctx context.Context, collegeID, studentID int, courseID int, lectureID int, newStatus string) (bool, error) {
+func (a *attendanceService) UpdateAttendanceStatus(ctx context.Context, collegeID, studentID int, courseID int, lectureID int, newStatus string, audit models.AuditInfo) (bool, error) {
 	// Validate newStatus if necessary (e.g., ensure it's one of "Present", "Absent", "Late", etc.)
-	validStatuses := map[string]bool{Present: true, Absent: true, Freezed: true, "Late": true, "Excused": true}
+	validStatuses := map[string]bool{Present: true, Absent: true, Freezed: true, Late: true, "Excused": true}
 	if !validStatuses[newStatus] {
 		return false, fmt.Errorf("invalid attendance status: %s", newStatus)
 	}
 
 	// Directly update the specific attendance record
-	err := a.repo.UpdateAttendance(ctx, collegeID, studentID, courseID, lectureID, newStatus)
+	err := a.repo.UpdateAttendance(ctx, collegeID, studentID, courseID, lectureID, newStatus, withAuditSource(audit, models.AuditSourceManualUpdate))
 	if err != nil {
 		return false, fmt.Errorf("failed to update attendance status: %w", err)
 	}
 	return true, nil
 }
 
+// ErrBulkAttendanceIncomplete is returned alongside the per-student results
+// when some rows of a bulk request were rejected or not written.
+var ErrBulkAttendanceIncomplete = errors.New("bulk attendance was not fully applied")
+
+// ErrLectureNotInCourse is returned when attendance is marked against a
+// lecture of another course.
+var ErrLectureNotInCourse = errors.New("lecture does not belong to the course")
+
 // manually mark attendance of multiple students
-func (a *attendanceService) MarkBulkAttendance(ctx context.Context, collegeID, courseID, lectureID int, studentStatuses []models.StudentAttendanceStatus) error {
-	var errors []error
-
-	for _, studentStatus := range studentStatuses {
-		// 1. Verify student state and enrollment *before* attempting to mark
-		ok, err := a.VerifyStudentStateAndEnrollment(ctx, collegeID, studentStatus.StudentID, courseID)
-		if err != nil {
-			errors = append(errors, fmt.Errorf("error verifying student %d: %w", studentStatus.StudentID, err))
-			continue // Skip this student if verification fails
+// enrollment is verified for all students in one query; in atomic mode any
+// rejected row abandons the whole request and the writes share one transaction
+func (a *attendanceService) MarkBulkAttendance(ctx context.Context, collegeID, courseID, lectureID int, studentStatuses []models.StudentAttendanceStatus, mode string, audit models.AuditInfo) ([]*models.BulkAttendanceResult, error) {
+	audit = withAuditSource(audit, models.AuditSourceBulk)
+	if mode == "" {
+		mode = models.BulkModeAtomic
+	}
+	if mode != models.BulkModeAtomic && mode != models.BulkModeBestEffort {
+		return nil, fmt.Errorf("invalid bulk attendance mode: %s", mode)
+	}
+	lecture, err := a.lectureRepo.GetLectureByID(ctx, collegeID, lectureID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load lecture: %w", err)
+	}
+	if lecture.CourseID != courseID {
+		return nil, fmt.Errorf("%w: lecture %d, course %d", ErrLectureNotInCourse, lectureID, courseID)
+	}
+
+	results := make([]*models.BulkAttendanceResult, len(studentStatuses))
+	studentIDs := make([]int, 0, len(studentStatuses))
+	seen := make(map[int]bool, len(studentStatuses))
+	for i, st := range studentStatuses {
+		results[i] = &models.BulkAttendanceResult{StudentID: st.StudentID, Status: st.Status}
+		switch {
+		case st.Status != Present && st.Status != Absent:
+			results[i].Result, results[i].Error = models.BulkResultRejected, fmt.Sprintf("invalid attendance status: %s", st.Status)
+		case seen[st.StudentID]:
+			results[i].Result, results[i].Error = models.BulkResultRejected, "student appears more than once in the request"
+		default:
+			seen[st.StudentID] = true
+			studentIDs = append(studentIDs, st.StudentID)
+		}
+	}
+
+	enrolled, err := a.enrollmentRepo.FindEnrolledActiveStudentIDs(ctx, collegeID, courseID, studentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify enrollments: %w", err)
+	}
+
+	valid := make([]models.StudentAttendanceStatus, 0, len(studentIDs))
+	pending := make([]*models.BulkAttendanceResult, 0, len(studentIDs))
+	for i, st := range studentStatuses {
+		if results[i].Result != "" {
+			continue
 		}
-		if !ok {
-			errors = append(errors, fmt.Errorf("student %d not active or not enrolled in course %d", studentStatus.StudentID, courseID))
-			continue // Skip this student
+		if !enrolled[st.StudentID] {
+			results[i].Result, results[i].Error = models.BulkResultRejected, fmt.Sprintf("student not active or not enrolled in course %d", courseID)
+			continue
 		}
+		valid = append(valid, st)
+		pending = append(pending, results[i])
+	}
 
-		// 2. Call repository to set the specific status
-		err = a.repo.SetAttendanceStatus(ctx, collegeID, studentStatus.StudentID, courseID, lectureID, studentStatus.Status)
-		if err != nil {
-			errors = append(errors, fmt.Errorf("error setting attendance for student %d: %w", studentStatus.StudentID, err))
+	if mode == models.BulkModeAtomic {
+		if len(valid) < len(studentStatuses) {
+			setBulkResults(pending, models.BulkResultNotApplied)
+			return results, ErrBulkAttendanceIncomplete
+		}
+		if err := a.repo.SetBulkAttendanceStatus(ctx, collegeID, courseID, lectureID, valid, audit); err != nil {
+			setBulkResults(pending, models.BulkResultNotApplied)
+			var writeErr *repository.AttendanceWriteError
+			if errors.As(err, &writeErr) {
+				for _, r := range pending {
+					if r.StudentID == writeErr.StudentID {
+						r.Result, r.Error = models.BulkResultFailed, writeErr.Err.Error()
+					}
+				}
+			}
+			return results, fmt.Errorf("failed to mark bulk attendance: %w", err)
 		}
+		setBulkResults(pending, models.BulkResultMarked)
+		return results, nil
 	}
-	// Combine errors if any occurred, otherwise return nil
-	if len(errors) > 0 {
-		// You might want a more sophisticated error aggregation depending on needs
-		return fmt.Errorf("encountered %d error(s) during bulk attendance marking: %v", len(errors), errors)
+
+	complete := len(valid) == len(studentStatuses)
+	for i, st := range valid {
+		if err := a.repo.SetAttendanceStatus(ctx, collegeID, st.StudentID, courseID, lectureID, st.Status, audit); err != nil {
+			pending[i].Result, pending[i].Error = models.BulkResultFailed, err.Error()
+			complete = false
+			continue
+		}
+		pending[i].Result = models.BulkResultMarked
 	}
-	return nil
+	if !complete {
+		return results, ErrBulkAttendanceIncomplete
+	}
+	return results, nil
 }
 
-func (a *attendanceService) FreezeAttendance(ctx context.Context, collegeID, studentID int) (bool, error) {
-	err := a.repo.FreezeAttendance(ctx, collegeID, studentID)
+func setBulkResults(results []*models.BulkAttendanceResult, result string) {
+	for _, r := range results {
+		r.Result = result
+	}
+}
+
+func (a *attendanceService) FreezeAttendance(ctx context.Context, collegeID, studentID int, audit models.AuditInfo) (bool, error) {
+	err := a.repo.FreezeAttendance(ctx, collegeID, studentID, withAuditSource(audit, models.AuditSourceFreeze))
 	if err != nil {
 		return false, err
 	}
 	return true, nil
 }
+
+// frozen records go back to the status they had before the freeze
+func (a *attendanceService) UnFreezeAttendance(ctx context.Context, collegeID, studentID int, audit models.AuditInfo) (bool, error) {
+	err := a.repo.UnFreezeAttendance(ctx, collegeID, studentID, withAuditSource(audit, models.AuditSourceUnfreeze))
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (a *attendanceService) RegisterDevice(ctx context.Context, collegeID, studentID int, device *models.StudentDevice) error {
+	device.CollegeID = collegeID
+	device.StudentID = studentID
+	if device.DeviceFingerprint == "" {
+		return fmt.Errorf("device fingerprint is required")
+	}
+	return a.deviceRepo.RegisterDevice(ctx, device, MaxStudentDevices)
+}
+
+func (a *attendanceService) GetStudentDevices(ctx context.Context, collegeID, studentID int) ([]*models.StudentDevice, error) {
+	return a.deviceRepo.FindDevicesByStudent(ctx, collegeID, studentID)
+}
+
+func (a *attendanceService) RevokeDevice(ctx context.Context, collegeID, studentID, deviceID int) error {
+	return a.deviceRepo.RevokeDevice(ctx, collegeID, studentID, deviceID)
+}
+
+// scans recorded for a lecture; flaggedOnly limits the list to rejected scans
+func (a *attendanceService) GetLectureScans(ctx context.Context, collegeID, courseID, lectureID int, flaggedOnly bool, limit, offset uint64) ([]*models.AttendanceScan, error) {
+	return a.scanRepo.FindScansByLecture(ctx, collegeID, courseID, lectureID, flaggedOnly, limit, offset)
+}
+
+// attendance percentage of every enrolled student in the course
+func (a *attendanceService) GetCourseAttendanceReport(ctx context.Context, collegeID, courseID int, termID *int) (*models.CourseAttendanceReport, error) {
+	students, err := a.repo.GetCourseAttendanceReport(ctx, collegeID, courseID, termID)
+	if err != nil {
+		return nil, err
+	}
+	return newCourseAttendanceReport(courseID, students), nil
+}
+
+// students whose attendance percentage in the course is below threshold
+func (a *attendanceService) GetAttendanceShortage(ctx context.Context, collegeID, courseID int, termID *int, threshold float64) (*models.CourseAttendanceReport, error) {
+	if threshold <= 0 || threshold > 100 {
+		return nil, fmt.Errorf("invalid attendance threshold: %v", threshold)
+	}
+	students, err := a.repo.GetAttendanceShortage(ctx, collegeID, courseID, termID, threshold)
+	if err != nil {
+		return nil, err
+	}
+	report := newCourseAttendanceReport(courseID, students)
+	report.Threshold = &threshold
+	return report, nil
+}
+
+func newCourseAttendanceReport(courseID int, students []*models.StudentAttendanceReport) *models.CourseAttendanceReport {
+	report := &models.CourseAttendanceReport{CourseID: courseID, Students: students}
+	if len(students) > 0 {
+		report.TotalLectures = students[0].TotalLectures
+	}
+	return report
+}
+
+// history of one attendance record, oldest change first
+func (a *attendanceService) GetAttendanceHistory(ctx context.Context, collegeID, attendanceID int) ([]*models.AttendanceAuditEntry, error) {
+	return a.repo.GetAttendanceHistory(ctx, collegeID, attendanceID)
+}
+
+// every attendance change recorded for a lecture, oldest first
+func (a *attendanceService) GetLectureAttendanceHistory(ctx context.Context, collegeID, courseID, lectureID int, limit, offset uint64) ([]*models.AttendanceAuditEntry, error) {
+	return a.repo.GetLectureAttendanceHistory(ctx, collegeID, courseID, lectureID, limit, offset)
+}
+
+func withAuditSource(audit models.AuditInfo, source string) models.AuditInfo {
+	if audit.Source == "" {
+		audit.Source = source
+	}
+	return audit
+}
@@ -0,0 +1,139 @@
+package attendance
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bulkEnrollmentRepo struct {
+	repository.EnrollmentRepository
+	enrolled map[int]bool
+	queries  int
+}
+
+func (f *bulkEnrollmentRepo) FindEnrolledActiveStudentIDs(ctx context.Context, collegeID int, courseID int, studentIDs []int) (map[int]bool, error) {
+	f.queries++
+	found := map[int]bool{}
+	for _, id := range studentIDs {
+		if f.enrolled[id] {
+			found[id] = true
+		}
+	}
+	return found, nil
+}
+
+type bulkAttendanceRepo struct {
+	repository.AttendanceRepository
+	failFor int
+	written map[int]string
+}
+
+func (f *bulkAttendanceRepo) SetAttendanceStatus(ctx context.Context, collegeID int, studentID, courseID int, lectureID int, status string, audit models.AuditInfo) error {
+	if studentID == f.failFor {
+		return errors.New("write failed")
+	}
+	f.written[studentID] = status
+	return nil
+}
+
+func (f *bulkAttendanceRepo) SetBulkAttendanceStatus(ctx context.Context, collegeID int, courseID int, lectureID int, statuses []models.StudentAttendanceStatus, audit models.AuditInfo) error {
+	staged := map[int]string{}
+	for _, st := range statuses {
+		if st.StudentID == f.failFor {
+			return &repository.AttendanceWriteError{StudentID: st.StudentID, Err: errors.New("write failed")}
+		}
+		staged[st.StudentID] = st.Status
+	}
+	for id, status := range staged {
+		f.written[id] = status
+	}
+	return nil
+}
+
+func newBulkTestService(failFor int) (*attendanceService, *bulkAttendanceRepo, *bulkEnrollmentRepo) {
+	attendanceRepo := &bulkAttendanceRepo{failFor: failFor, written: map[int]string{}}
+	enrollmentRepo := &bulkEnrollmentRepo{enrolled: map[int]bool{1: true, 2: true, 3: true}}
+	lectureRepo := &fakeLectureRepo{lecture: models.Lecture{CourseID: 2}}
+	return &attendanceService{repo: attendanceRepo, enrollmentRepo: enrollmentRepo, lectureRepo: lectureRepo}, attendanceRepo, enrollmentRepo
+}
+
+func resultsByStudent(results []*models.BulkAttendanceResult) map[int]string {
+	out := map[int]string{}
+	for _, r := range results {
+		out[r.StudentID] = r.Result
+	}
+	return out
+}
+
+var bulkRequest = []models.StudentAttendanceStatus{
+	{StudentID: 1, Status: Present},
+	{StudentID: 2, Status: Absent},
+	{StudentID: 9, Status: Present}, // not enrolled
+}
+
+func TestMarkBulkAttendance_AtomicRejectsWholeRequest(t *testing.T) {
+	svc, attendanceRepo, enrollmentRepo := newBulkTestService(0)
+
+	results, err := svc.MarkBulkAttendance(context.Background(), 1, 2, 3, bulkRequest, models.BulkModeAtomic, testAudit)
+	assert.ErrorIs(t, err, ErrBulkAttendanceIncomplete)
+	assert.Equal(t, map[int]string{
+		1: models.BulkResultNotApplied,
+		2: models.BulkResultNotApplied,
+		9: models.BulkResultRejected,
+	}, resultsByStudent(results))
+	assert.Empty(t, attendanceRepo.written)
+	assert.Equal(t, 1, enrollmentRepo.queries)
+}
+
+func TestMarkBulkAttendance_AtomicWriteFailure(t *testing.T) {
+	svc, attendanceRepo, _ := newBulkTestService(2)
+
+	results, err := svc.MarkBulkAttendance(context.Background(), 1, 2, 3, bulkRequest[:2], models.BulkModeAtomic, testAudit)
+	require.Error(t, err)
+	assert.Equal(t, map[int]string{
+		1: models.BulkResultNotApplied,
+		2: models.BulkResultFailed,
+	}, resultsByStudent(results))
+	assert.Empty(t, attendanceRepo.written)
+}
+
+func TestMarkBulkAttendance_BestEffort(t *testing.T) {
+	svc, attendanceRepo, _ := newBulkTestService(2)
+	request := append(bulkRequest, models.StudentAttendanceStatus{StudentID: 1, Status: Absent}, models.StudentAttendanceStatus{StudentID: 3, Status: "Maybe"})
+
+	results, err := svc.MarkBulkAttendance(context.Background(), 1, 2, 3, request, models.BulkModeBestEffort, testAudit)
+	assert.ErrorIs(t, err, ErrBulkAttendanceIncomplete)
+	require.Len(t, results, 5)
+	assert.Equal(t, models.BulkResultMarked, results[0].Result)
+	assert.Equal(t, models.BulkResultFailed, results[1].Result)
+	assert.Equal(t, models.BulkResultRejected, results[2].Result)
+	assert.Equal(t, models.BulkResultRejected, results[3].Result) // duplicate
+	assert.Equal(t, models.BulkResultRejected, results[4].Result) // invalid status
+	assert.Equal(t, map[int]string{1: Present}, attendanceRepo.written)
+}
+
+func TestMarkBulkAttendance_AllMarked(t *testing.T) {
+	svc, attendanceRepo, _ := newBulkTestService(0)
+
+	results, err := svc.MarkBulkAttendance(context.Background(), 1, 2, 3, bulkRequest[:2], "", testAudit)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]string{1: models.BulkResultMarked, 2: models.BulkResultMarked}, resultsByStudent(results))
+	assert.Equal(t, map[int]string{1: Present, 2: Absent}, attendanceRepo.written)
+}
+
+func TestMarkBulkAttendance_LectureOfAnotherCourse(t *testing.T) {
+	svc, attendanceRepo, enrollmentRepo := newBulkTestService(0)
+
+	results, err := svc.MarkBulkAttendance(context.Background(), 1, 5, 3, bulkRequest[:2], models.BulkModeBestEffort, testAudit)
+	assert.ErrorIs(t, err, ErrLectureNotInCourse)
+	assert.Nil(t, results)
+	assert.Empty(t, attendanceRepo.written)
+	assert.Zero(t, enrollmentRepo.queries)
+}
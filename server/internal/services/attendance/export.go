@@ -0,0 +1,153 @@
+package attendance
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+)
+
+var ErrUnsupportedExportFormat = errors.New("unsupported export format")
+
+// registerDateLayout is used for lecture column headers. Lectures on the same
+// day are told apart by their start time.
+const registerDateLayout = "2006-01-02 15:04"
+
+// registerWriter receives the register one row at a time. Flush writes out
+// the finished register; Close releases the writer either way.
+type registerWriter interface {
+	WriteRow(values []string) error
+	Flush() error
+	Close() error
+}
+
+// ExportAttendanceRegister writes a student-by-lecture matrix for the course to w.
+// Rows are the term's students ordered by roll number, columns are lectures
+// starting in [from, to) ordered by date; zero times leave that side of the
+// range open.
+func (a *attendanceService) ExportAttendanceRegister(ctx context.Context, collegeID, courseID int, termID *int, from, to time.Time, format string, w io.Writer) error {
+	var out registerWriter
+	switch format {
+	case ExportFormatCSV:
+		out = newCSVRegisterWriter(w)
+	case ExportFormatXLSX:
+		xw, err := newXLSXRegisterWriter(w)
+		if err != nil {
+			return err
+		}
+		out = xw
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedExportFormat, format)
+	}
+	defer out.Close()
+
+	lectures, err := a.lectureRepo.FindLecturesByCourseInRange(ctx, collegeID, courseID, from, to)
+	if err != nil {
+		return err
+	}
+	lectureIDs := make([]int, len(lectures))
+	header := make([]string, 0, len(lectures)+1)
+	header = append(header, "Roll No")
+	for i, lecture := range lectures {
+		lectureIDs[i] = lecture.ID
+		header = append(header, lecture.StartTime.Format(registerDateLayout))
+	}
+	if err := out.WriteRow(header); err != nil {
+		return err
+	}
+
+	err = a.repo.StreamAttendanceRegister(ctx, collegeID, courseID, termID, lectureIDs, func(row *models.AttendanceRegisterRow) error {
+		values := make([]string, 0, len(lectureIDs)+1)
+		values = append(values, row.RollNo)
+		for _, id := range lectureIDs {
+			values = append(values, row.Statuses[id])
+		}
+		return out.WriteRow(values)
+	})
+	if err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+type csvRegisterWriter struct {
+	w *csv.Writer
+}
+
+func newCSVRegisterWriter(w io.Writer) *csvRegisterWriter {
+	return &csvRegisterWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvRegisterWriter) WriteRow(values []string) error {
+	return c.w.Write(values)
+}
+
+func (c *csvRegisterWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvRegisterWriter) Close() error {
+	return nil
+}
+
+// xlsxRegisterWriter uses excelize's stream writer, which spills rows to a
+// temporary file instead of holding the sheet in memory.
+type xlsxRegisterWriter struct {
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	out    io.Writer
+	row    int
+}
+
+const registerSheet = "Attendance"
+
+func newXLSXRegisterWriter(w io.Writer) (_ *xlsxRegisterWriter, err error) {
+	file := excelize.NewFile()
+	defer func() {
+		if err != nil {
+			file.Close()
+		}
+	}()
+	if err := file.SetSheetName("Sheet1", registerSheet); err != nil {
+		return nil, fmt.Errorf("failed to create sheet: %w", err)
+	}
+	stream, err := file.NewStreamWriter(registerSheet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stream writer: %w", err)
+	}
+	return &xlsxRegisterWriter{file: file, stream: stream, out: w}, nil
+}
+
+func (x *xlsxRegisterWriter) WriteRow(values []string) error {
+	x.row++
+	cells := make([]interface{}, len(values))
+	for i, v := range values {
+		cells[i] = v
+	}
+	return x.stream.SetRow("A"+strconv.Itoa(x.row), cells)
+}
+
+func (x *xlsxRegisterWriter) Flush() error {
+	if err := x.stream.Flush(); err != nil {
+		return fmt.Errorf("failed to flush sheet: %w", err)
+	}
+	return x.file.Write(x.out)
+}
+
+// Close removes the stream writer's temporary file.
+func (x *xlsxRegisterWriter) Close() error {
+	return x.file.Close()
+}
@@ -0,0 +1,84 @@
+package attendance
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+type registerLectureRepo struct {
+	fakeLectureRepo
+	lectures []*models.Lecture
+}
+
+func (f *registerLectureRepo) FindLecturesByCourseInRange(ctx context.Context, collegeID int, courseID int, from, to time.Time) ([]*models.Lecture, error) {
+	return f.lectures, nil
+}
+
+type registerAttendanceRepo struct {
+	fakeAttendanceRepo
+	rows []*models.AttendanceRegisterRow
+}
+
+func (f *registerAttendanceRepo) StreamAttendanceRegister(ctx context.Context, collegeID int, courseID int, termID *int, lectureIDs []int, fn func(row *models.AttendanceRegisterRow) error) error {
+	for _, row := range f.rows {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newRegisterTestService() *attendanceService {
+	day := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	lectures := &registerLectureRepo{lectures: []*models.Lecture{
+		{ID: 10, StartTime: day},
+		{ID: 11, StartTime: day.AddDate(0, 0, 1)},
+	}}
+	attendance := &registerAttendanceRepo{rows: []*models.AttendanceRegisterRow{
+		{StudentID: 1, RollNo: "CS001", Statuses: map[int]string{10: Present, 11: Absent}},
+		{StudentID: 2, RollNo: "CS002", Statuses: map[int]string{11: Present}},
+	}}
+	return &attendanceService{repo: attendance, lectureRepo: lectures}
+}
+
+func TestExportAttendanceRegister_CSV(t *testing.T) {
+	svc := newRegisterTestService()
+	var buf bytes.Buffer
+
+	err := svc.ExportAttendanceRegister(context.Background(), 1, 2, nil, time.Time{}, time.Time{}, ExportFormatCSV, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, "Roll No,2025-01-06 09:00,2025-01-07 09:00\nCS001,Present,Absent\nCS002,,Present\n", buf.String())
+}
+
+func TestExportAttendanceRegister_XLSX(t *testing.T) {
+	svc := newRegisterTestService()
+	var buf bytes.Buffer
+
+	err := svc.ExportAttendanceRegister(context.Background(), 1, 2, nil, time.Time{}, time.Time{}, ExportFormatXLSX, &buf)
+	require.NoError(t, err)
+
+	file, err := excelize.OpenReader(&buf)
+	require.NoError(t, err)
+	defer file.Close()
+	rows, err := file.GetRows(registerSheet)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Roll No", "2025-01-06 09:00", "2025-01-07 09:00"},
+		{"CS001", Present, Absent},
+		{"CS002", "", Present},
+	}, rows)
+}
+
+func TestExportAttendanceRegister_UnsupportedFormat(t *testing.T) {
+	svc := newRegisterTestService()
+	err := svc.ExportAttendanceRegister(context.Background(), 1, 2, nil, time.Time{}, time.Time{}, "pdf", &bytes.Buffer{})
+	assert.ErrorIs(t, err, ErrUnsupportedExportFormat)
+}
@@ -0,0 +1,69 @@
+package attendance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+)
+
+// ErrAttendanceWindowClosed is returned for scans after the policy's absent cutoff.
+var ErrAttendanceWindowClosed = errors.New("attendance marking for this lecture has closed")
+
+// classifyArrival returns the status earned by a scan at `at` under policy.
+// Scans before the lecture starts count as Present.
+func classifyArrival(policy *models.AttendancePolicy, lecture *models.Lecture, at time.Time) (string, error) {
+	offset := at.Sub(lecture.StartTime)
+	switch {
+	case policy.AbsentCutoffMinutes != nil && offset > minutes(*policy.AbsentCutoffMinutes):
+		return "", ErrAttendanceWindowClosed
+	case offset <= minutes(policy.GraceMinutes):
+		return Present, nil
+	case offset <= minutes(policy.LateCutoffMinutes):
+		return Late, nil
+	default:
+		return Absent, nil
+	}
+}
+
+func minutes(m int) time.Duration {
+	return time.Duration(m) * time.Minute
+}
+
+// arrivalStatus classifies a scan at `at` using the college's policy.
+func (a *attendanceService) arrivalStatus(ctx context.Context, collegeID int, lecture *models.Lecture, at time.Time) (string, error) {
+	policy, err := a.GetAttendancePolicy(ctx, collegeID)
+	if err != nil {
+		return "", err
+	}
+	return classifyArrival(policy, lecture, at)
+}
+
+// the college's attendance policy, or the defaults if none is configured
+func (a *attendanceService) GetAttendancePolicy(ctx context.Context, collegeID int) (*models.AttendancePolicy, error) {
+	policy, err := a.policyRepo.GetAttendancePolicy(ctx, collegeID)
+	if errors.Is(err, repository.ErrAttendancePolicyNotFound) {
+		return models.DefaultAttendancePolicy(collegeID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load attendance policy: %w", err)
+	}
+	return policy, nil
+}
+
+func (a *attendanceService) UpdateAttendancePolicy(ctx context.Context, policy *models.AttendancePolicy) error {
+	switch {
+	case policy.GraceMinutes < 0:
+		return fmt.Errorf("grace minutes must not be negative")
+	case policy.LateCutoffMinutes < policy.GraceMinutes:
+		return fmt.Errorf("late cutoff must not be before the grace period ends")
+	case policy.AbsentCutoffMinutes != nil && *policy.AbsentCutoffMinutes < policy.LateCutoffMinutes:
+		return fmt.Errorf("absent cutoff must not be before the late cutoff")
+	case policy.LatesPerAbsence < 0:
+		return fmt.Errorf("lates per absence must not be negative")
+	}
+	return a.policyRepo.UpsertAttendancePolicy(ctx, policy)
+}
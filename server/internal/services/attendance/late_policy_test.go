@@ -0,0 +1,45 @@
+package attendance
+
+import (
+	"testing"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyArrival(t *testing.T) {
+	start := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	lecture := &models.Lecture{StartTime: start, EndTime: start.Add(time.Hour)}
+	cutoff := 45
+	policy := &models.AttendancePolicy{GraceMinutes: 10, LateCutoffMinutes: 30, AbsentCutoffMinutes: &cutoff}
+
+	tests := []struct {
+		name   string
+		offset time.Duration
+		want   string
+	}{
+		{"early", -5 * time.Minute, Present},
+		{"within grace", 10 * time.Minute, Present},
+		{"after grace", 10*time.Minute + time.Second, Late},
+		{"at late cutoff", 30 * time.Minute, Late},
+		{"after late cutoff", 31 * time.Minute, Absent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := classifyArrival(policy, lecture, start.Add(tt.offset))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, status)
+		})
+	}
+
+	_, err := classifyArrival(policy, lecture, start.Add(46*time.Minute))
+	assert.ErrorIs(t, err, ErrAttendanceWindowClosed)
+
+	// without an absent cutoff marking stays open
+	status, err := classifyArrival(models.DefaultAttendancePolicy(1), lecture, start.Add(3*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, Absent, status)
+}
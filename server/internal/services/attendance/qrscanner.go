@@ -3,43 +3,39 @@ package attendance
 import (
 	"context"
 	"encoding/base64"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
+	"eduhub/server/internal/models"
+
 	"github.com/skip2/go-qrcode"
 )
 
 // studentId in the request body
-// course id in the request body and lecture id obtained from qr code
+// course id, lecture id and college id are taken from the signed qr token
 // need to check if student is enrolled in the course
 // need to check if the student is enrolled in the lecture
-type QRCodeData struct {
-	CourseID  int       `json:"course_id"`
-	LectureID int       `json:"lecture_id"`
-	TimeStamp time.Time `json:"time_stamp"`
-	ExpiresAt time.Time `json:"expires_at"`
-}
-
 func (a *attendanceService) GenerateQRCode(ctx context.Context, collegeID int, courseID int, lectureID int) (string, error) {
-	// generate qr code
-	// return qr code
-	// need to check if student is enrolled in the course for marking attendance
-
-	now := time.Now()
-	expiresAt := now.Add(30 * time.Minute)
-	qrCodeData := QRCodeData{
+	// every call issues a new signed token; clients are expected to refresh
+	// the displayed code once per rotation period
+	token, claims, err := a.qrSigner.Issue(collegeID, courseID, lectureID, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("failed to issue qr token: %w", err)
+	}
+	// record the nonce so scans can be checked against issued codes
+	err = a.qrCodeRepo.CreateQRCode(ctx, &models.QRCode{
+		CollegeID: collegeID,
 		CourseID:  courseID,
 		LectureID: lectureID,
-		TimeStamp: now,
-		ExpiresAt: expiresAt,
-	}
-	jsonData, err := json.Marshal(qrCodeData)
+		QRCodeID:  claims.Nonce,
+		IssuedAt:  claims.IssuedAt,
+		ExpiresAt: claims.ExpiresAt,
+	})
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to record qr token: %w", err)
 	}
-	qrBytes, err := qrcode.Encode(string(jsonData), qrcode.Medium, 256)
+	qrBytes, err := qrcode.Encode(token, qrcode.Medium, 256)
 	if err != nil {
 		return "", err
 	}
@@ -48,33 +44,69 @@ func (a *attendanceService) GenerateQRCode(ctx context.Context, collegeID int, c
 }
 
 // process qr and take values from it to mark attendance(process qr and chaning state)
-func (a *attendanceService) ProcessQRCode(ctx context.Context, collegeID int, studentID int, qrCodeContent string) error {
-	var qrData QRCodeData
-	if err := json.Unmarshal([]byte(qrCodeContent), &qrData); err != nil {
-		// Consider logging the actual error here for debugging
-		return errors.New("invalid qr code content")
+// the scan is checked against the lecture's attendance window, geofence and device
+// constraints and recorded either way so flagged attempts can be reviewed by faculty.
+// The nonce is only redeemed, with the attendance, once every check has passed.
+func (a *attendanceService) ProcessQRCode(ctx context.Context, collegeID int, studentID int, qrCodeContent string, meta models.ScanMetadata, audit models.AuditInfo) (*models.AttendanceScan, error) {
+	qrData, err := a.qrSigner.Verify(qrCodeContent, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if qrData.CollegeID != collegeID {
+		return nil, ErrQRCodeCollegeDenied
+	}
+	// one redemption per student per nonce; replays fail with a typed repository error
+	if err := a.qrCodeRepo.CheckQRCode(ctx, collegeID, qrData.Nonce, studentID); err != nil {
+		return nil, err
 	}
 
-	// Check if the QR code has expired
-	if time.Now().After(qrData.ExpiresAt) {
-		return errors.New("qr code expired")
+	lecture, err := a.lectureRepo.GetLectureByID(ctx, collegeID, qrData.LectureID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load lecture: %w", err)
+	}
+	if lecture.CourseID != qrData.CourseID {
+		return nil, ErrInvalidQRCode
+	}
+	status, err := a.arrivalStatus(ctx, collegeID, lecture, time.Now())
+	windowClosed := errors.Is(err, ErrAttendanceWindowClosed)
+	if err != nil && !windowClosed {
+		return nil, err
+	}
+
+	scan := &models.AttendanceScan{
+		CollegeID: collegeID,
+		StudentID: studentID,
+		CourseID:  qrData.CourseID,
+		LectureID: qrData.LectureID,
+		QRNonce:   qrData.Nonce,
+		Latitude:  meta.Latitude,
+		Longitude: meta.Longitude,
+	}
+	if meta.DeviceFingerprint != "" {
+		scan.DeviceFingerprint = &meta.DeviceFingerprint
+	}
+	rejected, err := a.evaluateScan(ctx, lecture, scan, meta, windowClosed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate scan: %w", err)
+	}
+	if err := a.scanRepo.CreateScan(ctx, scan); err != nil {
+		return nil, fmt.Errorf("failed to record scan: %w", err)
+	}
+	if !scan.Accepted {
+		return scan, &ScanRejectedError{Reasons: rejected}
 	}
 
-	// Attempt to mark attendance
-	marked, err := a.MarkAttendance(ctx, collegeID, studentID, qrData.CourseID, qrData.LectureID)
+	// enrollment was verified while evaluating the scan
+	marked, err := a.repo.MarkScannedAttendance(ctx, collegeID, studentID, qrData.CourseID, qrData.LectureID, status, qrData.Nonce, withAuditSource(audit, models.AuditSourceQR))
 	if err != nil {
-		// Return the specific error from MarkAttendance
-		return fmt.Errorf("failed to mark attendance: %w", err)
+		return scan, fmt.Errorf("failed to mark attendance: %w", err)
 	}
 	if !marked {
-		// This case might indicate a specific condition like already marked,
-		// or student not enrolled, which might be better handled by MarkAttendance returning specific errors.
-		// Returning a generic error might be okay depending on requirements.
-		return errors.New("unable to mark attendance (check enrollment or if already marked)")
+		return scan, errors.New("unable to mark attendance (check enrollment or if already marked)")
 	}
 
 	// Attendance marked successfully
-	return nil
+	return scan, nil
 }
 
 /// process qr takes qr input and marks attendance
@@ -0,0 +1,217 @@
+package attendance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testAudit = models.AuditInfo{ActorID: "kratos-identity"}
+
+type fakeStudentRepo struct {
+	repository.StudentRepository
+}
+
+func (f *fakeStudentRepo) GetStudentByID(ctx context.Context, collegeID int, studentID int) (*models.Student, error) {
+	return &models.Student{StudentID: studentID, CollegeID: collegeID, IsActive: true}, nil
+}
+
+type fakeEnrollmentRepo struct {
+	repository.EnrollmentRepository
+}
+
+func (f *fakeEnrollmentRepo) IsStudentEnrolled(ctx context.Context, collegeID int, studentID int, courseID int) (bool, error) {
+	return true, nil
+}
+
+type fakeAttendanceRepo struct {
+	repository.AttendanceRepository
+	qrCodes    *repository.MemoryQRCodeRepository
+	marked     int
+	lastStatus string
+	lastAudit  models.AuditInfo
+}
+
+func (f *fakeAttendanceRepo) MarkAttendance(ctx context.Context, collegeID int, studentID int, courseID int, lectureID int, status string, audit models.AuditInfo) (bool, error) {
+	f.marked++
+	f.lastStatus = status
+	f.lastAudit = audit
+	return true, nil
+}
+
+func (f *fakeAttendanceRepo) MarkScannedAttendance(ctx context.Context, collegeID int, studentID int, courseID int, lectureID int, status string, nonce string, audit models.AuditInfo) (bool, error) {
+	if err := f.qrCodes.RedeemQRCode(ctx, collegeID, nonce, studentID); err != nil {
+		return false, err
+	}
+	return f.MarkAttendance(ctx, collegeID, studentID, courseID, lectureID, status, audit)
+}
+
+type fakeLectureRepo struct {
+	repository.LectureRepository
+	lecture models.Lecture
+}
+
+func (f *fakeLectureRepo) GetLectureByID(ctx context.Context, collegeID int, lectureID int) (*models.Lecture, error) {
+	lecture := f.lecture
+	lecture.ID = lectureID
+	lecture.CollegeID = collegeID
+	return &lecture, nil
+}
+
+type fakeScanRepo struct {
+	repository.AttendanceScanRepository
+	scans []*models.AttendanceScan
+}
+
+func (f *fakeScanRepo) CreateScan(ctx context.Context, scan *models.AttendanceScan) error {
+	f.scans = append(f.scans, scan)
+	return nil
+}
+
+type fakeDeviceRepo struct {
+	repository.StudentDeviceRepository
+	fingerprints map[string]bool
+}
+
+func (f *fakeDeviceRepo) IsDeviceRegistered(ctx context.Context, collegeID, studentID int, fingerprint string) (bool, error) {
+	return f.fingerprints[fingerprint], nil
+}
+
+type fakePolicyRepo struct {
+	repository.AttendancePolicyRepository
+	policy *models.AttendancePolicy
+}
+
+func (f *fakePolicyRepo) GetAttendancePolicy(ctx context.Context, collegeID int) (*models.AttendancePolicy, error) {
+	if f.policy == nil {
+		return nil, repository.ErrAttendancePolicyNotFound
+	}
+	return f.policy, nil
+}
+
+type testAttendanceService struct {
+	*attendanceService
+	attendanceRepo *fakeAttendanceRepo
+	lectureRepo    *fakeLectureRepo
+	scanRepo       *fakeScanRepo
+	deviceRepo     *fakeDeviceRepo
+	policyRepo     *fakePolicyRepo
+}
+
+func newTestAttendanceService() (*attendanceService, *fakeAttendanceRepo) {
+	svc := newTestAttendanceServiceWithFakes()
+	return svc.attendanceService, svc.attendanceRepo
+}
+
+func newTestAttendanceServiceWithFakes() *testAttendanceService {
+	qrCodes := repository.NewMemoryQRCodeRepository()
+	svc := &testAttendanceService{
+		attendanceRepo: &fakeAttendanceRepo{qrCodes: qrCodes},
+		lectureRepo:    &fakeLectureRepo{lecture: models.Lecture{CourseID: 2, StartTime: time.Now()}},
+		scanRepo:       &fakeScanRepo{},
+		deviceRepo:     &fakeDeviceRepo{fingerprints: map[string]bool{}},
+		policyRepo:     &fakePolicyRepo{},
+	}
+	svc.attendanceService = NewAttendanceService(
+		svc.attendanceRepo,
+		&fakeStudentRepo{},
+		&fakeEnrollmentRepo{},
+		svc.lectureRepo,
+		qrCodes,
+		svc.scanRepo,
+		svc.deviceRepo,
+		svc.policyRepo,
+		NewQRTokenSigner([]byte("test-key"), 15*time.Second),
+	).(*attendanceService)
+	return svc
+}
+
+func issueRecordedToken(t *testing.T, svc *attendanceService, collegeID, courseID, lectureID int) string {
+	t.Helper()
+	token, claims, err := svc.qrSigner.Issue(collegeID, courseID, lectureID, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, svc.qrCodeRepo.CreateQRCode(context.Background(), &models.QRCode{
+		CollegeID: collegeID,
+		CourseID:  courseID,
+		LectureID: lectureID,
+		QRCodeID:  claims.Nonce,
+		IssuedAt:  claims.IssuedAt,
+		ExpiresAt: claims.ExpiresAt,
+	}))
+	return token
+}
+
+func TestProcessQRCode_RejectsReplay(t *testing.T) {
+	svc, attendanceRepo := newTestAttendanceService()
+	ctx := context.Background()
+	token := issueRecordedToken(t, svc, 1, 2, 3)
+
+	_, err := svc.ProcessQRCode(ctx, 1, 101, token, models.ScanMetadata{}, testAudit)
+	require.NoError(t, err)
+	_, err = svc.ProcessQRCode(ctx, 1, 101, token, models.ScanMetadata{}, testAudit)
+	assert.ErrorIs(t, err, repository.ErrQRCodeAlreadyRedeemed)
+
+	// a classmate scanning the same projected code is not a replay
+	_, err = svc.ProcessQRCode(ctx, 1, 102, token, models.ScanMetadata{}, testAudit)
+	require.NoError(t, err)
+	assert.Equal(t, 2, attendanceRepo.marked)
+	assert.Equal(t, Present, attendanceRepo.lastStatus)
+	assert.Equal(t, models.AuditInfo{ActorID: testAudit.ActorID, Source: models.AuditSourceQR}, attendanceRepo.lastAudit)
+}
+
+func TestProcessQRCode_RejectsUnissuedToken(t *testing.T) {
+	svc, attendanceRepo := newTestAttendanceService()
+
+	// correctly signed, but never recorded in the ledger
+	token, _, err := svc.qrSigner.Issue(1, 2, 3, time.Now())
+	require.NoError(t, err)
+
+	_, err = svc.ProcessQRCode(context.Background(), 1, 101, token, models.ScanMetadata{}, testAudit)
+	assert.ErrorIs(t, err, repository.ErrQRCodeNotIssued)
+	assert.Zero(t, attendanceRepo.marked)
+}
+
+func TestProcessQRCode_RejectsOtherCollege(t *testing.T) {
+	svc, attendanceRepo := newTestAttendanceService()
+	token := issueRecordedToken(t, svc, 1, 2, 3)
+
+	_, err := svc.ProcessQRCode(context.Background(), 9, 101, token, models.ScanMetadata{}, testAudit)
+	assert.ErrorIs(t, err, ErrQRCodeCollegeDenied)
+	assert.Zero(t, attendanceRepo.marked)
+}
+
+func TestProcessQRCode_RejectedScanKeepsNonce(t *testing.T) {
+	svc := newTestAttendanceServiceWithFakes()
+	svc.lectureRepo.lecture.RequireRegisteredDevice = true
+	svc.deviceRepo.fingerprints["phone-a"] = true
+	ctx := context.Background()
+	token := issueRecordedToken(t, svc.attendanceService, 1, 2, 3)
+
+	_, err := svc.ProcessQRCode(ctx, 1, 101, token, models.ScanMetadata{DeviceFingerprint: "phone-b"}, testAudit)
+	assert.ErrorIs(t, err, ErrScanRejected)
+
+	// the student can retry the same code from their registered device
+	_, err = svc.ProcessQRCode(ctx, 1, 101, token, models.ScanMetadata{DeviceFingerprint: "phone-a"}, testAudit)
+	require.NoError(t, err)
+	assert.Equal(t, 1, svc.attendanceRepo.marked)
+}
+
+func TestProcessQRCode_RecordsScanAfterWindow(t *testing.T) {
+	svc := newTestAttendanceServiceWithFakes()
+	svc.lectureRepo.lecture.StartTime = time.Now().Add(-2 * time.Hour)
+	svc.policyRepo.policy = &models.AttendancePolicy{GraceMinutes: 5, LateCutoffMinutes: 15, AbsentCutoffMinutes: ptr(30)}
+
+	scan, err := svc.ProcessQRCode(context.Background(), 1, 101, issueRecordedToken(t, svc.attendanceService, 1, 2, 3), models.ScanMetadata{}, testAudit)
+	assert.ErrorIs(t, err, ErrAttendanceWindowClosed)
+	assert.ErrorIs(t, err, ErrScanRejected)
+	require.Len(t, svc.scanRepo.scans, 1)
+	assert.False(t, scan.Accepted)
+	assert.Equal(t, []string{models.ScanReasonWindowClosed}, scan.Reasons)
+	assert.Zero(t, svc.attendanceRepo.marked)
+}
@@ -0,0 +1,106 @@
+package attendance
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// qrClockSkew tolerates small differences between the projector and the scanning phone.
+const qrClockSkew = 5 * time.Second
+
+var (
+	ErrInvalidQRCode       = errors.New("invalid qr code content")
+	ErrQRCodeExpired       = errors.New("qr code expired")
+	ErrQRCodeCollegeDenied = errors.New("qr code was issued for a different college")
+)
+
+// QRTokenClaims is the payload signed into every attendance QR code.
+type QRTokenClaims struct {
+	CollegeID int       `json:"college_id"`
+	CourseID  int       `json:"course_id"`
+	LectureID int       `json:"lecture_id"`
+	Nonce     string    `json:"nonce"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// QRTokenSigner issues and verifies HMAC-SHA256 signed QR tokens.
+// A token has the form base64url(claims) + "." + base64url(signature).
+type QRTokenSigner struct {
+	key      []byte
+	rotation time.Duration
+}
+
+func NewQRTokenSigner(key []byte, rotation time.Duration) *QRTokenSigner {
+	return &QRTokenSigner{
+		key:      key,
+		rotation: rotation,
+	}
+}
+
+// Issue creates a fresh token for a lecture that expires after one rotation period.
+func (s *QRTokenSigner) Issue(collegeID, courseID, lectureID int, now time.Time) (string, *QRTokenClaims, error) {
+	nonce, err := newNonce()
+	if err != nil {
+		return "", nil, err
+	}
+	claims := &QRTokenClaims{
+		CollegeID: collegeID,
+		CourseID:  courseID,
+		LectureID: lectureID,
+		Nonce:     nonce,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(s.rotation),
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded)), claims, nil
+}
+
+// Verify checks the signature and validity window of a token and returns its claims.
+func (s *QRTokenSigner) Verify(token string, now time.Time) (*QRTokenClaims, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidQRCode
+	}
+	gotSig, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(gotSig, s.sign(encoded)) {
+		return nil, ErrInvalidQRCode
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidQRCode
+	}
+	var claims QRTokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Nonce == "" {
+		return nil, ErrInvalidQRCode
+	}
+	if now.Before(claims.IssuedAt.Add(-qrClockSkew)) || now.After(claims.ExpiresAt.Add(qrClockSkew)) {
+		return nil, ErrQRCodeExpired
+	}
+	return &claims, nil
+}
+
+func (s *QRTokenSigner) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
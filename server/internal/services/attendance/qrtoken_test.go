@@ -0,0 +1,78 @@
+package attendance
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQRTokenSigner_RoundTrip(t *testing.T) {
+	signer := NewQRTokenSigner([]byte("test-key"), 15*time.Second)
+	now := time.Now()
+
+	token, issued, err := signer.Issue(1, 2, 3, now)
+	require.NoError(t, err)
+
+	claims, err := signer.Verify(token, now.Add(10*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 1, claims.CollegeID)
+	assert.Equal(t, 2, claims.CourseID)
+	assert.Equal(t, 3, claims.LectureID)
+	assert.Equal(t, issued.Nonce, claims.Nonce)
+}
+
+func TestQRTokenSigner_UniqueNonces(t *testing.T) {
+	signer := NewQRTokenSigner([]byte("test-key"), 15*time.Second)
+	now := time.Now()
+
+	_, first, err := signer.Issue(1, 2, 3, now)
+	require.NoError(t, err)
+	_, second, err := signer.Issue(1, 2, 3, now)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first.Nonce, second.Nonce)
+}
+
+func TestQRTokenSigner_Expired(t *testing.T) {
+	signer := NewQRTokenSigner([]byte("test-key"), 15*time.Second)
+	now := time.Now()
+
+	token, _, err := signer.Issue(1, 2, 3, now)
+	require.NoError(t, err)
+
+	_, err = signer.Verify(token, now.Add(15*time.Second+qrClockSkew+time.Second))
+	assert.ErrorIs(t, err, ErrQRCodeExpired)
+}
+
+func TestQRTokenSigner_Rejects(t *testing.T) {
+	signer := NewQRTokenSigner([]byte("test-key"), 15*time.Second)
+	now := time.Now()
+
+	token, _, err := signer.Issue(1, 2, 3, now)
+	require.NoError(t, err)
+	payload, sig, _ := strings.Cut(token, ".")
+
+	forged, _, err := NewQRTokenSigner([]byte("other-key"), 15*time.Second).Issue(1, 2, 3, now)
+	require.NoError(t, err)
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "plain json", token: `{"course_id":2,"lecture_id":3}`},
+		{name: "missing signature", token: payload},
+		{name: "signed with another key", token: forged},
+		{name: "tampered payload", token: forgedPayload + "." + sig},
+		{name: "garbage signature", token: payload + ".!!!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := signer.Verify(tt.token, now)
+			assert.ErrorIs(t, err, ErrInvalidQRCode)
+		})
+	}
+}
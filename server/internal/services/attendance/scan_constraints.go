@@ -0,0 +1,103 @@
+package attendance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"eduhub/server/internal/models"
+)
+
+var ErrScanRejected = errors.New("attendance scan rejected")
+
+// ScanRejectedError is returned by ProcessQRCode when a scan fails one of the
+// lecture's constraints. The scan is still recorded for faculty review. A scan
+// after the attendance window is also ErrAttendanceWindowClosed.
+type ScanRejectedError struct {
+	Reasons []string
+}
+
+func (e *ScanRejectedError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrScanRejected, strings.Join(e.Reasons, ", "))
+}
+
+func (e *ScanRejectedError) Is(target error) bool {
+	return target == ErrScanRejected ||
+		target == ErrAttendanceWindowClosed && slices.Contains(e.Reasons, models.ScanReasonWindowClosed)
+}
+
+const earthRadiusMeters = 6371000
+
+// distanceMeters returns the great-circle distance between two points using
+// the haversine formula.
+func distanceMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(h))
+}
+
+// evaluateScan checks the scan against the lecture's constraints, filling in
+// scan.Reasons, scan.DistanceMeters and scan.Accepted. Only rejection reasons
+// are returned. A scan after the attendance window is rejected too.
+func (a *attendanceService) evaluateScan(ctx context.Context, lecture *models.Lecture, scan *models.AttendanceScan, meta models.ScanMetadata, windowClosed bool) ([]string, error) {
+	var reasons, rejected []string
+	reject := func(reason string) {
+		reasons = append(reasons, reason)
+		rejected = append(rejected, reason)
+	}
+
+	if windowClosed {
+		reject(models.ScanReasonWindowClosed)
+	}
+
+	ok, err := a.VerifyStudentStateAndEnrollment(ctx, scan.CollegeID, scan.StudentID, scan.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		reject(models.ScanReasonNotEnrolled)
+	}
+
+	if lecture.HasGeofence() {
+		if meta.Latitude == nil || meta.Longitude == nil {
+			reject(models.ScanReasonLocationMissing)
+		} else {
+			distance := distanceMeters(*lecture.Latitude, *lecture.Longitude, *meta.Latitude, *meta.Longitude)
+			scan.DistanceMeters = &distance
+			if distance > float64(*lecture.GeofenceRadiusMeters) {
+				reject(models.ScanReasonOutsideGeofence)
+			} else {
+				reasons = append(reasons, models.ScanReasonWithinGeofence)
+			}
+		}
+	}
+
+	if lecture.RequireRegisteredDevice {
+		if meta.DeviceFingerprint == "" {
+			reject(models.ScanReasonDeviceMissing)
+		} else {
+			registered, err := a.deviceRepo.IsDeviceRegistered(ctx, scan.CollegeID, scan.StudentID, meta.DeviceFingerprint)
+			if err != nil {
+				return nil, err
+			}
+			if registered {
+				reasons = append(reasons, models.ScanReasonDeviceVerified)
+			} else {
+				reject(models.ScanReasonDeviceNotRegistered)
+			}
+		}
+	}
+
+	if len(reasons) == 0 {
+		reasons = append(reasons, models.ScanReasonNoConstraints)
+	}
+	scan.Reasons = reasons
+	scan.Accepted = len(rejected) == 0
+	return rejected, nil
+}
@@ -0,0 +1,74 @@
+package attendance
+
+import (
+	"context"
+	"testing"
+
+	"eduhub/server/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestDistanceMeters(t *testing.T) {
+	// one thousandth of a degree of latitude is roughly 111 metres
+	d := distanceMeters(12.9716, 77.5946, 12.9726, 77.5946)
+	assert.InDelta(t, 111.2, d, 1)
+	assert.Zero(t, distanceMeters(12.9716, 77.5946, 12.9716, 77.5946))
+}
+
+func TestProcessQRCode_Geofence(t *testing.T) {
+	tests := []struct {
+		name     string
+		meta     models.ScanMetadata
+		accepted bool
+		reason   string
+	}{
+		{name: "inside", meta: models.ScanMetadata{Latitude: ptr(12.9717), Longitude: ptr(77.5946)}, accepted: true, reason: models.ScanReasonWithinGeofence},
+		{name: "outside", meta: models.ScanMetadata{Latitude: ptr(12.9816), Longitude: ptr(77.5946)}, reason: models.ScanReasonOutsideGeofence},
+		{name: "no location", meta: models.ScanMetadata{}, reason: models.ScanReasonLocationMissing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestAttendanceServiceWithFakes()
+			svc.lectureRepo.lecture.Latitude = ptr(12.9716)
+			svc.lectureRepo.lecture.Longitude = ptr(77.5946)
+			svc.lectureRepo.lecture.GeofenceRadiusMeters = ptr(50)
+			token := issueRecordedToken(t, svc.attendanceService, 1, 2, 3)
+
+			scan, err := svc.ProcessQRCode(context.Background(), 1, 101, token, tt.meta, testAudit)
+			require.NotNil(t, scan)
+			assert.Equal(t, tt.accepted, scan.Accepted)
+			assert.Contains(t, scan.Reasons, tt.reason)
+			require.Len(t, svc.scanRepo.scans, 1)
+			if tt.accepted {
+				require.NoError(t, err)
+				assert.Equal(t, 1, svc.attendanceRepo.marked)
+			} else {
+				assert.ErrorIs(t, err, ErrScanRejected)
+				assert.Zero(t, svc.attendanceRepo.marked)
+			}
+		})
+	}
+}
+
+func TestProcessQRCode_RegisteredDevice(t *testing.T) {
+	svc := newTestAttendanceServiceWithFakes()
+	svc.lectureRepo.lecture.RequireRegisteredDevice = true
+	svc.deviceRepo.fingerprints["phone-a"] = true
+	ctx := context.Background()
+
+	scan, err := svc.ProcessQRCode(ctx, 1, 101, issueRecordedToken(t, svc.attendanceService, 1, 2, 3), models.ScanMetadata{DeviceFingerprint: "phone-b"}, testAudit)
+	var rejected *ScanRejectedError
+	require.ErrorAs(t, err, &rejected)
+	assert.Equal(t, []string{models.ScanReasonDeviceNotRegistered}, rejected.Reasons)
+	assert.False(t, scan.Accepted)
+
+	scan, err = svc.ProcessQRCode(ctx, 1, 101, issueRecordedToken(t, svc.attendanceService, 1, 2, 3), models.ScanMetadata{DeviceFingerprint: "phone-a"}, testAudit)
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.ScanReasonDeviceVerified}, scan.Reasons)
+	assert.Equal(t, 1, svc.attendanceRepo.marked)
+	assert.Len(t, svc.scanRepo.scans, 2)
+}
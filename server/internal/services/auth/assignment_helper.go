@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 )
 
 type Assigner struct {
@@ -17,15 +16,25 @@ func NewAssigner(keto KetoService) *Assigner {
 	}
 }
 
-func (a *Assigner) AssignFacultyToCourse(ctx context.Context, facultyID, courseID string) {
-	relations := []string{"faculty", "manage_qr", "view_attendance", "grade_assignments"}
-	for _, relation := range relations {
+var facultyCourseRelations = []string{"faculty", "manage_qr", "view_attendance", "grade_assignments"}
+
+func (a *Assigner) AssignFacultyToCourse(ctx context.Context, facultyID, courseID string) error {
+	for _, relation := range facultyCourseRelations {
 		if err := a.keto.CreateRelation(ctx, "courses", courseID, relation, facultyID); err != nil {
-			log.Printf("failed to assign relation")
-			return
+			return fmt.Errorf("failed to assign faculty relation %s: %w", relation, err)
+		}
+	}
+	return nil
+}
+
+// RemoveFacultyFromCourse revokes what AssignFacultyToCourse granted.
+func (a *Assigner) RemoveFacultyFromCourse(ctx context.Context, facultyID, courseID string) error {
+	for _, relation := range facultyCourseRelations {
+		if err := a.keto.DeleteRelation(ctx, "courses", courseID, relation, facultyID); err != nil {
+			return fmt.Errorf("failed to remove faculty relation %s: %w", relation, err)
 		}
 	}
-	return
+	return nil
 }
 
 func (a *Assigner) AssignStudentToCourse(ctx context.Context, studentID, courseID string) error {
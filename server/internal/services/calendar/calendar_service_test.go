@@ -0,0 +1,204 @@
+package calendar
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCalendarRepo struct {
+	repository.CalendarRepository
+	events  []*models.CalendarBlock
+	created []*models.CalendarBlock
+	updated []*models.CalendarBlock
+	filter  models.CalendarBlockFilter
+}
+
+func (f *fakeCalendarRepo) CreateCalendarBlock(ctx context.Context, block *models.CalendarBlock) error {
+	f.created = append(f.created, block)
+	return nil
+}
+
+func (f *fakeCalendarRepo) UpdateCalendarBlock(ctx context.Context, block *models.CalendarBlock) error {
+	f.updated = append(f.updated, block)
+	return nil
+}
+
+func (f *fakeCalendarRepo) GetCalendarBlockByID(ctx context.Context, blockID int, collegeID int) (*models.CalendarBlock, error) {
+	for _, e := range f.events {
+		if e.ID == blockID {
+			return e, nil
+		}
+	}
+	return nil, repository.ErrCalendarBlockNotFound
+}
+
+func (f *fakeCalendarRepo) GetCalendarBlockByExternalUID(ctx context.Context, collegeID int, uid string) (*models.CalendarBlock, error) {
+	for _, e := range f.events {
+		if e.ExternalUID != nil && *e.ExternalUID == uid {
+			return e, nil
+		}
+	}
+	return nil, repository.ErrCalendarBlockNotFound
+}
+
+// GetCalendarBlocks applies the type and date range like the repository.
+func (f *fakeCalendarRepo) GetCalendarBlocks(ctx context.Context, filter models.CalendarBlockFilter) ([]*models.CalendarBlock, error) {
+	f.filter = filter
+	var found []*models.CalendarBlock
+	for _, e := range f.events {
+		if filter.EventType != nil && e.EventType != *filter.EventType {
+			continue
+		}
+		if filter.StartDate != nil && e.EndDate.Before(*filter.StartDate) || filter.EndDate != nil && e.Date.After(*filter.EndDate) {
+			continue
+		}
+		found = append(found, e)
+	}
+	return found, nil
+}
+
+type fakeCourseRepo struct{ repository.CourseRepository }
+
+func (f *fakeCourseRepo) FindCourseByID(ctx context.Context, collegeID int, courseID int) (*models.Course, error) {
+	if courseID != 7 {
+		return nil, repository.ErrCourseNotFound
+	}
+	return &models.Course{ID: 7, CollegeID: collegeID}, nil
+}
+
+func day(s string) time.Time {
+	d, _ := time.Parse(time.DateOnly, s)
+	return d
+}
+
+func at(s string) *time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return &t
+}
+
+func TestCreateEventSettlesDates(t *testing.T) {
+	tests := []struct {
+		name        string
+		event       models.CalendarBlock
+		wantDate    string
+		wantEndDate string
+		want        error
+	}{
+		{name: "one day", event: models.CalendarBlock{Date: day("2026-03-02")}, wantDate: "2026-03-02", wantEndDate: "2026-03-02"},
+		{name: "several days", event: models.CalendarBlock{Date: day("2026-03-02"), EndDate: day("2026-03-06")}, wantDate: "2026-03-02", wantEndDate: "2026-03-06"},
+		{name: "timed overnight", event: models.CalendarBlock{StartTime: at("2026-03-02T20:00:00+05:30"), EndTime: at("2026-03-03T02:00:00+05:30")}, wantDate: "2026-03-02", wantEndDate: "2026-03-03"},
+		{name: "no date", event: models.CalendarBlock{}, want: ErrInvalidEventDates},
+		{name: "ends before it starts", event: models.CalendarBlock{Date: day("2026-03-02"), EndDate: day("2026-03-01")}, want: ErrInvalidEventDates},
+		{name: "start time only", event: models.CalendarBlock{StartTime: at("2026-03-02T09:00:00Z")}, want: ErrInvalidEventTimes},
+		{name: "ends when it starts", event: models.CalendarBlock{StartTime: at("2026-03-02T09:00:00Z"), EndTime: at("2026-03-02T09:00:00Z")}, want: ErrInvalidEventTimes},
+		{name: "unknown course", event: models.CalendarBlock{Date: day("2026-03-02"), CourseID: func() *int { id := 8; return &id }()}, want: repository.ErrCourseNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeCalendarRepo{}
+			svc := NewCalendarService(repo, &fakeCourseRepo{})
+			event := tt.event
+			event.CollegeID, event.Title, event.EventType = 1, "Fest", models.EventTypeEvent
+
+			err := svc.CreateEvent(context.Background(), &event)
+			if tt.want != nil {
+				assert.ErrorIs(t, err, tt.want)
+				assert.Empty(t, repo.created)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDate, event.Date.Format(time.DateOnly))
+			assert.Equal(t, tt.wantEndDate, event.EndDate.Format(time.DateOnly))
+		})
+	}
+}
+
+func TestHolidaysDoNotFallOnExams(t *testing.T) {
+	courseID := 7
+	midterm := &models.CalendarBlock{ID: 1, CollegeID: 1, CourseID: &courseID, Title: "Midterm", EventType: models.EventExamType, Date: day("2026-03-04"), EndDate: day("2026-03-04")}
+	repo := &fakeCalendarRepo{events: []*models.CalendarBlock{midterm}}
+	svc := NewCalendarService(repo, &fakeCourseRepo{})
+
+	holiday := &models.CalendarBlock{CollegeID: 1, Title: "Spring break", EventType: models.EventTypeHoliday, Date: day("2026-03-02"), EndDate: day("2026-03-06")}
+	err := svc.CreateEvent(context.Background(), holiday)
+	var conflict *CalendarConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, []*models.CalendarBlock{midterm}, conflict.Conflicts)
+	assert.Equal(t, "holiday falls on 1 scheduled exam(s)", conflict.Error())
+	assert.Nil(t, repo.filter.CourseID, "a college-wide holiday is checked against every exam")
+	assert.Empty(t, repo.created)
+
+	holiday.EndDate = day("2026-03-03")
+	require.NoError(t, svc.CreateEvent(context.Background(), holiday))
+}
+
+func TestExamsDoNotFallOnHolidays(t *testing.T) {
+	courseID := 7
+	holiday := &models.CalendarBlock{ID: 2, CollegeID: 1, Title: "Founders' day", EventType: models.EventTypeHoliday, Date: day("2026-03-04"), EndDate: day("2026-03-04")}
+	exam := &models.CalendarBlock{ID: 3, CollegeID: 1, CourseID: &courseID, Title: "Quiz", EventType: models.EventExamType, Date: day("2026-03-05"), EndDate: day("2026-03-05")}
+	repo := &fakeCalendarRepo{events: []*models.CalendarBlock{holiday, exam}}
+	svc := NewCalendarService(repo, &fakeCourseRepo{})
+
+	// Moving the exam onto the holiday is refused
+	moved := *exam
+	moved.Date, moved.EndDate = day("2026-03-04"), time.Time{}
+	err := svc.UpdateEvent(context.Background(), &moved)
+	var conflict *CalendarConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, "exam falls on 1 holiday(s)", conflict.Error())
+	assert.Equal(t, 7, *repo.filter.CourseID)
+
+	timed := *exam
+	timed.StartTime, timed.EndTime = at("2026-03-05T10:00:00Z"), at("2026-03-05T11:00:00Z")
+	require.NoError(t, svc.UpdateEvent(context.Background(), &timed))
+	require.Len(t, repo.updated, 1)
+}
+
+func TestImportHolidays(t *testing.T) {
+	uid := "republic-day@holidays"
+	imported := &models.CalendarBlock{ID: 4, CollegeID: 1, Title: "Republic Day", EventType: models.EventTypeHoliday, Date: day("2026-01-25"), EndDate: day("2026-01-25"), ExternalUID: &uid}
+	exam := &models.CalendarBlock{ID: 5, CollegeID: 1, Title: "Finals", EventType: models.EventExamType, Date: day("2026-05-01"), EndDate: day("2026-05-01")}
+	repo := &fakeCalendarRepo{events: []*models.CalendarBlock{imported, exam}}
+	svc := NewCalendarService(repo, &fakeCourseRepo{})
+
+	file := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nUID:republic-day@holidays\r\nDTSTART;VALUE=DATE:20260126\r\nDTEND;VALUE=DATE:20260127\r\nSUMMARY:Republic Day\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:holi@holidays\r\nDTSTART;VALUE=DATE:20260303\r\nDTEND;VALUE=DATE:20260305\r\nSUMMARY:Holi\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:may-day@holidays\r\nDTSTART;VALUE=DATE:20260501\r\nSUMMARY:May Day\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:holi@holidays\r\nDTSTART;VALUE=DATE:20260304\r\nSUMMARY:Holi again\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20260815\r\nSUMMARY:No UID\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	result, err := svc.ImportHolidays(context.Background(), 1, strings.NewReader(file))
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 1, result.Updated)
+	require.Len(t, result.Skipped, 3)
+	assert.Equal(t, "may-day@holidays", result.Skipped[0].UID)
+	assert.Equal(t, "holiday falls on 1 scheduled exam(s)", result.Skipped[0].Reason)
+	assert.Equal(t, "UID appears more than once in the file", result.Skipped[1].Reason)
+	assert.Equal(t, "event has no UID", result.Skipped[2].Reason)
+
+	require.Len(t, repo.updated, 1)
+	assert.Equal(t, 4, repo.updated[0].ID)
+	assert.Equal(t, "2026-01-26", repo.updated[0].Date.Format(time.DateOnly))
+
+	require.Len(t, repo.created, 1)
+	holi := repo.created[0]
+	assert.Equal(t, models.EventTypeHoliday, holi.EventType)
+	assert.Nil(t, holi.CourseID)
+	assert.Equal(t, "holi@holidays", *holi.ExternalUID)
+	assert.Equal(t, "2026-03-03", holi.Date.Format(time.DateOnly))
+	assert.Equal(t, "2026-03-04", holi.EndDate.Format(time.DateOnly), "DTEND is exclusive")
+
+	_, err = svc.ImportHolidays(context.Background(), 1, strings.NewReader("holidays.csv"))
+	assert.ErrorIs(t, err, ErrInvalidCalendarFile)
+}
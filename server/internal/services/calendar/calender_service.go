@@ -1,5 +1,174 @@
 package calendar
 
-type CalendarService interface{}
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
 
-type calendarService struct{}
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrInvalidEventDates = errors.New("event needs a date and must not end before it starts")
+	ErrInvalidEventTimes = errors.New("start_time and end_time must be given together, with end_time after start_time")
+)
+
+// CalendarConflictError lists the exams a holiday would fall on, or the
+// holidays an exam would be scheduled on.
+type CalendarConflictError struct {
+	EventType models.CalendarEventType `json:"event_type"`
+	Conflicts []*models.CalendarBlock  `json:"conflicts"`
+}
+
+func (e *CalendarConflictError) Error() string {
+	if e.EventType == models.EventTypeHoliday {
+		return fmt.Sprintf("holiday falls on %d scheduled exam(s)", len(e.Conflicts))
+	}
+	return fmt.Sprintf("exam falls on %d holiday(s)", len(e.Conflicts))
+}
+
+type CalendarService interface {
+	// CreateEvent adds the event. Timed events take their dates from
+	// StartTime and EndTime; all-day events end on Date unless EndDate is
+	// set. Holidays may not fall on exams of the courses they concern and
+	// vice versa; that is refused with a *CalendarConflictError.
+	CreateEvent(ctx context.Context, event *models.CalendarBlock) error
+	GetEvent(ctx context.Context, collegeID int, eventID int) (*models.CalendarBlock, error)
+	GetEvents(ctx context.Context, filter models.CalendarBlockFilter) ([]*models.CalendarBlock, error)
+	CountEvents(ctx context.Context, filter models.CalendarBlockFilter) (int, error)
+	// UpdateEvent replaces the event, checked like CreateEvent.
+	UpdateEvent(ctx context.Context, event *models.CalendarBlock) error
+	DeleteEvent(ctx context.Context, collegeID int, eventID int) error
+	// ImportHolidays adds the VEVENTs of an iCalendar file as college-wide
+	// holidays. Events imported before, matched by UID, are updated; events
+	// that fail the checks of CreateEvent are skipped and reported.
+	ImportHolidays(ctx context.Context, collegeID int, r io.Reader) (*models.HolidayImport, error)
+}
+
+type calendarService struct {
+	calendarRepo repository.CalendarRepository
+	courseRepo   repository.CourseRepository
+	validate     validator.Validate
+}
+
+func NewCalendarService(calendarRepo repository.CalendarRepository, courseRepo repository.CourseRepository) CalendarService {
+	return &calendarService{
+		calendarRepo: calendarRepo,
+		courseRepo:   courseRepo,
+		validate:     *validator.New(),
+	}
+}
+
+func (s *calendarService) CreateEvent(ctx context.Context, event *models.CalendarBlock) error {
+	if err := s.check(ctx, event); err != nil {
+		return err
+	}
+	return s.calendarRepo.CreateCalendarBlock(ctx, event)
+}
+
+func (s *calendarService) GetEvent(ctx context.Context, collegeID int, eventID int) (*models.CalendarBlock, error) {
+	return s.calendarRepo.GetCalendarBlockByID(ctx, eventID, collegeID)
+}
+
+func (s *calendarService) GetEvents(ctx context.Context, filter models.CalendarBlockFilter) ([]*models.CalendarBlock, error) {
+	return s.calendarRepo.GetCalendarBlocks(ctx, filter)
+}
+
+func (s *calendarService) CountEvents(ctx context.Context, filter models.CalendarBlockFilter) (int, error) {
+	return s.calendarRepo.CountCalendarBlocks(ctx, filter)
+}
+
+func (s *calendarService) UpdateEvent(ctx context.Context, event *models.CalendarBlock) error {
+	if _, err := s.calendarRepo.GetCalendarBlockByID(ctx, event.ID, event.CollegeID); err != nil {
+		return err
+	}
+	if err := s.check(ctx, event); err != nil {
+		return err
+	}
+	return s.calendarRepo.UpdateCalendarBlock(ctx, event)
+}
+
+func (s *calendarService) DeleteEvent(ctx context.Context, collegeID int, eventID int) error {
+	return s.calendarRepo.DeleteCalendarBlock(ctx, eventID, collegeID)
+}
+
+// check validates the event, settles its dates and looks for collisions
+// between holidays and exams.
+func (s *calendarService) check(ctx context.Context, event *models.CalendarBlock) error {
+	if err := s.validate.Struct(event); err != nil {
+		return fmt.Errorf("validation failed %w", err)
+	}
+	if (event.StartTime == nil) != (event.EndTime == nil) {
+		return ErrInvalidEventTimes
+	}
+	if event.StartTime != nil {
+		if !event.EndTime.After(*event.StartTime) {
+			return ErrInvalidEventTimes
+		}
+		event.Date, event.EndDate = *event.StartTime, *event.EndTime
+	}
+	if event.Date.IsZero() {
+		return ErrInvalidEventDates
+	}
+	if event.EndDate.IsZero() {
+		event.EndDate = event.Date
+	}
+	event.Date, event.EndDate = dateOf(event.Date), dateOf(event.EndDate)
+	if event.EndDate.Before(event.Date) {
+		return ErrInvalidEventDates
+	}
+
+	if event.CourseID != nil {
+		if _, err := s.courseRepo.FindCourseByID(ctx, event.CollegeID, *event.CourseID); err != nil {
+			return err
+		}
+	}
+	return s.checkCollisions(ctx, event)
+}
+
+// checkCollisions refuses a holiday on the days of an exam, or an exam on a
+// holiday, when both concern the same course or either is college-wide.
+func (s *calendarService) checkCollisions(ctx context.Context, event *models.CalendarBlock) error {
+	var other models.CalendarEventType
+	switch event.EventType {
+	case models.EventTypeHoliday:
+		other = models.EventExamType
+	case models.EventExamType:
+		other = models.EventTypeHoliday
+	default:
+		return nil
+	}
+
+	// A course's own events and college-wide ones, or every event for a
+	// college-wide one
+	found, err := s.calendarRepo.GetCalendarBlocks(ctx, models.CalendarBlockFilter{
+		CollegeID: &event.CollegeID,
+		CourseID:  event.CourseID,
+		EventType: &other,
+		StartDate: &event.Date,
+		EndDate:   &event.EndDate,
+	})
+	if err != nil {
+		return err
+	}
+	conflicts := make([]*models.CalendarBlock, 0, len(found))
+	for _, b := range found {
+		if b.ID != event.ID {
+			conflicts = append(conflicts, b)
+		}
+	}
+	if len(conflicts) > 0 {
+		return &CalendarConflictError{EventType: event.EventType, Conflicts: conflicts}
+	}
+	return nil
+}
+
+// dateOf is t's calendar date, as stored in a DATE column.
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
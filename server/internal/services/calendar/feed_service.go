@@ -0,0 +1,255 @@
+package calendar
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+	"eduhub/server/pkg/ical"
+)
+
+const (
+	studentRole = "student"
+
+	feedTokenBytes = 32
+	// Feeds cover a month back and half a year ahead, except timetable
+	// blocks which recur through the current term.
+	feedPastDays   = 30
+	feedFutureDays = 180
+)
+
+var ErrNoStudentProfile = errors.New("user has no student profile in this college")
+
+type CalendarFeedService interface {
+	// CreateFeed issues the user a new feed, revoking their previous one.
+	// The feed's Token is only available here; just its hash is stored.
+	CreateFeed(ctx context.Context, collegeID int, identityID string, role string) (*models.CalendarFeed, error)
+	GetFeed(ctx context.Context, collegeID int, identityID string) (*models.CalendarFeed, error)
+	RevokeFeed(ctx context.Context, collegeID int, identityID string) error
+	// FeedCalendar builds the calendar of the feed with this token: lectures,
+	// timetable blocks without generated lectures, assignment and quiz due
+	// dates and calendar events of the feed's courses, plus college-wide
+	// events. Event UIDs are stable across requests.
+	FeedCalendar(ctx context.Context, token string) (*ical.Calendar, error)
+}
+
+type calendarFeedService struct {
+	feedRepo    repository.CalendarFeedRepository
+	studentRepo repository.StudentRepository
+	termRepo    repository.AcademicTermRepository
+	now         func() time.Time
+}
+
+func NewCalendarFeedService(feedRepo repository.CalendarFeedRepository, studentRepo repository.StudentRepository, termRepo repository.AcademicTermRepository) CalendarFeedService {
+	return &calendarFeedService{
+		feedRepo:    feedRepo,
+		studentRepo: studentRepo,
+		termRepo:    termRepo,
+		now:         time.Now,
+	}
+}
+
+func (s *calendarFeedService) CreateFeed(ctx context.Context, collegeID int, identityID string, role string) (*models.CalendarFeed, error) {
+	token, err := newFeedToken()
+	if err != nil {
+		return nil, err
+	}
+	feed := &models.CalendarFeed{
+		CollegeID:  collegeID,
+		IdentityID: identityID,
+		Role:       role,
+		TokenHash:  hashFeedToken(token),
+	}
+	if role == studentRole {
+		student, err := s.studentRepo.FindByKratosID(ctx, identityID)
+		if err != nil {
+			return nil, err
+		}
+		if student == nil || student.CollegeID != collegeID {
+			return nil, ErrNoStudentProfile
+		}
+		feed.StudentID = &student.StudentID
+	}
+
+	if err := s.feedRepo.CreateFeed(ctx, feed); err != nil {
+		return nil, err
+	}
+	feed.Token = token
+	return feed, nil
+}
+
+func (s *calendarFeedService) GetFeed(ctx context.Context, collegeID int, identityID string) (*models.CalendarFeed, error) {
+	return s.feedRepo.GetActiveFeed(ctx, collegeID, identityID)
+}
+
+func (s *calendarFeedService) RevokeFeed(ctx context.Context, collegeID int, identityID string) error {
+	return s.feedRepo.RevokeFeed(ctx, collegeID, identityID)
+}
+
+func (s *calendarFeedService) FeedCalendar(ctx context.Context, token string) (*ical.Calendar, error) {
+	feed, err := s.feedRepo.UseFeed(ctx, hashFeedToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	courses, err := s.feedRepo.FindFeedCourses(ctx, feed)
+	if err != nil {
+		return nil, err
+	}
+	courseIDs := make([]int, len(courses))
+	courseNames := make(map[int]string, len(courses))
+	for i, c := range courses {
+		courseIDs[i] = c.ID
+		courseNames[c.ID] = c.Name
+	}
+
+	now := s.now()
+	from, to := now.AddDate(0, 0, -feedPastDays), now.AddDate(0, 0, feedFutureDays)
+	cal := &ical.Calendar{Name: "EduHub"}
+
+	lectures, err := s.feedRepo.FindFeedLectures(ctx, feed.CollegeID, courseIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range lectures {
+		cal.Events = append(cal.Events, ical.Event{
+			UID:         feedUID("lecture", l.ID),
+			Summary:     courseNames[l.CourseID] + ": " + l.Title,
+			Description: l.Description,
+			Location:    l.MeetingLink,
+			Start:       l.StartTime,
+			End:         l.EndTime,
+			Modified:    l.UpdatedAt,
+		})
+	}
+
+	blocks, err := s.timetableEvents(ctx, feed.CollegeID, courseIDs, courseNames)
+	if err != nil {
+		return nil, err
+	}
+	cal.Events = append(cal.Events, blocks...)
+
+	assignments, err := s.feedRepo.FindFeedAssignments(ctx, feed.CollegeID, courseIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range assignments {
+		cal.Events = append(cal.Events, ical.Event{
+			UID:         feedUID("assignment", a.ID),
+			Summary:     courseNames[a.CourseID] + ": " + a.Title + " due",
+			Description: a.Description,
+			Start:       a.DueDate,
+			End:         a.DueDate,
+			Modified:    a.UpdatedAt,
+		})
+	}
+
+	quizzes, err := s.feedRepo.FindFeedQuizzes(ctx, feed.CollegeID, courseIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, q := range quizzes {
+		cal.Events = append(cal.Events, ical.Event{
+			UID:         feedUID("quiz", q.ID),
+			Summary:     courseNames[q.CourseID] + ": " + q.Title + " due",
+			Description: q.Description,
+			Start:       q.DueDate,
+			End:         q.DueDate,
+			Modified:    q.UpdatedAt,
+		})
+	}
+
+	events, err := s.feedRepo.FindFeedCalendarBlocks(ctx, feed.CollegeID, courseIDs, dateOf(from), dateOf(to))
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		event := ical.Event{
+			UID:         feedUID("event", e.ID),
+			Summary:     e.Title,
+			Description: e.Description,
+			Start:       e.Date,
+			End:         e.EndDate.AddDate(0, 0, 1),
+			AllDay:      true,
+			Modified:    e.UpdatedAt,
+		}
+		if e.CourseID != nil {
+			event.Summary = courseNames[*e.CourseID] + ": " + e.Title
+		}
+		if e.StartTime != nil && e.EndTime != nil {
+			event.Start, event.End, event.AllDay = *e.StartTime, *e.EndTime, false
+		}
+		cal.Events = append(cal.Events, event)
+	}
+	return cal, nil
+}
+
+// timetableEvents turns the current term's timetable blocks that have no
+// lectures yet into weekly events running through the term. Blocks are wall
+// clock times, so the events float in the reader's time zone.
+func (s *calendarFeedService) timetableEvents(ctx context.Context, collegeID int, courseIDs []int, courseNames map[int]string) ([]ical.Event, error) {
+	if len(courseIDs) == 0 {
+		return nil, nil
+	}
+	term, err := s.termRepo.GetCurrentTerm(ctx, collegeID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoCurrentTerm) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	blocks, err := s.feedRepo.FindFeedTimetableBlocks(ctx, collegeID, term.ID, courseIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := dateOf(term.StartDate), dateOf(term.EndDate)
+	until := "FREQ=WEEKLY;UNTIL=" + end.Format("20060102") + "T235959"
+	events := make([]ical.Event, 0, len(blocks))
+	for _, b := range blocks {
+		first := start.AddDate(0, 0, (int(b.DayOfWeek)-int(start.Weekday())+7)%7)
+		if first.After(end) {
+			continue
+		}
+		event := ical.Event{
+			UID:      feedUID("timetable", b.ID),
+			Summary:  courseNames[b.CourseID],
+			Start:    first.Add(time.Duration(b.StartTime.Microseconds) * time.Microsecond),
+			End:      first.Add(time.Duration(b.EndTime.Microseconds) * time.Microsecond),
+			Floating: true,
+			RRule:    until,
+			Modified: b.UpdatedAt,
+		}
+		if b.RoomNumber != nil {
+			event.Location = *b.RoomNumber
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// feedUID is the UID of an item in every feed it appears in, so calendar
+// clients update rather than duplicate it.
+func feedUID(kind string, id int) string {
+	return fmt.Sprintf("%s-%d@eduhub", kind, id)
+}
+
+func newFeedToken() (string, error) {
+	b := make([]byte, feedTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate feed token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
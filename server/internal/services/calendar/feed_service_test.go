@@ -0,0 +1,171 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFeedRepo struct {
+	repository.CalendarFeedRepository
+	feeds       []*models.CalendarFeed
+	courses     []*models.Course
+	lectures    []*models.Lecture
+	assignments []*models.Assignment
+	events      []*models.CalendarBlock
+	blocks      []*models.TimeTableBlock
+}
+
+func (f *fakeFeedRepo) CreateFeed(ctx context.Context, feed *models.CalendarFeed) error {
+	feed.ID = len(f.feeds) + 1
+	f.feeds = append(f.feeds, feed)
+	return nil
+}
+
+func (f *fakeFeedRepo) UseFeed(ctx context.Context, tokenHash string) (*models.CalendarFeed, error) {
+	for _, feed := range f.feeds {
+		if feed.TokenHash == tokenHash {
+			return feed, nil
+		}
+	}
+	return nil, repository.ErrCalendarFeedNotFound
+}
+
+func (f *fakeFeedRepo) FindFeedCourses(ctx context.Context, feed *models.CalendarFeed) ([]*models.Course, error) {
+	return f.courses, nil
+}
+
+func (f *fakeFeedRepo) FindFeedLectures(ctx context.Context, collegeID int, courseIDs []int, from, to time.Time) ([]*models.Lecture, error) {
+	return f.lectures, nil
+}
+
+func (f *fakeFeedRepo) FindFeedAssignments(ctx context.Context, collegeID int, courseIDs []int, from, to time.Time) ([]*models.Assignment, error) {
+	return f.assignments, nil
+}
+
+func (f *fakeFeedRepo) FindFeedQuizzes(ctx context.Context, collegeID int, courseIDs []int, from, to time.Time) ([]*models.Quiz, error) {
+	return nil, nil
+}
+
+func (f *fakeFeedRepo) FindFeedCalendarBlocks(ctx context.Context, collegeID int, courseIDs []int, from, to time.Time) ([]*models.CalendarBlock, error) {
+	return f.events, nil
+}
+
+func (f *fakeFeedRepo) FindFeedTimetableBlocks(ctx context.Context, collegeID int, termID int, courseIDs []int) ([]*models.TimeTableBlock, error) {
+	return f.blocks, nil
+}
+
+type fakeStudentRepo struct{ repository.StudentRepository }
+
+func (f *fakeStudentRepo) FindByKratosID(ctx context.Context, kratosID string) (*models.Student, error) {
+	if kratosID != "kratos-student" {
+		return nil, nil
+	}
+	return &models.Student{StudentID: 42, CollegeID: 1, KratosIdentityID: kratosID}, nil
+}
+
+type fakeTermRepo struct {
+	repository.AcademicTermRepository
+	term *models.AcademicTerm
+}
+
+func (f *fakeTermRepo) GetCurrentTerm(ctx context.Context, collegeID int) (*models.AcademicTerm, error) {
+	if f.term == nil {
+		return nil, repository.ErrNoCurrentTerm
+	}
+	return f.term, nil
+}
+
+func TestCreateFeed(t *testing.T) {
+	repo := &fakeFeedRepo{}
+	svc := NewCalendarFeedService(repo, &fakeStudentRepo{}, &fakeTermRepo{})
+
+	feed, err := svc.CreateFeed(context.Background(), 1, "kratos-student", "student")
+	require.NoError(t, err)
+	assert.Len(t, feed.Token, 43)
+	assert.Equal(t, hashFeedToken(feed.Token), feed.TokenHash)
+	assert.NotContains(t, feed.TokenHash, feed.Token)
+	require.NotNil(t, feed.StudentID)
+	assert.Equal(t, 42, *feed.StudentID)
+
+	other, err := svc.CreateFeed(context.Background(), 1, "kratos-faculty", "faculty")
+	require.NoError(t, err)
+	assert.Nil(t, other.StudentID)
+	assert.NotEqual(t, feed.Token, other.Token)
+
+	_, err = svc.CreateFeed(context.Background(), 1, "kratos-unknown", "student")
+	assert.ErrorIs(t, err, ErrNoStudentProfile)
+}
+
+func TestFeedCalendar(t *testing.T) {
+	courseID := 7
+	room := "B-204"
+	updated := time.Date(2026, 8, 1, 8, 0, 0, 0, time.UTC)
+	repo := &fakeFeedRepo{
+		courses: []*models.Course{{ID: courseID, Name: "Algorithms"}},
+		lectures: []*models.Lecture{{
+			ID: 11, CourseID: courseID, Title: "Graphs",
+			StartTime: time.Date(2026, 8, 4, 4, 0, 0, 0, time.UTC), EndTime: time.Date(2026, 8, 4, 5, 0, 0, 0, time.UTC),
+			UpdatedAt: updated,
+		}},
+		assignments: []*models.Assignment{{ID: 12, CourseID: courseID, Title: "Problem set 1", DueDate: time.Date(2026, 8, 10, 18, 30, 0, 0, time.UTC)}},
+		events: []*models.CalendarBlock{
+			{ID: 13, Title: "Independence Day", EventType: models.EventTypeHoliday, Date: day("2026-08-15"), EndDate: day("2026-08-15")},
+			{ID: 14, CourseID: &courseID, Title: "Midterm", EventType: models.EventExamType, Date: day("2026-09-01"), EndDate: day("2026-09-01"),
+				StartTime: at("2026-09-01T09:00:00Z"), EndTime: at("2026-09-01T11:00:00Z")},
+		},
+		blocks: []*models.TimeTableBlock{{
+			ID: 15, CourseID: courseID, DayOfWeek: time.Wednesday, RoomNumber: &room,
+			StartTime: pgtype.Time{Microseconds: int64(10 * time.Hour / time.Microsecond), Status: pgtype.Present},
+			EndTime:   pgtype.Time{Microseconds: int64(11 * time.Hour / time.Microsecond), Status: pgtype.Present},
+		}},
+	}
+	// Term starts on a Monday
+	terms := &fakeTermRepo{term: &models.AcademicTerm{ID: 3, StartDate: day("2026-08-03"), EndDate: day("2026-12-11")}}
+	svc := NewCalendarFeedService(repo, &fakeStudentRepo{}, terms)
+
+	feed, err := svc.CreateFeed(context.Background(), 1, "kratos-student", "student")
+	require.NoError(t, err)
+
+	_, err = svc.FeedCalendar(context.Background(), "not-a-token")
+	assert.ErrorIs(t, err, repository.ErrCalendarFeedNotFound)
+
+	cal, err := svc.FeedCalendar(context.Background(), feed.Token)
+	require.NoError(t, err)
+	uids := make([]string, len(cal.Events))
+	for i, e := range cal.Events {
+		uids[i] = e.UID
+	}
+	assert.Equal(t, []string{"lecture-11@eduhub", "timetable-15@eduhub", "assignment-12@eduhub", "event-13@eduhub", "event-14@eduhub"}, uids)
+
+	lecture := cal.Events[0]
+	assert.Equal(t, "Algorithms: Graphs", lecture.Summary)
+	assert.Equal(t, updated, lecture.Modified)
+
+	block := cal.Events[1]
+	assert.True(t, block.Floating)
+	assert.Equal(t, time.Date(2026, 8, 5, 10, 0, 0, 0, time.UTC), block.Start)
+	assert.Equal(t, time.Date(2026, 8, 5, 11, 0, 0, 0, time.UTC), block.End)
+	assert.Equal(t, "FREQ=WEEKLY;UNTIL=20261211T235959", block.RRule)
+	assert.Equal(t, "B-204", block.Location)
+
+	holiday := cal.Events[3]
+	assert.True(t, holiday.AllDay)
+	assert.Equal(t, day("2026-08-16"), holiday.End)
+
+	exam := cal.Events[4]
+	assert.False(t, exam.AllDay)
+	assert.Equal(t, "Algorithms: Midterm", exam.Summary)
+
+	var buf bytes.Buffer
+	require.NoError(t, cal.Encode(&buf))
+	assert.Contains(t, buf.String(), "DTSTART:20260805T100000\r\n")
+}
@@ -0,0 +1,103 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+	"eduhub/server/pkg/ical"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var ErrInvalidCalendarFile = errors.New("invalid iCalendar file")
+
+func (s *calendarService) ImportHolidays(ctx context.Context, collegeID int, r io.Reader) (*models.HolidayImport, error) {
+	events, err := ical.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCalendarFile, err)
+	}
+
+	result := &models.HolidayImport{Skipped: []*models.HolidayImportSkip{}}
+	seen := make(map[string]bool, len(events))
+	for _, e := range events {
+		skip := func(reason string) {
+			result.Skipped = append(result.Skipped, &models.HolidayImportSkip{UID: e.UID, Summary: e.Summary, Reason: reason})
+		}
+		uid := strings.TrimSpace(e.UID)
+		switch {
+		case uid == "":
+			skip("event has no UID")
+			continue
+		case seen[uid]:
+			skip("UID appears more than once in the file")
+			continue
+		case e.RRule != "":
+			skip("recurring events are not imported")
+			continue
+		}
+		seen[uid] = true
+
+		holiday := holidayFromICS(collegeID, uid, e)
+		existing, err := s.calendarRepo.GetCalendarBlockByExternalUID(ctx, collegeID, uid)
+		switch {
+		case err == nil:
+			holiday.ID = existing.ID
+		case !errors.Is(err, repository.ErrCalendarBlockNotFound):
+			return nil, err
+		}
+
+		if err := s.check(ctx, holiday); err != nil {
+			var (
+				conflict *CalendarConflictError
+				invalid  validator.ValidationErrors
+			)
+			if errors.As(err, &conflict) || errors.As(err, &invalid) ||
+				errors.Is(err, ErrInvalidEventDates) || errors.Is(err, ErrInvalidEventTimes) {
+				skip(err.Error())
+				continue
+			}
+			return nil, err
+		}
+
+		if holiday.ID != 0 {
+			if err := s.calendarRepo.UpdateCalendarBlock(ctx, holiday); err != nil {
+				return nil, err
+			}
+			result.Updated++
+			continue
+		}
+		if err := s.calendarRepo.CreateCalendarBlock(ctx, holiday); err != nil {
+			return nil, err
+		}
+		result.Created++
+	}
+	return result, nil
+}
+
+// holidayFromICS makes a college-wide holiday of an imported event. All-day
+// events end the day before their exclusive DTEND.
+func holidayFromICS(collegeID int, uid string, e ical.Event) *models.CalendarBlock {
+	holiday := &models.CalendarBlock{
+		CollegeID:   collegeID,
+		Title:       strings.TrimSpace(e.Summary),
+		Description: e.Description,
+		EventType:   models.EventTypeHoliday,
+		ExternalUID: &uid,
+	}
+	if !e.AllDay {
+		start, end := e.Start, e.End
+		holiday.StartTime, holiday.EndTime = &start, &end
+		return holiday
+	}
+
+	holiday.Date, holiday.EndDate = e.Start, e.End.AddDate(0, 0, -1)
+	if holiday.EndDate.Before(holiday.Date) {
+		holiday.EndDate = holiday.Date
+	}
+	return holiday
+}
@@ -2,38 +2,74 @@ package course
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"strconv"
 
 	"eduhub/server/internal/models"
 	"eduhub/server/internal/repository"
+	"eduhub/server/internal/services/auth"
 
 	"github.com/go-playground/validator/v10"
 )
 
+var (
+	ErrNotFaculty       = errors.New("instructor must be an active faculty member")
+	ErrInstructorChange = errors.New("the instructor is changed by assigning a new one")
+)
+
+const facultyRole = "faculty"
+
 type CourseService interface {
 	CreateCourse(ctx context.Context, course *models.Course) error
 	FindCourseByID(ctx context.Context, collegeID int, courseID int) (*models.Course, error) // Added collegeID
+	// IsCourseInstructor reports whether the Kratos identity instructs the course.
+	IsCourseInstructor(ctx context.Context, collegeID int, courseID int, identityID string) (bool, error)
+	// UpdateCourse keeps the course's instructor; changing it fails with
+	// ErrInstructorChange, as only AssignInstructor may do that.
 	UpdateCourse(ctx context.Context, courseID int, course *models.Course) error
+	// DeleteCourse also revokes the instructor's relations in Keto.
 	DeleteCourse(ctx context.Context, collegeID int, courseID int) error
 
 	// Find methods with pagination
 	FindAllCourses(ctx context.Context, collegeID int, limit, offset uint64) ([]*models.Course, error)
 	FindCoursesByInstructor(ctx context.Context, collegeID int, instructorID int, limit, offset uint64) ([]*models.Course, error)
+	SearchCourses(ctx context.Context, collegeID int, search string, limit, offset uint64) ([]*models.Course, error)
 
 	// Count methods
 	CountCoursesByCollege(ctx context.Context, collegeID int) (int, error)
 	CountCoursesByInstructor(ctx context.Context, collegeID int, instructorID int) (int, error)
+	CountSearchCourses(ctx context.Context, collegeID int, search string) (int, error)
+
+	// AssignInstructor makes instructorID the course's instructor, granting them
+	// the course's faculty relations in Keto and revoking the previous instructor's.
+	AssignInstructor(ctx context.Context, collegeID int, courseID int, instructorID int) (*models.Course, error)
+
+	// Prerequisites; adding one that would close a cycle fails with
+	// repository.ErrPrerequisiteCycle.
+	AddPrerequisite(ctx context.Context, prerequisite *models.CoursePrerequisite) error
+	RemovePrerequisite(ctx context.Context, collegeID int, courseID int, prerequisiteID int) error
+	GetPrerequisites(ctx context.Context, collegeID int, courseID int) ([]*models.CoursePrerequisite, error)
 }
 
 type courseService struct {
-	courseRepo repository.CourseRepository
-	validate   *validator.Validate
+	courseRepo       repository.CourseRepository
+	userRepo         repository.UserRepository
+	enrollmentRepo   repository.EnrollmentRepository
+	prerequisiteRepo repository.CoursePrerequisiteRepository
+	assigner         *auth.Assigner
+	validate         *validator.Validate
 }
 
-func NewCourseService(courseRepo repository.CourseRepository) CourseService {
+func NewCourseService(courseRepo repository.CourseRepository, userRepo repository.UserRepository, enrollmentRepo repository.EnrollmentRepository, prerequisiteRepo repository.CoursePrerequisiteRepository, assigner *auth.Assigner) CourseService {
 	return &courseService{
-		courseRepo: courseRepo,
-		validate:   validator.New(),
+		courseRepo:       courseRepo,
+		userRepo:         userRepo,
+		enrollmentRepo:   enrollmentRepo,
+		prerequisiteRepo: prerequisiteRepo,
+		assigner:         assigner,
+		validate:         validator.New(),
 	}
 }
 
@@ -41,27 +77,79 @@ func (c *courseService) CreateCourse(ctx context.Context, course *models.Course)
 	if err := c.validate.Struct(course); err != nil {
 		return fmt.Errorf("validation error %w", err)
 	}
-	return c.courseRepo.CreateCourse(ctx, course)
+	instructor, err := c.findFaculty(ctx, course.InstructorID)
+	if err != nil {
+		return err
+	}
+	if err := c.courseRepo.CreateCourse(ctx, course); err != nil {
+		return err
+	}
+	if err := c.assigner.AssignFacultyToCourse(ctx, instructor.KratosIdentityID, strconv.Itoa(course.ID)); err != nil {
+		// A course nobody can manage is worse than no course at all.
+		if delErr := c.courseRepo.DeleteCourse(ctx, course.CollegeID, course.ID); delErr != nil {
+			return fmt.Errorf("CreateCourse: %w (removing the course also failed: %v)", err, delErr)
+		}
+		return fmt.Errorf("CreateCourse: %w", err)
+	}
+	return nil
 }
 
 func (c *courseService) FindCourseByID(ctx context.Context, collegeID int, courseID int) (*models.Course, error) {
 	return c.courseRepo.FindCourseByID(ctx, collegeID, courseID)
 }
 
+func (c *courseService) IsCourseInstructor(ctx context.Context, collegeID int, courseID int, identityID string) (bool, error) {
+	return c.courseRepo.IsCourseInstructor(ctx, collegeID, courseID, identityID)
+}
+
 func (c *courseService) UpdateCourse(ctx context.Context, courseID int, course *models.Course) error {
 	if course.ID != courseID {
 		return fmt.Errorf("courseID not matching")
 	}
 
+	existing, err := c.courseRepo.FindCourseByID(ctx, course.CollegeID, courseID)
+	if err != nil {
+		return err
+	}
+	if course.InstructorID == 0 {
+		course.InstructorID = existing.InstructorID
+	}
+	if course.InstructorID != existing.InstructorID {
+		return ErrInstructorChange
+	}
 	if err := c.validate.Struct(course); err != nil {
 		return fmt.Errorf("validation error %w", err)
 	}
+	if err := c.courseRepo.UpdateCourse(ctx, course); err != nil {
+		return err
+	}
 
-	return c.courseRepo.UpdateCourse(ctx, course)
+	// Seats added to a full course go to the waitlist straight away.
+	if capacityGrew(existing.Capacity, course.Capacity) {
+		if _, err := c.enrollmentRepo.PromoteWaitlist(ctx, course.CollegeID, course.ID); err != nil {
+			return fmt.Errorf("UpdateCourse: %w", err)
+		}
+	}
+	return nil
+}
+
+func capacityGrew(before, after *int) bool {
+	if before == nil {
+		return false
+	}
+	return after == nil || *after > *before
 }
 
 func (c *courseService) DeleteCourse(ctx context.Context, collegeID int, courseID int) error {
-	return c.courseRepo.DeleteCourse(ctx, collegeID, courseID)
+	course, err := c.courseRepo.FindCourseByID(ctx, collegeID, courseID)
+	if err != nil {
+		return err
+	}
+	if err := c.courseRepo.DeleteCourse(ctx, collegeID, courseID); err != nil {
+		return err
+	}
+	c.revokeInstructor(ctx, course.InstructorID, strconv.Itoa(courseID))
+	return nil
 }
 
 // Find methods with pagination
@@ -73,6 +161,10 @@ func (c *courseService) FindCoursesByInstructor(ctx context.Context, collegeID i
 	return c.courseRepo.FindCoursesByInstructor(ctx, collegeID, instructorID, limit, offset)
 }
 
+func (c *courseService) SearchCourses(ctx context.Context, collegeID int, search string, limit, offset uint64) ([]*models.Course, error) {
+	return c.courseRepo.SearchCourses(ctx, collegeID, search, limit, offset)
+}
+
 // Count methods
 func (c *courseService) CountCoursesByCollege(ctx context.Context, collegeID int) (int, error) {
 	return c.courseRepo.CountCoursesByCollege(ctx, collegeID)
@@ -81,3 +173,89 @@ func (c *courseService) CountCoursesByCollege(ctx context.Context, collegeID int
 func (c *courseService) CountCoursesByInstructor(ctx context.Context, collegeID int, instructorID int) (int, error) {
 	return c.courseRepo.CountCoursesByInstructor(ctx, collegeID, instructorID)
 }
+
+func (c *courseService) CountSearchCourses(ctx context.Context, collegeID int, search string) (int, error) {
+	return c.courseRepo.CountSearchCourses(ctx, collegeID, search)
+}
+
+func (c *courseService) AssignInstructor(ctx context.Context, collegeID int, courseID int, instructorID int) (*models.Course, error) {
+	course, err := c.courseRepo.FindCourseByID(ctx, collegeID, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if course.InstructorID == instructorID {
+		return course, nil
+	}
+	previousID := course.InstructorID
+	course.InstructorID = instructorID
+	if err := c.changeInstructor(ctx, previousID, course); err != nil {
+		return nil, err
+	}
+	return course, nil
+}
+
+// changeInstructor saves course with its new instructor. The new instructor is
+// granted access before the row changes, so a Keto failure leaves the course
+// as it was; the previous instructor loses access only once the row is saved.
+func (c *courseService) changeInstructor(ctx context.Context, previousID int, course *models.Course) error {
+	instructor, err := c.findFaculty(ctx, course.InstructorID)
+	if err != nil {
+		return err
+	}
+	courseID := strconv.Itoa(course.ID)
+	if err := c.assigner.AssignFacultyToCourse(ctx, instructor.KratosIdentityID, courseID); err != nil {
+		return fmt.Errorf("changeInstructor: %w", err)
+	}
+	if err := c.courseRepo.UpdateCourse(ctx, course); err != nil {
+		if revokeErr := c.assigner.RemoveFacultyFromCourse(ctx, instructor.KratosIdentityID, courseID); revokeErr != nil {
+			log.Printf("changeInstructor: course %s: revoking new instructor after failed update: %v", courseID, revokeErr)
+		}
+		return err
+	}
+
+	c.revokeInstructor(ctx, previousID, courseID)
+	return nil
+}
+
+// revokeInstructor removes the faculty relations userID holds on the course.
+// Failures are only logged, as the course has already changed by then.
+func (c *courseService) revokeInstructor(ctx context.Context, userID int, courseID string) {
+	user, err := c.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		// The instructor's user may already be gone.
+		log.Printf("revokeInstructor: course %s: looking up instructor %d: %v", courseID, userID, err)
+		return
+	}
+	if err := c.assigner.RemoveFacultyFromCourse(ctx, user.KratosIdentityID, courseID); err != nil {
+		log.Printf("revokeInstructor: course %s: revoking instructor %d: %v", courseID, userID, err)
+	}
+}
+
+func (c *courseService) AddPrerequisite(ctx context.Context, prerequisite *models.CoursePrerequisite) error {
+	if err := c.validate.Struct(prerequisite); err != nil {
+		return fmt.Errorf("validation error %w", err)
+	}
+	return c.prerequisiteRepo.AddPrerequisite(ctx, prerequisite)
+}
+
+func (c *courseService) RemovePrerequisite(ctx context.Context, collegeID int, courseID int, prerequisiteID int) error {
+	return c.prerequisiteRepo.RemovePrerequisite(ctx, collegeID, courseID, prerequisiteID)
+}
+
+func (c *courseService) GetPrerequisites(ctx context.Context, collegeID int, courseID int) ([]*models.CoursePrerequisite, error) {
+	return c.prerequisiteRepo.FindPrerequisites(ctx, collegeID, courseID)
+}
+
+func (c *courseService) findFaculty(ctx context.Context, userID int) (*models.User, error) {
+	user, err := c.userRepo.GetUserByID(ctx, userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, ErrNotFaculty
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.Role != facultyRole || !user.IsActive {
+		return nil, ErrNotFaculty
+	}
+	return user, nil
+}
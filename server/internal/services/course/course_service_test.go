@@ -0,0 +1,206 @@
+package course
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+	"eduhub/server/internal/services/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCourseRepo struct {
+	repository.CourseRepository
+	courses map[int]*models.Course
+	nextID  int
+}
+
+func (f *fakeCourseRepo) CreateCourse(ctx context.Context, course *models.Course) error {
+	f.nextID++
+	course.ID = f.nextID
+	copied := *course
+	f.courses[course.ID] = &copied
+	return nil
+}
+
+func (f *fakeCourseRepo) FindCourseByID(ctx context.Context, collegeID int, courseID int) (*models.Course, error) {
+	course, ok := f.courses[courseID]
+	if !ok || course.CollegeID != collegeID {
+		return nil, fmt.Errorf("FindCourseByID: %w", repository.ErrCourseNotFound)
+	}
+	copied := *course
+	return &copied, nil
+}
+
+func (f *fakeCourseRepo) UpdateCourse(ctx context.Context, course *models.Course) error {
+	copied := *course
+	f.courses[course.ID] = &copied
+	return nil
+}
+
+func (f *fakeCourseRepo) DeleteCourse(ctx context.Context, collegeID int, courseID int) error {
+	delete(f.courses, courseID)
+	return nil
+}
+
+type fakeEnrollmentRepo struct {
+	repository.EnrollmentRepository
+	promoted []int
+}
+
+func (f *fakeEnrollmentRepo) PromoteWaitlist(ctx context.Context, collegeID int, courseID int) ([]int, error) {
+	f.promoted = append(f.promoted, courseID)
+	return nil, nil
+}
+
+type fakeUserRepo struct {
+	repository.UserRepository
+	users map[int]*models.User
+}
+
+func (f *fakeUserRepo) GetUserByID(ctx context.Context, userID int) (*models.User, error) {
+	user, ok := f.users[userID]
+	if !ok {
+		return nil, fmt.Errorf("GetUserByID: %w", repository.ErrUserNotFound)
+	}
+	return user, nil
+}
+
+// fakeKeto records relation tuples as "object#relation@subject".
+type fakeKeto struct {
+	auth.KetoService
+	relations map[string]bool
+	fail      bool
+}
+
+func (f *fakeKeto) CreateRelation(ctx context.Context, namespace, object, relation, subject string) error {
+	if f.fail {
+		return errors.New("keto unavailable")
+	}
+	f.relations[object+"#"+relation+"@"+subject] = true
+	return nil
+}
+
+func (f *fakeKeto) DeleteRelation(ctx context.Context, namespace, object, relation, subject string) error {
+	delete(f.relations, object+"#"+relation+"@"+subject)
+	return nil
+}
+
+func newTestCourseService() (CourseService, *fakeCourseRepo, *fakeKeto) {
+	courseRepo := &fakeCourseRepo{courses: map[int]*models.Course{}}
+	userRepo := &fakeUserRepo{users: map[int]*models.User{
+		1: {ID: 1, Role: "faculty", KratosIdentityID: "faculty-one", IsActive: true},
+		2: {ID: 2, Role: "faculty", KratosIdentityID: "faculty-two", IsActive: true},
+		3: {ID: 3, Role: "student", KratosIdentityID: "student-three", IsActive: true},
+	}}
+	keto := &fakeKeto{relations: map[string]bool{}}
+	return NewCourseService(courseRepo, userRepo, &fakeEnrollmentRepo{}, nil, auth.NewAssigner(keto)), courseRepo, keto
+}
+
+func newTestCourse() *models.Course {
+	return &models.Course{CollegeID: 10, Name: "Compilers", Credits: 4, InstructorID: 1}
+}
+
+func TestCreateCourseGrantsInstructorRelations(t *testing.T) {
+	svc, _, keto := newTestCourseService()
+
+	course := newTestCourse()
+	require.NoError(t, svc.CreateCourse(context.Background(), course))
+
+	assert.True(t, keto.relations["1#manage_qr@faculty-one"])
+	assert.True(t, keto.relations["1#view_attendance@faculty-one"])
+}
+
+func TestCreateCourseRemovesCourseWhenKetoFails(t *testing.T) {
+	svc, courseRepo, keto := newTestCourseService()
+	keto.fail = true
+
+	require.Error(t, svc.CreateCourse(context.Background(), newTestCourse()))
+	assert.Empty(t, courseRepo.courses)
+}
+
+func TestAssignInstructorMovesRelations(t *testing.T) {
+	svc, courseRepo, keto := newTestCourseService()
+	course := newTestCourse()
+	require.NoError(t, svc.CreateCourse(context.Background(), course))
+
+	updated, err := svc.AssignInstructor(context.Background(), 10, course.ID, 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, updated.InstructorID)
+	assert.Equal(t, 2, courseRepo.courses[course.ID].InstructorID)
+	assert.True(t, keto.relations["1#manage_qr@faculty-two"])
+	assert.False(t, keto.relations["1#manage_qr@faculty-one"])
+}
+
+func TestAssignInstructorRequiresFaculty(t *testing.T) {
+	svc, courseRepo, _ := newTestCourseService()
+	course := newTestCourse()
+	require.NoError(t, svc.CreateCourse(context.Background(), course))
+
+	_, err := svc.AssignInstructor(context.Background(), 10, course.ID, 3)
+	assert.ErrorIs(t, err, ErrNotFaculty)
+	_, err = svc.AssignInstructor(context.Background(), 10, course.ID, 99)
+	assert.ErrorIs(t, err, ErrNotFaculty)
+	assert.Equal(t, 1, courseRepo.courses[course.ID].InstructorID)
+}
+
+func TestUpdateCourseRaisingCapacityPromotesWaitlist(t *testing.T) {
+	courseRepo := &fakeCourseRepo{courses: map[int]*models.Course{}}
+	userRepo := &fakeUserRepo{users: map[int]*models.User{
+		1: {ID: 1, Role: "faculty", KratosIdentityID: "faculty-one", IsActive: true},
+	}}
+	enrollmentRepo := &fakeEnrollmentRepo{}
+	svc := NewCourseService(courseRepo, userRepo, enrollmentRepo, nil, auth.NewAssigner(&fakeKeto{relations: map[string]bool{}}))
+
+	seats := 30
+	course := newTestCourse()
+	course.Capacity = &seats
+	require.NoError(t, svc.CreateCourse(context.Background(), course))
+
+	fewer := 20
+	course.Capacity = &fewer
+	require.NoError(t, svc.UpdateCourse(context.Background(), course.ID, course))
+	assert.Empty(t, enrollmentRepo.promoted)
+
+	more := 40
+	course.Capacity = &more
+	require.NoError(t, svc.UpdateCourse(context.Background(), course.ID, course))
+	assert.Equal(t, []int{course.ID}, enrollmentRepo.promoted)
+}
+
+func TestUpdateCourseCannotChangeInstructor(t *testing.T) {
+	svc, courseRepo, keto := newTestCourseService()
+	course := newTestCourse()
+	require.NoError(t, svc.CreateCourse(context.Background(), course))
+
+	changed := *course
+	changed.InstructorID = 2
+	err := svc.UpdateCourse(context.Background(), course.ID, &changed)
+	assert.ErrorIs(t, err, ErrInstructorChange)
+	assert.Equal(t, 1, courseRepo.courses[course.ID].InstructorID)
+	assert.False(t, keto.relations["1#manage_qr@faculty-two"])
+
+	// Leaving the instructor out keeps the current one
+	renamed := *course
+	renamed.Name, renamed.InstructorID = "Compiler Design", 0
+	require.NoError(t, svc.UpdateCourse(context.Background(), course.ID, &renamed))
+	assert.Equal(t, "Compiler Design", courseRepo.courses[course.ID].Name)
+	assert.Equal(t, 1, courseRepo.courses[course.ID].InstructorID)
+}
+
+func TestDeleteCourseRevokesInstructorRelations(t *testing.T) {
+	svc, courseRepo, keto := newTestCourseService()
+	course := newTestCourse()
+	require.NoError(t, svc.CreateCourse(context.Background(), course))
+
+	require.NoError(t, svc.DeleteCourse(context.Background(), 10, course.ID))
+	assert.Empty(t, courseRepo.courses)
+	assert.False(t, keto.relations["1#manage_qr@faculty-one"])
+	assert.False(t, keto.relations["1#view_attendance@faculty-one"])
+}
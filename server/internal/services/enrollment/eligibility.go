@@ -0,0 +1,97 @@
+package enrollment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+)
+
+// IneligibleError lists the prerequisites a student has not met.
+type IneligibleError struct {
+	StudentID int                        `json:"student_id"`
+	Unmet     []*models.UnmetRequirement `json:"unmet"`
+}
+
+func (e *IneligibleError) Error() string {
+	return fmt.Sprintf("student %d has not met %d prerequisite(s) for this course", e.StudentID, len(e.Unmet))
+}
+
+// unmetByStudent returns the unmet prerequisites of every student in
+// studentIDs who has any.
+func (e *enrollmentService) unmetByStudent(ctx context.Context, collegeID, courseID int, studentIDs []int) (map[int][]*models.UnmetRequirement, error) {
+	unmet := make(map[int][]*models.UnmetRequirement)
+	if len(studentIDs) == 0 {
+		return unmet, nil
+	}
+	prerequisites, err := e.prerequisiteRepo.FindPrerequisites(ctx, collegeID, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if len(prerequisites) == 0 {
+		return unmet, nil
+	}
+
+	courseIDs := make([]int, len(prerequisites))
+	for i, p := range prerequisites {
+		courseIDs[i] = p.PrerequisiteID
+	}
+	best, err := e.gradeRepo.GetBestFinalPercentages(ctx, collegeID, studentIDs, courseIDs)
+	if err != nil {
+		return nil, err
+	}
+	passMark, err := e.passMark(ctx, collegeID)
+	if err != nil {
+		return nil, err
+	}
+	for _, studentID := range studentIDs {
+		if missing := unmetRequirements(prerequisites, best[studentID], passMark); len(missing) > 0 {
+			unmet[studentID] = missing
+		}
+	}
+	return unmet, nil
+}
+
+// passMark is the pass mark of the college's default grading scale, or of
+// the built-in one if it has none.
+func (e *enrollmentService) passMark(ctx context.Context, collegeID int) (float64, error) {
+	scale, err := e.gradingScaleRepo.GetDefaultGradingScale(ctx, collegeID)
+	if errors.Is(err, repository.ErrGradingScaleNotFound) {
+		return models.DefaultGradingScale.PassMark(), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return scale.PassMark(), nil
+}
+
+// unmetRequirements checks a student's best final-exam percentage per course
+// against each prerequisite's minimum, or passMark if it has none.
+func unmetRequirements(prerequisites []*models.CoursePrerequisite, best map[int]float64, passMark float64) []*models.UnmetRequirement {
+	var unmet []*models.UnmetRequirement
+	for _, p := range prerequisites {
+		minimum := passMark
+		if p.MinPercentage != nil {
+			minimum = *p.MinPercentage
+		}
+		requirement := &models.UnmetRequirement{
+			PrerequisiteID:   p.PrerequisiteID,
+			PrerequisiteName: p.PrerequisiteName,
+			MinPercentage:    minimum,
+		}
+		percentage, taken := best[p.PrerequisiteID]
+		switch {
+		case !taken:
+			requirement.Reason = models.UnmetNotTaken
+		case percentage < minimum:
+			requirement.Reason = models.UnmetBelowMinimum
+			requirement.BestPercentage = &percentage
+		default:
+			continue
+		}
+		unmet = append(unmet, requirement)
+	}
+	return unmet
+}
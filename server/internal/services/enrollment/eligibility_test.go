@@ -0,0 +1,220 @@
+package enrollment
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var dataStructuresII = []*models.CoursePrerequisite{
+	{CourseID: 20, PrerequisiteID: 10, PrerequisiteName: "Data Structures I", MinPercentage: floatPtr(50)},
+	{CourseID: 20, PrerequisiteID: 11, PrerequisiteName: "Discrete Maths"},
+}
+
+func TestUnmetRequirements(t *testing.T) {
+	unmet := unmetRequirements(dataStructuresII, map[int]float64{10: 42.5}, 40)
+	require.Len(t, unmet, 2)
+
+	assert.Equal(t, 10, unmet[0].PrerequisiteID)
+	assert.Equal(t, models.UnmetBelowMinimum, unmet[0].Reason)
+	require.NotNil(t, unmet[0].BestPercentage)
+	assert.Equal(t, 42.5, *unmet[0].BestPercentage)
+
+	assert.Equal(t, 11, unmet[1].PrerequisiteID)
+	assert.Equal(t, models.UnmetNotTaken, unmet[1].Reason)
+	assert.Nil(t, unmet[1].BestPercentage)
+
+	assert.Empty(t, unmetRequirements(dataStructuresII, map[int]float64{10: 50, 11: 40}, 40))
+
+	// Without a minimum of its own a prerequisite needs the pass mark
+	unmet = unmetRequirements(dataStructuresII, map[int]float64{10: 50, 11: 39}, 40)
+	require.Len(t, unmet, 1)
+	assert.Equal(t, models.UnmetBelowMinimum, unmet[0].Reason)
+	assert.Equal(t, 40.0, unmet[0].MinPercentage)
+}
+
+func TestPassMark(t *testing.T) {
+	assert.Equal(t, 40.0, models.DefaultGradingScale.PassMark())
+
+	relative := models.GradingScale{Method: models.RelativeGrading, PassPercentage: 35, Bands: models.DefaultGradingScale.Bands}
+	assert.Equal(t, 35.0, relative.PassMark())
+}
+
+func floatPtr(f float64) *float64 { return &f }
+
+type fakePrerequisiteRepo struct {
+	repository.CoursePrerequisiteRepository
+}
+
+func (f *fakePrerequisiteRepo) FindPrerequisites(ctx context.Context, collegeID int, courseID int) ([]*models.CoursePrerequisite, error) {
+	return dataStructuresII, nil
+}
+
+type fakeGradingScaleRepo struct {
+	repository.GradingScaleRepository
+}
+
+func (f *fakeGradingScaleRepo) GetDefaultGradingScale(ctx context.Context, collegeID int) (*models.GradingScale, error) {
+	return nil, repository.ErrGradingScaleNotFound
+}
+
+type fakeTermRepo struct {
+	repository.AcademicTermRepository
+	current *models.AcademicTerm
+}
+
+func (f *fakeTermRepo) GetCurrentTerm(ctx context.Context, collegeID int) (*models.AcademicTerm, error) {
+	if f.current == nil {
+		return nil, repository.ErrNoCurrentTerm
+	}
+	return f.current, nil
+}
+
+func (f *fakeTermRepo) GetTermByID(ctx context.Context, collegeID int, termID int) (*models.AcademicTerm, error) {
+	if f.current == nil || f.current.ID != termID {
+		return nil, repository.ErrTermNotFound
+	}
+	return f.current, nil
+}
+
+type fakeGradeRepo struct {
+	repository.GradeRepository
+	best map[int]map[int]float64
+}
+
+func (f *fakeGradeRepo) GetBestFinalPercentages(ctx context.Context, collegeID int, studentIDs []int, courseIDs []int) (map[int]map[int]float64, error) {
+	return f.best, nil
+}
+
+type fakeStudentRepo struct {
+	repository.StudentRepository
+}
+
+func (f *fakeStudentRepo) FindStudentIDsByRollNos(ctx context.Context, collegeID int, rollNos []string) (map[string]int, error) {
+	return map[string]int{"CS-002": 2}, nil
+}
+
+type fakeEnrollmentRepo struct {
+	repository.EnrollmentRepository
+	created    []*models.Enrollment
+	overrides  []*models.EnrollmentOverride
+	enrolled   []int
+	ineligible map[int][]*models.UnmetRequirement
+}
+
+func (f *fakeEnrollmentRepo) CreateEnrollment(ctx context.Context, enrollment *models.Enrollment) error {
+	f.created = append(f.created, enrollment)
+	return nil
+}
+
+func (f *fakeEnrollmentRepo) CreateEnrollmentWithOverride(ctx context.Context, enrollment *models.Enrollment, override *models.EnrollmentOverride) error {
+	f.created = append(f.created, enrollment)
+	f.overrides = append(f.overrides, override)
+	return nil
+}
+
+func (f *fakeEnrollmentRepo) EnrollStudents(ctx context.Context, collegeID int, courseID int, studentIDs []int, ineligible map[int][]*models.UnmetRequirement, override *models.EnrollmentOverride) ([]*models.BulkEnrollmentResult, error) {
+	f.enrolled, f.ineligible = studentIDs, ineligible
+	results := make([]*models.BulkEnrollmentResult, len(studentIDs))
+	for i, id := range studentIDs {
+		results[i] = &models.BulkEnrollmentResult{StudentID: id, Result: models.EnrollResultEnrolled}
+	}
+	return results, nil
+}
+
+func newTestEnrollmentService() (EnrollmentService, *fakeEnrollmentRepo) {
+	enrollmentRepo := &fakeEnrollmentRepo{}
+	grades := &fakeGradeRepo{best: map[int]map[int]float64{
+		1: {10: 81, 11: 64}, // eligible
+		2: {10: 35},         // below minimum, missing Discrete Maths
+	}}
+	return NewEnrollmentService(enrollmentRepo, &fakeStudentRepo{}, grades, &fakePrerequisiteRepo{}, &fakeTermRepo{}, &fakeGradingScaleRepo{}), enrollmentRepo
+}
+
+func TestCreateEnrollmentRejectsIneligibleStudent(t *testing.T) {
+	svc, enrollmentRepo := newTestEnrollmentService()
+
+	err := svc.CreateEnrollment(context.Background(), &models.Enrollment{CollegeID: 1, CourseID: 20, StudentID: 2}, nil)
+
+	var ineligible *IneligibleError
+	require.ErrorAs(t, err, &ineligible)
+	assert.Equal(t, 2, ineligible.StudentID)
+	assert.Len(t, ineligible.Unmet, 2)
+	assert.Empty(t, enrollmentRepo.created)
+}
+
+func TestCreateEnrollmentRecordsOverride(t *testing.T) {
+	svc, enrollmentRepo := newTestEnrollmentService()
+
+	err := svc.CreateEnrollment(context.Background(), &models.Enrollment{CollegeID: 1, CourseID: 20, StudentID: 2}, &models.EnrollmentOverride{})
+	require.Error(t, err, "an override without a reason is rejected")
+
+	override := &models.EnrollmentOverride{OverriddenBy: "admin-identity", Reason: "transfer credit from previous college"}
+	require.NoError(t, svc.CreateEnrollment(context.Background(), &models.Enrollment{CollegeID: 1, CourseID: 20, StudentID: 2}, override))
+	require.Len(t, enrollmentRepo.overrides, 1)
+	assert.Equal(t, 2, enrollmentRepo.overrides[0].StudentID)
+	assert.Len(t, enrollmentRepo.overrides[0].Unmet, 2)
+}
+
+func TestCreateEnrollmentAllowsEligibleStudent(t *testing.T) {
+	svc, enrollmentRepo := newTestEnrollmentService()
+
+	require.NoError(t, svc.CreateEnrollment(context.Background(), &models.Enrollment{CollegeID: 1, CourseID: 20, StudentID: 1}, nil))
+	assert.Len(t, enrollmentRepo.created, 1)
+	assert.Empty(t, enrollmentRepo.overrides)
+}
+
+func TestEnrollStudentsResolvesRollNumbers(t *testing.T) {
+	svc, enrollmentRepo := newTestEnrollmentService()
+
+	results, err := svc.EnrollStudents(context.Background(), 1, 20, []int{1}, []string{"CS-002", "CS-404"}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{1, 2}, enrollmentRepo.enrolled)
+	assert.Contains(t, enrollmentRepo.ineligible, 2)
+	assert.NotContains(t, enrollmentRepo.ineligible, 1)
+	require.Len(t, results, 3)
+	assert.Equal(t, "CS-404", results[2].RollNo)
+	assert.Equal(t, models.EnrollResultNotFound, results[2].Result)
+}
+
+func TestEnrollmentRespectsRegistrationWindow(t *testing.T) {
+	enrollmentRepo := &fakeEnrollmentRepo{}
+	opens, closes := date("2026-07-01"), date("2026-07-31")
+	terms := &fakeTermRepo{current: &models.AcademicTerm{ID: 3, Name: "Fall 2026", RegistrationOpensOn: &opens, RegistrationClosesOn: &closes}}
+	svc := NewEnrollmentService(enrollmentRepo, &fakeStudentRepo{}, &fakeGradeRepo{best: map[int]map[int]float64{1: {10: 81, 11: 64}}},
+		&fakePrerequisiteRepo{}, terms, &fakeGradingScaleRepo{}).(*enrollmentService)
+	ctx := context.Background()
+
+	svc.now = func() time.Time { return time.Date(2026, 8, 1, 9, 0, 0, 0, time.Local) }
+	err := svc.CreateEnrollment(ctx, &models.Enrollment{CollegeID: 1, CourseID: 20, StudentID: 1}, nil)
+	assert.ErrorIs(t, err, ErrRegistrationClosed)
+	_, err = svc.EnrollStudents(ctx, 1, 20, []int{1}, nil, nil)
+	assert.ErrorIs(t, err, ErrRegistrationClosed)
+	svc.now = func() time.Time { return time.Date(2026, 6, 30, 23, 0, 0, 0, time.Local) }
+	err = svc.CreateEnrollment(ctx, &models.Enrollment{CollegeID: 1, CourseID: 20, StudentID: 1, TermID: intPtr(3)}, nil)
+	assert.ErrorIs(t, err, ErrRegistrationClosed)
+	assert.Empty(t, enrollmentRepo.created)
+	assert.Empty(t, enrollmentRepo.enrolled)
+
+	// The window includes its last day
+	svc.now = func() time.Time { return time.Date(2026, 7, 31, 23, 0, 0, 0, time.Local) }
+	require.NoError(t, svc.CreateEnrollment(ctx, &models.Enrollment{CollegeID: 1, CourseID: 20, StudentID: 1}, nil))
+	_, err = svc.EnrollStudents(ctx, 1, 20, []int{1}, nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, enrollmentRepo.created, 1)
+	assert.Equal(t, []int{1}, enrollmentRepo.enrolled)
+}
+
+func date(s string) time.Time {
+	d, _ := time.Parse(time.DateOnly, s)
+	return d
+}
+
+func intPtr(i int) *int { return &i }
@@ -2,7 +2,9 @@ package enrollment
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"eduhub/server/internal/models"
 	"eduhub/server/internal/repository"
@@ -10,33 +12,99 @@ import (
 	"github.com/go-playground/validator/v10"
 )
 
+// MaxBulkEnrollment caps how many students one enroll request may name.
+const MaxBulkEnrollment = 500
+
+var (
+	ErrNoStudents         = errors.New("no student IDs or roll numbers given")
+	ErrTooManyStudents    = fmt.Errorf("at most %d students can be enrolled at once", MaxBulkEnrollment)
+	ErrInvalidStatus      = errors.New("invalid enrollment status filter")
+	ErrRegistrationClosed = errors.New("registration for the term is closed")
+)
+
 type EnrollmentService interface {
-	CreateEnrollment(ctx context.Context, enrollment *models.Enrollment) error
+	// CreateEnrollment returns an *IneligibleError if the student has unmet
+	// prerequisites, unless an admin override is given; overrides are recorded.
+	// Outside the term's registration window it fails with
+	// ErrRegistrationClosed.
+	CreateEnrollment(ctx context.Context, enrollment *models.Enrollment, override *models.EnrollmentOverride) error
+	CheckEligibility(ctx context.Context, collegeID, courseID, studentID int) ([]*models.UnmetRequirement, error)
 	IsStudentEnrolled(ctx context.Context, collegeID, studentID, courseID int) (bool, error)
 	UpdateEnrollment(ctx context.Context, enrollment *models.Enrollment) error
 	UpdateEnrollmentStatus(ctx context.Context, collegeID, enrollmentID int, Newstatus string) error
 	DeleteEnrollment(ctx context.Context, collegeID int, enrollmentID int) error
 	FindEnrollmentsByStudent(ctx context.Context, collegeID int, studentID int, limit, offset uint64) ([]*models.Enrollment, error)
 	GetEnrollmentByID(ctx context.Context, collegeID int, enrollmentID int) (*models.Enrollment, error)
+
+	// EnrollStudents enrolls students by ID or roll number in the current
+	// term, waitlisting them once the course is full, and reports the outcome
+	// for each. Students with unmet prerequisites are skipped unless override
+	// is given. Outside the term's registration window it fails with
+	// ErrRegistrationClosed.
+	EnrollStudents(ctx context.Context, collegeID, courseID int, studentIDs []int, rollNos []string, override *models.EnrollmentOverride) ([]*models.BulkEnrollmentResult, error)
+	// DropStudent drops the student from the course or its waitlist and
+	// returns the waitlisted students promoted into the freed seat.
+	DropStudent(ctx context.Context, collegeID, courseID, studentID int) ([]int, error)
+	// GetCourseRoster lists the course's enrollments in termID, or in the
+	// college's current term if termID is nil.
+	GetCourseRoster(ctx context.Context, collegeID, courseID int, termID *int, status string, limit, offset uint64) ([]*models.Enrollment, error)
+	GetEnrollmentOverrides(ctx context.Context, collegeID, courseID int, limit, offset uint64) ([]*models.EnrollmentOverride, error)
 }
 
 type enrollmentService struct {
-	enrollmentRepo repository.EnrollmentRepository
-	validate       *validator.Validate
+	enrollmentRepo   repository.EnrollmentRepository
+	studentRepo      repository.StudentRepository
+	gradeRepo        repository.GradeRepository
+	prerequisiteRepo repository.CoursePrerequisiteRepository
+	termRepo         repository.AcademicTermRepository
+	gradingScaleRepo repository.GradingScaleRepository
+	validate         *validator.Validate
+	now              func() time.Time
 }
 
-func NewEnrollmentService(enrollmentRepo repository.EnrollmentRepository) EnrollmentService {
+func NewEnrollmentService(enrollmentRepo repository.EnrollmentRepository, studentRepo repository.StudentRepository, gradeRepo repository.GradeRepository, prerequisiteRepo repository.CoursePrerequisiteRepository, termRepo repository.AcademicTermRepository, gradingScaleRepo repository.GradingScaleRepository) EnrollmentService {
 	return &enrollmentService{
-		enrollmentRepo: enrollmentRepo,
-		validate:       validator.New(),
+		enrollmentRepo:   enrollmentRepo,
+		studentRepo:      studentRepo,
+		gradeRepo:        gradeRepo,
+		prerequisiteRepo: prerequisiteRepo,
+		termRepo:         termRepo,
+		gradingScaleRepo: gradingScaleRepo,
+		validate:         validator.New(),
+		now:              time.Now,
 	}
 }
 
-func (e *enrollmentService) CreateEnrollment(ctx context.Context, enrollment *models.Enrollment) error {
+func (e *enrollmentService) CreateEnrollment(ctx context.Context, enrollment *models.Enrollment, override *models.EnrollmentOverride) error {
 	if err := e.validate.Struct(enrollment); err != nil {
 		return fmt.Errorf("struct validaton failed %w", err)
 	}
-	return e.enrollmentRepo.CreateEnrollment(ctx, enrollment)
+	if err := e.checkRegistration(ctx, enrollment.CollegeID, enrollment.TermID); err != nil {
+		return err
+	}
+	unmet, err := e.CheckEligibility(ctx, enrollment.CollegeID, enrollment.CourseID, enrollment.StudentID)
+	if err != nil {
+		return err
+	}
+	if len(unmet) == 0 {
+		return e.enrollmentRepo.CreateEnrollment(ctx, enrollment)
+	}
+	if override == nil {
+		return &IneligibleError{StudentID: enrollment.StudentID, Unmet: unmet}
+	}
+	if err := e.validate.Struct(override); err != nil {
+		return fmt.Errorf("override validation failed %w", err)
+	}
+	override.CollegeID, override.CourseID, override.StudentID, override.Unmet = enrollment.CollegeID, enrollment.CourseID, enrollment.StudentID, unmet
+	return e.enrollmentRepo.CreateEnrollmentWithOverride(ctx, enrollment, override)
+}
+
+func (e *enrollmentService) CheckEligibility(ctx context.Context, collegeID, courseID, studentID int) ([]*models.UnmetRequirement, error) {
+	unmet, err := e.unmetByStudent(ctx, collegeID, courseID, []int{studentID})
+	if err != nil {
+		return nil, err
+	}
+	return unmet[studentID], nil
 }
 
 func (e *enrollmentService) IsStudentEnrolled(ctx context.Context, collegeID, studentID, courseID int) (bool, error) {
@@ -66,6 +134,97 @@ func (e *enrollmentService) FindEnrollmentsByStudent(ctx context.Context, colleg
 	return e.enrollmentRepo.FindEnrollmentsByStudent(ctx, collegeID, studentID, limit, offset)
 }
 
+func (e *enrollmentService) EnrollStudents(ctx context.Context, collegeID, courseID int, studentIDs []int, rollNos []string, override *models.EnrollmentOverride) ([]*models.BulkEnrollmentResult, error) {
+	switch n := len(studentIDs) + len(rollNos); {
+	case n == 0:
+		return nil, ErrNoStudents
+	case n > MaxBulkEnrollment:
+		return nil, ErrTooManyStudents
+	}
+	if override != nil {
+		if err := e.validate.Struct(override); err != nil {
+			return nil, fmt.Errorf("override validation failed %w", err)
+		}
+	}
+
+	if err := e.checkRegistration(ctx, collegeID, nil); err != nil {
+		return nil, err
+	}
+
+	idByRollNo, err := e.studentRepo.FindStudentIDsByRollNos(ctx, collegeID, rollNos)
+	if err != nil {
+		return nil, err
+	}
+	ids := append([]int{}, studentIDs...)
+	var unknown []string
+	for _, rollNo := range rollNos {
+		if id, ok := idByRollNo[rollNo]; ok {
+			ids = append(ids, id)
+		} else {
+			unknown = append(unknown, rollNo)
+		}
+	}
+
+	ineligible, err := e.unmetByStudent(ctx, collegeID, courseID, ids)
+	if err != nil {
+		return nil, err
+	}
+	results, err := e.enrollmentRepo.EnrollStudents(ctx, collegeID, courseID, ids, ineligible, override)
+	if err != nil {
+		return nil, err
+	}
+	for _, rollNo := range unknown {
+		results = append(results, &models.BulkEnrollmentResult{RollNo: rollNo, Result: models.EnrollResultNotFound})
+	}
+	return results, nil
+}
+
+// checkRegistration fails with ErrRegistrationClosed unless today is in the
+// registration window of termID, or of the current term if it is nil. A
+// college without a current term takes enrollments at any time.
+func (e *enrollmentService) checkRegistration(ctx context.Context, collegeID int, termID *int) error {
+	var term *models.AcademicTerm
+	var err error
+	if termID != nil {
+		term, err = e.termRepo.GetTermByID(ctx, collegeID, *termID)
+	} else {
+		term, err = e.termRepo.GetCurrentTerm(ctx, collegeID)
+		if errors.Is(err, repository.ErrNoCurrentTerm) {
+			return nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if !term.RegistrationOpenOn(e.now()) {
+		return fmt.Errorf("%w: %s", ErrRegistrationClosed, term.Name)
+	}
+	return nil
+}
+
+func (e *enrollmentService) DropStudent(ctx context.Context, collegeID, courseID, studentID int) ([]int, error) {
+	return e.enrollmentRepo.DropEnrollment(ctx, collegeID, courseID, studentID)
+}
+
+func (e *enrollmentService) GetCourseRoster(ctx context.Context, collegeID, courseID int, termID *int, status string, limit, offset uint64) ([]*models.Enrollment, error) {
+	switch status {
+	case "", models.Active, models.Inactive, models.Completed, models.Frozen, models.Waitlisted, models.FrozenWaitlisted, models.Dropped:
+	default:
+		return nil, ErrInvalidStatus
+	}
+	if termID == nil {
+		var err error
+		if termID, err = e.termRepo.CurrentTermID(ctx, collegeID); err != nil {
+			return nil, err
+		}
+	}
+	return e.enrollmentRepo.FindCourseRoster(ctx, collegeID, courseID, termID, status, limit, offset)
+}
+
+func (e *enrollmentService) GetEnrollmentOverrides(ctx context.Context, collegeID, courseID int, limit, offset uint64) ([]*models.EnrollmentOverride, error) {
+	return e.prerequisiteRepo.FindEnrollmentOverrides(ctx, collegeID, courseID, limit, offset)
+}
+
 func (e *enrollmentService) GetEnrollmentByID(ctx context.Context, collegeID, enrollmentID int) (*models.Enrollment, error) {
 	enrollments, err := e.enrollmentRepo.GetEnrollmentByID(ctx, collegeID, enrollmentID)
 	if err != nil {
@@ -0,0 +1,270 @@
+package grades
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+)
+
+var ErrMaxMarksBelowScores = errors.New("max_marks is below marks already given in the assessment")
+
+// ScoreSubmissionError lists the scores refused in a submission; none of the
+// submission is saved.
+type ScoreSubmissionError struct {
+	Rejected []*models.RejectedScore `json:"rejected"`
+}
+
+func (e *ScoreSubmissionError) Error() string {
+	return fmt.Sprintf("%d score(s) rejected", len(e.Rejected))
+}
+
+func (g *gradeServices) CreateAssessment(ctx context.Context, assessment *models.Assessment) error {
+	if err := g.validate.Struct(assessment); err != nil {
+		return fmt.Errorf("validation failed %w", err)
+	}
+	if _, err := g.courseRepo.FindCourseByID(ctx, assessment.CollegeID, assessment.CourseID); err != nil {
+		return err
+	}
+	return g.assessmentRepo.CreateAssessment(ctx, assessment)
+}
+
+func (g *gradeServices) GetAssessments(ctx context.Context, collegeID int, courseID int, termID *int) ([]*models.Assessment, error) {
+	termID, err := g.termOrCurrent(ctx, collegeID, termID)
+	if err != nil {
+		return nil, err
+	}
+	return g.assessmentRepo.FindAssessments(ctx, collegeID, courseID, termID)
+}
+
+func (g *gradeServices) UpdateAssessment(ctx context.Context, assessment *models.Assessment) error {
+	existing, err := g.courseAssessment(ctx, assessment.CollegeID, assessment.CourseID, assessment.ID)
+	if err != nil {
+		return err
+	}
+	assessment.TermID, assessment.ExamType = existing.TermID, existing.ExamType
+	if err := g.validate.Struct(assessment); err != nil {
+		return fmt.Errorf("validation failed %w", err)
+	}
+
+	if assessment.MaxMarks < existing.MaxMarks {
+		scores, err := g.assessmentRepo.FindScores(ctx, assessment.CollegeID, []int{assessment.ID}, nil)
+		if err != nil {
+			return err
+		}
+		for _, s := range scores {
+			if s.MarksObtained > assessment.MaxMarks {
+				return ErrMaxMarksBelowScores
+			}
+		}
+	}
+	return g.assessmentRepo.UpdateAssessment(ctx, assessment)
+}
+
+func (g *gradeServices) DeleteAssessment(ctx context.Context, collegeID int, courseID int, assessmentID int) error {
+	if _, err := g.courseAssessment(ctx, collegeID, courseID, assessmentID); err != nil {
+		return err
+	}
+	return g.assessmentRepo.DeleteAssessment(ctx, collegeID, assessmentID)
+}
+
+func (g *gradeServices) SubmitScores(ctx context.Context, collegeID int, courseID int, assessmentID int, scores []models.AssessmentScore) ([]*models.Grade, error) {
+	assessment, err := g.courseAssessment(ctx, collegeID, courseID, assessmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	studentIDs := make([]int, 0, len(scores))
+	for _, s := range scores {
+		studentIDs = append(studentIDs, s.StudentID)
+	}
+	enrolled, err := g.enrollmentRepo.FindEnrolledActiveStudentIDs(ctx, collegeID, courseID, studentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var rejected []*models.RejectedScore
+	seen := make(map[int]bool, len(scores))
+	grades := make([]*models.Grade, 0, len(scores))
+	for _, s := range scores {
+		reason := ""
+		switch {
+		case g.validate.Struct(s) != nil:
+			reason = "student_id must be positive and marks not negative"
+		case seen[s.StudentID]:
+			reason = "student appears more than once"
+		case s.Marks > assessment.MaxMarks:
+			reason = fmt.Sprintf("marks exceed the maximum of %g", assessment.MaxMarks)
+		case !enrolled[s.StudentID]:
+			reason = "student is not actively enrolled in the course"
+		}
+		seen[s.StudentID] = true
+		if reason != "" {
+			rejected = append(rejected, &models.RejectedScore{StudentID: s.StudentID, Reason: reason})
+			continue
+		}
+
+		grades = append(grades, &models.Grade{
+			StudentID:     s.StudentID,
+			CourseID:      courseID,
+			CollegeID:     collegeID,
+			TermID:        assessment.TermID,
+			AssessmentID:  &assessment.ID,
+			MarksObtained: s.Marks,
+			TotalMarks:    assessment.MaxMarks,
+			ExamType:      assessment.ExamType,
+			Comments:      s.Comments,
+		})
+	}
+	if len(rejected) > 0 {
+		return nil, &ScoreSubmissionError{Rejected: rejected}
+	}
+
+	if err := g.assessmentRepo.SaveScores(ctx, grades); err != nil {
+		return nil, err
+	}
+	return grades, nil
+}
+
+func (g *gradeServices) GetCourseGrades(ctx context.Context, collegeID int, courseID int, termID *int, limit, offset uint64) (*models.CourseGradeBook, error) {
+	termID, err := g.termOrCurrent(ctx, collegeID, termID)
+	if err != nil {
+		return nil, err
+	}
+	assessments, err := g.assessmentRepo.FindAssessments(ctx, collegeID, courseID, termID)
+	if err != nil {
+		return nil, err
+	}
+	roster, err := g.enrollmentRepo.FindCourseRoster(ctx, collegeID, courseID, termID, string(models.Active), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	studentIDs := make([]int, len(roster))
+	for i, e := range roster {
+		studentIDs[i] = e.StudentID
+	}
+	scores, err := g.findScores(ctx, collegeID, assessments, studentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	book := &models.CourseGradeBook{
+		CourseID:    courseID,
+		TermID:      termID,
+		Assessments: assessments,
+		Students:    make([]*models.CourseGrade, len(roster)),
+	}
+	for i, e := range roster {
+		book.Students[i] = weightedGrade(e.StudentID, courseID, termID, assessments, scores[e.StudentID])
+	}
+	return book, nil
+}
+
+func (g *gradeServices) GetStudentGrades(ctx context.Context, collegeID int, studentID int, publishedOnly bool, limit, offset uint64) ([]*models.CourseGrade, error) {
+	enrollments, err := g.enrollmentRepo.FindEnrollmentsByStudent(ctx, collegeID, studentID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	var published map[string]bool
+	if publishedOnly {
+		if published, err = g.publishedCourses(ctx, collegeID); err != nil {
+			return nil, err
+		}
+	}
+
+	grades := make([]*models.CourseGrade, 0, len(enrollments))
+	for _, e := range enrollments {
+		if strings.EqualFold(string(e.Status), string(models.Dropped)) {
+			continue
+		}
+		if publishedOnly && !published[courseTermKey(e.CourseID, e.TermID)] {
+			continue
+		}
+		assessments, err := g.assessmentRepo.FindAssessments(ctx, collegeID, e.CourseID, e.TermID)
+		if err != nil {
+			return nil, err
+		}
+		scores, err := g.findScores(ctx, collegeID, assessments, []int{studentID})
+		if err != nil {
+			return nil, err
+		}
+		grades = append(grades, weightedGrade(studentID, e.CourseID, e.TermID, assessments, scores[studentID]))
+	}
+	return grades, nil
+}
+
+// courseAssessment returns the assessment if it belongs to the course.
+func (g *gradeServices) courseAssessment(ctx context.Context, collegeID int, courseID int, assessmentID int) (*models.Assessment, error) {
+	assessment, err := g.assessmentRepo.GetAssessment(ctx, collegeID, assessmentID)
+	if err != nil {
+		return nil, err
+	}
+	if assessment.CourseID != courseID {
+		return nil, fmt.Errorf("assessment %d is not part of course %d: %w", assessmentID, courseID, repository.ErrAssessmentNotFound)
+	}
+	return assessment, nil
+}
+
+// findScores returns the students' marks by student and assessment.
+func (g *gradeServices) findScores(ctx context.Context, collegeID int, assessments []*models.Assessment, studentIDs []int) (map[int]map[int]float64, error) {
+	ids := make([]int, len(assessments))
+	for i, a := range assessments {
+		ids[i] = a.ID
+	}
+	grades, err := g.assessmentRepo.FindScores(ctx, collegeID, ids, studentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make(map[int]map[int]float64, len(studentIDs))
+	for _, grade := range grades {
+		if grade.AssessmentID == nil {
+			continue
+		}
+		if scores[grade.StudentID] == nil {
+			scores[grade.StudentID] = make(map[int]float64)
+		}
+		scores[grade.StudentID][*grade.AssessmentID] = grade.MarksObtained
+	}
+	return scores, nil
+}
+
+func (g *gradeServices) termOrCurrent(ctx context.Context, collegeID int, termID *int) (*int, error) {
+	if termID != nil {
+		return termID, nil
+	}
+	return g.termRepo.CurrentTermID(ctx, collegeID)
+}
+
+// weightedGrade adds up a student's marks, by assessment ID, as fractions of
+// each assessment's weight.
+func weightedGrade(studentID int, courseID int, termID *int, assessments []*models.Assessment, marks map[int]float64) *models.CourseGrade {
+	grade := &models.CourseGrade{
+		StudentID:  studentID,
+		CourseID:   courseID,
+		TermID:     termID,
+		Components: make([]*models.ComponentScore, len(assessments)),
+	}
+	for i, a := range assessments {
+		component := &models.ComponentScore{
+			AssessmentID: a.ID,
+			Name:         a.Name,
+			ExamType:     a.ExamType,
+			Weight:       a.Weight,
+			MaxMarks:     a.MaxMarks,
+		}
+		if m, ok := marks[a.ID]; ok {
+			component.Marks = &m
+			component.Weighted = m / a.MaxMarks * a.Weight
+			grade.WeightedTotal += component.Weighted
+			grade.GradedWeight += a.Weight
+		}
+		grade.TotalWeight += a.Weight
+		grade.Components[i] = component
+	}
+	return grade
+}
@@ -0,0 +1,192 @@
+package grades
+
+import (
+	"context"
+	"testing"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAssessmentRepo struct {
+	repository.AssessmentRepository
+	assessments []*models.Assessment
+	scores      []*models.Grade
+	saved       []*models.Grade
+	updated     *models.Assessment
+}
+
+func (f *fakeAssessmentRepo) GetAssessment(ctx context.Context, collegeID int, assessmentID int) (*models.Assessment, error) {
+	for _, a := range f.assessments {
+		if a.ID == assessmentID {
+			return a, nil
+		}
+	}
+	return nil, repository.ErrAssessmentNotFound
+}
+
+func (f *fakeAssessmentRepo) UpdateAssessment(ctx context.Context, assessment *models.Assessment) error {
+	f.updated = assessment
+	return nil
+}
+
+func (f *fakeAssessmentRepo) FindAssessments(ctx context.Context, collegeID int, courseID int, termID *int) ([]*models.Assessment, error) {
+	return f.assessments, nil
+}
+
+func (f *fakeAssessmentRepo) FindScores(ctx context.Context, collegeID int, assessmentIDs []int, studentIDs []int) ([]*models.Grade, error) {
+	return f.scores, nil
+}
+
+func (f *fakeAssessmentRepo) SaveScores(ctx context.Context, grades []*models.Grade) error {
+	f.saved = append(f.saved, grades...)
+	return nil
+}
+
+type fakeEnrollmentRepo struct {
+	repository.EnrollmentRepository
+	active map[int]bool
+}
+
+func (f *fakeEnrollmentRepo) FindEnrolledActiveStudentIDs(ctx context.Context, collegeID int, courseID int, studentIDs []int) (map[int]bool, error) {
+	return f.active, nil
+}
+
+func (f *fakeEnrollmentRepo) FindCourseRoster(ctx context.Context, collegeID int, courseID int, termID *int, status string, limit, offset uint64) ([]*models.Enrollment, error) {
+	var roster []*models.Enrollment
+	for id := range f.active {
+		roster = append(roster, &models.Enrollment{StudentID: id, CourseID: courseID})
+	}
+	return roster, nil
+}
+
+type fakeTermRepo struct {
+	repository.AcademicTermRepository
+}
+
+func (f *fakeTermRepo) CurrentTermID(ctx context.Context, collegeID int) (*int, error) {
+	id := 3
+	return &id, nil
+}
+
+type fakeGradeRepo struct {
+	repository.GradeRepository
+	grades []*models.Grade
+	filter models.GradeFilter
+}
+
+func (f *fakeGradeRepo) FindGradeRows(ctx context.Context, filter models.GradeFilter) ([]*models.GradeRow, error) {
+	f.filter = filter
+	return []*models.GradeRow{}, nil
+}
+
+func newTestService(assessments *fakeAssessmentRepo, enrollments *fakeEnrollmentRepo) GradeServices {
+	return NewGradeServices(nil, nil, enrollments, nil, &fakeTermRepo{}, assessments, nil, nil, nil, nil, nil)
+}
+
+func intPtr(i int) *int { return &i }
+
+func TestWeightedGrade(t *testing.T) {
+	assessments := []*models.Assessment{
+		{ID: 1, Name: "Midterm 1", ExamType: models.Midterm1, Weight: 20, MaxMarks: 50},
+		{ID: 2, Name: "Midterm 2", ExamType: models.Midterm2, Weight: 20, MaxMarks: 50},
+		{ID: 3, Name: "Final", ExamType: models.Final, Weight: 60, MaxMarks: 100},
+	}
+
+	grade := weightedGrade(9, 7, intPtr(3), assessments, map[int]float64{1: 40, 3: 75})
+	assert.InDelta(t, 16+45, grade.WeightedTotal, 1e-9)
+	assert.Equal(t, 80.0, grade.GradedWeight)
+	assert.Equal(t, 100.0, grade.TotalWeight)
+	require.Len(t, grade.Components, 3)
+	assert.Nil(t, grade.Components[1].Marks, "ungraded components have no marks")
+	assert.Equal(t, 40.0, *grade.Components[0].Marks)
+	assert.InDelta(t, 45, grade.Components[2].Weighted, 1e-9)
+}
+
+func TestSubmitScores(t *testing.T) {
+	midterm := &models.Assessment{ID: 1, CollegeID: 1, CourseID: 7, TermID: intPtr(3), Name: "Midterm 1", ExamType: models.Midterm1, Weight: 20, MaxMarks: 50}
+	repo := &fakeAssessmentRepo{assessments: []*models.Assessment{midterm}}
+	svc := newTestService(repo, &fakeEnrollmentRepo{active: map[int]bool{10: true, 11: true}})
+
+	_, err := svc.SubmitScores(context.Background(), 1, 7, 1, []models.AssessmentScore{
+		{StudentID: 10, Marks: 45},
+		{StudentID: 11, Marks: 51},
+		{StudentID: 12, Marks: 30},
+		{StudentID: 10, Marks: 40},
+		{StudentID: 11, Marks: -1},
+	})
+	var rejected *ScoreSubmissionError
+	require.ErrorAs(t, err, &rejected)
+	require.Len(t, rejected.Rejected, 4)
+	assert.Equal(t, "marks exceed the maximum of 50", rejected.Rejected[0].Reason)
+	assert.Equal(t, "student is not actively enrolled in the course", rejected.Rejected[1].Reason)
+	assert.Equal(t, "student appears more than once", rejected.Rejected[2].Reason)
+	assert.Equal(t, 11, rejected.Rejected[3].StudentID)
+	assert.Empty(t, repo.saved, "nothing is saved when a score is refused")
+
+	saved, err := svc.SubmitScores(context.Background(), 1, 7, 1, []models.AssessmentScore{{StudentID: 10, Marks: 45}, {StudentID: 11, Marks: 50}})
+	require.NoError(t, err)
+	require.Len(t, saved, 2)
+	assert.Equal(t, 10, saved[0].StudentID)
+	assert.Equal(t, 1, *saved[0].AssessmentID)
+	assert.Equal(t, 3, *saved[0].TermID)
+	assert.Equal(t, 50.0, saved[0].TotalMarks)
+	assert.Equal(t, models.Midterm1, saved[0].ExamType)
+
+	_, err = svc.SubmitScores(context.Background(), 1, 8, 1, []models.AssessmentScore{{StudentID: 10, Marks: 45}})
+	assert.ErrorIs(t, err, repository.ErrAssessmentNotFound, "the assessment belongs to another course")
+}
+
+func TestUpdateAssessmentKeepsMaxMarksAboveScores(t *testing.T) {
+	final := &models.Assessment{ID: 3, CollegeID: 1, CourseID: 7, Name: "Final", ExamType: models.Final, Weight: 60, MaxMarks: 100}
+	repo := &fakeAssessmentRepo{
+		assessments: []*models.Assessment{final},
+		scores:      []*models.Grade{{StudentID: 10, AssessmentID: intPtr(3), MarksObtained: 85}},
+	}
+	svc := newTestService(repo, &fakeEnrollmentRepo{})
+
+	err := svc.UpdateAssessment(context.Background(), &models.Assessment{ID: 3, CollegeID: 1, CourseID: 7, Name: "Final", Weight: 50, MaxMarks: 80})
+	assert.ErrorIs(t, err, ErrMaxMarksBelowScores)
+	assert.Nil(t, repo.updated)
+
+	err = svc.UpdateAssessment(context.Background(), &models.Assessment{ID: 3, CollegeID: 1, CourseID: 7, Name: "Final exam", Weight: 50, MaxMarks: 90})
+	require.NoError(t, err)
+	assert.Equal(t, models.Final, repo.updated.ExamType, "the type stays")
+}
+
+func TestGetCourseGrades(t *testing.T) {
+	repo := &fakeAssessmentRepo{
+		assessments: []*models.Assessment{{ID: 1, CourseID: 7, Weight: 40, MaxMarks: 50}, {ID: 2, CourseID: 7, Weight: 60, MaxMarks: 100}},
+		scores: []*models.Grade{
+			{StudentID: 10, AssessmentID: intPtr(1), MarksObtained: 25},
+			{StudentID: 10, AssessmentID: intPtr(2), MarksObtained: 90},
+		},
+	}
+	svc := newTestService(repo, &fakeEnrollmentRepo{active: map[int]bool{10: true}})
+
+	book, err := svc.GetCourseGrades(context.Background(), 1, 7, nil, 20, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, *book.TermID, "defaults to the current term")
+	require.Len(t, book.Students, 1)
+	assert.InDelta(t, 20+54, book.Students[0].WeightedTotal, 1e-9)
+	assert.Equal(t, 100.0, book.Students[0].GradedWeight)
+}
+
+func TestGetGradeRowsDefaultsToCurrentTerm(t *testing.T) {
+	grades := &fakeGradeRepo{}
+	svc := NewGradeServices(grades, nil, nil, nil, &fakeTermRepo{}, nil, nil, nil, nil, nil, nil)
+	collegeID, courseID := 1, 7
+
+	_, err := svc.GetGradeRows(context.Background(), models.GradeFilter{CollegeID: &collegeID, CourseID: &courseID})
+	require.NoError(t, err)
+	assert.Equal(t, 3, *grades.filter.TermID)
+	assert.Equal(t, 7, *grades.filter.CourseID)
+
+	// An assessment belongs to one term already
+	_, err = svc.GetGradeRows(context.Background(), models.GradeFilter{CollegeID: &collegeID, AssessmentID: intPtr(2)})
+	require.NoError(t, err)
+	assert.Nil(t, grades.filter.TermID)
+}
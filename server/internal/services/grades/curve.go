@@ -0,0 +1,217 @@
+package grades
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+)
+
+var (
+	ErrInvalidCurve    = errors.New("invalid curve")
+	ErrNoScoresToCurve = errors.New("no enrolled student has scores in the course's assessments")
+)
+
+func (g *gradeServices) PreviewCurve(ctx context.Context, collegeID int, courseID int, curve *models.Curve) (*models.CurvePreview, error) {
+	if err := g.checkCurve(curve); err != nil {
+		return nil, err
+	}
+	if _, err := g.courseRepo.FindCourseByID(ctx, collegeID, courseID); err != nil {
+		return nil, err
+	}
+	termID, err := g.termOrCurrent(ctx, collegeID, curve.TermID)
+	if err != nil {
+		return nil, err
+	}
+	percentages, err := g.coursePercentages(ctx, collegeID, courseID, termID)
+	if err != nil {
+		return nil, err
+	}
+	if len(percentages) == 0 {
+		return nil, ErrNoScoresToCurve
+	}
+
+	preview := applyCurve(curve, percentages)
+	preview.CourseID, preview.TermID = courseID, termID
+	return preview, nil
+}
+
+func (g *gradeServices) CommitCurve(ctx context.Context, collegeID int, courseID int, curve *models.Curve, committedBy string) (*models.GradeCurve, error) {
+	termID, err := g.termOrCurrent(ctx, collegeID, curve.TermID)
+	if err != nil {
+		return nil, err
+	}
+	// Taken before the percentages, so the commit fails if they go stale
+	version, err := g.curveRepo.ScoresVersion(ctx, courseID, termID)
+	if err != nil {
+		return nil, err
+	}
+	pinned := *curve
+	pinned.TermID = termID
+	preview, err := g.PreviewCurve(ctx, collegeID, courseID, &pinned)
+	if err != nil {
+		return nil, err
+	}
+
+	committed := &models.GradeCurve{
+		CollegeID:    collegeID,
+		CourseID:     courseID,
+		TermID:       preview.TermID,
+		Method:       curve.Method,
+		Bands:        curve.Bands,
+		Cutoffs:      preview.Cutoffs,
+		Mean:         preview.Mean,
+		StdDev:       preview.StdDev,
+		Distribution: preview.Distribution,
+		CommittedBy:  committedBy,
+	}
+	if err := g.curveRepo.CommitCurve(ctx, committed, preview.Grades, version); err != nil {
+		return nil, err
+	}
+	return committed, nil
+}
+
+func (g *gradeServices) GetCurves(ctx context.Context, collegeID int, courseID int, termID *int) ([]*models.GradeCurve, error) {
+	termID, err := g.termOrCurrent(ctx, collegeID, termID)
+	if err != nil {
+		return nil, err
+	}
+	return g.curveRepo.FindCurves(ctx, collegeID, courseID, termID)
+}
+
+func (g *gradeServices) UnlockCurve(ctx context.Context, collegeID int, courseID int, termID *int, unlockedBy string, reason string) (*models.GradeCurve, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, fmt.Errorf("%w: a reason is required to unlock grades", ErrInvalidCurve)
+	}
+	termID, err := g.termOrCurrent(ctx, collegeID, termID)
+	if err != nil {
+		return nil, err
+	}
+	return g.curveRepo.UnlockCurve(ctx, collegeID, courseID, termID, unlockedBy, reason)
+}
+
+// courseScale is the scale a course's results are graded on: the cutoffs of
+// its locked curve if it has one, otherwise the college's scale.
+func (g *gradeServices) courseScale(ctx context.Context, collegeID int, courseID int, termID *int, collegeScale *models.GradingScale) (*models.GradingScale, error) {
+	curve, err := g.curveRepo.GetLockedCurve(ctx, collegeID, courseID, termID)
+	if errors.Is(err, repository.ErrGradeCurveNotFound) {
+		return collegeScale, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &models.GradingScale{CollegeID: collegeID, Method: models.AbsoluteGrading, Bands: curve.Cutoffs}, nil
+}
+
+// checkCurve validates the curve. Its bands must be given highest first.
+func (g *gradeServices) checkCurve(curve *models.Curve) error {
+	if err := g.validate.Struct(curve); err != nil {
+		return fmt.Errorf("validation failed %w", err)
+	}
+
+	letters := make(map[string]bool, len(curve.Bands))
+	for i, b := range curve.Bands {
+		last := i == len(curve.Bands)-1
+		if curve.Method != models.CurveMeanSD && !last && (b.Cutoff < 0 || b.Cutoff > 100) {
+			return fmt.Errorf("%w: cutoff of %s must be between 0 and 100", ErrInvalidCurve, b.Letter)
+		}
+		if i > 0 && !last && b.Cutoff >= curve.Bands[i-1].Cutoff {
+			return fmt.Errorf("%w: band %s must start below band %s", ErrInvalidCurve, b.Letter, curve.Bands[i-1].Letter)
+		}
+		if i > 0 && b.GradePoint > curve.Bands[i-1].GradePoint {
+			return fmt.Errorf("%w: band %s is worth more than the band above it", ErrInvalidCurve, b.Letter)
+		}
+		if letters[strings.ToUpper(b.Letter)] {
+			return fmt.Errorf("%w: letter %s is used twice", ErrInvalidCurve, b.Letter)
+		}
+		letters[strings.ToUpper(b.Letter)] = true
+	}
+	return nil
+}
+
+// applyCurve resolves the curve's cutoffs to percentages over the class's
+// percentages and grades each student on them. Cutoffs are kept within
+// 0-100 and never above the band before; the last band starts at 0.
+func applyCurve(curve *models.Curve, percentages map[int]float64) *models.CurvePreview {
+	values := make([]float64, 0, len(percentages))
+	for _, p := range percentages {
+		values = append(values, p)
+	}
+	sort.Float64s(values)
+	mean, sd := meanStdDev(values)
+
+	cutoffs := make([]models.GradeBand, len(curve.Bands))
+	for i, b := range curve.Bands {
+		var min float64
+		switch {
+		case i == len(curve.Bands)-1:
+			min = 0
+		case curve.Method == models.CurveMeanSD:
+			min = mean + b.Cutoff*sd
+		case curve.Method == models.CurvePercentile:
+			min = percentile(values, b.Cutoff)
+		default:
+			min = b.Cutoff
+		}
+		min = round2(math.Max(0, math.Min(100, min)))
+		if i > 0 && min > cutoffs[i-1].MinScore {
+			min = cutoffs[i-1].MinScore
+		}
+		cutoffs[i] = models.GradeBand{Letter: b.Letter, MinScore: min, GradePoint: b.GradePoint}
+	}
+
+	scale := &models.GradingScale{Method: models.AbsoluteGrading, Bands: cutoffs}
+	counts := make(map[string]int, len(cutoffs))
+	preview := &models.CurvePreview{
+		Method:  curve.Method,
+		Mean:    round2(mean),
+		StdDev:  round2(sd),
+		Cutoffs: cutoffs,
+	}
+	for _, r := range gradeCourse(scale, percentages, 0) {
+		counts[r.GradeLetter]++
+		preview.Grades = append(preview.Grades, &models.CurvedGrade{
+			StudentID:   r.StudentID,
+			Percentage:  r.Percentage,
+			GradeLetter: r.GradeLetter,
+			GradePoint:  r.GradePoint,
+		})
+	}
+	for _, c := range cutoffs {
+		preview.Distribution = append(preview.Distribution, models.LetterCount{Letter: c.Letter, Count: counts[c.Letter]})
+	}
+	return preview
+}
+
+// meanStdDev returns the mean and population standard deviation.
+func meanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)))
+}
+
+// percentile interpolates the p-th percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(rank-float64(lo))
+}
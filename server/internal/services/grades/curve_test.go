@@ -0,0 +1,218 @@
+package grades
+
+import (
+	"context"
+	"testing"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCurveRepo struct {
+	repository.GradeCurveRepository
+	locked    *models.GradeCurve
+	committed []*models.CurvedGrade
+	// Scores are seen at seenVersion but are at version by the commit
+	seenVersion, version string
+}
+
+func (f *fakeCurveRepo) ScoresVersion(ctx context.Context, courseID int, termID *int) (string, error) {
+	return f.seenVersion, nil
+}
+
+func (f *fakeCurveRepo) CommitCurve(ctx context.Context, curve *models.GradeCurve, grades []*models.CurvedGrade, scoresVersion string) error {
+	if f.locked != nil {
+		return repository.ErrGradesLocked
+	}
+	if scoresVersion != f.version {
+		return repository.ErrScoresChanged
+	}
+	curve.Version, curve.IsLocked = 1, true
+	f.locked, f.committed = curve, grades
+	return nil
+}
+
+func (f *fakeCurveRepo) GetLockedCurve(ctx context.Context, collegeID int, courseID int, termID *int) (*models.GradeCurve, error) {
+	if f.locked == nil {
+		return nil, repository.ErrGradeCurveNotFound
+	}
+	return f.locked, nil
+}
+
+type fakeCourseRepo struct {
+	repository.CourseRepository
+	instructors map[int]string // Identity by course
+	heads       map[int]string // Department head's identity by course
+}
+
+func (f *fakeCourseRepo) FindCourseByID(ctx context.Context, collegeID int, courseID int) (*models.Course, error) {
+	return &models.Course{ID: courseID, CollegeID: collegeID, Credits: 3}, nil
+}
+
+func (f *fakeCourseRepo) IsCourseInstructor(ctx context.Context, collegeID int, courseID int, identityID string) (bool, error) {
+	return f.instructors[courseID] == identityID, nil
+}
+
+func (f *fakeCourseRepo) IsDepartmentHead(ctx context.Context, collegeID int, courseID int, identityID string) (bool, error) {
+	return f.heads[courseID] == identityID, nil
+}
+
+// classOfFive scores 50, 60, 70, 80 and 90: a mean of 70 and a standard
+// deviation of about 14.14.
+var classOfFive = map[int]float64{1: 50, 2: 60, 3: 70, 4: 80, 5: 90}
+
+func curveLetters(preview *models.CurvePreview) map[int]string {
+	letters := make(map[int]string, len(preview.Grades))
+	for _, g := range preview.Grades {
+		letters[g.StudentID] = g.GradeLetter
+	}
+	return letters
+}
+
+func TestApplyCurveMeanSD(t *testing.T) {
+	preview := applyCurve(&models.Curve{
+		Method: models.CurveMeanSD,
+		Bands: []models.CurveBand{
+			{Letter: "A", Cutoff: 1, GradePoint: 10},
+			{Letter: "B", Cutoff: 0, GradePoint: 8},
+			{Letter: "C", Cutoff: -1, GradePoint: 6},
+			{Letter: "F", GradePoint: 0},
+		},
+	}, classOfFive)
+
+	assert.Equal(t, 70.0, preview.Mean)
+	assert.Equal(t, 14.14, preview.StdDev)
+	assert.Equal(t, []models.GradeBand{
+		{Letter: "A", MinScore: 84.14, GradePoint: 10},
+		{Letter: "B", MinScore: 70, GradePoint: 8},
+		{Letter: "C", MinScore: 55.86, GradePoint: 6},
+		{Letter: "F", MinScore: 0, GradePoint: 0},
+	}, preview.Cutoffs)
+	assert.Equal(t, map[int]string{1: "F", 2: "C", 3: "B", 4: "B", 5: "A"}, curveLetters(preview))
+	assert.Equal(t, []models.LetterCount{{Letter: "A", Count: 1}, {Letter: "B", Count: 2}, {Letter: "C", Count: 1}, {Letter: "F", Count: 1}}, preview.Distribution)
+}
+
+func TestApplyCurvePercentile(t *testing.T) {
+	preview := applyCurve(&models.Curve{
+		Method: models.CurvePercentile,
+		Bands: []models.CurveBand{
+			{Letter: "A", Cutoff: 80, GradePoint: 10},
+			{Letter: "B", Cutoff: 40, GradePoint: 8},
+			{Letter: "F", GradePoint: 0},
+		},
+	}, classOfFive)
+
+	assert.Equal(t, 82.0, preview.Cutoffs[0].MinScore)
+	assert.Equal(t, 66.0, preview.Cutoffs[1].MinScore)
+	assert.Equal(t, map[int]string{1: "F", 2: "F", 3: "B", 4: "B", 5: "A"}, curveLetters(preview))
+}
+
+func TestApplyCurveClampsCutoffs(t *testing.T) {
+	// 2.5 and 3 deviations above a mean of 70 are both past 100
+	preview := applyCurve(&models.Curve{
+		Method: models.CurveMeanSD,
+		Bands: []models.CurveBand{
+			{Letter: "A", Cutoff: 3, GradePoint: 10},
+			{Letter: "B", Cutoff: 2.5, GradePoint: 8},
+			{Letter: "F", GradePoint: 0},
+		},
+	}, classOfFive)
+
+	assert.Equal(t, 100.0, preview.Cutoffs[0].MinScore)
+	assert.Equal(t, 100.0, preview.Cutoffs[1].MinScore)
+	assert.Equal(t, []models.LetterCount{{Letter: "A", Count: 0}, {Letter: "B", Count: 0}, {Letter: "F", Count: 5}}, preview.Distribution)
+}
+
+func TestCheckCurve(t *testing.T) {
+	g := &gradeServices{validate: *validator.New()}
+	valid := []models.CurveBand{{Letter: "A", Cutoff: 80, GradePoint: 10}, {Letter: "F", Cutoff: 99}}
+	require.NoError(t, g.checkCurve(&models.Curve{Method: models.CurvePercentile, Bands: valid}), "the last cutoff is ignored")
+
+	for name, curve := range map[string]*models.Curve{
+		"rising cutoffs": {Method: models.CurveFixed, Bands: []models.CurveBand{
+			{Letter: "A", Cutoff: 60, GradePoint: 10}, {Letter: "B", Cutoff: 70, GradePoint: 8}, {Letter: "F"},
+		}},
+		"over 100": {Method: models.CurvePercentile, Bands: []models.CurveBand{
+			{Letter: "A", Cutoff: 120, GradePoint: 10}, {Letter: "F"},
+		}},
+		"same letter": {Method: models.CurveMeanSD, Bands: []models.CurveBand{
+			{Letter: "A", Cutoff: 1, GradePoint: 10}, {Letter: "a"},
+		}},
+		"points inverse": {Method: models.CurveMeanSD, Bands: []models.CurveBand{
+			{Letter: "A", Cutoff: 1, GradePoint: 6}, {Letter: "F", GradePoint: 8},
+		}},
+	} {
+		assert.ErrorIs(t, g.checkCurve(curve), ErrInvalidCurve, name)
+	}
+	assert.Error(t, g.checkCurve(&models.Curve{Method: "bell", Bands: valid}))
+}
+
+func TestCommitCurveGradesResults(t *testing.T) {
+	assessments := &fakeAssessmentRepo{
+		assessments: []*models.Assessment{{ID: 1, CourseID: 10, TermID: intPtr(3), Weight: 100, MaxMarks: 100}},
+		scores: []*models.Grade{
+			{StudentID: 1, AssessmentID: intPtr(1), MarksObtained: 50},
+			{StudentID: 2, AssessmentID: intPtr(1), MarksObtained: 60},
+			{StudentID: 3, AssessmentID: intPtr(1), MarksObtained: 70},
+		},
+	}
+	curves := &fakeCurveRepo{}
+	results := &fakeResultRepo{
+		courses:   []*models.Course{{ID: 10, Credits: 3}},
+		results:   map[int][]*models.CourseResult{},
+		snapshots: map[int][]*models.GPASnapshot{},
+	}
+	g := NewGradeServices(nil, nil, &fakeEnrollmentRepo{active: map[int]bool{1: true, 2: true, 3: true}}, &fakeCourseRepo{},
+		&fakeTermRepo{}, assessments, &fakeGradingScaleRepo{}, results, curves, &fakePublicationRepo{}, nil)
+
+	curve := &models.Curve{
+		Method: models.CurveFixed,
+		Bands:  []models.CurveBand{{Letter: "A", Cutoff: 65, GradePoint: 10}, {Letter: "B", GradePoint: 8}},
+	}
+	// Scores changing while the curve is computed stop the commit
+	curves.seenVersion, curves.version = "v1", "v2"
+	_, err := g.CommitCurve(context.Background(), 1, 10, curve, "faculty-1")
+	assert.ErrorIs(t, err, repository.ErrScoresChanged)
+	assert.Nil(t, curves.locked)
+
+	curves.seenVersion = "v2"
+	committed, err := g.CommitCurve(context.Background(), 1, 10, curve, "faculty-1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, *committed.TermID)
+	assert.True(t, committed.IsLocked)
+	assert.Equal(t, "faculty-1", committed.CommittedBy)
+	require.Len(t, curves.committed, 3)
+	assert.Equal(t, "A", curves.committed[2].GradeLetter)
+
+	_, err = g.CommitCurve(context.Background(), 1, 10, curve, "faculty-1")
+	assert.ErrorIs(t, err, repository.ErrGradesLocked)
+
+	// Results follow the locked curve rather than the ten point scale, which
+	// would fail a 50
+	_, err = g.ComputeTermResults(context.Background(), 1, nil)
+	require.NoError(t, err)
+	letters := map[int]string{}
+	for _, r := range results.results[10] {
+		letters[r.StudentID] = r.GradeLetter
+	}
+	assert.Equal(t, map[int]string{1: "B", 2: "B", 3: "A"}, letters)
+}
+
+func TestPreviewCurveNeedsScores(t *testing.T) {
+	g := NewGradeServices(nil, nil, &fakeEnrollmentRepo{}, &fakeCourseRepo{}, &fakeTermRepo{}, &fakeAssessmentRepo{}, nil, nil, nil, nil, nil)
+	_, err := g.PreviewCurve(context.Background(), 1, 10, &models.Curve{
+		Method: models.CurveMeanSD,
+		Bands:  []models.CurveBand{{Letter: "A", Cutoff: 1, GradePoint: 10}, {Letter: "F"}},
+	})
+	assert.ErrorIs(t, err, ErrNoScoresToCurve)
+}
+
+func TestUnlockCurveNeedsReason(t *testing.T) {
+	g := newTestService(&fakeAssessmentRepo{}, &fakeEnrollmentRepo{})
+	_, err := g.UnlockCurve(context.Background(), 1, 10, nil, "admin-1", "  ")
+	assert.ErrorIs(t, err, ErrInvalidCurve)
+}
@@ -0,0 +1,393 @@
+package grades
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+)
+
+var ErrInvalidGradingScale = errors.New("invalid grading scale")
+
+func (g *gradeServices) CreateGradingScale(ctx context.Context, scale *models.GradingScale) error {
+	if err := g.checkGradingScale(scale); err != nil {
+		return err
+	}
+	return g.gradingScaleRepo.CreateGradingScale(ctx, scale)
+}
+
+func (g *gradeServices) GetGradingScales(ctx context.Context, collegeID int) ([]*models.GradingScale, error) {
+	return g.gradingScaleRepo.FindGradingScales(ctx, collegeID)
+}
+
+func (g *gradeServices) UpdateGradingScale(ctx context.Context, scale *models.GradingScale) error {
+	if err := g.checkGradingScale(scale); err != nil {
+		return err
+	}
+	return g.gradingScaleRepo.UpdateGradingScale(ctx, scale)
+}
+
+func (g *gradeServices) DeleteGradingScale(ctx context.Context, collegeID int, scaleID int) error {
+	return g.gradingScaleRepo.DeleteGradingScale(ctx, collegeID, scaleID)
+}
+
+func (g *gradeServices) ComputeTermResults(ctx context.Context, collegeID int, termID *int) (*models.TermResults, error) {
+	termID, err := g.termOrCurrent(ctx, collegeID, termID)
+	if err != nil {
+		return nil, err
+	}
+	scale, err := g.gradingScale(ctx, collegeID)
+	if err != nil {
+		return nil, err
+	}
+	courses, err := g.resultRepo.FindAssessedCourses(ctx, collegeID, termID)
+	if err != nil {
+		return nil, err
+	}
+
+	published, err := g.publicationRepo.FindPublications(ctx, collegeID, termID, models.ResultsPublished)
+	if err != nil {
+		return nil, err
+	}
+	skip := make(map[int]bool, len(published))
+	for _, p := range published {
+		skip[p.CourseID] = true
+	}
+
+	report := &models.TermResults{TermID: termID, Courses: len(courses), Students: []int{}}
+	students := make(map[int]bool)
+	for _, course := range courses {
+		if skip[course.ID] {
+			report.Published++
+			continue
+		}
+		results, _, err := g.saveCourseResults(ctx, collegeID, course, termID, scale)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range results {
+			students[r.StudentID] = true
+		}
+		report.Results += len(results)
+	}
+
+	for id := range students {
+		report.Students = append(report.Students, id)
+	}
+	sort.Ints(report.Students)
+	for _, id := range report.Students {
+		if _, err := g.CalculateAndStoreStudentGPA(ctx, collegeID, id); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+func (g *gradeServices) CalculateAndStoreStudentGPA(ctx context.Context, collegeID int, studentID int) ([]*models.GPASnapshot, error) {
+	results, err := g.resultRepo.FindStudentResults(ctx, collegeID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	snapshots := gpaSnapshots(termResults(results))
+	if err := g.resultRepo.SaveGPASnapshots(ctx, collegeID, studentID, snapshots); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+func (g *gradeServices) GetStudentGPA(ctx context.Context, collegeID int, studentID int, publishedOnly bool) ([]*models.GPASnapshot, error) {
+	if !publishedOnly {
+		return g.resultRepo.FindGPASnapshots(ctx, collegeID, studentID)
+	}
+	// The stored snapshots count results still on their way to publication
+	results, err := g.studentResults(ctx, collegeID, studentID, true)
+	if err != nil {
+		return nil, err
+	}
+	snapshots := gpaSnapshots(termResults(results))
+	for _, s := range snapshots {
+		s.CollegeID, s.StudentID = collegeID, studentID
+	}
+	return snapshots, nil
+}
+
+func (g *gradeServices) GetTranscript(ctx context.Context, collegeID int, studentID int, publishedOnly bool) (*models.Transcript, error) {
+	transcript, err := g.resultRepo.GetTranscriptStudent(ctx, collegeID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	results, err := g.studentResults(ctx, collegeID, studentID, publishedOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := termResults(results)
+	snapshots := gpaSnapshots(terms)
+	transcript.Terms = make([]*models.TranscriptTerm, len(terms))
+	for i, courses := range terms {
+		term := &models.TranscriptTerm{
+			TermID:  courses[0].TermID,
+			Name:    "Other courses",
+			Courses: courses,
+			Credits: snapshots[i].TermCredits,
+			SGPA:    snapshots[i].SGPA,
+			CGPA:    snapshots[i].CGPA,
+		}
+		if courses[0].TermName != nil {
+			term.Name = *courses[0].TermName
+		}
+		transcript.Terms[i] = term
+	}
+	if n := len(snapshots); n > 0 {
+		transcript.CGPA = snapshots[n-1].CGPA
+		transcript.CreditsEarned = snapshots[n-1].CreditsEarned
+	}
+	transcript.GeneratedAt = time.Now()
+	return transcript, nil
+}
+
+// gradingScale returns the college's default scale, or the built-in one if
+// it has none.
+func (g *gradeServices) gradingScale(ctx context.Context, collegeID int) (*models.GradingScale, error) {
+	scale, err := g.gradingScaleRepo.GetDefaultGradingScale(ctx, collegeID)
+	if errors.Is(err, repository.ErrGradingScaleNotFound) {
+		builtIn := models.DefaultGradingScale
+		builtIn.CollegeID = collegeID
+		return &builtIn, nil
+	}
+	return scale, err
+}
+
+// saveCourseResults grades the course's students in termID on the scale, or
+// the course's locked curve, replacing its earlier results. It returns them
+// with the scale they were graded on.
+func (g *gradeServices) saveCourseResults(ctx context.Context, collegeID int, course *models.Course, termID *int, scale *models.GradingScale) ([]*models.CourseResult, *models.GradingScale, error) {
+	percentages, err := g.coursePercentages(ctx, collegeID, course.ID, termID)
+	if err != nil {
+		return nil, nil, err
+	}
+	courseScale, err := g.courseScale(ctx, collegeID, course.ID, termID, scale)
+	if err != nil {
+		return nil, nil, err
+	}
+	results := gradeCourse(courseScale, percentages, course.Credits)
+	if err := g.resultRepo.SaveCourseResults(ctx, collegeID, course.ID, termID, results); err != nil {
+		return nil, nil, err
+	}
+	return results, courseScale, nil
+}
+
+// coursePercentages returns the weighted totals, as percentages of the
+// course's assessment weights, of the enrolled students graded in it.
+func (g *gradeServices) coursePercentages(ctx context.Context, collegeID int, courseID int, termID *int) (map[int]float64, error) {
+	assessments, err := g.assessmentRepo.FindAssessments(ctx, collegeID, courseID, termID)
+	if err != nil {
+		return nil, err
+	}
+	scores, err := g.findScores(ctx, collegeID, assessments, nil)
+	if err != nil {
+		return nil, err
+	}
+	studentIDs := make([]int, 0, len(scores))
+	for id := range scores {
+		studentIDs = append(studentIDs, id)
+	}
+	enrolled, err := g.enrollmentRepo.FindEnrolledActiveStudentIDs(ctx, collegeID, courseID, studentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	percentages := make(map[int]float64, len(studentIDs))
+	for _, id := range studentIDs {
+		if !enrolled[id] {
+			continue
+		}
+		grade := weightedGrade(id, courseID, termID, assessments, scores[id])
+		if grade.TotalWeight > 0 {
+			percentages[id] = round2(grade.WeightedTotal / grade.TotalWeight * 100)
+		}
+	}
+	return percentages, nil
+}
+
+// checkGradingScale validates the scale and sorts its bands, highest first.
+func (g *gradeServices) checkGradingScale(scale *models.GradingScale) error {
+	if err := g.validate.Struct(scale); err != nil {
+		return fmt.Errorf("validation failed %w", err)
+	}
+	sort.SliceStable(scale.Bands, func(i, j int) bool { return scale.Bands[i].MinScore > scale.Bands[j].MinScore })
+
+	letters := make(map[string]bool, len(scale.Bands))
+	for i, b := range scale.Bands {
+		if i > 0 && b.MinScore == scale.Bands[i-1].MinScore {
+			return fmt.Errorf("%w: two bands start at %g", ErrInvalidGradingScale, b.MinScore)
+		}
+		if i > 0 && b.GradePoint > scale.Bands[i-1].GradePoint {
+			return fmt.Errorf("%w: band %s is worth more than the band above it", ErrInvalidGradingScale, b.Letter)
+		}
+		if letters[strings.ToUpper(b.Letter)] {
+			return fmt.Errorf("%w: letter %s is used twice", ErrInvalidGradingScale, b.Letter)
+		}
+		letters[strings.ToUpper(b.Letter)] = true
+	}
+	if last := scale.Bands[len(scale.Bands)-1]; last.MinScore != 0 {
+		return fmt.Errorf("%w: the lowest band must start at 0", ErrInvalidGradingScale)
+	}
+	return nil
+}
+
+// gradeCourse gives each student, by percentage, a band of the scale. On
+// relative scales students at or above the pass percentage are banded by
+// the percentage of them scoring at or below their own, so the top scorer
+// is always at 100; the rest get the lowest band.
+func gradeCourse(scale *models.GradingScale, percentages map[int]float64, credits int) []*models.CourseResult {
+	var scaleID *int
+	if scale.ID != 0 {
+		scaleID = &scale.ID
+	}
+
+	var passing []float64
+	if scale.Method == models.RelativeGrading {
+		for _, p := range percentages {
+			if p >= scale.PassPercentage {
+				passing = append(passing, p)
+			}
+		}
+		sort.Float64s(passing)
+	}
+
+	results := make([]*models.CourseResult, 0, len(percentages))
+	for studentID, p := range percentages {
+		score := p
+		if scale.Method == models.RelativeGrading {
+			score = 0
+			if p >= scale.PassPercentage {
+				atOrBelow := sort.Search(len(passing), func(i int) bool { return passing[i] > p })
+				score = float64(atOrBelow) / float64(len(passing)) * 100
+			}
+		}
+		band := bandFor(scale.Bands, score)
+		results = append(results, &models.CourseResult{
+			StudentID:      studentID,
+			Percentage:     p,
+			GradeLetter:    band.Letter,
+			GradePoint:     band.GradePoint,
+			Credits:        credits,
+			GradingScaleID: scaleID,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].StudentID < results[j].StudentID })
+	return results
+}
+
+// bandFor returns the first of the bands, highest first, that score reaches,
+// or the last one.
+func bandFor(bands []models.GradeBand, score float64) models.GradeBand {
+	for _, b := range bands {
+		if score >= b.MinScore {
+			return b
+		}
+	}
+	return bands[len(bands)-1]
+}
+
+// percentageBands returns the bands results were graded on by the scale,
+// starting at percentages. Relative scales band percentiles, so each of
+// their bands starts at the lowest passing percentage graded into it or, if
+// none was, where the band above starts, the pass percentage for the top
+// band. The last band still starts at 0.
+func percentageBands(scale *models.GradingScale, results []*models.CourseResult) []models.GradeBand {
+	bands := append([]models.GradeBand(nil), scale.Bands...)
+	if scale.Method != models.RelativeGrading {
+		return bands
+	}
+	lowest := make(map[string]float64, len(bands))
+	for _, r := range results {
+		if min, ok := lowest[r.GradeLetter]; r.Percentage >= scale.PassPercentage && (!ok || r.Percentage < min) {
+			lowest[r.GradeLetter] = r.Percentage
+		}
+	}
+	start := scale.PassPercentage
+	for i := range bands {
+		if i == len(bands)-1 {
+			bands[i].MinScore = 0
+			break
+		}
+		if min, ok := lowest[bands[i].Letter]; ok {
+			start = min
+		}
+		bands[i].MinScore = start
+	}
+	return bands
+}
+
+// termResults splits results ordered by term into one slice per term.
+func termResults(results []*models.CourseResult) [][]*models.CourseResult {
+	var terms [][]*models.CourseResult
+	for i, r := range results {
+		if i == 0 || termKey(r.TermID) != termKey(results[i-1].TermID) {
+			terms = append(terms, nil)
+		}
+		terms[len(terms)-1] = append(terms[len(terms)-1], r)
+	}
+	return terms
+}
+
+// gpaSnapshots computes the SGPA of each term and the CGPA through it, both
+// credit weighted. A course taken again counts only with its latest result
+// towards the CGPA.
+func gpaSnapshots(terms [][]*models.CourseResult) []*models.GPASnapshot {
+	snapshots := make([]*models.GPASnapshot, len(terms))
+	latest := make(map[int]*models.CourseResult)
+	for i, results := range terms {
+		credits, points := 0, 0.0
+		for _, r := range results {
+			credits += r.Credits
+			points += float64(r.Credits) * r.GradePoint
+			latest[r.CourseID] = r
+		}
+
+		totalCredits, totalPoints, earned := 0, 0.0, 0
+		for _, r := range latest {
+			totalCredits += r.Credits
+			totalPoints += float64(r.Credits) * r.GradePoint
+			if r.GradePoint > 0 {
+				earned += r.Credits
+			}
+		}
+
+		snapshots[i] = &models.GPASnapshot{
+			TermID:        results[0].TermID,
+			SGPA:          gpa(points, credits),
+			CGPA:          gpa(totalPoints, totalCredits),
+			TermCredits:   credits,
+			CreditsEarned: earned,
+		}
+	}
+	return snapshots
+}
+
+func gpa(points float64, credits int) float64 {
+	if credits == 0 {
+		return 0
+	}
+	return round2(points / float64(credits))
+}
+
+func round2(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+func termKey(termID *int) string {
+	if termID == nil {
+		return ""
+	}
+	return strconv.Itoa(*termID)
+}
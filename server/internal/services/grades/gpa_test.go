@@ -0,0 +1,232 @@
+package grades
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeGradingScaleRepo struct {
+	repository.GradingScaleRepository
+	scale *models.GradingScale
+}
+
+func (f *fakeGradingScaleRepo) GetDefaultGradingScale(ctx context.Context, collegeID int) (*models.GradingScale, error) {
+	if f.scale == nil {
+		return nil, repository.ErrGradingScaleNotFound
+	}
+	return f.scale, nil
+}
+
+type fakeResultRepo struct {
+	repository.ResultRepository
+	courses   []*models.Course
+	results   map[int][]*models.CourseResult // By course
+	snapshots map[int][]*models.GPASnapshot  // By student
+}
+
+func (f *fakeResultRepo) FindAssessedCourses(ctx context.Context, collegeID int, termID *int) ([]*models.Course, error) {
+	return f.courses, nil
+}
+
+func (f *fakeResultRepo) SaveCourseResults(ctx context.Context, collegeID int, courseID int, termID *int, results []*models.CourseResult) error {
+	for _, r := range results {
+		r.CourseID, r.TermID = courseID, termID
+	}
+	f.results[courseID] = results
+	return nil
+}
+
+func (f *fakeResultRepo) FindStudentResults(ctx context.Context, collegeID int, studentID int) ([]*models.CourseResult, error) {
+	var results []*models.CourseResult
+	for _, course := range f.courses {
+		for _, r := range f.results[course.ID] {
+			if r.StudentID == studentID {
+				results = append(results, r)
+			}
+		}
+	}
+	return results, nil
+}
+
+func (f *fakeResultRepo) SaveGPASnapshots(ctx context.Context, collegeID int, studentID int, snapshots []*models.GPASnapshot) error {
+	f.snapshots[studentID] = snapshots
+	return nil
+}
+
+func TestGradeCourseAbsolute(t *testing.T) {
+	scale := models.DefaultGradingScale
+	results := gradeCourse(&scale, map[int]float64{1: 95, 2: 70, 3: 69.99, 4: 12}, 4)
+
+	require.Len(t, results, 4)
+	letters := []string{"O", "A", "B+", "F"}
+	for i, r := range results {
+		assert.Equal(t, i+1, r.StudentID)
+		assert.Equal(t, letters[i], r.GradeLetter)
+		assert.Equal(t, 4, r.Credits)
+		assert.Nil(t, r.GradingScaleID)
+	}
+	assert.Equal(t, 8.0, results[1].GradePoint)
+}
+
+func TestGradeCourseRelative(t *testing.T) {
+	scale := &models.GradingScale{
+		ID:             7,
+		Method:         models.RelativeGrading,
+		PassPercentage: 40,
+		Bands: []models.GradeBand{
+			{Letter: "A", MinScore: 75, GradePoint: 10},
+			{Letter: "B", MinScore: 25, GradePoint: 8},
+			{Letter: "C", MinScore: 1, GradePoint: 6},
+			{Letter: "F", MinScore: 0, GradePoint: 0},
+		},
+	}
+	// Four pass: 45 is at the 25th percentile, 50 the 50th and both 60s the
+	// 100th; 30 is below the pass percentage.
+	results := gradeCourse(scale, map[int]float64{1: 45, 2: 50, 3: 60, 4: 60, 5: 30}, 3)
+
+	letters := map[int]string{}
+	for _, r := range results {
+		letters[r.StudentID] = r.GradeLetter
+		assert.Equal(t, 7, *r.GradingScaleID)
+	}
+	assert.Equal(t, map[int]string{1: "B", 2: "B", 3: "A", 4: "A", 5: "F"}, letters)
+
+	// Resolved to percentages, C starts with B as nobody was given it
+	starts := []float64{}
+	for _, b := range percentageBands(scale, results) {
+		starts = append(starts, b.MinScore)
+	}
+	assert.Equal(t, []float64{60, 45, 45, 0}, starts)
+	assert.Equal(t, 25.0, scale.Bands[1].MinScore, "the scale is left as it was")
+}
+
+func TestGPASnapshots(t *testing.T) {
+	fall, spring := intPtr(1), intPtr(2)
+	terms := termResults([]*models.CourseResult{
+		{CourseID: 10, TermID: fall, Credits: 4, GradePoint: 10},
+		{CourseID: 11, TermID: fall, Credits: 2, GradePoint: 0},
+		{CourseID: 11, TermID: spring, Credits: 2, GradePoint: 7},
+		{CourseID: 12, TermID: spring, Credits: 3, GradePoint: 8},
+	})
+	require.Len(t, terms, 2)
+
+	snapshots := gpaSnapshots(terms)
+	require.Len(t, snapshots, 2)
+
+	// Fall: (4*10 + 2*0) / 6
+	assert.Equal(t, fall, snapshots[0].TermID)
+	assert.Equal(t, 6.67, snapshots[0].SGPA)
+	assert.Equal(t, 6.67, snapshots[0].CGPA)
+	assert.Equal(t, 6, snapshots[0].TermCredits)
+	assert.Equal(t, 4, snapshots[0].CreditsEarned)
+
+	// Spring: (2*7 + 3*8) / 5; the retaken course replaces its fall result
+	// in the CGPA: (4*10 + 2*7 + 3*8) / 9
+	assert.Equal(t, 7.6, snapshots[1].SGPA)
+	assert.Equal(t, 8.67, snapshots[1].CGPA)
+	assert.Equal(t, 9, snapshots[1].CreditsEarned)
+}
+
+func TestCheckGradingScale(t *testing.T) {
+	g := &gradeServices{validate: *validator.New()}
+
+	scale := &models.GradingScale{
+		Name:   "Pass/fail",
+		Method: models.AbsoluteGrading,
+		Bands:  []models.GradeBand{{Letter: "F", MinScore: 0}, {Letter: "P", MinScore: 50, GradePoint: 4}},
+	}
+	require.NoError(t, g.checkGradingScale(scale))
+	assert.Equal(t, "P", scale.Bands[0].Letter, "bands are sorted highest first")
+
+	for name, bands := range map[string][]models.GradeBand{
+		"no zero band":   {{Letter: "P", MinScore: 50, GradePoint: 4}},
+		"same start":     {{Letter: "P", MinScore: 50, GradePoint: 4}, {Letter: "Q", MinScore: 50}, {Letter: "F"}},
+		"same letter":    {{Letter: "P", MinScore: 50, GradePoint: 4}, {Letter: "p"}},
+		"points inverse": {{Letter: "P", MinScore: 50, GradePoint: 4}, {Letter: "F", GradePoint: 5}},
+	} {
+		scale.Bands = bands
+		assert.ErrorIs(t, g.checkGradingScale(scale), ErrInvalidGradingScale, name)
+	}
+
+	scale.Method = "curved"
+	assert.Error(t, g.checkGradingScale(scale))
+}
+
+func TestComputeTermResults(t *testing.T) {
+	assessments := &fakeAssessmentRepo{
+		assessments: []*models.Assessment{
+			{ID: 1, CourseID: 10, TermID: intPtr(3), Weight: 40, MaxMarks: 50},
+			{ID: 2, CourseID: 10, TermID: intPtr(3), Weight: 60, MaxMarks: 100},
+		},
+		scores: []*models.Grade{
+			{StudentID: 5, AssessmentID: intPtr(1), MarksObtained: 50},
+			{StudentID: 5, AssessmentID: intPtr(2), MarksObtained: 80},
+			{StudentID: 6, AssessmentID: intPtr(1), MarksObtained: 20},
+			{StudentID: 7, AssessmentID: intPtr(1), MarksObtained: 50}, // Not enrolled
+		},
+	}
+	results := &fakeResultRepo{
+		courses:   []*models.Course{{ID: 10, Credits: 4}},
+		results:   map[int][]*models.CourseResult{},
+		snapshots: map[int][]*models.GPASnapshot{},
+	}
+	g := NewGradeServices(nil, nil, &fakeEnrollmentRepo{active: map[int]bool{5: true, 6: true}}, nil,
+		&fakeTermRepo{}, assessments, &fakeGradingScaleRepo{}, results, &fakeCurveRepo{}, &fakePublicationRepo{}, nil)
+
+	report, err := g.ComputeTermResults(context.Background(), 1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, *report.TermID)
+	assert.Equal(t, 2, report.Results)
+	assert.Equal(t, []int{5, 6}, report.Students)
+
+	saved := results.results[10]
+	require.Len(t, saved, 2)
+	// 40 + 48 = 88% and 16%
+	assert.Equal(t, 88.0, saved[0].Percentage)
+	assert.Equal(t, "A+", saved[0].GradeLetter)
+	assert.Equal(t, 16.0, saved[1].Percentage)
+	assert.Equal(t, "F", saved[1].GradeLetter)
+
+	require.Len(t, results.snapshots[5], 1)
+	assert.Equal(t, 9.0, results.snapshots[5][0].SGPA)
+	assert.Equal(t, 4, results.snapshots[5][0].CreditsEarned)
+	assert.Equal(t, 0, results.snapshots[6][0].CreditsEarned)
+}
+
+func TestWriteTranscriptPDF(t *testing.T) {
+	name := "Fall 2026"
+	transcript := &models.Transcript{
+		CollegeName: "Springfield College",
+		StudentName: "Lee Park",
+		RollNo:      "CS-042",
+		CGPA:        8.5,
+		GeneratedAt: time.Date(2026, 12, 20, 0, 0, 0, 0, time.UTC),
+		Terms:       []*models.TranscriptTerm{{Name: name, SGPA: 8.5, CGPA: 8.5, Credits: 240}},
+	}
+	// Enough courses to run onto a second page
+	for i := 0; i < 60; i++ {
+		transcript.Terms[0].Courses = append(transcript.Terms[0].Courses, &models.CourseResult{
+			CourseName: fmt.Sprintf("Course %d", i), Credits: 4, GradeLetter: "A", GradePoint: 8,
+		})
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTranscriptPDF(&buf, transcript))
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "%PDF-"))
+	assert.Contains(t, out, "(Springfield College) Tj")
+	assert.Contains(t, out, "(Course 59) Tj")
+	assert.Contains(t, out, "(Cumulative GPA: 8.50    Credits earned: 0) Tj")
+	assert.Contains(t, out, "/Count 2")
+}
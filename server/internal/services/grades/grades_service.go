@@ -14,8 +14,103 @@ type GradeServices interface {
 	GetGradeByID(ctx context.Context, gradeId int, collegeID int) (*models.Grade, error)
 	UpdateGrade(ctx context.Context, grade *models.Grade) error
 	DeleteGrade(ctx context.Context, gradeID int, collegeID int) error
+	// GetGrades defaults to the college's current term unless the filter
+	// names a term, semester or academic year.
 	GetGrades(ctx context.Context, filter models.GradeFilter) ([]*models.Grade, error)
-	// CalculateAndStoreStudentGPA(ctx context.Context,collegeID int,RollNo string)error 
+	// GetGradeRows is GetGrades with each student's roll number and name and
+	// the course's name, read in a single query for result sheets.
+	GetGradeRows(ctx context.Context, filter models.GradeFilter) ([]*models.GradeRow, error)
+
+	// Assessments are the weighted components of a course's grade, such as
+	// midterms and the final; see assessments.go.
+	CreateAssessment(ctx context.Context, assessment *models.Assessment) error
+	// GetAssessments defaults to the college's current term.
+	GetAssessments(ctx context.Context, collegeID int, courseID int, termID *int) ([]*models.Assessment, error)
+	// UpdateAssessment changes the name, weight and max marks; max marks may
+	// not drop below marks already given.
+	UpdateAssessment(ctx context.Context, assessment *models.Assessment) error
+	DeleteAssessment(ctx context.Context, collegeID int, courseID int, assessmentID int) error
+	// SubmitScores stores students' marks in the assessment, replacing
+	// earlier ones. Every student must be actively enrolled in the course and
+	// within the assessment's max marks, or a *ScoreSubmissionError lists
+	// those who are not and nothing is saved.
+	SubmitScores(ctx context.Context, collegeID int, courseID int, assessmentID int, scores []models.AssessmentScore) ([]*models.Grade, error)
+	// GetCourseGrades pages through the weighted totals of the course's
+	// active students in termID, defaulting to the current term.
+	GetCourseGrades(ctx context.Context, collegeID int, courseID int, termID *int, limit, offset uint64) (*models.CourseGradeBook, error)
+	// GetStudentGrades pages through the student's weighted totals in the
+	// courses they have not dropped, each in the term of the enrollment;
+	// with publishedOnly just those whose results are published.
+	GetStudentGrades(ctx context.Context, collegeID int, studentID int, publishedOnly bool, limit, offset uint64) ([]*models.CourseGrade, error)
+
+	// Grading scales turn course results into letter grades; see gpa.go.
+	// Bands are sorted highest first and the lowest must start at 0.
+	CreateGradingScale(ctx context.Context, scale *models.GradingScale) error
+	GetGradingScales(ctx context.Context, collegeID int) ([]*models.GradingScale, error)
+	UpdateGradingScale(ctx context.Context, scale *models.GradingScale) error
+	DeleteGradingScale(ctx context.Context, collegeID int, scaleID int) error
+	// ComputeTermResults grades every course with assessments in termID,
+	// defaulting to the current term, on the college's default scale (the
+	// built-in ten point scale if it has none), replacing earlier results
+	// of all but published courses, then recalculates the GPA of each
+	// student graded.
+	ComputeTermResults(ctx context.Context, collegeID int, termID *int) (*models.TermResults, error)
+	// CalculateAndStoreStudentGPA recomputes the student's SGPA and CGPA
+	// snapshots from their course results, weighted by course credits.
+	CalculateAndStoreStudentGPA(ctx context.Context, collegeID int, studentID int) ([]*models.GPASnapshot, error)
+	// GetStudentGPA returns the stored snapshots or, with publishedOnly,
+	// computes them from the student's published results alone.
+	GetStudentGPA(ctx context.Context, collegeID int, studentID int, publishedOnly bool) ([]*models.GPASnapshot, error)
+	// GetTranscript lists the student's course results, only the published
+	// ones with publishedOnly, term by term with each term's SGPA and CGPA;
+	// WriteTranscriptPDF renders it.
+	GetTranscript(ctx context.Context, collegeID int, studentID int, publishedOnly bool) (*models.Transcript, error)
+
+	// Curves grade a course relative to its class; see curve.go.
+	// PreviewCurve shows the letters the curve would give the course's
+	// students in curve.TermID, defaulting to the current term.
+	PreviewCurve(ctx context.Context, collegeID int, courseID int, curve *models.Curve) (*models.CurvePreview, error)
+	// CommitCurve applies the curve like PreviewCurve, writes each student's
+	// letter to their grades and stores it as the course's next curve
+	// version, locked: scores and assessments cannot change, and results
+	// are graded on its cutoffs, until it is unlocked. It fails with
+	// repository.ErrScoresChanged if scores change while it is computed.
+	CommitCurve(ctx context.Context, collegeID int, courseID int, curve *models.Curve, committedBy string) (*models.GradeCurve, error)
+	// GetCurves lists the course's curve versions in termID, defaulting to
+	// the current term, newest first.
+	GetCurves(ctx context.Context, collegeID int, courseID int, termID *int) ([]*models.GradeCurve, error)
+	UnlockCurve(ctx context.Context, collegeID int, courseID int, termID *int, unlockedBy string, reason string) (*models.GradeCurve, error)
+
+	// Results move from draft to submitted, HOD-approved and published per
+	// course and term, defaulting to the current term; see publication.go.
+	// Past the draft the course's grades cannot change, and students only
+	// see them once published.
+	GetPublication(ctx context.Context, collegeID int, courseID int, termID *int) (*models.ResultPublication, error)
+	// GetPublications lists the publications in termID with the status, or
+	// any status if it is empty.
+	GetPublications(ctx context.Context, collegeID int, termID *int, status models.PublicationStatus) ([]*models.ResultPublication, error)
+	// SubmitResults fails with ErrNotSubmitter unless submittedBy instructs the course.
+	SubmitResults(ctx context.Context, collegeID int, courseID int, termID *int, submittedBy string) (*models.ResultPublication, error)
+	// ApproveResults fails with ErrNotApprover unless approvedBy heads the
+	// course's department, and with ErrSelfApproval if they submitted them.
+	ApproveResults(ctx context.Context, collegeID int, courseID int, termID *int, approvedBy string) (*models.ResultPublication, error)
+	// ReturnResults sends submitted or approved results back to a draft; like
+	// approval it is left to the head of the course's department.
+	ReturnResults(ctx context.Context, collegeID int, courseID int, termID *int, returnedBy string, reason string) (*models.ResultPublication, error)
+	// PublishResults grades the course, as ComputeTermResults does, and
+	// publishes the results for good.
+	PublishResults(ctx context.Context, collegeID int, courseID int, termID *int, publishedBy string) (*models.ResultPublication, error)
+	// RequestReevaluation asks for one of the student's published grades to
+	// be marked again.
+	RequestReevaluation(ctx context.Context, collegeID int, studentID int, gradeID int, reason string, requestedBy string) (*models.Reevaluation, error)
+	// GetReevaluations pages through the requests, newest first, of the
+	// student unless studentID is 0, with the status unless it is empty.
+	GetReevaluations(ctx context.Context, collegeID int, studentID int, status models.ReevaluationStatus, limit, offset uint64) ([]*models.Reevaluation, error)
+	// ResolveReevaluation accepts the request with the decision's marks,
+	// regrading the student's course result on the bands it was published
+	// on, or rejects it with a note. Only an admin or the course's faculty
+	// may resolve it.
+	ResolveReevaluation(ctx context.Context, collegeID int, reevaluationID int, decision *models.ReevaluationDecision, resolvedBy string, admin bool) (*models.Reevaluation, error)
 
 }
 
@@ -24,16 +119,30 @@ type gradeServices struct {
 	studentRepo repository.StudentRepository
 	enrollmentRepo  repository.EnrollmentRepository
 	courseRepo  repository.CourseRepository
+	termRepo    repository.AcademicTermRepository
+	assessmentRepo repository.AssessmentRepository
+	gradingScaleRepo repository.GradingScaleRepository
+	resultRepo repository.ResultRepository
+	curveRepo repository.GradeCurveRepository
+	publicationRepo repository.ResultPublicationRepository
+	reevaluationRepo repository.ReevaluationRepository
 
 	validate validator.Validate
 }
 
-func NewGradeServices(gradeRepo repository.GradeRepository, studentRepo repository.StudentRepository, enrollmentRepo repository.EnrollmentRepository, courseRepo repository.CourseRepository) GradeServices {
+func NewGradeServices(gradeRepo repository.GradeRepository, studentRepo repository.StudentRepository, enrollmentRepo repository.EnrollmentRepository, courseRepo repository.CourseRepository, termRepo repository.AcademicTermRepository, assessmentRepo repository.AssessmentRepository, gradingScaleRepo repository.GradingScaleRepository, resultRepo repository.ResultRepository, curveRepo repository.GradeCurveRepository, publicationRepo repository.ResultPublicationRepository, reevaluationRepo repository.ReevaluationRepository) GradeServices {
 	return &gradeServices{
 		gradeRepo: gradeRepo,
 		studentRepo: studentRepo,
 		enrollmentRepo: enrollmentRepo,
 		courseRepo: courseRepo,
+		termRepo: termRepo,
+		assessmentRepo: assessmentRepo,
+		gradingScaleRepo: gradingScaleRepo,
+		resultRepo: resultRepo,
+		curveRepo: curveRepo,
+		publicationRepo: publicationRepo,
+		reevaluationRepo: reevaluationRepo,
 		validate:  *validator.New(),
 	}
 }
@@ -42,6 +151,13 @@ func (g *gradeServices) CreateGrade(ctx context.Context, grade *models.Grade) er
 	if err := g.validate.Struct(grade); err != nil {
 		return fmt.Errorf("unable to validate %w", err)
 	}
+	termID, err := g.termOrCurrent(ctx, grade.CollegeID, grade.TermID)
+	if err != nil {
+		return err
+	}
+	if err := g.checkDraft(ctx, grade.CollegeID, grade.CourseID, termID); err != nil {
+		return err
+	}
 	return g.gradeRepo.CreateGrade(ctx, grade)
 
 }
@@ -55,15 +171,60 @@ func (g *gradeServices) UpdateGrade(ctx context.Context, grade *models.Grade) er
 	if err := g.validate.Struct(grade); err != nil {
 		return fmt.Errorf("unable to validate %w", err)
 	}
+	existing, err := g.gradeRepo.GetGradeByID(ctx, grade.ID, grade.CollegeID)
+	if err != nil {
+		return err
+	}
+	if err := g.checkDraft(ctx, grade.CollegeID, existing.CourseID, existing.TermID); err != nil {
+		return err
+	}
+	termID := existing.TermID
+	if grade.TermID != nil {
+		termID = grade.TermID
+	}
+	if err := g.checkDraft(ctx, grade.CollegeID, grade.CourseID, termID); err != nil {
+		return err
+	}
 	return g.gradeRepo.UpdateGrade(ctx, grade)
 }
 
 func (g *gradeServices) DeleteGrade(ctx context.Context, gradeID int, collegeID int) error {
+	existing, err := g.gradeRepo.GetGradeByID(ctx, gradeID, collegeID)
+	if err != nil {
+		return err
+	}
+	if err := g.checkDraft(ctx, collegeID, existing.CourseID, existing.TermID); err != nil {
+		return err
+	}
 	return g.gradeRepo.DeleteGrade(ctx, gradeID, collegeID)
 }
 
 func (g *gradeServices) GetGrades(ctx context.Context, filters models.GradeFilter) ([]*models.Grade, error) {
+	if err := g.defaultGradeTerm(ctx, &filters); err != nil {
+		return nil, err
+	}
 	return g.gradeRepo.GetGrades(ctx, filters)
 }
 
+func (g *gradeServices) GetGradeRows(ctx context.Context, filters models.GradeFilter) ([]*models.GradeRow, error) {
+	if err := g.defaultGradeTerm(ctx, &filters); err != nil {
+		return nil, err
+	}
+	return g.gradeRepo.FindGradeRows(ctx, filters)
+}
+
+// defaultGradeTerm narrows the filter to the current term unless it names a
+// term, semester, academic year or assessment.
+func (g *gradeServices) defaultGradeTerm(ctx context.Context, filters *models.GradeFilter) error {
+	if filters.TermID != nil || filters.Semester != nil || filters.AcademicYear != nil || filters.AssessmentID != nil || filters.CollegeID == nil {
+		return nil
+	}
+	termID, err := g.termRepo.CurrentTermID(ctx, *filters.CollegeID)
+	if err != nil {
+		return err
+	}
+	filters.TermID = termID
+	return nil
+}
+
 
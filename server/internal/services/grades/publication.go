@@ -0,0 +1,366 @@
+package grades
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+)
+
+var (
+	ErrInvalidTransition   = errors.New("invalid result publication step")
+	ErrSelfApproval        = errors.New("results must be approved by someone other than who submitted them")
+	ErrNotSubmitter        = errors.New("results are submitted by the course's instructor")
+	ErrNotApprover         = errors.New("results are approved or returned by the head of the course's department")
+	ErrResultsNotPublished = errors.New("course results are not published")
+	ErrInvalidReevaluation = errors.New("invalid re-evaluation")
+	ErrNotResolver         = errors.New("re-evaluations are resolved by the course's faculty or an admin")
+)
+
+// publicationSteps lists the statuses each status can move to. Submitted and
+// approved results can be returned to a draft; published ones are final.
+var publicationSteps = map[models.PublicationStatus][]models.PublicationStatus{
+	models.ResultsDraft:     {models.ResultsSubmitted},
+	models.ResultsSubmitted: {models.ResultsApproved, models.ResultsDraft},
+	models.ResultsApproved:  {models.ResultsPublished, models.ResultsDraft},
+}
+
+func (g *gradeServices) GetPublication(ctx context.Context, collegeID int, courseID int, termID *int) (*models.ResultPublication, error) {
+	termID, err := g.termOrCurrent(ctx, collegeID, termID)
+	if err != nil {
+		return nil, err
+	}
+	return g.publicationRepo.GetPublication(ctx, collegeID, courseID, termID)
+}
+
+func (g *gradeServices) GetPublications(ctx context.Context, collegeID int, termID *int, status models.PublicationStatus) ([]*models.ResultPublication, error) {
+	termID, err := g.termOrCurrent(ctx, collegeID, termID)
+	if err != nil {
+		return nil, err
+	}
+	return g.publicationRepo.FindPublications(ctx, collegeID, termID, status)
+}
+
+func (g *gradeServices) SubmitResults(ctx context.Context, collegeID int, courseID int, termID *int, submittedBy string) (*models.ResultPublication, error) {
+	return g.movePublication(ctx, collegeID, courseID, termID, models.ResultsSubmitted, submittedBy, "")
+}
+
+func (g *gradeServices) ApproveResults(ctx context.Context, collegeID int, courseID int, termID *int, approvedBy string) (*models.ResultPublication, error) {
+	return g.movePublication(ctx, collegeID, courseID, termID, models.ResultsApproved, approvedBy, "")
+}
+
+func (g *gradeServices) ReturnResults(ctx context.Context, collegeID int, courseID int, termID *int, returnedBy string, reason string) (*models.ResultPublication, error) {
+	return g.movePublication(ctx, collegeID, courseID, termID, models.ResultsDraft, returnedBy, reason)
+}
+
+func (g *gradeServices) PublishResults(ctx context.Context, collegeID int, courseID int, termID *int, publishedBy string) (*models.ResultPublication, error) {
+	return g.movePublication(ctx, collegeID, courseID, termID, models.ResultsPublished, publishedBy, "")
+}
+
+func (g *gradeServices) RequestReevaluation(ctx context.Context, collegeID int, studentID int, gradeID int, reason string, requestedBy string) (*models.Reevaluation, error) {
+	grade, err := g.gradeRepo.GetGradeByID(ctx, gradeID, collegeID)
+	if err != nil {
+		return nil, err
+	}
+	if grade.StudentID != studentID {
+		return nil, fmt.Errorf("grade %d of student %d: %w", gradeID, studentID, repository.ErrGradeNotFound)
+	}
+	pub, err := g.publicationRepo.GetPublication(ctx, collegeID, grade.CourseID, grade.TermID)
+	if err != nil {
+		return nil, err
+	}
+	if pub.Status != models.ResultsPublished {
+		return nil, fmt.Errorf("course %d: %w", grade.CourseID, ErrResultsNotPublished)
+	}
+
+	reevaluation := &models.Reevaluation{
+		CollegeID:     collegeID,
+		GradeID:       grade.ID,
+		StudentID:     studentID,
+		CourseID:      grade.CourseID,
+		TermID:        grade.TermID,
+		Reason:        strings.TrimSpace(reason),
+		RequestedBy:   requestedBy,
+		PreviousMarks: grade.MarksObtained,
+	}
+	if err := g.validate.Struct(reevaluation); err != nil {
+		return nil, fmt.Errorf("validation failed %w", err)
+	}
+	if err := g.reevaluationRepo.CreateReevaluation(ctx, reevaluation); err != nil {
+		return nil, err
+	}
+	return reevaluation, nil
+}
+
+func (g *gradeServices) GetReevaluations(ctx context.Context, collegeID int, studentID int, status models.ReevaluationStatus, limit, offset uint64) ([]*models.Reevaluation, error) {
+	return g.reevaluationRepo.FindReevaluations(ctx, collegeID, studentID, status, limit, offset)
+}
+
+func (g *gradeServices) ResolveReevaluation(ctx context.Context, collegeID int, reevaluationID int, decision *models.ReevaluationDecision, resolvedBy string, admin bool) (*models.Reevaluation, error) {
+	if err := g.validate.Struct(decision); err != nil {
+		return nil, fmt.Errorf("validation failed %w", err)
+	}
+	reevaluation, err := g.reevaluationRepo.GetReevaluation(ctx, collegeID, reevaluationID)
+	if err != nil {
+		return nil, err
+	}
+	if !admin {
+		instructs, err := g.courseRepo.IsCourseInstructor(ctx, collegeID, reevaluation.CourseID, resolvedBy)
+		if err != nil {
+			return nil, err
+		}
+		if !instructs {
+			return nil, ErrNotResolver
+		}
+	}
+	if reevaluation.Status != models.ReevaluationPending {
+		return nil, fmt.Errorf("re-evaluation %d: %w", reevaluationID, repository.ErrReevaluationResolved)
+	}
+
+	reevaluation.ResolvedBy = &resolvedBy
+	if note := strings.TrimSpace(decision.Note); note != "" {
+		reevaluation.ResolutionNote = &note
+	}
+	var result *models.CourseResult
+	var version string
+	if decision.Marks == nil {
+		if reevaluation.ResolutionNote == nil {
+			return nil, fmt.Errorf("%w: a note is required to reject a re-evaluation", ErrInvalidReevaluation)
+		}
+		reevaluation.Status = models.ReevaluationRejected
+	} else {
+		grade, err := g.gradeRepo.GetGradeByID(ctx, reevaluation.GradeID, collegeID)
+		if err != nil {
+			return nil, err
+		}
+		if *decision.Marks > grade.TotalMarks {
+			return nil, fmt.Errorf("%w: marks are out of %g", ErrInvalidReevaluation, grade.TotalMarks)
+		}
+		reevaluation.Status = models.ReevaluationAccepted
+		reevaluation.RevisedMarks = decision.Marks
+		if version, err = g.curveRepo.ScoresVersion(ctx, reevaluation.CourseID, reevaluation.TermID); err != nil {
+			return nil, err
+		}
+		if result, err = g.revisedResult(ctx, collegeID, reevaluation, grade); err != nil {
+			return nil, err
+		}
+	}
+	if err := g.reevaluationRepo.ResolveReevaluation(ctx, reevaluation, result, version); err != nil {
+		return nil, err
+	}
+
+	if result != nil {
+		if _, err := g.CalculateAndStoreStudentGPA(ctx, collegeID, reevaluation.StudentID); err != nil {
+			return nil, err
+		}
+	}
+	return reevaluation, nil
+}
+
+// revisedResult regrades the student's course result with the accepted
+// re-evaluation's marks for the grade, on the bands the course's results
+// were published on. The rest of the class is left as published.
+func (g *gradeServices) revisedResult(ctx context.Context, collegeID int, reevaluation *models.Reevaluation, grade *models.Grade) (*models.CourseResult, error) {
+	pub, err := g.publicationRepo.GetPublication(ctx, collegeID, reevaluation.CourseID, reevaluation.TermID)
+	if err != nil {
+		return nil, err
+	}
+	if pub.Status != models.ResultsPublished || len(pub.Bands) == 0 {
+		return nil, fmt.Errorf("course %d: %w", reevaluation.CourseID, ErrResultsNotPublished)
+	}
+	assessments, err := g.assessmentRepo.FindAssessments(ctx, collegeID, reevaluation.CourseID, reevaluation.TermID)
+	if err != nil {
+		return nil, err
+	}
+	scores, err := g.findScores(ctx, collegeID, assessments, []int{reevaluation.StudentID})
+	if err != nil {
+		return nil, err
+	}
+	marks := scores[reevaluation.StudentID]
+	if marks == nil {
+		marks = make(map[int]float64, 1)
+	}
+	if grade.AssessmentID != nil {
+		marks[*grade.AssessmentID] = *reevaluation.RevisedMarks
+	}
+
+	course := weightedGrade(reevaluation.StudentID, reevaluation.CourseID, reevaluation.TermID, assessments, marks)
+	result := &models.CourseResult{StudentID: reevaluation.StudentID}
+	if course.TotalWeight > 0 {
+		result.Percentage = round2(course.WeightedTotal / course.TotalWeight * 100)
+	}
+	band := bandFor(pub.Bands, result.Percentage)
+	result.GradeLetter, result.GradePoint = band.Letter, band.GradePoint
+	return result, nil
+}
+
+// movePublication takes the course's results in termID, defaulting to the
+// current term, one step to the status. Published results are graded, on
+// the grades the head of department approved, before the step is saved,
+// and the publication keeps the bands they were graded on.
+func (g *gradeServices) movePublication(ctx context.Context, collegeID int, courseID int, termID *int, to models.PublicationStatus, by string, reason string) (*models.ResultPublication, error) {
+	if _, err := g.courseRepo.FindCourseByID(ctx, collegeID, courseID); err != nil {
+		return nil, err
+	}
+	if err := g.checkPublicationStep(ctx, collegeID, courseID, to, by); err != nil {
+		return nil, err
+	}
+	termID, err := g.termOrCurrent(ctx, collegeID, termID)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := g.publicationRepo.GetPublication(ctx, collegeID, courseID, termID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := pub.Status
+	allowed := false
+	for _, next := range publicationSteps[from] {
+		allowed = allowed || next == to
+	}
+	if !allowed {
+		return nil, fmt.Errorf("%w: %s results cannot become %s", ErrInvalidTransition, from, to)
+	}
+
+	now := time.Now()
+	var results []*models.CourseResult
+	switch to {
+	case models.ResultsSubmitted:
+		pub.SubmittedBy, pub.SubmittedAt = &by, &now
+		pub.ApprovedBy, pub.ApprovedAt = nil, nil
+	case models.ResultsApproved:
+		if pub.SubmittedBy != nil && *pub.SubmittedBy == by {
+			return nil, ErrSelfApproval
+		}
+		pub.ApprovedBy, pub.ApprovedAt = &by, &now
+	case models.ResultsPublished:
+		if results, pub.Bands, err = g.regradeCourse(ctx, collegeID, courseID, termID); err != nil {
+			return nil, err
+		}
+		pub.PublishedBy, pub.PublishedAt = &by, &now
+	case models.ResultsDraft:
+		reason = strings.TrimSpace(reason)
+		if reason == "" {
+			return nil, fmt.Errorf("%w: a reason is required to return results", ErrInvalidTransition)
+		}
+		pub.ReturnedBy, pub.ReturnedAt, pub.ReturnReason = &by, &now, &reason
+	}
+	pub.Status = to
+	if err := g.publicationRepo.SavePublication(ctx, pub, from); err != nil {
+		return nil, err
+	}
+
+	if err := g.recalculateGPAs(ctx, collegeID, results); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
+
+// regradeCourse replaces the course's results in termID, graded on the
+// college's scale or the course's locked curve, and returns them with the
+// bands they were graded on as percentages.
+func (g *gradeServices) regradeCourse(ctx context.Context, collegeID int, courseID int, termID *int) ([]*models.CourseResult, []models.GradeBand, error) {
+	course, err := g.courseRepo.FindCourseByID(ctx, collegeID, courseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	scale, err := g.gradingScale(ctx, collegeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	results, scale, err := g.saveCourseResults(ctx, collegeID, course, termID, scale)
+	if err != nil {
+		return nil, nil, err
+	}
+	return results, percentageBands(scale, results), nil
+}
+
+func (g *gradeServices) recalculateGPAs(ctx context.Context, collegeID int, results []*models.CourseResult) error {
+	for _, r := range results {
+		if _, err := g.CalculateAndStoreStudentGPA(ctx, collegeID, r.StudentID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkDraft fails with repository.ErrResultsSubmitted unless the course's
+// results in termID are a draft.
+func (g *gradeServices) checkDraft(ctx context.Context, collegeID int, courseID int, termID *int) error {
+	pub, err := g.publicationRepo.GetPublication(ctx, collegeID, courseID, termID)
+	if err != nil {
+		return err
+	}
+	if pub.Status != models.ResultsDraft {
+		return fmt.Errorf("course %d: %w", courseID, repository.ErrResultsSubmitted)
+	}
+	return nil
+}
+
+// publishedCourses returns the college's published course results, keyed
+// by courseTermKey.
+func (g *gradeServices) publishedCourses(ctx context.Context, collegeID int) (map[string]bool, error) {
+	pubs, err := g.publicationRepo.FindPublished(ctx, collegeID)
+	if err != nil {
+		return nil, err
+	}
+	published := make(map[string]bool, len(pubs))
+	for _, p := range pubs {
+		published[courseTermKey(p.CourseID, p.TermID)] = true
+	}
+	return published, nil
+}
+
+// studentResults returns the student's course results ordered by term,
+// only the published ones if publishedOnly.
+func (g *gradeServices) studentResults(ctx context.Context, collegeID int, studentID int, publishedOnly bool) ([]*models.CourseResult, error) {
+	results, err := g.resultRepo.FindStudentResults(ctx, collegeID, studentID)
+	if err != nil || !publishedOnly {
+		return results, err
+	}
+	published, err := g.publishedCourses(ctx, collegeID)
+	if err != nil {
+		return nil, err
+	}
+	kept := make([]*models.CourseResult, 0, len(results))
+	for _, r := range results {
+		if published[courseTermKey(r.CourseID, r.TermID)] {
+			kept = append(kept, r)
+		}
+	}
+	return kept, nil
+}
+
+func courseTermKey(courseID int, termID *int) string {
+	return fmt.Sprintf("%d/%s", courseID, termKey(termID))
+}
+
+// checkPublicationStep ensures the course's instructor submits its results
+// and the head of its department approves or returns them. Who publishes is
+// left to the caller.
+func (g *gradeServices) checkPublicationStep(ctx context.Context, collegeID int, courseID int, to models.PublicationStatus, by string) error {
+	switch to {
+	case models.ResultsSubmitted:
+		instructs, err := g.courseRepo.IsCourseInstructor(ctx, collegeID, courseID, by)
+		if err != nil {
+			return err
+		}
+		if !instructs {
+			return ErrNotSubmitter
+		}
+	case models.ResultsApproved, models.ResultsDraft:
+		heads, err := g.courseRepo.IsDepartmentHead(ctx, collegeID, courseID, by)
+		if err != nil {
+			return err
+		}
+		if !heads {
+			return ErrNotApprover
+		}
+	}
+	return nil
+}
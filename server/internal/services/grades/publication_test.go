@@ -0,0 +1,293 @@
+package grades
+
+import (
+	"context"
+	"testing"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePublicationRepo struct {
+	repository.ResultPublicationRepository
+	pubs map[int]*models.ResultPublication // By course
+}
+
+func (f *fakePublicationRepo) GetPublication(ctx context.Context, collegeID int, courseID int, termID *int) (*models.ResultPublication, error) {
+	if pub, ok := f.pubs[courseID]; ok {
+		saved := *pub
+		return &saved, nil
+	}
+	return &models.ResultPublication{CollegeID: collegeID, CourseID: courseID, TermID: termID, Status: models.ResultsDraft}, nil
+}
+
+func (f *fakePublicationRepo) FindPublications(ctx context.Context, collegeID int, termID *int, status models.PublicationStatus) ([]*models.ResultPublication, error) {
+	var pubs []*models.ResultPublication
+	for _, pub := range f.pubs {
+		if status == "" || pub.Status == status {
+			pubs = append(pubs, pub)
+		}
+	}
+	return pubs, nil
+}
+
+func (f *fakePublicationRepo) FindPublished(ctx context.Context, collegeID int) ([]*models.ResultPublication, error) {
+	return f.FindPublications(ctx, collegeID, nil, models.ResultsPublished)
+}
+
+func (f *fakePublicationRepo) SavePublication(ctx context.Context, pub *models.ResultPublication, from models.PublicationStatus) error {
+	current, _ := f.GetPublication(ctx, pub.CollegeID, pub.CourseID, pub.TermID)
+	if current.Status != from {
+		return repository.ErrPublicationChanged
+	}
+	if f.pubs == nil {
+		f.pubs = map[int]*models.ResultPublication{}
+	}
+	saved := *pub
+	f.pubs[pub.CourseID] = &saved
+	return nil
+}
+
+type fakeReevaluationRepo struct {
+	repository.ReevaluationRepository
+	reevaluations []*models.Reevaluation
+	grades        []*models.Grade
+	results       *fakeResultRepo
+}
+
+func (f *fakeReevaluationRepo) CreateReevaluation(ctx context.Context, reevaluation *models.Reevaluation) error {
+	reevaluation.Status = models.ReevaluationPending
+	f.reevaluations = append(f.reevaluations, reevaluation)
+	reevaluation.ID = len(f.reevaluations)
+	return nil
+}
+
+func (f *fakeReevaluationRepo) GetReevaluation(ctx context.Context, collegeID int, reevaluationID int) (*models.Reevaluation, error) {
+	if reevaluationID < 1 || reevaluationID > len(f.reevaluations) {
+		return nil, repository.ErrReevaluationNotFound
+	}
+	saved := *f.reevaluations[reevaluationID-1]
+	return &saved, nil
+}
+
+func (f *fakeReevaluationRepo) ResolveReevaluation(ctx context.Context, reevaluation *models.Reevaluation, result *models.CourseResult, scoresVersion string) error {
+	f.reevaluations[reevaluation.ID-1] = reevaluation
+	if reevaluation.Status != models.ReevaluationAccepted {
+		return nil
+	}
+	for _, g := range f.grades {
+		if g.ID == reevaluation.GradeID {
+			g.MarksObtained = *reevaluation.RevisedMarks
+		}
+		if g.StudentID == reevaluation.StudentID && g.CourseID == reevaluation.CourseID {
+			revised := result.GradeLetter
+			g.GradeLetter = &revised
+		}
+	}
+	for _, r := range f.results.results[reevaluation.CourseID] {
+		if r.StudentID == reevaluation.StudentID {
+			r.Percentage, r.GradeLetter, r.GradePoint = result.Percentage, result.GradeLetter, result.GradePoint
+		}
+	}
+	return nil
+}
+
+func (f *fakeGradeRepo) GetGradeByID(ctx context.Context, gradeID int, collegeID int) (*models.Grade, error) {
+	for _, g := range f.grades {
+		if g.ID == gradeID {
+			saved := *g
+			return &saved, nil
+		}
+	}
+	return nil, repository.ErrGradeNotFound
+}
+
+type publicationFixture struct {
+	svc           GradeServices
+	courses       *fakeCourseRepo
+	pubs          *fakePublicationRepo
+	scales        *fakeGradingScaleRepo
+	results       *fakeResultRepo
+	reevaluations *fakeReevaluationRepo
+}
+
+// newPublicationFixture has students 1 and 2 scoring 50 and 90 in the one
+// assessment of course 10.
+func newPublicationFixture() *publicationFixture {
+	scores := []*models.Grade{
+		{ID: 100, StudentID: 1, CourseID: 10, TermID: intPtr(3), AssessmentID: intPtr(1), MarksObtained: 50, TotalMarks: 100},
+		{ID: 101, StudentID: 2, CourseID: 10, TermID: intPtr(3), AssessmentID: intPtr(1), MarksObtained: 90, TotalMarks: 100},
+	}
+	f := &publicationFixture{
+		courses: &fakeCourseRepo{instructors: map[int]string{10: "faculty-1"}, heads: map[int]string{10: "hod-1"}},
+		pubs:    &fakePublicationRepo{},
+		scales:  &fakeGradingScaleRepo{},
+		results: &fakeResultRepo{
+			courses:   []*models.Course{{ID: 10, Credits: 3}},
+			results:   map[int][]*models.CourseResult{},
+			snapshots: map[int][]*models.GPASnapshot{},
+		},
+	}
+	f.reevaluations = &fakeReevaluationRepo{grades: scores, results: f.results}
+	assessments := &fakeAssessmentRepo{
+		assessments: []*models.Assessment{{ID: 1, CourseID: 10, TermID: intPtr(3), Weight: 100, MaxMarks: 100}},
+		scores:      scores,
+	}
+	f.svc = NewGradeServices(&fakeGradeRepo{grades: scores}, nil, &fakeEnrollmentRepo{active: map[int]bool{1: true, 2: true}},
+		f.courses, &fakeTermRepo{}, assessments, f.scales, f.results, &fakeCurveRepo{}, f.pubs,
+		f.reevaluations)
+	return f
+}
+
+func (f *publicationFixture) publish(t *testing.T) {
+	ctx := context.Background()
+	_, err := f.svc.SubmitResults(ctx, 1, 10, nil, "faculty-1")
+	require.NoError(t, err)
+	_, err = f.svc.ApproveResults(ctx, 1, 10, nil, "hod-1")
+	require.NoError(t, err)
+	_, err = f.svc.PublishResults(ctx, 1, 10, nil, "admin-1")
+	require.NoError(t, err)
+}
+
+func letters(results []*models.CourseResult) map[int]string {
+	byStudent := map[int]string{}
+	for _, r := range results {
+		byStudent[r.StudentID] = r.GradeLetter
+	}
+	return byStudent
+}
+
+func TestPublicationWorkflow(t *testing.T) {
+	ctx := context.Background()
+	f := newPublicationFixture()
+
+	_, err := f.svc.ApproveResults(ctx, 1, 10, nil, "hod-1")
+	assert.ErrorIs(t, err, ErrInvalidTransition, "drafts are submitted first")
+
+	_, err = f.svc.SubmitResults(ctx, 1, 10, nil, "hod-1")
+	assert.ErrorIs(t, err, ErrNotSubmitter)
+	pub, err := f.svc.SubmitResults(ctx, 1, 10, nil, "faculty-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.ResultsSubmitted, pub.Status)
+	assert.Equal(t, 3, *pub.TermID)
+
+	_, err = f.svc.ApproveResults(ctx, 1, 10, nil, "admin-1")
+	assert.ErrorIs(t, err, ErrNotApprover, "admins do not stand in for the head of department")
+	_, err = f.svc.ReturnResults(ctx, 1, 10, nil, "faculty-1", "Wrong marks")
+	assert.ErrorIs(t, err, ErrNotApprover)
+	// A head of department instructing the course has someone else approve
+	f.courses.heads[10] = "faculty-1"
+	_, err = f.svc.ApproveResults(ctx, 1, 10, nil, "faculty-1")
+	assert.ErrorIs(t, err, ErrSelfApproval)
+	f.courses.heads[10] = "hod-1"
+	_, err = f.svc.PublishResults(ctx, 1, 10, nil, "admin-1")
+	assert.ErrorIs(t, err, ErrInvalidTransition, "results are approved before publication")
+
+	pub, err = f.svc.ApproveResults(ctx, 1, 10, nil, "hod-1")
+	require.NoError(t, err)
+	assert.Equal(t, "hod-1", *pub.ApprovedBy)
+
+	pub, err = f.svc.PublishResults(ctx, 1, 10, nil, "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.ResultsPublished, pub.Status)
+	assert.Equal(t, map[int]string{1: "B", 2: "O"}, letters(f.results.results[10]))
+	assert.Len(t, f.results.snapshots, 2)
+
+	_, err = f.svc.ReturnResults(ctx, 1, 10, nil, "hod-1", "Wrong marks")
+	assert.ErrorIs(t, err, ErrInvalidTransition, "published results are final")
+
+	// Term results keep what was published
+	f.results.results[10] = nil
+	report, err := f.svc.ComputeTermResults(ctx, 1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Published)
+	assert.Nil(t, f.results.results[10])
+}
+
+func TestReturnResults(t *testing.T) {
+	ctx := context.Background()
+	f := newPublicationFixture()
+	_, err := f.svc.SubmitResults(ctx, 1, 10, nil, "faculty-1")
+	require.NoError(t, err)
+	_, err = f.svc.ApproveResults(ctx, 1, 10, nil, "hod-1")
+	require.NoError(t, err)
+
+	_, err = f.svc.ReturnResults(ctx, 1, 10, nil, "hod-1", " ")
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+
+	pub, err := f.svc.ReturnResults(ctx, 1, 10, nil, "hod-1", "Midterm 2 is missing")
+	require.NoError(t, err)
+	assert.Equal(t, models.ResultsDraft, pub.Status)
+	assert.Equal(t, "Midterm 2 is missing", *pub.ReturnReason)
+
+	pub, err = f.svc.SubmitResults(ctx, 1, 10, nil, "faculty-1")
+	require.NoError(t, err)
+	assert.Nil(t, pub.ApprovedBy, "resubmitted results need approving again")
+}
+
+func TestStudentsSeeOnlyPublishedResults(t *testing.T) {
+	ctx := context.Background()
+	f := newPublicationFixture()
+	f.results.courses = append(f.results.courses, &models.Course{ID: 11})
+	f.results.results[10] = []*models.CourseResult{{StudentID: 1, CourseID: 10, TermID: intPtr(3), Credits: 4, GradePoint: 10}}
+	f.results.results[11] = []*models.CourseResult{{StudentID: 1, CourseID: 11, TermID: intPtr(3), Credits: 4, GradePoint: 0}}
+	f.pubs.pubs = map[int]*models.ResultPublication{
+		10: {CourseID: 10, TermID: intPtr(3), Status: models.ResultsPublished},
+		11: {CourseID: 11, TermID: intPtr(3), Status: models.ResultsApproved},
+	}
+
+	snapshots, err := f.svc.GetStudentGPA(ctx, 1, 1, true)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, 10.0, snapshots[0].SGPA)
+	assert.Equal(t, 1, snapshots[0].StudentID)
+}
+
+func TestReevaluation(t *testing.T) {
+	ctx := context.Background()
+	f := newPublicationFixture()
+
+	_, err := f.svc.RequestReevaluation(ctx, 1, 1, 100, "Question 4 was not marked", "student-1")
+	assert.ErrorIs(t, err, ErrResultsNotPublished)
+
+	f.publish(t)
+	_, err = f.svc.RequestReevaluation(ctx, 1, 2, 100, "Question 4 was not marked", "student-2")
+	assert.ErrorIs(t, err, repository.ErrGradeNotFound, "students can only ask about their own grades")
+
+	requested, err := f.svc.RequestReevaluation(ctx, 1, 1, 100, "Question 4 was not marked", "student-1")
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, requested.PreviousMarks)
+
+	_, err = f.svc.ResolveReevaluation(ctx, 1, requested.ID, &models.ReevaluationDecision{Marks: floatPtr(85)}, "faculty-2", false)
+	assert.ErrorIs(t, err, ErrNotResolver, "only the course's faculty or an admin resolve")
+	_, err = f.svc.ResolveReevaluation(ctx, 1, requested.ID, &models.ReevaluationDecision{}, "faculty-1", false)
+	assert.ErrorIs(t, err, ErrInvalidReevaluation, "rejections need a note")
+	_, err = f.svc.ResolveReevaluation(ctx, 1, requested.ID, &models.ReevaluationDecision{Marks: floatPtr(120)}, "faculty-1", false)
+	assert.ErrorIs(t, err, ErrInvalidReevaluation)
+
+	// The college's scale changing since does not touch published results
+	f.scales.scale = &models.GradingScale{ID: 2, Method: models.AbsoluteGrading, Bands: []models.GradeBand{
+		{Letter: "P", MinScore: 40, GradePoint: 4}, {Letter: "F", MinScore: 0},
+	}}
+	f.results.snapshots = map[int][]*models.GPASnapshot{}
+
+	resolved, err := f.svc.ResolveReevaluation(ctx, 1, requested.ID, &models.ReevaluationDecision{Marks: floatPtr(85)}, "faculty-1", false)
+	require.NoError(t, err)
+	assert.Equal(t, models.ReevaluationAccepted, resolved.Status)
+	assert.Equal(t, map[int]string{1: "A+", 2: "O"}, letters(f.results.results[10]))
+	assert.Equal(t, 85.0, f.results.results[10][0].Percentage)
+	// The grade carries the regraded letter, other students' are untouched
+	require.NotNil(t, f.reevaluations.grades[0].GradeLetter)
+	assert.Equal(t, "A+", *f.reevaluations.grades[0].GradeLetter)
+	assert.Nil(t, f.reevaluations.grades[1].GradeLetter)
+	assert.Len(t, f.results.snapshots, 1, "only the student's GPA is recalculated")
+	assert.Contains(t, f.results.snapshots, 1)
+
+	_, err = f.svc.ResolveReevaluation(ctx, 1, requested.ID, &models.ReevaluationDecision{Note: "Again"}, "admin-1", true)
+	assert.ErrorIs(t, err, repository.ErrReevaluationResolved)
+}
+
+func floatPtr(f float64) *float64 { return &f }
@@ -0,0 +1,119 @@
+package grades
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/pkg/pdf"
+)
+
+// Transcript layout, in points.
+const (
+	transcriptMargin  = 50.0
+	transcriptLine    = 15.0
+	transcriptCredits = 360.0 // Column x positions
+	transcriptGrade   = 420.0
+	transcriptPoints  = 480.0
+)
+
+// WriteTranscriptPDF renders the transcript as an A4 PDF: a header with the
+// college and student, then each term's courses with their credits, grade
+// and grade points, followed by the term's SGPA and CGPA.
+func WriteTranscriptPDF(w io.Writer, t *models.Transcript) error {
+	doc := pdf.New(fmt.Sprintf("Transcript of %s", t.RollNo))
+	tw := &transcriptWriter{doc: doc}
+	tw.newPage()
+
+	tw.text(transcriptMargin, 16, pdf.HelveticaBold, t.CollegeName)
+	tw.next(transcriptLine * 1.5)
+	tw.text(transcriptMargin, 12, pdf.HelveticaBold, "Official Transcript")
+	tw.next(transcriptLine * 1.5)
+	tw.text(transcriptMargin, 10, pdf.Helvetica, "Name: "+t.StudentName)
+	tw.next(transcriptLine)
+	tw.text(transcriptMargin, 10, pdf.Helvetica, "Roll No: "+t.RollNo)
+	tw.next(transcriptLine)
+	tw.text(transcriptMargin, 10, pdf.Helvetica, "Issued: "+t.GeneratedAt.Format("2 January 2006"))
+	tw.next(transcriptLine)
+
+	if len(t.Terms) == 0 {
+		tw.next(transcriptLine)
+		tw.text(transcriptMargin, 10, pdf.Helvetica, "No results have been recorded.")
+	}
+	for _, term := range t.Terms {
+		// Keep a term's heading with at least its first course
+		tw.ensure(transcriptLine * 4)
+		tw.next(transcriptLine)
+		tw.text(transcriptMargin, 11, pdf.HelveticaBold, term.Name)
+		tw.next(transcriptLine)
+		tw.text(transcriptMargin, 9, pdf.HelveticaBold, "Course")
+		tw.text(transcriptCredits, 9, pdf.HelveticaBold, "Credits")
+		tw.text(transcriptGrade, 9, pdf.HelveticaBold, "Grade")
+		tw.text(transcriptPoints, 9, pdf.HelveticaBold, "Grade Points")
+		tw.rule()
+
+		for _, c := range term.Courses {
+			tw.next(transcriptLine)
+			tw.text(transcriptMargin, 9, pdf.Helvetica, truncate(c.CourseName, 55))
+			tw.text(transcriptCredits, 9, pdf.Helvetica, strconv.Itoa(c.Credits))
+			tw.text(transcriptGrade, 9, pdf.Helvetica, c.GradeLetter)
+			tw.text(transcriptPoints, 9, pdf.Helvetica, strconv.FormatFloat(c.GradePoint, 'f', 2, 64))
+		}
+		tw.next(transcriptLine)
+		tw.text(transcriptMargin, 9, pdf.HelveticaBold,
+			fmt.Sprintf("Credits: %d    SGPA: %.2f    CGPA: %.2f", term.Credits, term.SGPA, term.CGPA))
+	}
+
+	tw.ensure(transcriptLine * 3)
+	tw.next(transcriptLine * 2)
+	tw.text(transcriptMargin, 11, pdf.HelveticaBold,
+		fmt.Sprintf("Cumulative GPA: %.2f    Credits earned: %d", t.CGPA, t.CreditsEarned))
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+// transcriptWriter lays out lines top to bottom, starting new pages as
+// they fill.
+type transcriptWriter struct {
+	doc  *pdf.Document
+	page *pdf.Page
+	y    float64
+}
+
+func (tw *transcriptWriter) newPage() {
+	tw.page = tw.doc.AddPage()
+	tw.y = pdf.PageHeight - transcriptMargin
+}
+
+// next moves down by dy, onto a new page if this one is full.
+func (tw *transcriptWriter) next(dy float64) {
+	tw.y -= dy
+	if tw.y < transcriptMargin {
+		tw.newPage()
+	}
+}
+
+// ensure starts a new page unless height is left on this one.
+func (tw *transcriptWriter) ensure(height float64) {
+	if tw.y-height < transcriptMargin {
+		tw.newPage()
+	}
+}
+
+func (tw *transcriptWriter) text(x, size float64, font pdf.Font, s string) {
+	tw.page.Text(x, tw.y, size, font, s)
+}
+
+func (tw *transcriptWriter) rule() {
+	tw.page.Line(transcriptMargin, tw.y-4, pdf.PageWidth-transcriptMargin, tw.y-4, 0.5)
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
@@ -0,0 +1,176 @@
+package leave
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrLeaveNotPending  = errors.New("leave request is no longer pending")
+	ErrInvalidDateRange = errors.New("leave end date must not be before its start date")
+	ErrNotRequestOwner  = errors.New("leave request belongs to another student")
+	ErrNotReviewer      = errors.New("leave requests are reviewed by the student's course faculty or an admin")
+	ErrCrossCourseLeave = errors.New("leave covering other faculty's courses is approved by an admin")
+)
+
+type LeaveService interface {
+	SubmitLeaveRequest(ctx context.Context, req *models.LeaveRequest) error
+	GetLeaveRequest(ctx context.Context, collegeID int, requestID int) (*models.LeaveRequest, error)
+	GetStudentLeaveRequests(ctx context.Context, collegeID int, studentID int, limit, offset uint64) ([]*models.LeaveRequest, error)
+	GetLeaveRequests(ctx context.Context, collegeID int, status string, limit, offset uint64) ([]*models.LeaveRequest, error)
+	CancelLeaveRequest(ctx context.Context, collegeID int, studentID int, requestID int) error
+
+	// ApproveLeaveRequest approves a pending request and marks the student
+	// Excused for every lecture held in the leave period. Unless admin is
+	// set, the reviewer must instruct one of the student's active courses, or
+	// it fails with ErrNotReviewer; RejectLeaveRequest checks the same. A
+	// faculty reviewer must also instruct all of them, as the excusal spans
+	// every course, or it fails with ErrCrossCourseLeave.
+	ApproveLeaveRequest(ctx context.Context, collegeID int, requestID int, reviewerID string, admin bool, note string) (*models.LeaveRequest, error)
+	RejectLeaveRequest(ctx context.Context, collegeID int, requestID int, reviewerID string, admin bool, note string) (*models.LeaveRequest, error)
+}
+
+type leaveService struct {
+	leaveRepo      repository.LeaveRequestRepository
+	attendanceRepo repository.AttendanceRepository
+	courseRepo     repository.CourseRepository
+	validate       validator.Validate
+}
+
+func NewLeaveService(leaveRepo repository.LeaveRequestRepository, attendanceRepo repository.AttendanceRepository, courseRepo repository.CourseRepository) LeaveService {
+	return &leaveService{
+		leaveRepo:      leaveRepo,
+		attendanceRepo: attendanceRepo,
+		courseRepo:     courseRepo,
+		validate:       *validator.New(),
+	}
+}
+
+func (l *leaveService) SubmitLeaveRequest(ctx context.Context, req *models.LeaveRequest) error {
+	if err := l.validate.Struct(req); err != nil {
+		return fmt.Errorf("validation failed %w", err)
+	}
+	if req.EndDate.Before(req.StartDate) {
+		return ErrInvalidDateRange
+	}
+	return l.leaveRepo.CreateLeaveRequest(ctx, req)
+}
+
+func (l *leaveService) GetLeaveRequest(ctx context.Context, collegeID int, requestID int) (*models.LeaveRequest, error) {
+	return l.leaveRepo.GetLeaveRequestByID(ctx, collegeID, requestID)
+}
+
+func (l *leaveService) GetStudentLeaveRequests(ctx context.Context, collegeID int, studentID int, limit, offset uint64) ([]*models.LeaveRequest, error) {
+	return l.leaveRepo.FindLeaveRequestsByStudent(ctx, collegeID, studentID, limit, offset)
+}
+
+func (l *leaveService) GetLeaveRequests(ctx context.Context, collegeID int, status string, limit, offset uint64) ([]*models.LeaveRequest, error) {
+	return l.leaveRepo.FindLeaveRequests(ctx, collegeID, status, limit, offset)
+}
+
+func (l *leaveService) CancelLeaveRequest(ctx context.Context, collegeID int, studentID int, requestID int) error {
+	req, err := l.leaveRepo.GetLeaveRequestByID(ctx, collegeID, requestID)
+	if err != nil {
+		return err
+	}
+	if req.StudentID != studentID {
+		return ErrNotRequestOwner
+	}
+	cancelled, err := l.leaveRepo.UpdateLeaveRequestStatus(ctx, collegeID, requestID, models.LeaveStatusPending, models.LeaveStatusCancelled, nil, nil)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return ErrLeaveNotPending
+	}
+	return nil
+}
+
+func (l *leaveService) ApproveLeaveRequest(ctx context.Context, collegeID int, requestID int, reviewerID string, admin bool, note string) (*models.LeaveRequest, error) {
+	req, err := l.reviewableRequest(ctx, collegeID, requestID, reviewerID, admin)
+	if err != nil {
+		return nil, err
+	}
+	if !admin {
+		teachesAll, err := l.courseRepo.TeachesAllCoursesOf(ctx, collegeID, reviewerID, req.StudentID)
+		if err != nil {
+			return nil, err
+		}
+		if !teachesAll {
+			return nil, ErrCrossCourseLeave
+		}
+	}
+
+	// Claim the request first so two reviewers cannot both apply it.
+	approved, err := l.leaveRepo.UpdateLeaveRequestStatus(ctx, collegeID, requestID, models.LeaveStatusPending, models.LeaveStatusApproved, &reviewerID, optionalNote(note))
+	if err != nil {
+		return nil, err
+	}
+	if !approved {
+		return nil, ErrLeaveNotPending
+	}
+
+	audit := models.AuditInfo{
+		ActorID: reviewerID,
+		Source:  models.AuditSourceLeave,
+		Reason:  fmt.Sprintf("leave request %d (%s)", req.ID, req.LeaveType),
+	}
+	excused, err := l.attendanceRepo.ExcuseAttendanceRange(ctx, collegeID, req.StudentID, req.StartDate, req.EndDate, audit)
+	if err != nil {
+		// Put the request back so it can be approved again once the
+		// attendance update goes through.
+		if _, revertErr := l.leaveRepo.UpdateLeaveRequestStatus(ctx, collegeID, requestID, models.LeaveStatusApproved, models.LeaveStatusPending, nil, nil); revertErr != nil {
+			return nil, fmt.Errorf("ApproveLeaveRequest: %w (reverting approval also failed: %v)", err, revertErr)
+		}
+		return nil, fmt.Errorf("ApproveLeaveRequest: %w", err)
+	}
+	if err := l.leaveRepo.SetExcusedLectures(ctx, collegeID, requestID, excused); err != nil {
+		return nil, err
+	}
+
+	return l.leaveRepo.GetLeaveRequestByID(ctx, collegeID, requestID)
+}
+
+func (l *leaveService) RejectLeaveRequest(ctx context.Context, collegeID int, requestID int, reviewerID string, admin bool, note string) (*models.LeaveRequest, error) {
+	if _, err := l.reviewableRequest(ctx, collegeID, requestID, reviewerID, admin); err != nil {
+		return nil, err
+	}
+	rejected, err := l.leaveRepo.UpdateLeaveRequestStatus(ctx, collegeID, requestID, models.LeaveStatusPending, models.LeaveStatusRejected, &reviewerID, optionalNote(note))
+	if err != nil {
+		return nil, err
+	}
+	if !rejected {
+		return nil, ErrLeaveNotPending
+	}
+	return l.leaveRepo.GetLeaveRequestByID(ctx, collegeID, requestID)
+}
+
+// reviewableRequest loads the request if the reviewer may review it: admins
+// review any request, faculty only those of students in their courses.
+func (l *leaveService) reviewableRequest(ctx context.Context, collegeID int, requestID int, reviewerID string, admin bool) (*models.LeaveRequest, error) {
+	req, err := l.leaveRepo.GetLeaveRequestByID(ctx, collegeID, requestID)
+	if err != nil || admin {
+		return req, err
+	}
+	teaches, err := l.courseRepo.TeachesStudent(ctx, collegeID, reviewerID, req.StudentID)
+	if err != nil {
+		return nil, err
+	}
+	if !teaches {
+		return nil, ErrNotReviewer
+	}
+	return req, nil
+}
+
+func optionalNote(note string) *string {
+	if note == "" {
+		return nil
+	}
+	return &note
+}
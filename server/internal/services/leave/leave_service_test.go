@@ -0,0 +1,179 @@
+package leave
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLeaveRepo struct {
+	repository.LeaveRequestRepository
+	requests map[int]*models.LeaveRequest
+}
+
+func (f *fakeLeaveRepo) GetLeaveRequestByID(ctx context.Context, collegeID int, requestID int) (*models.LeaveRequest, error) {
+	req, ok := f.requests[requestID]
+	if !ok || req.CollegeID != collegeID {
+		return nil, fmt.Errorf("GetLeaveRequestByID: %w", repository.ErrLeaveRequestNotFound)
+	}
+	copied := *req
+	return &copied, nil
+}
+
+func (f *fakeLeaveRepo) UpdateLeaveRequestStatus(ctx context.Context, collegeID int, requestID int, fromStatus, toStatus string, reviewedBy *string, note *string) (bool, error) {
+	req, ok := f.requests[requestID]
+	if !ok || req.CollegeID != collegeID || req.Status != fromStatus {
+		return false, nil
+	}
+	req.Status = toStatus
+	if reviewedBy != nil {
+		req.ReviewedBy = reviewedBy
+		req.ReviewNote = note
+	}
+	return true, nil
+}
+
+func (f *fakeLeaveRepo) SetExcusedLectures(ctx context.Context, collegeID int, requestID int, count int) error {
+	f.requests[requestID].ExcusedLectures = count
+	return nil
+}
+
+type fakeCourseRepo struct {
+	repository.CourseRepository
+	students map[string][]int // Active students by instructor identity
+}
+
+func (f *fakeCourseRepo) TeachesStudent(ctx context.Context, collegeID int, identityID string, studentID int) (bool, error) {
+	for _, id := range f.students[identityID] {
+		if id == studentID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeCourseRepo) TeachesAllCoursesOf(ctx context.Context, collegeID int, identityID string, studentID int) (bool, error) {
+	for other := range f.students {
+		if teaches, _ := f.TeachesStudent(ctx, collegeID, other, studentID); teaches && other != identityID {
+			return false, nil
+		}
+	}
+	return f.TeachesStudent(ctx, collegeID, identityID, studentID)
+}
+
+type fakeAttendanceRepo struct {
+	repository.AttendanceRepository
+	fail  bool
+	calls []models.AuditInfo
+}
+
+func (f *fakeAttendanceRepo) ExcuseAttendanceRange(ctx context.Context, collegeID int, studentID int, from, to time.Time, audit models.AuditInfo) (int, error) {
+	f.calls = append(f.calls, audit)
+	if f.fail {
+		return 0, errors.New("write failed")
+	}
+	return 3, nil
+}
+
+func newTestLeaveService(status string) (*leaveService, *fakeLeaveRepo, *fakeAttendanceRepo) {
+	start := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	leaveRepo := &fakeLeaveRepo{requests: map[int]*models.LeaveRequest{
+		1: {ID: 1, CollegeID: 10, StudentID: 7, LeaveType: models.LeaveTypeMedical, StartDate: start, EndDate: start.AddDate(0, 0, 2), Status: status},
+		2: {ID: 2, CollegeID: 10, StudentID: 8, LeaveType: models.LeaveTypeMedical, StartDate: start, EndDate: start.AddDate(0, 0, 2), Status: status},
+	}}
+	attendanceRepo := &fakeAttendanceRepo{}
+	courseRepo := &fakeCourseRepo{students: map[string][]int{"faculty-identity": {7, 8}, "other-faculty": {8}}}
+	svc := NewLeaveService(leaveRepo, attendanceRepo, courseRepo).(*leaveService)
+	return svc, leaveRepo, attendanceRepo
+}
+
+func TestApproveLeaveRequestExcusesAttendance(t *testing.T) {
+	svc, _, attendanceRepo := newTestLeaveService(models.LeaveStatusPending)
+
+	req, err := svc.ApproveLeaveRequest(context.Background(), 10, 1, "hod-identity", true, "certificate verified")
+	require.NoError(t, err)
+
+	assert.Equal(t, models.LeaveStatusApproved, req.Status)
+	assert.Equal(t, 3, req.ExcusedLectures)
+	require.Len(t, attendanceRepo.calls, 1)
+	assert.Equal(t, "hod-identity", attendanceRepo.calls[0].ActorID)
+	assert.Equal(t, models.AuditSourceLeave, attendanceRepo.calls[0].Source)
+}
+
+func TestApproveLeaveRequestOnlyOnce(t *testing.T) {
+	svc, _, attendanceRepo := newTestLeaveService(models.LeaveStatusRejected)
+
+	_, err := svc.ApproveLeaveRequest(context.Background(), 10, 1, "hod-identity", true, "")
+	assert.ErrorIs(t, err, ErrLeaveNotPending)
+	assert.Empty(t, attendanceRepo.calls)
+}
+
+func TestApproveLeaveRequestRevertsWhenAttendanceFails(t *testing.T) {
+	svc, leaveRepo, attendanceRepo := newTestLeaveService(models.LeaveStatusPending)
+	attendanceRepo.fail = true
+
+	_, err := svc.ApproveLeaveRequest(context.Background(), 10, 1, "hod-identity", true, "")
+	require.Error(t, err)
+	assert.Equal(t, models.LeaveStatusPending, leaveRepo.requests[1].Status)
+}
+
+func TestCancelLeaveRequestChecksOwner(t *testing.T) {
+	svc, leaveRepo, _ := newTestLeaveService(models.LeaveStatusPending)
+
+	err := svc.CancelLeaveRequest(context.Background(), 10, 8, 1)
+	assert.ErrorIs(t, err, ErrNotRequestOwner)
+
+	require.NoError(t, svc.CancelLeaveRequest(context.Background(), 10, 7, 1))
+	assert.Equal(t, models.LeaveStatusCancelled, leaveRepo.requests[1].Status)
+}
+
+func TestSubmitLeaveRequestRejectsInvertedRange(t *testing.T) {
+	svc, _, _ := newTestLeaveService(models.LeaveStatusPending)
+	start := time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)
+
+	err := svc.SubmitLeaveRequest(context.Background(), &models.LeaveRequest{
+		CollegeID: 10, StudentID: 7, LeaveType: models.LeaveTypeSports,
+		StartDate: start, EndDate: start.AddDate(0, 0, -1), Reason: "inter-college meet",
+	})
+	assert.ErrorIs(t, err, ErrInvalidDateRange)
+}
+
+func TestReviewLeaveRequestNeedsStudentsFaculty(t *testing.T) {
+	svc, leaveRepo, attendanceRepo := newTestLeaveService(models.LeaveStatusPending)
+	ctx := context.Background()
+
+	_, err := svc.ApproveLeaveRequest(ctx, 10, 1, "other-faculty", false, "")
+	assert.ErrorIs(t, err, ErrNotReviewer)
+	_, err = svc.RejectLeaveRequest(ctx, 10, 1, "other-faculty", false, "")
+	assert.ErrorIs(t, err, ErrNotReviewer)
+	assert.Equal(t, models.LeaveStatusPending, leaveRepo.requests[1].Status)
+	assert.Empty(t, attendanceRepo.calls)
+
+	req, err := svc.ApproveLeaveRequest(ctx, 10, 1, "faculty-identity", false, "")
+	require.NoError(t, err)
+	assert.Equal(t, models.LeaveStatusApproved, req.Status)
+}
+
+func TestApproveLeaveRequestAcrossCoursesNeedsAdmin(t *testing.T) {
+	svc, leaveRepo, attendanceRepo := newTestLeaveService(models.LeaveStatusPending)
+	ctx := context.Background()
+
+	// Student 8 is also taught by other-faculty, whose lectures would be excused too
+	_, err := svc.ApproveLeaveRequest(ctx, 10, 2, "faculty-identity", false, "")
+	assert.ErrorIs(t, err, ErrCrossCourseLeave)
+	assert.Equal(t, models.LeaveStatusPending, leaveRepo.requests[2].Status)
+	assert.Empty(t, attendanceRepo.calls)
+
+	// Faculty may still turn it down
+	req, err := svc.RejectLeaveRequest(ctx, 10, 2, "faculty-identity", false, "")
+	require.NoError(t, err)
+	assert.Equal(t, models.LeaveStatusRejected, req.Status)
+}
@@ -0,0 +1,145 @@
+package lecture
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"eduhub/server/internal/models"
+)
+
+// MaxGenerationDays caps the date range one generation request may cover.
+const MaxGenerationDays = 366
+
+var (
+	ErrNoTimetable            = errors.New("course has no timetable blocks")
+	ErrNoGenerationRange      = errors.New("no date range given and no term to take it from")
+	ErrInvalidGenerationRange = fmt.Errorf("generation range must not end before it starts or span more than %d days", MaxGenerationDays)
+)
+
+func (l *lectureService) GenerateLectures(ctx context.Context, collegeID, courseID int, termID *int, from, to time.Time, loc *time.Location) (*models.LectureGeneration, error) {
+	course, err := l.courseRepo.FindCourseByID(ctx, collegeID, courseID)
+	if err != nil {
+		return nil, err
+	}
+	termID, err = l.termOrCurrent(ctx, collegeID, termID)
+	if err != nil {
+		return nil, err
+	}
+	if from.IsZero() || to.IsZero() {
+		if termID == nil {
+			return nil, ErrNoGenerationRange
+		}
+		term, err := l.termRepo.GetTermByID(ctx, collegeID, *termID)
+		if err != nil {
+			return nil, err
+		}
+		if from.IsZero() {
+			from = term.StartDate
+		}
+		if to.IsZero() {
+			to = term.EndDate
+		}
+	}
+	from, to = dateIn(from, loc), dateIn(to, loc)
+	if to.Before(from) || to.After(from.AddDate(0, 0, MaxGenerationDays)) {
+		return nil, ErrInvalidGenerationRange
+	}
+
+	blocks, err := l.timetableRepo.GetTimeTableBlocks(ctx, models.TimeTableBlockFilter{
+		CollegeID: collegeID,
+		CourseID:  &courseID,
+		TermID:    termID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) == 0 {
+		return nil, ErrNoTimetable
+	}
+
+	holidayType := models.EventTypeHoliday
+	firstDay, lastDay := dateIn(from, time.UTC), dateIn(to, time.UTC)
+	events, err := l.calendarRepo.GetCalendarBlocks(ctx, models.CalendarBlockFilter{
+		CollegeID: &collegeID,
+		CourseID:  &courseID,
+		EventType: &holidayType,
+		StartDate: &firstDay,
+		EndDate:   &lastDay,
+	})
+	if err != nil {
+		return nil, err
+	}
+	holidays := make(map[string]bool, len(events))
+	for _, event := range events {
+		for day := event.Date; !day.After(event.EndDate); day = day.AddDate(0, 0, 1) {
+			holidays[day.Format(time.DateOnly)] = true
+		}
+	}
+
+	lectures, skipped := scheduleLectures(course, blocks, holidays, from, to)
+	for _, lecture := range lectures {
+		lecture.TermID = termID
+	}
+	created, err := l.lectureRepo.CreateGeneratedLectures(ctx, lectures)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LectureGeneration{
+		Created:  created,
+		Existing: len(lectures) - len(created),
+		Holidays: skipped,
+	}, nil
+}
+
+// scheduleLectures returns a lecture for every slot of blocks on the days from
+// from through to, in from's location, except on holidays (keyed YYYY-MM-DD).
+// It also returns the holidays that cancelled at least one slot.
+func scheduleLectures(course *models.Course, blocks []*models.TimeTableBlock, holidays map[string]bool, from, to time.Time) ([]*models.Lecture, []string) {
+	var (
+		lectures []*models.Lecture
+		skipped  []string
+	)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+		cancelled := false
+		for _, block := range blocks {
+			if block.DayOfWeek != day.Weekday() {
+				continue
+			}
+			if holidays[date] {
+				cancelled = true
+				continue
+			}
+			lecture := &models.Lecture{
+				CourseID:         course.ID,
+				CollegeID:        course.CollegeID,
+				TimetableBlockID: &block.ID,
+				Title:            course.Name,
+				StartTime:        clockOn(day, block.StartTime.Microseconds),
+				EndTime:          clockOn(day, block.EndTime.Microseconds),
+			}
+			if block.RoomNumber != nil {
+				lecture.Description = "Room " + *block.RoomNumber
+			}
+			lectures = append(lectures, lecture)
+		}
+		if cancelled {
+			skipped = append(skipped, date)
+		}
+	}
+	return lectures, skipped
+}
+
+// dateIn returns midnight of t's calendar date in loc.
+func dateIn(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// clockOn returns the wall-clock time micros after midnight on day, so DST
+// changes do not shift lectures.
+func clockOn(day time.Time, micros int64) time.Time {
+	seconds := micros / 1e6
+	return time.Date(day.Year(), day.Month(), day.Day(), int(seconds/3600), int(seconds/60%60), int(seconds%60), 0, day.Location())
+}
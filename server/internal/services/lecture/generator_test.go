@@ -0,0 +1,159 @@
+package lecture
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func clock(h, m int) pgtype.Time {
+	return pgtype.Time{Microseconds: int64(h*3600+m*60) * 1e6, Status: pgtype.Present}
+}
+
+var (
+	algorithms = &models.Course{ID: 7, CollegeID: 1, Name: "Algorithms"}
+	room       = "B-204"
+	// Mondays 09:00-10:00 and Wednesdays 14:30-16:00
+	algorithmsBlocks = []*models.TimeTableBlock{
+		{ID: 1, CollegeID: 1, CourseID: 7, DayOfWeek: time.Monday, StartTime: clock(9, 0), EndTime: clock(10, 0), RoomNumber: &room},
+		{ID: 2, CollegeID: 1, CourseID: 7, DayOfWeek: time.Wednesday, StartTime: clock(14, 30), EndTime: clock(16, 0)},
+	}
+)
+
+func TestScheduleLectures(t *testing.T) {
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+	// Monday 2026-01-05 through Monday 2026-01-12, with Wednesday a holiday
+	from := time.Date(2026, 1, 5, 0, 0, 0, 0, kolkata)
+	to := time.Date(2026, 1, 12, 0, 0, 0, 0, kolkata)
+
+	lectures, skipped := scheduleLectures(algorithms, algorithmsBlocks, map[string]bool{"2026-01-07": true}, from, to)
+
+	require.Len(t, lectures, 2)
+	assert.Equal(t, []string{"2026-01-07"}, skipped)
+	for i, want := range []time.Time{
+		time.Date(2026, 1, 5, 9, 0, 0, 0, kolkata),
+		time.Date(2026, 1, 12, 9, 0, 0, 0, kolkata),
+	} {
+		assert.True(t, want.Equal(lectures[i].StartTime), "lecture %d starts %s", i, lectures[i].StartTime)
+		assert.True(t, want.Add(time.Hour).Equal(lectures[i].EndTime))
+		assert.Equal(t, 1, *lectures[i].TimetableBlockID)
+		assert.Equal(t, "Algorithms", lectures[i].Title)
+		assert.Equal(t, "Room B-204", lectures[i].Description)
+		assert.Equal(t, 7, lectures[i].CourseID)
+	}
+}
+
+func TestScheduleLecturesKeepsWallClockAcrossDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	// Clocks go forward on Sunday 2026-03-08
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, newYork)
+	to := time.Date(2026, 3, 9, 0, 0, 0, 0, newYork)
+
+	lectures, _ := scheduleLectures(algorithms, algorithmsBlocks[:1], nil, from, to)
+
+	require.Len(t, lectures, 2)
+	for _, l := range lectures {
+		assert.Equal(t, 9, l.StartTime.Hour())
+		assert.Equal(t, 10, l.EndTime.Hour())
+	}
+}
+
+type fakeCourseRepo struct{ repository.CourseRepository }
+
+func (f *fakeCourseRepo) FindCourseByID(ctx context.Context, collegeID int, courseID int) (*models.Course, error) {
+	return algorithms, nil
+}
+
+type fakeTermRepo struct {
+	repository.AcademicTermRepository
+}
+
+func (f *fakeTermRepo) CurrentTermID(ctx context.Context, collegeID int) (*int, error) {
+	id := 3
+	return &id, nil
+}
+
+func (f *fakeTermRepo) GetTermByID(ctx context.Context, collegeID int, termID int) (*models.AcademicTerm, error) {
+	return &models.AcademicTerm{
+		ID:        termID,
+		StartDate: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, 1, 18, 0, 0, 0, 0, time.UTC),
+	}, nil
+}
+
+type fakeTimetableRepo struct {
+	repository.TimeTableRepository
+	filter models.TimeTableBlockFilter
+}
+
+func (f *fakeTimetableRepo) GetTimeTableBlocks(ctx context.Context, filter models.TimeTableBlockFilter) ([]*models.TimeTableBlock, error) {
+	f.filter = filter
+	return algorithmsBlocks, nil
+}
+
+type fakeCalendarRepo struct{ repository.CalendarRepository }
+
+func (f *fakeCalendarRepo) GetCalendarBlocks(ctx context.Context, filter models.CalendarBlockFilter) ([]*models.CalendarBlock, error) {
+	holiday := time.Date(2026, 1, 14, 0, 0, 0, 0, time.UTC)
+	return []*models.CalendarBlock{{Date: holiday, EndDate: holiday, EventType: models.EventTypeHoliday}}, nil
+}
+
+type fakeLectureRepo struct {
+	repository.LectureRepository
+	generated map[time.Time]bool
+}
+
+func (f *fakeLectureRepo) CreateGeneratedLectures(ctx context.Context, lectures []*models.Lecture) ([]*models.Lecture, error) {
+	created := []*models.Lecture{}
+	for _, l := range lectures {
+		if !f.generated[l.StartTime] {
+			f.generated[l.StartTime] = true
+			created = append(created, l)
+		}
+	}
+	return created, nil
+}
+
+func TestGenerateLecturesDefaultsToCurrentTerm(t *testing.T) {
+	timetable := &fakeTimetableRepo{}
+	lectures := &fakeLectureRepo{generated: map[time.Time]bool{}}
+	svc := NewLectureService(lectures, &fakeTermRepo{}, &fakeCourseRepo{}, timetable, &fakeCalendarRepo{})
+
+	result, err := svc.GenerateLectures(context.Background(), 1, 7, nil, time.Time{}, time.Time{}, time.UTC)
+	require.NoError(t, err)
+
+	// Two weeks of Mondays and Wednesdays, less the Wednesday holiday
+	assert.Len(t, result.Created, 3)
+	assert.Equal(t, 0, result.Existing)
+	assert.Equal(t, []string{"2026-01-14"}, result.Holidays)
+	require.NotNil(t, timetable.filter.TermID)
+	assert.Equal(t, 3, *timetable.filter.TermID)
+	for _, l := range result.Created {
+		assert.Equal(t, 3, *l.TermID)
+	}
+
+	again, err := svc.GenerateLectures(context.Background(), 1, 7, nil, time.Time{}, time.Time{}, time.UTC)
+	require.NoError(t, err)
+	assert.Empty(t, again.Created)
+	assert.Equal(t, 3, again.Existing)
+}
+
+func TestGenerateLecturesRejectsBadRange(t *testing.T) {
+	svc := NewLectureService(&fakeLectureRepo{}, &fakeTermRepo{}, &fakeCourseRepo{}, &fakeTimetableRepo{}, &fakeCalendarRepo{})
+	from := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	_, err := svc.GenerateLectures(context.Background(), 1, 7, nil, from, from.AddDate(0, 0, -1), time.UTC)
+	assert.ErrorIs(t, err, ErrInvalidGenerationRange)
+
+	_, err = svc.GenerateLectures(context.Background(), 1, 7, nil, from, from.AddDate(2, 0, 0), time.UTC)
+	assert.ErrorIs(t, err, ErrInvalidGenerationRange)
+}
@@ -5,6 +5,7 @@ import (
 	"eduhub/server/internal/models"
 	"eduhub/server/internal/repository"
 	"fmt"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -16,19 +17,35 @@ type LectureService interface {
 	DeleteLecture(ctx context.Context, collegeID int, lectureID int) error
 
 	// Finder methods
-	FindLecturesByCourse(ctx context.Context, collegeID int, courseID int, limit, offset uint64) ([]*models.Lecture, error)
-	CountLecturesByCourse(ctx context.Context, collegeID int, courseID int) (int, error)
+	// FindLecturesByCourse and CountLecturesByCourse consider lectures in
+	// termID, or in the college's current term if termID is nil.
+	FindLecturesByCourse(ctx context.Context, collegeID int, courseID int, termID *int, limit, offset uint64) ([]*models.Lecture, error)
+	CountLecturesByCourse(ctx context.Context, collegeID int, courseID int, termID *int) (int, error)
+
+	// GenerateLectures creates the course's lectures for every timetable slot
+	// of the term (the current one if termID is nil) between from and to,
+	// skipping holidays. Dates are taken in loc, and a zero from or to
+	// defaults to the term's start or end. Generating twice is harmless.
+	GenerateLectures(ctx context.Context, collegeID, courseID int, termID *int, from, to time.Time, loc *time.Location) (*models.LectureGeneration, error)
 }
 
 type lectureService struct {
-	lectureRepo repository.LectureRepository
-	validate    validator.Validate
+	lectureRepo   repository.LectureRepository
+	termRepo      repository.AcademicTermRepository
+	courseRepo    repository.CourseRepository
+	timetableRepo repository.TimeTableRepository
+	calendarRepo  repository.CalendarRepository
+	validate      validator.Validate
 }
 
-func NewLectureService(lectureRepo repository.LectureRepository) LectureService {
+func NewLectureService(lectureRepo repository.LectureRepository, termRepo repository.AcademicTermRepository, courseRepo repository.CourseRepository, timetableRepo repository.TimeTableRepository, calendarRepo repository.CalendarRepository) LectureService {
 	return &lectureService{
-		lectureRepo: lectureRepo,
-		validate:    *validator.New(),
+		lectureRepo:   lectureRepo,
+		termRepo:      termRepo,
+		courseRepo:    courseRepo,
+		timetableRepo: timetableRepo,
+		calendarRepo:  calendarRepo,
+		validate:      *validator.New(),
 	}
 }
 
@@ -64,10 +81,26 @@ func (l *lectureService) DeleteLecture(ctx context.Context, collegeID int, lectu
 	return l.lectureRepo.DeleteLecture(ctx, collegeID, lectureID)
 }
 
-func (l *lectureService) FindLecturesByCourse(ctx context.Context, collegeID int, courseID int, limit, offset uint64) ([]*models.Lecture, error) {
-	return l.lectureRepo.FindLecturesByCourse(ctx, collegeID, courseID, limit, offset)
+func (l *lectureService) FindLecturesByCourse(ctx context.Context, collegeID int, courseID int, termID *int, limit, offset uint64) ([]*models.Lecture, error) {
+	termID, err := l.termOrCurrent(ctx, collegeID, termID)
+	if err != nil {
+		return nil, err
+	}
+	return l.lectureRepo.FindLecturesByCourse(ctx, collegeID, courseID, termID, limit, offset)
+}
+
+func (l *lectureService) CountLecturesByCourse(ctx context.Context, collegeID int, courseID int, termID *int) (int, error) {
+	termID, err := l.termOrCurrent(ctx, collegeID, termID)
+	if err != nil {
+		return 0, err
+	}
+	return l.lectureRepo.CountLecturesByCourse(ctx, collegeID, courseID, termID)
 }
 
-func (l *lectureService) CountLecturesByCourse(ctx context.Context, collegeID int, courseID int) (int, error) {
-	return l.lectureRepo.CountLecturesByCourse(ctx, collegeID, courseID)
+// termOrCurrent returns termID, or the college's current term if it is nil.
+func (l *lectureService) termOrCurrent(ctx context.Context, collegeID int, termID *int) (*int, error) {
+	if termID != nil {
+		return termID, nil
+	}
+	return l.termRepo.CurrentTermID(ctx, collegeID)
 }
@@ -3,14 +3,20 @@ package services
 import (
 	"eduhub/server/internal/config"
 	"eduhub/server/internal/repository"
+	"eduhub/server/internal/services/calendar"
 	"eduhub/server/internal/services/college"
 	"eduhub/server/internal/services/course"
+	"eduhub/server/internal/services/enrollment"
 	"eduhub/server/internal/services/grades"
+	"eduhub/server/internal/services/leave"
 	"eduhub/server/internal/services/lecture"
 	"eduhub/server/internal/services/attendance"
 	"eduhub/server/internal/services/auth"
 	"eduhub/server/internal/services/quiz" // Added Quiz service import
 	"eduhub/server/internal/services/student"
+	"eduhub/server/internal/services/suspension"
+	"eduhub/server/internal/services/term"
+	"eduhub/server/internal/services/timetable"
 )
 
 type Services struct {
@@ -19,9 +25,20 @@ type Services struct {
 	StudentService student.StudentService
 	CollegeService college.CollegeService
 	CourseService  course.CourseService
+	Enrollment     enrollment.EnrollmentService
 	GradeService   grades.GradeServices
 	LectureService lecture.LectureService
 	QuizService    quiz.QuizService // Added QuizService field
+	LeaveService   leave.LeaveService
+	Suspension     suspension.SuspensionService
+	Term           term.TermService
+	Timetable      timetable.TimetableService
+	Calendar       calendar.CalendarService
+	CalendarFeed   calendar.CalendarFeedService
+
+	// Background jobs, started by the app
+	AbsenteeScheduler   *attendance.AbsenteeScheduler
+	SuspensionScheduler *suspension.Scheduler
 
 	// Fee *Fee.FeeService
 }
@@ -40,12 +57,23 @@ func NewServices(cfg *config.Config) *Services {
 		repo.GradeRepository,   // Added GradeRepository
 	)
 	// systemService := system.NewSystemService(cfg.DB)
-	attendanceService := attendance.NewAttendanceService(repo.AttendanceRepository, repo.StudentRepository, repo.EnrollmentRepository)
+	qrSigner := attendance.NewQRTokenSigner(cfg.QRConfig.SigningKey, cfg.QRConfig.RotationPeriod)
+	attendanceService := attendance.NewAttendanceService(repo.AttendanceRepository, repo.StudentRepository, repo.EnrollmentRepository, repo.LectureRepository, repo.QRCodeRepository, repo.AttendanceScanRepository, repo.StudentDeviceRepository, repo.AttendancePolicyRepository, qrSigner)
 	collegeService := college.NewCollegeService(repo.CollegeRepository)
-	courseService := course.NewCourseService(repo.CourseRepository)
-	gradeService := grades.NewGradeServices(repo.GradeRepository, repo.StudentRepository, repo.EnrollmentRepository, repo.CourseRepository)
-	lectureService := lecture.NewLectureService(repo.LectureRepository)
+	assigner := auth.NewAssigner(ketoService)
+	courseService := course.NewCourseService(repo.CourseRepository, repo.UserRepository, repo.EnrollmentRepository, repo.CoursePrerequisiteRepository, assigner)
+	enrollmentService := enrollment.NewEnrollmentService(repo.EnrollmentRepository, repo.StudentRepository, repo.GradeRepository, repo.CoursePrerequisiteRepository, repo.AcademicTermRepository, repo.GradingScaleRepository)
+	gradeService := grades.NewGradeServices(repo.GradeRepository, repo.StudentRepository, repo.EnrollmentRepository, repo.CourseRepository, repo.AcademicTermRepository, repo.AssessmentRepository, repo.GradingScaleRepository, repo.ResultRepository, repo.GradeCurveRepository, repo.ResultPublicationRepository, repo.ReevaluationRepository)
+	lectureService := lecture.NewLectureService(repo.LectureRepository, repo.AcademicTermRepository, repo.CourseRepository, repo.TimeTableRepository, repo.CalendarRepository)
 	quizService := quiz.NewQuizService(repo.QuizRepository) // Initialize QuizService
+	leaveService := leave.NewLeaveService(repo.LeaveRequestRepository, repo.AttendanceRepository, repo.CourseRepository)
+	suspensionService := suspension.NewSuspensionService(repo.StudentSuspensionRepository)
+	termService := term.NewTermService(repo.AcademicTermRepository)
+	calendarService := calendar.NewCalendarService(repo.CalendarRepository, repo.CourseRepository)
+	calendarFeedService := calendar.NewCalendarFeedService(repo.CalendarFeedRepository, repo.StudentRepository, repo.AcademicTermRepository)
+	timetableService := timetable.NewTimetableService(repo.TimeTableRepository, repo.TimetableDraftRepository, repo.CourseRepository, repo.AcademicTermRepository)
+	absenteeScheduler := attendance.NewAbsenteeScheduler(repo.AttendanceRepository, cfg.Scheduler.AbsenteeInterval, cfg.Scheduler.AbsenteeBatchSize)
+	suspensionScheduler := suspension.NewScheduler(repo.StudentSuspensionRepository, cfg.Scheduler.SuspensionInterval, cfg.Scheduler.SuspensionBatchSize)
 
 	return &Services{
 		Auth:           authService,
@@ -53,8 +81,18 @@ func NewServices(cfg *config.Config) *Services {
 		StudentService: studentService,
 		CollegeService: collegeService,
 		CourseService:  courseService,
+		Enrollment:     enrollmentService,
 		GradeService:   gradeService,
 		LectureService: lectureService,
 		QuizService:    quizService, // Add QuizService to the struct
+		LeaveService:   leaveService,
+		Suspension:     suspensionService,
+		Term:           termService,
+		Timetable:      timetableService,
+		Calendar:       calendarService,
+		CalendarFeed:   calendarFeedService,
+
+		AbsenteeScheduler:   absenteeScheduler,
+		SuspensionScheduler: suspensionScheduler,
 	}
 }
@@ -0,0 +1,79 @@
+package suspension
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"eduhub/server/internal/repository"
+)
+
+// Scheduler starts suspensions on their start date and lifts them after their
+// end date. It is safe to run on every replica: rows are claimed with
+// SKIP LOCKED and each transition happens once.
+type Scheduler struct {
+	repo      repository.StudentSuspensionRepository
+	interval  time.Duration
+	batchSize int
+	now       func() time.Time
+}
+
+func NewScheduler(repo repository.StudentSuspensionRepository, interval time.Duration, batchSize int) *Scheduler {
+	return &Scheduler{
+		repo:      repo,
+		interval:  interval,
+		batchSize: batchSize,
+		now:       time.Now,
+	}
+}
+
+// Run applies due transitions every interval until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		activated, lifted, err := s.RunOnce(ctx)
+		if err != nil {
+			log.Printf("suspension job: %v", err)
+		} else if activated > 0 || lifted > 0 {
+			log.Printf("suspension job: started %d suspensions, lifted %d", activated, lifted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce starts every suspension that is due and lifts every one that has
+// ended, one batch per transaction.
+func (s *Scheduler) RunOnce(ctx context.Context) (int, int, error) {
+	today := dateOf(s.now())
+	activated, err := s.drain(ctx, func() (int, error) {
+		return s.repo.ActivateDueSuspensions(ctx, today, uint64(s.batchSize))
+	})
+	if err != nil {
+		return activated, 0, err
+	}
+	lifted, err := s.drain(ctx, func() (int, error) {
+		return s.repo.LiftExpiredSuspensions(ctx, today, uint64(s.batchSize))
+	})
+	return activated, lifted, err
+}
+
+func (s *Scheduler) drain(ctx context.Context, batch func() (int, error)) (int, error) {
+	total := 0
+	for ctx.Err() == nil {
+		n, err := batch()
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < s.batchSize {
+			break
+		}
+	}
+	return total, nil
+}
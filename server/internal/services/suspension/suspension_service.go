@@ -0,0 +1,84 @@
+package suspension
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrSuspensionAlreadyLifted = errors.New("suspension has already been lifted")
+	ErrInvalidSuspensionPeriod = errors.New("suspension must not end before it starts")
+)
+
+type SuspensionService interface {
+	// SuspendStudent freezes the student, their attendance and their
+	// enrollments from StartsOn (today if unset) until lifted or EndsOn passes.
+	SuspendStudent(ctx context.Context, suspension *models.StudentSuspension) error
+	GetSuspension(ctx context.Context, collegeID int, suspensionID int) (*models.StudentSuspension, error)
+	GetSuspensions(ctx context.Context, collegeID int, studentID int, status string, limit, offset uint64) ([]*models.StudentSuspension, error)
+	LiftSuspension(ctx context.Context, collegeID int, suspensionID int, liftedBy string, reason string) (*models.StudentSuspension, error)
+}
+
+type suspensionService struct {
+	suspensionRepo repository.StudentSuspensionRepository
+	validate       validator.Validate
+	now            func() time.Time
+}
+
+func NewSuspensionService(suspensionRepo repository.StudentSuspensionRepository) SuspensionService {
+	return &suspensionService{
+		suspensionRepo: suspensionRepo,
+		validate:       *validator.New(),
+		now:            time.Now,
+	}
+}
+
+func (s *suspensionService) SuspendStudent(ctx context.Context, suspension *models.StudentSuspension) error {
+	if err := s.validate.Struct(suspension); err != nil {
+		return fmt.Errorf("validation failed %w", err)
+	}
+	if suspension.SuspendedBy == "" {
+		return fmt.Errorf("suspending user is required")
+	}
+	today := dateOf(s.now())
+	if suspension.StartsOn.IsZero() {
+		suspension.StartsOn = today
+	}
+	if suspension.EndsOn != nil && suspension.EndsOn.Before(suspension.StartsOn) {
+		return ErrInvalidSuspensionPeriod
+	}
+	return s.suspensionRepo.CreateSuspension(ctx, suspension, today)
+}
+
+func (s *suspensionService) GetSuspension(ctx context.Context, collegeID int, suspensionID int) (*models.StudentSuspension, error) {
+	return s.suspensionRepo.GetSuspensionByID(ctx, collegeID, suspensionID)
+}
+
+func (s *suspensionService) GetSuspensions(ctx context.Context, collegeID int, studentID int, status string, limit, offset uint64) ([]*models.StudentSuspension, error) {
+	return s.suspensionRepo.FindSuspensions(ctx, collegeID, studentID, status, limit, offset)
+}
+
+func (s *suspensionService) LiftSuspension(ctx context.Context, collegeID int, suspensionID int, liftedBy string, reason string) (*models.StudentSuspension, error) {
+	lifted, err := s.suspensionRepo.LiftSuspension(ctx, collegeID, suspensionID, liftedBy, reason)
+	if err != nil {
+		return nil, err
+	}
+	if !lifted {
+		return nil, ErrSuspensionAlreadyLifted
+	}
+	return s.suspensionRepo.GetSuspensionByID(ctx, collegeID, suspensionID)
+}
+
+// dateOf drops the time of day, returning t's calendar date, read in t's
+// location, as midnight UTC.
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,101 @@
+package suspension
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSuspensionRepo struct {
+	repository.StudentSuspensionRepository
+	created  []*models.StudentSuspension
+	today    time.Time
+	due      int
+	expired  int
+	batches  []string
+	liftable bool
+}
+
+func (f *fakeSuspensionRepo) CreateSuspension(ctx context.Context, s *models.StudentSuspension, today time.Time) error {
+	f.created = append(f.created, s)
+	f.today = today
+	return nil
+}
+
+func (f *fakeSuspensionRepo) LiftSuspension(ctx context.Context, collegeID int, suspensionID int, liftedBy string, reason string) (bool, error) {
+	return f.liftable, nil
+}
+
+func (f *fakeSuspensionRepo) ActivateDueSuspensions(ctx context.Context, today time.Time, limit uint64) (int, error) {
+	f.batches = append(f.batches, "activate")
+	n := min(f.due, int(limit))
+	f.due -= n
+	return n, nil
+}
+
+func (f *fakeSuspensionRepo) LiftExpiredSuspensions(ctx context.Context, today time.Time, limit uint64) (int, error) {
+	f.batches = append(f.batches, "lift")
+	n := min(f.expired, int(limit))
+	f.expired -= n
+	return n, nil
+}
+
+var testNow = time.Date(2026, 3, 4, 15, 30, 0, 0, time.UTC)
+
+func newTestSuspensionService(repo *fakeSuspensionRepo) *suspensionService {
+	svc := NewSuspensionService(repo).(*suspensionService)
+	svc.now = func() time.Time { return testNow }
+	return svc
+}
+
+func TestSuspendStudentStartsTodayByDefault(t *testing.T) {
+	repo := &fakeSuspensionRepo{}
+	svc := newTestSuspensionService(repo)
+
+	s := &models.StudentSuspension{CollegeID: 10, StudentID: 7, Reason: "disciplinary hearing", SuspendedBy: "admin-identity"}
+	require.NoError(t, svc.SuspendStudent(context.Background(), s))
+
+	today := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
+	require.Len(t, repo.created, 1)
+	assert.Equal(t, today, s.StartsOn)
+	assert.Equal(t, today, repo.today)
+}
+
+func TestSuspendStudentRejectsInvertedPeriod(t *testing.T) {
+	repo := &fakeSuspensionRepo{}
+	svc := newTestSuspensionService(repo)
+	start := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, -1)
+
+	err := svc.SuspendStudent(context.Background(), &models.StudentSuspension{
+		CollegeID: 10, StudentID: 7, Reason: "fee default", SuspendedBy: "admin-identity",
+		StartsOn: start, EndsOn: &end,
+	})
+	assert.ErrorIs(t, err, ErrInvalidSuspensionPeriod)
+	assert.Empty(t, repo.created)
+}
+
+func TestLiftSuspensionOnlyOnce(t *testing.T) {
+	svc := newTestSuspensionService(&fakeSuspensionRepo{liftable: false})
+
+	_, err := svc.LiftSuspension(context.Background(), 10, 1, "admin-identity", "appeal upheld")
+	assert.ErrorIs(t, err, ErrSuspensionAlreadyLifted)
+}
+
+func TestSchedulerDrainsBothQueuesInBatches(t *testing.T) {
+	repo := &fakeSuspensionRepo{due: 5, expired: 2}
+	scheduler := NewScheduler(repo, time.Minute, 2)
+
+	activated, lifted, err := scheduler.RunOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, activated)
+	assert.Equal(t, 2, lifted)
+	assert.Equal(t, []string{"activate", "activate", "activate", "lift", "lift"}, repo.batches)
+}
@@ -0,0 +1,95 @@
+package term
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrInvalidTermDates          = errors.New("term must not end before it starts")
+	ErrInvalidRegistrationWindow = errors.New("registration must not close before it opens or after the term ends")
+)
+
+type TermService interface {
+	// CreateTerm stores the term; if it is current it replaces the college's
+	// current term.
+	CreateTerm(ctx context.Context, term *models.AcademicTerm) error
+	GetTerm(ctx context.Context, collegeID int, termID int) (*models.AcademicTerm, error)
+	GetTerms(ctx context.Context, collegeID int, limit, offset uint64) ([]*models.AcademicTerm, error)
+	GetCurrentTerm(ctx context.Context, collegeID int) (*models.AcademicTerm, error)
+	UpdateTerm(ctx context.Context, term *models.AcademicTerm) error
+	DeleteTerm(ctx context.Context, collegeID int, termID int) error
+	SetCurrentTerm(ctx context.Context, collegeID int, termID int) (*models.AcademicTerm, error)
+}
+
+type termService struct {
+	termRepo repository.AcademicTermRepository
+	validate validator.Validate
+}
+
+func NewTermService(termRepo repository.AcademicTermRepository) TermService {
+	return &termService{
+		termRepo: termRepo,
+		validate: *validator.New(),
+	}
+}
+
+func (s *termService) CreateTerm(ctx context.Context, term *models.AcademicTerm) error {
+	if err := s.validateTerm(term); err != nil {
+		return err
+	}
+	return s.termRepo.CreateTerm(ctx, term)
+}
+
+func (s *termService) GetTerm(ctx context.Context, collegeID int, termID int) (*models.AcademicTerm, error) {
+	return s.termRepo.GetTermByID(ctx, collegeID, termID)
+}
+
+func (s *termService) GetTerms(ctx context.Context, collegeID int, limit, offset uint64) ([]*models.AcademicTerm, error) {
+	return s.termRepo.FindTerms(ctx, collegeID, limit, offset)
+}
+
+func (s *termService) GetCurrentTerm(ctx context.Context, collegeID int) (*models.AcademicTerm, error) {
+	return s.termRepo.GetCurrentTerm(ctx, collegeID)
+}
+
+func (s *termService) UpdateTerm(ctx context.Context, term *models.AcademicTerm) error {
+	if err := s.validateTerm(term); err != nil {
+		return err
+	}
+	return s.termRepo.UpdateTerm(ctx, term)
+}
+
+func (s *termService) DeleteTerm(ctx context.Context, collegeID int, termID int) error {
+	return s.termRepo.DeleteTerm(ctx, collegeID, termID)
+}
+
+func (s *termService) SetCurrentTerm(ctx context.Context, collegeID int, termID int) (*models.AcademicTerm, error) {
+	if err := s.termRepo.SetCurrentTerm(ctx, collegeID, termID); err != nil {
+		return nil, err
+	}
+	return s.termRepo.GetTermByID(ctx, collegeID, termID)
+}
+
+func (s *termService) validateTerm(term *models.AcademicTerm) error {
+	if err := s.validate.Struct(term); err != nil {
+		return fmt.Errorf("validation failed %w", err)
+	}
+	if term.StartDate.IsZero() || term.EndDate.Before(term.StartDate) {
+		return ErrInvalidTermDates
+	}
+	opens, closes := term.RegistrationOpensOn, term.RegistrationClosesOn
+	if opens != nil && closes != nil && closes.Before(*opens) {
+		return ErrInvalidRegistrationWindow
+	}
+	if closes != nil && closes.After(term.EndDate) {
+		return ErrInvalidRegistrationWindow
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package term
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTermRepo struct {
+	repository.AcademicTermRepository
+	created []*models.AcademicTerm
+}
+
+func (f *fakeTermRepo) CreateTerm(ctx context.Context, term *models.AcademicTerm) error {
+	f.created = append(f.created, term)
+	return nil
+}
+
+func date(s string) *time.Time {
+	d, _ := time.Parse(time.DateOnly, s)
+	return &d
+}
+
+func TestCreateTermValidatesDates(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end string
+		opens      *time.Time
+		closes     *time.Time
+		want       error
+	}{
+		{name: "valid", start: "2026-08-01", end: "2026-12-15", opens: date("2026-07-01"), closes: date("2026-08-14")},
+		{name: "single day", start: "2026-08-01", end: "2026-08-01"},
+		{name: "ends before start", start: "2026-08-01", end: "2026-07-31", want: ErrInvalidTermDates},
+		{name: "registration closes before it opens", start: "2026-08-01", end: "2026-12-15", opens: date("2026-08-14"), closes: date("2026-07-01"), want: ErrInvalidRegistrationWindow},
+		{name: "registration closes after term ends", start: "2026-08-01", end: "2026-12-15", closes: date("2026-12-16"), want: ErrInvalidRegistrationWindow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeTermRepo{}
+			svc := NewTermService(repo)
+			term := &models.AcademicTerm{
+				CollegeID:            1,
+				Name:                 "Fall 2026",
+				StartDate:            *date(tt.start),
+				EndDate:              *date(tt.end),
+				RegistrationOpensOn:  tt.opens,
+				RegistrationClosesOn: tt.closes,
+			}
+
+			err := svc.CreateTerm(context.Background(), term)
+			if tt.want != nil {
+				assert.ErrorIs(t, err, tt.want)
+				assert.Empty(t, repo.created)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, repo.created, 1)
+		})
+	}
+}
+
+func TestCreateTermRequiresName(t *testing.T) {
+	repo := &fakeTermRepo{}
+	err := NewTermService(repo).CreateTerm(context.Background(), &models.AcademicTerm{
+		CollegeID: 1,
+		StartDate: *date("2026-08-01"),
+		EndDate:   *date("2026-12-15"),
+	})
+	require.Error(t, err)
+	assert.Empty(t, repo.created)
+}
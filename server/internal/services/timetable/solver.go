@@ -0,0 +1,416 @@
+package timetable
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/jackc/pgtype"
+)
+
+const (
+	defaultDayStartHour        = 9
+	defaultDayEndHour          = 17
+	defaultMaxConsecutiveHours = 3
+
+	// Soft constraint costs: per hour taught beyond the consecutive limit,
+	// and per extra period of a requirement already taught that day.
+	consecutiveHourPenalty = 10
+	sameDayPenalty         = 3
+
+	// maxSolverSteps bounds the backtracking search.
+	maxSolverSteps = 200000
+)
+
+var ErrInvalidDayHours = errors.New("day_end_hour must be after day_start_hour")
+
+// UnschedulableError reports a requirement the solver could not place
+// without breaking a hard constraint.
+type UnschedulableError struct {
+	Requirement models.TimetableRequirement `json:"requirement"`
+	Reason      string                      `json:"reason"`
+}
+
+func (e *UnschedulableError) Error() string {
+	return fmt.Sprintf("cannot schedule course %d: %s", e.Requirement.CourseID, e.Reason)
+}
+
+// solution is the solver's output for a draft.
+type solution struct {
+	Blocks   []*models.TimeTableBlock
+	Penalty  int
+	Warnings []string
+}
+
+type placement struct {
+	slot, room int
+}
+
+// solver assigns every weekly hour of every requirement a period and a room.
+// Hard constraints: a faculty member, class or room is never in two places
+// at once, rooms seat the requirement's students, and faculty only teach
+// within their availability. Soft constraints are scored, and the search
+// tries the cheapest placements first.
+type solver struct {
+	problem models.TimetableProblem
+	periods int // Per day
+	rooms   []models.TimetableRoom
+
+	available   map[string][]bool // By faculty; absent means always available
+	facultyBusy map[string][]bool
+	classBusy   map[int][]bool
+	roomBusy    [][]bool
+	daily       [][]int // Periods of each requirement per day
+
+	sessions []int // Requirement index of each weekly hour, in search order
+	placed   []placement
+	steps    int
+	deepest  int
+}
+
+// solve schedules problem around busy, the blocks already in the timetable
+// that the result must not clash with.
+func solve(problem models.TimetableProblem, busy []*models.TimeTableBlock) (*solution, error) {
+	s, err := newSolver(problem, busy)
+	if err != nil {
+		return nil, err
+	}
+	if !s.search(0) {
+		reason := "no free period fits its faculty, class and rooms"
+		if s.steps >= maxSolverSteps {
+			reason = "no timetable found within the search limit"
+		}
+		return nil, &UnschedulableError{Requirement: s.problem.Requirements[s.sessions[s.deepest]], Reason: reason}
+	}
+	return s.solution(), nil
+}
+
+func newSolver(problem models.TimetableProblem, busy []*models.TimeTableBlock) (*solver, error) {
+	if len(problem.Days) == 0 {
+		problem.Days = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	}
+	if problem.DayStartHour == 0 && problem.DayEndHour == 0 {
+		problem.DayStartHour, problem.DayEndHour = defaultDayStartHour, defaultDayEndHour
+	}
+	if problem.DayEndHour <= problem.DayStartHour {
+		return nil, ErrInvalidDayHours
+	}
+	if problem.MaxConsecutiveHours == 0 {
+		problem.MaxConsecutiveHours = defaultMaxConsecutiveHours
+	}
+
+	s := &solver{
+		problem:     problem,
+		periods:     problem.DayEndHour - problem.DayStartHour,
+		available:   map[string][]bool{},
+		facultyBusy: map[string][]bool{},
+		classBusy:   map[int][]bool{},
+		daily:       make([][]int, len(problem.Requirements)),
+	}
+	slots := len(problem.Days) * s.periods
+
+	// Smallest rooms first, so big rooms stay free for big classes
+	s.rooms = append([]models.TimetableRoom(nil), problem.Rooms...)
+	sort.SliceStable(s.rooms, func(i, j int) bool { return s.rooms[i].Capacity < s.rooms[j].Capacity })
+	s.roomBusy = make([][]bool, len(s.rooms))
+	for i := range s.rooms {
+		s.roomBusy[i] = make([]bool, slots)
+	}
+
+	for _, a := range problem.Availability {
+		if s.available[a.FacultyID] == nil {
+			s.available[a.FacultyID] = make([]bool, slots)
+		}
+		for d, day := range problem.Days {
+			if day != a.DayOfWeek {
+				continue
+			}
+			for h := max(a.FromHour, problem.DayStartHour); h < min(a.ToHour, problem.DayEndHour); h++ {
+				s.available[a.FacultyID][d*s.periods+h-problem.DayStartHour] = true
+			}
+		}
+	}
+
+	for i, r := range problem.Requirements {
+		s.daily[i] = make([]int, len(problem.Days))
+		s.busyFor(r)
+		if !s.seats(r) {
+			return nil, &UnschedulableError{Requirement: r, Reason: fmt.Sprintf("no room seats %d students", r.Students)}
+		}
+	}
+	for _, b := range busy {
+		s.block(b)
+	}
+
+	// Most constrained requirements first
+	order := make([]int, len(problem.Requirements))
+	freeSlots := make([]int, len(problem.Requirements))
+	for i, r := range problem.Requirements {
+		order[i] = i
+		for slot := 0; slot < slots; slot++ {
+			if s.free(r, slot) {
+				freeSlots[i]++
+			}
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ra, rb := order[a], order[b]
+		return freeSlots[ra]-problem.Requirements[ra].HoursPerWeek < freeSlots[rb]-problem.Requirements[rb].HoursPerWeek
+	})
+	for _, i := range order {
+		for h := 0; h < problem.Requirements[i].HoursPerWeek; h++ {
+			s.sessions = append(s.sessions, i)
+		}
+	}
+	s.placed = make([]placement, len(s.sessions))
+	return s, nil
+}
+
+// search places sessions[k:], backtracking when a session has nowhere to go.
+func (s *solver) search(k int) bool {
+	if k == len(s.sessions) {
+		return true
+	}
+	if k > s.deepest {
+		s.deepest = k
+	}
+	if s.steps >= maxSolverSteps {
+		return false
+	}
+	s.steps++
+
+	// Hours of a requirement are interchangeable, so place them in slot
+	// order rather than trying every permutation
+	i, from := s.sessions[k], 0
+	if k > 0 && s.sessions[k-1] == i {
+		from = s.placed[k-1].slot + 1
+	}
+	for _, c := range s.candidates(i, from) {
+		s.place(i, c, true)
+		s.placed[k] = c
+		if s.search(k + 1) {
+			return true
+		}
+		s.place(i, c, false)
+	}
+	return false
+}
+
+// candidates returns every free period from slot from on and room for
+// requirement i, cheapest first, then earliest, then smallest room.
+func (s *solver) candidates(i int, from int) []placement {
+	r := s.problem.Requirements[i]
+	type scored struct {
+		placement
+		cost int
+	}
+	var options []scored
+	for slot := from; slot < len(s.problem.Days)*s.periods; slot++ {
+		if !s.free(r, slot) {
+			continue
+		}
+		cost := -1
+		for room := range s.rooms {
+			if s.rooms[room].Capacity < r.Students || s.roomBusy[room][slot] {
+				continue
+			}
+			if cost < 0 {
+				cost = s.cost(i, slot)
+			}
+			options = append(options, scored{placement{slot, room}, cost})
+		}
+	}
+	sort.SliceStable(options, func(a, b int) bool { return options[a].cost < options[b].cost })
+
+	out := make([]placement, len(options))
+	for n, o := range options {
+		out[n] = o.placement
+	}
+	return out
+}
+
+// cost is the soft constraint penalty of teaching requirement i in slot.
+func (s *solver) cost(i int, slot int) int {
+	r := s.problem.Requirements[i]
+	day, period := slot/s.periods, slot%s.periods
+	busy := s.facultyBusy[r.FacultyID][day*s.periods : (day+1)*s.periods]
+
+	before, after := 0, 0
+	for p := period - 1; p >= 0 && busy[p]; p-- {
+		before++
+	}
+	for p := period + 1; p < s.periods && busy[p]; p++ {
+		after++
+	}
+	limit := s.problem.MaxConsecutiveHours
+	excess := max(0, before+1+after-limit) - max(0, before-limit) - max(0, after-limit)
+
+	return excess*consecutiveHourPenalty + s.daily[i][day]*sameDayPenalty
+}
+
+// place marks requirement i as taught at c, or unmarks it.
+func (s *solver) place(i int, c placement, on bool) {
+	r := s.problem.Requirements[i]
+	s.facultyBusy[r.FacultyID][c.slot] = on
+	if r.ClassID != nil {
+		s.classBusy[*r.ClassID][c.slot] = on
+	}
+	s.roomBusy[c.room][c.slot] = on
+	if on {
+		s.daily[i][c.slot/s.periods]++
+	} else {
+		s.daily[i][c.slot/s.periods]--
+	}
+}
+
+// free reports whether r's faculty and class can meet in slot.
+func (s *solver) free(r models.TimetableRequirement, slot int) bool {
+	if s.facultyBusy[r.FacultyID][slot] {
+		return false
+	}
+	if available, ok := s.available[r.FacultyID]; ok && !available[slot] {
+		return false
+	}
+	return r.ClassID == nil || !s.classBusy[*r.ClassID][slot]
+}
+
+func (s *solver) seats(r models.TimetableRequirement) bool {
+	for _, room := range s.rooms {
+		if room.Capacity >= r.Students {
+			return true
+		}
+	}
+	return false
+}
+
+// busyFor makes sure r's faculty and class have a busy row.
+func (s *solver) busyFor(r models.TimetableRequirement) {
+	slots := len(s.problem.Days) * s.periods
+	if s.facultyBusy[r.FacultyID] == nil {
+		s.facultyBusy[r.FacultyID] = make([]bool, slots)
+	}
+	if r.ClassID != nil && s.classBusy[*r.ClassID] == nil {
+		s.classBusy[*r.ClassID] = make([]bool, slots)
+	}
+}
+
+// block marks the periods an existing timetable block overlaps as busy for
+// its faculty, class and room.
+func (s *solver) block(b *models.TimeTableBlock) {
+	day := -1
+	for d, weekday := range s.problem.Days {
+		if weekday == b.DayOfWeek {
+			day = d
+		}
+	}
+	if day < 0 {
+		return
+	}
+	room := -1
+	if b.RoomNumber != nil {
+		for n := range s.rooms {
+			if strings.EqualFold(s.rooms[n].Number, *b.RoomNumber) {
+				room = n
+			}
+		}
+	}
+
+	const hour = int64(time.Hour / time.Microsecond)
+	for p := 0; p < s.periods; p++ {
+		start := int64(s.problem.DayStartHour+p) * hour
+		if b.StartTime.Microseconds >= start+hour || b.EndTime.Microseconds <= start {
+			continue
+		}
+		slot := day*s.periods + p
+		if b.FacultyID != nil && s.facultyBusy[*b.FacultyID] != nil {
+			s.facultyBusy[*b.FacultyID][slot] = true
+		}
+		if b.ClassID != nil && s.classBusy[*b.ClassID] != nil {
+			s.classBusy[*b.ClassID][slot] = true
+		}
+		if room >= 0 {
+			s.roomBusy[room][slot] = true
+		}
+	}
+}
+
+// solution turns the placements into timetable blocks, joining back-to-back
+// periods of a requirement in the same room, and scores the result.
+func (s *solver) solution() *solution {
+	periodsOf := make(map[int][]placement)
+	for k, i := range s.sessions {
+		periodsOf[i] = append(periodsOf[i], s.placed[k])
+	}
+
+	out := &solution{Warnings: []string{}}
+	for i, r := range s.problem.Requirements {
+		periods := periodsOf[i]
+		sort.Slice(periods, func(a, b int) bool { return periods[a].slot < periods[b].slot })
+		for n := 0; n < len(periods); {
+			first := periods[n]
+			last := n
+			for last+1 < len(periods) && periods[last+1].slot == periods[last].slot+1 &&
+				periods[last+1].room == first.room && periods[last+1].slot/s.periods == first.slot/s.periods {
+				last++
+			}
+			out.Blocks = append(out.Blocks, s.toBlock(r, first.slot, periods[last].slot+1-first.slot, first.room))
+			n = last + 1
+		}
+		for _, count := range s.daily[i] {
+			out.Penalty += max(0, count-1) * sameDayPenalty
+		}
+	}
+	sort.SliceStable(out.Blocks, func(a, b int) bool {
+		if out.Blocks[a].DayOfWeek != out.Blocks[b].DayOfWeek {
+			return out.Blocks[a].DayOfWeek < out.Blocks[b].DayOfWeek
+		}
+		return out.Blocks[a].StartTime.Microseconds < out.Blocks[b].StartTime.Microseconds
+	})
+
+	faculty := make([]string, 0, len(s.facultyBusy))
+	for f := range s.facultyBusy {
+		faculty = append(faculty, f)
+	}
+	sort.Strings(faculty)
+	limit := s.problem.MaxConsecutiveHours
+	for _, f := range faculty {
+		for d, day := range s.problem.Days {
+			busy := s.facultyBusy[f][d*s.periods : (d+1)*s.periods]
+			for p := 0; p < s.periods; {
+				if !busy[p] {
+					p++
+					continue
+				}
+				run := p
+				for run < s.periods && busy[run] {
+					run++
+				}
+				if hours := run - p; hours > limit {
+					out.Penalty += (hours - limit) * consecutiveHourPenalty
+					out.Warnings = append(out.Warnings, fmt.Sprintf("faculty %s teaches %d consecutive hours on %s from %02d:00", f, hours, day, s.problem.DayStartHour+p))
+				}
+				p = run
+			}
+		}
+	}
+	return out
+}
+
+func (s *solver) toBlock(r models.TimetableRequirement, slot, hours, room int) *models.TimeTableBlock {
+	const hour = int64(time.Hour / time.Microsecond)
+	start := int64(s.problem.DayStartHour+slot%s.periods) * hour
+	facultyID, roomNumber := r.FacultyID, s.rooms[room].Number
+	return &models.TimeTableBlock{
+		CourseID:   r.CourseID,
+		ClassID:    r.ClassID,
+		DayOfWeek:  s.problem.Days[slot/s.periods],
+		StartTime:  pgtype.Time{Microseconds: start, Status: pgtype.Present},
+		EndTime:    pgtype.Time{Microseconds: start + int64(hours)*hour, Status: pgtype.Present},
+		RoomNumber: &roomNumber,
+		FacultyID:  &facultyID,
+	}
+}
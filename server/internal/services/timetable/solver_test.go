@@ -0,0 +1,158 @@
+package timetable
+
+import (
+	"testing"
+	"time"
+
+	"eduhub/server/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(i int) *int { return &i }
+
+func strPtr(s string) *string { return &s }
+
+// assertClashFree fails if two blocks share a room, faculty member or class
+// at overlapping times.
+func assertClashFree(t *testing.T, blocks []*models.TimeTableBlock) {
+	t.Helper()
+	for i, a := range blocks {
+		for _, b := range blocks[i+1:] {
+			if a.DayOfWeek != b.DayOfWeek || a.StartTime.Microseconds >= b.EndTime.Microseconds || b.StartTime.Microseconds >= a.EndTime.Microseconds {
+				continue
+			}
+			assert.NotEqual(t, *a.RoomNumber, *b.RoomNumber, "room clash on %s", a.DayOfWeek)
+			assert.NotEqual(t, *a.FacultyID, *b.FacultyID, "faculty clash on %s", a.DayOfWeek)
+			if a.ClassID != nil && b.ClassID != nil {
+				assert.NotEqual(t, *a.ClassID, *b.ClassID, "class clash on %s", a.DayOfWeek)
+			}
+		}
+	}
+}
+
+func hoursOf(blocks []*models.TimeTableBlock, courseID int) int {
+	total := 0
+	for _, b := range blocks {
+		if b.CourseID == courseID {
+			total += int((b.EndTime.Microseconds - b.StartTime.Microseconds) / int64(time.Hour/time.Microsecond))
+		}
+	}
+	return total
+}
+
+func TestSolveProducesClashFreeTimetable(t *testing.T) {
+	problem := models.TimetableProblem{
+		Requirements: []models.TimetableRequirement{
+			{CourseID: 1, ClassID: intPtr(10), FacultyID: "alice", HoursPerWeek: 4, Students: 60},
+			{CourseID: 2, ClassID: intPtr(10), FacultyID: "bob", HoursPerWeek: 3, Students: 60},
+			{CourseID: 3, ClassID: intPtr(11), FacultyID: "alice", HoursPerWeek: 3, Students: 30},
+			{CourseID: 4, ClassID: intPtr(11), FacultyID: "carol", HoursPerWeek: 5, Students: 30},
+		},
+		Rooms: []models.TimetableRoom{{Number: "Hall", Capacity: 80}, {Number: "B-204", Capacity: 40}},
+		Days:  []time.Weekday{time.Monday, time.Tuesday, time.Wednesday},
+	}
+
+	result, err := solve(problem, nil)
+	require.NoError(t, err)
+
+	assertClashFree(t, result.Blocks)
+	for _, r := range problem.Requirements {
+		assert.Equal(t, r.HoursPerWeek, hoursOf(result.Blocks, r.CourseID), "course %d", r.CourseID)
+	}
+	for _, b := range result.Blocks {
+		if b.CourseID == 1 || b.CourseID == 2 {
+			assert.Equal(t, "Hall", *b.RoomNumber, "60 students only fit the hall")
+		}
+		assert.GreaterOrEqual(t, b.StartTime.Microseconds, int64(9*time.Hour/time.Microsecond))
+		assert.LessOrEqual(t, b.EndTime.Microseconds, int64(17*time.Hour/time.Microsecond))
+	}
+}
+
+func TestSolveKeepsToFacultyAvailability(t *testing.T) {
+	problem := models.TimetableProblem{
+		Requirements: []models.TimetableRequirement{{CourseID: 1, FacultyID: "alice", HoursPerWeek: 2}},
+		Rooms:        []models.TimetableRoom{{Number: "B-204", Capacity: 40}},
+		Availability: []models.FacultyAvailability{{FacultyID: "alice", DayOfWeek: time.Thursday, FromHour: 14, ToHour: 16}},
+	}
+
+	result, err := solve(problem, nil)
+	require.NoError(t, err)
+
+	require.Len(t, result.Blocks, 1)
+	assert.Equal(t, time.Thursday, result.Blocks[0].DayOfWeek)
+	assert.Equal(t, int64(14*time.Hour/time.Microsecond), result.Blocks[0].StartTime.Microseconds)
+	assert.Equal(t, int64(16*time.Hour/time.Microsecond), result.Blocks[0].EndTime.Microseconds)
+}
+
+func TestSolveAvoidsExistingBlocks(t *testing.T) {
+	problem := models.TimetableProblem{
+		Requirements: []models.TimetableRequirement{{CourseID: 1, FacultyID: "alice", HoursPerWeek: 1}},
+		Rooms:        []models.TimetableRoom{{Number: "B-204", Capacity: 40}},
+		Days:         []time.Weekday{time.Monday},
+		DayStartHour: 9,
+		DayEndHour:   11,
+	}
+	// B-204 is taken 09:00-10:00 by another course
+	busy := []*models.TimeTableBlock{{CourseID: 9, DayOfWeek: time.Monday, StartTime: clock(9, 0), EndTime: clock(10, 0), RoomNumber: strPtr("b-204")}}
+
+	result, err := solve(problem, busy)
+	require.NoError(t, err)
+
+	require.Len(t, result.Blocks, 1)
+	assert.Equal(t, int64(10*time.Hour/time.Microsecond), result.Blocks[0].StartTime.Microseconds)
+}
+
+func TestSolveBreaksUpLongTeachingRuns(t *testing.T) {
+	// Four hours fit a nine-to-two day with a break, but not a nine-to-one day
+	problem := models.TimetableProblem{
+		Requirements: []models.TimetableRequirement{
+			{CourseID: 1, FacultyID: "alice", HoursPerWeek: 2},
+			{CourseID: 2, FacultyID: "alice", HoursPerWeek: 2},
+		},
+		Rooms:        []models.TimetableRoom{{Number: "B-204", Capacity: 40}},
+		Days:         []time.Weekday{time.Monday},
+		DayStartHour: 9,
+		DayEndHour:   14,
+	}
+
+	result, err := solve(problem, nil)
+	require.NoError(t, err)
+	assert.Empty(t, result.Warnings, "a free hour fits between three and one")
+
+	problem.DayEndHour = 13
+	result, err = solve(problem, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"faculty alice teaches 4 consecutive hours on Monday from 09:00"}, result.Warnings)
+	assert.GreaterOrEqual(t, result.Penalty, consecutiveHourPenalty)
+}
+
+func TestSolveReportsUnschedulableRequirements(t *testing.T) {
+	rooms := []models.TimetableRoom{{Number: "B-204", Capacity: 40}}
+
+	_, err := solve(models.TimetableProblem{
+		Requirements: []models.TimetableRequirement{{CourseID: 1, FacultyID: "alice", HoursPerWeek: 2, Students: 120}},
+		Rooms:        rooms,
+	}, nil)
+	var unschedulable *UnschedulableError
+	require.ErrorAs(t, err, &unschedulable)
+	assert.Equal(t, 1, unschedulable.Requirement.CourseID)
+	assert.Equal(t, "no room seats 120 students", unschedulable.Reason)
+
+	// One room, one day of four periods, five hours of teaching
+	_, err = solve(models.TimetableProblem{
+		Requirements: []models.TimetableRequirement{
+			{CourseID: 1, FacultyID: "alice", HoursPerWeek: 3},
+			{CourseID: 2, FacultyID: "bob", HoursPerWeek: 2},
+		},
+		Rooms:        rooms,
+		Days:         []time.Weekday{time.Monday},
+		DayStartHour: 9,
+		DayEndHour:   13,
+	}, nil)
+	require.ErrorAs(t, err, &unschedulable)
+
+	_, err = solve(models.TimetableProblem{Rooms: rooms, DayStartHour: 12, DayEndHour: 10}, nil)
+	assert.ErrorIs(t, err, ErrInvalidDayHours)
+}
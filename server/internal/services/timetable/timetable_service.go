@@ -0,0 +1,221 @@
+package timetable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrInvalidBlockTimes = errors.New("timetable block must end after it starts")
+	ErrInvalidDayOfWeek  = errors.New("day_of_week must be between 0 (Sunday) and 6 (Saturday)")
+)
+
+type TimetableService interface {
+	// CreateBlock adds a weekly block to the course's timetable, in the
+	// current term unless TermID is set. A block that shares a room, faculty
+	// member or class with another at an overlapping time on the same day
+	// is refused with a *repository.TimeTableClashError listing the others.
+	CreateBlock(ctx context.Context, block *models.TimeTableBlock) error
+	GetBlock(ctx context.Context, collegeID int, blockID int) (*models.TimeTableBlock, error)
+	// UpdateBlock replaces the block, keeping its term unless TermID is set,
+	// and refuses clashes like CreateBlock.
+	UpdateBlock(ctx context.Context, block *models.TimeTableBlock) error
+	DeleteBlock(ctx context.Context, collegeID int, blockID int) error
+
+	// The weekly timetable of a class, faculty member or room, ordered by day
+	// and start time, in termID or the college's current term if it is nil.
+	GetClassTimetable(ctx context.Context, collegeID int, classID int, termID *int) ([]*models.TimeTableBlock, error)
+	GetFacultyTimetable(ctx context.Context, collegeID int, facultyID string, termID *int) ([]*models.TimeTableBlock, error)
+	GetRoomTimetable(ctx context.Context, collegeID int, room string, termID *int) ([]*models.TimeTableBlock, error)
+
+	// GenerateDraft solves problem into a clash-free weekly timetable for
+	// termID (the current term if nil) and stores it as a draft for review.
+	// The blocks of courses outside the problem are kept clear of. It
+	// returns an *UnschedulableError if some requirement cannot be placed.
+	GenerateDraft(ctx context.Context, collegeID int, termID *int, name string, createdBy string, problem models.TimetableProblem) (*models.TimetableDraft, error)
+	GetDraft(ctx context.Context, collegeID int, draftID int) (*models.TimetableDraft, error)
+	GetDrafts(ctx context.Context, collegeID int, termID *int, limit, offset uint64) ([]*models.TimetableDraft, error)
+	// PublishDraft makes the draft's blocks the live timetable of its
+	// courses, replacing their previous blocks in the term.
+	PublishDraft(ctx context.Context, collegeID int, draftID int, publishedBy string) (*models.TimetableDraft, error)
+	DeleteDraft(ctx context.Context, collegeID int, draftID int) error
+}
+
+type timetableService struct {
+	timetableRepo repository.TimeTableRepository
+	draftRepo     repository.TimetableDraftRepository
+	courseRepo    repository.CourseRepository
+	termRepo      repository.AcademicTermRepository
+	validate      validator.Validate
+}
+
+func NewTimetableService(timetableRepo repository.TimeTableRepository, draftRepo repository.TimetableDraftRepository, courseRepo repository.CourseRepository, termRepo repository.AcademicTermRepository) TimetableService {
+	return &timetableService{
+		timetableRepo: timetableRepo,
+		draftRepo:     draftRepo,
+		courseRepo:    courseRepo,
+		termRepo:      termRepo,
+		validate:      *validator.New(),
+	}
+}
+
+func (s *timetableService) CreateBlock(ctx context.Context, block *models.TimeTableBlock) error {
+	if err := s.validateBlock(ctx, block); err != nil {
+		return err
+	}
+	return s.timetableRepo.CreateTimeTableBlock(ctx, block)
+}
+
+func (s *timetableService) GetBlock(ctx context.Context, collegeID int, blockID int) (*models.TimeTableBlock, error) {
+	return s.timetableRepo.GetTimeTableBlockByID(ctx, blockID, collegeID)
+}
+
+func (s *timetableService) UpdateBlock(ctx context.Context, block *models.TimeTableBlock) error {
+	existing, err := s.timetableRepo.GetTimeTableBlockByID(ctx, block.ID, block.CollegeID)
+	if err != nil {
+		return err
+	}
+	if block.TermID == nil {
+		block.TermID = existing.TermID
+	}
+	if err := s.validateBlock(ctx, block); err != nil {
+		return err
+	}
+	return s.timetableRepo.UpdateTimeTableBlock(ctx, block)
+}
+
+func (s *timetableService) DeleteBlock(ctx context.Context, collegeID int, blockID int) error {
+	return s.timetableRepo.DeleteTimeTableBlock(ctx, blockID, collegeID)
+}
+
+func (s *timetableService) GetClassTimetable(ctx context.Context, collegeID int, classID int, termID *int) ([]*models.TimeTableBlock, error) {
+	return s.weekOf(ctx, models.TimeTableBlockFilter{CollegeID: collegeID, ClassID: &classID, TermID: termID})
+}
+
+func (s *timetableService) GetFacultyTimetable(ctx context.Context, collegeID int, facultyID string, termID *int) ([]*models.TimeTableBlock, error) {
+	return s.weekOf(ctx, models.TimeTableBlockFilter{CollegeID: collegeID, FacultyID: &facultyID, TermID: termID})
+}
+
+func (s *timetableService) GetRoomTimetable(ctx context.Context, collegeID int, room string, termID *int) ([]*models.TimeTableBlock, error) {
+	room = strings.TrimSpace(room)
+	return s.weekOf(ctx, models.TimeTableBlockFilter{CollegeID: collegeID, RoomNumber: &room, TermID: termID})
+}
+
+func (s *timetableService) GenerateDraft(ctx context.Context, collegeID int, termID *int, name string, createdBy string, problem models.TimetableProblem) (*models.TimetableDraft, error) {
+	draft := &models.TimetableDraft{CollegeID: collegeID, Name: strings.TrimSpace(name), Problem: problem, CreatedBy: createdBy}
+	if err := s.validate.Struct(draft); err != nil {
+		return nil, fmt.Errorf("validation failed %w", err)
+	}
+	if err := s.validate.Struct(problem); err != nil {
+		return nil, fmt.Errorf("validation failed %w", err)
+	}
+	termID, err := s.termOrCurrent(ctx, collegeID, termID)
+	if err != nil {
+		return nil, err
+	}
+	draft.TermID = termID
+
+	scheduled := make(map[int]bool)
+	for _, r := range problem.Requirements {
+		if scheduled[r.CourseID] {
+			continue
+		}
+		if _, err := s.courseRepo.FindCourseByID(ctx, collegeID, r.CourseID); err != nil {
+			return nil, err
+		}
+		scheduled[r.CourseID] = true
+	}
+
+	// Publishing replaces the scheduled courses' blocks, so only the others
+	// constrain the solver
+	existing, err := s.timetableRepo.GetTimeTableBlocks(ctx, models.TimeTableBlockFilter{CollegeID: collegeID, TermID: termID})
+	if err != nil {
+		return nil, err
+	}
+	busy := make([]*models.TimeTableBlock, 0, len(existing))
+	for _, b := range existing {
+		if !scheduled[b.CourseID] {
+			busy = append(busy, b)
+		}
+	}
+
+	result, err := solve(problem, busy)
+	if err != nil {
+		return nil, err
+	}
+	draft.Blocks, draft.Penalty, draft.Warnings = result.Blocks, result.Penalty, result.Warnings
+	if err := s.draftRepo.CreateDraft(ctx, draft); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+func (s *timetableService) GetDraft(ctx context.Context, collegeID int, draftID int) (*models.TimetableDraft, error) {
+	return s.draftRepo.GetDraft(ctx, collegeID, draftID)
+}
+
+func (s *timetableService) GetDrafts(ctx context.Context, collegeID int, termID *int, limit, offset uint64) ([]*models.TimetableDraft, error) {
+	return s.draftRepo.FindDrafts(ctx, collegeID, termID, limit, offset)
+}
+
+func (s *timetableService) PublishDraft(ctx context.Context, collegeID int, draftID int, publishedBy string) (*models.TimetableDraft, error) {
+	if err := s.draftRepo.PublishDraft(ctx, collegeID, draftID, publishedBy); err != nil {
+		return nil, err
+	}
+	return s.draftRepo.GetDraft(ctx, collegeID, draftID)
+}
+
+func (s *timetableService) DeleteDraft(ctx context.Context, collegeID int, draftID int) error {
+	return s.draftRepo.DeleteDraft(ctx, collegeID, draftID)
+}
+
+// weekOf returns every block matching filter, defaulting to the current term.
+func (s *timetableService) weekOf(ctx context.Context, filter models.TimeTableBlockFilter) ([]*models.TimeTableBlock, error) {
+	termID, err := s.termOrCurrent(ctx, filter.CollegeID, filter.TermID)
+	if err != nil {
+		return nil, err
+	}
+	filter.TermID = termID
+	return s.timetableRepo.GetTimeTableBlocks(ctx, filter)
+}
+
+// termOrCurrent returns termID, or the college's current term if it is nil.
+func (s *timetableService) termOrCurrent(ctx context.Context, collegeID int, termID *int) (*int, error) {
+	if termID != nil {
+		return termID, nil
+	}
+	return s.termRepo.CurrentTermID(ctx, collegeID)
+}
+
+// validateBlock checks the block's day and times, that its course belongs to
+// the college, and drops a blank room number or faculty ID.
+func (s *timetableService) validateBlock(ctx context.Context, block *models.TimeTableBlock) error {
+	if block.DayOfWeek < 0 || block.DayOfWeek > 6 {
+		return ErrInvalidDayOfWeek
+	}
+	if block.EndTime.Microseconds <= block.StartTime.Microseconds {
+		return ErrInvalidBlockTimes
+	}
+	if block.RoomNumber != nil {
+		room := strings.TrimSpace(*block.RoomNumber)
+		if room == "" {
+			block.RoomNumber = nil
+		} else {
+			block.RoomNumber = &room
+		}
+	}
+	if block.FacultyID != nil && *block.FacultyID == "" {
+		block.FacultyID = nil
+	}
+	if _, err := s.courseRepo.FindCourseByID(ctx, block.CollegeID, block.CourseID); err != nil {
+		return err
+	}
+	return nil
+}
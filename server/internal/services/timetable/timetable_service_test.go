@@ -0,0 +1,201 @@
+package timetable
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"eduhub/server/internal/models"
+	"eduhub/server/internal/repository"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func clock(h, m int) pgtype.Time {
+	return pgtype.Time{Microseconds: int64(h*3600+m*60) * 1e6, Status: pgtype.Present}
+}
+
+type fakeTimetableRepo struct {
+	repository.TimeTableRepository
+	stored  map[int]*models.TimeTableBlock
+	created []*models.TimeTableBlock
+	updated []*models.TimeTableBlock
+	blocks  []*models.TimeTableBlock
+	filter  models.TimeTableBlockFilter
+}
+
+func (f *fakeTimetableRepo) CreateTimeTableBlock(ctx context.Context, block *models.TimeTableBlock) error {
+	f.created = append(f.created, block)
+	return nil
+}
+
+func (f *fakeTimetableRepo) GetTimeTableBlockByID(ctx context.Context, blockID int, collegeID int) (*models.TimeTableBlock, error) {
+	if block, ok := f.stored[blockID]; ok {
+		return block, nil
+	}
+	return nil, repository.ErrTimeTableBlockNotFound
+}
+
+func (f *fakeTimetableRepo) UpdateTimeTableBlock(ctx context.Context, block *models.TimeTableBlock) error {
+	f.updated = append(f.updated, block)
+	return nil
+}
+
+func (f *fakeTimetableRepo) GetTimeTableBlocks(ctx context.Context, filter models.TimeTableBlockFilter) ([]*models.TimeTableBlock, error) {
+	f.filter = filter
+	return f.blocks, nil
+}
+
+type fakeCourseRepo struct{ repository.CourseRepository }
+
+func (f *fakeCourseRepo) FindCourseByID(ctx context.Context, collegeID int, courseID int) (*models.Course, error) {
+	if courseID != 7 {
+		return nil, repository.ErrCourseNotFound
+	}
+	return &models.Course{ID: 7, CollegeID: collegeID}, nil
+}
+
+type fakeTermRepo struct {
+	repository.AcademicTermRepository
+}
+
+func (f *fakeTermRepo) CurrentTermID(ctx context.Context, collegeID int) (*int, error) {
+	id := 3
+	return &id, nil
+}
+
+func TestCreateBlockValidates(t *testing.T) {
+	blank := "  "
+	tests := []struct {
+		name  string
+		block models.TimeTableBlock
+		want  error
+	}{
+		{name: "valid", block: models.TimeTableBlock{CourseID: 7, DayOfWeek: time.Monday, StartTime: clock(9, 0), EndTime: clock(10, 0)}},
+		{name: "ends when it starts", block: models.TimeTableBlock{CourseID: 7, DayOfWeek: time.Monday, StartTime: clock(9, 0), EndTime: clock(9, 0)}, want: ErrInvalidBlockTimes},
+		{name: "ends before it starts", block: models.TimeTableBlock{CourseID: 7, DayOfWeek: time.Monday, StartTime: clock(10, 0), EndTime: clock(9, 0)}, want: ErrInvalidBlockTimes},
+		{name: "no such day", block: models.TimeTableBlock{CourseID: 7, DayOfWeek: 7, StartTime: clock(9, 0), EndTime: clock(10, 0)}, want: ErrInvalidDayOfWeek},
+		{name: "unknown course", block: models.TimeTableBlock{CourseID: 8, DayOfWeek: time.Monday, StartTime: clock(9, 0), EndTime: clock(10, 0)}, want: repository.ErrCourseNotFound},
+		{name: "blank room", block: models.TimeTableBlock{CourseID: 7, DayOfWeek: time.Friday, StartTime: clock(9, 0), EndTime: clock(10, 0), RoomNumber: &blank}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeTimetableRepo{}
+			svc := NewTimetableService(repo, nil, &fakeCourseRepo{}, &fakeTermRepo{})
+			block := tt.block
+			block.CollegeID = 1
+
+			err := svc.CreateBlock(context.Background(), &block)
+			if tt.want != nil {
+				assert.ErrorIs(t, err, tt.want)
+				assert.Empty(t, repo.created)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, repo.created, 1)
+			assert.Nil(t, repo.created[0].RoomNumber)
+		})
+	}
+}
+
+func TestCreateBlockReturnsClashes(t *testing.T) {
+	room := "B-204"
+	clash := &repository.TimeTableClashError{Clashes: []*models.TimeTableBlock{{ID: 4, RoomNumber: &room}}}
+	repo := &clashingRepo{fakeTimetableRepo: &fakeTimetableRepo{}, err: clash}
+	svc := NewTimetableService(repo, nil, &fakeCourseRepo{}, &fakeTermRepo{})
+
+	err := svc.CreateBlock(context.Background(), &models.TimeTableBlock{CollegeID: 1, CourseID: 7, DayOfWeek: time.Monday, StartTime: clock(9, 0), EndTime: clock(10, 0), RoomNumber: &room})
+
+	var got *repository.TimeTableClashError
+	require.ErrorAs(t, err, &got)
+	assert.ErrorIs(t, err, repository.ErrTimeTableClash)
+	assert.Equal(t, 4, got.Clashes[0].ID)
+}
+
+type clashingRepo struct {
+	*fakeTimetableRepo
+	err error
+}
+
+func (f *clashingRepo) CreateTimeTableBlock(ctx context.Context, block *models.TimeTableBlock) error {
+	return f.err
+}
+
+func TestUpdateBlockKeepsTerm(t *testing.T) {
+	term := 2
+	repo := &fakeTimetableRepo{stored: map[int]*models.TimeTableBlock{5: {ID: 5, CollegeID: 1, CourseID: 7, TermID: &term}}}
+	svc := NewTimetableService(repo, nil, &fakeCourseRepo{}, &fakeTermRepo{})
+
+	err := svc.UpdateBlock(context.Background(), &models.TimeTableBlock{ID: 5, CollegeID: 1, CourseID: 7, DayOfWeek: time.Tuesday, StartTime: clock(11, 0), EndTime: clock(12, 30)})
+	require.NoError(t, err)
+	require.Len(t, repo.updated, 1)
+	assert.Equal(t, 2, *repo.updated[0].TermID)
+
+	err = svc.UpdateBlock(context.Background(), &models.TimeTableBlock{ID: 6, CollegeID: 1, CourseID: 7, DayOfWeek: time.Tuesday, StartTime: clock(11, 0), EndTime: clock(12, 30)})
+	assert.ErrorIs(t, err, repository.ErrTimeTableBlockNotFound)
+}
+
+func TestTimetableViewsDefaultToCurrentTerm(t *testing.T) {
+	repo := &fakeTimetableRepo{}
+	svc := NewTimetableService(repo, nil, &fakeCourseRepo{}, &fakeTermRepo{})
+
+	_, err := svc.GetRoomTimetable(context.Background(), 1, " B-204 ", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, *repo.filter.TermID)
+	assert.Equal(t, "B-204", *repo.filter.RoomNumber)
+
+	term := 2
+	_, err = svc.GetFacultyTimetable(context.Background(), 1, "kratos-id", &term)
+	require.NoError(t, err)
+	assert.Equal(t, 2, *repo.filter.TermID)
+	assert.Equal(t, "kratos-id", *repo.filter.FacultyID)
+}
+
+type fakeDraftRepo struct {
+	repository.TimetableDraftRepository
+	created []*models.TimetableDraft
+}
+
+func (f *fakeDraftRepo) CreateDraft(ctx context.Context, draft *models.TimetableDraft) error {
+	f.created = append(f.created, draft)
+	return nil
+}
+
+func TestGenerateDraftWorksAroundOtherCourses(t *testing.T) {
+	room := "B-204"
+	repo := &fakeTimetableRepo{blocks: []*models.TimeTableBlock{
+		// Being rescheduled, so its old slot is free
+		{ID: 1, CourseID: 7, DayOfWeek: time.Monday, StartTime: clock(10, 0), EndTime: clock(11, 0), RoomNumber: &room},
+		// Another course keeps B-204 at nine
+		{ID: 2, CourseID: 8, DayOfWeek: time.Monday, StartTime: clock(9, 0), EndTime: clock(10, 0), RoomNumber: &room},
+	}}
+	drafts := &fakeDraftRepo{}
+	svc := NewTimetableService(repo, drafts, &fakeCourseRepo{}, &fakeTermRepo{})
+	problem := models.TimetableProblem{
+		Requirements: []models.TimetableRequirement{{CourseID: 7, FacultyID: "alice", HoursPerWeek: 1}},
+		Rooms:        []models.TimetableRoom{{Number: room, Capacity: 40}},
+		Days:         []time.Weekday{time.Monday},
+		DayStartHour: 9,
+		DayEndHour:   11,
+	}
+
+	draft, err := svc.GenerateDraft(context.Background(), 1, nil, "Odd semester", "hod", problem)
+	require.NoError(t, err)
+
+	require.Len(t, drafts.created, 1)
+	assert.Equal(t, 3, *draft.TermID)
+	assert.Equal(t, 3, *repo.filter.TermID)
+	require.Len(t, draft.Blocks, 1)
+	assert.Equal(t, clock(10, 0), draft.Blocks[0].StartTime)
+
+	problem.Requirements[0].CourseID = 99
+	_, err = svc.GenerateDraft(context.Background(), 1, nil, "Odd semester", "hod", problem)
+	assert.ErrorIs(t, err, repository.ErrCourseNotFound)
+
+	_, err = svc.GenerateDraft(context.Background(), 1, nil, "", "hod", problem)
+	var invalid validator.ValidationErrors
+	assert.ErrorAs(t, err, &invalid)
+}
@@ -0,0 +1,274 @@
+// Package ical reads and writes the subset of iCalendar (RFC 5545) that
+// calendar subscriptions and holiday lists use: VEVENTs with a UID, summary,
+// description, location, start, end and an optional recurrence rule.
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout         = "20060102"
+	dateTimeLayout     = "20060102T150405"
+	utcDateTimeLayout  = "20060102T150405Z"
+	maxLineOctets      = 75
+	defaultProductID   = "-//EduHub//Calendar//EN"
+	contentLineEnding  = "\r\n"
+	continuationPrefix = " "
+)
+
+var ErrNoCalendar = errors.New("ical: no VCALENDAR found")
+
+// Event is a VEVENT. All-day events use the dates of Start and End, End
+// being the day after the last one. Floating events happen at Start's wall
+// clock time wherever the reader is; other times are written in UTC.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Floating    bool
+	RRule       string    // e.g. FREQ=WEEKLY;UNTIL=20261215T235959Z
+	Modified    time.Time // DTSTAMP and LAST-MODIFIED; now if zero
+}
+
+// Calendar is a VCALENDAR of events.
+type Calendar struct {
+	Name      string // X-WR-CALNAME, shown by most clients
+	ProductID string // Defaults to EduHub's
+	Events    []Event
+}
+
+// Encode writes the calendar to w with CRLF line endings and long lines
+// folded.
+func (c *Calendar) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	productID := c.ProductID
+	if productID == "" {
+		productID = defaultProductID
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", productID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escape(c.Name))
+	}
+
+	now := time.Now().UTC()
+	for _, e := range c.Events {
+		modified := now
+		if !e.Modified.IsZero() {
+			modified = e.Modified.UTC()
+		}
+		line("BEGIN", "VEVENT")
+		line("UID", escape(e.UID))
+		line("DTSTAMP", modified.Format(utcDateTimeLayout))
+		line("LAST-MODIFIED", modified.Format(utcDateTimeLayout))
+		switch {
+		case e.AllDay:
+			line("DTSTART;VALUE=DATE", e.Start.Format(dateLayout))
+			line("DTEND;VALUE=DATE", e.End.Format(dateLayout))
+		case e.Floating:
+			line("DTSTART", e.Start.Format(dateTimeLayout))
+			line("DTEND", e.End.Format(dateTimeLayout))
+		default:
+			line("DTSTART", e.Start.UTC().Format(utcDateTimeLayout))
+			line("DTEND", e.End.UTC().Format(utcDateTimeLayout))
+		}
+		if e.RRule != "" {
+			line("RRULE", e.RRule)
+		}
+		line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", escape(e.Location))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// Parse reads the VEVENTs of the first VCALENDAR in r. Times with a TZID
+// are read in that zone, floating times in UTC. An event without DTEND ends
+// when it starts, or a day later if it is all-day.
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events  []Event
+		current *Event
+		inside  bool
+		hasEnd  bool
+	)
+	for n, raw := range lines {
+		name, params, value := splitLine(raw)
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VCALENDAR"):
+			inside = true
+		case !inside:
+			continue
+		case name == "END" && strings.EqualFold(value, "VCALENDAR"):
+			return events, nil
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			current, hasEnd = &Event{}, false
+		case current == nil:
+			continue
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if current.Start.IsZero() {
+				return nil, fmt.Errorf("ical: line %d: event %q has no DTSTART", n+1, current.UID)
+			}
+			if !hasEnd {
+				current.End = current.Start
+				if current.AllDay {
+					current.End = current.Start.AddDate(0, 0, 1)
+				}
+			}
+			events = append(events, *current)
+			current = nil
+		case name == "UID":
+			current.UID = unescape(value)
+		case name == "SUMMARY":
+			current.Summary = unescape(value)
+		case name == "DESCRIPTION":
+			current.Description = unescape(value)
+		case name == "LOCATION":
+			current.Location = unescape(value)
+		case name == "RRULE":
+			current.RRule = value
+		case name == "DTSTART", name == "DTEND":
+			t, allDay, floating, err := parseTime(params, value)
+			if err != nil {
+				return nil, fmt.Errorf("ical: line %d: %w", n+1, err)
+			}
+			if name == "DTSTART" {
+				current.Start, current.AllDay, current.Floating = t, allDay, floating
+			} else {
+				current.End, hasEnd = t, true
+			}
+		}
+	}
+	if !inside {
+		return nil, ErrNoCalendar
+	}
+	return events, nil
+}
+
+// unfold joins continuation lines onto the line they continue.
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var lines []string
+	for scanner.Scan() {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += text[1:]
+			continue
+		}
+		if text != "" {
+			lines = append(lines, text)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ical: %w", err)
+	}
+	return lines, nil
+}
+
+// splitLine splits "NAME;PARAM=x;PARAM=y:value", ignoring colons inside
+// quoted parameter values.
+func splitLine(line string) (string, map[string]string, string) {
+	quoted := false
+	colon := -1
+	for i, ch := range line {
+		if ch == '"' {
+			quoted = !quoted
+		} else if ch == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return strings.ToUpper(line), nil, ""
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:]
+}
+
+func parseTime(params map[string]string, value string) (t time.Time, allDay, floating bool, err error) {
+	if strings.EqualFold(params["VALUE"], "DATE") || len(value) == len(dateLayout) {
+		t, err = time.Parse(dateLayout, value)
+		return t, true, false, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse(utcDateTimeLayout, value)
+		return t, false, false, err
+	}
+	loc := time.UTC
+	floating = true
+	if tzid := params["TZID"]; tzid != "" {
+		if zone, zerr := time.LoadLocation(tzid); zerr == nil {
+			loc, floating = zone, false
+		}
+	}
+	t, err = time.ParseInLocation(dateTimeLayout, value, loc)
+	return t, false, floating, err
+}
+
+// writeFolded writes a content line, folding it into lines of at most 75
+// octets without splitting UTF-8 sequences.
+func writeFolded(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString(contentLineEnding)
+		w.WriteString(continuationPrefix)
+		line = line[cut:]
+		limit = maxLineOctets - len(continuationPrefix)
+	}
+	w.WriteString(line)
+	w.WriteString(contentLineEnding)
+}
+
+var (
+	escaper   = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	unescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func unescape(s string) string {
+	return unescaper.Replace(s)
+}
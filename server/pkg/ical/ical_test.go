@@ -0,0 +1,131 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeRoundTrip(t *testing.T) {
+	modified := time.Date(2026, 8, 1, 10, 0, 0, 0, time.UTC)
+	cal := Calendar{
+		Name: "Timetable",
+		Events: []Event{
+			{
+				UID:      "holiday-1@eduhub",
+				Summary:  "Diwali; college closed, no classes",
+				Start:    time.Date(2026, 11, 8, 0, 0, 0, 0, time.UTC),
+				End:      time.Date(2026, 11, 10, 0, 0, 0, 0, time.UTC),
+				AllDay:   true,
+				Modified: modified,
+			},
+			{
+				UID:      "block-7@eduhub",
+				Summary:  "Algorithms",
+				Location: "B-204",
+				Start:    time.Date(2026, 8, 3, 9, 0, 0, 0, time.UTC),
+				End:      time.Date(2026, 8, 3, 10, 0, 0, 0, time.UTC),
+				Floating: true,
+				RRule:    "FREQ=WEEKLY;UNTIL=20261215T235959Z",
+				Modified: modified,
+			},
+			{
+				UID:         "quiz-3@eduhub",
+				Summary:     "Quiz due",
+				Description: "Covers chapters 1-3\nOpen book",
+				Start:       time.Date(2026, 8, 5, 18, 30, 0, 0, time.FixedZone("IST", 5*3600+1800)),
+				End:         time.Date(2026, 8, 5, 18, 30, 0, 0, time.FixedZone("IST", 5*3600+1800)),
+				Modified:    modified,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, cal.Encode(&buf))
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\n"))
+	assert.Contains(t, out, "DTSTART;VALUE=DATE:20261108\r\n")
+	assert.Contains(t, out, "DTSTART:20260803T090000\r\n")
+	assert.Contains(t, out, "DTSTART:20260805T130000Z\r\n")
+	assert.Contains(t, out, `SUMMARY:Diwali\; college closed\, no classes`)
+	assert.Contains(t, out, "DTSTAMP:20260801T100000Z\r\n")
+
+	events, err := Parse(&buf)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	assert.Equal(t, "holiday-1@eduhub", events[0].UID)
+	assert.Equal(t, "Diwali; college closed, no classes", events[0].Summary)
+	assert.True(t, events[0].AllDay)
+	assert.Equal(t, cal.Events[0].End, events[0].End)
+
+	assert.True(t, events[1].Floating)
+	assert.Equal(t, "B-204", events[1].Location)
+	assert.Equal(t, "FREQ=WEEKLY;UNTIL=20261215T235959Z", events[1].RRule)
+
+	assert.Equal(t, "Covers chapters 1-3\nOpen book", events[2].Description)
+	assert.True(t, cal.Events[2].Start.Equal(events[2].Start))
+}
+
+func TestEncodeFoldsLongLines(t *testing.T) {
+	cal := Calendar{Events: []Event{{
+		UID:     "long@eduhub",
+		Summary: strings.Repeat("ü", 100),
+		Start:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		AllDay:  true,
+	}}}
+
+	var buf bytes.Buffer
+	require.NoError(t, cal.Encode(&buf))
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineOctets)
+	}
+
+	events, err := Parse(&buf)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, strings.Repeat("ü", 100), events[0].Summary)
+}
+
+func TestParseHolidayList(t *testing.T) {
+	input := "BEGIN:VCALENDAR\n" +
+		"VERSION:2.0\n" +
+		"BEGIN:VEVENT\n" +
+		"UID:2026-01-26@holidays\n" +
+		"DTSTART;VALUE=DATE:20260126\n" +
+		"SUMMARY:Republic\n" +
+		"  Day\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"UID:meeting@holidays\n" +
+		"DTSTART;TZID=Asia/Kolkata:20260127T100000\n" +
+		"DTEND;TZID=Asia/Kolkata:20260127T110000\n" +
+		"SUMMARY:Staff meeting\n" +
+		"END:VEVENT\n" +
+		"END:VCALENDAR\n"
+
+	events, err := Parse(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	assert.Equal(t, "Republic Day", events[0].Summary)
+	assert.True(t, events[0].AllDay)
+	assert.Equal(t, time.Date(2026, 1, 27, 0, 0, 0, 0, time.UTC), events[0].End)
+
+	assert.False(t, events[1].Floating)
+	assert.Equal(t, time.Date(2026, 1, 27, 4, 30, 0, 0, time.UTC), events[1].Start.UTC())
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := Parse(strings.NewReader("not a calendar"))
+	assert.ErrorIs(t, err, ErrNoCalendar)
+
+	_, err = Parse(strings.NewReader("BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:x\nEND:VEVENT\nEND:VCALENDAR\n"))
+	assert.Error(t, err)
+}
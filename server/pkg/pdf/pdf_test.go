@@ -0,0 +1,52 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTo(t *testing.T) {
+	doc := New("Transcript (official)")
+	p := doc.AddPage()
+	p.Text(50, 800, 16, HelveticaBold, "Café (2026)")
+	p.Line(50, 790, 545, 790, 0.5)
+	doc.AddPage().Text(50, 800, 10, Helvetica, "Page two ✓")
+
+	var buf bytes.Buffer
+	_, err := doc.WriteTo(&buf)
+	require.NoError(t, err)
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, "%PDF-1.4\n"))
+	assert.True(t, strings.HasSuffix(out, "%%EOF\n"))
+	assert.Contains(t, out, "/Count 2")
+	assert.Contains(t, out, `(Caf\351 \(2026\)) Tj`)
+	assert.Contains(t, out, "(Page two ?) Tj")
+	assert.Contains(t, out, `/Title (Transcript \(official\))`)
+
+	// Every xref entry points at its object
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(out)
+	require.Len(t, m, 2)
+	xref, _ := strconv.Atoi(m[1])
+	require.True(t, strings.HasPrefix(out[xref:], "xref\n"))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(out[xref:], -1)
+	require.Len(t, entries, 9)
+	for i, e := range entries {
+		off, _ := strconv.Atoi(e[1])
+		assert.True(t, strings.HasPrefix(out[off:], fmt.Sprintf("%d 0 obj\n", i+1)), "object %d", i+1)
+	}
+}
+
+func TestEmptyDocumentHasAPage(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := New("").WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "/Count 1")
+}